@@ -0,0 +1,121 @@
+// Package buildinfo reports the version, commit, and build date of the running binary.
+//
+// Values can be stamped at build time with ldflags:
+//
+//	go build -ldflags "-X github.com/mhpenta/minimcp/buildinfo.Version=v1.2.3 \
+//	    -X github.com/mhpenta/minimcp/buildinfo.Commit=$(git rev-parse HEAD) \
+//	    -X github.com/mhpenta/minimcp/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Anything left unset falls back to the module and VCS information embedded by the Go toolchain.
+package buildinfo
+
+import (
+	"flag"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Set via -ldflags "-X"; empty values fall back to debug.ReadBuildInfo
+var (
+	Version string
+	Commit  string
+	Date    string
+)
+
+// DevelVersion is reported when no version is stamped or embedded
+const DevelVersion = "(devel)"
+
+// Info describes the running binary
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	GoVersion string `json:"goVersion"`
+	Modified  bool   `json:"modified,omitempty"`
+}
+
+var (
+	once sync.Once
+	info Info
+)
+
+// Get returns the build information, computed once
+func Get() Info {
+	once.Do(func() {
+		info = read(debug.ReadBuildInfo)
+	})
+	return info
+}
+
+// read merges ldflags values with the toolchain's embedded build information
+func read(readBuildInfo func() (*debug.BuildInfo, bool)) Info {
+	i := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+	}
+
+	if bi, ok := readBuildInfo(); ok {
+		if i.Version == "" && bi.Main.Version != "" && bi.Main.Version != DevelVersion {
+			i.Version = bi.Main.Version
+		}
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if i.Commit == "" {
+					i.Commit = setting.Value
+				}
+			case "vcs.time":
+				if i.Date == "" {
+					i.Date = setting.Value
+				}
+			case "vcs.modified":
+				i.Modified = setting.Value == "true"
+			}
+		}
+	}
+
+	if i.Version == "" {
+		i.Version = DevelVersion
+	}
+	return i
+}
+
+// String formats the build information for a --version flag or log line
+func (i Info) String() string {
+	s := i.Version
+	if i.Commit != "" {
+		commit := i.Commit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		if i.Modified {
+			commit += "-dirty"
+		}
+		s += " (" + commit
+		if i.Date != "" {
+			s += ", " + i.Date
+		}
+		s += ")"
+	}
+	return fmt.Sprintf("%s %s", s, i.GoVersion)
+}
+
+// VersionFlag registers a --version flag on fs (flag.CommandLine when nil).
+// After parsing, a true value means the caller should print Get() and exit:
+//
+//	showVersion := buildinfo.VersionFlag(nil)
+//	flag.Parse()
+//	if *showVersion {
+//	    fmt.Println(buildinfo.Get())
+//	    os.Exit(0)
+//	}
+func VersionFlag(fs *flag.FlagSet) *bool {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	return fs.Bool("version", false, "print version information and exit")
+}
@@ -0,0 +1,61 @@
+package buildinfo
+
+import (
+	"flag"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestRead_FallsBackToEmbeddedInfo(t *testing.T) {
+	embedded := func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			Main: debug.Module{Version: "v1.4.0"},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "0123456789abcdef0123"},
+				{Key: "vcs.time", Value: "2024-05-01T12:00:00Z"},
+				{Key: "vcs.modified", Value: "true"},
+			},
+		}, true
+	}
+
+	info := read(embedded)
+	if info.Version != "v1.4.0" || info.Commit != "0123456789abcdef0123" || info.Date != "2024-05-01T12:00:00Z" || !info.Modified {
+		t.Errorf("unexpected info: %+v", info)
+	}
+	if s := info.String(); !strings.HasPrefix(s, "v1.4.0 (0123456789ab-dirty, 2024-05-01T12:00:00Z)") {
+		t.Errorf("unexpected String(): %q", s)
+	}
+}
+
+func TestRead_LdflagsTakePrecedence(t *testing.T) {
+	Version, Commit = "v2.0.0", "feedface"
+	defer func() { Version, Commit = "", "" }()
+
+	info := read(func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			Main:     debug.Module{Version: "v1.4.0"},
+			Settings: []debug.BuildSetting{{Key: "vcs.revision", Value: "0123456789abcdef"}},
+		}, true
+	})
+	if info.Version != "v2.0.0" || info.Commit != "feedface" {
+		t.Errorf("expected ldflags values, got %+v", info)
+	}
+
+	Version, Commit = "", ""
+	info = read(func() (*debug.BuildInfo, bool) { return nil, false })
+	if info.Version != DevelVersion {
+		t.Errorf("expected %q without any build info, got %q", DevelVersion, info.Version)
+	}
+}
+
+func TestVersionFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	showVersion := VersionFlag(fs)
+	if err := fs.Parse([]string{"--version"}); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if !*showVersion {
+		t.Error("expected --version to be set")
+	}
+}
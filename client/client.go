@@ -0,0 +1,321 @@
+// Package client connects to MCP servers: minimcp servers, and any other server speaking
+// the protocol over stdio or HTTP.
+//
+// Example:
+//
+//	conn, err := client.StartCommand(ctx, "npx", "-y", "@modelcontextprotocol/server-filesystem", "/tmp")
+//	if err != nil {
+//	    return err
+//	}
+//	c := client.New(conn)
+//	defer c.Close()
+//
+//	if _, err := c.Initialize(ctx, mcp.ClientInfo{Name: "my-app", Version: "1.0.0"}); err != nil {
+//	    return err
+//	}
+//	tools, err := c.ListTools(ctx)
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mhpenta/minimcp/mcp"
+)
+
+// ErrClosed is returned by calls made after the connection closed
+var ErrClosed = errors.New("client closed")
+
+// Conn carries JSON-RPC messages to and from a server. *mcp.InMemoryClient implements
+// it, as do the stdio and HTTP connections of this package.
+type Conn interface {
+	// Send delivers one message to the server
+	Send(ctx context.Context, msg []byte) error
+
+	// Receive blocks until the server sends a message
+	Receive(ctx context.Context) ([]byte, error)
+
+	// Close disconnects from the server
+	Close() error
+}
+
+// Client issues MCP requests over a Conn, matching responses to requests by ID
+type Client struct {
+	conn   Conn
+	nextID atomic.Int64
+
+	mu            sync.Mutex
+	pending       map[int64]chan *response
+	onNotify      []func(mcp.JSONRPCNotification)
+	err           error // set once the connection fails or closes
+	initialized   *mcp.InitializeResult
+	readLoopDone  chan struct{}
+	cancelReading context.CancelFunc
+}
+
+// response is a JSON-RPC response whose result is decoded by the caller
+type response struct {
+	Result json.RawMessage `json:"result"`
+	Error  *mcp.RPCError   `json:"error"`
+}
+
+// message is any JSON-RPC message received from the server
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	response
+}
+
+// New starts a client over conn. Call Initialize before other requests.
+func New(conn Conn) *Client {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Client{
+		conn:          conn,
+		pending:       make(map[int64]chan *response),
+		readLoopDone:  make(chan struct{}),
+		cancelReading: cancel,
+	}
+	go c.readLoop(ctx)
+	return c
+}
+
+// Initialize performs the MCP handshake, offering the latest protocol version this
+// module speaks, and sends notifications/initialized
+func (c *Client) Initialize(ctx context.Context, info mcp.ClientInfo) (*mcp.InitializeResult, error) {
+	var result mcp.InitializeResult
+	err := c.Call(ctx, mcp.MethodInitialize, mcp.InitializeParams{
+		ProtocolVersion: mcp.LatestProtocolVersion,
+		Capabilities:    map[string]interface{}{},
+		ClientInfo:      info,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Notify(ctx, "notifications/initialized", nil); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.initialized = &result
+	c.mu.Unlock()
+	return &result, nil
+}
+
+// ServerInfo returns the initialize result, or nil before Initialize succeeds
+func (c *Client) ServerInfo() *mcp.InitializeResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.initialized
+}
+
+// ListTools lists the server's tools, following pagination cursors
+func (c *Client) ListTools(ctx context.Context) ([]mcp.ToolDescription, error) {
+	var all []mcp.ToolDescription
+	cursor := ""
+	for {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		var result mcp.ToolsListResult
+		if err := c.Call(ctx, mcp.MethodToolsList, params, &result); err != nil {
+			return nil, err
+		}
+		all = append(all, result.Tools...)
+		if result.NextCursor == "" || result.NextCursor == cursor {
+			return all, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// CallTool calls a tool. A tool that fails is reported in the result's IsError, not as
+// an error; errors are protocol failures such as an unknown tool.
+func (c *Client) CallTool(ctx context.Context, name string, arguments json.RawMessage) (*mcp.ToolsCallResult, error) {
+	var result mcp.ToolsCallResult
+	if err := c.Call(ctx, mcp.MethodToolsCall, mcp.ToolsCallParams{Name: name, Arguments: arguments}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Ping checks that the server is responsive
+func (c *Client) Ping(ctx context.Context) error {
+	return c.Call(ctx, mcp.MethodPing, nil, nil)
+}
+
+// OnNotification registers fn to receive the server's notifications, such as
+// notifications/tools/list_changed. fn runs on the read loop and must not block.
+func (c *Client) OnNotification(fn func(mcp.JSONRPCNotification)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onNotify = append(c.onNotify, fn)
+}
+
+// Call sends a request and decodes its result into result, which may be nil. A JSON-RPC
+// error response is returned as *mcp.RPCError. If ctx ends first, the server is sent
+// notifications/cancelled.
+func (c *Client) Call(ctx context.Context, method string, params, result interface{}) error {
+	id := c.nextID.Add(1)
+	ch := make(chan *response, 1)
+
+	c.mu.Lock()
+	if c.err != nil {
+		err := c.err
+		c.mu.Unlock()
+		return err
+	}
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.send(ctx, map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params}); err != nil {
+		return err
+	}
+
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			return c.Err()
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil || len(resp.Result) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("decoding %s result: %w", method, err)
+		}
+		return nil
+	case <-ctx.Done():
+		// Best effort: the request is abandoned either way
+		_ = c.Notify(context.Background(), mcp.MethodNotificationCancelled, mcp.CancelledNotificationParams{
+			RequestID: id,
+			Reason:    ctx.Err().Error(),
+		})
+		return ctx.Err()
+	}
+}
+
+// Notify sends a notification, which has no response
+func (c *Client) Notify(ctx context.Context, method string, params interface{}) error {
+	msg := map[string]interface{}{"jsonrpc": "2.0", "method": method}
+	if params != nil {
+		msg["params"] = params
+	}
+	return c.send(ctx, msg)
+}
+
+func (c *Client) send(ctx context.Context, msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return c.conn.Send(ctx, data)
+}
+
+// Err returns the error that ended the connection, or nil while it is open
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Done is closed once the connection has ended, after which every call fails with Err
+func (c *Client) Done() <-chan struct{} {
+	return c.readLoopDone
+}
+
+// Close disconnects from the server, failing calls still waiting for a response
+func (c *Client) Close() error {
+	c.fail(ErrClosed)
+	err := c.conn.Close()
+	c.cancelReading()
+	<-c.readLoopDone
+	return err
+}
+
+// readLoop dispatches the server's messages until the connection fails
+func (c *Client) readLoop(ctx context.Context) {
+	defer close(c.readLoopDone)
+	for {
+		data, err := c.conn.Receive(ctx)
+		if err != nil {
+			c.fail(fmt.Errorf("connection lost: %w", err))
+			return
+		}
+
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		switch {
+		case msg.Method != "" && len(msg.ID) > 0:
+			c.rejectRequest(ctx, msg)
+		case msg.Method != "":
+			c.notify(mcp.JSONRPCNotification{JSONRPC: "2.0", Method: msg.Method, Params: msg.Params})
+		default:
+			c.deliver(msg)
+		}
+	}
+}
+
+// deliver hands a response to the call waiting for it
+func (c *Client) deliver(msg message) {
+	var id int64
+	if err := json.Unmarshal(msg.ID, &id); err != nil {
+		return
+	}
+	// Sending under the lock keeps fail from closing ch first; ch is buffered, so this
+	// never blocks
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ch, ok := c.pending[id]; ok {
+		resp := msg.response
+		ch <- &resp
+		delete(c.pending, id)
+	}
+}
+
+func (c *Client) notify(n mcp.JSONRPCNotification) {
+	c.mu.Lock()
+	handlers := c.onNotify[:len(c.onNotify):len(c.onNotify)]
+	c.mu.Unlock()
+	for _, fn := range handlers {
+		fn(n)
+	}
+}
+
+// rejectRequest answers requests from the server, such as sampling, which this client
+// does not offer
+func (c *Client) rejectRequest(ctx context.Context, msg message) {
+	_ = c.send(ctx, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      msg.ID,
+		"error":   mcp.RPCError{Code: mcp.MethodNotFound, Message: "Method not found: " + msg.Method},
+	})
+}
+
+// fail records the first error ending the connection and releases waiting calls
+func (c *Client) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+}
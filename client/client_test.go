@@ -0,0 +1,185 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/tools"
+)
+
+type addInput struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+type addOutput struct {
+	Sum int `json:"sum"`
+}
+
+func newTestServer() *mcp.Server {
+	add := tools.NewTool("add", "Adds two integers", func(ctx context.Context, in addInput) (addOutput, error) {
+		return addOutput{Sum: in.A + in.B}, nil
+	})
+	fail := tools.NewTool("fail", "Always fails", func(ctx context.Context, in struct{}) (string, error) {
+		return "", errors.New("broken")
+	})
+	return mcp.NewServer(mcp.ServerConfig{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Tools:   []tools.Tool{add, fail},
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+}
+
+// exercise runs the same session against any connection
+func exercise(t *testing.T, conn Conn) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c := New(conn)
+	defer c.Close()
+
+	init, err := c.Initialize(ctx, mcp.ClientInfo{Name: "client-test", Version: "1.0"})
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if init.ServerInfo.Name != "test-server" || init.ProtocolVersion != mcp.LatestProtocolVersion {
+		t.Errorf("unexpected initialize result: %+v", init)
+	}
+
+	list, err := c.ListTools(ctx)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(list) != 2 || list[0].Name != "add" {
+		t.Errorf("unexpected tools: %+v", list)
+	}
+
+	result, err := c.CallTool(ctx, "add", json.RawMessage(`{"a":2,"b":3}`))
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if result.IsError || len(result.Content) != 1 || result.Content[0].Text != `{"sum":5}` {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	result, err = c.CallTool(ctx, "fail", json.RawMessage(`{}`))
+	if err != nil || !result.IsError {
+		t.Errorf("expected a tool error result, got %+v, %v", result, err)
+	}
+
+	_, err = c.CallTool(ctx, "missing", nil)
+	var rpcErr *mcp.RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != mcp.InvalidParams {
+		t.Errorf("expected InvalidParams for an unknown tool, got %v", err)
+	}
+
+	if err := c.Ping(ctx); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+}
+
+func TestClient_InMemory(t *testing.T) {
+	transport, conn := mcp.NewInMemoryTransport(newTestServer(), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go transport.Start(ctx)
+
+	exercise(t, conn)
+}
+
+func TestClient_Stdio(t *testing.T) {
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+	transport := mcp.NewStdioTransportWithIO(newTestServer(), nil, serverReader, serverWriter)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		transport.Start(context.Background())
+		serverWriter.Close()
+	}()
+
+	exercise(t, NewStdioConn(clientReader, clientWriter))
+	<-done
+}
+
+func TestClient_HTTP(t *testing.T) {
+	server := newTestServer()
+	transport := mcp.NewHTTPTransport(server, nil, mcp.NewConstantTimeStaticValidator("secret"))
+	httpServer := httptest.NewServer(transport)
+	defer httpServer.Close()
+
+	exercise(t, NewHTTPConn(httpServer.URL+"/mcp").WithHeader("Authorization", "Bearer secret"))
+
+	// Requests without credentials fail instead of hanging
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := New(NewHTTPConn(httpServer.URL + "/mcp"))
+	defer c.Close()
+	if _, err := c.Initialize(ctx, mcp.ClientInfo{Name: "client-test"}); err == nil {
+		t.Error("expected unauthorized initialize to fail")
+	}
+}
+
+func TestClient_ConnectionLost(t *testing.T) {
+	clientReader, serverWriter := io.Pipe()
+	_, clientWriter := io.Pipe()
+	c := New(NewStdioConn(clientReader, clientWriter))
+	defer c.Close()
+
+	serverWriter.Close()
+	select {
+	case <-c.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the client to notice the closed stream")
+	}
+	if err := c.Ping(context.Background()); err == nil {
+		t.Error("expected calls to fail after the connection is lost")
+	}
+}
+
+func TestClient_CallCancelled(t *testing.T) {
+	clientReader, _ := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+	c := New(NewStdioConn(clientReader, clientWriter))
+	defer c.Close()
+
+	// Read what the client sends: the request, then its cancellation
+	sent := make(chan map[string]interface{}, 2)
+	go func() {
+		decoder := json.NewDecoder(serverReader)
+		for {
+			var msg map[string]interface{}
+			if decoder.Decode(&msg) != nil {
+				return
+			}
+			sent <- msg
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-sent
+		cancel()
+	}()
+	if err := c.Ping(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	select {
+	case msg := <-sent:
+		if msg["method"] != mcp.MethodNotificationCancelled {
+			t.Errorf("expected notifications/cancelled, got %v", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a cancellation notification")
+	}
+}
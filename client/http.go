@@ -0,0 +1,176 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTPConn sends messages to a server's streamable HTTP endpoint. Responses arrive as
+// JSON or as a server-sent event stream, and the session ID the server assigns is sent
+// with every later request.
+type HTTPConn struct {
+	url    string
+	client *http.Client
+	header http.Header
+
+	mu        sync.Mutex
+	sessionID string
+
+	messages  chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewHTTPConn creates a connection to the MCP endpoint at url
+func NewHTTPConn(url string) *HTTPConn {
+	return &HTTPConn{
+		url:      url,
+		client:   &http.Client{Timeout: 5 * time.Minute},
+		header:   make(http.Header),
+		messages: make(chan []byte, 16),
+		closed:   make(chan struct{}),
+	}
+}
+
+// WithHeader adds a header to every request, such as Authorization
+func (c *HTTPConn) WithHeader(key, value string) *HTTPConn {
+	c.header.Add(key, value)
+	return c
+}
+
+// WithHTTPClient replaces the HTTP client used for requests
+func (c *HTTPConn) WithHTTPClient(client *http.Client) *HTTPConn {
+	c.client = client
+	return c
+}
+
+// Send posts one message and queues the messages the server answers with
+func (c *HTTPConn) Send(ctx context.Context, msg []byte) error {
+	select {
+	case <-c.closed:
+		return ErrClosed
+	default:
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(msg))
+	if err != nil {
+		return err
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	c.mu.Lock()
+	if c.sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", c.sessionID)
+	}
+	c.mu.Unlock()
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if id := resp.Header.Get("Mcp-Session-Id"); id != "" {
+		c.mu.Lock()
+		c.sessionID = id
+		c.mu.Unlock()
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusAccepted || resp.StatusCode == http.StatusNoContent:
+		return nil
+	case resp.StatusCode != http.StatusOK:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "text/event-stream" {
+		return c.readEvents(ctx, resp.Body)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	return c.queue(ctx, body)
+}
+
+// readEvents queues the data of each server-sent event
+func (c *HTTPConn) readEvents(ctx context.Context, body io.Reader) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	var data []byte
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "data:"):
+			if len(data) > 0 {
+				data = append(data, '\n')
+			}
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")...)
+		case line == "":
+			if len(data) > 0 {
+				if err := c.queue(ctx, data); err != nil {
+					return err
+				}
+				data = nil
+			}
+		}
+	}
+	if len(data) > 0 {
+		if err := c.queue(ctx, data); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (c *HTTPConn) queue(ctx context.Context, msg []byte) error {
+	select {
+	case c.messages <- msg:
+		return nil
+	case <-c.closed:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Receive returns the next message the server sent
+func (c *HTTPConn) Receive(ctx context.Context) ([]byte, error) {
+	select {
+	case msg := <-c.messages:
+		return msg, nil
+	case <-c.closed:
+		return nil, ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// SessionID returns the session ID the server assigned, or ""
+func (c *HTTPConn) SessionID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sessionID
+}
+
+// Close stops the connection
+func (c *HTTPConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return nil
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/mhpenta/minimcp/mcp"
+)
+
+// processExitTimeout is how long a server process may take to exit once its stdin closes
+// before it is killed
+const processExitTimeout = 2 * time.Second
+
+// StdioConn exchanges newline-delimited JSON-RPC messages over a pair of streams, such
+// as a server process's stdout and stdin
+type StdioConn struct {
+	writer io.Writer
+	close  func() error
+
+	writeMu sync.Mutex
+
+	messages  chan []byte
+	readErr   error // set before done is closed
+	done      chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewStdioConn reads messages from r and writes them to w. Close closes w, if it is an
+// io.Closer.
+func NewStdioConn(r io.Reader, w io.Writer) *StdioConn {
+	c := newStdioConn(r, w)
+	c.close = func() error {
+		if closer, ok := w.(io.Closer); ok {
+			return closer.Close()
+		}
+		return nil
+	}
+	return c
+}
+
+func newStdioConn(r io.Reader, w io.Writer) *StdioConn {
+	c := &StdioConn{
+		writer:   w,
+		messages: make(chan []byte),
+		done:     make(chan struct{}),
+		closed:   make(chan struct{}),
+	}
+	go c.read(r)
+	return c
+}
+
+// StartCommand runs a server process and connects to its stdin and stdout. The process's
+// stderr is passed through to this process's stderr.
+func StartCommand(ctx context.Context, name string, args ...string) (*StdioConn, error) {
+	return StartProcess(exec.CommandContext(ctx, name, args...))
+}
+
+// StartProcess starts cmd, which must not have Stdin or Stdout set, and connects to it.
+// Set cmd.Env and cmd.Dir as needed; a nil cmd.Stderr is passed through to os.Stderr.
+// Close closes the process's stdin, waits briefly for it to exit, then kills it.
+func StartProcess(cmd *exec.Cmd) (*StdioConn, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting %s: %w", cmd.Path, err)
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	c := newStdioConn(stdout, stdin)
+	c.close = func() error {
+		stdin.Close()
+		select {
+		case <-exited:
+			return nil
+		case <-time.After(processExitTimeout):
+			cmd.Process.Kill()
+			<-exited
+			return nil
+		}
+	}
+	return c, nil
+}
+
+// read forwards each line until r fails
+func (c *StdioConn) read(r io.Reader) {
+	defer close(c.done)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, mcp.DefaultInitialBufferBytes), mcp.DefaultMaxMessageBytes)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		msg := append([]byte(nil), line...)
+		select {
+		case c.messages <- msg:
+		case <-c.closed:
+			c.readErr = ErrClosed
+			return
+		}
+	}
+	c.readErr = scanner.Err()
+	if c.readErr == nil {
+		c.readErr = io.EOF
+	}
+}
+
+// Send writes one message followed by a newline
+func (c *StdioConn) Send(ctx context.Context, msg []byte) error {
+	select {
+	case <-c.closed:
+		return ErrClosed
+	default:
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := c.writer.Write(append(msg, '\n')); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Receive returns the next message, or the error that ended the stream
+func (c *StdioConn) Receive(ctx context.Context) ([]byte, error) {
+	select {
+	case msg := <-c.messages:
+		return msg, nil
+	case <-c.done:
+		return nil, c.readErr
+	case <-c.closed:
+		return nil, ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close closes the connection, stopping the server process if StartProcess started it
+func (c *StdioConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.closeErr = c.close()
+	})
+	return c.closeErr
+}
@@ -0,0 +1,398 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mhpenta/minimcp/mcp"
+)
+
+// Defaults applied by NewSupervisor
+const (
+	DefaultMinBackoff   = 500 * time.Millisecond
+	DefaultMaxBackoff   = 30 * time.Second
+	DefaultStartTimeout = 30 * time.Second
+)
+
+// ErrNotRunning is returned by Supervisor.Client while the server process is not ready
+var ErrNotRunning = errors.New("server process not running")
+
+// ProcessState is the lifecycle state of a supervised server process
+type ProcessState int
+
+const (
+	// ProcessStarting means the process is being spawned and initialized
+	ProcessStarting ProcessState = iota
+
+	// ProcessRunning means the process completed the MCP handshake and is serving
+	ProcessRunning
+
+	// ProcessBackoff means the process exited and is waiting to be restarted
+	ProcessBackoff
+
+	// ProcessStopped means the supervisor was closed
+	ProcessStopped
+)
+
+func (s ProcessState) String() string {
+	switch s {
+	case ProcessStarting:
+		return "starting"
+	case ProcessRunning:
+		return "running"
+	case ProcessBackoff:
+		return "backoff"
+	case ProcessStopped:
+		return "stopped"
+	}
+	return fmt.Sprintf("ProcessState(%d)", int(s))
+}
+
+// ProcessConfig configures a Supervisor
+type ProcessConfig struct {
+	// Command and Args start the server, which must speak MCP over stdio
+	Command string
+	Args    []string
+
+	// Env entries are added to this process's environment. Dir is the working
+	// directory, defaulting to this process's.
+	Env map[string]string
+	Dir string
+
+	// ClientInfo identifies this application in the MCP handshake
+	ClientInfo mcp.ClientInfo
+
+	// MinBackoff is the delay before the first restart, doubling after each crash up to
+	// MaxBackoff. A process that stays up for MaxBackoff resets the delay.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// StartTimeout bounds the MCP handshake with a new process
+	StartTimeout time.Duration
+
+	// OnConnect, if set, is called with the client of each new process before it is
+	// reported running, for example to subscribe to notifications
+	OnConnect func(*Client)
+
+	// Logger receives lifecycle events and every line the process writes to stderr
+	Logger *slog.Logger
+}
+
+// Health reports the state of a supervised process
+type Health struct {
+	State ProcessState
+
+	// Since is when the process entered State
+	Since time.Time
+
+	// PID is the process ID while running, 0 otherwise
+	PID int
+
+	// Restarts counts the times the process exited or failed to start and was restarted
+	Restarts int
+
+	// LastError is why the process last exited or failed to start
+	LastError error
+
+	// NextRestart is when the process will be restarted, set only in ProcessBackoff
+	NextRestart time.Time
+}
+
+// Supervisor keeps an MCP server process running: it spawns the process, restarts it
+// with exponential backoff when it crashes, and logs its stderr.
+//
+// Example:
+//
+//	sup := client.NewSupervisor(client.ProcessConfig{
+//	    Command:    "npx",
+//	    Args:       []string{"-y", "@modelcontextprotocol/server-filesystem", "/tmp"},
+//	    ClientInfo: mcp.ClientInfo{Name: "my-app", Version: "1.0.0"},
+//	})
+//	sup.Start(ctx)
+//	defer sup.Close()
+//
+//	c, err := sup.WaitReady(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	result, err := c.CallTool(ctx, "read_file", args)
+type Supervisor struct {
+	cfg    ProcessConfig
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	client  *Client
+	health  Health
+	changed chan struct{} // closed and replaced on every state change
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewSupervisor creates a supervisor. Start spawns the process.
+func NewSupervisor(cfg ProcessConfig) *Supervisor {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = DefaultMinBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = cfg.MinBackoff
+	}
+	if cfg.StartTimeout <= 0 {
+		cfg.StartTimeout = DefaultStartTimeout
+	}
+	return &Supervisor{
+		cfg:     cfg,
+		logger:  cfg.Logger.With("command", cfg.Command),
+		health:  Health{State: ProcessStarting, Since: time.Now()},
+		changed: make(chan struct{}),
+	}
+}
+
+// Start spawns the process and keeps it running in the background until ctx ends or
+// Close is called. It returns immediately; use WaitReady to wait for the handshake.
+func (s *Supervisor) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.run(ctx)
+}
+
+// Close stops the process and waits for it to exit
+func (s *Supervisor) Close() error {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+	return nil
+}
+
+// Health returns the current state of the process
+func (s *Supervisor) Health() Health {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.health
+}
+
+// Client returns the client of the running process. It fails with ErrNotRunning while
+// the process is starting or restarting; the client becomes unusable once the process
+// exits, so fetch it again for each use.
+func (s *Supervisor) Client() (*Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client == nil {
+		if s.health.LastError != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotRunning, s.health.LastError)
+		}
+		return nil, ErrNotRunning
+	}
+	return s.client, nil
+}
+
+// WaitReady blocks until the process is running and returns its client, or fails when
+// ctx ends or the supervisor stops
+func (s *Supervisor) WaitReady(ctx context.Context) (*Client, error) {
+	for {
+		s.mu.Lock()
+		c, state, changed := s.client, s.health.State, s.changed
+		s.mu.Unlock()
+
+		if c != nil {
+			return c, nil
+		}
+		if state == ProcessStopped {
+			return nil, ErrNotRunning
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// run restarts the process until ctx ends
+func (s *Supervisor) run(ctx context.Context) {
+	defer s.wg.Done()
+
+	backoff := s.cfg.MinBackoff
+	for {
+		started := time.Now()
+		err := s.runOnce(ctx)
+		if ctx.Err() != nil {
+			s.setState(func(h *Health) { h.State = ProcessStopped })
+			return
+		}
+
+		if time.Since(started) >= s.cfg.MaxBackoff {
+			backoff = s.cfg.MinBackoff
+		}
+		next := time.Now().Add(backoff)
+		s.logger.Warn("server process exited", "error", err, "restart_in", backoff)
+		s.setState(func(h *Health) {
+			h.State = ProcessBackoff
+			h.LastError = err
+			h.NextRestart = next
+		})
+
+		select {
+		case <-ctx.Done():
+			s.setState(func(h *Health) { h.State = ProcessStopped })
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, s.cfg.MaxBackoff)
+		s.setState(func(h *Health) {
+			h.State = ProcessStarting
+			h.Restarts++
+			h.NextRestart = time.Time{}
+		})
+	}
+}
+
+// runOnce spawns and initializes the process, then waits for it to exit or for ctx to
+// end. It returns why the process stopped.
+func (s *Supervisor) runOnce(ctx context.Context) error {
+	cmd := exec.Command(s.cfg.Command, s.cfg.Args...)
+	cmd.Dir = s.cfg.Dir
+	if len(s.cfg.Env) > 0 {
+		cmd.Env = os.Environ()
+		keys := make([]string, 0, len(s.cfg.Env))
+		for key := range s.cfg.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			cmd.Env = append(cmd.Env, key+"="+s.cfg.Env[key])
+		}
+	}
+	stderr := &logWriter{logger: s.logger}
+	cmd.Stderr = stderr
+	defer stderr.Flush()
+
+	conn, err := StartProcess(cmd)
+	if err != nil {
+		return err
+	}
+	c := New(conn)
+
+	initCtx, cancel := context.WithTimeout(ctx, s.cfg.StartTimeout)
+	_, err = c.Initialize(initCtx, s.cfg.ClientInfo)
+	cancel()
+	if err != nil {
+		c.Close()
+		return exitError(cmd, fmt.Errorf("initializing: %w", err))
+	}
+	if s.cfg.OnConnect != nil {
+		s.cfg.OnConnect(c)
+	}
+
+	s.logger.Info("server process running", "pid", cmd.Process.Pid)
+	s.setState(func(h *Health) {
+		h.State = ProcessRunning
+		h.PID = cmd.Process.Pid
+		h.NextRestart = time.Time{}
+	})
+	s.mu.Lock()
+	s.client = c
+	s.mu.Unlock()
+
+	select {
+	case <-c.Done():
+		err = c.Err()
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	s.client = nil
+	s.mu.Unlock()
+	s.setState(func(h *Health) { h.PID = 0 })
+
+	// Closing waits for the process to exit, so its exit status is available below
+	c.Close()
+	return exitError(cmd, err)
+}
+
+// setState applies update to the health report and wakes WaitReady callers
+func (s *Supervisor) setState(update func(*Health)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.health.State
+	update(&s.health)
+	if s.health.State != previous {
+		s.health.Since = time.Now()
+	}
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// exitError adds the process's exit status to err, which is often just the closed
+// stdout that followed the exit
+func exitError(cmd *exec.Cmd, err error) error {
+	if cmd.ProcessState == nil {
+		return err
+	}
+	if err == nil {
+		return fmt.Errorf("server process %s", cmd.ProcessState)
+	}
+	return fmt.Errorf("%w (server process %s)", err, cmd.ProcessState)
+}
+
+// logWriter logs each line written to it, for capturing a process's stderr
+type logWriter struct {
+	logger *slog.Logger
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush logs a final line that did not end in a newline
+func (w *logWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.log(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *logWriter) log(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) > 0 {
+		w.logger.Info("server stderr", "line", string(line))
+	}
+}
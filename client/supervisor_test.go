@@ -0,0 +1,171 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// TestMain doubles as a stdio MCP server, so supervisors can run the test binary
+func TestMain(m *testing.M) {
+	switch os.Getenv("CLIENT_TEST_SERVER") {
+	case "serve":
+		fmt.Fprintln(os.Stderr, "test server starting")
+		server := newTestServer()
+		server.AddTools(tools.NewTool("crash", "Exits the process", func(ctx context.Context, in struct{}) (string, error) {
+			os.Exit(3)
+			return "", nil
+		}))
+		mcp.NewStdioTransport(server, nil).Start(context.Background())
+		return
+	case "crash":
+		fmt.Fprint(os.Stderr, "cannot start")
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// syncBuffer collects log output written from several goroutines
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newTestSupervisor(t *testing.T, mode string, logs *syncBuffer) *Supervisor {
+	t.Helper()
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewSupervisor(ProcessConfig{
+		Command:      executable,
+		Env:          map[string]string{"CLIENT_TEST_SERVER": mode},
+		ClientInfo:   mcp.ClientInfo{Name: "supervisor-test", Version: "1.0"},
+		MinBackoff:   10 * time.Millisecond,
+		MaxBackoff:   40 * time.Millisecond,
+		StartTimeout: 5 * time.Second,
+		Logger:       slog.New(slog.NewTextHandler(logs, nil)),
+	})
+}
+
+func TestSupervisor_RestartsAfterCrash(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var logs syncBuffer
+	sup := newTestSupervisor(t, "serve", &logs)
+	sup.Start(ctx)
+	defer sup.Close()
+
+	c, err := sup.WaitReady(ctx)
+	if err != nil {
+		t.Fatalf("WaitReady failed: %v", err)
+	}
+	health := sup.Health()
+	if health.State != ProcessRunning || health.PID == 0 || health.Restarts != 0 {
+		t.Errorf("unexpected health after start: %+v", health)
+	}
+	if result, err := c.CallTool(ctx, "add", []byte(`{"a":1,"b":2}`)); err != nil || result.IsError {
+		t.Fatalf("CallTool failed: %v %+v", err, result)
+	}
+
+	// The crash ends the connection before any response arrives
+	c.CallTool(ctx, "crash", nil)
+	for {
+		if h := sup.Health(); h.Restarts == 1 && h.State == ProcessRunning {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("expected the process to be restarted, health %+v", sup.Health())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	if err := sup.Health().LastError; err == nil || !strings.Contains(err.Error(), "exit status 3") {
+		t.Errorf("expected the exit status in LastError, got %v", err)
+	}
+
+	c, err = sup.Client()
+	if err != nil {
+		t.Fatalf("Client failed after restart: %v", err)
+	}
+	if err := c.Ping(ctx); err != nil {
+		t.Errorf("expected the restarted process to answer, got %v", err)
+	}
+	if !strings.Contains(logs.String(), "test server starting") {
+		t.Errorf("expected stderr to be logged, got %s", logs.String())
+	}
+
+	sup.Close()
+	if h := sup.Health(); h.State != ProcessStopped {
+		t.Errorf("expected stopped after Close, got %+v", h)
+	}
+	if _, err := sup.WaitReady(ctx); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("expected ErrNotRunning after Close, got %v", err)
+	}
+}
+
+func TestSupervisor_BackoffOnStartFailure(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var logs syncBuffer
+	sup := newTestSupervisor(t, "crash", &logs)
+	sup.Start(ctx)
+	defer sup.Close()
+
+	for sup.Health().Restarts < 3 {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("expected repeated restarts, health %+v", sup.Health())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	if _, err := sup.Client(); !errors.Is(err, ErrNotRunning) || !strings.Contains(err.Error(), "exit status 1") {
+		t.Errorf("expected ErrNotRunning with the exit status, got %v", err)
+	}
+	// A final line without a newline is still logged
+	if !strings.Contains(logs.String(), "cannot start") {
+		t.Errorf("expected stderr to be logged, got %s", logs.String())
+	}
+}
+
+func TestLogWriter(t *testing.T) {
+	var logs syncBuffer
+	w := &logWriter{logger: slog.New(slog.NewTextHandler(&logs, nil))}
+	w.Write([]byte("first\r\nsec"))
+	w.Write([]byte("ond\n\npartial"))
+	w.Flush()
+
+	out := logs.String()
+	for _, want := range []string{"line=first\n", "line=second\n", "line=partial\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in %s", want, out)
+		}
+	}
+	if n := strings.Count(out, "server stderr"); n != 3 {
+		t.Errorf("expected 3 lines logged, got %d", n)
+	}
+}
@@ -0,0 +1,191 @@
+// Command minimcp-docs generates a ToolDescriptions method for a service type, returning
+// the doc comments of its tool methods so that tools.FromStruct can use them as tool
+// descriptions. Doc comments are not available at run time, hence the generator.
+//
+// Usage, from a go:generate directive in the service's package:
+//
+//	//go:generate go run github.com/mhpenta/minimcp/cmd/minimcp-docs -type WeatherService
+//
+// It writes weatherservice_tools_gen.go next to the package's files. A comment that starts
+// with the method's name, as Go doc comments do, has the name removed: "GetWeather fetches
+// the weather" describes the tool as "Fetches the weather".
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mhpenta/minimcp/buildinfo"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("minimcp-docs", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	typeName := fs.String("type", "", "service type whose methods become tools")
+	dir := fs.String("dir", ".", "directory of the service's package")
+	out := fs.String("out", "", `file to write (default "<type>_tools_gen.go" in -dir)`)
+	showVersion := buildinfo.VersionFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *showVersion {
+		fmt.Fprintln(stdout, buildinfo.Get())
+		return 0
+	}
+	if *typeName == "" || fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "usage: minimcp-docs -type Name [-dir package directory] [-out file.go]")
+		return 2
+	}
+	if *out == "" {
+		*out = filepath.Join(*dir, strings.ToLower(*typeName)+"_tools_gen.go")
+	}
+
+	source, err := generate(*dir, *typeName, filepath.Base(*out))
+	if err == nil {
+		err = os.WriteFile(*out, source, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "minimcp-docs:", err)
+		return 1
+	}
+	return 0
+}
+
+// generate parses the package in dir and returns the source of typeName's
+// ToolDescriptions method. outName, the file being written, is skipped when parsing.
+func generate(dir, typeName, outName string) ([]byte, error) {
+	fset := token.NewFileSet()
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	pkgName := ""
+	found := false
+	descriptions := map[string]string{}
+	for _, path := range matches {
+		base := filepath.Base(path)
+		if strings.HasSuffix(base, "_test.go") || base == outName {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		pkgName = file.Name.Name
+
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok && ts.Name.Name == typeName {
+						if ts.TypeParams != nil {
+							return nil, fmt.Errorf("%s is generic, which tools.FromStruct does not support", typeName)
+						}
+						found = true
+					}
+				}
+			case *ast.FuncDecl:
+				if decl.Recv == nil || receiverName(decl.Recv) != typeName || !decl.Name.IsExported() || !isToolMethod(decl.Type) {
+					continue
+				}
+				if description := describe(decl.Name.Name, decl.Doc.Text()); description != "" {
+					descriptions[decl.Name.Name] = description
+				}
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no type %s in %s", typeName, dir)
+	}
+
+	methods := make([]string, 0, len(descriptions))
+	for method := range descriptions {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by minimcp-docs. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkgName)
+	fmt.Fprintf(&b, "// ToolDescriptions returns the doc comments of %s's tool methods, which\n", typeName)
+	b.WriteString("// tools.FromStruct uses as tool descriptions\n")
+	fmt.Fprintf(&b, "func (%s) ToolDescriptions() map[string]string {\n", typeName)
+	b.WriteString("\treturn map[string]string{\n")
+	for _, method := range methods {
+		fmt.Fprintf(&b, "\t\t%s: %s,\n", strconv.Quote(method), strconv.Quote(descriptions[method]))
+	}
+	b.WriteString("\t}\n}\n")
+	return format.Source(b.Bytes())
+}
+
+// receiverName returns the type name of a method receiver, without any pointer
+func receiverName(recv *ast.FieldList) string {
+	if len(recv.List) != 1 {
+		return ""
+	}
+	expr := recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// isToolMethod reports whether a method looks like func(context.Context, In) (Out, error).
+// The types are not resolved, so tools.FromStruct makes the final decision.
+func isToolMethod(fn *ast.FuncType) bool {
+	return countFields(fn.Params) == 2 && countFields(fn.Results) == 2 &&
+		isIdent(fn.Results.List[len(fn.Results.List)-1].Type, "error")
+}
+
+func countFields(list *ast.FieldList) int {
+	if list == nil {
+		return 0
+	}
+	n := 0
+	for _, field := range list.List {
+		n += max(len(field.Names), 1)
+	}
+	return n
+}
+
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+// describe turns a method's doc comment into a tool description
+func describe(method, doc string) string {
+	doc = strings.TrimSpace(doc)
+	rest, ok := strings.CutPrefix(doc, method+" ")
+	if !ok {
+		return doc
+	}
+	r, size := utf8.DecodeRuneInString(rest)
+	return string(unicode.ToUpper(r)) + rest[size:]
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "descriptions_gen.go")
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-type", "WeatherService", "-dir", "testdata/weather", "-out", out}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `// Code generated by minimcp-docs. DO NOT EDIT.
+
+package weather
+
+// ToolDescriptions returns the doc comments of WeatherService's tool methods, which
+// tools.FromStruct uses as tool descriptions
+func (WeatherService) ToolDescriptions() map[string]string {
+	return map[string]string{
+		"Forecast":   "Returns tomorrow's weather",
+		"GetWeather": "Fetches the current weather for a city.\n\nTemperatures are in Celsius.",
+	}
+}
+`
+	if string(data) != want {
+		t.Errorf("unexpected output:\n%s", data)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	cases := []struct {
+		args []string
+		code int
+		want string
+	}{
+		{[]string{}, 2, "usage"},
+		{[]string{"-type", "Missing", "-dir", "testdata/weather", "-out", filepath.Join(t.TempDir(), "x.go")}, 1, "no type Missing"},
+	}
+	for _, tc := range cases {
+		var stdout, stderr bytes.Buffer
+		if code := run(tc.args, &stdout, &stderr); code != tc.code || !strings.Contains(stderr.String(), tc.want) {
+			t.Errorf("%v: expected exit %d with %q, got %d: %s", tc.args, tc.code, tc.want, code, stderr.String())
+		}
+	}
+}
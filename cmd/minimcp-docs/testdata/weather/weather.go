@@ -0,0 +1,36 @@
+package weather
+
+import "context"
+
+type WeatherInput struct {
+	City string `json:"city"`
+}
+
+// WeatherService answers questions about the weather
+type WeatherService struct{}
+
+// GetWeather fetches the current weather for a city.
+//
+// Temperatures are in Celsius.
+func (s *WeatherService) GetWeather(ctx context.Context, in WeatherInput) (string, error) {
+	return "sunny", nil
+}
+
+// Forecast returns tomorrow's weather
+func (WeatherService) Forecast(ctx context.Context, in WeatherInput) (string, error) {
+	return "rain", nil
+}
+
+func (s *WeatherService) Undocumented(ctx context.Context, in WeatherInput) (string, error) {
+	return "", nil
+}
+
+// String is not a tool method
+func (s *WeatherService) String() string {
+	return "weather"
+}
+
+// helper is not exported
+func (s *WeatherService) helper(ctx context.Context, in WeatherInput) (string, error) {
+	return "", nil
+}
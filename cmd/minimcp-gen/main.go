@@ -0,0 +1,87 @@
+// Command minimcp-gen generates MCP tools from an OpenAPI 3 document. It writes a Go file
+// declaring an input struct and a tool for each operation, and a NewTools function that
+// returns them all, bound to an openapi.Invoker.
+//
+// Usage:
+//
+//	minimcp-gen -in petstore.json -out petstore_gen.go -package petstore
+//
+// It is usually run from a go:generate directive next to the document:
+//
+//	//go:generate go run github.com/mhpenta/minimcp/cmd/minimcp-gen -in petstore.json -out petstore_gen.go -package petstore
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/mhpenta/minimcp/buildinfo"
+	"github.com/mhpenta/minimcp/openapi"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("minimcp-gen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	in := fs.String("in", "", "OpenAPI 3 document to read (JSON)")
+	out := fs.String("out", "", "Go file to write (default stdout)")
+	pkg := fs.String("package", "", "package name of the generated file (default the output directory's name)")
+	showVersion := buildinfo.VersionFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *showVersion {
+		fmt.Fprintln(stdout, buildinfo.Get())
+		return 0
+	}
+	if *in == "" || fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "usage: minimcp-gen -in document.json [-out file.go] [-package name]")
+		return 2
+	}
+
+	if *pkg == "" {
+		if *out == "" {
+			fmt.Fprintln(stderr, "minimcp-gen: -package is required when writing to stdout")
+			return 2
+		}
+		abs, err := filepath.Abs(*out)
+		if err != nil {
+			fmt.Fprintln(stderr, "minimcp-gen:", err)
+			return 1
+		}
+		*pkg = filepath.Base(filepath.Dir(abs))
+	}
+
+	doc, err := openapi.Load(*in)
+	if err != nil {
+		fmt.Fprintln(stderr, "minimcp-gen:", err)
+		return 1
+	}
+	source, err := openapi.Generate(doc, openapi.GenerateOptions{Package: *pkg, Source: filepath.Base(*in)})
+	if err != nil {
+		fmt.Fprintf(stderr, "minimcp-gen: %s: %v\n", *in, err)
+		return 1
+	}
+
+	if *out == "" {
+		_, err = stdout.Write(source)
+	} else {
+		err = os.WriteFile(*out, source, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "minimcp-gen:", err)
+		return 1
+	}
+	return 0
+}
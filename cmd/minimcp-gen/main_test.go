@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "petstore")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "tools_gen.go")
+	in := "../../examples/petstore/petstore/petstore.json"
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-in", in, "-out", out}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The package defaults to the output directory's name
+	if !strings.Contains(string(data), "package petstore\n") || !strings.Contains(string(data), "from petstore.json") {
+		t.Errorf("unexpected output:\n%s", data)
+	}
+
+	stdout.Reset()
+	if code := run([]string{"-in", in, "-package", "api"}, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "package api\n") {
+		t.Errorf("stdout: exit %d, %s", code, stderr.String())
+	}
+
+	for _, args := range [][]string{{}, {"-in", in}, {"-in", in, "extra"}} {
+		if code := run(args, &stdout, &stderr); code != 2 {
+			t.Errorf("%v: expected exit 2, got %d", args, code)
+		}
+	}
+	if code := run([]string{"-in", "missing.json", "-package", "api"}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected a missing document to exit 1, got %d", code)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mhpenta/minimcp/mcp"
+)
+
+// printJSON writes v as indented JSON
+func printJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func printInfo(w io.Writer, info *mcp.InitializeResult) {
+	fmt.Fprintf(w, "Server:   %s %s\n", info.ServerInfo.Name, info.ServerInfo.Version)
+	fmt.Fprintf(w, "Protocol: %s\n", info.ProtocolVersion)
+
+	var capabilities []string
+	if info.Capabilities.Tools != nil {
+		capabilities = append(capabilities, "tools")
+	}
+	if info.Capabilities.Resources != nil {
+		capabilities = append(capabilities, "resources")
+	}
+	var experimental []string
+	for name := range info.Capabilities.Experimental {
+		experimental = append(experimental, "experimental:"+name)
+	}
+	sort.Strings(experimental)
+	capabilities = append(capabilities, experimental...)
+	if len(capabilities) == 0 {
+		capabilities = []string{"none"}
+	}
+	fmt.Fprintf(w, "Supports: %s\n", strings.Join(capabilities, ", "))
+}
+
+// printTools lists each tool with a table of its parameters
+func printTools(w io.Writer, list []mcp.ToolDescription) {
+	if len(list) == 0 {
+		fmt.Fprintln(w, "No tools")
+		return
+	}
+	for i, tool := range list {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, tool.Name)
+		if tool.Description != "" {
+			for _, line := range strings.Split(strings.TrimSpace(tool.Description), "\n") {
+				fmt.Fprintln(w, "  "+line)
+			}
+		}
+
+		properties, _ := tool.InputSchema["properties"].(map[string]interface{})
+		if len(properties) == 0 {
+			fmt.Fprintln(w, "  (no parameters)")
+			continue
+		}
+		required := map[string]bool{}
+		if names, ok := tool.InputSchema["required"].([]interface{}); ok {
+			for _, name := range names {
+				if s, ok := name.(string); ok {
+					required[s] = true
+				}
+			}
+		}
+
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, name := range names {
+			prop, _ := properties[name].(map[string]interface{})
+			flag := ""
+			if required[name] {
+				flag = "required"
+			}
+			fmt.Fprintf(table, "  %s\t%s\t%s\t%s\n", name, schemaType(prop), flag, propertyNote(prop))
+		}
+		table.Flush()
+	}
+}
+
+// schemaType summarizes a property's type, such as "string", "integer|null" or "[]string"
+func schemaType(prop map[string]interface{}) string {
+	var types []string
+	switch t := prop["type"].(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, v := range t {
+			types = append(types, fmt.Sprint(v))
+		}
+	}
+	if len(types) == 0 {
+		return "any"
+	}
+	if types[0] == "array" {
+		if items, ok := prop["items"].(map[string]interface{}); ok {
+			types[0] = "[]" + schemaType(items)
+		}
+	}
+	return strings.Join(types, "|")
+}
+
+// propertyNote combines a property's description with its enum and default
+func propertyNote(prop map[string]interface{}) string {
+	var parts []string
+	if description, ok := prop["description"].(string); ok && description != "" {
+		parts = append(parts, strings.Join(strings.Fields(description), " "))
+	}
+	if enum, ok := prop["enum"].([]interface{}); ok {
+		values := make([]string, len(enum))
+		for i, v := range enum {
+			values[i] = compactJSON(v)
+		}
+		parts = append(parts, "one of "+strings.Join(values, ", "))
+	}
+	if def, ok := prop["default"]; ok {
+		parts = append(parts, "default "+compactJSON(def))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func compactJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// printResult prints a tool result's content, indenting text that holds JSON
+func printResult(w io.Writer, result *mcp.ToolsCallResult) {
+	if result.IsError {
+		fmt.Fprintln(w, "Tool returned an error:")
+	}
+	for _, block := range result.Content {
+		if block.Type != "text" {
+			fmt.Fprintf(w, "[%s content]\n", block.Type)
+			continue
+		}
+		fmt.Fprintln(w, prettyText(block.Text))
+	}
+	if len(result.Meta) > 0 {
+		fmt.Fprintf(w, "_meta: %s\n", compactJSON(result.Meta))
+	}
+}
+
+// printReplay prints a replayed call's new result and how it differs from the recorded one
+func printReplay(w io.Writer, replay *mcp.ReplayResult) {
+	fmt.Fprintf(w, "Replayed %s from %s\n", replay.Record.Tool, replay.Record.Time.Format(time.RFC3339))
+	switch {
+	case replay.Error != nil:
+		fmt.Fprintf(w, "Error %d: %s\n", replay.Error.Code, replay.Error.Message)
+	case replay.Result != nil:
+		printResult(w, replay.Result)
+	}
+	if !replay.Changed {
+		fmt.Fprintln(w, "Unchanged from the recorded result")
+		return
+	}
+	fmt.Fprintln(w, "Changed from the recorded result:")
+	for _, line := range replay.Diff {
+		fmt.Fprintf(w, "  %s\n", line)
+	}
+}
+
+// prettyText indents text that is a JSON object or array and returns other text as is
+func prettyText(text string) string {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return text
+	}
+	var v interface{}
+	if json.Unmarshal([]byte(trimmed), &v) != nil {
+		return text
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return text
+	}
+	return string(data)
+}
+
+// printNotification prints one notification per line, prefixed with the time it arrived
+func printNotification(w io.Writer, n mcp.JSONRPCNotification, raw bool) {
+	if raw {
+		data, _ := json.Marshal(n)
+		fmt.Fprintln(w, string(data))
+		return
+	}
+	params := ""
+	if len(n.Params) > 0 {
+		params = " " + compactJSON(json.RawMessage(n.Params))
+	}
+	fmt.Fprintf(w, "%s %s%s\n", time.Now().Format("15:04:05.000"), n.Method, params)
+}
@@ -0,0 +1,338 @@
+// Command minimcp inspects MCP servers. It connects to a server over stdio or HTTP, lists
+// its tools with their parameters, calls tools, and prints notifications, which makes it
+// handy for debugging servers built with this module or any other.
+//
+// Usage:
+//
+//	minimcp [flags] <command> [arguments] [-- server command and arguments]
+//
+// Commands:
+//
+//	info               show the server's name, version, protocol, and capabilities
+//	tools              list tools and their parameters
+//	schema <tool>      print a tool's input and output schemas
+//	call <tool> [json] call a tool with JSON arguments; "-" reads them from stdin
+//	ping               check that the server answers
+//	tail               print notifications until interrupted
+//	replay <id>        replay an audited call and print how its result changed; a
+//	                   destructive tool is replayed only after confirming, or with -yes
+//
+// Examples:
+//
+//	minimcp tools -- go run ./examples/coding_assistant -root=.
+//	minimcp -url http://localhost:8080/mcp -header "Authorization: Bearer $KEY" call get_weather '{"city":"Paris"}'
+//	minimcp -url http://localhost:8080/mcp -header "Authorization: Bearer $KEY" replay 3f9a...
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/mhpenta/minimcp/buildinfo"
+	"github.com/mhpenta/minimcp/client"
+	"github.com/mhpenta/minimcp/mcp"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// headerFlag collects repeated -header "Key: Value" flags
+type headerFlag [][2]string
+
+func (h *headerFlag) String() string {
+	return fmt.Sprint(*h)
+}
+
+func (h *headerFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, ":")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf(`header must be "Key: Value", got %q`, value)
+	}
+	*h = append(*h, [2]string{strings.TrimSpace(key), strings.TrimSpace(val)})
+	return nil
+}
+
+// options are the parsed command line
+type options struct {
+	url     string
+	headers headerFlag
+	timeout time.Duration
+	raw     bool
+	yes     bool
+
+	command string
+	args    []string
+	server  []string
+}
+
+// run executes the command line and returns the exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	opts, err := parseArgs(args, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "minimcp:", err)
+		return 2
+	}
+	if opts == nil {
+		fmt.Fprintln(stdout, buildinfo.Get())
+		return 0
+	}
+
+	conn, err := dial(ctx, opts)
+	if err != nil {
+		fmt.Fprintln(stderr, "minimcp:", err)
+		return 1
+	}
+	c := client.New(conn)
+	defer c.Close()
+
+	initCtx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+	if _, err := c.Initialize(initCtx, mcp.ClientInfo{Name: "minimcp", Version: buildinfo.Get().Version}); err != nil {
+		fmt.Fprintln(stderr, "minimcp: initialize:", err)
+		return 1
+	}
+
+	code, err := execute(ctx, c, opts, stdin, stdout)
+	if err != nil {
+		fmt.Fprintln(stderr, "minimcp:", err)
+	}
+	return code
+}
+
+// parseArgs splits the inspector's flags and command from the server command after "--".
+// It returns nil options when -version was given.
+func parseArgs(args []string, stderr io.Writer) (*options, error) {
+	opts := &options{}
+	for i, arg := range args {
+		if arg == "--" {
+			opts.server = args[i+1:]
+			args = args[:i]
+			break
+		}
+	}
+
+	fs := flag.NewFlagSet("minimcp", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&opts.url, "url", "", "connect to an MCP HTTP endpoint instead of running a server command")
+	fs.Var(&opts.headers, "header", `HTTP header to send, as "Key: Value" (repeatable)`)
+	fs.DurationVar(&opts.timeout, "timeout", 30*time.Second, "how long to wait for each response")
+	fs.BoolVar(&opts.raw, "json", false, "print results as raw JSON")
+	fs.BoolVar(&opts.yes, "yes", false, "replay destructive tools without asking")
+	showVersion := buildinfo.VersionFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: minimcp [flags] <info|tools|schema|call|ping|tail|replay> [arguments] [-- server command]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if *showVersion {
+		return nil, nil
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return nil, errors.New("no command given")
+	}
+	opts.command, opts.args = fs.Arg(0), fs.Args()[1:]
+	switch opts.command {
+	case "info", "tools", "schema", "call", "ping", "tail", "replay":
+	default:
+		return nil, fmt.Errorf("unknown command %q", opts.command)
+	}
+
+	switch {
+	case opts.url == "" && len(opts.server) == 0:
+		return nil, errors.New("give a server command after -- or an HTTP endpoint with -url")
+	case opts.url != "" && len(opts.server) > 0:
+		return nil, errors.New("-url and a server command are mutually exclusive")
+	case len(opts.headers) > 0 && opts.url == "":
+		return nil, errors.New("-header requires -url")
+	}
+	return opts, nil
+}
+
+// dial connects to the server the options describe
+func dial(ctx context.Context, opts *options) (client.Conn, error) {
+	if opts.url != "" {
+		conn := client.NewHTTPConn(opts.url)
+		for _, header := range opts.headers {
+			conn.WithHeader(header[0], header[1])
+		}
+		return conn, nil
+	}
+	return client.StartProcess(exec.CommandContext(ctx, opts.server[0], opts.server[1:]...))
+}
+
+// execute runs one command against an initialized client
+func execute(ctx context.Context, c *client.Client, opts *options, stdin io.Reader, stdout io.Writer) (int, error) {
+	call := func() (context.Context, context.CancelFunc) {
+		return context.WithTimeout(ctx, opts.timeout)
+	}
+
+	switch opts.command {
+	case "info":
+		if opts.raw {
+			return 0, printJSON(stdout, c.ServerInfo())
+		}
+		printInfo(stdout, c.ServerInfo())
+		return 0, nil
+
+	case "tools":
+		callCtx, cancel := call()
+		defer cancel()
+		list, err := c.ListTools(callCtx)
+		if err != nil {
+			return 1, err
+		}
+		if opts.raw {
+			return 0, printJSON(stdout, list)
+		}
+		printTools(stdout, list)
+		return 0, nil
+
+	case "schema":
+		if len(opts.args) != 1 {
+			return 2, errors.New("usage: schema <tool>")
+		}
+		callCtx, cancel := call()
+		defer cancel()
+		list, err := c.ListTools(callCtx)
+		if err != nil {
+			return 1, err
+		}
+		for _, tool := range list {
+			if tool.Name == opts.args[0] {
+				return 0, printJSON(stdout, tool)
+			}
+		}
+		return 1, fmt.Errorf("no tool named %q", opts.args[0])
+
+	case "call":
+		if len(opts.args) < 1 || len(opts.args) > 2 {
+			return 2, errors.New("usage: call <tool> [json arguments]")
+		}
+		arguments, err := readArguments(opts.args[1:], stdin)
+		if err != nil {
+			return 2, err
+		}
+		callCtx, cancel := call()
+		defer cancel()
+		result, err := c.CallTool(callCtx, opts.args[0], arguments)
+		if err != nil {
+			return 1, err
+		}
+		if opts.raw {
+			err = printJSON(stdout, result)
+		} else {
+			printResult(stdout, result)
+		}
+		if result.IsError {
+			return 1, err
+		}
+		return 0, err
+
+	case "ping":
+		callCtx, cancel := call()
+		defer cancel()
+		start := time.Now()
+		if err := c.Ping(callCtx); err != nil {
+			return 1, err
+		}
+		fmt.Fprintf(stdout, "pong in %v\n", time.Since(start).Round(time.Microsecond))
+		return 0, nil
+
+	case "replay":
+		if len(opts.args) != 1 {
+			return 2, errors.New("usage: replay <audit record id>")
+		}
+		params := mcp.AuditReplayParams{ID: opts.args[0], ConfirmDestructive: opts.yes}
+		var replay mcp.ReplayResult
+		callCtx, cancel := call()
+		defer cancel()
+		err := c.Call(callCtx, mcp.MethodAuditReplay, params, &replay)
+		var rpcErr *mcp.RPCError
+		if errors.As(err, &rpcErr) && rpcErr.Code == mcp.ReplayNeedsConfirmation {
+			if !confirm(stdin, stdout, fmt.Sprintf("%s. Replay it anyway? [y/N] ", rpcErr.Message)) {
+				return 1, errors.New("replay not confirmed")
+			}
+			params.ConfirmDestructive = true
+			callCtx, cancel := call()
+			defer cancel()
+			err = c.Call(callCtx, mcp.MethodAuditReplay, params, &replay)
+		}
+		if err != nil {
+			return 1, err
+		}
+		if opts.raw {
+			return 0, printJSON(stdout, replay)
+		}
+		printReplay(stdout, &replay)
+		return 0, nil
+
+	case "tail":
+		notifications := make(chan mcp.JSONRPCNotification, 16)
+		stopped := make(chan struct{})
+		defer close(stopped)
+		c.OnNotification(func(n mcp.JSONRPCNotification) {
+			select {
+			case notifications <- n:
+			case <-stopped:
+			}
+		})
+		for {
+			select {
+			case n := <-notifications:
+				printNotification(stdout, n, opts.raw)
+			case <-c.Done():
+				return 1, c.Err()
+			case <-ctx.Done():
+				return 0, nil
+			}
+		}
+	}
+	return 2, fmt.Errorf("unknown command %q", opts.command)
+}
+
+// confirm asks a yes/no question on stdout and reports whether stdin answered yes
+func confirm(stdin io.Reader, stdout io.Writer, question string) bool {
+	fmt.Fprint(stdout, question)
+	var answer string
+	fmt.Fscanln(stdin, &answer)
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// readArguments returns the call's JSON arguments: the given argument, stdin for "-",
+// or an empty object
+func readArguments(args []string, stdin io.Reader) (json.RawMessage, error) {
+	if len(args) == 0 {
+		return json.RawMessage("{}"), nil
+	}
+	data := []byte(args[0])
+	if args[0] == "-" {
+		var err error
+		if data, err = io.ReadAll(stdin); err != nil {
+			return nil, fmt.Errorf("reading arguments: %w", err)
+		}
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("arguments are not valid JSON: %s", data)
+	}
+	return json.RawMessage(data), nil
+}
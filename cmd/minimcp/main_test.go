@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// TestMain doubles as a stdio MCP server for the inspector to connect to
+func TestMain(m *testing.M) {
+	if os.Getenv("MINIMCP_TEST_SERVER") != "" {
+		server := newTestServer()
+		// Give tail something to print
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			server.AddTools(tools.NewTool("late", "Added after startup", func(ctx context.Context, in struct{}) (string, error) {
+				return "", nil
+			}))
+		}()
+		mcp.NewStdioTransport(server, quietLogger()).Start(context.Background())
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func quietLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+type weatherInput struct {
+	City  string `json:"city" description:"city name"`
+	Units string `json:"units,omitempty" jsonschema:"enum=c|f,default=c"`
+}
+
+func newTestServer() *mcp.Server {
+	weather := tools.NewTool("get_weather", "Fetches the weather", func(ctx context.Context, in weatherInput) (map[string]string, error) {
+		if in.City == "" {
+			return nil, errors.New("city is required")
+		}
+		return map[string]string{"city": in.City, "sky": "sunny"}, nil
+	})
+	return mcp.NewServer(mcp.ServerConfig{Name: "weather", Version: "1.2.3", Tools: []tools.Tool{weather}, Logger: quietLogger()})
+}
+
+// inspect runs the inspector and returns its exit code and output
+func inspect(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	code := run(ctx, args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// stdioServer returns the arguments that run the test binary as a server
+func stdioServer(t *testing.T) []string {
+	t.Helper()
+	t.Setenv("MINIMCP_TEST_SERVER", "1")
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []string{"--", executable}
+}
+
+func TestInspector_Stdio(t *testing.T) {
+	server := stdioServer(t)
+
+	code, out, errOut := inspect(t, "", append([]string{"info"}, server...)...)
+	if code != 0 || !strings.Contains(out, "Server:   weather 1.2.3") || !strings.Contains(out, "tools") {
+		t.Errorf("info: exit %d, stdout %q, stderr %q", code, out, errOut)
+	}
+
+	code, out, errOut = inspect(t, "", append([]string{"tools"}, server...)...)
+	if code != 0 {
+		t.Fatalf("tools: exit %d, stderr %q", code, errOut)
+	}
+	for _, want := range []string{"get_weather", "Fetches the weather", "city", "required", "city name", `one of "c", "f"; default "c"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("tools: expected %q in:\n%s", want, out)
+		}
+	}
+
+	code, out, errOut = inspect(t, "", append([]string{"call", "get_weather", `{"city":"Paris"}`}, server...)...)
+	if code != 0 || !strings.Contains(out, `"sky": "sunny"`) {
+		t.Errorf("call: exit %d, stdout %q, stderr %q", code, out, errOut)
+	}
+
+	// Arguments from stdin, and a tool failure exits non-zero
+	code, out, _ = inspect(t, `{"city":""}`, append([]string{"call", "get_weather", "-"}, server...)...)
+	if code != 1 || !strings.Contains(out, "Tool returned an error") || !strings.Contains(out, "city is required") {
+		t.Errorf("failing call: exit %d, stdout %q", code, out)
+	}
+
+	code, out, _ = inspect(t, "", append([]string{"-json", "schema", "get_weather"}, server...)...)
+	if code != 0 || !strings.Contains(out, `"inputSchema"`) {
+		t.Errorf("schema: exit %d, stdout %q", code, out)
+	}
+
+	code, _, errOut = inspect(t, "", append([]string{"call", "missing"}, server...)...)
+	if code != 1 || !strings.Contains(errOut, "Tool not found") {
+		t.Errorf("unknown tool: exit %d, stderr %q", code, errOut)
+	}
+}
+
+// cancelOnWrite cancels a context once anything is written
+type cancelOnWrite struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	cancel context.CancelFunc
+}
+
+func (w *cancelOnWrite) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	defer w.cancel()
+	return w.buf.Write(p)
+}
+
+func TestInspector_Tail(t *testing.T) {
+	server := stdioServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stdout := &cancelOnWrite{cancel: cancel}
+	var stderr bytes.Buffer
+	code := run(ctx, append([]string{"tail"}, server...), strings.NewReader(""), stdout, &stderr)
+
+	stdout.mu.Lock()
+	defer stdout.mu.Unlock()
+	if code != 0 || !strings.Contains(stdout.buf.String(), mcp.MethodNotificationToolsListChanged) {
+		t.Errorf("tail: exit %d, stdout %q, stderr %q", code, stdout.buf.String(), stderr.String())
+	}
+}
+
+func TestInspector_HTTP(t *testing.T) {
+	transport := mcp.NewHTTPTransport(newTestServer(), quietLogger(), mcp.NewConstantTimeStaticValidator("secret"))
+	httpServer := httptest.NewServer(transport)
+	defer httpServer.Close()
+	url := httpServer.URL + "/mcp"
+
+	code, out, errOut := inspect(t, "", "-url", url, "-header", "Authorization: Bearer secret", "ping")
+	if code != 0 || !strings.HasPrefix(out, "pong in ") {
+		t.Errorf("ping: exit %d, stdout %q, stderr %q", code, out, errOut)
+	}
+
+	code, out, _ = inspect(t, "", "-url", url, "-header", "Authorization: Bearer secret", "-json", "call", "get_weather", `{"city":"Oslo"}`)
+	if code != 0 || !strings.Contains(out, `"content"`) || !strings.Contains(out, "Oslo") {
+		t.Errorf("call: exit %d, stdout %q", code, out)
+	}
+
+	if code, _, _ := inspect(t, "", "-url", url, "ping"); code != 1 {
+		t.Errorf("expected unauthenticated ping to fail, got exit %d", code)
+	}
+}
+
+// auditor authenticates every key as a caller allowed to replay audited calls
+type auditor struct{}
+
+func (auditor) Validate(ctx context.Context, key string) bool { return true }
+
+func (auditor) Authenticate(ctx context.Context, key string) (*mcp.Principal, error) {
+	return &mcp.Principal{Subject: "auditor", Scopes: []string{mcp.AuditScope}}, nil
+}
+
+func TestInspector_Replay(t *testing.T) {
+	count := 0
+	bump := tools.NewTool("bump", "Bumps a counter", func(ctx context.Context, in struct{}) (int, error) {
+		count++
+		return count, nil
+	}, tools.WithDestructive(true))
+	server := mcp.NewServer(mcp.ServerConfig{Name: "counter", Tools: []tools.Tool{bump}, AuditLog: mcp.NewMemoryAuditLog(10), Logger: quietLogger()})
+	httpServer := httptest.NewServer(mcp.NewHTTPTransport(server, quietLogger(), auditor{}))
+	defer httpServer.Close()
+	url := httpServer.URL + "/mcp"
+
+	code, out, errOut := inspect(t, "", "-url", url, "-json", "call", "bump")
+	var result mcp.ToolsCallResult
+	if code != 0 || json.Unmarshal([]byte(out), &result) != nil {
+		t.Fatalf("call: exit %d, stdout %q, stderr %q", code, out, errOut)
+	}
+	id, _ := result.Meta[mcp.AuditRecordMetaKey].(string)
+	if id == "" {
+		t.Fatalf("expected the result to carry its audit record ID, got %v", result.Meta)
+	}
+
+	if code, out, _ := inspect(t, "n\n", "-url", url, "replay", id); code != 1 || !strings.Contains(out, "Replay it anyway?") {
+		t.Errorf("expected a declined replay to fail after asking, got exit %d: %q", code, out)
+	}
+	code, out, errOut = inspect(t, "y\n", "-url", url, "replay", id)
+	if code != 0 || !strings.Contains(out, "Changed from the recorded result") || !strings.Contains(out, `recorded "1", replayed "2"`) {
+		t.Errorf("replay: exit %d, stdout %q, stderr %q", code, out, errOut)
+	}
+	if code, out, errOut := inspect(t, "", "-url", url, "-yes", "replay", id); code != 0 || strings.Contains(out, "Replay it anyway?") {
+		t.Errorf("expected -yes to skip the question, got exit %d: %q %q", code, out, errOut)
+	}
+}
+
+func TestInspector_Usage(t *testing.T) {
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"tools"}, "give a server command"},
+		{[]string{"-url", "http://localhost/mcp", "tools", "--", "server"}, "mutually exclusive"},
+		{[]string{"-header", "X-Key: 1", "tools", "--", "server"}, "-header requires -url"},
+		{[]string{"-header", "no-colon", "tools"}, "Key: Value"},
+		{[]string{"frobnicate", "--", "server"}, `unknown command "frobnicate"`},
+		{[]string{"--", "server"}, "no command given"},
+	}
+	for _, tc := range cases {
+		code, _, errOut := inspect(t, "", tc.args...)
+		if code != 2 || !strings.Contains(errOut, tc.want) {
+			t.Errorf("%v: expected exit 2 with %q, got %d: %s", tc.args, tc.want, code, errOut)
+		}
+	}
+
+	if code, out, _ := inspect(t, "", "-version"); code != 0 || out == "" {
+		t.Errorf("expected -version to print build information, got %d %q", code, out)
+	}
+}
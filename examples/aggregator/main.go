@@ -0,0 +1,277 @@
+// Command aggregator fronts several MCP servers as one. It lists the tools of each
+// upstream HTTP server, re-exposes them prefixed with the upstream's name, and forwards
+// calls. Upstreams are re-listed periodically; tools that appear or disappear are added
+// to or removed from the registry, and connected clients are told the list changed.
+//
+//	UPSTREAM_KEY=secret go run ./examples/aggregator \
+//	    -upstream search=https://search.internal/mcp -upstream db=https://db.internal/mcp
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// toolSeparator joins an upstream's name and its tool's name
+const toolSeparator = "__"
+
+// upstreamFlags collects repeated -upstream name=url flags
+type upstreamFlags map[string]string
+
+func (f upstreamFlags) String() string { return fmt.Sprint(map[string]string(f)) }
+
+func (f upstreamFlags) Set(value string) error {
+	name, url, ok := strings.Cut(value, "=")
+	if !ok || name == "" || url == "" {
+		return fmt.Errorf("expected name=url, got %q", value)
+	}
+	f[name] = url
+	return nil
+}
+
+func main() {
+	upstreams := upstreamFlags{}
+	flag.Var(upstreams, "upstream", "upstream MCP server as name=url (repeatable)")
+	refresh := flag.Duration("refresh", time.Minute, "how often upstream tool lists are refreshed")
+	flag.Parse()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	agg := newAggregator(logger)
+	for name, url := range upstreams {
+		agg.addUpstream(name, url, os.Getenv("UPSTREAM_KEY"))
+	}
+	if err := agg.refresh(ctx); err != nil {
+		logger.Warn("initial refresh incomplete", "error", err)
+	}
+	go agg.refreshEvery(ctx, *refresh)
+
+	if err := mcp.NewStdioTransport(agg.server, logger).Start(ctx); err != nil {
+		logger.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// aggregator keeps a server's registry in step with its upstreams' tools
+type aggregator struct {
+	server    *mcp.Server
+	logger    *slog.Logger
+	upstreams map[string]*upstream
+
+	mu sync.Mutex
+
+	// proxied maps each registered tool to its upstream description, so refreshes only
+	// re-register tools that changed
+	proxied map[string]string
+}
+
+func newAggregator(logger *slog.Logger) *aggregator {
+	return &aggregator{
+		server: mcp.NewServer(mcp.ServerConfig{
+			Name:    "aggregator",
+			Version: "1.0.0",
+			Logger:  logger,
+		}),
+		logger:    logger,
+		upstreams: make(map[string]*upstream),
+		proxied:   make(map[string]string),
+	}
+}
+
+func (a *aggregator) addUpstream(name, url, key string) {
+	a.upstreams[name] = &upstream{
+		name:   name,
+		url:    url,
+		key:    key,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (a *aggregator) refreshEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.refresh(ctx); err != nil {
+				a.logger.Warn("refresh incomplete", "error", err)
+			}
+		}
+	}
+}
+
+// refresh re-lists every upstream and registers the difference. An upstream that cannot
+// be reached keeps its previously listed tools until it answers again.
+func (a *aggregator) refresh(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var errs []error
+	current := make(map[string]string)
+	var changed []tools.Tool
+	for name, up := range a.upstreams {
+		descriptions, err := up.listTools(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("listing %s: %w", name, err))
+			for proxied, fingerprint := range a.proxied {
+				if strings.HasPrefix(proxied, name+toolSeparator) {
+					current[proxied] = fingerprint
+				}
+			}
+			continue
+		}
+		for _, d := range descriptions {
+			tool := newProxyTool(up, d)
+			fingerprint, _ := json.Marshal(d)
+			current[tool.spec.Name] = string(fingerprint)
+			if a.proxied[tool.spec.Name] != string(fingerprint) {
+				changed = append(changed, tool)
+			}
+		}
+	}
+
+	var stale []string
+	for name := range a.proxied {
+		if _, ok := current[name]; !ok {
+			stale = append(stale, name)
+		}
+	}
+	if len(stale) > 0 {
+		a.server.RemoveTools(stale...)
+	}
+	if len(changed) > 0 {
+		if err := a.server.AddTools(changed...); err != nil {
+			errs = append(errs, err)
+			// Keep what is actually registered so the next refresh retries
+			for _, tool := range changed {
+				name := tool.Spec().Name
+				if previous, ok := a.proxied[name]; ok {
+					current[name] = previous
+				} else {
+					delete(current, name)
+				}
+			}
+		}
+	}
+	a.proxied = current
+	return errors.Join(errs...)
+}
+
+// upstream is a minimal JSON-RPC client for an MCP server's HTTP endpoint
+type upstream struct {
+	name   string
+	url    string
+	key    string
+	client *http.Client
+	nextID atomic.Int64
+}
+
+func (u *upstream) rpc(ctx context.Context, method string, params, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      u.nextID.Add(1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if u.key != "" {
+		req.Header.Set("Authorization", "Bearer "+u.key)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("upstream returned %s", resp.Status)
+	}
+
+	var rpcResp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *mcp.RPCError   `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return err
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("upstream error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+func (u *upstream) listTools(ctx context.Context) ([]mcp.ToolDescription, error) {
+	var result mcp.ToolsListResult
+	if err := u.rpc(ctx, mcp.MethodToolsList, map[string]interface{}{}, &result); err != nil {
+		return nil, err
+	}
+	return result.Tools, nil
+}
+
+// proxyTool forwards calls to a tool on an upstream server
+type proxyTool struct {
+	spec     *tools.ToolSpec
+	upstream *upstream
+	remote   string
+}
+
+func newProxyTool(up *upstream, d mcp.ToolDescription) *proxyTool {
+	spec := &tools.ToolSpec{
+		Name:        up.name + toolSeparator + d.Name,
+		Description: fmt.Sprintf("[%s] %s", up.name, d.Description),
+		Parameters:  d.InputSchema,
+		Output:      d.OutputSchema,
+		Category:    up.name,
+	}
+	if d.Annotations != nil && d.Annotations.DestructiveHint != nil {
+		spec.Destructive = *d.Annotations.DestructiveHint
+	}
+	return &proxyTool{spec: spec, upstream: up, remote: d.Name}
+}
+
+func (p *proxyTool) Spec() *tools.ToolSpec {
+	return p.spec
+}
+
+func (p *proxyTool) Execute(ctx context.Context, params json.RawMessage) (*tools.ToolResult, error) {
+	var result mcp.ToolsCallResult
+	err := p.upstream.rpc(ctx, mcp.MethodToolsCall, mcp.ToolsCallParams{Name: p.remote, Arguments: params}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	var text strings.Builder
+	for _, block := range result.Content {
+		text.WriteString(block.Text)
+	}
+	if result.IsError {
+		return nil, errors.New(text.String())
+	}
+	return &tools.ToolResult{Output: text.String()}, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/tools"
+)
+
+type echoInput struct {
+	Text string `json:"text"`
+}
+
+func newUpstream(t *testing.T, logger *slog.Logger, ts ...tools.Tool) (*mcp.Server, string) {
+	t.Helper()
+	server := mcp.NewServer(mcp.ServerConfig{Name: "upstream", Tools: ts, Logger: logger})
+	transport := mcp.NewHTTPTransport(server, logger, mcp.NewConstantTimeStaticValidator("upstream-key"))
+	srv := httptest.NewServer(transport)
+	t.Cleanup(srv.Close)
+	return server, srv.URL + "/mcp"
+}
+
+func toolNames(s *mcp.Server) []string {
+	var names []string
+	for _, tool := range s.GetTools() {
+		names = append(names, tool.Spec().Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestAggregator(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	echo := tools.NewTool("echo", "Echoes text", func(ctx context.Context, in echoInput) (string, error) {
+		return "echo: " + in.Text, nil
+	})
+	shout := tools.NewTool("shout", "Shouts text", func(ctx context.Context, in echoInput) (string, error) {
+		return strings.ToUpper(in.Text), nil
+	})
+
+	search, searchURL := newUpstream(t, logger, echo)
+	_, dbURL := newUpstream(t, logger, shout)
+
+	agg := newAggregator(logger)
+	agg.addUpstream("search", searchURL, "upstream-key")
+	agg.addUpstream("db", dbURL, "upstream-key")
+	if err := agg.refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if names := strings.Join(toolNames(agg.server), ","); names != "db__shout,search__echo" {
+		t.Fatalf("unexpected aggregated tools %s", names)
+	}
+
+	handler := mcp.NewJSONRPCHandler(agg.server)
+	resp, err := handler.HandleMessage(context.Background(), []byte(
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search__echo","arguments":{"text":"hi"}}}`))
+	if err != nil || resp.Error != nil {
+		t.Fatalf("forwarded call failed: %v %v", err, resp.Error)
+	}
+	if text := resp.Result.(mcp.ToolsCallResult).Content[0].Text; text != "echo: hi" {
+		t.Errorf("expected the upstream's output, got %q", text)
+	}
+
+	// Upstream tool changes reach the aggregate registry on the next refresh
+	var revisions []uint64
+	agg.server.OnToolsChanged(func(revision uint64) { revisions = append(revisions, revision) })
+	search.RemoveTools("echo")
+	search.AddTools(shout)
+	if err := agg.refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if names := strings.Join(toolNames(agg.server), ","); names != "db__shout,search__shout" {
+		t.Errorf("expected refreshed tools, got %s", names)
+	}
+	if len(revisions) == 0 {
+		t.Error("expected clients to be notified of the change")
+	}
+
+	// Unchanged upstreams do not churn the registry
+	revision := agg.server.ToolsRevision()
+	if err := agg.refresh(context.Background()); err != nil || agg.server.ToolsRevision() != revision {
+		t.Errorf("expected an unchanged refresh to keep revision %d, got %d (%v)", revision, agg.server.ToolsRevision(), err)
+	}
+
+	// An unreachable upstream keeps its last known tools
+	agg.addUpstream("db", "http://127.0.0.1:1/mcp", "upstream-key")
+	if err := agg.refresh(context.Background()); err == nil {
+		t.Error("expected an error for the unreachable upstream")
+	}
+	if names := strings.Join(toolNames(agg.server), ","); names != "db__shout,search__shout" {
+		t.Errorf("expected tools to be kept while the upstream is down, got %s", names)
+	}
+}
@@ -0,0 +1,233 @@
+// Command coding_assistant serves a repository to coding agents over stdio: files can be
+// listed, read, searched and written, and git history inspected through a single router
+// tool. Every path is confined to the repository root.
+//
+//	go run ./examples/coding_assistant -root=/path/to/repo
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/tools"
+	"github.com/mhpenta/minimcp/tools/mcpctx"
+)
+
+// maxReadBytes caps how much of a file read_file returns
+const maxReadBytes = 256 * 1024
+
+// PathRequest names a file or directory relative to the repository root
+type PathRequest struct {
+	Path string `json:"path" jsonschema:"path relative to the repository root"`
+}
+
+// WriteRequest is the input of write_file
+type WriteRequest struct {
+	Path    string `json:"path" jsonschema:"path relative to the repository root"`
+	Content string `json:"content" jsonschema:"complete new content of the file"`
+}
+
+// GrepRequest is the input of grep
+type GrepRequest struct {
+	Pattern string `json:"pattern" jsonschema:"regular expression to search for"`
+	Path    string `json:"path,omitempty" jsonschema:"directory to search, defaults to the repository root"`
+}
+
+// LogRequest is the input of the git log operation
+type LogRequest struct {
+	Limit int `json:"limit,omitempty" jsonschema:"number of commits to show, default 10"`
+}
+
+// DiffRequest is the input of the git diff operation
+type DiffRequest struct {
+	Path string `json:"path,omitempty" jsonschema:"limit the diff to this path"`
+}
+
+func main() {
+	root := flag.String("root", ".", "repository root to serve")
+	flag.Parse()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	server, err := newServer(*root, logger)
+	if err == nil {
+		err = mcp.NewStdioTransport(server, logger).Start(ctx)
+	}
+	if err != nil {
+		logger.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// repo resolves tool paths inside a repository root
+type repo struct {
+	root string
+}
+
+// resolve maps a relative path to an absolute one that cannot escape the root
+func (r repo) resolve(path string) string {
+	return filepath.Join(r.root, filepath.Clean("/"+path))
+}
+
+// newServer builds the coding assistant server for the repository at root
+func newServer(root string, logger *slog.Logger) (*mcp.Server, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	r := repo{root: abs}
+
+	git, err := tools.NewRouterTool("git", "Inspects the repository's git history", []tools.Tool{
+		tools.NewTool("status", "Shows the working tree status", func(ctx context.Context, _ struct{}) (string, error) {
+			return r.git(ctx, "status", "--short", "--branch")
+		}),
+		tools.NewTool("log", "Shows recent commits", func(ctx context.Context, req LogRequest) (string, error) {
+			if req.Limit <= 0 {
+				req.Limit = 10
+			}
+			return r.git(ctx, "log", "--oneline", fmt.Sprintf("-n%d", req.Limit))
+		}),
+		tools.NewTool("diff", "Shows uncommitted changes", func(ctx context.Context, req DiffRequest) (string, error) {
+			args := []string{"diff"}
+			if req.Path != "" {
+				args = append(args, "--", r.resolve(req.Path))
+			}
+			return r.git(ctx, args...)
+		}),
+	}, tools.WithCategory("git"))
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewServer(mcp.ServerConfig{
+		Name:    "coding-assistant",
+		Version: "1.0.0",
+		Tools: []tools.Tool{
+			tools.NewTool("read_file", "Reads a file from the repository", r.readFile,
+				tools.WithCategory("files"), tools.WithPriority(10)),
+			tools.NewTool("list_dir", "Lists a directory in the repository", r.listDir,
+				tools.WithCategory("files"), tools.WithPriority(10)),
+			tools.NewStreamingTool("grep", "Searches repository files for a regular expression, streaming matches as file:line: text", r.grep,
+				tools.WithCategory("files")),
+			tools.NewTool("write_file", "Replaces the content of a file in the repository", r.writeFile,
+				tools.WithCategory("files"), tools.WithDestructive(true)),
+			git,
+		},
+		Logger:             logger,
+		DefaultToolTimeout: time.Minute,
+		ClientProfiles: map[string]mcp.ClientProfile{
+			// Agents that review changes want history first
+			"reviewer": {CategoryPriority: map[string]int{"git": 100}},
+		},
+	}), nil
+}
+
+func (r repo) readFile(ctx context.Context, req PathRequest) (string, error) {
+	f, err := os.Open(r.resolve(req.Path))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	buf := make([]byte, maxReadBytes+1)
+	n, err := f.Read(buf)
+	if err != nil && n == 0 {
+		return "", err
+	}
+	if n > maxReadBytes {
+		return string(buf[:maxReadBytes]) + "\n[truncated]", nil
+	}
+	return string(buf[:n]), nil
+}
+
+func (r repo) listDir(ctx context.Context, req PathRequest) ([]string, error) {
+	entries, err := os.ReadDir(r.resolve(req.Path))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (r repo) writeFile(ctx context.Context, req WriteRequest) (string, error) {
+	path := r.resolve(req.Path)
+	if err := os.WriteFile(path, []byte(req.Content), 0o644); err != nil {
+		return "", err
+	}
+	mcpctx.Logger(ctx).Info("file written", "path", path, "bytes", len(req.Content))
+	return fmt.Sprintf("wrote %d bytes to %s", len(req.Content), req.Path), nil
+}
+
+func (r repo) grep(ctx context.Context, req GrepRequest, emit tools.EmitFunc) error {
+	pattern, err := regexp.Compile(req.Pattern)
+	if err != nil {
+		return tools.NewInvalidParamsError(fmt.Sprintf("invalid pattern: %v", err))
+	}
+
+	return filepath.WalkDir(r.resolve(req.Path), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		rel, _ := filepath.Rel(r.root, path)
+		scanner := bufio.NewScanner(f)
+		for line := 1; scanner.Scan(); line++ {
+			if pattern.MatchString(scanner.Text()) {
+				if err := emit(fmt.Sprintf("%s:%d: %s\n", rel, line, scanner.Text())); err != nil {
+					return err
+				}
+			}
+		}
+		// Binary files and overlong lines are skipped rather than failing the search
+		if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+			return nil
+		}
+		return scanner.Err()
+	})
+}
+
+// git runs a read-only git command in the repository
+func (r repo) git(ctx context.Context, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", append([]string{"-C", r.root}, args...)...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mhpenta/minimcp/mcp"
+)
+
+func callTool(t *testing.T, handler *mcp.JSONRPCHandler, name, args string) mcp.ToolsCallResult {
+	t.Helper()
+	resp, err := handler.HandleMessage(context.Background(), []byte(
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"`+name+`","arguments":`+args+`}}`))
+	if err != nil || resp.Error != nil {
+		t.Fatalf("%s failed: %v %v", name, err, resp.Error)
+	}
+	return resp.Result.(mcp.ToolsCallResult)
+}
+
+func TestCodingAssistantServer(t *testing.T) {
+	root := t.TempDir()
+	os.Mkdir(filepath.Join(root, "pkg"), 0o755)
+	os.WriteFile(filepath.Join(root, "pkg", "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644)
+	os.WriteFile(filepath.Join(root, "README.md"), []byte("# demo\nmain entry point\n"), 0o644)
+
+	server, err := newServer(root, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("newServer failed: %v", err)
+	}
+	handler := mcp.NewJSONRPCHandler(server)
+
+	if text := callTool(t, handler, "list_dir", `{"path":""}`).Content[0].Text; !strings.Contains(text, "pkg/") {
+		t.Errorf("expected pkg/ in listing, got %s", text)
+	}
+	if text := callTool(t, handler, "read_file", `{"path":"pkg/main.go"}`).Content[0].Text; !strings.Contains(text, "package main") {
+		t.Errorf("unexpected file content %s", text)
+	}
+	if result := callTool(t, handler, "read_file", `{"path":"../../etc/passwd"}`); !result.IsError {
+		t.Error("expected paths outside the root to be confined to it")
+	}
+
+	text := callTool(t, handler, "grep", `{"pattern":"main"}`).Content[0].Text
+	if !strings.Contains(text, "README.md:2: main entry point") || !strings.Contains(text, filepath.Join("pkg", "main.go")+":1: package main") {
+		t.Errorf("unexpected grep output %q", text)
+	}
+
+	callTool(t, handler, "write_file", `{"path":"notes.txt","content":"hello"}`)
+	if data, _ := os.ReadFile(filepath.Join(root, "notes.txt")); string(data) != "hello" {
+		t.Errorf("expected file to be written, got %q", data)
+	}
+
+	resp, _ := handler.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`))
+	listing, _ := json.Marshal(resp.Result)
+	if !strings.Contains(string(listing), `"destructiveHint":true`) {
+		t.Errorf("expected write_file to be marked destructive, got %s", listing)
+	}
+}
+
+func TestCodingAssistantServer_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial commit"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	server, err := newServer(root, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("newServer failed: %v", err)
+	}
+	handler := mcp.NewJSONRPCHandler(server)
+
+	if text := callTool(t, handler, "git", `{"operation":"log","arguments":{"limit":1}}`).Content[0].Text; !strings.Contains(text, "initial commit") {
+		t.Errorf("expected the commit in git log, got %q", text)
+	}
+	if result := callTool(t, handler, "git", `{"operation":"status","arguments":{}}`); result.IsError {
+		t.Errorf("git status failed: %+v", result)
+	}
+}
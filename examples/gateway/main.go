@@ -0,0 +1,80 @@
+// Command gateway serves every MCP server listed in a Claude Desktop configuration file
+// through a single stdio server. Move the servers out of claude_desktop_config.json into
+// their own file and leave one entry behind:
+//
+//	{"mcpServers": {"gateway": {"command": "gateway", "args": ["-config", "/path/to/servers.json"]}}}
+//
+// Tools are renamed "<server>__<tool>" by default; -prefix collisions keeps original
+// names unless two servers offer the same one. Servers that fail to start are retried in
+// the background and their tools appear once they answer.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/mhpenta/minimcp/gateway"
+	"github.com/mhpenta/minimcp/mcp"
+)
+
+func main() {
+	configPath := flag.String("config", "", "Claude Desktop style configuration listing the upstream servers")
+	prefix := flag.String("prefix", "all", `which tools to prefix with their server's name: "all" or "collisions"`)
+	retry := flag.Duration("retry", gateway.DefaultRetryInterval, "how long to wait before reconnecting a failed server")
+	flag.Parse()
+
+	// Stdout carries the protocol, so logs go to stderr
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	gw, err := newGateway(*configPath, *prefix, *retry, logger)
+	if err != nil {
+		logger.Error("invalid configuration", "error", err)
+		os.Exit(2)
+	}
+	if err := gw.Start(ctx); err != nil {
+		logger.Warn("some servers are unavailable", "error", err)
+	}
+	defer gw.Close()
+
+	if err := mcp.NewStdioTransport(gw.Server(), logger).Start(ctx); err != nil {
+		logger.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// newGateway builds a gateway over the servers of the configuration file
+func newGateway(configPath, prefix string, retry time.Duration, logger *slog.Logger) (*gateway.Gateway, error) {
+	if configPath == "" {
+		return nil, fmt.Errorf("-config is required")
+	}
+	upstreams, err := gateway.LoadClaudeDesktopConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var mode gateway.PrefixMode
+	switch prefix {
+	case "all":
+		mode = gateway.PrefixAll
+	case "collisions":
+		mode = gateway.PrefixCollisions
+	default:
+		return nil, fmt.Errorf(`-prefix must be "all" or "collisions", got %q`, prefix)
+	}
+
+	return gateway.New(gateway.Config{
+		Name:          "gateway",
+		Version:       "1.0.0",
+		Upstreams:     upstreams,
+		PrefixMode:    mode,
+		RetryInterval: retry,
+		Logger:        logger,
+	}), nil
+}
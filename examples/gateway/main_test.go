@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/tools"
+)
+
+type lookupInput struct {
+	Key string `json:"key"`
+}
+
+func startUpstream(t *testing.T, name string, toolNames ...string) string {
+	t.Helper()
+	var ts []tools.Tool
+	for _, toolName := range toolNames {
+		ts = append(ts, tools.NewTool(toolName, "Looks up a key", func(ctx context.Context, in lookupInput) (string, error) {
+			return name + ":" + in.Key, nil
+		}))
+	}
+	server := mcp.NewServer(mcp.ServerConfig{Name: name, Version: "1.0.0", Tools: ts, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	httpServer := httptest.NewServer(mcp.NewHTTPTransport(server, nil, mcp.NewConstantTimeStaticValidator("key-"+name)))
+	t.Cleanup(httpServer.Close)
+	return httpServer.URL + "/mcp"
+}
+
+func TestGateway(t *testing.T) {
+	config := fmt.Sprintf(`{"mcpServers": {
+		"wiki":   {"url": %q, "headers": {"Authorization": "Bearer key-wiki"}},
+		"crm":    {"url": %q, "headers": {"Authorization": "Bearer key-crm"}},
+		"broken": {"command": "/nonexistent/server"}
+	}}`, startUpstream(t, "wiki", "lookup", "search"), startUpstream(t, "crm", "lookup"))
+	path := filepath.Join(t.TempDir(), "servers.json")
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	gw, err := newGateway(path, "collisions", time.Hour, logger)
+	if err != nil {
+		t.Fatalf("newGateway failed: %v", err)
+	}
+	if err := gw.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected the broken server to be reported, got %v", err)
+	}
+	defer gw.Close()
+
+	var names []string
+	for _, tool := range gw.Server().GetTools() {
+		names = append(names, tool.Spec().Name)
+	}
+	sort.Strings(names)
+	if got := strings.Join(names, ","); got != "crm__lookup,search,wiki__lookup" {
+		t.Errorf("unexpected tools: %s", got)
+	}
+
+	result, err := tools.Call[lookupInput, string](context.Background(), gw.Server().GetTools()[0], lookupInput{Key: "k"})
+	if err != nil || !strings.HasSuffix(result, ":k") {
+		t.Errorf("expected the call to reach its upstream, got %q, %v", result, err)
+	}
+
+	if _, err := newGateway(path, "sometimes", time.Hour, logger); err == nil {
+		t.Error("expected an invalid -prefix to be rejected")
+	}
+	if _, err := newGateway("", "all", time.Hour, logger); err == nil {
+		t.Error("expected a missing -config to be rejected")
+	}
+}
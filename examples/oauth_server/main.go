@@ -0,0 +1,123 @@
+// Command oauth_server is a remote MCP server protected by OAuth 2.1. Access tokens issued
+// by the authorization server are verified against its JWKS; OAuth-capable clients
+// discover it through the protected resource metadata. Each caller gets a private
+// notebook, and writing requires the notes:write scope.
+//
+//	go run ./examples/oauth_server -port=8443 \
+//	    -resource=https://notes.example.com/mcp \
+//	    -issuer=https://auth.example.com \
+//	    -jwks=https://auth.example.com/.well-known/jwks.json
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/tools"
+	"github.com/mhpenta/minimcp/tools/mcpctx"
+)
+
+const (
+	scopeRead  = "notes:read"
+	scopeWrite = "notes:write"
+)
+
+// AddNoteRequest is the input of add_note
+type AddNoteRequest struct {
+	Text string `json:"text" jsonschema:"the note to save"`
+}
+
+// config holds the settings of an OAuth-protected notes server
+type config struct {
+	resource string
+	issuer   string
+	jwksURL  string
+}
+
+func main() {
+	var cfg config
+	port := flag.String("port", "8443", "port to listen on")
+	flag.StringVar(&cfg.resource, "resource", "", "canonical URI of this server, e.g. https://notes.example.com/mcp")
+	flag.StringVar(&cfg.issuer, "issuer", "", "issuer URL of the authorization server")
+	flag.StringVar(&cfg.jwksURL, "jwks", "", "JWKS URL of the authorization server")
+	flag.Parse()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if cfg.resource == "" || cfg.issuer == "" || cfg.jwksURL == "" {
+		logger.Error("-resource, -issuer and -jwks are required")
+		os.Exit(2)
+	}
+	if err := newHandler(cfg, logger).Start(ctx, *port); err != nil {
+		logger.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// newHandler builds the HTTP transport serving the notes tools
+func newHandler(cfg config, logger *slog.Logger) *mcp.HTTPTransport {
+	notes := &notebooks{bySubject: make(map[string][]string)}
+	server := mcp.NewServer(mcp.ServerConfig{
+		Name:    "notes",
+		Version: "1.0.0",
+		Tools: []tools.Tool{
+			tools.NewTool("list_notes", "Lists your saved notes", notes.list,
+				tools.WithRequiredScopes(scopeRead)),
+			tools.NewTool("add_note", "Saves a note", notes.add,
+				tools.WithRequiredScopes(scopeWrite)),
+		},
+		Logger: logger,
+	})
+
+	validator := mcp.NewJWTValidator(mcp.JWTValidatorConfig{
+		JWKSURL:  cfg.jwksURL,
+		Issuer:   cfg.issuer,
+		Audience: cfg.resource,
+	})
+	return mcp.NewHTTPTransport(server, logger, validator).
+		WithRESTEndpoints(false).
+		WithOAuth(mcp.OAuthConfig{
+			Resource:             cfg.resource,
+			AuthorizationServers: []string{cfg.issuer},
+			ScopesSupported:      []string{scopeRead, scopeWrite},
+		})
+}
+
+// errNoCaller is returned when a tool runs without an authenticated caller
+var errNoCaller = errors.New("no authenticated caller")
+
+// notebooks stores notes per token subject
+type notebooks struct {
+	mu        sync.Mutex
+	bySubject map[string][]string
+}
+
+func (n *notebooks) list(ctx context.Context, _ struct{}) ([]string, error) {
+	caller := mcpctx.Principal(ctx)
+	if caller == nil {
+		return nil, errNoCaller
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string{}, n.bySubject[caller.Subject]...), nil
+}
+
+func (n *notebooks) add(ctx context.Context, req AddNoteRequest) (string, error) {
+	caller := mcpctx.Principal(ctx)
+	if caller == nil {
+		return "", errNoCaller
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.bySubject[caller.Subject] = append(n.bySubject[caller.Subject], req.Text)
+	mcpctx.Logger(ctx).Info("note saved", "subject", caller.Subject)
+	return "saved", nil
+}
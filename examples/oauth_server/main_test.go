@@ -0,0 +1,121 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testIssuer   = "https://auth.example.com"
+	testResource = "https://notes.example.com/mcp"
+)
+
+// authServer stands in for the authorization server, publishing a JWKS and minting tokens
+type authServer struct {
+	key  *rsa.PrivateKey
+	jwks *httptest.Server
+}
+
+func newAuthServer(t *testing.T) *authServer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks := map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "k1", "use": "sig", "n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes())},
+	}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jwks)
+	}))
+	t.Cleanup(srv.Close)
+	return &authServer{key: key, jwks: srv}
+}
+
+func (a *authServer) token(t *testing.T, subject, scope string) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k1", "typ": "JWT"})
+	payload, _ := json.Marshal(map[string]interface{}{
+		"sub":   subject,
+		"iss":   testIssuer,
+		"aud":   testResource,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": scope,
+	})
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("signing: %v", err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestOAuthServer(t *testing.T) {
+	auth := newAuthServer(t)
+	handler := newHandler(config{resource: testResource, issuer: testIssuer, jwksURL: auth.jwks.URL},
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	post := func(token, body string) (*http.Response, string) {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/mcp", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp, string(data)
+	}
+	call := func(tool, args string) string {
+		return `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"` + tool + `","arguments":` + args + `}}`
+	}
+
+	// Unauthenticated clients are pointed at the resource metadata
+	resp, _ := post("", call("list_notes", `{}`))
+	if resp.StatusCode != http.StatusUnauthorized || !strings.Contains(resp.Header.Get("WWW-Authenticate"), "resource_metadata=") {
+		t.Fatalf("expected an OAuth challenge, got %d %q", resp.StatusCode, resp.Header.Get("WWW-Authenticate"))
+	}
+	metadata, err := http.Get(srv.URL + "/.well-known/oauth-protected-resource/mcp")
+	if err != nil || metadata.StatusCode != http.StatusOK {
+		t.Fatalf("expected protected resource metadata, got %v %v", metadata, err)
+	}
+	metadata.Body.Close()
+
+	alice := auth.token(t, "alice", "notes:read notes:write")
+	bob := auth.token(t, "bob", "notes:read")
+
+	if _, body := post(alice, call("add_note", `{"text":"buy milk"}`)); !strings.Contains(body, "saved") {
+		t.Errorf("expected alice to save a note, got %s", body)
+	}
+	if _, body := post(alice, call("list_notes", `{}`)); !strings.Contains(body, "buy milk") {
+		t.Errorf("expected alice to see her note, got %s", body)
+	}
+	if _, body := post(bob, call("list_notes", `{}`)); strings.Contains(body, "buy milk") {
+		t.Errorf("expected bob not to see alice's notes, got %s", body)
+	}
+	if _, body := post(bob, call("add_note", `{"text":"hi"}`)); !strings.Contains(body, "Permission denied") {
+		t.Errorf("expected bob to lack notes:write, got %s", body)
+	}
+	if _, body := post(bob, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`); strings.Contains(body, "add_note") {
+		t.Errorf("expected add_note to be hidden from bob, got %s", body)
+	}
+}
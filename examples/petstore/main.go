@@ -0,0 +1,52 @@
+// Command petstore serves the tools generated from an OpenAPI document over stdio. The
+// petstore package is produced by minimcp-gen; run go generate ./... after editing
+// petstore/petstore.json.
+//
+//	petstore -base-url http://localhost:8080/v1 -token "$PETSTORE_TOKEN"
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"github.com/mhpenta/minimcp/examples/petstore/petstore"
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/openapi"
+)
+
+func main() {
+	baseURL := flag.String("base-url", petstore.DefaultBaseURL, "where the Petstore API is served")
+	token := flag.String("token", "", "bearer token sent with every request")
+	flag.Parse()
+
+	// Stdout carries the protocol, so logs go to stderr
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	server := newServer(newInvoker(*baseURL, *token), logger)
+	if err := mcp.NewStdioTransport(server, logger).Start(ctx); err != nil {
+		logger.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func newInvoker(baseURL, token string) *openapi.Invoker {
+	invoker := openapi.NewInvoker(baseURL)
+	if token != "" {
+		invoker.WithHeader("Authorization", "Bearer "+token)
+	}
+	return invoker
+}
+
+func newServer(invoker *openapi.Invoker, logger *slog.Logger) *mcp.Server {
+	return mcp.NewServer(mcp.ServerConfig{
+		Name:    "petstore",
+		Version: "1.0.0",
+		Tools:   petstore.NewTools(invoker),
+		Logger:  logger,
+	})
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/mcptest"
+)
+
+// fakePetstore records the requests it receives and answers with canned pets
+func fakePetstore(t *testing.T, requests *[]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		*requests = append(*requests, strings.TrimSpace(r.Method+" "+r.URL.RequestURI()+" "+r.Header.Get("Authorization")+" "+string(body)))
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/pets":
+			json.NewEncoder(w).Encode([]map[string]interface{}{{"id": 1, "name": "Rex"}})
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/pets":
+			w.WriteHeader(http.StatusCreated)
+			w.Write(body)
+		case r.URL.Path == "/v1/pets/404":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"no such pet"}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPetstoreTools(t *testing.T) {
+	var requests []string
+	api := fakePetstore(t, &requests)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	c := mcptest.NewClient(t, newServer(newInvoker(api.URL+"/v1", "secret"), logger))
+
+	var names []string
+	for _, tool := range c.ListTools() {
+		names = append(names, tool.Name)
+	}
+	if got := strings.Join(names, ","); got != "list_categories,list_pets,create_pet,get_pet_by_id,delete_pet" {
+		t.Errorf("unexpected tools %s", got)
+	}
+
+	c.CallTool("list_pets", map[string]interface{}{"limit": 5, "status": []string{"available", "sold"}}).ExpectToolText(`[{"id":1,"name":"Rex"}]`)
+	c.CallTool("create_pet", map[string]interface{}{"body": map[string]interface{}{"name": "Tom"}}).ExpectToolText(`{"name":"Tom"}`)
+	c.CallTool("delete_pet", map[string]interface{}{"petId": 7}).ExpectToolText("204 No Content")
+	c.CallTool("get_pet_by_id", map[string]interface{}{"petId": 404}).ExpectToolError()
+
+	// Constraints from the document are enforced before any request is sent
+	c.CallTool("list_pets", map[string]interface{}{"limit": 500}).ExpectRPCError(mcp.InvalidParams)
+	c.CallTool("list_pets", map[string]interface{}{"status": []string{"lost"}}).ExpectRPCError(mcp.InvalidParams)
+
+	want := []string{
+		"GET /v1/pets?limit=5&status=available&status=sold Bearer secret",
+		`POST /v1/pets Bearer secret {"name":"Tom"}`,
+		"DELETE /v1/pets/7 Bearer secret",
+		"GET /v1/pets/404 Bearer secret",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected requests:\n%s\nwant:\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,5 @@
+// Package petstore holds the tools generated from petstore.json, a small OpenAPI document
+// describing a pet store
+package petstore
+
+//go:generate go run github.com/mhpenta/minimcp/cmd/minimcp-gen -in petstore.json -out petstore_gen.go -package petstore
@@ -0,0 +1,130 @@
+// Command sql_analytics serves read-only SQL analytics over MCP. It exposes the
+// ReadOnlySQLQuery tool and a streaming CSV export, records every call in an audit log,
+// and publishes Prometheus metrics when served over HTTP.
+//
+// Link the database driver you need with a blank import, then run:
+//
+//	go run ./examples/sql_analytics -driver=postgres -dsn="postgres://localhost/analytics"
+//	MCP_API_KEYS=secret go run ./examples/sql_analytics -driver=postgres -dsn=... -port=8080
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/metrics"
+	"github.com/mhpenta/minimcp/tools"
+	"github.com/mhpenta/minimcp/utilitytools"
+)
+
+// ExportRequest is the input of the export_csv tool
+type ExportRequest struct {
+	Query string `json:"query" jsonschema:"read-only SELECT or WITH query whose results are exported"`
+}
+
+// exportRowsPerChunk is how many CSV rows are emitted per streamed chunk
+const exportRowsPerChunk = 100
+
+func main() {
+	driver := flag.String("driver", "", "database/sql driver name (the driver must be linked in)")
+	dsn := flag.String("dsn", "", "data source name")
+	port := flag.String("port", "", "serve over HTTP on this port instead of stdio")
+	flag.Parse()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, logger, *driver, *dsn, *port); err != nil {
+		logger.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, logger *slog.Logger, driver, dsn, port string) error {
+	if driver == "" || dsn == "" {
+		return fmt.Errorf("-driver and -dsn are required")
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	server := newServer(db, logger, metrics.New())
+	if port == "" {
+		return mcp.NewStdioTransport(server, logger).Start(ctx)
+	}
+
+	validator, err := mcp.NewStaticValidatorFromEnv("MCP_API_KEYS")
+	if err != nil {
+		return err
+	}
+	return mcp.NewHTTPTransport(server, logger, validator).Start(ctx, port)
+}
+
+// newServer wires the SQL tools into a server. The query tool's long description is
+// trimmed in tools/list and served in full as a resource, and /mcp/health pings the database.
+func newServer(db *sql.DB, logger *slog.Logger, m *metrics.Metrics) *mcp.Server {
+	return mcp.NewServer(mcp.ServerConfig{
+		Name:    "sql-analytics",
+		Version: "1.0.0",
+		Tools: []tools.Tool{
+			utilitytools.NewReadOnlySQLTool(db, logger),
+			newExportTool(db, logger),
+		},
+		Logger:               logger,
+		AuditLog:             mcp.NewMemoryAuditLog(1000),
+		Metrics:              m,
+		MinifySchemas:        true,
+		MaxDescriptionTokens: 200,
+		HealthChecks:         map[string]mcp.HealthCheck{"database": db.PingContext},
+	})
+}
+
+// newExportTool streams query results as CSV, a chunk of rows at a time
+func newExportTool(db *sql.DB, logger *slog.Logger) tools.Tool {
+	return tools.NewStreamingTool("export_csv", "Exports the results of a read-only query as CSV",
+		func(ctx context.Context, req ExportRequest, emit tools.EmitFunc) error {
+			result, err := utilitytools.ExecuteSQLQuery(ctx, logger, db, req.Query)
+			if err != nil {
+				return err
+			}
+
+			var chunk strings.Builder
+			w := csv.NewWriter(&chunk)
+			w.Write(result.Columns)
+			for i, row := range result.Rows {
+				record := make([]string, len(row))
+				for j, value := range row {
+					if value != nil {
+						record[j] = fmt.Sprint(value)
+					}
+				}
+				w.Write(record)
+
+				if (i+1)%exportRowsPerChunk == 0 {
+					w.Flush()
+					if err := emit(chunk.String()); err != nil {
+						return err
+					}
+					chunk.Reset()
+				}
+			}
+			w.Flush()
+			if chunk.Len() == 0 {
+				return w.Error()
+			}
+			return emit(chunk.String())
+		},
+		tools.WithVerb("Exporting query results"),
+		tools.WithCategory("export"))
+}
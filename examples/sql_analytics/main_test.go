@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/metrics"
+)
+
+// fakeDriver answers every query with a fixed table, standing in for a real database
+type fakeDriver struct{}
+type fakeConn struct{}
+type fakeStmt struct{}
+type fakeRows struct{ next int }
+
+var fakeTable = [][]driver.Value{{"widgets", int64(3)}, {"gadgets", int64(5)}}
+
+func (fakeDriver) Open(string) (driver.Conn, error)         { return fakeConn{}, nil }
+func (fakeConn) Prepare(string) (driver.Stmt, error)        { return fakeStmt{}, nil }
+func (fakeConn) Close() error                               { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                  { return nil, driver.ErrSkip }
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error)  { return &fakeRows{}, nil }
+func (*fakeRows) Columns() []string                         { return []string{"product", "sold"} }
+func (*fakeRows) Close() error                              { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(fakeTable) {
+		return io.EOF
+	}
+	copy(dest, fakeTable[r.next])
+	r.next++
+	return nil
+}
+
+func init() {
+	sql.Register("sql_analytics_fake", fakeDriver{})
+}
+
+func call(t *testing.T, handler *mcp.JSONRPCHandler, method, params string) *mcp.JSONRPCResponse {
+	t.Helper()
+	resp, err := handler.HandleMessage(context.Background(), []byte(
+		`{"jsonrpc":"2.0","id":1,"method":"`+method+`","params":`+params+`}`))
+	if err != nil {
+		t.Fatalf("%s failed: %v", method, err)
+	}
+	if resp.Error != nil {
+		t.Fatalf("%s returned error: %v", method, resp.Error.Message)
+	}
+	data, _ := json.Marshal(resp.Result)
+	resp.Result = json.RawMessage(data)
+	return resp
+}
+
+func TestSQLAnalyticsServer(t *testing.T) {
+	db, err := sql.Open("sql_analytics_fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	m := metrics.New()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := mcp.NewJSONRPCHandler(newServer(db, logger, m))
+
+	resp := call(t, handler, "tools/call", `{"name":"ReadOnlySQLQuery","arguments":{"query":"SELECT product, sold FROM sales"}}`)
+	if result := string(resp.Result.(json.RawMessage)); !strings.Contains(result, "widgets") {
+		t.Errorf("expected query rows, got %s", result)
+	}
+
+	resp = call(t, handler, "tools/call", `{"name":"export_csv","arguments":{"query":"SELECT product, sold FROM sales"}}`)
+	var result mcp.ToolsCallResult
+	json.Unmarshal(resp.Result.(json.RawMessage), &result)
+	if want := "product,sold\nwidgets,3\ngadgets,5\n"; result.IsError || result.Content[0].Text != want {
+		t.Errorf("expected CSV export %q, got %+v", want, result)
+	}
+
+	resp = call(t, handler, "tools/call", `{"name":"export_csv","arguments":{"query":"DELETE FROM sales"}}`)
+	json.Unmarshal(resp.Result.(json.RawMessage), &result)
+	if !result.IsError {
+		t.Error("expected a write query to be rejected")
+	}
+
+	// The query tool's long description overflows the budget and is served as a resource
+	resp = call(t, handler, "resources/read", `{"uri":"minimcp://tools/ReadOnlySQLQuery/description"}`)
+	if text := string(resp.Result.(json.RawMessage)); !strings.Contains(text, "READ-ONLY MODE") {
+		t.Errorf("expected the full description as a resource, got %s", text)
+	}
+
+	var exposition strings.Builder
+	m.WriteTo(&exposition)
+	if !strings.Contains(exposition.String(), `minimcp_tool_calls_total{tool="export_csv",status="ok"}`) {
+		t.Errorf("expected tool call metrics, got:\n%s", exposition.String())
+	}
+}
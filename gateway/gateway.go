@@ -0,0 +1,389 @@
+// Package gateway re-exposes the tools of several MCP servers through one minimcp server,
+// so a client configured with many servers can connect to a single one instead.
+//
+// The gateway initializes every upstream concurrently, lists their tools, and registers
+// them under prefixed names. Calls are forwarded to the upstream that owns the tool. An
+// upstream that fails to start, or whose connection drops, has its tools removed and is
+// reconnected in the background; tools reappear once it answers again.
+//
+// Example:
+//
+//	upstreams, err := gateway.LoadClaudeDesktopConfig("servers.json")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	gw := gateway.New(gateway.Config{Name: "gateway", Version: "1.0.0", Upstreams: upstreams})
+//	if err := gw.Start(ctx); err != nil {
+//	    logger.Warn("some upstreams are unavailable", "error", err)
+//	}
+//	defer gw.Close()
+//	mcp.NewStdioTransport(gw.Server(), logger).Start(ctx)
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/mhpenta/minimcp/client"
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// Defaults applied by New
+const (
+	DefaultSeparator      = "__"
+	DefaultRetryInterval  = 30 * time.Second
+	DefaultConnectTimeout = 30 * time.Second
+)
+
+// PrefixMode decides which tools are renamed with their upstream's name
+type PrefixMode int
+
+const (
+	// PrefixAll names every tool "<upstream><separator><tool>"
+	PrefixAll PrefixMode = iota
+
+	// PrefixCollisions keeps tool names as they are, prefixing only tools whose name
+	// is offered by more than one upstream
+	PrefixCollisions
+)
+
+// Config configures a Gateway
+type Config struct {
+	// Name and Version identify the gateway to its clients and to upstreams
+	Name    string
+	Version string
+
+	// Upstreams lists the servers to aggregate. Names must be unique.
+	Upstreams []Upstream
+
+	// Server, when set, receives the upstream tools instead of a server created from
+	// Name and Version, so it can carry its own tools and configuration
+	Server *mcp.Server
+
+	// Separator joins upstream and tool names. Defaults to DefaultSeparator, since some
+	// clients only accept letters, digits, underscores and hyphens in tool names.
+	Separator string
+
+	// PrefixMode selects which tools are prefixed. Defaults to PrefixAll.
+	PrefixMode PrefixMode
+
+	// RetryInterval is how long to wait before reconnecting a failed upstream
+	RetryInterval time.Duration
+
+	// ConnectTimeout bounds connecting to, initializing, and listing an upstream
+	ConnectTimeout time.Duration
+
+	Logger *slog.Logger
+}
+
+// Gateway aggregates the tools of upstream MCP servers into one server
+type Gateway struct {
+	server *mcp.Server
+	logger *slog.Logger
+	info   mcp.ClientInfo
+
+	separator      string
+	prefixMode     PrefixMode
+	retryInterval  time.Duration
+	connectTimeout time.Duration
+
+	upstreams []*upstream
+
+	// mu guards upstream state; syncMu serializes registry updates
+	mu         sync.Mutex
+	syncMu     sync.Mutex
+	registered map[string]string // tool name to fingerprint of its upstream description
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// upstream is the connection state of one configured upstream
+type upstream struct {
+	Upstream
+
+	client *client.Client
+	tools  []mcp.ToolDescription
+	err    error
+}
+
+// UpstreamStatus reports the state of an upstream
+type UpstreamStatus struct {
+	Name      string
+	Connected bool
+	Tools     int
+
+	// Err is the last connection failure, nil while connected
+	Err error
+}
+
+// New creates a gateway. Start connects to the upstreams.
+func New(cfg Config) *Gateway {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	if cfg.Separator == "" {
+		cfg.Separator = DefaultSeparator
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = DefaultRetryInterval
+	}
+	if cfg.ConnectTimeout <= 0 {
+		cfg.ConnectTimeout = DefaultConnectTimeout
+	}
+	if cfg.Server == nil {
+		cfg.Server = mcp.NewServer(mcp.ServerConfig{Name: cfg.Name, Version: cfg.Version, Logger: cfg.Logger})
+	}
+
+	g := &Gateway{
+		server:         cfg.Server,
+		logger:         cfg.Logger,
+		info:           mcp.ClientInfo{Name: cfg.Server.Name(), Version: cfg.Server.Version()},
+		separator:      cfg.Separator,
+		prefixMode:     cfg.PrefixMode,
+		retryInterval:  cfg.RetryInterval,
+		connectTimeout: cfg.ConnectTimeout,
+		registered:     make(map[string]string),
+	}
+	for _, u := range cfg.Upstreams {
+		g.upstreams = append(g.upstreams, &upstream{Upstream: u})
+	}
+	return g
+}
+
+// Server returns the server exposing the upstreams' tools
+func (g *Gateway) Server() *mcp.Server {
+	return g.server
+}
+
+// Start connects to every upstream concurrently and registers their tools. It returns
+// once each upstream has connected or failed, reporting the failures; failed upstreams
+// keep being retried in the background until Close.
+func (g *Gateway) Start(ctx context.Context) error {
+	ctx, g.cancel = context.WithCancel(ctx)
+
+	firstAttempts := make(chan error, len(g.upstreams))
+	for _, u := range g.upstreams {
+		g.wg.Add(1)
+		go func() {
+			defer g.wg.Done()
+			g.maintain(ctx, u, firstAttempts)
+		}()
+	}
+
+	var errs []error
+	for range g.upstreams {
+		if err := <-firstAttempts; err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close disconnects from every upstream and stops reconnecting
+func (g *Gateway) Close() error {
+	if g.cancel != nil {
+		g.cancel()
+	}
+	g.wg.Wait()
+	return nil
+}
+
+// Status reports the state of each upstream, in configuration order
+func (g *Gateway) Status() []UpstreamStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	statuses := make([]UpstreamStatus, 0, len(g.upstreams))
+	for _, u := range g.upstreams {
+		statuses = append(statuses, UpstreamStatus{
+			Name:      u.Name,
+			Connected: u.client != nil,
+			Tools:     len(u.tools),
+			Err:       u.err,
+		})
+	}
+	return statuses
+}
+
+// maintain keeps an upstream connected until ctx ends, reporting the outcome of the
+// first attempt on firstAttempt
+func (g *Gateway) maintain(ctx context.Context, u *upstream, firstAttempt chan<- error) {
+	for attempt := 0; ; attempt++ {
+		c, err := g.connect(ctx, u)
+		if attempt == 0 {
+			firstAttempt <- err
+		}
+
+		if err == nil {
+			select {
+			case <-c.Done():
+				err = c.Err()
+				g.logger.Warn("upstream disconnected", "upstream", u.Name, "error", err)
+			case <-ctx.Done():
+			}
+			c.Close()
+			g.setState(u, nil, nil, err)
+		} else {
+			g.logger.Warn("upstream unavailable", "upstream", u.Name, "error", err, "retry_in", g.retryInterval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(g.retryInterval):
+		}
+	}
+}
+
+// connect opens, initializes, and lists an upstream, registering its tools
+func (g *Gateway) connect(ctx context.Context, u *upstream) (*client.Client, error) {
+	conn, err := u.Connect(ctx)
+	if err != nil {
+		err = fmt.Errorf("upstream %q: %w", u.Name, err)
+		g.setState(u, nil, nil, err)
+		return nil, err
+	}
+	c := client.New(conn)
+
+	setupCtx, cancel := context.WithTimeout(ctx, g.connectTimeout)
+	defer cancel()
+	list, err := func() ([]mcp.ToolDescription, error) {
+		if _, err := c.Initialize(setupCtx, g.info); err != nil {
+			return nil, fmt.Errorf("initializing: %w", err)
+		}
+		return c.ListTools(setupCtx)
+	}()
+	if err != nil {
+		c.Close()
+		err = fmt.Errorf("upstream %q: %w", u.Name, err)
+		g.setState(u, nil, nil, err)
+		return nil, err
+	}
+
+	c.OnNotification(func(n mcp.JSONRPCNotification) {
+		if n.Method == mcp.MethodNotificationToolsListChanged {
+			go g.relist(ctx, u, c)
+		}
+	})
+	g.logger.Info("upstream connected", "upstream", u.Name, "tools", len(list))
+	g.setState(u, c, list, nil)
+	return c, nil
+}
+
+// relist refreshes an upstream's tools after it reported a change
+func (g *Gateway) relist(ctx context.Context, u *upstream, c *client.Client) {
+	ctx, cancel := context.WithTimeout(ctx, g.connectTimeout)
+	defer cancel()
+	list, err := c.ListTools(ctx)
+	if err != nil {
+		g.logger.Warn("failed to relist upstream tools", "upstream", u.Name, "error", err)
+		return
+	}
+
+	g.mu.Lock()
+	current := u.client == c
+	g.mu.Unlock()
+	if current {
+		g.setState(u, c, list, nil)
+	}
+}
+
+// setState records an upstream's connection and tools, then updates the registry
+func (g *Gateway) setState(u *upstream, c *client.Client, list []mcp.ToolDescription, err error) {
+	g.mu.Lock()
+	u.client, u.tools, u.err = c, list, err
+	g.mu.Unlock()
+	g.sync()
+}
+
+// sync registers the tools of connected upstreams and removes the rest. Tools whose
+// upstream description is unchanged are left alone, so clients only hear of real changes.
+func (g *Gateway) sync() {
+	g.syncMu.Lock()
+	defer g.syncMu.Unlock()
+
+	desired, fingerprints := g.desiredTools()
+
+	var stale []string
+	for name := range g.registered {
+		if _, ok := fingerprints[name]; !ok {
+			stale = append(stale, name)
+		}
+	}
+	var changed []tools.Tool
+	for _, tool := range desired {
+		name := tool.Spec().Name
+		if g.registered[name] != fingerprints[name] {
+			changed = append(changed, tool)
+		}
+	}
+
+	if len(stale) > 0 {
+		g.server.RemoveTools(stale...)
+		for _, name := range stale {
+			delete(g.registered, name)
+		}
+	}
+	if len(changed) > 0 {
+		if err := g.server.AddTools(changed...); err != nil {
+			g.logger.Error("failed to register upstream tools", "error", err)
+			return
+		}
+		for _, tool := range changed {
+			name := tool.Spec().Name
+			g.registered[name] = fingerprints[name]
+		}
+	}
+}
+
+// desiredTools builds the proxies for every connected upstream's tools, skipping tools
+// whose resulting name is invalid or already taken
+func (g *Gateway) desiredTools() ([]tools.Tool, map[string]string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	offered := make(map[string]int)
+	for _, u := range g.upstreams {
+		for _, d := range u.tools {
+			offered[d.Name]++
+		}
+	}
+
+	var desired []tools.Tool
+	fingerprints := make(map[string]string)
+	for _, u := range g.upstreams {
+		for _, d := range u.tools {
+			name := d.Name
+			if g.prefixMode == PrefixAll || offered[d.Name] > 1 {
+				name = u.Name + g.separator + d.Name
+			}
+			if _, taken := fingerprints[name]; taken {
+				g.logger.Warn("skipping upstream tool with duplicate name", "upstream", u.Name, "tool", d.Name, "name", name)
+				continue
+			}
+			tool := g.newProxyTool(u, name, d)
+			if err := tools.Validate(tool); err != nil {
+				g.logger.Warn("skipping invalid upstream tool", "upstream", u.Name, "tool", d.Name, "error", err)
+				continue
+			}
+			fingerprint, _ := json.Marshal(d)
+			fingerprints[name] = u.Name + "\x00" + string(fingerprint)
+			desired = append(desired, tool)
+		}
+	}
+	return desired, fingerprints
+}
+
+// currentClient returns the upstream's live client, or nil while it is disconnected
+func (g *Gateway) currentClient(u *upstream) *client.Client {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return u.client
+}
@@ -0,0 +1,316 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/client"
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// TestMain doubles as a stdio MCP server, so Command upstreams can run the test binary
+func TestMain(m *testing.M) {
+	if name := os.Getenv("GATEWAY_TEST_SERVER"); name != "" {
+		server := newUpstreamServer(name, echoTool("echo"))
+		mcp.NewStdioTransport(server, quietLogger()).Start(context.Background())
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func quietLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+type echoInput struct {
+	Text string `json:"text"`
+}
+
+func echoTool(name string) tools.Tool {
+	return tools.NewTool(name, "Echoes text", func(ctx context.Context, in echoInput) (string, error) {
+		if in.Text == "fail" {
+			return "", errors.New("asked to fail")
+		}
+		return name + ":" + in.Text, nil
+	})
+}
+
+func newUpstreamServer(name string, ts ...tools.Tool) *mcp.Server {
+	return mcp.NewServer(mcp.ServerConfig{Name: name, Version: "1.0.0", Tools: ts, Logger: quietLogger()})
+}
+
+// memoryUpstream serves server in-process; each connection gets its own transport
+func memoryUpstream(name string, server *mcp.Server) Upstream {
+	return Upstream{
+		Name: name,
+		Connect: func(ctx context.Context) (client.Conn, error) {
+			transport, conn := mcp.NewInMemoryTransport(server, nil)
+			go transport.Start(ctx)
+			return conn, nil
+		},
+	}
+}
+
+func toolNames(server *mcp.Server) []string {
+	var names []string
+	for _, tool := range server.GetTools() {
+		names = append(names, tool.Spec().Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func call(t *testing.T, server *mcp.Server, name, text string) (*mcp.JSONRPCResponse, string) {
+	t.Helper()
+	msg, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0", "id": 1, "method": "tools/call",
+		"params": map[string]interface{}{"name": name, "arguments": map[string]string{"text": text}},
+	})
+	resp, err := mcp.NewJSONRPCHandler(server).HandleMessage(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("HandleMessage failed: %v", err)
+	}
+	data, _ := json.Marshal(resp.Result)
+	return resp, string(data)
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestGateway_AggregatesUpstreams(t *testing.T) {
+	search := newUpstreamServer("search", echoTool("query"), echoTool("echo"))
+	httpUpstream := httptest.NewServer(mcp.NewHTTPTransport(newUpstreamServer("notes", echoTool("echo")), quietLogger(), mcp.NewConstantTimeStaticValidator("secret")))
+	defer httpUpstream.Close()
+
+	gw := New(Config{
+		Name:    "gateway",
+		Version: "1.0.0",
+		Logger:  quietLogger(),
+		Upstreams: []Upstream{
+			memoryUpstream("search", search),
+			HTTP("notes", httpUpstream.URL+"/mcp", http.Header{"Authorization": {"Bearer secret"}}),
+			Command("local", os.Args[0], nil, map[string]string{"GATEWAY_TEST_SERVER": "local"}),
+		},
+	})
+	if err := gw.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer gw.Close()
+
+	want := []string{"local__echo", "notes__echo", "search__echo", "search__query"}
+	if got := toolNames(gw.Server()); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	for name, expected := range map[string]string{
+		"search__query": "query:hi",
+		"notes__echo":   "echo:hi",
+		"local__echo":   "echo:hi",
+	} {
+		resp, result := call(t, gw.Server(), name, "hi")
+		if resp.Error != nil || !strings.Contains(result, `"text":"`+expected+`"`) {
+			t.Errorf("%s: expected %q, got %s (error %v)", name, expected, result, resp.Error)
+		}
+	}
+
+	// Upstream tool failures stay tool failures
+	_, result := call(t, gw.Server(), "search__echo", "fail")
+	if !strings.Contains(result, `"isError":true`) || !strings.Contains(result, "asked to fail") {
+		t.Errorf("expected the upstream failure as an error result, got %s", result)
+	}
+
+	for _, status := range gw.Status() {
+		if !status.Connected || status.Err != nil {
+			t.Errorf("expected %s to be connected, got %+v", status.Name, status)
+		}
+	}
+}
+
+func TestGateway_PrefixCollisions(t *testing.T) {
+	gw := New(Config{
+		Name:       "gateway",
+		Logger:     quietLogger(),
+		PrefixMode: PrefixCollisions,
+		Separator:  ".",
+		Upstreams: []Upstream{
+			memoryUpstream("a", newUpstreamServer("a", echoTool("echo"), echoTool("alpha"))),
+			memoryUpstream("b", newUpstreamServer("b", echoTool("echo"), echoTool("beta"))),
+		},
+	})
+	if err := gw.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer gw.Close()
+
+	want := "a.echo,alpha,b.echo,beta"
+	if got := strings.Join(toolNames(gw.Server()), ","); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestGateway_ProxySpendsBudget(t *testing.T) {
+	gw := New(Config{
+		Name:      "gateway",
+		Logger:    quietLogger(),
+		Upstreams: []Upstream{memoryUpstream("search", newUpstreamServer("search", echoTool("echo")))},
+	})
+	if err := gw.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer gw.Close()
+
+	var proxy tools.Tool
+	for _, tool := range gw.Server().GetTools() {
+		proxy = tool
+	}
+	params := json.RawMessage(`{"text":"hi"}`)
+
+	tests := []struct {
+		name   string
+		limits tools.BudgetLimits
+	}{
+		{"calls", tools.BudgetLimits{MaxCalls: 1}},
+		{"bytes", tools.BudgetLimits{MaxBytes: int64(len("echo:hi")) + 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tools.WithBudget(context.Background(), tools.NewBudget(tt.limits))
+			if _, err := proxy.Execute(ctx, params); err != nil {
+				t.Fatalf("expected the first upstream call to fit the budget, got %v", err)
+			}
+			if _, err := proxy.Execute(ctx, params); !errors.Is(err, tools.ErrBudgetExceeded) {
+				t.Errorf("expected ErrBudgetExceeded, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGateway_UpstreamFailures(t *testing.T) {
+	var mu sync.Mutex
+	available := false
+	var conns []client.Conn
+	flaky := newUpstreamServer("flaky", echoTool("echo"))
+	upstream := Upstream{
+		Name: "flaky",
+		Connect: func(ctx context.Context) (client.Conn, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if !available {
+				return nil, errors.New("connection refused")
+			}
+			transport, conn := mcp.NewInMemoryTransport(flaky, nil)
+			go transport.Start(ctx)
+			conns = append(conns, conn)
+			return conn, nil
+		},
+	}
+
+	gw := New(Config{
+		Name:          "gateway",
+		Logger:        quietLogger(),
+		RetryInterval: 10 * time.Millisecond,
+		Upstreams:     []Upstream{upstream, memoryUpstream("steady", newUpstreamServer("steady", echoTool("echo")))},
+	})
+	err := gw.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "flaky") {
+		t.Fatalf("expected Start to report the flaky upstream, got %v", err)
+	}
+	defer gw.Close()
+	if got := strings.Join(toolNames(gw.Server()), ","); got != "steady__echo" {
+		t.Errorf("expected only the steady upstream's tools, got %s", got)
+	}
+
+	// The upstream is retried until it answers
+	mu.Lock()
+	available = true
+	mu.Unlock()
+	waitFor(t, "flaky tools", func() bool { return len(toolNames(gw.Server())) == 2 })
+
+	// Losing the connection removes its tools until it reconnects
+	mu.Lock()
+	available = false
+	conns[0].Close()
+	mu.Unlock()
+	waitFor(t, "flaky tools to be removed", func() bool { return len(toolNames(gw.Server())) == 1 })
+	if status := gw.Status()[0]; status.Connected || status.Err == nil {
+		t.Errorf("expected a disconnected status with an error, got %+v", status)
+	}
+
+	mu.Lock()
+	available = true
+	mu.Unlock()
+	waitFor(t, "flaky tools to return", func() bool { return len(toolNames(gw.Server())) == 2 })
+}
+
+func TestGateway_UpstreamListChanged(t *testing.T) {
+	upstreamServer := newUpstreamServer("dynamic", echoTool("echo"))
+	transport, conn := mcp.NewInMemoryTransport(upstreamServer, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go transport.Start(ctx)
+
+	gw := New(Config{
+		Name:   "gateway",
+		Logger: quietLogger(),
+		Upstreams: []Upstream{{
+			Name:    "dynamic",
+			Connect: func(context.Context) (client.Conn, error) { return conn, nil },
+		}},
+	})
+	if err := gw.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer gw.Close()
+
+	if err := upstreamServer.AddTools(echoTool("added")); err != nil {
+		t.Fatalf("AddTools failed: %v", err)
+	}
+	waitFor(t, "the added tool", func() bool { return len(toolNames(gw.Server())) == 2 })
+}
+
+func TestLoadClaudeDesktopConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "claude_desktop_config.json")
+	config := `{"mcpServers": {
+		"files": {"command": "npx", "args": ["-y", "server-filesystem", "/tmp"], "env": {"DEBUG": "1"}},
+		"remote": {"url": "https://example.com/mcp", "headers": {"Authorization": "Bearer x"}}
+	}}`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	upstreams, err := LoadClaudeDesktopConfig(path)
+	if err != nil {
+		t.Fatalf("LoadClaudeDesktopConfig failed: %v", err)
+	}
+	if len(upstreams) != 2 || upstreams[0].Name != "files" || upstreams[1].Name != "remote" {
+		t.Fatalf("unexpected upstreams: %+v", upstreams)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"mcpServers": {"broken": {}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadClaudeDesktopConfig(path); err == nil {
+		t.Error("expected error for a server without command or url")
+	}
+}
@@ -0,0 +1,73 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// proxyTool forwards calls to a tool of an upstream, through whichever connection to it
+// is current
+type proxyTool struct {
+	spec     *tools.ToolSpec
+	gateway  *Gateway
+	upstream *upstream
+	remote   string
+}
+
+func (g *Gateway) newProxyTool(u *upstream, name string, d mcp.ToolDescription) *proxyTool {
+	spec := &tools.ToolSpec{
+		Name:        name,
+		Description: d.Description,
+		Parameters:  d.InputSchema,
+		Output:      d.OutputSchema,
+		Category:    u.Name,
+	}
+	if d.Annotations != nil && d.Annotations.DestructiveHint != nil {
+		spec.Destructive = *d.Annotations.DestructiveHint
+	}
+	return &proxyTool{spec: spec, gateway: g, upstream: u, remote: d.Name}
+}
+
+func (p *proxyTool) Spec() *tools.ToolSpec {
+	return p.spec
+}
+
+// Execute calls the upstream tool. Upstream protocol errors keep their code; an
+// unreachable upstream is reported to the model as a tool failure. Each call draws one
+// sub-call, and the bytes of its result, from the request budget.
+func (p *proxyTool) Execute(ctx context.Context, params json.RawMessage) (*tools.ToolResult, error) {
+	c := p.gateway.currentClient(p.upstream)
+	if c == nil {
+		return nil, fmt.Errorf("upstream %q is unavailable", p.upstream.Name)
+	}
+	if err := tools.SpendCall(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := c.CallTool(ctx, p.remote, params)
+	if err != nil {
+		var rpcErr *mcp.RPCError
+		if errors.As(err, &rpcErr) {
+			return nil, &tools.Error{Code: rpcErr.Code, Message: rpcErr.Message, Data: rpcErr.Data}
+		}
+		return nil, fmt.Errorf("upstream %q: %w", p.upstream.Name, err)
+	}
+
+	var text strings.Builder
+	for _, block := range result.Content {
+		text.WriteString(block.Text)
+	}
+	if err := tools.SpendBytes(ctx, int64(text.Len())); err != nil {
+		return nil, err
+	}
+	if result.IsError {
+		return tools.ErrorResult(errors.New(text.String())), nil
+	}
+	return &tools.ToolResult{Output: text.String()}, nil
+}
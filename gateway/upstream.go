@@ -0,0 +1,112 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+
+	"github.com/mhpenta/minimcp/client"
+)
+
+// Upstream is a server the gateway connects to
+type Upstream struct {
+	// Name identifies the upstream and prefixes its tools' names
+	Name string
+
+	// Connect opens a new connection to the server. It is called again to reconnect
+	// after the connection fails.
+	Connect func(ctx context.Context) (client.Conn, error)
+}
+
+// Command returns an upstream served by a local process speaking MCP over stdio. env
+// entries are added to the gateway's own environment.
+func Command(name, command string, args []string, env map[string]string) Upstream {
+	return Upstream{
+		Name: name,
+		Connect: func(ctx context.Context) (client.Conn, error) {
+			cmd := exec.CommandContext(ctx, command, args...)
+			if len(env) > 0 {
+				cmd.Env = os.Environ()
+				keys := make([]string, 0, len(env))
+				for key := range env {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				for _, key := range keys {
+					cmd.Env = append(cmd.Env, key+"="+env[key])
+				}
+			}
+			return client.StartProcess(cmd)
+		},
+	}
+}
+
+// HTTP returns an upstream served at an MCP HTTP endpoint, sending header with every
+// request
+func HTTP(name, url string, header http.Header) Upstream {
+	return Upstream{
+		Name: name,
+		Connect: func(ctx context.Context) (client.Conn, error) {
+			conn := client.NewHTTPConn(url)
+			for key, values := range header {
+				for _, value := range values {
+					conn.WithHeader(key, value)
+				}
+			}
+			return conn, nil
+		},
+	}
+}
+
+// claudeDesktopConfig is the shape of Claude Desktop's claude_desktop_config.json
+type claudeDesktopConfig struct {
+	MCPServers map[string]struct {
+		Command string            `json:"command"`
+		Args    []string          `json:"args"`
+		Env     map[string]string `json:"env"`
+		URL     string            `json:"url"`
+		Headers map[string]string `json:"headers"`
+	} `json:"mcpServers"`
+}
+
+// LoadClaudeDesktopConfig reads the "mcpServers" of a Claude Desktop configuration file
+// as upstreams, sorted by name. Entries with a "url" instead of a "command" connect over
+// HTTP, sending their "headers".
+func LoadClaudeDesktopConfig(path string) ([]Upstream, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg claudeDesktopConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(cfg.MCPServers))
+	for name := range cfg.MCPServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	upstreams := make([]Upstream, 0, len(names))
+	for _, name := range names {
+		entry := cfg.MCPServers[name]
+		switch {
+		case entry.Command != "":
+			upstreams = append(upstreams, Command(name, entry.Command, entry.Args, entry.Env))
+		case entry.URL != "":
+			header := make(http.Header)
+			for key, value := range entry.Headers {
+				header.Set(key, value)
+			}
+			upstreams = append(upstreams, HTTP(name, entry.URL, header))
+		default:
+			return nil, fmt.Errorf("server %q in %s has neither a command nor a url", name, path)
+		}
+	}
+	return upstreams, nil
+}
@@ -2,4 +2,16 @@ module github.com/mhpenta/minimcp
 
 go 1.23.0
 
-require github.com/google/jsonschema-go v0.3.0
+require (
+	github.com/google/jsonschema-go v0.3.0
+	go.opentelemetry.io/otel v1.35.0
+	go.opentelemetry.io/otel/trace v1.35.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel/metric v1.35.0 // indirect
+)
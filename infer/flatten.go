@@ -0,0 +1,124 @@
+package infer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FlattenRefs returns a copy of a JSON schema map with every local $ref replaced by the
+// definition it points to, and $defs/definitions removed, for clients that reject
+// references in inputSchema. A $ref with sibling keywords is merged with its definition,
+// or combined through allOf when the two share a keyword.
+//
+// Recursive definitions cannot be written without references, nor can references to
+// other documents, so both are reported as errors. The input is not modified.
+//
+// Example:
+//
+//	flat, err := infer.FlattenRefs(upstreamSchema)
+//	if err != nil {
+//	    return fmt.Errorf("schema of %q is not self-contained: %w", name, err)
+//	}
+func FlattenRefs(schema map[string]interface{}) (map[string]interface{}, error) {
+	if schema == nil {
+		return nil, nil
+	}
+	root, ok := deepCopy(schema).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("schema is not a JSON object")
+	}
+
+	defs := collectDefs(root)
+	for _, key := range defsKeys {
+		delete(root, key)
+	}
+
+	flat, err := flattenNode(root, defs, nil)
+	if err != nil {
+		return nil, err
+	}
+	return flat.(map[string]interface{}), nil
+}
+
+// flattenNode inlines the references in a schema node. expanding holds the definitions
+// being inlined above this node, so a reference back to one of them is a cycle.
+func flattenNode(node interface{}, defs map[string]interface{}, expanding []string) (interface{}, error) {
+	schema, ok := node.(map[string]interface{})
+	if !ok {
+		return node, nil
+	}
+
+	for key, child := range schema {
+		if key == "$ref" {
+			continue
+		}
+		switch value := child.(type) {
+		case map[string]interface{}:
+			if subschemaMaps[key] {
+				for name, sub := range value {
+					flat, err := flattenNode(sub, defs, expanding)
+					if err != nil {
+						return nil, err
+					}
+					value[name] = flat
+				}
+			} else if key != "const" && key != "default" && key != "enum" && key != "examples" {
+				flat, err := flattenNode(value, defs, expanding)
+				if err != nil {
+					return nil, err
+				}
+				schema[key] = flat
+			}
+		case []interface{}:
+			if key == "allOf" || key == "anyOf" || key == "oneOf" || key == "prefixItems" || key == "items" {
+				for i, sub := range value {
+					flat, err := flattenNode(sub, defs, expanding)
+					if err != nil {
+						return nil, err
+					}
+					value[i] = flat
+				}
+			}
+		}
+	}
+
+	ref, ok := schema["$ref"].(string)
+	if !ok {
+		return schema, nil
+	}
+	for _, outer := range expanding {
+		if outer == ref {
+			return nil, fmt.Errorf("cannot inline recursive definition %q", ref)
+		}
+	}
+	if ref == "#" {
+		return nil, fmt.Errorf("cannot inline recursive reference to the root schema")
+	}
+	def, ok := defs[ref]
+	if !ok {
+		if strings.HasPrefix(ref, "#") {
+			return nil, fmt.Errorf("reference %q has no definition", ref)
+		}
+		return nil, fmt.Errorf("cannot inline reference %q to another document", ref)
+	}
+
+	inlined, err := flattenNode(deepCopy(def), defs, append(expanding, ref))
+	if err != nil {
+		return nil, err
+	}
+	delete(schema, "$ref")
+	if len(schema) == 0 {
+		return inlined, nil
+	}
+
+	sub, ok := inlined.(map[string]interface{})
+	if !ok || conflicts(schema, sub) {
+		allOf, _ := schema["allOf"].([]interface{})
+		schema["allOf"] = append(allOf, inlined)
+		return schema, nil
+	}
+	for k, v := range sub {
+		schema[k] = v
+	}
+	return schema, nil
+}
@@ -0,0 +1,78 @@
+package infer
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFlattenRefs(t *testing.T) {
+	schema := decodeSchema(t, `{
+		"type": "object",
+		"properties": {
+			"home": {"$ref": "#/$defs/Address"},
+			"work": {"$ref": "#/$defs/Address", "description": "office address"},
+			"tags": {"type": "array", "items": {"$ref": "#/definitions/Tag"}},
+			"note": {"$ref": "#/$defs/Note", "type": "string"},
+			"example": {"type": "object", "default": {"$ref": "#/$defs/Missing"}}
+		},
+		"$defs": {
+			"Address": {"type": "object", "properties": {"city": {"$ref": "#/$defs/City"}}},
+			"City": {"type": "string", "minLength": 1},
+			"Note": {"type": "string", "maxLength": 10}
+		},
+		"definitions": {
+			"Tag": {"type": "string"}
+		}
+	}`)
+	original := decodeSchema(t, mustJSON(t, schema))
+
+	flat, err := FlattenRefs(schema)
+	if err != nil {
+		t.Fatalf("FlattenRefs failed: %v", err)
+	}
+
+	want := decodeSchema(t, `{
+		"type": "object",
+		"properties": {
+			"home": {"type": "object", "properties": {"city": {"type": "string", "minLength": 1}}},
+			"work": {"type": "object", "description": "office address", "properties": {"city": {"type": "string", "minLength": 1}}},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"note": {"type": "string", "allOf": [{"type": "string", "maxLength": 10}]},
+			"example": {"type": "object", "default": {"$ref": "#/$defs/Missing"}}
+		}
+	}`)
+	if !reflect.DeepEqual(flat, want) {
+		t.Errorf("unexpected flattened schema:\n got: %s\nwant: %s", mustJSON(t, flat), mustJSON(t, want))
+	}
+	if !reflect.DeepEqual(schema, original) {
+		t.Error("expected input schema to be left unmodified")
+	}
+	if strings.Contains(mustJSON(t, flat["properties"].(map[string]interface{})["home"]), "$ref") {
+		t.Error("expected no references left in properties")
+	}
+}
+
+func TestFlattenRefs_Errors(t *testing.T) {
+	tests := map[string]string{
+		"recursive": `{"$ref": "#/$defs/Node", "$defs": {"Node": {"type": "object", "properties": {"next": {"$ref": "#/$defs/Node"}}}}}`,
+		"root":      `{"type": "object", "properties": {"self": {"$ref": "#"}}}`,
+		"missing":   `{"type": "object", "properties": {"a": {"$ref": "#/$defs/Missing"}}}`,
+		"external":  `{"type": "object", "properties": {"a": {"$ref": "https://example.com/a.json"}}}`,
+	}
+	for name, input := range tests {
+		if _, err := FlattenRefs(decodeSchema(t, input)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
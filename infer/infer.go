@@ -9,6 +9,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
+
 	"github.com/google/jsonschema-go/jsonschema"
 )
 
@@ -25,13 +28,13 @@ import (
 //	input, output, err := schematic.FromFunc(HandleUser)
 func FromFunc[T any, R any](fn func(context.Context, T) (R, error)) (*jsonschema.Schema, *jsonschema.Schema, error) {
 	// Generate input schema
-	inputSchema, err := jsonschema.For[T](nil)
+	inputSchema, err := forType[T]()
 	if err != nil {
 		return nil, nil, fmt.Errorf("generating input schema: %w", err)
 	}
 
 	// Generate output schema
-	outputSchema, err := jsonschema.For[R](nil)
+	outputSchema, err := forType[R]()
 	if err != nil {
 		return nil, nil, fmt.Errorf("generating output schema: %w", err)
 	}
@@ -46,7 +49,30 @@ func FromFunc[T any, R any](fn func(context.Context, T) (R, error)) (*jsonschema
 //
 //	input, err := schematic.FromFuncInput(HandleUser)
 func FromFuncInput[T any, R any](fn func(context.Context, T) (R, error)) (*jsonschema.Schema, error) {
-	return jsonschema.For[T](nil)
+	return forType[T]()
+}
+
+// FromType generates the JSON schema of a Go type, including generic envelopes such as
+// tools.Page[T].
+//
+// Example:
+//
+//	schema, err := infer.FromType[tools.Page[User]]()
+func FromType[T any]() (*jsonschema.Schema, error) {
+	return forType[T]()
+}
+
+// FromReflectType generates the JSON schema of a type known only at run time, such as
+// the parameter of a method found by reflection.
+//
+// Example:
+//
+//	schema, err := infer.FromReflectType(method.Type.In(2))
+func FromReflectType(t reflect.Type) (*jsonschema.Schema, error) {
+	if t == nil {
+		return nil, fmt.Errorf("cannot generate schema for nil type")
+	}
+	return forReflectType(t)
 }
 
 // ToMap converts a jsonschema.Schema to a map[string]interface{} representation.
@@ -69,10 +95,157 @@ func ToMap(s *jsonschema.Schema) (map[string]interface{}, error) {
 		return nil, fmt.Errorf("failed to marshal schema: %w", err)
 	}
 
+	// The schema of interface{} accepts anything and marshals as true
+	if string(data) == "true" {
+		return map[string]interface{}{}, nil
+	}
+
 	var result map[string]interface{}
 	if err := json.Unmarshal(data, &result); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal schema to map: %w", err)
 	}
 
+	addRequiredArrays(result)
 	return result, nil
 }
+
+// addRequiredArrays gives every object schema with properties a required list, empty when
+// all its fields are optional, as jsonschema.Schema omits empty lists when marshaling and
+// some clients expect the keyword
+func addRequiredArrays(node interface{}) {
+	schema, ok := node.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if _, hasProperties := schema["properties"]; hasProperties {
+		if _, hasRequired := schema["required"]; !hasRequired {
+			schema["required"] = []interface{}{}
+		}
+	}
+
+	for key, child := range schema {
+		switch value := child.(type) {
+		case map[string]interface{}:
+			if subschemaMaps[key] {
+				for _, sub := range value {
+					addRequiredArrays(sub)
+				}
+			} else if key != "const" && key != "default" && key != "enum" && key != "examples" {
+				addRequiredArrays(value)
+			}
+		case []interface{}:
+			if key == "allOf" || key == "anyOf" || key == "oneOf" || key == "prefixItems" || key == "items" {
+				for _, sub := range value {
+					addRequiredArrays(sub)
+				}
+			}
+		}
+	}
+}
+
+// Validator checks JSON values against a schema resolved once by Compile
+type Validator struct {
+	resolved *jsonschema.Resolved
+}
+
+// ValidationError describes a value that does not satisfy a schema
+type ValidationError struct {
+	// Field is the dotted path of the offending property, such as "address.city" or
+	// "tags[]", and is empty when the violation concerns the value as a whole
+	Field string `json:"field,omitempty"`
+
+	// Message explains the violation
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// Compile resolves a schema in map form (as produced by ToMap) for repeated validation
+func Compile(schema map[string]interface{}) (*Validator, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	var s jsonschema.Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	resolved, err := s.Resolve(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve schema: %w", err)
+	}
+	return &Validator{resolved: resolved}, nil
+}
+
+// Validate checks a JSON value against the schema. A value that does not satisfy it is
+// reported as a *ValidationError describing the first violation found.
+func (v *Validator) Validate(value json.RawMessage) error {
+	var instance interface{}
+	if err := json.Unmarshal(value, &instance); err != nil {
+		return &ValidationError{Message: fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	if err := v.resolved.Validate(instance); err != nil {
+		return newValidationError(err)
+	}
+	return nil
+}
+
+// Validate checks a JSON value against a schema in map form (as produced by ToMap),
+// returning a *ValidationError describing the first violation found
+func Validate(schema map[string]interface{}, value json.RawMessage) error {
+	v, err := Compile(schema)
+	if err != nil {
+		return err
+	}
+	return v.Validate(value)
+}
+
+// newValidationError extracts the innermost property path from the library's nested
+// "validating <pointer>: ..." messages
+func newValidationError(err error) *ValidationError {
+	message := err.Error()
+	var pointer string
+	for {
+		rest, ok := strings.CutPrefix(message, "validating ")
+		if !ok {
+			break
+		}
+		location, remainder, ok := strings.Cut(rest, ": ")
+		if !ok {
+			break
+		}
+		if location != "root" {
+			pointer = location
+		}
+		message = remainder
+	}
+	return &ValidationError{Field: fieldPath(pointer), Message: message}
+}
+
+// fieldPath converts a schema pointer such as /properties/tags/items to tags[]
+func fieldPath(pointer string) string {
+	var path strings.Builder
+	segments := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i := 0; i < len(segments); i++ {
+		switch segments[i] {
+		case "properties":
+			if i+1 < len(segments) {
+				if path.Len() > 0 {
+					path.WriteByte('.')
+				}
+				path.WriteString(segments[i+1])
+				i++
+			}
+		case "items":
+			path.WriteString("[]")
+		}
+	}
+	return path.String()
+}
@@ -2,6 +2,7 @@ package infer
 
 import (
 	"context"
+	"reflect"
 	"testing"
 )
 
@@ -104,3 +105,63 @@ func TestToMap_NilSchema(t *testing.T) {
 		t.Fatal("Expected error for nil schema")
 	}
 }
+
+func TestFromReflectType(t *testing.T) {
+	type tagged struct {
+		Unit string `json:"unit" jsonschema:"enum=c|f"`
+	}
+	schema, err := FromReflectType(reflect.TypeOf(tagged{}))
+	if err != nil {
+		t.Fatalf("FromReflectType failed: %v", err)
+	}
+	if unit := schema.Properties["unit"]; unit == nil || len(unit.Enum) != 2 {
+		t.Errorf("expected the constraint tag to apply, got %+v", unit)
+	}
+	if _, err := FromReflectType(nil); err == nil {
+		t.Error("expected an error for a nil type")
+	}
+}
+
+func TestToMap_AnyOutput(t *testing.T) {
+	_, output, err := FromFunc(func(ctx context.Context, in struct{}) (interface{}, error) { return nil, nil })
+	if err != nil {
+		t.Fatalf("FromFunc failed: %v", err)
+	}
+	schemaMap, err := ToMap(output)
+	if err != nil {
+		t.Fatalf("ToMap failed: %v", err)
+	}
+	if len(schemaMap) != 0 {
+		t.Errorf("expected an empty schema for interface{}, got %v", schemaMap)
+	}
+}
+
+func TestValidate_FieldPaths(t *testing.T) {
+	schema, err := FromFuncInput(func(ctx context.Context, in ComplexType) (string, error) { return "", nil })
+	if err != nil {
+		t.Fatalf("FromFuncInput failed: %v", err)
+	}
+	schemaMap, _ := ToMap(schema)
+	validator, err := Compile(schemaMap)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	valid := `{"id":1,"person":{"name":"ada","age":36},"address":{"street":"s","city":"c","zip_code":"z"},"tags":[],"metadata":{}}`
+	if err := validator.Validate([]byte(valid)); err != nil {
+		t.Errorf("expected valid value, got %v", err)
+	}
+
+	tests := map[string]string{
+		`{"id":1,"person":{"name":"ada","age":"old"},"address":{"street":"s","city":"c","zip_code":"z"},"tags":[],"metadata":{}}`: "person.age",
+		`{"id":1,"person":{"name":"ada","age":1},"address":{"street":"s","city":"c","zip_code":"z"},"tags":[7],"metadata":{}}`:    "tags[]",
+		`{"id":1}`: "",
+	}
+	for value, field := range tests {
+		err := validator.Validate([]byte(value))
+		violation, ok := err.(*ValidationError)
+		if !ok || violation.Field != field || violation.Message == "" {
+			t.Errorf("expected a violation at %q for %s, got %#v", field, value, err)
+		}
+	}
+}
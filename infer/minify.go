@@ -0,0 +1,213 @@
+package infer
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Minify returns a smaller copy of a JSON schema map with the same validation behaviour,
+// for sending to models where every token counts. It:
+//   - drops "title" annotations
+//   - inlines $defs/definitions entries referenced exactly once (recursive definitions stay)
+//   - collapses allOf with a single subschema into its parent
+//   - prunes definitions no longer referenced
+//
+// The input is not modified. Descriptions are kept, since models rely on them.
+func Minify(schema map[string]interface{}) map[string]interface{} {
+	if schema == nil {
+		return nil
+	}
+	root, ok := deepCopy(schema).(map[string]interface{})
+	if !ok {
+		return schema
+	}
+
+	defs := collectDefs(root)
+	if len(defs) > 0 {
+		counts := make(map[string]int)
+		countRefs(root, counts)
+		root = inlineRefs(root, defs, counts, nil).(map[string]interface{})
+		pruneDefs(root)
+	}
+
+	return minifyNode(root).(map[string]interface{})
+}
+
+// defsKeys are the keywords holding reusable definitions
+var defsKeys = []string{"$defs", "definitions"}
+
+// collectDefs maps local reference strings ("#/$defs/Name") to their definitions
+func collectDefs(root map[string]interface{}) map[string]interface{} {
+	defs := make(map[string]interface{})
+	for _, key := range defsKeys {
+		if entries, ok := root[key].(map[string]interface{}); ok {
+			for name, def := range entries {
+				defs["#/"+key+"/"+name] = def
+			}
+		}
+	}
+	return defs
+}
+
+// countRefs counts references to each local definition
+func countRefs(node interface{}, counts map[string]int) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok && strings.HasPrefix(ref, "#/") {
+			counts[ref]++
+		}
+		for _, child := range v {
+			countRefs(child, counts)
+		}
+	case []interface{}:
+		for _, child := range v {
+			countRefs(child, counts)
+		}
+	}
+}
+
+// inlineRefs replaces bare references to definitions used once with the definition itself.
+// expanding tracks definitions being inlined, so recursive definitions are left as references.
+func inlineRefs(node interface{}, defs map[string]interface{}, counts map[string]int, expanding map[string]bool) interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok && len(v) == 1 && counts[ref] == 1 && !expanding[ref] {
+			if def, ok := defs[ref]; ok && !refersTo(def, ref) {
+				next := map[string]bool{ref: true}
+				for k := range expanding {
+					next[k] = true
+				}
+				return inlineRefs(deepCopy(def), defs, counts, next)
+			}
+		}
+		for key, child := range v {
+			v[key] = inlineRefs(child, defs, counts, expanding)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = inlineRefs(child, defs, counts, expanding)
+		}
+		return v
+	}
+	return node
+}
+
+// refersTo reports whether node contains a reference to ref
+func refersTo(node interface{}, ref string) bool {
+	counts := make(map[string]int)
+	countRefs(node, counts)
+	return counts[ref] > 0
+}
+
+// pruneDefs removes definitions that are no longer referenced, along with empty definition blocks
+func pruneDefs(root map[string]interface{}) {
+	for {
+		counts := make(map[string]int)
+		for key, child := range root {
+			if key == "$defs" || key == "definitions" {
+				continue
+			}
+			countRefs(child, counts)
+		}
+		// References from definitions that are still used count too
+		for _, key := range defsKeys {
+			if entries, ok := root[key].(map[string]interface{}); ok {
+				for name, def := range entries {
+					if counts["#/"+key+"/"+name] > 0 {
+						countRefs(def, counts)
+					}
+				}
+			}
+		}
+
+		removed := false
+		for _, key := range defsKeys {
+			entries, ok := root[key].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for name := range entries {
+				if counts["#/"+key+"/"+name] == 0 {
+					delete(entries, name)
+					removed = true
+				}
+			}
+			if len(entries) == 0 {
+				delete(root, key)
+			}
+		}
+		if !removed {
+			return
+		}
+	}
+}
+
+// subschemaMaps are keywords whose values map names to subschemas
+var subschemaMaps = map[string]bool{
+	"properties": true, "patternProperties": true, "$defs": true, "definitions": true, "dependentSchemas": true,
+}
+
+// minifyNode drops titles and collapses single-variant allOf. Only schema positions are
+// rewritten, so a property that happens to be named "title" is kept.
+func minifyNode(node interface{}) interface{} {
+	schema, ok := node.(map[string]interface{})
+	if !ok {
+		return node
+	}
+
+	delete(schema, "title")
+
+	for key, child := range schema {
+		switch value := child.(type) {
+		case map[string]interface{}:
+			if subschemaMaps[key] {
+				for name, sub := range value {
+					value[name] = minifyNode(sub)
+				}
+			} else if key != "const" && key != "default" && key != "enum" && key != "examples" {
+				schema[key] = minifyNode(value)
+			}
+		case []interface{}:
+			if key == "allOf" || key == "anyOf" || key == "oneOf" || key == "prefixItems" || key == "items" {
+				for i, sub := range value {
+					value[i] = minifyNode(sub)
+				}
+			}
+		}
+	}
+
+	// A single-variant allOf is equivalent to merging that subschema into its parent
+	if allOf, ok := schema["allOf"].([]interface{}); ok && len(allOf) == 1 {
+		if sub, ok := allOf[0].(map[string]interface{}); ok && !conflicts(schema, sub) {
+			delete(schema, "allOf")
+			for k, v := range sub {
+				schema[k] = v
+			}
+		}
+	}
+	return schema
+}
+
+// conflicts reports whether merging sub into schema would overwrite a keyword
+func conflicts(schema, sub map[string]interface{}) bool {
+	for k := range sub {
+		if _, exists := schema[k]; exists && k != "allOf" {
+			return true
+		}
+	}
+	return false
+}
+
+// deepCopy copies a decoded JSON value via a JSON round trip
+func deepCopy(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return v
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package infer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decodeSchema(t *testing.T, s string) map[string]interface{} {
+	t.Helper()
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		t.Fatalf("invalid test schema: %v", err)
+	}
+	return m
+}
+
+func TestMinify(t *testing.T) {
+	input := decodeSchema(t, `{
+		"title": "Request",
+		"type": "object",
+		"properties": {
+			"title": {"type": "string", "title": "Title"},
+			"address": {"$ref": "#/$defs/Address"},
+			"tree": {"$ref": "#/$defs/Node"},
+			"mode": {"allOf": [{"type": "string", "enum": ["a", "b"]}], "description": "Mode"}
+		},
+		"$defs": {
+			"Address": {"type": "object", "title": "Address", "properties": {"city": {"type": "string"}}},
+			"Node": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/$defs/Node"}}}},
+			"Unused": {"type": "string"}
+		}
+	}`)
+	original := decodeSchema(t, `{}`)
+	data, _ := json.Marshal(input)
+	json.Unmarshal(data, &original)
+
+	want := decodeSchema(t, `{
+		"type": "object",
+		"properties": {
+			"title": {"type": "string"},
+			"address": {"type": "object", "properties": {"city": {"type": "string"}}},
+			"tree": {"$ref": "#/$defs/Node"},
+			"mode": {"type": "string", "enum": ["a", "b"], "description": "Mode"}
+		},
+		"$defs": {
+			"Node": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/$defs/Node"}}}}
+		}
+	}`)
+
+	got := Minify(input)
+	if !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.MarshalIndent(got, "", "  ")
+		t.Errorf("unexpected minified schema:\n%s", gotJSON)
+	}
+	if !reflect.DeepEqual(input, original) {
+		t.Error("Minify modified its input")
+	}
+}
+
+func TestMinify_Nil(t *testing.T) {
+	if Minify(nil) != nil {
+		t.Error("expected nil for a nil schema")
+	}
+}
@@ -0,0 +1,363 @@
+package infer
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// DescriptionTag is the struct tag whose value documents a property, as an alternative
+// to the jsonschema tag:
+//
+//	type SearchInput struct {
+//	    Query string `json:"query" description:"full-text search terms"`
+//	    Limit int    `json:"limit,omitempty" description:"maximum results, default 10"`
+//	}
+//
+// Unlike jsonschema tags, descriptions may start with "WORD=". When a field has both
+// tags, the description tag wins.
+const DescriptionTag = "description"
+
+// OptionalTag marks a field optional in the generated schema, leaving it out of the
+// object's required list:
+//
+//	type SearchInput struct {
+//	    Query string `json:"query"`
+//	    Limit int    `json:"limit" optional:"true"`
+//	}
+//
+// Fields tagged omitempty or omitzero, and pointer fields, are optional without it. Every
+// other field is required.
+const OptionalTag = "optional"
+
+// A jsonschema tag made of comma-separated key=value pairs sets constraints instead of
+// the description:
+//
+//	Unit  string    `json:"unit" jsonschema:"enum=c|f|k,default=c"`
+//	At    time.Time `json:"at" jsonschema:"format=date-time"`
+//	Limit int       `json:"limit" jsonschema:"minimum=1,maximum=100,description=results per page"`
+//
+// Supported keys are description, enum (values separated by |), default, format,
+// pattern, minimum, maximum, exclusiveMinimum, exclusiveMaximum, minLength, maxLength,
+// minItems and maxItems. Values are parsed according to the property's type. On array
+// properties, enum, format, pattern and the length and range bounds apply to the items.
+// A description may contain commas if it is the last pair.
+var constraintTagRegexp = regexp.MustCompile(`^[^ \t\n]*=`)
+
+// forType generates the schema of T with description and constraint tags applied
+func forType[T any]() (*jsonschema.Schema, error) {
+	return forReflectType(reflect.TypeFor[T]())
+}
+
+// forReflectType generates the schema of t with description and constraint tags applied
+func forReflectType(t reflect.Type) (*jsonschema.Schema, error) {
+	shadow, err := withoutConstraintTags(t, map[reflect.Type]reflect.Type{}, map[reflect.Type]bool{})
+	if err != nil {
+		return nil, err
+	}
+	s, err := jsonschema.ForType(shadow, &jsonschema.ForOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if err := applyTags(t, s, map[reflect.Type]bool{}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// withoutConstraintTags returns t, or an equivalent type whose structs carry no constraint
+// tags, which jsonschema.For rejects. Only types that need it are rebuilt, so types with
+// special schemas such as time.Time keep their identity. Rebuilt structs list their
+// visible fields directly, as jsonschema.For flattens embedded structs anyway.
+func withoutConstraintTags(t reflect.Type, done map[reflect.Type]reflect.Type, inProgress map[reflect.Type]bool) (reflect.Type, error) {
+	if rebuilt, ok := done[t]; ok {
+		return rebuilt, nil
+	}
+	if inProgress[t] {
+		return nil, fmt.Errorf("cycle detected for type %v", t)
+	}
+	inProgress[t] = true
+	defer delete(inProgress, t)
+
+	rebuilt := t
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		elem, err := withoutConstraintTags(t.Elem(), done, inProgress)
+		if err != nil {
+			return nil, err
+		}
+		if elem != t.Elem() {
+			switch t.Kind() {
+			case reflect.Pointer:
+				rebuilt = reflect.PointerTo(elem)
+			case reflect.Slice:
+				rebuilt = reflect.SliceOf(elem)
+			case reflect.Array:
+				rebuilt = reflect.ArrayOf(t.Len(), elem)
+			case reflect.Map:
+				rebuilt = reflect.MapOf(t.Key(), elem)
+			}
+		}
+
+	case reflect.Struct:
+		var fields []reflect.StructField
+		changed := false
+		for _, field := range reflect.VisibleFields(t) {
+			if field.Anonymous || !field.IsExported() {
+				changed = changed || field.Anonymous
+				continue
+			}
+			fieldType, err := withoutConstraintTags(field.Type, done, inProgress)
+			if err != nil {
+				return nil, err
+			}
+			tag := field.Tag
+			if schemaTag, ok := tag.Lookup("jsonschema"); ok && constraintTagRegexp.MatchString(schemaTag) {
+				tag = stripTag(tag, "jsonschema")
+			}
+			changed = changed || fieldType != field.Type || tag != field.Tag || len(field.Index) > 1
+			fields = append(fields, reflect.StructField{Name: field.Name, Type: fieldType, Tag: tag})
+		}
+		if changed {
+			rebuilt = reflect.StructOf(fields)
+		}
+	}
+
+	done[t] = rebuilt
+	return rebuilt, nil
+}
+
+// stripTag removes one key from a struct tag
+func stripTag(tag reflect.StructTag, key string) reflect.StructTag {
+	var kept []string
+	for _, other := range []string{"json", DescriptionTag} {
+		if value, ok := tag.Lookup(other); ok && other != key {
+			kept = append(kept, fmt.Sprintf("%s:%q", other, value))
+		}
+	}
+	return reflect.StructTag(strings.Join(kept, " "))
+}
+
+// applyTags copies description and constraint tags of t's fields onto the matching
+// properties of s, walking the type the way jsonschema.For does
+func applyTags(t reflect.Type, s *jsonschema.Schema, seen map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if s == nil || seen[t] {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return applyTags(t.Elem(), s.Items, seen)
+
+	case reflect.Map:
+		return applyTags(t.Elem(), s.AdditionalProperties, seen)
+
+	case reflect.Struct:
+		seen[t] = true
+		defer delete(seen, t)
+
+		required := []string{}
+		for _, field := range reflect.VisibleFields(t) {
+			if field.Anonymous || !field.IsExported() {
+				continue
+			}
+			name := jsonName(field)
+			prop := s.Properties[name]
+			if prop == nil {
+				continue
+			}
+			optional, err := isOptional(field)
+			if err != nil {
+				return fmt.Errorf("field %s.%s: %w", t, field.Name, err)
+			}
+			if !optional {
+				required = append(required, name)
+			}
+			if schemaTag, ok := field.Tag.Lookup("jsonschema"); ok && constraintTagRegexp.MatchString(schemaTag) {
+				if err := applyConstraints(prop, schemaTag); err != nil {
+					return fmt.Errorf("field %s.%s: %w", t, field.Name, err)
+				}
+			}
+			if description := field.Tag.Get(DescriptionTag); description != "" {
+				prop.Description = description
+			}
+			if err := applyTags(field.Type, prop, seen); err != nil {
+				return err
+			}
+		}
+		if s.Properties != nil {
+			s.Required = required
+		}
+	}
+	return nil
+}
+
+// isOptional reports whether field may be left out of a JSON object
+func isOptional(field reflect.StructField) (bool, error) {
+	if value, ok := field.Tag.Lookup(OptionalTag); ok {
+		optional, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, fmt.Errorf("invalid %s tag %q", OptionalTag, value)
+		}
+		if optional {
+			return true, nil
+		}
+	}
+	if field.Type.Kind() == reflect.Pointer {
+		return true, nil
+	}
+	_, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" || option == "omitzero" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// jsonName returns the property name encoding/json uses for field, or "" when the field
+// is omitted
+func jsonName(field reflect.StructField) string {
+	name, _, found := strings.Cut(field.Tag.Get("json"), ",")
+	switch {
+	case name == "-" && !found:
+		return ""
+	case name == "":
+		return field.Name
+	}
+	return name
+}
+
+// applyConstraints parses a key=value constraint tag onto prop
+func applyConstraints(prop *jsonschema.Schema, tag string) error {
+	for _, pair := range splitConstraints(tag) {
+		key, value, _ := strings.Cut(pair, "=")
+
+		// Constraints on values apply to the items of an array
+		target := prop
+		if schemaType(prop) == "array" && prop.Items != nil {
+			switch key {
+			case "enum", "format", "pattern", "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "minLength", "maxLength":
+				target = prop.Items
+			}
+		}
+
+		var err error
+		switch key {
+		case "description":
+			prop.Description = value
+		case "enum":
+			target.Enum = nil
+			for _, option := range strings.Split(value, "|") {
+				parsed, perr := parseValue(target, option)
+				if perr != nil {
+					return fmt.Errorf("enum value %q: %w", option, perr)
+				}
+				target.Enum = append(target.Enum, parsed)
+			}
+		case "default":
+			var parsed interface{}
+			if parsed, err = parseValue(prop, value); err == nil {
+				prop.Default, err = json.Marshal(parsed)
+			}
+		case "format":
+			target.Format = value
+		case "pattern":
+			if _, err = regexp.Compile(value); err == nil {
+				target.Pattern = value
+			}
+		case "minimum":
+			target.Minimum, err = parseFloat(value)
+		case "maximum":
+			target.Maximum, err = parseFloat(value)
+		case "exclusiveMinimum":
+			target.ExclusiveMinimum, err = parseFloat(value)
+		case "exclusiveMaximum":
+			target.ExclusiveMaximum, err = parseFloat(value)
+		case "minLength":
+			target.MinLength, err = parseInt(value)
+		case "maxLength":
+			target.MaxLength, err = parseInt(value)
+		case "minItems":
+			prop.MinItems, err = parseInt(value)
+		case "maxItems":
+			prop.MaxItems, err = parseInt(value)
+		default:
+			return fmt.Errorf("unknown jsonschema tag key %q", key)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// splitConstraints splits a tag into key=value pairs. A segment without "=" continues
+// the previous value, so a trailing description may contain commas.
+func splitConstraints(tag string) []string {
+	var pairs []string
+	for _, segment := range strings.Split(tag, ",") {
+		if len(pairs) > 0 && !strings.Contains(segment, "=") {
+			pairs[len(pairs)-1] += "," + segment
+			continue
+		}
+		pairs = append(pairs, segment)
+	}
+	return pairs
+}
+
+// schemaType returns the non-null type of s
+func schemaType(s *jsonschema.Schema) string {
+	if s.Type != "" {
+		return s.Type
+	}
+	for _, t := range s.Types {
+		if t != "null" {
+			return t
+		}
+	}
+	return ""
+}
+
+// parseValue converts a tag value to the JSON value of s's type
+func parseValue(s *jsonschema.Schema, value string) (interface{}, error) {
+	switch schemaType(s) {
+	case "string":
+		return value, nil
+	case "integer":
+		return strconv.ParseInt(value, 10, 64)
+	case "number":
+		return strconv.ParseFloat(value, 64)
+	case "boolean":
+		return strconv.ParseBool(value)
+	}
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		return nil, fmt.Errorf("not valid JSON: %w", err)
+	}
+	return parsed, nil
+}
+
+func parseFloat(value string) (*float64, error) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func parseInt(value string) (*int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
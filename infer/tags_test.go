@@ -0,0 +1,211 @@
+package infer
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type describedFilter struct {
+	Field string `json:"field" description:"column to filter on"`
+	Value string `json:"value" jsonschema:"value to match"`
+}
+
+type describedEmbedded struct {
+	Page int `json:"page,omitempty" description:"page number, starting at 1"`
+}
+
+type describedInput struct {
+	describedEmbedded
+	Query   string                     `json:"query" description:"unit=ms is allowed here"`
+	Both    string                     `json:"both" jsonschema:"library description" description:"tag description"`
+	Filters []describedFilter          `json:"filters,omitempty"`
+	Owner   *describedFilter           `json:"owner,omitempty"`
+	ByName  map[string]describedFilter `json:"by_name,omitempty"`
+	Hidden  string                     `json:"-" description:"never shown"`
+}
+
+func TestDescriptionTags(t *testing.T) {
+	schema, err := FromType[describedInput]()
+	if err != nil {
+		t.Fatalf("FromType failed: %v", err)
+	}
+
+	tests := map[string]string{
+		"query":                 schema.Properties["query"].Description,
+		"both":                  schema.Properties["both"].Description,
+		"page":                  schema.Properties["page"].Description,
+		"filters[].field":       schema.Properties["filters"].Items.Properties["field"].Description,
+		"filters[].value":       schema.Properties["filters"].Items.Properties["value"].Description,
+		"owner.field":           schema.Properties["owner"].Properties["field"].Description,
+		"by_name[string].field": schema.Properties["by_name"].AdditionalProperties.Properties["field"].Description,
+	}
+	want := map[string]string{
+		"query":                 "unit=ms is allowed here",
+		"both":                  "tag description",
+		"page":                  "page number, starting at 1",
+		"filters[].field":       "column to filter on",
+		"filters[].value":       "value to match",
+		"owner.field":           "column to filter on",
+		"by_name[string].field": "column to filter on",
+	}
+	for path, got := range tests {
+		if got != want[path] {
+			t.Errorf("%s: expected description %q, got %q", path, want[path], got)
+		}
+	}
+	if _, ok := schema.Properties["Hidden"]; ok {
+		t.Error("expected json:\"-\" fields to stay omitted")
+	}
+
+	input, _, err := FromFunc(func(ctx context.Context, in describedInput) (describedFilter, error) { return describedFilter{}, nil })
+	if err != nil || input.Properties["query"].Description == "" {
+		t.Errorf("expected FromFunc to apply description tags, got %v", err)
+	}
+}
+
+type constrainedInput struct {
+	Unit   string    `json:"unit" jsonschema:"enum=c|f|k,default=c"`
+	Since  time.Time `json:"since" jsonschema:"format=date-time"`
+	Limit  int       `json:"limit,omitempty" jsonschema:"minimum=1,maximum=100,description=rows per page, at most 100"`
+	Ratio  *float64  `json:"ratio,omitempty" jsonschema:"exclusiveMinimum=0,exclusiveMaximum=1"`
+	Code   string    `json:"code,omitempty" jsonschema:"pattern=^[A-Z]{3}$,minLength=3,maxLength=3"`
+	Fields []string  `json:"fields,omitempty" jsonschema:"enum=temp|humidity,minItems=1,maxItems=2"`
+	Levels []int     `json:"levels,omitempty" jsonschema:"enum=1|2|3"`
+	Nested *constrainedFilter
+	constrainedPage
+}
+
+type constrainedFilter struct {
+	Op string `json:"op" jsonschema:"enum=eq|ne" description:"comparison operator"`
+}
+
+type constrainedPage struct {
+	Size int `json:"size,omitempty" jsonschema:"default=20"`
+}
+
+func TestConstraintTags(t *testing.T) {
+	schema, err := FromType[constrainedInput]()
+	if err != nil {
+		t.Fatalf("FromType failed: %v", err)
+	}
+	props := schema.Properties
+
+	if got := props["unit"].Enum; !reflect.DeepEqual(got, []any{"c", "f", "k"}) {
+		t.Errorf("unit: expected enum [c f k], got %v", got)
+	}
+	if got := string(props["unit"].Default); got != `"c"` {
+		t.Errorf("unit: expected default \"c\", got %s", got)
+	}
+	if got := props["since"].Format; got != "date-time" {
+		t.Errorf("since: expected format date-time, got %q", got)
+	}
+	if props["limit"].Minimum == nil || *props["limit"].Minimum != 1 || props["limit"].Maximum == nil || *props["limit"].Maximum != 100 {
+		t.Errorf("limit: expected range 1..100, got %v..%v", props["limit"].Minimum, props["limit"].Maximum)
+	}
+	if got := props["limit"].Description; got != "rows per page, at most 100" {
+		t.Errorf("limit: expected description with comma, got %q", got)
+	}
+	if props["ratio"].ExclusiveMinimum == nil || *props["ratio"].ExclusiveMaximum != 1 {
+		t.Errorf("ratio: expected exclusive bounds, got %+v", props["ratio"])
+	}
+	if props["code"].Pattern != "^[A-Z]{3}$" || *props["code"].MinLength != 3 || *props["code"].MaxLength != 3 {
+		t.Errorf("code: expected pattern and length bounds, got %+v", props["code"])
+	}
+	if got := props["fields"].Items.Enum; !reflect.DeepEqual(got, []any{"temp", "humidity"}) {
+		t.Errorf("fields: expected item enum, got %v", got)
+	}
+	if props["fields"].MinItems == nil || *props["fields"].MaxItems != 2 {
+		t.Errorf("fields: expected item count bounds, got %+v", props["fields"])
+	}
+	if got := props["levels"].Items.Enum; !reflect.DeepEqual(got, []any{int64(1), int64(2), int64(3)}) {
+		t.Errorf("levels: expected integer enum, got %v", got)
+	}
+	op := props["Nested"].Properties["op"]
+	if !reflect.DeepEqual(op.Enum, []any{"eq", "ne"}) || op.Description != "comparison operator" {
+		t.Errorf("Nested.op: expected enum and description, got %+v", op)
+	}
+	if got := string(props["size"].Default); got != "20" {
+		t.Errorf("size: expected embedded default 20, got %s", got)
+	}
+
+	schemaMap, err := ToMap(schema)
+	if err != nil {
+		t.Fatalf("ToMap failed: %v", err)
+	}
+	validator, err := Compile(schemaMap)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if err := validator.Validate(json.RawMessage(`{"unit":"c","since":"2024-01-02T03:04:05Z","Nested":{"op":"eq"}}`)); err != nil {
+		t.Errorf("expected valid input to pass, got %v", err)
+	}
+	if err := validator.Validate(json.RawMessage(`{"unit":"x","since":"2024-01-02T03:04:05Z","Nested":{"op":"eq"}}`)); err == nil {
+		t.Error("expected unit outside enum to fail validation")
+	}
+	if err := validator.Validate(json.RawMessage(`{"unit":"c","since":"2024-01-02T03:04:05Z","limit":500,"Nested":{"op":"eq"}}`)); err == nil {
+		t.Error("expected limit above maximum to fail validation")
+	}
+}
+
+func TestConstraintTags_Invalid(t *testing.T) {
+	type unknownKey struct {
+		Name string `json:"name" jsonschema:"colour=red"`
+	}
+	if _, err := FromType[unknownKey](); err == nil || !strings.Contains(err.Error(), "colour") {
+		t.Errorf("expected error naming the unknown key, got %v", err)
+	}
+
+	type badEnum struct {
+		Count int `json:"count" jsonschema:"enum=1|two"`
+	}
+	if _, err := FromType[badEnum](); err == nil {
+		t.Error("expected error for non-integer enum value on int field")
+	}
+}
+
+type requiredInput struct {
+	Query    string            `json:"query"`
+	Limit    int               `json:"limit" optional:"true"`
+	Cursor   string            `json:"cursor,omitempty"`
+	Since    *time.Time        `json:"since"`
+	Explicit string            `json:"explicit" optional:"false"`
+	Filter   requiredFilter    `json:"filter"`
+	Extra    map[string]string `json:"extra,omitzero"`
+}
+
+type requiredFilter struct {
+	Field string `json:"field,omitempty"`
+}
+
+func TestRequiredFields(t *testing.T) {
+	schema, err := FromType[requiredInput]()
+	if err != nil {
+		t.Fatalf("FromType failed: %v", err)
+	}
+	if want := []string{"query", "explicit", "filter"}; !reflect.DeepEqual(schema.Required, want) {
+		t.Errorf("expected required %v, got %v", want, schema.Required)
+	}
+
+	m, err := ToMap(schema)
+	if err != nil {
+		t.Fatalf("ToMap failed: %v", err)
+	}
+	filter := m["properties"].(map[string]interface{})["filter"].(map[string]interface{})
+	if required, ok := filter["required"].([]interface{}); !ok || len(required) != 0 {
+		t.Errorf("expected empty required array on filter, got %v", filter["required"])
+	}
+	if _, ok := m["properties"].(map[string]interface{})["since"].(map[string]interface{})["required"]; ok {
+		t.Error("expected no required keyword on non-object schemas")
+	}
+
+	type badTag struct {
+		Name string `json:"name" optional:"maybe"`
+	}
+	if _, err := FromType[badTag](); err == nil || !strings.Contains(err.Error(), "optional") {
+		t.Errorf("expected error for invalid optional tag, got %v", err)
+	}
+}
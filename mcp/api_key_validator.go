@@ -14,8 +14,8 @@ const (
 // DEVKeyValidator is a simple validator for development and testing ONLY.
 //
 // WARNING: This validator uses a hardcoded key and should NEVER be used in production.
-// For production deployments, implement your own APIKeyValidator with secure key storage,
-// constant-time comparison, rate limiting, and proper logging.
+// For production deployments, use ConstantTimeStaticValidator, HashedKeyValidator,
+// JWTValidator, or wrap your own backend with CachingValidator.
 //
 // See the Security section in README.md for production implementation examples.
 type DEVKeyValidator struct{}
@@ -0,0 +1,280 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// Audit replay
+const (
+	// MethodAuditReplay is a vendor extension that replays an audited tools/call by record ID
+	// and diffs the new outcome against the recorded one. It is served when the server has an
+	// audit log.
+	MethodAuditReplay = "minimcp/audit/replay"
+
+	// AuditScope is the scope an authenticated caller needs to replay audited calls
+	AuditScope = "minimcp:audit"
+
+	// AuditRecordMetaKey carries the audit record ID in the _meta of tools/call results
+	AuditRecordMetaKey = "minimcp/auditRecordId"
+
+	// ReplayNeedsConfirmation is the JSON-RPC error code returned by MethodAuditReplay when a
+	// destructive tool is replayed without confirmDestructive
+	ReplayNeedsConfirmation = -32004
+)
+
+// ErrAuditRecordNotFound is returned when an audit record ID is unknown to the log
+var ErrAuditRecordNotFound = errors.New("audit record not found")
+
+// ErrReplayNeedsConfirmation is returned when replaying a destructive tool without ReplayOptions.ConfirmDestructive
+var ErrReplayNeedsConfirmation = errors.New("replaying a destructive tool requires confirmation")
+
+// AuditRecord captures a single tools/call invocation and its outcome
+type AuditRecord struct {
+	ID        string           `json:"id"`
+	Time      time.Time        `json:"time"`
+	Tool      string           `json:"tool"`
+	Arguments json.RawMessage  `json:"arguments,omitempty"`
+	Result    *ToolsCallResult `json:"result,omitempty"`
+	Error     *RPCError        `json:"error,omitempty"`
+	Duration  time.Duration    `json:"duration"`
+}
+
+// AuditLog stores audit records for tool invocations.
+// Implementations must be safe for concurrent use.
+type AuditLog interface {
+	Append(ctx context.Context, record AuditRecord) error
+	Get(ctx context.Context, id string) (AuditRecord, error)
+
+	// List returns the stored records, oldest first
+	List(ctx context.Context) ([]AuditRecord, error)
+}
+
+// MemoryAuditLog is an in-memory AuditLog that keeps the most recent records
+type MemoryAuditLog struct {
+	mu         sync.Mutex
+	maxRecords int
+	order      []string
+	records    map[string]AuditRecord
+}
+
+// NewMemoryAuditLog creates an in-memory audit log holding at most maxRecords records.
+// A maxRecords of 0 or less keeps every record.
+func NewMemoryAuditLog(maxRecords int) *MemoryAuditLog {
+	return &MemoryAuditLog{
+		maxRecords: maxRecords,
+		records:    make(map[string]AuditRecord),
+	}
+}
+
+// Append stores a record, evicting the oldest record when the log is full
+func (l *MemoryAuditLog) Append(ctx context.Context, record AuditRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, exists := l.records[record.ID]; !exists {
+		l.order = append(l.order, record.ID)
+	}
+	l.records[record.ID] = record
+
+	if l.maxRecords > 0 && len(l.order) > l.maxRecords {
+		oldest := l.order[0]
+		l.order = l.order[1:]
+		delete(l.records, oldest)
+	}
+	return nil
+}
+
+// Get returns the record with the given ID
+func (l *MemoryAuditLog) Get(ctx context.Context, id string) (AuditRecord, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record, ok := l.records[id]
+	if !ok {
+		return AuditRecord{}, fmt.Errorf("%w: %s", ErrAuditRecordNotFound, id)
+	}
+	return record, nil
+}
+
+// List returns the stored records, oldest first
+func (l *MemoryAuditLog) List(ctx context.Context) ([]AuditRecord, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	records := make([]AuditRecord, 0, len(l.order))
+	for _, id := range l.order {
+		records = append(records, l.records[id])
+	}
+	return records, nil
+}
+
+// recordAudit appends a record for a completed tools/call when an audit log is configured,
+// returning the record's ID, or "" when nothing was recorded
+func (s *Server) recordAudit(ctx context.Context, params ToolsCallParams, result *ToolsCallResult, rpcErr *RPCError, start time.Time) string {
+	if s.auditLog == nil {
+		return ""
+	}
+
+	record := AuditRecord{
+		ID:        newAuditID(),
+		Time:      start,
+		Tool:      params.Name,
+		Arguments: params.Arguments,
+		Result:    result,
+		Error:     rpcErr,
+		Duration:  time.Since(start),
+	}
+
+	if err := s.auditLog.Append(ctx, record); err != nil {
+		s.logger.Error("failed to write audit record", "tool", params.Name, "error", err)
+		return ""
+	}
+	s.logger.Debug("audit record written", "id", record.ID, "tool", params.Name)
+	return record.ID
+}
+
+// newAuditID returns a random 128-bit hex identifier
+func newAuditID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// AuditReplayParams represents parameters for MethodAuditReplay
+type AuditReplayParams struct {
+	// ID is the audit record to replay, as reported under AuditRecordMetaKey
+	ID string `json:"id"`
+
+	// ConfirmDestructive must be set to replay tools whose spec is marked Destructive
+	ConfirmDestructive bool `json:"confirmDestructive,omitempty"`
+}
+
+// ReplayOptions controls how an audited call is re-executed
+type ReplayOptions struct {
+	// ConfirmDestructive must be set to replay tools whose spec is marked Destructive
+	ConfirmDestructive bool
+}
+
+// ReplayResult holds the outcome of re-executing an audited call
+type ReplayResult struct {
+	Record  AuditRecord      `json:"record"`
+	Result  *ToolsCallResult `json:"result,omitempty"`
+	Error   *RPCError        `json:"error,omitempty"`
+	Changed bool             `json:"changed"`
+	Diff    []string         `json:"diff,omitempty"`
+}
+
+// Replay re-executes the exact call captured in the audit record with the given ID
+// and diffs the new outcome against the recorded one. Replays are not themselves audited.
+func (s *Server) Replay(ctx context.Context, recordID string, opts ReplayOptions) (*ReplayResult, error) {
+	if s.auditLog == nil {
+		return nil, fmt.Errorf("no audit log configured")
+	}
+
+	record, err := s.auditLog.Get(ctx, recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tool := range s.GetTools() {
+		spec := tool.Spec()
+		if spec.Name == record.Tool && spec.Destructive && !opts.ConfirmDestructive {
+			return nil, fmt.Errorf("%w: %s", ErrReplayNeedsConfirmation, record.Tool)
+		}
+	}
+
+	s.logger.Info("replaying audited tool call", "id", record.ID, "tool", record.Tool)
+
+	result, rpcErr := s.callTool(ctx, ToolsCallParams{
+		Name:      record.Tool,
+		Arguments: record.Arguments,
+	})
+
+	replay := &ReplayResult{
+		Record: record,
+		Result: result,
+		Error:  rpcErr,
+	}
+	replay.Diff = diffCallOutcome(record.Result, record.Error, replay.Result, replay.Error)
+	replay.Changed = len(replay.Diff) > 0
+
+	return replay, nil
+}
+
+// handleAuditReplay serves MethodAuditReplay. Authenticated callers need AuditScope.
+func (h *JSONRPCHandler) handleAuditReplay(ctx context.Context, params json.RawMessage) (interface{}, *RPCError) {
+	if h.server.auditLog == nil {
+		return nil, &RPCError{Code: MethodNotFound, Message: fmt.Sprintf("Method not found: %s", MethodAuditReplay)}
+	}
+	if !canUse(ctx, &tools.ToolSpec{RequiredScopes: []string{AuditScope}}) {
+		return nil, &RPCError{Code: PermissionDenied, Message: fmt.Sprintf("Replaying audited calls requires the %q scope", AuditScope)}
+	}
+
+	var replayParams AuditReplayParams
+	if err := json.Unmarshal(params, &replayParams); err != nil || replayParams.ID == "" {
+		return nil, &RPCError{Code: InvalidParams, Message: "Invalid replay parameters: id is required"}
+	}
+
+	replay, err := h.server.Replay(ctx, replayParams.ID, ReplayOptions{ConfirmDestructive: replayParams.ConfirmDestructive})
+	switch {
+	case errors.Is(err, ErrAuditRecordNotFound):
+		return nil, &RPCError{Code: InvalidParams, Message: err.Error()}
+	case errors.Is(err, ErrReplayNeedsConfirmation):
+		return nil, &RPCError{Code: ReplayNeedsConfirmation, Message: err.Error()}
+	case err != nil:
+		return nil, &RPCError{Code: InternalError, Message: err.Error()}
+	}
+	return replay, nil
+}
+
+// diffCallOutcome describes the differences between a recorded and a replayed call outcome
+func diffCallOutcome(oldResult *ToolsCallResult, oldErr *RPCError, newResult *ToolsCallResult, newErr *RPCError) []string {
+	var diff []string
+
+	switch {
+	case oldErr == nil && newErr != nil:
+		diff = append(diff, fmt.Sprintf("error: recorded none, replayed %d %q", newErr.Code, newErr.Message))
+	case oldErr != nil && newErr == nil:
+		diff = append(diff, fmt.Sprintf("error: recorded %d %q, replayed none", oldErr.Code, oldErr.Message))
+	case oldErr != nil && newErr != nil && (oldErr.Code != newErr.Code || oldErr.Message != newErr.Message):
+		diff = append(diff, fmt.Sprintf("error: recorded %d %q, replayed %d %q",
+			oldErr.Code, oldErr.Message, newErr.Code, newErr.Message))
+	}
+
+	var oldContent, newContent []ContentBlock
+	var oldIsError, newIsError bool
+	if oldResult != nil {
+		oldContent, oldIsError = oldResult.Content, oldResult.IsError
+	}
+	if newResult != nil {
+		newContent, newIsError = newResult.Content, newResult.IsError
+	}
+
+	if oldIsError != newIsError {
+		diff = append(diff, fmt.Sprintf("isError: recorded %t, replayed %t", oldIsError, newIsError))
+	}
+
+	for i := 0; i < len(oldContent) || i < len(newContent); i++ {
+		switch {
+		case i >= len(oldContent):
+			diff = append(diff, fmt.Sprintf("content[%d]: added %s %q", i, newContent[i].Type, newContent[i].Text))
+		case i >= len(newContent):
+			diff = append(diff, fmt.Sprintf("content[%d]: removed %s %q", i, oldContent[i].Type, oldContent[i].Text))
+		case oldContent[i] != newContent[i]:
+			diff = append(diff, fmt.Sprintf("content[%d]: recorded %q, replayed %q", i, oldContent[i].Text, newContent[i].Text))
+		}
+	}
+
+	return diff
+}
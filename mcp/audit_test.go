@@ -0,0 +1,158 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func TestServer_ReplayDiffsAgainstRecord(t *testing.T) {
+	calls := 0
+	tool := &mockTool{
+		name:        "counter",
+		description: "Returns a changing count",
+		parameters:  map[string]interface{}{"type": "object"},
+		executeFn: func(ctx context.Context, params json.RawMessage) (*tools.ToolResult, error) {
+			calls++
+			return &tools.ToolResult{Output: fmt.Sprintf("call %d", calls)}, nil
+		},
+	}
+
+	auditLog := NewMemoryAuditLog(10)
+	server := NewServer(ServerConfig{
+		Name:     "test-server",
+		Version:  "1.0.0",
+		Tools:    []tools.Tool{tool},
+		AuditLog: auditLog,
+	})
+	handler := NewJSONRPCHandler(server)
+
+	msg := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"counter","arguments":{"n":1}}}`
+	recordID := auditedCall(t, handler, msg)
+
+	records, err := auditLog.List(context.Background())
+	if err != nil || len(records) != 1 || records[0].ID != recordID {
+		t.Fatalf("expected the one audit record the result names, got %+v, %v", records, err)
+	}
+
+	replay, err := server.Replay(context.Background(), recordID, ReplayOptions{})
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+
+	if string(replay.Record.Arguments) != `{"n":1}` {
+		t.Errorf("expected recorded arguments to be replayed, got %s", replay.Record.Arguments)
+	}
+	if !replay.Changed {
+		t.Error("expected replay to report a change")
+	}
+	if len(replay.Diff) != 1 {
+		t.Errorf("expected 1 diff line, got %v", replay.Diff)
+	}
+	if records, _ := auditLog.List(context.Background()); len(records) != 1 {
+		t.Errorf("replay should not be audited, got %d records", len(records))
+	}
+}
+
+// auditedCall sends a tools/call and returns the audit record ID from its result's _meta
+func auditedCall(t *testing.T, handler *JSONRPCHandler, msg string) string {
+	t.Helper()
+	resp, err := handler.HandleMessage(context.Background(), []byte(msg))
+	if err != nil {
+		t.Fatalf("HandleMessage failed: %v", err)
+	}
+	result, ok := resp.Result.(ToolsCallResult)
+	if !ok {
+		t.Fatalf("expected a tools/call result, got %+v", resp)
+	}
+	id, _ := result.Meta[AuditRecordMetaKey].(string)
+	if id == "" {
+		t.Fatalf("expected the audit record ID in _meta, got %v", result.Meta)
+	}
+	return id
+}
+
+func TestServer_ReplayDestructiveNeedsConfirmation(t *testing.T) {
+	tool := tools.NewTool("delete_thing", "Deletes a thing", func(ctx context.Context, in struct{}) (string, error) {
+		return "deleted", nil
+	}, tools.WithDestructive(true))
+
+	auditLog := NewMemoryAuditLog(0)
+	server := NewServer(ServerConfig{
+		Name:     "test-server",
+		Version:  "1.0.0",
+		Tools:    []tools.Tool{tool},
+		AuditLog: auditLog,
+	})
+	handler := NewJSONRPCHandler(server)
+
+	msg := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"delete_thing","arguments":{}}}`
+	recordID := auditedCall(t, handler, msg)
+
+	if _, err := server.Replay(context.Background(), recordID, ReplayOptions{}); !errors.Is(err, ErrReplayNeedsConfirmation) {
+		t.Fatalf("expected ErrReplayNeedsConfirmation, got %v", err)
+	}
+
+	replay, err := server.Replay(context.Background(), recordID, ReplayOptions{ConfirmDestructive: true})
+	if err != nil {
+		t.Fatalf("confirmed Replay failed: %v", err)
+	}
+	if replay.Changed {
+		t.Errorf("expected identical replay, got diff %v", replay.Diff)
+	}
+
+	if _, err := server.Replay(context.Background(), "missing", ReplayOptions{}); !errors.Is(err, ErrAuditRecordNotFound) {
+		t.Errorf("expected ErrAuditRecordNotFound, got %v", err)
+	}
+}
+
+func TestJSONRPCHandler_AuditReplay(t *testing.T) {
+	tool := tools.NewTool("delete_thing", "Deletes a thing", func(ctx context.Context, in struct{}) (string, error) {
+		return "deleted", nil
+	}, tools.WithDestructive(true))
+	server := NewServer(ServerConfig{Name: "test-server", Tools: []tools.Tool{tool}, AuditLog: NewMemoryAuditLog(10)})
+	handler := NewJSONRPCHandler(server)
+	recordID := auditedCall(t, handler, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"delete_thing","arguments":{}}}`)
+
+	auditor := &Principal{Subject: "auditor", Scopes: []string{AuditScope}}
+	tests := []struct {
+		name      string
+		principal *Principal
+		params    string
+		code      int
+	}{
+		{"missing scope", &Principal{Subject: "reader"}, `{"id":"` + recordID + `","confirmDestructive":true}`, PermissionDenied},
+		{"unconfirmed destructive tool", auditor, `{"id":"` + recordID + `"}`, ReplayNeedsConfirmation},
+		{"unknown record", auditor, `{"id":"missing"}`, InvalidParams},
+		{"confirmed", auditor, `{"id":"` + recordID + `","confirmDestructive":true}`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithPrincipal(context.Background(), tt.principal)
+			resp, err := handler.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"minimcp/audit/replay","params":`+tt.params+`}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.code != 0 {
+				if resp.Error == nil || resp.Error.Code != tt.code {
+					t.Errorf("expected error %d, got %+v", tt.code, resp)
+				}
+				return
+			}
+			replay, ok := resp.Result.(*ReplayResult)
+			if resp.Error != nil || !ok || replay.Changed {
+				t.Errorf("expected an unchanged replay, got %+v", resp)
+			}
+		})
+	}
+
+	plain := NewJSONRPCHandler(NewServer(ServerConfig{Name: "plain"}))
+	resp, _ := plain.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"minimcp/audit/replay","params":{"id":"x"}}`))
+	if resp.Error == nil || resp.Error.Code != MethodNotFound {
+		t.Errorf("expected replay to be unavailable without an audit log, got %+v", resp)
+	}
+}
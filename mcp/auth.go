@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mhpenta/minimcp/tools"
+	"github.com/mhpenta/minimcp/tools/mcpctx"
+)
+
+// ErrUnauthenticated is returned by authenticators when a credential is missing or invalid
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// PermissionDenied is the JSON-RPC error code returned when the caller lacks a tool's required scopes
+const PermissionDenied = -32003
+
+// Principal identifies an authenticated caller. It is the same type tools read through
+// mcpctx.Principal.
+type Principal = mcpctx.Identity
+
+// Authenticator is a richer alternative to APIKeyValidator that identifies the caller.
+// When the validator passed to the HTTP transport also implements Authenticator, the
+// returned Principal is attached to the request context and available to tools via PrincipalFrom.
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*Principal, error)
+}
+
+// WithPrincipal returns a context carrying the principal
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return mcpctx.WithPrincipal(ctx, p)
+}
+
+// PrincipalFrom returns the authenticated principal for the request, or nil
+func PrincipalFrom(ctx context.Context) *Principal {
+	return mcpctx.Principal(ctx)
+}
+
+type scopesEnforcedKey struct{}
+
+// withScopesEnforced marks ctx as arriving over a transport that identifies callers, such
+// as HTTP, so that scoped tools are refused when no principal was established
+func withScopesEnforced(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopesEnforcedKey{}, true)
+}
+
+// canUse reports whether the caller in ctx may list and call the tool. Transports that carry
+// no identity at all, such as stdio, see every tool. Over HTTP a scoped tool requires a
+// principal holding its scopes, so validators that only accept or reject a key, like
+// static API keys, cannot reach it.
+func canUse(ctx context.Context, spec *tools.ToolSpec) bool {
+	if len(spec.RequiredScopes) == 0 {
+		return true
+	}
+	principal := PrincipalFrom(ctx)
+	if principal == nil {
+		enforced, _ := ctx.Value(scopesEnforcedKey{}).(bool)
+		return !enforced
+	}
+	return principal.HasScopes(spec.RequiredScopes...)
+}
@@ -0,0 +1,120 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func TestScopes_ListAndCall(t *testing.T) {
+	public := tools.NewTool("public", "Anyone may call", func(ctx context.Context, in struct{}) (string, error) {
+		return "ok", nil
+	})
+	admin := tools.NewTool("admin", "Requires admin scope", func(ctx context.Context, in struct{}) (string, error) {
+		return "ok", nil
+	}, tools.WithRequiredScopes("admin", "tools:call"))
+
+	server := NewServer(ServerConfig{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Tools:   []tools.Tool{public, admin},
+	})
+	handler := NewJSONRPCHandler(server)
+
+	listNames := func(ctx context.Context) []string {
+		resp, err := handler.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		if err != nil {
+			t.Fatalf("HandleMessage failed: %v", err)
+		}
+		data, _ := json.Marshal(resp.Result)
+		var result ToolsListResult
+		json.Unmarshal(data, &result)
+		names := make([]string, len(result.Tools))
+		for i, tool := range result.Tools {
+			names[i] = tool.Name
+		}
+		return names
+	}
+	callAdmin := func(ctx context.Context) *RPCError {
+		resp, err := handler.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"admin","arguments":{}}}`))
+		if err != nil {
+			t.Fatalf("HandleMessage failed: %v", err)
+		}
+		return resp.Error
+	}
+
+	reader := WithPrincipal(context.Background(), &Principal{Subject: "reader", Scopes: []string{"tools:call"}})
+	if names := listNames(reader); len(names) != 1 || names[0] != "public" {
+		t.Errorf("expected only the public tool, got %v", names)
+	}
+	if rpcErr := callAdmin(reader); rpcErr == nil || rpcErr.Code != PermissionDenied {
+		t.Errorf("expected PermissionDenied, got %+v", rpcErr)
+	}
+
+	adminCtx := WithPrincipal(context.Background(), &Principal{Subject: "root", Scopes: []string{"tools:call", "admin"}})
+	if names := listNames(adminCtx); len(names) != 2 {
+		t.Errorf("expected both tools, got %v", names)
+	}
+	if rpcErr := callAdmin(adminCtx); rpcErr != nil {
+		t.Errorf("expected admin call to succeed, got %+v", rpcErr)
+	}
+
+	// Without a principal (e.g. stdio) scopes are not enforced
+	if names := listNames(context.Background()); len(names) != 2 {
+		t.Errorf("expected both tools without a principal, got %v", names)
+	}
+}
+
+func TestScopes_StaticKeyValidatorHidesScopedTools(t *testing.T) {
+	public := tools.NewTool("public", "Anyone may call", func(ctx context.Context, in struct{}) (string, error) {
+		return "ok", nil
+	})
+	admin := tools.NewTool("admin", "Requires admin scope", func(ctx context.Context, in struct{}) (string, error) {
+		return "ok", nil
+	}, tools.WithRequiredScopes("admin"))
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(ServerConfig{Name: "test-server", Tools: []tools.Tool{public, admin}, Logger: logger})
+	transport := NewHTTPTransport(server, logger, NewConstantTimeStaticValidator("secret"))
+
+	post := func(body string) *JSONRPCResponse {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		transport.ServeHTTP(w, req)
+		var resp JSONRPCResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("decoding response (status %d): %v", w.Code, err)
+		}
+		return &resp
+	}
+
+	resp := post(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	data, _ := json.Marshal(resp.Result)
+	var result ToolsListResult
+	json.Unmarshal(data, &result)
+	if len(result.Tools) != 1 || result.Tools[0].Name != "public" {
+		t.Errorf("expected only the public tool behind a static key, got %+v", result.Tools)
+	}
+
+	resp = post(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"admin","arguments":{}}}`)
+	if resp.Error == nil || resp.Error.Code != PermissionDenied {
+		t.Errorf("expected PermissionDenied calling a scoped tool without a principal, got %+v", resp)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp/tools/call", bytes.NewBufferString(`{"name":"admin","arguments":{}}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	transport.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 from the REST endpoint, got %d", w.Code)
+	}
+}
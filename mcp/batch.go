@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Reply is what a transport sends back for one payload: a single response, an array of
+// responses to a batch, or nothing when only notifications arrived
+type Reply struct {
+	Responses []*JSONRPCResponse
+
+	// Batch is set when the payload was a batch, so the responses are sent as an array
+	Batch bool
+}
+
+// Empty reports whether nothing should be sent back
+func (r Reply) Empty() bool {
+	return len(r.Responses) == 0
+}
+
+// MarshalJSON encodes a batch reply as an array and any other reply as its response
+func (r Reply) MarshalJSON() ([]byte, error) {
+	if r.Batch {
+		return json.Marshal(r.Responses)
+	}
+	if len(r.Responses) == 0 {
+		return []byte("null"), nil
+	}
+	return json.Marshal(r.Responses[0])
+}
+
+// HandlePayload processes one message or one JSON-RPC batch, as read from a stdio line or
+// an HTTP body.
+//
+// Batches follow JSON-RPC 2.0: requests are processed in order and answered in one array,
+// notifications get no entry, and a batch of only notifications gets no reply at all. An
+// empty batch, an oversized one, or one that is not valid JSON gets a single error
+// response rather than an array. Entries that are not objects are each answered with
+// InvalidRequest.
+func (h *JSONRPCHandler) HandlePayload(ctx context.Context, data []byte) Reply {
+	if !isBatchPayload(data) {
+		if resp := h.handleEntry(ctx, data); resp != nil {
+			return Reply{Responses: []*JSONRPCResponse{resp}}
+		}
+		return Reply{}
+	}
+
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return Reply{Responses: []*JSONRPCResponse{
+			{JSONRPC: "2.0", Error: &RPCError{Code: ParseError, Message: "Parse error", Data: err.Error()}},
+		}}
+	}
+	if len(entries) == 0 {
+		return Reply{Responses: []*JSONRPCResponse{
+			{JSONRPC: "2.0", Error: &RPCError{Code: InvalidRequest, Message: "Empty batch"}},
+		}}
+	}
+	if h.maxBatchSize > 0 && len(entries) > h.maxBatchSize {
+		h.server.logger.Warn("rejecting oversized batch", "size", len(entries), "max", h.maxBatchSize)
+		return Reply{Responses: []*JSONRPCResponse{
+			{JSONRPC: "2.0", Error: &RPCError{
+				Code:    InvalidRequest,
+				Message: fmt.Sprintf("Batch of %d requests exceeds the limit of %d", len(entries), h.maxBatchSize),
+			}},
+		}}
+	}
+
+	reply := Reply{Batch: true}
+	for _, entry := range entries {
+		if resp := h.handleEntry(ctx, entry); resp != nil {
+			reply.Responses = append(reply.Responses, resp)
+		}
+	}
+	return reply
+}
+
+// handleEntry processes a single message, turning a handler failure into an error response
+func (h *JSONRPCHandler) handleEntry(ctx context.Context, data []byte) *JSONRPCResponse {
+	resp, err := h.HandleMessage(ctx, data)
+	if err != nil {
+		h.server.logger.Error("error handling JSON-RPC message", "error", err)
+		return &JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error: &RPCError{
+				Code:    InternalError,
+				Message: "Internal server error",
+				Data:    err.Error(),
+			},
+		}
+	}
+	return resp
+}
+
+// isBatchPayload reports whether data holds a JSON array
+func isBatchPayload(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
@@ -0,0 +1,135 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// summarizeReply reduces a reply to its IDs and outcomes, such as "[1:ok 2:-32601]" for a
+// batch or "null:-32600" for a single response
+func summarizeReply(t *testing.T, data []byte) string {
+	t.Helper()
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return ""
+	}
+
+	type response struct {
+		ID     interface{}     `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *RPCError       `json:"error"`
+	}
+	summarize := func(r response) string {
+		id := "null"
+		if r.ID != nil {
+			id = fmt.Sprint(r.ID)
+		}
+		if r.Error != nil {
+			return fmt.Sprintf("%s:%d", id, r.Error.Code)
+		}
+		return id + ":ok"
+	}
+
+	if data[0] == '[' {
+		var responses []response
+		if err := json.Unmarshal(data, &responses); err != nil {
+			t.Fatalf("invalid batch reply %s: %v", data, err)
+		}
+		parts := make([]string, len(responses))
+		for i, r := range responses {
+			parts[i] = summarize(r)
+		}
+		return "[" + strings.Join(parts, " ") + "]"
+	}
+	var r response
+	if err := json.Unmarshal(data, &r); err != nil {
+		t.Fatalf("invalid reply %s: %v", data, err)
+	}
+	return summarize(r)
+}
+
+func TestBatchSemantics(t *testing.T) {
+	ping := func(id int) string { return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"ping"}`, id) }
+	notification := `{"jsonrpc":"2.0","method":"notifications/initialized"}`
+
+	cases := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{"single request", ping(1), "1:ok"},
+		{"single notification", notification, ""},
+		{"mixed batch", "[" + ping(1) + "," + notification + `,{"jsonrpc":"2.0","id":2,"method":"nope"}]`, "[1:ok 2:-32601]"},
+		{"only notifications", "[" + notification + "," + notification + "]", ""},
+		{"empty batch", "[]", "null:-32600"},
+		{"invalid entry", "[1]", "[null:-32600]"},
+		{"invalid entries", "[1,2,3]", "[null:-32600 null:-32600 null:-32600]"},
+		{"invalid entry among requests", "[" + ping(1) + `,"x",` + ping(2) + "]", "[1:ok null:-32600 2:ok]"},
+		{"invalid JSON", "[" + ping(1) + `,{"jsonrpc":"2.0","method"`, "null:-32700"},
+		{"wrong version in batch", `[{"jsonrpc":"1.0","id":1,"method":"ping"}]`, "[1:-32600]"},
+		{"oversized batch", "[" + ping(1) + "," + ping(2) + "," + ping(3) + "," + ping(4) + "]", "null:-32600"},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	newServer := func() *Server {
+		return NewServer(ServerConfig{Name: "test-server", Version: "1.0.0", Logger: logger})
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Stdio
+			var output bytes.Buffer
+			transport := NewStdioTransportWithIO(newServer(), nil, strings.NewReader(tc.payload+"\n"), &output).
+				WithMaxBatchSize(3)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := transport.Start(ctx); err != nil {
+				t.Fatalf("stdio transport failed: %v", err)
+			}
+			if got := summarizeReply(t, output.Bytes()); got != tc.want {
+				t.Errorf("stdio: expected %q, got %q from %s", tc.want, got, output.String())
+			}
+
+			// HTTP
+			httpTransport := NewHTTPTransport(newServer(), logger, newMockValidator("test-key")).WithMaxBatchSize(3)
+			req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(tc.payload))
+			req.Header.Set("Authorization", "Bearer test-key")
+			w := httptest.NewRecorder()
+			httpTransport.ServeHTTP(w, req)
+			if got := summarizeReply(t, w.Body.Bytes()); got != tc.want {
+				t.Errorf("http: expected %q, got %q from %s", tc.want, got, w.Body.String())
+			}
+			if tc.want == "" && w.Code != http.StatusAccepted {
+				t.Errorf("http: expected 202 when nothing is owed, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestBatchOverInMemoryTransport(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server", Version: "1.0.0"})
+	transport, client := NewInMemoryTransport(server, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go transport.Start(ctx)
+
+	if err := client.Send(ctx, []byte(`[{"jsonrpc":"2.0","id":1,"method":"ping"},{"jsonrpc":"2.0","id":2,"method":"tools/list"}]`)); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := client.Receive(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := summarizeReply(t, reply); got != "[1:ok 2:ok]" {
+		t.Errorf("expected both responses in one array, got %s", reply)
+	}
+}
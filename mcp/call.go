@@ -0,0 +1,133 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+	"github.com/mhpenta/minimcp/tools/mcpctx"
+)
+
+// CallTool calls the named tool in-process, as the caller in ctx, without going through a
+// transport. arguments is the tool's JSON input and may be nil. It runs the same path as
+// tools/call, including scope checks, timeouts, and auditing; with no principal in ctx,
+// every tool may be called.
+//
+// A tool that fails is reported in the result's IsError, not as an error. Errors are
+// protocol failures, returned as *RPCError: an unknown tool, missing scopes, or a tool
+// error with a code in the reserved JSON-RPC range, such as InvalidParams. A call made
+// while serving another draws one sub-call from its request budget, failing with
+// tools.ErrBudgetExceeded once the budget is spent.
+//
+// Example:
+//
+//	result, err := server.CallTool(ctx, "get_weather", json.RawMessage(`{"city":"Paris"}`))
+func (s *Server) CallTool(ctx context.Context, name string, arguments json.RawMessage) (*ToolsCallResult, error) {
+	if err := tools.SpendCall(ctx); err != nil {
+		return nil, err
+	}
+	result, rpcErr := s.handleToolsCall(ctx, ToolsCallParams{Name: name, Arguments: arguments})
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return result, nil
+}
+
+// ListTools lists the tools the caller in ctx may use, as tools/list does. The server lists
+// every tool at once, so cursor must be empty; any other cursor is an InvalidParams error.
+func (s *Server) ListTools(ctx context.Context, cursor string) (*ToolsListResult, error) {
+	if cursor != "" {
+		return nil, &RPCError{Code: InvalidParams, Message: fmt.Sprintf("Invalid cursor: %s", cursor)}
+	}
+	result := s.listTools(ctx)
+	return &result, nil
+}
+
+// handleToolsCall runs a tools/call for the caller in ctx. Every transport, the REST
+// endpoint, and CallTool use it, so tool resolution, scope checks, timeouts, auditing, and
+// error mapping are the same however a tool is called.
+//
+// Unknown tools, missing scopes, and tool failures with a code in the reserved JSON-RPC
+// range, such as InvalidParams, are returned as an *RPCError. Other tool failures are
+// reported in the result with IsError set.
+func (s *Server) handleToolsCall(ctx context.Context, params ToolsCallParams) (*ToolsCallResult, *RPCError) {
+	if params.Meta != nil && params.Meta.ProgressToken != nil {
+		ctx = mcpctx.WithProgressToken(ctx, params.Meta.ProgressToken)
+	}
+
+	start := time.Now()
+	result, rpcErr := s.callTool(ctx, params)
+	if id := s.recordAudit(ctx, params, result, rpcErr, start); id != "" && result != nil {
+		audited := *result
+		audited.Meta = withMetaValue(result.Meta, AuditRecordMetaKey, id)
+		result = &audited
+	}
+	return result, rpcErr
+}
+
+// callTool resolves and executes the tool named in params, mapping tool errors to either
+// a protocol-level RPCError or an IsError tool result. Unlike handleToolsCall, it is not audited.
+func (s *Server) callTool(ctx context.Context, params ToolsCallParams) (*ToolsCallResult, *RPCError) {
+	s.logger.Info("executing tool", "tool", params.Name)
+	nameToolSpan(ctx, params.Name)
+	ctx = mcpctx.WithLogger(ctx, mcpctx.Logger(ctx).With("tool", params.Name))
+
+	tool, rpcErr := s.lookupTool(ctx, params.Name)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	s.coverage.Record(tool.Spec(), params.Arguments)
+
+	start := time.Now()
+	var timeout time.Duration
+	if requested := params.requestedTimeout(); requested > 0 {
+		timeout = s.clampTimeout(tool.Spec(), requested)
+		ctx = withCallTimeout(ctx, timeout)
+	}
+
+	result, err := s.executeTool(ctx, tool, params.Arguments)
+
+	// Failures with a code in the reserved JSON-RPC range (-32768 to -32000), such as
+	// InvalidParams, are protocol-level errors and are returned directly
+	if failure := toolFailure(result, err); failure.IsProtocolError() {
+		return nil, &RPCError{
+			Code:    failure.Code,
+			Message: failure.Message,
+			Data:    failure.Data,
+		}
+	}
+
+	if err != nil {
+		s.logger.Error("tool execution failed",
+			"tool", params.Name,
+			"error", err.Error(),
+			"errorType", fmt.Sprintf("%T", err),
+			"arguments", string(params.Arguments))
+	}
+	callResult := s.toolCallResult(result, err)
+	if timeout > 0 {
+		callResult = withDeadlineMeta(callResult, timeout, start)
+	}
+	return &callResult, nil
+}
+
+// lookupTool returns the named tool if the caller in ctx may use it
+func (s *Server) lookupTool(ctx context.Context, name string) (tools.Tool, *RPCError) {
+	tool := s.findTool(name)
+	if tool == nil {
+		return nil, &RPCError{
+			Code:    InvalidParams,
+			Message: fmt.Sprintf("Tool not found: %s", name),
+		}
+	}
+	if !canUse(ctx, tool.Spec()) {
+		return nil, &RPCError{
+			Code:    PermissionDenied,
+			Message: fmt.Sprintf("Permission denied: tool %s requires scopes %v", name, tool.Spec().RequiredScopes),
+		}
+	}
+	return tool, nil
+}
@@ -0,0 +1,166 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// countingAuditLog records how many calls were audited
+type countingAuditLog struct {
+	*MemoryAuditLog
+	appended int
+}
+
+func (l *countingAuditLog) Append(ctx context.Context, record AuditRecord) error {
+	l.appended++
+	return l.MemoryAuditLog.Append(ctx, record)
+}
+
+// readerAuthenticator accepts any key as a caller without extra scopes
+type readerAuthenticator struct{}
+
+func (readerAuthenticator) Validate(ctx context.Context, key string) bool { return true }
+
+func (readerAuthenticator) Authenticate(ctx context.Context, key string) (*Principal, error) {
+	return &Principal{Subject: "reader"}, nil
+}
+
+func TestCallTool_TransportsAgree(t *testing.T) {
+	type input struct {
+		City string `json:"city"`
+	}
+	weather := tools.NewTool("get_weather", "Current weather", func(ctx context.Context, in input) (string, error) {
+		if in.City == "" {
+			return "", tools.NewInvalidParamsError("city is required")
+		}
+		return "sunny in " + in.City, nil
+	})
+	admin := tools.NewTool("reset", "Resets state", func(ctx context.Context, in struct{}) (string, error) {
+		return "ok", nil
+	}, tools.WithRequiredScopes("admin"))
+
+	auditLog := &countingAuditLog{MemoryAuditLog: NewMemoryAuditLog(10)}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(ServerConfig{Name: "test-server", Tools: []tools.Tool{weather, admin}, AuditLog: auditLog, Logger: logger})
+	handler := NewJSONRPCHandler(server)
+	transport := NewHTTPTransport(server, logger, readerAuthenticator{})
+
+	rest := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp/tools/call", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer test-key")
+		w := httptest.NewRecorder()
+		transport.ServeHTTP(w, req)
+		return w
+	}
+	rpc := func(params string) *JSONRPCResponse {
+		ctx := WithPrincipal(context.Background(), &Principal{Subject: "reader"})
+		resp, err := handler.HandleMessage(ctx,
+			[]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":`+params+`}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	// Successful calls produce the same result
+	w := rest(`{"name":"get_weather","arguments":{"city":"Oslo"}}`)
+	var restResult ToolsCallResult
+	json.NewDecoder(w.Body).Decode(&restResult)
+	rpcResult, _ := rpc(`{"name":"get_weather","arguments":{"city":"Oslo"}}`).Result.(ToolsCallResult)
+	if w.Code != http.StatusOK || restResult.Content[0].Text != "sunny in Oslo" || rpcResult.Content[0].Text != restResult.Content[0].Text {
+		t.Errorf("expected matching results, got REST %d %+v and JSON-RPC %+v", w.Code, restResult, rpcResult)
+	}
+
+	// Protocol-level failures are errors on both
+	tests := []struct {
+		name, params string
+		code, status int
+	}{
+		{"unknown tool", `{"name":"missing","arguments":{}}`, InvalidParams, http.StatusNotFound},
+		{"invalid params", `{"name":"get_weather","arguments":{}}`, InvalidParams, http.StatusBadRequest},
+		{"missing scope", `{"name":"reset","arguments":{}}`, PermissionDenied, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resp := rpc(tt.params); resp.Error == nil || resp.Error.Code != tt.code {
+				t.Errorf("expected JSON-RPC error %d, got %+v", tt.code, resp)
+			}
+			if w := rest(tt.params); w.Code != tt.status {
+				t.Errorf("expected REST status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// Both paths are audited
+	if auditLog.appended != 2+2*len(tests) {
+		t.Errorf("expected every call to be audited, got %d records", auditLog.appended)
+	}
+}
+
+func TestServer_CallTool(t *testing.T) {
+	type input struct {
+		A int `json:"a"`
+		B int `json:"b"`
+	}
+	add := tools.NewTool("add", "Adds two numbers", func(ctx context.Context, in input) (int, error) {
+		return in.A + in.B, nil
+	})
+	fail := tools.NewTool("fail", "Always fails", func(ctx context.Context, in struct{}) (string, error) {
+		return "", errors.New("boom")
+	})
+	server := NewServer(ServerConfig{Name: "test-server", Tools: []tools.Tool{add, fail}})
+	ctx := context.Background()
+
+	result, err := server.CallTool(ctx, "add", json.RawMessage(`{"a":2,"b":3}`))
+	if err != nil || result.IsError || result.Content[0].Text != "5" {
+		t.Errorf("expected 5, got %+v, %v", result, err)
+	}
+
+	// Tool failures are results, protocol failures are errors
+	if result, err := server.CallTool(ctx, "fail", nil); err != nil || !result.IsError {
+		t.Errorf("expected an IsError result, got %+v, %v", result, err)
+	}
+	var rpcErr *RPCError
+	if _, err := server.CallTool(ctx, "missing", nil); !errors.As(err, &rpcErr) || rpcErr.Code != InvalidParams {
+		t.Errorf("expected an InvalidParams error, got %v", err)
+	}
+
+	// Nested calls draw from the request budget
+	budgeted := tools.WithBudget(ctx, tools.NewBudget(tools.BudgetLimits{MaxCalls: 1}))
+	if _, err := server.CallTool(budgeted, "add", json.RawMessage(`{"a":1,"b":1}`)); err != nil {
+		t.Errorf("expected the first call to fit the budget, got %v", err)
+	}
+	if _, err := server.CallTool(budgeted, "add", json.RawMessage(`{"a":1,"b":1}`)); !errors.Is(err, tools.ErrBudgetExceeded) {
+		t.Errorf("expected ErrBudgetExceeded, got %v", err)
+	}
+}
+
+func TestServer_ListTools(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server", Tools: []tools.Tool{
+		tools.NewTool("open", "Anyone", func(ctx context.Context, in struct{}) (string, error) { return "", nil }),
+		tools.NewTool("admin", "Admins only", func(ctx context.Context, in struct{}) (string, error) { return "", nil },
+			tools.WithRequiredScopes("admin")),
+	}})
+
+	result, err := server.ListTools(WithPrincipal(context.Background(), &Principal{Subject: "reader"}), "")
+	if err != nil || len(result.Tools) != 1 || result.Tools[0].Name != "open" {
+		t.Errorf("expected only the tools the caller may use, got %+v, %v", result, err)
+	}
+	if _, ok := result.Meta[ToolsRevisionMetaKey]; !ok {
+		t.Error("expected the registry revision in _meta")
+	}
+
+	var rpcErr *RPCError
+	if _, err := server.ListTools(context.Background(), "page-2"); !errors.As(err, &rpcErr) || rpcErr.Code != InvalidParams {
+		t.Errorf("expected an invalid cursor error, got %v", err)
+	}
+}
@@ -0,0 +1,389 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+	"gopkg.in/yaml.v3"
+)
+
+// Transports a FileConfig can select
+const (
+	TransportStdio = "stdio"
+	TransportHTTP  = "http"
+	TransportSSE   = "sse" // the HTTP transport, which streams responses to clients accepting text/event-stream
+)
+
+// Auth modes a FileConfig can select for the HTTP transport
+const (
+	AuthModeAPIKeys = "api-keys" // keys listed in an environment variable
+	AuthModeDev     = "dev"      // NewDEVKeyValidator; for local development only
+)
+
+// FileConfig describes a server in a YAML or JSON file, loaded with LoadConfig:
+//
+//	name: weather
+//	version: 1.2.0
+//	transport: http
+//	port: "8080"
+//	log_level: debug
+//	auth:
+//	  mode: api-keys
+//	  keys_env: WEATHER_API_KEYS
+//	tools:
+//	  enabled: [get_weather, get_forecast]
+//	  settings:
+//	    get_forecast:
+//	      timeout: 30s
+type FileConfig struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+
+	// Transport is "stdio" (the default), "http", or "sse"
+	Transport string `json:"transport,omitempty"`
+
+	// Port is the HTTP port. Defaults to 8080.
+	Port string `json:"port,omitempty"`
+
+	// LogLevel is "debug", "info" (the default), "warn", or "error"
+	LogLevel string `json:"log_level,omitempty"`
+
+	Auth  AuthFileConfig  `json:"auth,omitempty"`
+	Tools ToolsFileConfig `json:"tools,omitempty"`
+}
+
+// AuthFileConfig selects how the HTTP transport authenticates requests
+type AuthFileConfig struct {
+	// Mode is "api-keys" (the default) or "dev"
+	Mode string `json:"mode,omitempty"`
+
+	// KeysEnv names the environment variable holding comma-separated API keys.
+	// Defaults to MCP_API_KEYS.
+	KeysEnv string `json:"keys_env,omitempty"`
+
+	// KeysFile names a file holding one API key per line, used instead of KeysEnv.
+	// Servers run with RunFromConfigFile re-read it on reload, so keys can be rotated.
+	KeysFile string `json:"keys_file,omitempty"`
+
+	// Header is "bearer" (the default) or "api-key"
+	Header AuthHeaderType `json:"header,omitempty"`
+}
+
+// ToolsFileConfig selects and adjusts the tools a configured server exposes
+type ToolsFileConfig struct {
+	// Enabled lists the tools to expose by name. Empty exposes every available tool.
+	Enabled []string `json:"enabled,omitempty"`
+
+	// Settings overrides parts of tools' specs, keyed by tool name
+	Settings map[string]ToolFileSettings `json:"settings,omitempty"`
+}
+
+// ToolFileSettings overrides parts of one tool's spec. Unset fields keep the tool's own values.
+type ToolFileSettings struct {
+	Description string   `json:"description,omitempty"`
+	Timeout     Duration `json:"timeout,omitempty"`
+	Sequential  *bool    `json:"sequential,omitempty"`
+}
+
+// Duration is a time.Duration read from a string such as "30s" or "5m"
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON formats the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// LoadConfig reads a server configuration from a .yaml, .yml, or .json file. Unknown
+// fields are rejected, so misspelled settings are reported rather than ignored.
+func LoadConfig(path string) (*FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		// YAML is converted to JSON so both formats share one set of field names and decoders
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	case ".json":
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q: use .yaml, .yml, or .json", ext)
+	}
+
+	var cfg FileConfig
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// validate checks the configuration's enumerated values
+func (c *FileConfig) validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch c.Transport {
+	case "", TransportStdio, TransportHTTP, TransportSSE:
+	default:
+		return fmt.Errorf("unknown transport %q: use stdio, http, or sse", c.Transport)
+	}
+	switch c.Auth.Mode {
+	case "", AuthModeAPIKeys, AuthModeDev:
+	default:
+		return fmt.Errorf("unknown auth mode %q: use api-keys or dev", c.Auth.Mode)
+	}
+	switch c.Auth.Header {
+	case "", AuthHeaderBearer, AuthHeaderAPIKey:
+	default:
+		return fmt.Errorf("unknown auth header %q: use bearer or api-key", c.Auth.Header)
+	}
+	if _, err := c.logLevel(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// logLevel parses LogLevel, defaulting to info
+func (c *FileConfig) logLevel() (slog.Level, error) {
+	var level slog.Level
+	if c.LogLevel == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q: use debug, info, warn, or error", c.LogLevel)
+	}
+	return level, nil
+}
+
+// ServerConfig builds the ServerConfig the file describes, exposing the enabled tools of
+// registry with their settings applied. Set further fields on the result before calling
+// NewServer to use options the file cannot express.
+func (c *FileConfig) ServerConfig(registry []tools.Tool) (ServerConfig, error) {
+	return c.serverConfig(registry, new(slog.LevelVar))
+}
+
+// serverConfig builds the ServerConfig with a logger whose level is read from level,
+// so reloads can change it
+func (c *FileConfig) serverConfig(registry []tools.Tool, level *slog.LevelVar) (ServerConfig, error) {
+	parsed, err := c.logLevel()
+	if err != nil {
+		return ServerConfig{}, err
+	}
+	level.Set(parsed)
+	selected, err := c.Tools.apply(registry)
+	if err != nil {
+		return ServerConfig{}, err
+	}
+	return ServerConfig{
+		Name:    c.Name,
+		Version: c.Version,
+		Tools:   selected,
+		// Stdout carries the stdio protocol, so logs always go to stderr
+		Logger: slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})),
+	}, nil
+}
+
+// apply selects the enabled tools from registry, in the order listed, and applies settings
+func (c ToolsFileConfig) apply(registry []tools.Tool) ([]tools.Tool, error) {
+	byName := make(map[string]tools.Tool, len(registry))
+	for _, tool := range registry {
+		byName[tool.Spec().Name] = tool
+	}
+
+	selected := registry
+	if len(c.Enabled) > 0 {
+		selected = make([]tools.Tool, 0, len(c.Enabled))
+		for _, name := range c.Enabled {
+			tool, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("enabled tool %q is not available", name)
+			}
+			selected = append(selected, tool)
+		}
+	}
+
+	enabled := make(map[string]int, len(selected))
+	for i, tool := range selected {
+		enabled[tool.Spec().Name] = i
+	}
+	configured := append([]tools.Tool(nil), selected...)
+	for name, settings := range c.Settings {
+		i, ok := enabled[name]
+		if !ok {
+			return nil, fmt.Errorf("settings given for %q, which is not an enabled tool", name)
+		}
+		configured[i] = settings.apply(configured[i])
+	}
+	return configured, nil
+}
+
+// apply returns tool with its spec overridden by the settings
+func (s ToolFileSettings) apply(tool tools.Tool) tools.Tool {
+	spec := *tool.Spec()
+	if s.Description != "" {
+		spec.Description = s.Description
+	}
+	if s.Timeout > 0 {
+		spec.Timeout = time.Duration(s.Timeout)
+	}
+	if s.Sequential != nil {
+		spec.Sequential = *s.Sequential
+	}
+
+	configured := &configuredTool{Tool: tool, spec: &spec}
+	if streaming, ok := tool.(tools.StreamingTool); ok {
+		return &configuredStreamingTool{configuredTool: configured, streaming: streaming}
+	}
+	return configured
+}
+
+// configuredTool is a tool whose spec was adjusted by a config file
+type configuredTool struct {
+	tools.Tool
+	spec *tools.ToolSpec
+}
+
+func (t *configuredTool) Spec() *tools.ToolSpec {
+	return t.spec
+}
+
+// configuredStreamingTool keeps streaming tools streaming when configured
+type configuredStreamingTool struct {
+	*configuredTool
+	streaming tools.StreamingTool
+}
+
+func (t *configuredStreamingTool) ExecuteStream(ctx context.Context, params json.RawMessage, emit tools.EmitFunc) (*tools.ToolResult, error) {
+	return t.streaming.ExecuteStream(ctx, params, emit)
+}
+
+// RunFromConfig runs the server cfg describes until ctx is cancelled, exposing tools from
+// registry. It makes a configurable server binary a few lines long:
+//
+//	cfg, err := mcp.LoadConfig(*configPath)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if err := mcp.RunFromConfig(ctx, cfg, allTools); err != nil {
+//	    log.Fatal(err)
+//	}
+func RunFromConfig(ctx context.Context, cfg *FileConfig, registry []tools.Tool) error {
+	return runFromConfig(ctx, cfg, registry, "")
+}
+
+// RunFromConfigFile loads the configuration at path and runs it like RunFromConfig, then
+// applies changes to the file while the server runs. The file is checked for changes every
+// DefaultConfigPollInterval, and re-read at once on SIGHUP. Enabled tools, tool settings,
+// API keys, and the log level change without restarting the transport; clients are notified
+// when the tool list changes. Changing the name, version, transport, port, or auth header
+// requires a restart. An invalid file is logged and leaves the running configuration in place.
+func RunFromConfigFile(ctx context.Context, path string, registry []tools.Tool) error {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	return runFromConfig(ctx, cfg, registry, path)
+}
+
+// runFromConfig runs cfg, watching path for changes unless it is empty
+func runFromConfig(ctx context.Context, cfg *FileConfig, registry []tools.Tool, path string) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	level := new(slog.LevelVar)
+	serverConfig, err := cfg.serverConfig(registry, level)
+	if err != nil {
+		return err
+	}
+	server := NewServer(serverConfig)
+	logger := serverConfig.Logger
+
+	var validator *ReloadableValidator
+	if cfg.Transport == TransportHTTP || cfg.Transport == TransportSSE {
+		keys, err := cfg.Auth.validator(logger)
+		if err != nil {
+			return err
+		}
+		validator = NewReloadableValidator(keys)
+	}
+
+	if path != "" {
+		reloader := newConfigReloader(path, cfg, server, registry, level, validator, logger)
+		go reloader.watch(ctx, DefaultConfigPollInterval)
+	}
+
+	if validator == nil {
+		return NewStdioTransport(server, logger).Start(ctx)
+	}
+
+	transport := NewHTTPTransport(server, logger, validator)
+	if cfg.Auth.Header != "" {
+		transport.WithAuthHeaderType(cfg.Auth.Header)
+	}
+	port := cfg.Port
+	if port == "" {
+		port = "8080"
+	}
+	return transport.Start(ctx, port)
+}
+
+// validator creates the API key validator for the configured auth mode
+func (a AuthFileConfig) validator(logger *slog.Logger) (APIKeyValidator, error) {
+	if a.Mode == AuthModeDev {
+		logger.Warn("using the development API key validator; do not use it in production")
+		return NewDEVKeyValidator(), nil
+	}
+	if a.KeysFile != "" {
+		return newStaticValidatorFromFile(a.KeysFile)
+	}
+	keysEnv := a.KeysEnv
+	if keysEnv == "" {
+		keysEnv = "MCP_API_KEYS"
+	}
+	return NewStaticValidatorFromEnv(keysEnv)
+}
+
+// newStaticValidatorFromFile creates a validator from a file of API keys, one per line
+func newStaticValidatorFromFile(path string) (*ConstantTimeStaticValidator, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading API keys: %w", err)
+	}
+	v := NewConstantTimeStaticValidator(strings.Split(string(data), "\n")...)
+	if len(v.digests) == 0 {
+		return nil, fmt.Errorf("API key file %s contains no keys", path)
+	}
+	return v, nil
+}
@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func configTestTools() []tools.Tool {
+	weather := tools.NewTool("get_weather", "Current weather", func(ctx context.Context, in struct{}) (string, error) {
+		return "sunny", nil
+	})
+	forecast := tools.NewTool("get_forecast", "Forecast", func(ctx context.Context, in struct{}) (string, error) {
+		return "rain", nil
+	}, tools.WithTimeout(time.Second))
+	admin := tools.NewTool("reset_cache", "Resets the cache", func(ctx context.Context, in struct{}) (string, error) {
+		return "ok", nil
+	})
+	return []tools.Tool{weather, forecast, admin}
+}
+
+func TestLoadConfig_YAML(t *testing.T) {
+	path := writeConfig(t, "server.yaml", `
+name: weather
+version: 1.2.0
+transport: http
+port: "9090"
+log_level: debug
+auth:
+  mode: api-keys
+  keys_env: WEATHER_API_KEYS
+  header: api-key
+tools:
+  enabled: [get_forecast, get_weather]
+  settings:
+    get_forecast:
+      description: Forecast for the next five days
+      timeout: 30s
+      sequential: true
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Name != "weather" || cfg.Version != "1.2.0" || cfg.Transport != TransportHTTP || cfg.Port != "9090" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	if cfg.Auth.KeysEnv != "WEATHER_API_KEYS" || cfg.Auth.Header != AuthHeaderAPIKey {
+		t.Errorf("unexpected auth %+v", cfg.Auth)
+	}
+
+	serverConfig, err := cfg.ServerConfig(configTestTools())
+	if err != nil {
+		t.Fatalf("ServerConfig: %v", err)
+	}
+	if serverConfig.Name != "weather" || serverConfig.Version != "1.2.0" {
+		t.Errorf("unexpected server config %+v", serverConfig)
+	}
+	if !serverConfig.Logger.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("expected debug logging")
+	}
+	if len(serverConfig.Tools) != 2 {
+		t.Fatalf("expected the two enabled tools, got %d", len(serverConfig.Tools))
+	}
+
+	forecast := serverConfig.Tools[0].Spec()
+	if forecast.Name != "get_forecast" || forecast.Description != "Forecast for the next five days" ||
+		forecast.Timeout != 30*time.Second || !forecast.Sequential {
+		t.Errorf("expected settings applied, got %+v", forecast)
+	}
+	if forecast.Parameters == nil {
+		t.Error("expected the tool's schema to be kept")
+	}
+	if weather := serverConfig.Tools[1].Spec(); weather.Name != "get_weather" || weather.Description != "Current weather" {
+		t.Errorf("expected an unconfigured tool unchanged, got %+v", weather)
+	}
+
+	// The configured tool still runs
+	result, err := serverConfig.Tools[0].Execute(context.Background(), nil)
+	if err != nil || result.Output != "rain" {
+		t.Errorf("expected the configured tool to execute, got %v, %v", result, err)
+	}
+}
+
+func TestLoadConfig_JSON(t *testing.T) {
+	path := writeConfig(t, "server.json", `{"name": "weather", "tools": {"settings": {"get_weather": {"timeout": "5s"}}}}`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	serverConfig, err := cfg.ServerConfig(configTestTools())
+	if err != nil {
+		t.Fatalf("ServerConfig: %v", err)
+	}
+	if len(serverConfig.Tools) != 3 {
+		t.Errorf("expected every tool when none are listed as enabled, got %d", len(serverConfig.Tools))
+	}
+	if timeout := serverConfig.Tools[0].Spec().Timeout; timeout != 5*time.Second {
+		t.Errorf("expected a 5s timeout, got %s", timeout)
+	}
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name, file, content, want string
+	}{
+		{"unknown field", "c.yaml", "name: x\nlog_levl: debug\n", `unknown field "log_levl"`},
+		{"missing name", "c.json", `{"transport": "stdio"}`, "name is required"},
+		{"bad transport", "c.yaml", "name: x\ntransport: grpc\n", `unknown transport "grpc"`},
+		{"bad auth mode", "c.yaml", "name: x\nauth:\n  mode: oauth\n", `unknown auth mode "oauth"`},
+		{"bad log level", "c.yaml", "name: x\nlog_level: loud\n", `unknown log level "loud"`},
+		{"bad duration", "c.yaml", "name: x\ntools:\n  settings:\n    t:\n      timeout: soon\n", "invalid duration"},
+		{"scopes setting", "c.yaml", "name: x\ntools:\n  settings:\n    t:\n      required_scopes: [admin]\n", `unknown field "required_scopes"`},
+		{"bad extension", "c.toml", "name = 'x'", "unsupported config file extension"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadConfig(writeConfig(t, tt.file, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestFileConfig_ToolErrors(t *testing.T) {
+	cfg := &FileConfig{Name: "x", Tools: ToolsFileConfig{Enabled: []string{"get_weather", "missing"}}}
+	if _, err := cfg.ServerConfig(configTestTools()); err == nil || !strings.Contains(err.Error(), `"missing" is not available`) {
+		t.Errorf("expected an unknown enabled tool to fail, got %v", err)
+	}
+
+	cfg = &FileConfig{Name: "x", Tools: ToolsFileConfig{
+		Enabled:  []string{"get_weather"},
+		Settings: map[string]ToolFileSettings{"reset_cache": {Description: "x"}},
+	}}
+	if _, err := cfg.ServerConfig(configTestTools()); err == nil || !strings.Contains(err.Error(), "not an enabled tool") {
+		t.Errorf("expected settings for a disabled tool to fail, got %v", err)
+	}
+}
+
+func TestRunFromConfig_MissingAPIKeys(t *testing.T) {
+	t.Setenv("CONFIG_TEST_KEYS", "")
+	cfg := &FileConfig{Name: "x", Transport: TransportHTTP, LogLevel: "error", Auth: AuthFileConfig{KeysEnv: "CONFIG_TEST_KEYS"}}
+	err := RunFromConfig(context.Background(), cfg, configTestTools())
+	if err == nil || !strings.Contains(err.Error(), "CONFIG_TEST_KEYS") {
+		t.Errorf("expected an error naming the empty key variable, got %v", err)
+	}
+}
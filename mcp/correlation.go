@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"unicode"
+
+	"github.com/mhpenta/minimcp/tools/mcpctx"
+)
+
+const (
+	// RequestIDHeader carries a request's correlation ID over HTTP, when ServerConfig.RequestIDs is set
+	RequestIDHeader = "X-Request-ID"
+
+	// MetaRequestID is the _meta key carrying the correlation ID in requests and results
+	MetaRequestID = "requestId"
+
+	// maxRequestIDLength bounds the correlation IDs accepted from clients
+	maxRequestIDLength = 128
+)
+
+type headerRequestIDKey struct{}
+
+// withHeaderRequestID records the correlation ID an HTTP request carried
+func withHeaderRequestID(ctx context.Context, r *http.Request) context.Context {
+	if id := r.Header.Get(RequestIDHeader); validRequestID(id) {
+		return context.WithValue(ctx, headerRequestIDKey{}, id)
+	}
+	return ctx
+}
+
+// withCorrelation assigns the request's correlation ID: the one in params._meta, else the
+// HTTP request's, else a new one
+func (s *Server) withCorrelation(ctx context.Context, params json.RawMessage) context.Context {
+	if !s.requestIDs {
+		return ctx
+	}
+	var meta struct {
+		Meta struct {
+			RequestID string `json:"requestId"`
+		} `json:"_meta"`
+	}
+	json.Unmarshal(params, &meta)
+
+	id := meta.Meta.RequestID
+	if !validRequestID(id) {
+		id, _ = ctx.Value(headerRequestIDKey{}).(string)
+	}
+	if id == "" {
+		id, _ = UUIDGenerator{}.NextID("").(string)
+	}
+	return mcpctx.WithRequestID(ctx, id)
+}
+
+// validRequestID accepts non-empty IDs of printable characters up to a bounded length, so
+// client-supplied IDs cannot forge log lines
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// withRequestIDMeta echoes the request's correlation ID in the _meta of results that carry it
+func withRequestIDMeta(ctx context.Context, result interface{}) interface{} {
+	id := mcpctx.RequestID(ctx)
+	if id == "" {
+		return result
+	}
+	switch r := result.(type) {
+	case ToolsCallResult:
+		r.Meta = withMetaValue(r.Meta, MetaRequestID, id)
+		return r
+	case ToolsListResult:
+		r.Meta = withMetaValue(r.Meta, MetaRequestID, id)
+		return r
+	}
+	return result
+}
+
+// withMetaValue returns a copy of meta with key set, leaving the original, which may be
+// shared, untouched
+func withMetaValue(meta map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(meta)+1)
+	for k, v := range meta {
+		copied[k] = v
+	}
+	copied[key] = value
+	return copied
+}
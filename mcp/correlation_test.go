@@ -0,0 +1,144 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+	"github.com/mhpenta/minimcp/tools/mcpctx"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent log writes
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newCorrelatedServer(t *testing.T, logs *syncBuffer, enabled bool) (*Server, chan string) {
+	t.Helper()
+	seen := make(chan string, 4)
+	probe := tools.NewTool("probe", "Logs and records its request ID", func(ctx context.Context, _ emptyInput) (string, error) {
+		mcpctx.Logger(ctx).Info("probing")
+		seen <- mcpctx.RequestID(ctx)
+		return "ok", nil
+	})
+	return NewServer(ServerConfig{
+		Name:       "test-server",
+		Tools:      []tools.Tool{probe},
+		Logger:     slog.New(slog.NewTextHandler(logs, nil)),
+		RequestIDs: enabled,
+	}), seen
+}
+
+func resultMeta(t *testing.T, resp *JSONRPCResponse) map[string]interface{} {
+	t.Helper()
+	data, _ := json.Marshal(resp.Result)
+	var result struct {
+		Meta map[string]interface{} `json:"_meta"`
+	}
+	json.Unmarshal(data, &result)
+	return result.Meta
+}
+
+func TestRequestIDs_PropagatedFromMeta(t *testing.T) {
+	var logs syncBuffer
+	server, seen := newCorrelatedServer(t, &logs, true)
+	handler := NewJSONRPCHandler(server)
+
+	resp, err := handler.HandleMessage(context.Background(), []byte(
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"probe","arguments":{},"_meta":{"requestId":"client-42"}}}`))
+	if err != nil || resp.Error != nil {
+		t.Fatalf("tools/call failed: %v %v", err, resp.Error)
+	}
+	if id := <-seen; id != "client-42" {
+		t.Errorf("expected the tool to see the client's ID, got %q", id)
+	}
+	if meta := resultMeta(t, resp); meta[MetaRequestID] != "client-42" {
+		t.Errorf("expected the ID echoed in _meta, got %v", meta)
+	}
+	if !strings.Contains(logs.String(), `msg=probing`) || !strings.Contains(logs.String(), "request_id=client-42") {
+		t.Errorf("expected the tool's log line to carry the request ID:\n%s", logs.String())
+	}
+}
+
+func TestRequestIDs_Generated(t *testing.T) {
+	var logs syncBuffer
+	server, seen := newCorrelatedServer(t, &logs, true)
+	handler := NewJSONRPCHandler(server)
+
+	ids := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		// Unprintable IDs are replaced
+		resp, _ := handler.HandleMessage(context.Background(), []byte(
+			`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"probe","_meta":{"requestId":"bad\nid"}}}`))
+		id := <-seen
+		if len(id) != 36 || resultMeta(t, resp)[MetaRequestID] != id {
+			t.Errorf("expected a generated UUID echoed in _meta, got %q and %v", id, resultMeta(t, resp))
+		}
+		ids[id] = true
+	}
+	if len(ids) != 2 {
+		t.Error("expected a new ID per request")
+	}
+
+	resp, _ := handler.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":3,"method":"tools/list"}`))
+	if resultMeta(t, resp)[MetaRequestID] == nil {
+		t.Error("expected tools/list results to carry the request ID")
+	}
+}
+
+func TestRequestIDs_Disabled(t *testing.T) {
+	var logs syncBuffer
+	server, seen := newCorrelatedServer(t, &logs, false)
+	resp, _ := NewJSONRPCHandler(server).HandleMessage(context.Background(), []byte(
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"probe","_meta":{"requestId":"client-42"}}}`))
+	if id := <-seen; id != "" {
+		t.Errorf("expected no request ID, got %q", id)
+	}
+	if resultMeta(t, resp)[MetaRequestID] != nil || strings.Contains(logs.String(), "request_id") {
+		t.Error("expected no request ID in results or logs")
+	}
+}
+
+func TestRequestIDs_HTTPHeader(t *testing.T) {
+	var logs syncBuffer
+	server, seen := newCorrelatedServer(t, &logs, true)
+	transport := NewHTTPTransport(server, slog.New(slog.NewTextHandler(&logs, nil)), NewConstantTimeStaticValidator("key"))
+
+	for _, tc := range []struct{ path, body string }{
+		{"/mcp", `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"probe","arguments":{}}}`},
+		{"/mcp/tools/call", `{"name":"probe","arguments":{}}`},
+	} {
+		req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Authorization", "Bearer key")
+		req.Header.Set(RequestIDHeader, "edge-7")
+		rec := httptest.NewRecorder()
+		transport.ServeHTTP(rec, req)
+
+		if id := <-seen; id != "edge-7" {
+			t.Errorf("%s: expected the header's ID, got %q", tc.path, id)
+		}
+		if rec.Header().Get(RequestIDHeader) != "edge-7" || !strings.Contains(rec.Body.String(), `"requestId":"edge-7"`) {
+			t.Errorf("%s: expected the ID echoed, got headers %v and body %s", tc.path, rec.Header(), rec.Body.String())
+		}
+	}
+}
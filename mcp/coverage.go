@@ -0,0 +1,191 @@
+package mcp
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// ArgumentCoverage records which top-level argument fields callers actually
+// populate for each tool. Enable it via ServerConfig.ArgumentCoverage and read
+// the results with Report to find parameters that are never used or always
+// left at their default, which are good candidates for schema simplification.
+type ArgumentCoverage struct {
+	mu    sync.Mutex
+	tools map[string]*toolCoverage
+}
+
+type toolCoverage struct {
+	calls  int
+	fields map[string]*fieldCoverage
+}
+
+type fieldCoverage struct {
+	populated int
+	defaulted int
+}
+
+// FieldCoverage reports usage statistics for a single schema property.
+type FieldCoverage struct {
+	Name string `json:"name"`
+
+	// Populated is the number of calls that supplied the field.
+	Populated int `json:"populated"`
+
+	// Defaulted is the number of calls that supplied the field with its
+	// schema default (or the JSON zero value when no default is declared).
+	Defaulted int `json:"defaulted"`
+}
+
+// ToolCoverageReport summarizes argument usage for one tool.
+type ToolCoverageReport struct {
+	Tool   string          `json:"tool"`
+	Calls  int             `json:"calls"`
+	Fields []FieldCoverage `json:"fields"`
+
+	// Unused lists schema properties that no call has ever populated.
+	Unused []string `json:"unused"`
+
+	// AlwaysDefaulted lists properties that were populated, but only ever
+	// with their default or zero value.
+	AlwaysDefaulted []string `json:"alwaysDefaulted"`
+}
+
+// NewArgumentCoverage creates an empty coverage recorder.
+func NewArgumentCoverage() *ArgumentCoverage {
+	return &ArgumentCoverage{
+		tools: make(map[string]*toolCoverage),
+	}
+}
+
+// Record registers a single call to the tool described by spec.
+// Arguments that are not a JSON object still count towards the call total.
+func (c *ArgumentCoverage) Record(spec *tools.ToolSpec, args json.RawMessage) {
+	if c == nil || spec == nil {
+		return
+	}
+
+	var fields map[string]interface{}
+	if len(args) > 0 {
+		_ = json.Unmarshal(args, &fields)
+	}
+	properties, _ := spec.Parameters["properties"].(map[string]interface{})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tc, ok := c.tools[spec.Name]
+	if !ok {
+		tc = &toolCoverage{fields: make(map[string]*fieldCoverage)}
+		c.tools[spec.Name] = tc
+	}
+	tc.calls++
+
+	for name, value := range fields {
+		fc, ok := tc.fields[name]
+		if !ok {
+			fc = &fieldCoverage{}
+			tc.fields[name] = fc
+		}
+		fc.populated++
+
+		propSchema, _ := properties[name].(map[string]interface{})
+		if isDefaultValue(propSchema, value) {
+			fc.defaulted++
+		}
+	}
+}
+
+// Report returns a coverage summary for each of the given tools, in the same
+// order. Tools that have never been called are included with zero calls and
+// every property listed as unused.
+func (c *ArgumentCoverage) Report(toolList []tools.Tool) []ToolCoverageReport {
+	reports := make([]ToolCoverageReport, 0, len(toolList))
+	if c == nil {
+		return reports
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tool := range toolList {
+		spec := tool.Spec()
+		report := ToolCoverageReport{
+			Tool:            spec.Name,
+			Fields:          []FieldCoverage{},
+			Unused:          []string{},
+			AlwaysDefaulted: []string{},
+		}
+
+		tc := c.tools[spec.Name]
+		if tc != nil {
+			report.Calls = tc.calls
+		}
+
+		properties, _ := spec.Parameters["properties"].(map[string]interface{})
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			field := FieldCoverage{Name: name}
+			if tc != nil {
+				if fc := tc.fields[name]; fc != nil {
+					field.Populated = fc.populated
+					field.Defaulted = fc.defaulted
+				}
+			}
+			report.Fields = append(report.Fields, field)
+
+			switch {
+			case field.Populated == 0:
+				report.Unused = append(report.Unused, name)
+			case field.Defaulted == field.Populated:
+				report.AlwaysDefaulted = append(report.AlwaysDefaulted, name)
+			}
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports
+}
+
+// Reset discards all recorded calls.
+func (c *ArgumentCoverage) Reset() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tools = make(map[string]*toolCoverage)
+}
+
+// isDefaultValue reports whether value equals the property's declared default,
+// or the JSON zero value for its kind when no default is declared.
+func isDefaultValue(propSchema map[string]interface{}, value interface{}) bool {
+	if def, ok := propSchema["default"]; ok {
+		return reflect.DeepEqual(def, value)
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case float64:
+		return v == 0
+	case bool:
+		return !v
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func TestArgumentCoverage_Report(t *testing.T) {
+	tool := &mockTool{
+		name:        "search",
+		description: "Searches things",
+		parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query":  map[string]interface{}{"type": "string"},
+				"limit":  map[string]interface{}{"type": "integer", "default": float64(10)},
+				"fuzzy":  map[string]interface{}{"type": "boolean"},
+				"region": map[string]interface{}{"type": "string"},
+			},
+		},
+		result: &tools.ToolResult{Output: "ok"},
+	}
+
+	coverage := NewArgumentCoverage()
+	server := NewServer(ServerConfig{
+		Name:             "test-server",
+		Version:          "1.0.0",
+		Tools:            []tools.Tool{tool},
+		ArgumentCoverage: coverage,
+	})
+	handler := NewJSONRPCHandler(server)
+
+	calls := []string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search","arguments":{"query":"a","limit":10}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"search","arguments":{"query":"b","fuzzy":false}}}`,
+	}
+	for _, call := range calls {
+		if _, err := handler.HandleMessage(context.Background(), []byte(call)); err != nil {
+			t.Fatalf("HandleMessage failed: %v", err)
+		}
+	}
+
+	reports := coverage.Report(server.GetTools())
+	if len(reports) != 1 {
+		t.Fatalf("expected 1 report, got %d", len(reports))
+	}
+
+	report := reports[0]
+	if report.Calls != 2 {
+		t.Errorf("expected 2 calls, got %d", report.Calls)
+	}
+	if !reflect.DeepEqual(report.Unused, []string{"region"}) {
+		t.Errorf("expected unused [region], got %v", report.Unused)
+	}
+	if !reflect.DeepEqual(report.AlwaysDefaulted, []string{"fuzzy", "limit"}) {
+		t.Errorf("expected always defaulted [fuzzy limit], got %v", report.AlwaysDefaulted)
+	}
+}
+
+func TestArgumentCoverage_NilRecorder(t *testing.T) {
+	var coverage *ArgumentCoverage
+	coverage.Record(&tools.ToolSpec{Name: "x"}, json.RawMessage(`{"a":1}`))
+
+	if reports := coverage.Report(nil); len(reports) != 0 {
+		t.Errorf("expected no reports from nil recorder, got %d", len(reports))
+	}
+}
@@ -0,0 +1,86 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// DeadlineMetaKey carries a call's timeout, elapsed time, and remaining time in the _meta
+// of tools/call results, when the client asked for a timeout
+const DeadlineMetaKey = "minimcp/deadline"
+
+// DeadlineInfo reports how a call used the timeout its client asked for
+type DeadlineInfo struct {
+	TimeoutMs   int64 `json:"timeoutMs"`   // the timeout applied, after clamping
+	ElapsedMs   int64 `json:"elapsedMs"`   // time from receiving the call to its result
+	RemainingMs int64 `json:"remainingMs"` // time left of the timeout, zero once exceeded
+}
+
+type callTimeoutKey struct{}
+
+// requestedTimeout returns the timeout the client asked for in _meta.timeoutMs or
+// params.timeoutMs, preferring _meta, or zero when it asked for none
+func (p ToolsCallParams) requestedTimeout() time.Duration {
+	ms := p.TimeoutMs
+	if p.Meta != nil && p.Meta.TimeoutMs > 0 {
+		ms = p.Meta.TimeoutMs
+	}
+	if ms <= 0 {
+		return 0
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// clampTimeout limits a client's timeout to ServerConfig.MaxToolTimeout or, when no
+// maximum is set, to the tool's own timeout, so clients can shorten but not extend it
+func (s *Server) clampTimeout(spec *tools.ToolSpec, requested time.Duration) time.Duration {
+	limit := s.maxToolTimeout
+	if limit <= 0 {
+		limit = s.toolTimeout(spec)
+	}
+	if limit > 0 && requested > limit {
+		return limit
+	}
+	return requested
+}
+
+// withCallTimeout returns a context carrying the timeout for the tool call it serves,
+// used in place of the tool's own timeout. executeWithTimeout consumes it, so it does not
+// reach calls nested inside the tool.
+func withCallTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, callTimeoutKey{}, timeout)
+}
+
+// withoutCallTimeout returns a context that no longer carries a client's timeout, so
+// calls a tool makes through Server.CallTool or a mount use their own timeouts
+func withoutCallTimeout(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(callTimeoutKey{}).(time.Duration); !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, callTimeoutKey{}, nil)
+}
+
+// callTimeout returns the call's timeout from ctx: the client's, if it asked for one,
+// else the tool's own or the server default
+func (s *Server) callTimeout(ctx context.Context, spec *tools.ToolSpec) time.Duration {
+	if timeout, ok := ctx.Value(callTimeoutKey{}).(time.Duration); ok {
+		return timeout
+	}
+	return s.toolTimeout(spec)
+}
+
+// withDeadlineMeta reports the call's use of its timeout in the result's _meta
+func withDeadlineMeta(r ToolsCallResult, timeout time.Duration, start time.Time) ToolsCallResult {
+	elapsed := time.Since(start)
+	info := DeadlineInfo{
+		TimeoutMs: timeout.Milliseconds(),
+		ElapsedMs: elapsed.Milliseconds(),
+	}
+	if remaining := timeout - elapsed; remaining > 0 {
+		info.RemainingMs = remaining.Milliseconds()
+	}
+	r.Meta = withMetaValue(r.Meta, DeadlineMetaKey, info)
+	return r
+}
@@ -0,0 +1,132 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// deadlineMeta returns the deadline info of a result, or nil when it has none
+func deadlineMeta(t *testing.T, result ToolsCallResult) map[string]interface{} {
+	t.Helper()
+	info, _ := result.Meta[DeadlineMetaKey].(map[string]interface{})
+	return info
+}
+
+func TestClientTimeout(t *testing.T) {
+	deadlines := make(chan time.Duration, 1)
+	wait := tools.NewTool("wait", "Waits for its context", func(ctx context.Context, in struct{}) (string, error) {
+		if deadline, ok := ctx.Deadline(); ok {
+			deadlines <- time.Until(deadline)
+		} else {
+			deadlines <- 0
+		}
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	quick := tools.NewTool("quick", "Returns at once", func(ctx context.Context, in struct{}) (string, error) {
+		return "ok", nil
+	})
+	server := NewServer(ServerConfig{Name: "test-server", Tools: []tools.Tool{wait, quick}})
+	handler := NewJSONRPCHandler(server)
+
+	result := callToolResult(t, handler,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"wait","arguments":{},"_meta":{"timeoutMs":30}}}`)
+	if remaining := <-deadlines; remaining <= 0 || remaining > 30*time.Millisecond {
+		t.Errorf("expected the tool's context to carry the 30ms deadline, got %s left", remaining)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "timed out after 30ms") {
+		t.Errorf("expected a timeout, got %+v", result)
+	}
+	info := deadlineMeta(t, result)
+	if info == nil || info["timeoutMs"] != float64(30) || info["remainingMs"] != float64(0) || info["elapsedMs"].(float64) < 30 {
+		t.Errorf("unexpected deadline meta %v", info)
+	}
+
+	// params.timeoutMs is accepted too, and the remaining time is reported
+	result = callToolResult(t, handler,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"quick","arguments":{},"timeoutMs":5000}}`)
+	if info := deadlineMeta(t, result); info == nil || info["timeoutMs"] != float64(5000) || info["remainingMs"].(float64) <= 0 {
+		t.Errorf("unexpected deadline meta %v", info)
+	}
+
+	// Calls without a timeout carry no deadline meta
+	result = callToolResult(t, handler,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"quick","arguments":{}}}`)
+	if _, ok := result.Meta[DeadlineMetaKey]; ok {
+		t.Errorf("expected no deadline meta, got %v", result.Meta)
+	}
+}
+
+func TestClientTimeout_NotInheritedByNestedCalls(t *testing.T) {
+	inner := tools.NewTool("inner", "Waits for its context", func(ctx context.Context, in struct{}) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}, tools.WithTimeout(20*time.Millisecond))
+	var server *Server
+	outer := tools.NewTool("outer", "Calls inner", func(ctx context.Context, in struct{}) (string, error) {
+		result, err := server.CallTool(ctx, "inner", json.RawMessage(`{}`))
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].Text, nil
+	})
+	server = NewServer(ServerConfig{Name: "test-server", Tools: []tools.Tool{inner, outer}})
+	handler := NewJSONRPCHandler(server)
+
+	// The client's 5s timeout applies to outer; inner keeps its own 20ms
+	start := time.Now()
+	result := callToolResult(t, handler,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"outer","arguments":{},"_meta":{"timeoutMs":5000}}}`)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected inner to time out after its own 20ms, took %s", elapsed)
+	}
+	if result.IsError || !strings.Contains(result.Content[0].Text, "timed out after 20ms") {
+		t.Errorf("expected outer to report inner's timeout, got %+v", result)
+	}
+	if info := deadlineMeta(t, result); info == nil || info["timeoutMs"] != float64(5000) {
+		t.Errorf("unexpected deadline meta %v", info)
+	}
+}
+
+func TestClampTimeout(t *testing.T) {
+	limited := &tools.ToolSpec{Name: "limited", Timeout: time.Second}
+	open := &tools.ToolSpec{Name: "open"}
+
+	tests := []struct {
+		name      string
+		max       time.Duration
+		spec      *tools.ToolSpec
+		requested time.Duration
+		want      time.Duration
+	}{
+		{"shorter than the tool's timeout", 0, limited, 200 * time.Millisecond, 200 * time.Millisecond},
+		{"cannot extend the tool's timeout", 0, limited, time.Minute, time.Second},
+		{"unbounded tool", 0, open, time.Hour, time.Hour},
+		{"server maximum", 10 * time.Second, open, time.Minute, 10 * time.Second},
+		{"server maximum may extend the tool's timeout", 10 * time.Second, limited, 5 * time.Second, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(ServerConfig{Name: "test-server", MaxToolTimeout: tt.max})
+			if got := server.clampTimeout(tt.spec, tt.requested); got != tt.want {
+				t.Errorf("clampTimeout = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestedTimeout(t *testing.T) {
+	params := ToolsCallParams{TimeoutMs: 100, Meta: &RequestMeta{TimeoutMs: 250}}
+	if got := params.requestedTimeout(); got != 250*time.Millisecond {
+		t.Errorf("expected _meta.timeoutMs to take precedence, got %s", got)
+	}
+	params = ToolsCallParams{TimeoutMs: -5}
+	if got := params.requestedTimeout(); got != 0 {
+		t.Errorf("expected negative timeouts to be ignored, got %s", got)
+	}
+}
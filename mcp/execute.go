@@ -0,0 +1,134 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// ErrToolTimeout is returned when a tool does not finish within its timeout
+var ErrToolTimeout = errors.New("tool execution timed out")
+
+// toolTimeout returns the effective timeout for a tool: its own, or the server default
+func (s *Server) toolTimeout(spec *tools.ToolSpec) time.Duration {
+	if spec.Timeout > 0 {
+		return spec.Timeout
+	}
+	return s.defaultToolTimeout
+}
+
+// executeTool runs a tool, recording its outcome and reusing cached results when the
+// server has a result cache
+func (s *Server) executeTool(ctx context.Context, tool tools.Tool, args json.RawMessage) (result *tools.ToolResult, err error) {
+	spec := tool.Spec()
+
+	recordStats := s.stats.start(spec.Name)
+	defer func() { recordStats(err != nil || result.IsError()) }()
+
+	if s.metrics != nil {
+		done := s.metrics.StartToolCall(spec.Name)
+		defer func() { done(err != nil || result.IsError()) }()
+	}
+
+	ctx, span := s.startToolSpan(ctx, spec.Name)
+	defer func() { endToolSpan(span, err != nil || result.IsError(), err) }()
+
+	ctx = s.attachUploads(ctx)
+	if s.secrets != nil {
+		ctx = tools.WithSecrets(ctx, s.secrets)
+	}
+
+	// Nested calls share the budget of the outermost request
+	if s.requestBudget != (tools.BudgetLimits{}) && tools.BudgetFrom(ctx) == nil {
+		ctx = tools.WithBudget(ctx, tools.NewBudget(s.requestBudget))
+	}
+
+	if _, streaming := tool.(tools.StreamingTool); s.resultCache != nil && !streaming {
+		run := func(ctx context.Context, args json.RawMessage) (*tools.ToolResult, error) {
+			return s.executeWithTimeout(ctx, tool, args)
+		}
+		return s.resultCache.Middleware()(spec, run)(ctx, args)
+	}
+	return s.executeWithTimeout(ctx, tool, args)
+}
+
+// executeWithTimeout runs a tool once the scheduler allows it, enforcing its timeout.
+// Time spent queued behind other calls does not count against the timeout. A tool that
+// ignores context cancellation is abandoned once the timeout passes, so it cannot block
+// the caller, but it keeps its place in the queue until it returns.
+func (s *Server) executeWithTimeout(ctx context.Context, tool tools.Tool, args json.RawMessage) (*tools.ToolResult, error) {
+	spec := tool.Spec()
+	release, err := s.scheduler.acquire(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := s.callTimeout(ctx, spec)
+	ctx = withoutCallTimeout(ctx)
+	if timeout <= 0 {
+		defer release()
+		return s.safeExecute(ctx, tool, args)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		result *tools.ToolResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := s.safeExecute(ctx, tool, args)
+		release()
+		done <- outcome{result: result, err: err}
+	}()
+
+	select {
+	case out := <-done:
+		if out.err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w after %s", ErrToolTimeout, timeout)
+		}
+		return out.result, out.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			s.logger.Warn("tool exceeded timeout", "tool", spec.Name, "timeout", timeout)
+			return nil, fmt.Errorf("%w after %s", ErrToolTimeout, timeout)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// safeExecute runs a tool, converting a panic into an internal error so that one
+// misbehaving tool cannot take down the transport serving it
+func (s *Server) safeExecute(ctx context.Context, tool tools.Tool, args json.RawMessage) (result *tools.ToolResult, err error) {
+	defer s.active.track(tool.Spec().Name, sessionFrom(ctx))()
+	defer func() {
+		if r := recover(); r != nil {
+			name := tool.Spec().Name
+			s.logger.Error("tool panicked",
+				"tool", name,
+				"panic", fmt.Sprintf("%v", r),
+				"stack", string(debug.Stack()))
+			result = nil
+			err = tools.NewError(tools.CodeInternalError, fmt.Sprintf("tool %q panicked: %v", name, r))
+		}
+	}()
+
+	// Streaming tools push chunks to clients whose transport can receive them
+	streaming, isStreaming := tool.(tools.StreamingTool)
+	if emit := chunkEmitter(ctx); isStreaming && emit != nil {
+		result, err = streaming.ExecuteStream(ctx, args, emit)
+	} else {
+		result, err = tool.Execute(ctx, args)
+	}
+	if result == nil && err == nil {
+		result = &tools.ToolResult{}
+	}
+	return result, err
+}
@@ -0,0 +1,213 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func callToolResult(t *testing.T, handler *JSONRPCHandler, msg string) ToolsCallResult {
+	t.Helper()
+
+	resp, err := handler.HandleMessage(context.Background(), []byte(msg))
+	if err != nil {
+		t.Fatalf("HandleMessage failed: %v", err)
+	}
+	if resp.Error != nil {
+		t.Fatalf("expected tool result, got RPC error: %v", resp.Error)
+	}
+
+	resultBytes, _ := json.Marshal(resp.Result)
+	var result ToolsCallResult
+	if err := json.Unmarshal(resultBytes, &result); err != nil {
+		t.Fatalf("failed to unmarshal result: %v", err)
+	}
+	return result
+}
+
+func TestExecuteTool_ToolTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	// The tool ignores its context entirely, so only the server can unblock the caller
+	hung := tools.NewTool("hung", "Never returns in time", func(ctx context.Context, in struct{}) (string, error) {
+		<-release
+		return "too late", nil
+	}, tools.WithTimeout(20*time.Millisecond))
+
+	server := NewServer(ServerConfig{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Tools:   []tools.Tool{hung},
+	})
+
+	result := callToolResult(t, NewJSONRPCHandler(server),
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"hung","arguments":{}}}`)
+
+	if !result.IsError {
+		t.Fatal("expected IsError result for timed out tool")
+	}
+	if !strings.Contains(result.Content[0].Text, "timed out") {
+		t.Errorf("expected timeout message, got %q", result.Content[0].Text)
+	}
+}
+
+func TestExecuteTool_DefaultTimeout(t *testing.T) {
+	slow := tools.NewTool("slow", "Respects its context", func(ctx context.Context, in struct{}) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	fast := tools.NewTool("fast", "Returns immediately", func(ctx context.Context, in struct{}) (string, error) {
+		return "done", nil
+	})
+
+	server := NewServer(ServerConfig{
+		Name:               "test-server",
+		Version:            "1.0.0",
+		Tools:              []tools.Tool{slow, fast},
+		DefaultToolTimeout: 20 * time.Millisecond,
+	})
+	handler := NewJSONRPCHandler(server)
+
+	result := callToolResult(t, handler,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow","arguments":{}}}`)
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "timed out") {
+		t.Errorf("expected timeout result, got %+v", result)
+	}
+
+	result = callToolResult(t, handler,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"fast","arguments":{}}}`)
+	if result.IsError || result.Content[0].Text != "done" {
+		t.Errorf("expected successful result, got %+v", result)
+	}
+}
+
+func TestExecuteTool_PanicRecovery(t *testing.T) {
+	panicky := &mockTool{
+		name:        "panicky",
+		description: "Always panics",
+		parameters:  map[string]interface{}{"type": "object"},
+		executeFn: func(ctx context.Context, params json.RawMessage) (*tools.ToolResult, error) {
+			panic("boom")
+		},
+	}
+
+	server := NewServer(ServerConfig{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Tools:   []tools.Tool{panicky},
+	})
+
+	resp, err := NewJSONRPCHandler(server).HandleMessage(context.Background(),
+		[]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"panicky"}}`))
+	if err != nil {
+		t.Fatalf("HandleMessage failed: %v", err)
+	}
+	if resp.Error == nil || resp.Error.Code != InternalError {
+		t.Fatalf("expected InternalError response, got %+v", resp)
+	}
+
+	// The timeout path runs the tool on its own goroutine and must recover there too
+	server.defaultToolTimeout = time.Second
+	resp, err = NewJSONRPCHandler(server).HandleMessage(context.Background(),
+		[]byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"panicky"}}`))
+	if err != nil {
+		t.Fatalf("HandleMessage failed: %v", err)
+	}
+	if resp.Error == nil || resp.Error.Code != InternalError {
+		t.Fatalf("expected InternalError response, got %+v", resp)
+	}
+}
+
+func TestExecuteTool_RequestBudget(t *testing.T) {
+	leaf := tools.NewTool("leaf", "Does a small thing", func(ctx context.Context, in struct{}) (string, error) {
+		return "leaf", nil
+	})
+
+	// The fan tool calls leaf repeatedly and should be stopped by the request budget
+	fan := tools.NewTool("fan", "Calls leaf many times", func(ctx context.Context, in struct{}) (string, error) {
+		for i := 0; i < 10; i++ {
+			if _, err := tools.Call[struct{}, string](ctx, leaf, struct{}{}); err != nil {
+				return "", err
+			}
+		}
+		return "all done", nil
+	})
+
+	server := NewServer(ServerConfig{
+		Name:          "test-server",
+		Version:       "1.0.0",
+		Tools:         []tools.Tool{leaf, fan},
+		RequestBudget: tools.BudgetLimits{MaxCalls: 3},
+	})
+
+	result := callToolResult(t, NewJSONRPCHandler(server),
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"fan","arguments":{}}}`)
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "budget exceeded") {
+		t.Errorf("expected budget exceeded result, got %+v", result)
+	}
+}
+
+func TestExecuteTool_ResultCache(t *testing.T) {
+	type schemaInput struct {
+		Table string `json:"table"`
+	}
+	var introspections, clocks int
+	schema := tools.NewTool("schema", "Describes a table", func(ctx context.Context, in schemaInput) (string, error) {
+		introspections++
+		return "columns of " + in.Table, nil
+	}, tools.WithCacheable(time.Minute))
+	clock := tools.NewTool("clock", "Reads the clock", func(ctx context.Context, in struct{}) (int, error) {
+		clocks++
+		return clocks, nil
+	})
+
+	cache := tools.NewResultCache(100)
+	server := NewServer(ServerConfig{
+		Name:        "test-server",
+		Version:     "1.0.0",
+		Tools:       []tools.Tool{schema, clock},
+		ResultCache: cache,
+	})
+	handler := NewJSONRPCHandler(server)
+
+	for i := 0; i < 3; i++ {
+		result := callToolResult(t, handler,
+			`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"schema","arguments":{"table":"users"}}}`)
+		if result.IsError || !strings.Contains(result.Content[0].Text, "columns of users") {
+			t.Fatalf("unexpected result %+v", result)
+		}
+		callToolResult(t, handler, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"clock","arguments":{}}}`)
+	}
+	if introspections != 1 {
+		t.Errorf("expected the cacheable tool to run once, ran %d times", introspections)
+	}
+	if clocks != 3 {
+		t.Errorf("expected the uncached tool to run every time, ran %d times", clocks)
+	}
+	// Cache hits are still counted as calls
+	if stats := server.GetStats(); stats.Tools[1].Tool != "schema" || stats.Tools[1].Calls != 3 {
+		t.Errorf("unexpected stats %+v", stats.Tools)
+	}
+}
+
+func TestExecuteTool_Secrets(t *testing.T) {
+	fetch := tools.NewTool("fetch", "Uses an API key", func(ctx context.Context, in struct{}) (string, error) {
+		return tools.Secret(ctx, "API_KEY")
+	})
+	server := NewServer(ServerConfig{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Tools:   []tools.Tool{fetch},
+		Secrets: tools.MapSecrets{"API_KEY": "fake-key"},
+	})
+
+	result, err := server.executeTool(context.Background(), fetch, json.RawMessage(`{}`))
+	if err != nil || result.Output != "fake-key" {
+		t.Errorf("expected the injected secret, got %v, %v", result, err)
+	}
+}
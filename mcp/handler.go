@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// HandlerOptions configures the http.Handler returned by Server.Handler
+type HandlerOptions struct {
+	// Logger for request logging. Defaults to the server's logger.
+	Logger *slog.Logger
+
+	// Validator authenticates requests to the MCP and REST routes. Required.
+	Validator APIKeyValidator
+
+	// AuthHeaderType selects where the API key is read from. Defaults to AuthHeaderBearer.
+	AuthHeaderType AuthHeaderType
+
+	// DisableREST removes the /mcp/tools/list, /mcp/tools/call, and /mcp/tools/validate REST routes, leaving only JSON-RPC
+	DisableREST bool
+
+	// DisableHealth removes the /mcp/health and /mcp/ready routes
+	DisableHealth bool
+
+	// RequireHealthAuth applies the validator to /mcp/health and /mcp/ready, which are public by default
+	RequireHealthAuth bool
+
+	// RequireMetricsAuth applies the validator to /metrics, which is public by default
+	RequireMetricsAuth bool
+
+	// OAuth, when set, enables OAuth 2.1 authorization and serves protected resource metadata
+	OAuth *OAuthConfig
+}
+
+// Handler returns an http.Handler serving every MCP route (the JSON-RPC endpoint at /mcp,
+// the REST endpoints under /mcp/tools/, /mcp/health and /mcp/ready, /mcp/stats, /mcp/uploads when ServerConfig.Uploads
+// is set, /metrics when ServerConfig.Metrics is set, and, with OAuth enabled, the /.well-known/oauth-protected-resource metadata)
+// without starting a server.
+// Use it when the application owns the http.Server, TLS configuration, and middleware stack:
+//
+//	mcpHandler := server.Handler(mcp.HandlerOptions{Validator: validator})
+//	mux := http.NewServeMux()
+//	mux.Handle("/mcp", mcpHandler)
+//	mux.Handle("/mcp/", mcpHandler)
+//	http.ListenAndServeTLS(":443", certFile, keyFile, withMiddleware(mux))
+func (s *Server) Handler(opts HandlerOptions) http.Handler {
+	logger := opts.Logger
+	if logger == nil {
+		logger = s.logger
+	}
+
+	transport := NewHTTPTransport(s, logger, opts.Validator)
+	if opts.AuthHeaderType != "" {
+		transport.WithAuthHeaderType(opts.AuthHeaderType)
+	}
+	transport.WithRESTEndpoints(!opts.DisableREST).
+		WithHealthEndpoint(!opts.DisableHealth).
+		WithHealthAuth(opts.RequireHealthAuth).
+		WithMetricsAuth(opts.RequireMetricsAuth)
+	if opts.OAuth != nil {
+		transport.WithOAuth(*opts.OAuth)
+	}
+	return transport
+}
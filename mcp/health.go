@@ -0,0 +1,179 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mhpenta/minimcp/buildinfo"
+)
+
+// DefaultHealthCheckTimeout bounds each health check run for /mcp/health and /mcp/ready
+const DefaultHealthCheckTimeout = 5 * time.Second
+
+// HealthCheck reports whether a dependency, such as a database, is usable. It matches
+// the signature of (*sql.DB).PingContext, so a database can be checked with
+// HealthChecks: map[string]mcp.HealthCheck{"database": db.PingContext}.
+type HealthCheck func(ctx context.Context) error
+
+// HealthCheckResult is the outcome of one health check
+type HealthCheckResult struct {
+	Status     string  `json:"status"` // "ok" or "error"
+	Error      string  `json:"error,omitempty"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// HealthReport is the body of /mcp/health and /mcp/ready
+type HealthReport struct {
+	Status        string                       `json:"status"` // "healthy", "unhealthy", "ready", or "not_ready"
+	Timestamp     int64                        `json:"timestamp"`
+	Version       string                       `json:"version"`
+	Build         buildinfo.Info               `json:"build"`
+	Tools         int                          `json:"tools"`
+	Uptime        string                       `json:"uptime"`
+	UptimeSeconds float64                      `json:"uptime_seconds"`
+	Checks        map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
+// healthChecks holds the registered health checks and readiness state
+type healthChecks struct {
+	mu       sync.RWMutex
+	checks   map[string]HealthCheck
+	notReady bool
+}
+
+// AddHealthCheck registers a health check run by /mcp/health and /mcp/ready, replacing
+// any check of the same name
+func (s *Server) AddHealthCheck(name string, check HealthCheck) {
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+	if s.health.checks == nil {
+		s.health.checks = make(map[string]HealthCheck)
+	}
+	s.health.checks[name] = check
+}
+
+// SetReady marks the server ready or not ready to receive traffic, as reported by
+// /mcp/ready. Servers start ready; mark one not ready during warm-up or maintenance.
+func (s *Server) SetReady(ready bool) {
+	s.health.mu.Lock()
+	s.health.notReady = !ready
+	s.health.mu.Unlock()
+}
+
+// CheckHealth runs the registered health checks concurrently and reports the server's
+// state. The report's status is "healthy" unless a check failed.
+func (s *Server) CheckHealth(ctx context.Context) HealthReport {
+	s.health.mu.RLock()
+	checks := make(map[string]HealthCheck, len(s.health.checks))
+	for name, check := range s.health.checks {
+		checks[name] = check
+	}
+	s.health.mu.RUnlock()
+
+	uptime := time.Since(s.started)
+	report := HealthReport{
+		Status:        "healthy",
+		Timestamp:     time.Now().Unix(),
+		Version:       s.version,
+		Build:         buildinfo.Get(),
+		Tools:         len(s.GetTools()),
+		Uptime:        uptime.Round(time.Second).String(),
+		UptimeSeconds: uptime.Seconds(),
+	}
+	if len(checks) == 0 {
+		return report
+	}
+
+	report.Checks = make(map[string]HealthCheckResult, len(checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := runHealthCheck(ctx, check)
+			mu.Lock()
+			report.Checks[name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if failed := failedChecks(report); len(failed) > 0 {
+		report.Status = "unhealthy"
+		s.logger.Warn("health check failed", "checks", failed)
+	}
+	return report
+}
+
+// runHealthCheck runs one check under DefaultHealthCheckTimeout, recovering from panics
+func runHealthCheck(ctx context.Context, check HealthCheck) (result HealthCheckResult) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultHealthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			result = HealthCheckResult{Status: "error", Error: fmt.Sprintf("panic: %v", r)}
+		}
+		result.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+	}()
+
+	if err := check(ctx); err != nil {
+		return HealthCheckResult{Status: "error", Error: err.Error()}
+	}
+	return HealthCheckResult{Status: "ok"}
+}
+
+// handleHealth reports the server's health, with status 503 when a health check fails
+func (t *HTTPTransport) handleHealth(w http.ResponseWriter, r *http.Request) {
+	report := t.server.CheckHealth(r.Context())
+	status := http.StatusOK
+	if report.Status != "healthy" {
+		status = http.StatusServiceUnavailable
+	}
+	writeHealthReport(w, status, report)
+}
+
+// handleReady reports whether the server should receive traffic: it is ready unless
+// marked otherwise with SetReady or a health check fails
+func (t *HTTPTransport) handleReady(w http.ResponseWriter, r *http.Request) {
+	t.server.health.mu.RLock()
+	notReady := t.server.health.notReady
+	t.server.health.mu.RUnlock()
+
+	report := t.server.CheckHealth(r.Context())
+	status := http.StatusOK
+	if notReady || report.Status != "healthy" {
+		status = http.StatusServiceUnavailable
+		report.Status = "not_ready"
+	} else {
+		report.Status = "ready"
+	}
+	writeHealthReport(w, status, report)
+}
+
+// failedChecks returns the names of the report's failed checks, sorted
+func failedChecks(report HealthReport) []string {
+	var failed []string
+	for name, result := range report.Checks {
+		if result.Status != "ok" {
+			failed = append(failed, name)
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+// writeHealthReport writes report as an uncacheable JSON response
+func writeHealthReport(w http.ResponseWriter, status int, report HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
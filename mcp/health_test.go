@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func getHealthReport(t *testing.T, handler http.Handler, path string) (int, HealthReport) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var report HealthReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("%s: decoding report: %v", path, err)
+	}
+	return rec.Code, report
+}
+
+func TestHealthChecks(t *testing.T) {
+	echo := tools.NewTool("echo", "Echoes", func(ctx context.Context, in struct{}) (string, error) {
+		return "", nil
+	})
+	var dbErr error
+	server := NewServer(ServerConfig{
+		Name:         "test-server",
+		Version:      "2.3.4",
+		Tools:        []tools.Tool{echo},
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		HealthChecks: map[string]HealthCheck{"database": func(ctx context.Context) error { return dbErr }},
+	})
+	server.AddHealthCheck("cache", func(ctx context.Context) error { return nil })
+	transport := NewHTTPTransport(server, server.logger, newMockValidator("test-key"))
+
+	code, report := getHealthReport(t, transport, "/mcp/health")
+	if code != http.StatusOK || report.Status != "healthy" {
+		t.Fatalf("expected healthy, got %d %+v", code, report)
+	}
+	if report.Version != "2.3.4" || report.Tools != 1 || report.Uptime == "" || report.UptimeSeconds > 60 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if len(report.Checks) != 2 || report.Checks["database"].Status != "ok" || report.Checks["cache"].Status != "ok" {
+		t.Errorf("unexpected checks %+v", report.Checks)
+	}
+
+	dbErr = errors.New("connection refused")
+	for _, path := range []string{"/mcp/health", "/mcp/ready"} {
+		code, report = getHealthReport(t, transport, path)
+		if code != http.StatusServiceUnavailable {
+			t.Errorf("%s: expected 503 with a failing check, got %d", path, code)
+		}
+		if check := report.Checks["database"]; check.Status != "error" || check.Error != "connection refused" {
+			t.Errorf("%s: expected the database failure, got %+v", path, check)
+		}
+		if report.Checks["cache"].Status != "ok" {
+			t.Errorf("%s: expected the cache check to pass, got %+v", path, report.Checks["cache"])
+		}
+	}
+	if report.Status != "not_ready" {
+		t.Errorf("expected not_ready, got %q", report.Status)
+	}
+}
+
+func TestHealthCheckPanicIsReported(t *testing.T) {
+	server := NewServer(ServerConfig{
+		Name:         "test-server",
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		HealthChecks: map[string]HealthCheck{"flaky": func(ctx context.Context) error { panic("boom") }},
+	})
+	report := server.CheckHealth(context.Background())
+	if report.Status != "unhealthy" || report.Checks["flaky"].Error != "panic: boom" {
+		t.Errorf("expected the panic as a failed check, got %+v", report)
+	}
+}
+
+func TestReadyEndpoint(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server", Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	transport := NewHTTPTransport(server, server.logger, newMockValidator("test-key"))
+
+	if code, report := getHealthReport(t, transport, "/mcp/ready"); code != http.StatusOK || report.Status != "ready" {
+		t.Errorf("expected ready, got %d %q", code, report.Status)
+	}
+
+	server.SetReady(false)
+	if code, report := getHealthReport(t, transport, "/mcp/ready"); code != http.StatusServiceUnavailable || report.Status != "not_ready" {
+		t.Errorf("expected not_ready, got %d %q", code, report.Status)
+	}
+	// Liveness is unaffected by readiness
+	if code, _ := getHealthReport(t, transport, "/mcp/health"); code != http.StatusOK {
+		t.Errorf("expected health to stay 200, got %d", code)
+	}
+
+	transport.WithHealthEndpoint(false)
+	rec := httptest.NewRecorder()
+	transport.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mcp/ready", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected /mcp/ready to be disabled with the health endpoint, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,135 @@
+package mcp
+
+import (
+	"context"
+	"time"
+)
+
+// LatestProtocolVersion is the newest MCP protocol version the server speaks
+const LatestProtocolVersion = "2025-03-26"
+
+// supportedProtocolVersions lists every protocol version the server accepts, newest first
+var supportedProtocolVersions = []string{LatestProtocolVersion, "2024-11-05"}
+
+const (
+	// initializeResultTTL bounds how long a built InitializeResult is reused
+	initializeResultTTL = 5 * time.Minute
+
+	// maxInitializedSessions bounds how many sessions' initialization state is tracked
+	maxInitializedSessions = 10000
+
+	// initializedSessionTTL is the age after which a session's initialization state may be pruned
+	initializedSessionTTL = 24 * time.Hour
+)
+
+// initializedSession records when a session initialized and the client it identified as
+type initializedSession struct {
+	at     time.Time
+	client ClientInfo
+}
+
+// cachedInitializeResult is an InitializeResult built for one negotiated protocol version
+type cachedInitializeResult struct {
+	result   InitializeResult
+	revision uint64
+	expires  time.Time
+}
+
+// negotiateProtocolVersion echoes the client's version when supported, and otherwise
+// proposes the latest version, leaving the client to disconnect if it cannot speak it
+func negotiateProtocolVersion(requested string) string {
+	for _, version := range supportedProtocolVersions {
+		if version == requested {
+			return version
+		}
+	}
+	return LatestProtocolVersion
+}
+
+// initializeResult returns the cached result for the negotiated version, rebuilding it once
+// expired or once the server's capabilities have changed
+func (h *JSONRPCHandler) initializeResult(version string) InitializeResult {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	revision := h.server.capabilityRevision.Load()
+	if cached, ok := h.initResults[version]; ok && cached.revision == revision && now.Before(cached.expires) {
+		return cached.result
+	}
+
+	result := InitializeResult{
+		ProtocolVersion: version,
+		Capabilities: ServerCapabilities{
+			Tools: map[string]interface{}{
+				"listChanged": true,
+			},
+			Experimental: map[string]interface{}{
+				ToolsDiffCapability: map[string]interface{}{},
+				MethodValidate:      map[string]interface{}{},
+			},
+		},
+		ServerInfo: ServerInfo{
+			Name:    h.server.name,
+			Version: h.server.version,
+		},
+	}
+	if h.server.auditLog != nil {
+		result.Capabilities.Experimental[MethodAuditReplay] = map[string]interface{}{}
+	}
+	// Overflowing tool descriptions, resource templates, and providers are served as
+	// resources. Providers can report changes. Capabilities are never empty objects,
+	// which omitempty would drop from the response.
+	if h.server.hasResources() {
+		providers := h.server.hasResourceProviders()
+		result.Capabilities.Resources = map[string]interface{}{
+			"subscribe":   providers,
+			"listChanged": providers,
+		}
+	}
+	if h.server.hasPrompts() {
+		result.Capabilities.Prompts = map[string]interface{}{
+			"listChanged": false,
+		}
+	}
+	h.initResults[version] = cachedInitializeResult{result: result, revision: revision, expires: now.Add(initializeResultTTL)}
+	return result
+}
+
+// markInitialized records that the session has initialized. It returns false if the
+// session was already initialized, in which case its state is left untouched.
+func (h *JSONRPCHandler) markInitialized(session string, client ClientInfo) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.initialized[session]; ok {
+		return false
+	}
+
+	now := time.Now()
+	if len(h.initialized) >= maxInitializedSessions {
+		oldestID, oldest := "", now
+		for id, state := range h.initialized {
+			if now.Sub(state.at) > initializedSessionTTL {
+				delete(h.initialized, id)
+			} else if state.at.Before(oldest) {
+				oldestID, oldest = id, state.at
+			}
+		}
+		if len(h.initialized) >= maxInitializedSessions {
+			delete(h.initialized, oldestID)
+		}
+	}
+	h.initialized[session] = initializedSession{at: now, client: client}
+	return true
+}
+
+// clientInfo returns the client info the session in ctx sent in initialize, if known
+func (h *JSONRPCHandler) clientInfo(ctx context.Context) ClientInfo {
+	if !hasStatefulSession(ctx) {
+		return ClientInfo{}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.initialized[sessionFrom(ctx)].client
+}
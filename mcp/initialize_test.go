@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func initialize(t *testing.T, handler *JSONRPCHandler, ctx context.Context, version string) *JSONRPCResponse {
+	t.Helper()
+	msg := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"` + version + `","clientInfo":{"name":"test-client","version":"1.0"}}}`
+	resp, err := handler.HandleMessage(ctx, []byte(msg))
+	if err != nil {
+		t.Fatalf("HandleMessage failed: %v", err)
+	}
+	return resp
+}
+
+func TestJSONRPCHandler_InitializeNegotiatesVersion(t *testing.T) {
+	handler := NewJSONRPCHandler(NewServer(ServerConfig{Name: "test-server", Version: "1.0.0"}))
+
+	for requested, want := range map[string]string{
+		"2024-11-05": "2024-11-05",
+		"2025-03-26": "2025-03-26",
+		"1999-01-01": LatestProtocolVersion,
+	} {
+		resp := initialize(t, handler, context.Background(), requested)
+		data, _ := json.Marshal(resp.Result)
+		var result InitializeResult
+		json.Unmarshal(data, &result)
+		if result.ProtocolVersion != want {
+			t.Errorf("requested %s: expected %s, got %s", requested, want, result.ProtocolVersion)
+		}
+	}
+	if len(handler.initResults) != 2 {
+		t.Errorf("expected one cached result per negotiated version, got %d", len(handler.initResults))
+	}
+}
+
+func TestJSONRPCHandler_Reinitialize(t *testing.T) {
+	handler := NewJSONRPCHandler(NewServer(ServerConfig{Name: "test-server", Version: "1.0.0"}))
+
+	session := withSession(context.Background(), "session-a")
+	if resp := initialize(t, handler, session, "2024-11-05"); resp.Error != nil {
+		t.Fatalf("first initialize failed: %+v", resp.Error)
+	}
+	if resp := initialize(t, handler, session, "2024-11-05"); resp.Error == nil || resp.Error.Code != InvalidRequest {
+		t.Errorf("expected InvalidRequest on re-initialization, got %+v", resp)
+	}
+
+	if resp := initialize(t, handler, withSession(context.Background(), "session-b"), "2024-11-05"); resp.Error != nil {
+		t.Errorf("expected a new session to initialize, got %+v", resp.Error)
+	}
+
+	// Connections without a stable session identity keep no initialization state
+	conn := withConnection(context.Background(), "192.0.2.1:1234")
+	for i := 0; i < 2; i++ {
+		if resp := initialize(t, handler, conn, "2024-11-05"); resp.Error != nil {
+			t.Errorf("expected stateless initialize to succeed, got %+v", resp.Error)
+		}
+	}
+}
+
+func TestJSONRPCHandler_InitializeAfterAddingCapabilities(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server", Version: "1.0.0"})
+	handler := NewJSONRPCHandler(server)
+
+	capabilities := func() ServerCapabilities {
+		resp := initialize(t, handler, context.Background(), "2025-03-26")
+		data, _ := json.Marshal(resp.Result)
+		var result InitializeResult
+		json.Unmarshal(data, &result)
+		return result.Capabilities
+	}
+
+	if caps := capabilities(); caps.Prompts != nil || caps.Resources != nil {
+		t.Fatalf("expected no prompts or resources yet, got %+v", caps)
+	}
+
+	err := server.AddPrompts(Prompt{Name: "greet", Render: func(ctx context.Context, args map[string]string) (*PromptsGetResult, error) {
+		return &PromptsGetResult{}, nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caps := capabilities(); caps.Prompts == nil {
+		t.Errorf("expected the prompts capability after AddPrompts, got %+v", caps)
+	}
+
+	err = server.AddResourceTemplates(ResourceTemplate{URITemplate: "notes://{id}", Name: "note",
+		Resolve: func(ctx context.Context, uri string, vars map[string]string) ([]ResourceContents, error) {
+			return nil, nil
+		}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caps := capabilities(); caps.Resources == nil || caps.Resources["subscribe"] != false {
+		t.Errorf("expected the resources capability after AddResourceTemplates, got %+v", caps)
+	}
+
+	if err := server.AddResourceProviders(mapProvider{"notes://1": "one"}); err != nil {
+		t.Fatal(err)
+	}
+	if caps := capabilities(); caps.Resources["subscribe"] != true {
+		t.Errorf("expected resource subscriptions after AddResourceProviders, got %+v", caps)
+	}
+}
@@ -0,0 +1,205 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// Interop traces replay the messages real MCP clients send against a fixed server. Each
+// fixture in testdata/interop lists the client's messages in order, with the response
+// expected for each request. Expectations match as subsets: every field in the fixture
+// must be present in the response with the same value, while fields the fixture omits are
+// ignored, so additive server changes keep passing but anything a client depends on
+// breaking fails here first.
+
+type interopTrace struct {
+	Client    string            `json:"client"`
+	Version   string            `json:"version"`
+	Exchanges []interopExchange `json:"exchanges"`
+}
+
+type interopExchange struct {
+	Send   json.RawMessage `json:"send"`
+	Expect json.RawMessage `json:"expect,omitempty"`
+}
+
+type interopWeatherInput struct {
+	City string `json:"city" description:"city name, e.g. Paris"`
+	Unit string `json:"unit,omitempty" jsonschema:"enum=c|f,default=c"`
+}
+
+type interopWeatherOutput struct {
+	City        string  `json:"city"`
+	Temperature float64 `json:"temperature"`
+	Unit        string  `json:"unit"`
+}
+
+type interopAddInput struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+type interopAddOutput struct {
+	Sum int `json:"sum"`
+}
+
+// newInteropServer builds the server every trace is replayed against. Changing its tools
+// means updating the fixtures.
+func newInteropServer() *Server {
+	weather := tools.NewTool("get_weather", "Get the current weather for a city",
+		func(ctx context.Context, in interopWeatherInput) (interopWeatherOutput, error) {
+			unit := in.Unit
+			if unit == "" {
+				unit = "c"
+			}
+			return interopWeatherOutput{City: in.City, Temperature: 21.5, Unit: unit}, nil
+		},
+		tools.WithInputValidation(true),
+	)
+	add := tools.NewTool("add", "Add two integers",
+		func(ctx context.Context, in interopAddInput) (interopAddOutput, error) {
+			return interopAddOutput{Sum: in.A + in.B}, nil
+		},
+	)
+	return NewServer(ServerConfig{
+		Name:    "interop-server",
+		Version: "1.0.0",
+		Tools:   []tools.Tool{weather, add},
+	})
+}
+
+func TestInterop_ClientTraces(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "interop", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no interop traces found in testdata/interop")
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var trace interopTrace
+		if err := json.Unmarshal(data, &trace); err != nil {
+			t.Fatalf("%s: invalid trace: %v", path, err)
+		}
+
+		t.Run(fmt.Sprintf("%s %s", trace.Client, trace.Version), func(t *testing.T) {
+			replayTrace(t, trace)
+		})
+	}
+}
+
+func replayTrace(t *testing.T, trace interopTrace) {
+	client := startInMemory(t, newInteropServer())
+
+	for i, exchange := range trace.Exchanges {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := client.Send(ctx, exchange.Send); err != nil {
+			cancel()
+			t.Fatalf("exchange %d: Send failed: %v", i, err)
+		}
+		if len(exchange.Expect) == 0 {
+			cancel()
+			continue
+		}
+
+		msg, err := client.Receive(ctx)
+		cancel()
+		if err != nil {
+			t.Fatalf("exchange %d: no response to %s: %v", i, exchange.Send, err)
+		}
+
+		var got, want interface{}
+		if err := json.Unmarshal(msg, &got); err != nil {
+			t.Fatalf("exchange %d: invalid response %s: %v", i, msg, err)
+		}
+		if err := json.Unmarshal(exchange.Expect, &want); err != nil {
+			t.Fatalf("exchange %d: invalid expectation: %v", i, err)
+		}
+		if mismatch := matchSubset("$", want, got); mismatch != "" {
+			t.Errorf("exchange %d: %s\nrequest:  %s\nresponse: %s", i, mismatch, exchange.Send, msg)
+		}
+	}
+}
+
+// matchSubset reports the first place got lacks or differs from want, or "". Objects may
+// have extra keys; arrays must have the same length, matching element by element.
+func matchSubset(path string, want, got interface{}) string {
+	switch want := want.(type) {
+	case map[string]interface{}:
+		obj, ok := got.(map[string]interface{})
+		if !ok {
+			return fmt.Sprintf("%s: expected object, got %v", path, got)
+		}
+		keys := make([]string, 0, len(want))
+		for key := range want {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, ok := obj[key]
+			if !ok {
+				return fmt.Sprintf("%s.%s: missing", path, key)
+			}
+			if mismatch := matchSubset(path+"."+key, want[key], value); mismatch != "" {
+				return mismatch
+			}
+		}
+		return ""
+
+	case []interface{}:
+		arr, ok := got.([]interface{})
+		if !ok {
+			return fmt.Sprintf("%s: expected array, got %v", path, got)
+		}
+		if len(arr) != len(want) {
+			return fmt.Sprintf("%s: expected %d elements, got %d", path, len(want), len(arr))
+		}
+		for i := range want {
+			if mismatch := matchSubset(fmt.Sprintf("%s[%d]", path, i), want[i], arr[i]); mismatch != "" {
+				return mismatch
+			}
+		}
+		return ""
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		return fmt.Sprintf("%s: expected %v, got %v", path, want, got)
+	}
+	return ""
+}
+
+func TestMatchSubset(t *testing.T) {
+	got := map[string]interface{}{"a": 1.0, "b": []interface{}{"x", map[string]interface{}{"c": true, "d": "extra"}}}
+
+	if mismatch := matchSubset("$", map[string]interface{}{"b": []interface{}{"x", map[string]interface{}{"c": true}}}, got); mismatch != "" {
+		t.Errorf("expected subset to match, got %s", mismatch)
+	}
+	for _, want := range []interface{}{
+		map[string]interface{}{"a": 2.0},
+		map[string]interface{}{"missing": 1.0},
+		map[string]interface{}{"b": []interface{}{"x"}},
+	} {
+		if matchSubset("$", want, got) == "" {
+			t.Errorf("expected %v not to match", want)
+		}
+	}
+	if mismatch := matchSubset("$", map[string]interface{}{"b": []interface{}{"y"}}, got); !strings.HasPrefix(mismatch, "$.b") {
+		t.Errorf("expected mismatch path to start with $.b, got %q", mismatch)
+	}
+}
@@ -3,10 +3,13 @@ package mcp
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/mhpenta/minimcp/infer"
 	"github.com/mhpenta/minimcp/tools"
+	"github.com/mhpenta/minimcp/tools/mcpctx"
 )
 
 // JSON-RPC 2.0 message structures
@@ -23,7 +26,7 @@ type JSONRPCRequest struct {
 // JSONRPCResponse represents a JSON-RPC 2.0 response
 type JSONRPCResponse struct {
 	JSONRPC string      `json:"jsonrpc"`
-	ID      interface{} `json:"id,omitempty"`
+	ID      interface{} `json:"id"`
 	Result  interface{} `json:"result,omitempty"`
 	Error   *RPCError   `json:"error,omitempty"`
 }
@@ -42,6 +45,10 @@ type RPCError struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
 // Standard JSON-RPC error codes
 const (
 	ParseError     = -32700
@@ -56,8 +63,17 @@ const (
 	MethodInitialize = "initialize"
 	MethodToolsList  = "tools/list"
 	MethodToolsCall  = "tools/call"
+	MethodPing       = "ping"
+
+	MethodNotificationCancelled = "notifications/cancelled"
 )
 
+// CancelledNotificationParams represents parameters for notifications/cancelled
+type CancelledNotificationParams struct {
+	RequestID interface{} `json:"requestId"`
+	Reason    string      `json:"reason,omitempty"`
+}
+
 // InitializeParams represents MCP initialize request parameters
 type InitializeParams struct {
 	ProtocolVersion string                 `json:"protocolVersion"`
@@ -80,7 +96,10 @@ type InitializeResult struct {
 
 // ServerCapabilities describes what the server supports
 type ServerCapabilities struct {
-	Tools map[string]interface{} `json:"tools,omitempty"`
+	Tools        map[string]interface{} `json:"tools,omitempty"`
+	Resources    map[string]interface{} `json:"resources,omitempty"`
+	Prompts      map[string]interface{} `json:"prompts,omitempty"`
+	Experimental map[string]interface{} `json:"experimental,omitempty"`
 }
 
 // ServerInfo represents information about the MCP server
@@ -92,36 +111,110 @@ type ServerInfo struct {
 // ToolsListResult represents the response for tools/list
 type ToolsListResult struct {
 	Tools []ToolDescription `json:"tools"`
+
+	// NextCursor, when set, is sent back as the "cursor" param of tools/list to fetch the
+	// next page. This server lists every tool at once; servers it proxies may paginate.
+	NextCursor string `json:"nextCursor,omitempty"`
+
+	// Meta carries the registry revision the listing reflects, the base for MethodToolsDiff
+	Meta map[string]interface{} `json:"_meta,omitempty"`
 }
 
 // ToolDescription represents a tool in MCP format
 type ToolDescription struct {
-	Name        string                 `json:"name"`
-	Description string                 `json:"description"`
-	InputSchema map[string]interface{} `json:"inputSchema"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	InputSchema  map[string]interface{} `json:"inputSchema"`
+	OutputSchema map[string]interface{} `json:"outputSchema,omitempty"`
+	Annotations  *ToolAnnotations       `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations carries optional behavioral hints about a tool
+type ToolAnnotations struct {
+	DestructiveHint *bool `json:"destructiveHint,omitempty"`
 }
 
 // ToolsCallParams represents parameters for tools/call
 type ToolsCallParams struct {
 	Name      string          `json:"name"`
 	Arguments json.RawMessage `json:"arguments,omitempty"`
+	Meta      *RequestMeta    `json:"_meta,omitempty"`
+
+	// TimeoutMs asks for a timeout on this call, for clients that cannot set _meta.timeoutMs
+	TimeoutMs float64 `json:"timeoutMs,omitempty"`
+}
+
+// RequestMeta holds the _meta fields of a request that the server acts on
+type RequestMeta struct {
+	// ProgressToken asks for progress notifications tied to this request
+	ProgressToken interface{} `json:"progressToken,omitempty"`
+
+	// TimeoutMs asks for a timeout on a tools/call, in milliseconds, limited to
+	// ServerConfig.MaxToolTimeout
+	TimeoutMs float64 `json:"timeoutMs,omitempty"`
 }
 
 // ToolsCallResult represents the response for tools/call
 type ToolsCallResult struct {
 	Content []ContentBlock `json:"content"`
 	IsError bool           `json:"isError,omitempty"`
+
+	// Meta carries the error classification and system metadata of the result
+	Meta map[string]interface{} `json:"_meta,omitempty"`
 }
 
 // JSONRPCHandler handles JSON-RPC 2.0 messages for MCP protocol
 type JSONRPCHandler struct {
 	server *Server
+
+	// maxBatchSize is the most messages accepted in one batch; zero or less is unlimited
+	maxBatchSize int
+
+	// retryGrace is how long abandoned requests stay attachable; zero cancels them at once
+	retryGrace time.Duration
+
+	mu       sync.Mutex
+	inFlight map[string]*inFlightRequest
+
+	initResults map[string]cachedInitializeResult
+	initialized map[string]initializedSession
+
+	// subscriptions holds the resource URIs each session subscribed to
+	subscriptions map[string]map[string]bool
+}
+
+// inFlightRequest tracks a request that is currently being processed so it can be
+// cancelled, and so retries carrying the same ID can wait for its response
+type inFlightRequest struct {
+	cancel      context.CancelFunc
+	cancelled   bool
+	fingerprint string
+
+	// waiters counts callers still expecting the response; the execution is
+	// cancelled once the last one has been gone for the retry grace period
+	waiters     int
+	grace       *time.Timer
+	stopWatcher func() bool
+	done        chan struct{}
+	response    *JSONRPCResponse
 }
 
+// DefaultRetryGracePeriod is how long a request keeps running, and its response is kept,
+// after every caller waiting on it has gone away, so a client retrying over a new
+// connection can still attach to it
+const DefaultRetryGracePeriod = 10 * time.Second
+
 // NewJSONRPCHandler creates a new JSON-RPC handler
 func NewJSONRPCHandler(server *Server) *JSONRPCHandler {
 	return &JSONRPCHandler{
-		server: server,
+		server:       server,
+		maxBatchSize: DefaultMaxBatchSize,
+		retryGrace:   DefaultRetryGracePeriod,
+		inFlight:     make(map[string]*inFlightRequest),
+		initResults:  make(map[string]cachedInitializeResult),
+		initialized:  make(map[string]initializedSession),
+
+		subscriptions: make(map[string]map[string]bool),
 	}
 }
 
@@ -131,6 +224,16 @@ func (h *JSONRPCHandler) HandleMessage(ctx context.Context, data []byte) (*JSONR
 	// First, try to parse as a request (has ID)
 	var req JSONRPCRequest
 	if err := json.Unmarshal(data, &req); err != nil {
+		// Valid JSON that is not a request object, such as a number in a batch
+		if json.Valid(data) {
+			return &JSONRPCResponse{
+				JSONRPC: "2.0",
+				Error: &RPCError{
+					Code:    InvalidRequest,
+					Message: "Invalid Request",
+				},
+			}, nil
+		}
 		return &JSONRPCResponse{
 			JSONRPC: "2.0",
 			Error: &RPCError{
@@ -144,7 +247,7 @@ func (h *JSONRPCHandler) HandleMessage(ctx context.Context, data []byte) (*JSONR
 	// Check if it's a notification (no ID field)
 	if req.ID == nil {
 		// It's a notification, no response needed
-		h.server.logger.Info("received notification", "method", req.Method)
+		h.handleNotification(ctx, req)
 		return nil, nil
 	}
 
@@ -160,10 +263,34 @@ func (h *JSONRPCHandler) HandleMessage(ctx context.Context, data []byte) (*JSONR
 		}, nil
 	}
 
+	ctx, entry, duplicate := h.trackRequest(ctx, req)
+	if duplicate {
+		return h.awaitDuplicate(ctx, req, entry), nil
+	}
+
+	response := h.dispatch(ctx, req, entry)
+	h.finishRequest(ctx, req.ID, entry, response)
+	return response, nil
+}
+
+// dispatch routes a request to its method handler and builds the response.
+// It returns nil if the client cancelled the request.
+func (h *JSONRPCHandler) dispatch(ctx context.Context, req JSONRPCRequest, entry *inFlightRequest) *JSONRPCResponse {
 	// Route to appropriate method handler
 	var result interface{}
 	var rpcErr *RPCError
 
+	if h.server.metrics != nil {
+		done := h.server.metrics.StartRequest(metricsMethod(req.Method))
+		defer func() { done(rpcErr != nil || isErrorResult(result)) }()
+	}
+
+	ctx = withRequestID(ctx, req.ID)
+	ctx = h.server.withCorrelation(ctx, req.Params)
+	ctx = h.withRequestContext(ctx, req)
+	ctx, span := h.server.startRequestSpan(ctx, req)
+	defer func() { endRequestSpan(span, result, rpcErr) }()
+
 	switch req.Method {
 	case MethodInitialize:
 		result, rpcErr = h.handleInitialize(ctx, req.Params)
@@ -171,6 +298,29 @@ func (h *JSONRPCHandler) HandleMessage(ctx context.Context, data []byte) (*JSONR
 		result, rpcErr = h.handleToolsList(ctx, req.Params)
 	case MethodToolsCall:
 		result, rpcErr = h.handleToolsCall(ctx, req.Params)
+	case MethodToolsDiff:
+		result, rpcErr = h.handleToolsDiff(ctx, req.Params)
+	case MethodValidate:
+		result, rpcErr = h.handleValidate(ctx, req.Params)
+	case MethodAuditReplay:
+		result, rpcErr = h.handleAuditReplay(ctx, req.Params)
+	case MethodResourcesList:
+		result, rpcErr = h.handleResourcesList(ctx, req.Params)
+	case MethodResourcesRead:
+		result, rpcErr = h.handleResourcesRead(ctx, req.Params)
+	case MethodResourcesTemplatesList:
+		result, rpcErr = h.handleResourcesTemplatesList(ctx, req.Params)
+	case MethodResourcesSubscribe:
+		result, rpcErr = h.handleResourcesSubscribe(ctx, req.Params, true)
+	case MethodResourcesUnsubscribe:
+		result, rpcErr = h.handleResourcesSubscribe(ctx, req.Params, false)
+	case MethodPromptsList:
+		result, rpcErr = h.handlePromptsList(ctx, req.Params)
+	case MethodPromptsGet:
+		result, rpcErr = h.handlePromptsGet(ctx, req.Params)
+	case MethodPing:
+		// Either side may ping at any time, even before initialize; the reply is empty
+		result = struct{}{}
 	default:
 		rpcErr = &RPCError{
 			Code:    MethodNotFound,
@@ -178,15 +328,205 @@ func (h *JSONRPCHandler) HandleMessage(ctx context.Context, data []byte) (*JSONR
 		}
 	}
 
+	// Requests cancelled by the client must not receive a response
+	if h.wasCancelled(entry) {
+		h.server.logger.Info("request cancelled, dropping response", "id", req.ID, "method", req.Method)
+		return nil
+	}
+
 	return &JSONRPCResponse{
 		JSONRPC: "2.0",
 		ID:      req.ID,
-		Result:  result,
+		Result:  withRequestIDMeta(ctx, result),
 		Error:   rpcErr,
-	}, nil
+	}
+}
+
+// withRequestContext exposes what is known about the request to tools through mcpctx
+func (h *JSONRPCHandler) withRequestContext(ctx context.Context, req JSONRPCRequest) context.Context {
+	if client := h.clientInfo(ctx); client.Name != "" {
+		ctx = mcpctx.WithClientInfo(ctx, mcpctx.Client{Name: client.Name, Version: client.Version})
+	}
+	logger := h.server.logger.With(
+		"method", req.Method,
+		"id", req.ID,
+		"session", sessionFrom(ctx))
+	if id := mcpctx.RequestID(ctx); id != "" {
+		logger = logger.With("request_id", id)
+	}
+	return mcpctx.WithLogger(ctx, logger)
+}
+
+// handleNotification processes a notification; unknown notifications are logged and ignored
+func (h *JSONRPCHandler) handleNotification(ctx context.Context, req JSONRPCRequest) {
+	h.server.logger.Info("received notification", "method", req.Method)
+
+	if req.Method != MethodNotificationCancelled {
+		return
+	}
+
+	var params CancelledNotificationParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.RequestID == nil {
+		h.server.logger.Warn("invalid cancellation notification", "params", string(req.Params))
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if inFlight, ok := h.inFlight[requestKey(ctx, params.RequestID)]; ok {
+		inFlight.cancelled = true
+		inFlight.cancel()
+		h.server.logger.Info("cancelling request", "id", params.RequestID, "reason", params.Reason)
+	}
+}
+
+// trackRequest registers an in-flight request and returns the context to execute it with.
+// If a request with the same ID is already running in this session, its entry is returned
+// with duplicate set instead, and the caller must wait for it via awaitDuplicate.
+//
+// The execution context is detached from the caller's cancellation: it is cancelled when the
+// client sends notifications/cancelled, or once every caller waiting on it (the original and
+// any retries) has been gone for the retry grace period. A retry over a flaky connection can
+// thus pick up the result of an execution whose original caller disconnected.
+func (h *JSONRPCHandler) trackRequest(ctx context.Context, req JSONRPCRequest) (context.Context, *inFlightRequest, bool) {
+	key := requestKey(ctx, req.ID)
+	fingerprint := requestFingerprint(req)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if existing, ok := h.inFlight[key]; ok {
+		if existing.fingerprint == fingerprint {
+			existing.waiters++
+			if existing.grace != nil {
+				existing.grace.Stop()
+				existing.grace = nil
+			}
+		}
+		return ctx, existing, true
+	}
+
+	execCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	entry := &inFlightRequest{
+		cancel:      cancel,
+		fingerprint: fingerprint,
+		waiters:     1,
+		done:        make(chan struct{}),
+	}
+	h.inFlight[key] = entry
+
+	entry.stopWatcher = context.AfterFunc(ctx, func() { h.release(entry) })
+	return execCtx, entry, false
+}
+
+// awaitDuplicate attaches a retried request to the in-flight request with the same ID.
+// A request reusing an in-flight ID with different content is rejected.
+func (h *JSONRPCHandler) awaitDuplicate(ctx context.Context, req JSONRPCRequest, entry *inFlightRequest) *JSONRPCResponse {
+	if entry.fingerprint != requestFingerprint(req) {
+		return &JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Error: &RPCError{
+				Code:    InvalidRequest,
+				Message: fmt.Sprintf("Request ID %v is already in use by an in-flight request", req.ID),
+			},
+		}
+	}
+
+	h.server.logger.Info("attaching duplicate request to in-flight execution", "id", req.ID, "method", req.Method)
+
+	select {
+	case <-entry.done:
+		return entry.response
+	case <-ctx.Done():
+		h.release(entry)
+		return nil
+	}
+}
+
+// finishRequest publishes the response to waiting retries and removes the in-flight entry.
+// A response nobody was left to receive is kept for the retry grace period.
+func (h *JSONRPCHandler) finishRequest(ctx context.Context, id interface{}, entry *inFlightRequest, response *JSONRPCResponse) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entry.stopWatcher()
+	if entry.grace != nil {
+		entry.grace.Stop()
+		entry.grace = nil
+	}
+	entry.response = response
+	close(entry.done)
+	entry.cancel()
+
+	key := requestKey(ctx, id)
+	if h.inFlight[key] != entry {
+		return
+	}
+	if entry.waiters > 0 || response == nil || h.retryGrace <= 0 {
+		delete(h.inFlight, key)
+		return
+	}
+	time.AfterFunc(h.retryGrace, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.inFlight[key] == entry {
+			delete(h.inFlight, key)
+		}
+	})
 }
 
-// handleInitialize processes the initialize request
+// release drops one waiter. When nobody is left to receive the response, the execution is
+// cancelled after the retry grace period unless a retry attaches first.
+func (h *JSONRPCHandler) release(entry *inFlightRequest) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entry.waiters--
+	if entry.waiters > 0 {
+		return
+	}
+	if h.retryGrace <= 0 {
+		entry.cancel()
+		return
+	}
+	if entry.grace == nil {
+		entry.grace = time.AfterFunc(h.retryGrace, func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if entry.waiters <= 0 {
+				entry.cancel()
+			}
+		})
+	}
+}
+
+// wasCancelled reports whether the client cancelled the in-flight request
+func (h *JSONRPCHandler) wasCancelled(entry *inFlightRequest) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return entry.cancelled
+}
+
+// requestFingerprint identifies a request's content, so a retry can be told apart from an ID reused for a different call
+func requestFingerprint(req JSONRPCRequest) string {
+	return req.Method + "\x00" + string(req.Params)
+}
+
+// requestKey normalizes a JSON-RPC ID (string or number) into a map key scoped to the
+// caller's principal and session, so clients cannot collide with or cancel each other's
+// requests
+func requestKey(ctx context.Context, id interface{}) string {
+	subject := ""
+	if principal := PrincipalFrom(ctx); principal != nil {
+		subject = principal.Subject
+	}
+	return fmt.Sprintf("%q/%s/%T:%v", subject, sessionFrom(ctx), id, id)
+}
+
+// handleInitialize processes the initialize request. A session that persists across
+// requests, a stdio or in-memory connection or an issued HTTP session, may initialize
+// once; an HTTP initialize without a session header is issued a new session instead.
 func (h *JSONRPCHandler) handleInitialize(ctx context.Context, params json.RawMessage) (interface{}, *RPCError) {
 	var initParams InitializeParams
 	if params != nil {
@@ -199,70 +539,83 @@ func (h *JSONRPCHandler) handleInitialize(ctx context.Context, params json.RawMe
 		}
 	}
 
+	if hasStatefulSession(ctx) && !h.markInitialized(sessionFrom(ctx), initParams.ClientInfo) {
+		return nil, &RPCError{
+			Code:    InvalidRequest,
+			Message: "Session already initialized",
+		}
+	}
+
+	version := negotiateProtocolVersion(initParams.ProtocolVersion)
 	h.server.logger.Info("MCP client connected",
 		"client", initParams.ClientInfo.Name,
-		"version", initParams.ClientInfo.Version)
+		"version", initParams.ClientInfo.Version,
+		"protocol_version", version)
 
-	return InitializeResult{
-		ProtocolVersion: "2024-11-05", // MCP protocol version
-		Capabilities: ServerCapabilities{
-			Tools: map[string]interface{}{
-				"listChanged": true,
-			},
-		},
-		ServerInfo: ServerInfo{
-			Name:    h.server.name,
-			Version: h.server.version,
-		},
-	}, nil
+	return h.initializeResult(version), nil
 }
 
 // handleToolsList processes the tools/list request
 func (h *JSONRPCHandler) handleToolsList(ctx context.Context, params json.RawMessage) (interface{}, *RPCError) {
-	toolList := make([]ToolDescription, 0, len(h.server.tools))
-	for _, tool := range h.server.tools {
-		spec := tool.Spec()
-
-		// Normalize the input schema to ensure "required" is always an array, not null
-		// This is required by JSON Schema spec and some MCP clients reject null values
-		inputSchema := normalizeJSONSchema(spec.Parameters)
-
-		toolList = append(toolList, ToolDescription{
-			Name:        spec.Name,
-			Description: spec.Description,
-			InputSchema: inputSchema,
-		})
-	}
+	return h.server.listTools(ctx), nil
+}
 
+// listTools lists the tools the caller in ctx may use, ordered for the client that
+// initialized the session, with the registry revision the listing reflects. tools/list on
+// every transport, the REST listing, and ListTools use it, so clients see identical metadata.
+func (s *Server) listTools(ctx context.Context) ToolsListResult {
+	descriptions, revision := s.toolDescriptions(ctx, mcpctx.ClientInfo(ctx).Name)
 	return ToolsListResult{
-		Tools: toolList,
-	}, nil
+		Tools: descriptions,
+		Meta:  map[string]interface{}{ToolsRevisionMetaKey: revision},
+	}
 }
 
-// normalizeJSONSchema ensures the schema conforms to JSON Schema spec
-// Specifically, it ensures "required" is an empty array instead of null
-func normalizeJSONSchema(schema map[string]interface{}) map[string]interface{} {
-	if schema == nil {
-		return schema
+// toolDescriptions builds the MCP descriptions of the tools the caller in ctx may use,
+// in ranked order, along with the registry revision they reflect
+func (s *Server) toolDescriptions(ctx context.Context, client string) ([]ToolDescription, uint64) {
+	s.toolsMu.RLock()
+	registered, revision := s.tools, s.registry.revision
+	s.toolsMu.RUnlock()
+	registered = s.rankTools(registered, client)
+
+	toolList := make([]ToolDescription, 0, len(registered))
+	for _, tool := range registered {
+		if !canUse(ctx, tool.Spec()) {
+			continue
+		}
+		toolList = append(toolList, s.describeTool(tool.Spec()))
 	}
+	return toolList, revision
+}
 
-	// Marshal and unmarshal to get a deep copy, then fix the required field
-	data, err := json.Marshal(schema)
-	if err != nil {
-		return schema
+// describeTool converts a tool spec into its MCP description
+func (s *Server) describeTool(spec *tools.ToolSpec) ToolDescription {
+	desc := ToolDescription{
+		Name:        spec.Name,
+		Description: s.budgetDescription(spec),
+		InputSchema: s.wireSchema(spec.Parameters),
 	}
 
-	var normalized map[string]interface{}
-	if err := json.Unmarshal(data, &normalized); err != nil {
-		return schema
+	// MCP only allows object output schemas; tools returning scalars are described by text content alone
+	if spec.Output != nil && spec.Output["type"] == "object" {
+		desc.OutputSchema = s.wireSchema(spec.Output)
 	}
 
-	// Fix the "required" field if it's null or doesn't exist
-	if required, exists := normalized["required"]; !exists || required == nil {
-		normalized["required"] = []string{}
+	if spec.Destructive {
+		destructive := true
+		desc.Annotations = &ToolAnnotations{DestructiveHint: &destructive}
 	}
 
-	return normalized
+	return desc
+}
+
+// wireSchema returns the schema as sent to clients, minified when the server is configured to
+func (s *Server) wireSchema(schema map[string]interface{}) map[string]interface{} {
+	if s.minifySchemas {
+		return infer.Minify(schema)
+	}
+	return schema
 }
 
 // handleToolsCall processes the tools/call request
@@ -276,85 +629,9 @@ func (h *JSONRPCHandler) handleToolsCall(ctx context.Context, params json.RawMes
 		}
 	}
 
-	h.server.logger.Info("executing tool via JSON-RPC", "tool", callParams.Name)
-
-	// Find the tool
-	var targetTool tools.Tool
-	for _, tool := range h.server.tools {
-		if tool.Spec().Name == callParams.Name {
-			targetTool = tool
-			break
-		}
+	result, rpcErr := h.server.handleToolsCall(ctx, callParams)
+	if rpcErr != nil {
+		return nil, rpcErr
 	}
-
-	if targetTool == nil {
-		return nil, &RPCError{
-			Code:    InvalidParams,
-			Message: fmt.Sprintf("Tool not found: %s", callParams.Name),
-		}
-	}
-
-	// Execute the tool
-	result, err := targetTool.Execute(ctx, callParams.Arguments)
-	if err != nil {
-		// Check if it's a specific tool error
-		var toolErr *tools.Error
-		if errors.As(err, &toolErr) {
-			// If the error code is within the reserved JSON-RPC error range (-32768 to -32000),
-			// we treat it as a protocol-level error and return it directly.
-			// This allows tools to return InvalidParams, InternalError, or other standard codes.
-			if toolErr.Code >= -32768 && toolErr.Code <= -32000 {
-				return nil, &RPCError{
-					Code:    toolErr.Code,
-					Message: toolErr.Message,
-					Data:    toolErr.Data,
-				}
-			}
-		}
-
-		h.server.logger.Error("MCP JSON-RPC tool execution failed",
-			"tool", callParams.Name,
-			"error", err.Error(),
-			"errorType", fmt.Sprintf("%T", err),
-			"arguments", string(callParams.Arguments),
-			"context", "mcp_jsonrpc_handler")
-
-		return ToolsCallResult{
-			Content: []ContentBlock{
-				{
-					Type: "text",
-					Text: fmt.Sprintf("Error executing tool: %v", err),
-				},
-			},
-			IsError: true,
-		}, nil
-	}
-
-	// Convert tool result to MCP response format
-	var text string
-	if result.Error != nil {
-		text = *result.Error
-	} else if result.Output != nil {
-		text = tools.MarshalOutput(h.server.logger, result.Output)
-	} else if result.System != nil {
-		text = *result.System
-	} else {
-		// Fallback to JSON marshaling the entire result
-		resultBytes, err := json.Marshal(result)
-		if err != nil {
-			text = "Error serializing result"
-		} else {
-			text = string(resultBytes)
-		}
-	}
-
-	return ToolsCallResult{
-		Content: []ContentBlock{
-			{
-				Type: "text",
-				Text: text,
-			},
-		},
-		IsError: false,
-	}, nil
+	return *result, nil
 }
@@ -0,0 +1,180 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func newBlockingServer(t *testing.T) (*Server, chan struct{}, chan struct{}, *int32) {
+	t.Helper()
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var executions int32
+
+	tool := &mockTool{
+		name:        "slow",
+		description: "Waits to be released",
+		parameters:  map[string]interface{}{"type": "object"},
+		executeFn: func(ctx context.Context, params json.RawMessage) (*tools.ToolResult, error) {
+			atomic.AddInt32(&executions, 1)
+			started <- struct{}{}
+			select {
+			case <-release:
+				return &tools.ToolResult{Output: "done"}, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		},
+	}
+
+	server := NewServer(ServerConfig{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Tools:   []tools.Tool{tool},
+	})
+	return server, started, release, &executions
+}
+
+func TestJSONRPCHandler_DuplicateRequestAttaches(t *testing.T) {
+	server, started, release, executions := newBlockingServer(t)
+	handler := NewJSONRPCHandler(server)
+	msg := []byte(`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"slow","arguments":{}}}`)
+
+	// The original caller disconnects once its retry is attached
+	originalCtx, disconnect := context.WithCancel(context.Background())
+	responses := make(chan *JSONRPCResponse, 2)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		resp, _ := handler.HandleMessage(originalCtx, msg)
+		responses <- resp
+	}()
+	<-started
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		resp, _ := handler.HandleMessage(context.Background(), msg)
+		responses <- resp
+	}()
+
+	// Wait for the retry to attach before the original goes away
+	deadline := time.Now().Add(time.Second)
+	for {
+		handler.mu.Lock()
+		waiters := 0
+		for _, entry := range handler.inFlight {
+			waiters = entry.waiters
+		}
+		handler.mu.Unlock()
+		if waiters == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	disconnect()
+	close(release)
+	wg.Wait()
+	close(responses)
+
+	for resp := range responses {
+		if resp == nil || resp.Error != nil {
+			t.Fatalf("expected a successful response for both callers, got %+v", resp)
+		}
+	}
+	if n := atomic.LoadInt32(executions); n != 1 {
+		t.Errorf("expected the tool to execute once, got %d", n)
+	}
+}
+
+func TestJSONRPCHandler_ReusedIDWithDifferentParams(t *testing.T) {
+	server, started, release, _ := newBlockingServer(t)
+	defer close(release)
+	handler := NewJSONRPCHandler(server)
+
+	go handler.HandleMessage(context.Background(),
+		[]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow","arguments":{}}}`))
+	<-started
+
+	resp, err := handler.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	if err != nil {
+		t.Fatalf("HandleMessage failed: %v", err)
+	}
+	if resp.Error == nil || resp.Error.Code != InvalidRequest {
+		t.Errorf("expected InvalidRequest for reused ID, got %+v", resp)
+	}
+
+	// The same ID in another session is independent
+	other := withSession(context.Background(), "other")
+	resp, _ = handler.HandleMessage(other, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	if resp.Error != nil {
+		t.Errorf("expected other session to be unaffected, got %+v", resp.Error)
+	}
+}
+
+func TestJSONRPCHandler_NextRequestIDSkipsClientIDs(t *testing.T) {
+	server, started, release, _ := newBlockingServer(t)
+	defer close(release)
+	server.idGenerator = NewMonotonicIDGenerator("")
+	handler := NewJSONRPCHandler(server)
+
+	// The client has request 1 in flight
+	go handler.HandleMessage(context.Background(),
+		[]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow","arguments":{}}}`))
+	<-started
+
+	id, err := handler.NextRequestID(context.Background())
+	if err != nil {
+		t.Fatalf("NextRequestID failed: %v", err)
+	}
+	if id != int64(2) {
+		t.Errorf("expected colliding ID 1 to be skipped, got %v", id)
+	}
+
+	// Counters are per session
+	id, _ = handler.NextRequestID(withSession(context.Background(), "other"))
+	if id != int64(1) {
+		t.Errorf("expected a fresh counter for another session, got %v", id)
+	}
+}
+
+func TestUUIDGenerator(t *testing.T) {
+	a, b := UUIDGenerator{}.NextID(""), UUIDGenerator{}.NextID("")
+	if a == b || len(a.(string)) != 36 {
+		t.Errorf("expected distinct UUIDs, got %v and %v", a, b)
+	}
+}
+
+func TestJSONRPCHandler_MinifySchemas(t *testing.T) {
+	tool := &mockTool{
+		name:        "titled",
+		description: "Has a verbose schema",
+		parameters: map[string]interface{}{
+			"type":       "object",
+			"title":      "TitledInput",
+			"properties": map[string]interface{}{"q": map[string]interface{}{"type": "string", "title": "Q"}},
+		},
+	}
+	list := func(minify bool) string {
+		server := NewServer(ServerConfig{Name: "test-server", Tools: []tools.Tool{tool}, MinifySchemas: minify})
+		resp, _ := NewJSONRPCHandler(server).HandleMessage(context.Background(),
+			[]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		data, _ := json.Marshal(resp.Result)
+		return string(data)
+	}
+
+	if !strings.Contains(list(false), `"title"`) {
+		t.Error("expected titles without minification")
+	}
+	if strings.Contains(list(true), `"title"`) {
+		t.Errorf("expected titles to be dropped, got %s", list(true))
+	}
+}
@@ -0,0 +1,390 @@
+package mcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultJWKSCacheTTL = time.Hour
+	defaultScopeClaim   = "scope"
+
+	// minJWKSRefetchInterval stops tokens with unknown key IDs from forcing a fetch per request
+	minJWKSRefetchInterval = time.Minute
+)
+
+// JWTValidatorConfig configures a JWTValidator
+type JWTValidatorConfig struct {
+	// JWKSURL is the identity provider's JSON Web Key Set endpoint. Required.
+	JWKSURL string
+
+	// Issuer, when set, must match the token's "iss" claim
+	Issuer string
+
+	// Audience, when set, must appear in the token's "aud" claim
+	Audience string
+
+	// Leeway allows for clock skew when checking "exp" and "nbf"
+	Leeway time.Duration
+
+	// CacheTTL controls how long fetched keys are reused (default 1h).
+	// Keys are also refetched when a token references an unknown key ID.
+	CacheTTL time.Duration
+
+	// ScopeClaim names the claim holding granted scopes (default "scope").
+	// Both space-separated strings and string arrays are accepted.
+	ScopeClaim string
+
+	// HTTPClient is used to fetch the key set. Defaults to a client with a 10s timeout.
+	HTTPClient *http.Client
+}
+
+// JWTValidator authenticates RS256 and ES256 signed JWT bearer tokens against a JWKS endpoint.
+// It implements both APIKeyValidator and Authenticator, so tools can read the token's
+// subject, scopes, and claims via PrincipalFrom.
+type JWTValidator struct {
+	cfg JWTValidatorConfig
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	fetching  *jwksFetch // the fetch in flight, if any
+	now       func() time.Time
+}
+
+// jwksFetch is a key set download shared by every caller that needs it
+type jwksFetch struct {
+	done chan struct{} // closed when the fetch ends
+	err  error
+}
+
+// NewJWTValidator creates a JWT validator for the given configuration
+func NewJWTValidator(cfg JWTValidatorConfig) *JWTValidator {
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = defaultJWKSCacheTTL
+	}
+	if cfg.ScopeClaim == "" {
+		cfg.ScopeClaim = defaultScopeClaim
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &JWTValidator{
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+// Validate reports whether the token is a valid, unexpired JWT
+func (v *JWTValidator) Validate(ctx context.Context, token string) bool {
+	_, err := v.Authenticate(ctx, token)
+	return err == nil
+}
+
+// Authenticate verifies the token's signature and claims and returns the caller's principal
+func (v *JWTValidator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed JWT", ErrUnauthenticated)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: invalid JWT header: %v", ErrUnauthenticated, err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid JWT signature encoding", ErrUnauthenticated)
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := verifyJWTSignature(header.Alg, key, digest[:], signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: invalid JWT claims: %v", ErrUnauthenticated, err)
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return nil, err
+	}
+
+	subject, _ := claims["sub"].(string)
+	return &Principal{
+		Subject: subject,
+		Scopes:  scopesFromClaim(claims[v.cfg.ScopeClaim]),
+		Claims:  claims,
+	}, nil
+}
+
+// checkClaims validates the registered time, issuer, and audience claims
+func (v *JWTValidator) checkClaims(claims map[string]interface{}) error {
+	now := v.now()
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("%w: token has no expiry", ErrUnauthenticated)
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(v.cfg.Leeway)) {
+		return fmt.Errorf("%w: token expired", ErrUnauthenticated)
+	}
+
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(v.cfg.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("%w: token not yet valid", ErrUnauthenticated)
+	}
+
+	if v.cfg.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.cfg.Issuer {
+			return fmt.Errorf("%w: unexpected issuer %q", ErrUnauthenticated, iss)
+		}
+	}
+
+	if v.cfg.Audience != "" && !audienceContains(claims["aud"], v.cfg.Audience) {
+		return fmt.Errorf("%w: token not issued for audience %q", ErrUnauthenticated, v.cfg.Audience)
+	}
+
+	return nil
+}
+
+// key returns the public key with the given ID, refreshing the key set when it is stale or
+// the ID is unknown. The download runs without holding the lock, so cached keys stay
+// available, and concurrent callers share one download.
+func (v *JWTValidator) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	fresh := v.keys != nil && v.now().Sub(v.fetchedAt) < v.cfg.CacheTTL
+	if key, ok := v.keys[kid]; ok && fresh {
+		v.mu.Unlock()
+		return key, nil
+	}
+	if fresh && v.now().Sub(v.fetchedAt) < minJWKSRefetchInterval {
+		v.mu.Unlock()
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrUnauthenticated, kid)
+	}
+	fetch := v.fetching
+	if fetch == nil {
+		fetch = &jwksFetch{done: make(chan struct{})}
+		v.fetching = fetch
+		// Callers share the download, so one giving up must not cancel it for the others;
+		// the HTTP client's timeout bounds it instead
+		go v.refresh(context.WithoutCancel(ctx), fetch)
+	}
+	v.mu.Unlock()
+
+	select {
+	case <-fetch.done:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, ctx.Err())
+	}
+	if fetch.err != nil {
+		// A failed download says nothing about the token, so it is not a rejection
+		return nil, fmt.Errorf("fetching signing keys: %w", fetch.err)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	key, ok := v.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrUnauthenticated, kid)
+	}
+	return key, nil
+}
+
+// refresh downloads the key set for fetch, taking the lock only to swap it in
+func (v *JWTValidator) refresh(ctx context.Context, fetch *jwksFetch) {
+	keys, err := fetchJWKS(ctx, v.cfg.HTTPClient, v.cfg.JWKSURL)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if err == nil {
+		v.keys = keys
+		v.fetchedAt = v.now()
+	}
+	fetch.err = err
+	v.fetching = nil
+	close(fetch.done)
+}
+
+// jsonWebKey is the subset of RFC 7517 fields needed for RSA and EC signature keys
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetchJWKS downloads and parses a JSON Web Key Set; unsupported keys are skipped
+func fetchJWKS(ctx context.Context, client *http.Client, url string) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating JWKS request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("decoding JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+// publicKey converts the JWK into an RSA or P-256 ECDSA public key
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("RSA exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// verifyJWTSignature checks the signature over digest for the RS256 and ES256 algorithms
+func verifyJWTSignature(alg string, key crypto.PublicKey, digest, signature []byte) error {
+	switch alg {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: key type does not match RS256", ErrUnauthenticated)
+		}
+		if err := rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest, signature); err != nil {
+			return fmt.Errorf("%w: invalid signature", ErrUnauthenticated)
+		}
+		return nil
+
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: key type does not match ES256", ErrUnauthenticated)
+		}
+		if len(signature) != 64 {
+			return fmt.Errorf("%w: invalid signature length", ErrUnauthenticated)
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return fmt.Errorf("%w: invalid signature", ErrUnauthenticated)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: unsupported algorithm %q", ErrUnauthenticated, alg)
+}
+
+func decodeJWTSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// audienceContains checks a string or string-array "aud" claim for the expected audience
+func audienceContains(aud interface{}, expected string) bool {
+	switch a := aud.(type) {
+	case string:
+		return a == expected
+	case []interface{}:
+		for _, item := range a {
+			if s, ok := item.(string); ok && s == expected {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scopesFromClaim accepts both space-separated scope strings and string arrays
+func scopesFromClaim(claim interface{}) []string {
+	switch c := claim.(type) {
+	case string:
+		return strings.Fields(c)
+	case []interface{}:
+		scopes := make([]string, 0, len(c))
+		for _, item := range c {
+			if s, ok := item.(string); ok {
+				scopes = append(scopes, s)
+			}
+		}
+		return scopes
+	}
+	return nil
+}
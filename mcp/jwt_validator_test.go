@@ -0,0 +1,251 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+type jwtTestKeys struct {
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+func newJWTTestKeys(t *testing.T) (*jwtTestKeys, *httptest.Server) {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating EC key: %v", err)
+	}
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jwks)
+	}))
+	t.Cleanup(srv.Close)
+
+	return &jwtTestKeys{rsa: rsaKey, ec: ecKey}, srv
+}
+
+func (k *jwtTestKeys) sign(t *testing.T, alg, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var sig []byte
+	switch alg {
+	case "RS256":
+		var err error
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k.rsa, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("signing: %v", err)
+		}
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, k.ec, digest[:])
+		if err != nil {
+			t.Fatalf("signing: %v", err)
+		}
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":   "user-42",
+		"iss":   "https://issuer.example",
+		"aud":   []string{"minimcp"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "tools:read tools:call",
+	}
+}
+
+func TestJWTValidator_Authenticate(t *testing.T) {
+	keys, jwksServer := newJWTTestKeys(t)
+	validator := NewJWTValidator(JWTValidatorConfig{
+		JWKSURL:  jwksServer.URL,
+		Issuer:   "https://issuer.example",
+		Audience: "minimcp",
+	})
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	wrongAudience := validClaims()
+	wrongAudience["aud"] = "someone-else"
+	wrongIssuer := validClaims()
+	wrongIssuer["iss"] = "https://evil.example"
+
+	tampered := keys.sign(t, "RS256", "rsa-1", validClaims())
+	tampered = tampered[:len(tampered)-4] + "AAAA"
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"valid RS256", keys.sign(t, "RS256", "rsa-1", validClaims()), false},
+		{"valid ES256", keys.sign(t, "ES256", "ec-1", validClaims()), false},
+		{"expired", keys.sign(t, "RS256", "rsa-1", expired), true},
+		{"wrong audience", keys.sign(t, "RS256", "rsa-1", wrongAudience), true},
+		{"wrong issuer", keys.sign(t, "ES256", "ec-1", wrongIssuer), true},
+		{"unknown kid", keys.sign(t, "RS256", "rsa-2", validClaims()), true},
+		{"algorithm mismatch", keys.sign(t, "ES256", "rsa-1", validClaims()), true},
+		{"tampered signature", tampered, true},
+		{"malformed", "not-a-jwt", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := validator.Authenticate(context.Background(), tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnauthenticated) {
+					t.Errorf("expected ErrUnauthenticated, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate failed: %v", err)
+			}
+			if principal.Subject != "user-42" {
+				t.Errorf("expected subject user-42, got %q", principal.Subject)
+			}
+			if len(principal.Scopes) != 2 || principal.Scopes[1] != "tools:call" {
+				t.Errorf("unexpected scopes: %v", principal.Scopes)
+			}
+		})
+	}
+}
+
+func TestJWTValidator_SlowKeyRefresh(t *testing.T) {
+	keys, jwksServer := newJWTTestKeys(t)
+	var requests atomic.Int32
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) > 1 {
+			<-release
+		}
+		resp, err := http.Get(jwksServer.URL)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		io.Copy(w, resp.Body)
+	}))
+	t.Cleanup(slow.Close)
+	defer close(release)
+
+	validator := NewJWTValidator(JWTValidatorConfig{JWKSURL: slow.URL, Issuer: "https://issuer.example", Audience: "minimcp"})
+	now := time.Now()
+	validator.now = func() time.Time { return now }
+	cached := keys.sign(t, "RS256", "rsa-1", validClaims())
+	rotated := keys.sign(t, "RS256", "rsa-2", validClaims())
+	if _, err := validator.Authenticate(context.Background(), cached); err != nil {
+		t.Fatal(err)
+	}
+
+	// An unknown key triggers a refresh, which hangs
+	now = now.Add(2 * minJWKSRefetchInterval)
+	unknown := make(chan error, 1)
+	go func() {
+		_, err := validator.Authenticate(context.Background(), rotated)
+		unknown <- err
+	}()
+	for deadline := time.Now().Add(5 * time.Second); requests.Load() < 2; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the key set to be fetched again")
+		}
+	}
+
+	// Cached keys are still served, and callers waiting on the refresh can give up
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := validator.Authenticate(ctx, cached); err != nil {
+		t.Errorf("expected the cached key to validate during the refresh, got %v", err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := validator.Authenticate(ctx, rotated); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated when the context expires, got %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("expected callers to share one refresh, got %d requests", n)
+	}
+
+	release <- struct{}{}
+	if err := <-unknown; !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("expected the unknown key to be rejected after the refresh, got %v", err)
+	}
+}
+
+func TestJWTValidator_PrincipalInToolContext(t *testing.T) {
+	keys, jwksServer := newJWTTestKeys(t)
+	validator := NewJWTValidator(JWTValidatorConfig{JWKSURL: jwksServer.URL, Audience: "minimcp"})
+
+	whoami := tools.NewTool("whoami", "Returns the caller", func(ctx context.Context, in struct{}) (string, error) {
+		if p := PrincipalFrom(ctx); p != nil {
+			return p.Subject, nil
+		}
+		return "anonymous", nil
+	})
+	server := NewServer(ServerConfig{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Tools:   []tools.Tool{whoami},
+	})
+	transport := NewHTTPTransport(server, slog.Default(), validator)
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"whoami","arguments":{}}}`
+	req := httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+keys.sign(t, "RS256", "rsa-1", validClaims()))
+	w := httptest.NewRecorder()
+	transport.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "user-42") {
+		t.Errorf("expected tool to see principal subject, got %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer not-a-jwt")
+	w = httptest.NewRecorder()
+	transport.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for invalid token, got %d", w.Code)
+	}
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+	"github.com/mhpenta/minimcp/tools/mcpctx"
+)
+
+type emptyInput struct{}
+
+func TestRequestContextForTools(t *testing.T) {
+	seen := make(chan context.Context, 1)
+	probe := tools.NewTool("probe", "Records its context", func(ctx context.Context, _ emptyInput) (string, error) {
+		seen <- ctx
+		return "ok", nil
+	})
+	server := NewServer(ServerConfig{Name: "test-server", Tools: []tools.Tool{probe}})
+	client := startInMemory(t, server)
+	ctx := context.Background()
+
+	client.Send(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test-client","version":"2.1"}}}`))
+	receiveResponse(t, client)
+	client.Send(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"probe","arguments":{},"_meta":{"progressToken":"p-1"}}}`))
+	if resp := receiveResponse(t, client); resp.Error != nil {
+		t.Fatalf("tools/call failed: %v", resp.Error)
+	}
+
+	toolCtx := <-seen
+	if client := mcpctx.ClientInfo(toolCtx); client.Name != "test-client" || client.Version != "2.1" {
+		t.Errorf("expected client info from initialize, got %+v", client)
+	}
+	if id := mcpctx.SessionID(toolCtx); id != "in-memory" {
+		t.Errorf("expected the in-memory session ID, got %q", id)
+	}
+	if token := mcpctx.ProgressToken(toolCtx); token != "p-1" {
+		t.Errorf("expected progress token p-1, got %v", token)
+	}
+	if mcpctx.Principal(toolCtx) != nil {
+		t.Error("expected no principal over the in-memory transport")
+	}
+	if mcpctx.Logger(toolCtx) == nil {
+		t.Error("expected a request logger")
+	}
+}
+
+func TestRequestMetadataFromHTTPHeaders(t *testing.T) {
+	seen := make(chan mcpctx.Metadata, 2)
+	probe := tools.NewTool("probe", "Records its metadata", func(ctx context.Context, _ emptyInput) (string, error) {
+		seen <- mcpctx.RequestMetadata(ctx)
+		return "ok", nil
+	})
+	server := NewServer(ServerConfig{Name: "test-server", Tools: []tools.Tool{probe}})
+	transport := NewHTTPTransport(server, slog.New(slog.NewTextHandler(io.Discard, nil)), NewConstantTimeStaticValidator("key")).
+		WithContextHeaders("x-tenant-id", "X-Request-ID")
+
+	call := func(path, body string, headers map[string]string) {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer key")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		transport.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", path, rec.Code, rec.Body.String())
+		}
+	}
+
+	call("/mcp", `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"probe","arguments":{}}}`,
+		map[string]string{"X-Tenant-ID": "acme", "X-Request-ID": "r-1", "X-Other": "ignored"})
+	md := <-seen
+	if md.Get("X-Tenant-ID") != "acme" || md.Get("X-Request-ID") != "r-1" || len(md) != 2 {
+		t.Errorf("unexpected metadata %v", md)
+	}
+
+	// The REST endpoint carries it too, and absent headers are left out
+	call("/mcp/tools/call", `{"name":"probe","arguments":{}}`, map[string]string{"X-Tenant-ID": "globex"})
+	md = <-seen
+	if md.Get("X-Tenant-ID") != "globex" || len(md) != 1 {
+		t.Errorf("unexpected metadata %v", md)
+	}
+}
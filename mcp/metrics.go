@@ -0,0 +1,40 @@
+package mcp
+
+import "net/http"
+
+// MetricsPath is where the HTTP transport serves metrics when ServerConfig.Metrics is set
+const MetricsPath = "/metrics"
+
+// WithMetricsAuth requires authentication on /metrics, which is public by default
+func (t *HTTPTransport) WithMetricsAuth(required bool) *HTTPTransport {
+	t.requireMetricsAuth = required
+	return t
+}
+
+// metricsRoute serves the server's metrics according to the transport's configuration
+func (t *HTTPTransport) metricsRoute(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case t.server.metrics == nil:
+		http.NotFound(w, r)
+	case t.requireMetricsAuth:
+		t.authMiddleware(t.server.metrics.ServeHTTP)(w, r)
+	default:
+		t.server.metrics.ServeHTTP(w, r)
+	}
+}
+
+// metricsMethod bounds the method label to known methods, so arbitrary client input
+// cannot create unlimited series
+func metricsMethod(method string) string {
+	switch method {
+	case MethodInitialize, MethodToolsList, MethodToolsCall, MethodToolsDiff, MethodValidate, MethodResourcesList, MethodResourcesRead, MethodResourcesTemplatesList, MethodResourcesSubscribe, MethodResourcesUnsubscribe, MethodPromptsList, MethodPromptsGet, MethodPing:
+		return method
+	}
+	return "other"
+}
+
+// isErrorResult reports whether a method result is a failed tool call
+func isErrorResult(result interface{}) bool {
+	r, ok := result.(ToolsCallResult)
+	return ok && r.IsError
+}
@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mhpenta/minimcp/metrics"
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func TestHTTPTransport_Metrics(t *testing.T) {
+	echo := tools.NewTool("echo", "Echoes input", func(ctx context.Context, in struct{}) (string, error) {
+		return "ok", nil
+	})
+	server := NewServer(ServerConfig{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Tools:   []tools.Tool{echo},
+		Metrics: metrics.New(),
+	})
+	transport := NewHTTPTransport(server, slog.Default(), newMockValidator("test-key"))
+
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"no/such/method"}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-key")
+		transport.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	w := httptest.NewRecorder()
+	transport.ServeHTTP(w, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 from /metrics, got %d", w.Code)
+	}
+	for _, want := range []string{
+		`minimcp_requests_total{method="tools/call",status="ok"} 1`,
+		`minimcp_requests_total{method="other",status="error"} 1`,
+		`minimcp_tool_calls_total{tool="echo",status="ok"} 1`,
+	} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("missing %q in metrics:\n%s", want, w.Body.String())
+		}
+	}
+
+	transport.WithMetricsAuth(true)
+	w = httptest.NewRecorder()
+	transport.ServeHTTP(w, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for unauthenticated metrics scrape, got %d", w.Code)
+	}
+
+	// Without ServerConfig.Metrics the route does not exist
+	plain := NewHTTPTransport(NewServer(ServerConfig{Name: "plain"}), slog.Default(), newMockValidator("test-key"))
+	w = httptest.NewRecorder()
+	plain.ServeHTTP(w, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 without metrics, got %d", w.Code)
+	}
+}
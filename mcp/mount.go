@@ -0,0 +1,188 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// mount is a server whose tools are exposed by another under a prefix
+type mount struct {
+	prefix     string
+	server     *Server
+	unregister func()
+
+	// mu serializes syncs, which run whenever the mounted server's tools change
+	mu     sync.Mutex
+	synced map[string]tools.Tool // mounted tool by prefixed name
+}
+
+// Mount exposes the tools of sub under prefixed names, such as "billing.refund" for
+// sub's "refund" tool mounted at "billing". Calls are routed to sub, so its timeouts,
+// metrics and tracing apply as well as this server's. A mounted call is one sub-call of
+// the calling request's budget, which sub's tools keep drawing from; sub's RequestBudget
+// only applies to calls made to sub directly. Tools later added to or removed from sub
+// are mirrored, notifying connected clients.
+//
+// Example:
+//
+//	api := mcp.NewServer(mcp.ServerConfig{Name: "api"})
+//	api.Mount("billing", billing.NewServer())
+//	api.Mount("search", search.NewServer())
+func (s *Server) Mount(prefix string, sub *Server) error {
+	if sub == nil || sub == s {
+		return fmt.Errorf("cannot mount server at %q: a server cannot mount itself or nil", prefix)
+	}
+	if prefix == "" {
+		return fmt.Errorf("mount prefix cannot be empty")
+	}
+
+	s.mountsMu.Lock()
+	defer s.mountsMu.Unlock()
+	if _, exists := s.mounts[prefix]; exists {
+		return fmt.Errorf("a server is already mounted at %q", prefix)
+	}
+
+	// Listening first means no change is missed; syncs are serialized by m.mu
+	m := &mount{prefix: prefix, server: sub, synced: make(map[string]tools.Tool)}
+	m.unregister = sub.OnToolsChanged(func(uint64) {
+		if err := s.syncMount(m); err != nil {
+			s.logger.Error("failed to sync mounted server tools", "prefix", prefix, "error", err)
+		}
+	})
+	if err := s.syncMount(m); err != nil {
+		m.unregister()
+		return fmt.Errorf("mounting server at %q: %w", prefix, err)
+	}
+
+	if s.mounts == nil {
+		s.mounts = make(map[string]*mount)
+	}
+	s.mounts[prefix] = m
+	return nil
+}
+
+// Unmount removes the tools of the server mounted at prefix. Unknown prefixes are ignored.
+func (s *Server) Unmount(prefix string) {
+	s.mountsMu.Lock()
+	m, ok := s.mounts[prefix]
+	delete(s.mounts, prefix)
+	s.mountsMu.Unlock()
+	if !ok {
+		return
+	}
+
+	m.unregister()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s.RemoveTools(m.names()...)
+	m.synced = nil
+}
+
+// syncMount registers the mounted server's current tools and removes those it dropped.
+// Tools the mounted server still holds unchanged are left alone, so clients are only
+// notified of real changes.
+func (s *Server) syncMount(m *mount) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.synced == nil {
+		return nil // unmounted
+	}
+
+	current := make(map[string]tools.Tool)
+	var added []tools.Tool
+	for _, tool := range m.server.GetTools() {
+		name := m.prefix + tools.DefaultToolsetSeparator + tool.Spec().Name
+		if existing, ok := m.synced[name]; ok && mountedTarget(existing) == tool {
+			current[name] = existing
+			continue
+		}
+		mounted := newMountedTool(name, m.server, tool)
+		current[name] = mounted
+		added = append(added, mounted)
+	}
+
+	var removed []string
+	for name := range m.synced {
+		if _, ok := current[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+
+	if len(removed) > 0 {
+		s.RemoveTools(removed...)
+	}
+	if len(added) > 0 {
+		if err := s.AddTools(added...); err != nil {
+			return err
+		}
+	}
+	m.synced = current
+	return nil
+}
+
+func (m *mount) names() []string {
+	names := make([]string, 0, len(m.synced))
+	for name := range m.synced {
+		names = append(names, name)
+	}
+	return names
+}
+
+// mountedTool routes calls to a tool of a mounted server
+type mountedTool struct {
+	spec   *tools.ToolSpec
+	server *Server
+	tool   tools.Tool
+}
+
+// mountedStreamingTool keeps mounted streaming tools streaming
+type mountedStreamingTool struct {
+	*mountedTool
+}
+
+func newMountedTool(name string, server *Server, tool tools.Tool) tools.Tool {
+	spec := *tool.Spec()
+	spec.Name = name
+
+	mounted := &mountedTool{spec: &spec, server: server, tool: tool}
+	if _, ok := tool.(tools.StreamingTool); ok {
+		return &mountedStreamingTool{mounted}
+	}
+	return mounted
+}
+
+// mountedTarget returns the mounted server's tool behind a mounted tool
+func mountedTarget(tool tools.Tool) tools.Tool {
+	switch t := tool.(type) {
+	case *mountedTool:
+		return t.tool
+	case *mountedStreamingTool:
+		return t.tool
+	}
+	return nil
+}
+
+func (t *mountedTool) Spec() *tools.ToolSpec {
+	return t.spec
+}
+
+// Execute runs the tool on the mounted server, drawing one sub-call from the request budget
+func (t *mountedTool) Execute(ctx context.Context, params json.RawMessage) (*tools.ToolResult, error) {
+	if err := tools.SpendCall(ctx); err != nil {
+		return nil, err
+	}
+	return t.server.executeTool(ctx, t.tool, params)
+}
+
+// ExecuteStream runs the tool on the mounted server, which streams chunks to the same
+// client emitter found in ctx
+func (t *mountedStreamingTool) ExecuteStream(ctx context.Context, params json.RawMessage, emit tools.EmitFunc) (*tools.ToolResult, error) {
+	if err := tools.SpendCall(ctx); err != nil {
+		return nil, err
+	}
+	return t.server.executeTool(ctx, t.tool, params)
+}
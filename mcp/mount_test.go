@@ -0,0 +1,165 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func toolNames(server *Server) []string {
+	var names []string
+	for _, tool := range server.GetTools() {
+		names = append(names, tool.Spec().Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestServer_Mount(t *testing.T) {
+	echo := func(name string) tools.Tool {
+		return &mockTool{
+			name:        name,
+			description: "Echoes " + name,
+			parameters:  map[string]interface{}{"type": "object"},
+			result:      &tools.ToolResult{Output: name},
+		}
+	}
+	slow := &mockTool{
+		name:        "slow",
+		description: "Never finishes on time",
+		parameters:  map[string]interface{}{"type": "object"},
+		executeFn: func(ctx context.Context, params json.RawMessage) (*tools.ToolResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	billing := NewServer(ServerConfig{
+		Name:               "billing",
+		Tools:              []tools.Tool{echo("refund"), slow},
+		DefaultToolTimeout: 20 * time.Millisecond,
+	})
+	search := NewServer(ServerConfig{Name: "search", Tools: []tools.Tool{echo("query")}})
+	api := NewServer(ServerConfig{Name: "api", Tools: []tools.Tool{echo("ping")}})
+
+	if err := api.Mount("billing", billing); err != nil {
+		t.Fatalf("Mount failed: %v", err)
+	}
+	if err := api.Mount("search", search); err != nil {
+		t.Fatalf("Mount failed: %v", err)
+	}
+	if got := toolNames(api); len(got) != 4 || got[0] != "billing.refund" || got[1] != "billing.slow" || got[2] != "ping" || got[3] != "search.query" {
+		t.Fatalf("unexpected tools: %v", got)
+	}
+
+	handler := NewJSONRPCHandler(api)
+	resp, _ := handler.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search.query","arguments":{}}}`))
+	if data, _ := json.Marshal(resp.Result); string(data) != `{"content":[{"type":"text","text":"query"}]}` {
+		t.Errorf("expected search.query to reach the search server, got %s (error %v)", data, resp.Error)
+	}
+
+	// The mounted server's own timeout applies
+	_, err := api.findTool("billing.slow").Execute(context.Background(), json.RawMessage(`{}`))
+	if !errors.Is(err, ErrToolTimeout) {
+		t.Errorf("expected the billing server's timeout, got %v", err)
+	}
+
+	// Each mounted call draws from the request budget
+	budgeted := tools.WithBudget(context.Background(), tools.NewBudget(tools.BudgetLimits{MaxCalls: 1}))
+	if _, err := api.findTool("billing.refund").Execute(budgeted, json.RawMessage(`{}`)); err != nil {
+		t.Errorf("expected the first mounted call to fit the budget, got %v", err)
+	}
+	if _, err := api.findTool("billing.refund").Execute(budgeted, json.RawMessage(`{}`)); !errors.Is(err, tools.ErrBudgetExceeded) {
+		t.Errorf("expected ErrBudgetExceeded, got %v", err)
+	}
+
+	// Changes to a mounted server are mirrored with a single notification each
+	var revisions []uint64
+	unregister := api.OnToolsChanged(func(revision uint64) { revisions = append(revisions, revision) })
+	defer unregister()
+
+	if err := search.AddTools(echo("suggest")); err != nil {
+		t.Fatalf("AddTools failed: %v", err)
+	}
+	search.RemoveTools("query")
+	if got := toolNames(api); len(got) != 4 || got[3] != "search.suggest" {
+		t.Errorf("expected search.suggest to replace search.query, got %v", got)
+	}
+	if len(revisions) != 2 {
+		t.Errorf("expected two list changes, got %v", revisions)
+	}
+
+	api.Unmount("billing")
+	if got := toolNames(api); len(got) != 2 || got[0] != "ping" || got[1] != "search.suggest" {
+		t.Errorf("expected billing tools to be removed, got %v", got)
+	}
+	if err := billing.AddTools(echo("invoice")); err != nil {
+		t.Fatalf("AddTools failed: %v", err)
+	}
+	if api.findTool("billing.invoice") != nil {
+		t.Error("expected unmounted server changes to be ignored")
+	}
+}
+
+func TestServer_MountErrors(t *testing.T) {
+	api := NewServer(ServerConfig{Name: "api"})
+	sub := NewServer(ServerConfig{Name: "sub"})
+
+	if err := api.Mount("self", api); err == nil {
+		t.Error("expected error mounting a server on itself")
+	}
+	if err := api.Mount("", sub); err == nil {
+		t.Error("expected error for an empty prefix")
+	}
+	if err := api.Mount("sub", sub); err != nil {
+		t.Fatalf("Mount failed: %v", err)
+	}
+	if err := api.Mount("sub", NewServer(ServerConfig{Name: "other"})); err == nil {
+		t.Error("expected error for a prefix already in use")
+	}
+}
+
+func TestServer_MountUsesCallerBudget(t *testing.T) {
+	noop := tools.NewTool("noop", "Does nothing", func(ctx context.Context, in struct{}) (string, error) {
+		return "ok", nil
+	})
+	twice := tools.NewTool("twice", "Calls noop twice", func(ctx context.Context, in struct{}) (string, error) {
+		for i := 0; i < 2; i++ {
+			if _, err := tools.Call[struct{}, string](ctx, noop, struct{}{}); err != nil {
+				return "", err
+			}
+		}
+		return "ok", nil
+	})
+	sub := NewServer(ServerConfig{Name: "sub", Tools: []tools.Tool{twice}, RequestBudget: tools.BudgetLimits{MaxCalls: 1}})
+
+	tests := []struct {
+		name     string
+		maxCalls int
+		wantErr  bool
+	}{
+		// The mounted call and both nested calls fit the caller's budget, though not sub's
+		{"caller budget suffices", 3, false},
+		{"caller budget exhausted", 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := NewServer(ServerConfig{Name: "api", RequestBudget: tools.BudgetLimits{MaxCalls: tt.maxCalls}})
+			if err := api.Mount("sub", sub); err != nil {
+				t.Fatalf("Mount failed: %v", err)
+			}
+			result, err := api.CallTool(context.Background(), "sub.twice", nil)
+			if err != nil {
+				t.Fatalf("CallTool failed: %v", err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Errorf("expected IsError %v, got %+v", tt.wantErr, result)
+			}
+		})
+	}
+}
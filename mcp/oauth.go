@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ProtectedResourceMetadataPath is the RFC 9728 well-known path for protected resource metadata
+const ProtectedResourceMetadataPath = "/.well-known/oauth-protected-resource"
+
+// OAuthConfig enables the MCP authorization flow on an HTTP transport. The transport's
+// validator is expected to verify access tokens (see JWTValidator); OAuthConfig adds the
+// discovery metadata and challenges that OAuth-capable MCP clients rely on.
+type OAuthConfig struct {
+	// Resource is the canonical URI of this MCP server, e.g. "https://mcp.example.com/mcp". Required.
+	Resource string
+
+	// AuthorizationServers lists the issuer URLs of authorization servers that issue tokens for this resource
+	AuthorizationServers []string
+
+	// ScopesSupported lists the scopes clients may request
+	ScopesSupported []string
+
+	// ResourceDocumentation is an optional URL of human-readable documentation
+	ResourceDocumentation string
+
+	// MetadataURL overrides the resource_metadata URL advertised in WWW-Authenticate challenges.
+	// Defaults to the well-known path on the request's host.
+	MetadataURL string
+
+	// ValidateResource checks that an authenticated token was issued for this resource
+	// (RFC 8707 resource indicators). Defaults to requiring Resource in the bearer token's
+	// "aud" claim, rejecting tokens without one; client certificates are not checked.
+	ValidateResource func(ctx context.Context, principal *Principal, resource string) error
+}
+
+// ProtectedResourceMetadata is the RFC 9728 document served at ProtectedResourceMetadataPath
+type ProtectedResourceMetadata struct {
+	Resource               string   `json:"resource"`
+	AuthorizationServers   []string `json:"authorization_servers,omitempty"`
+	ScopesSupported        []string `json:"scopes_supported,omitempty"`
+	BearerMethodsSupported []string `json:"bearer_methods_supported,omitempty"`
+	ResourceDocumentation  string   `json:"resource_documentation,omitempty"`
+}
+
+// WithOAuth enables OAuth 2.1 authorization: protected resource metadata is served without
+// authentication, unauthorized responses carry a WWW-Authenticate challenge pointing at it,
+// and authenticated principals are checked against the resource indicator
+func (t *HTTPTransport) WithOAuth(cfg OAuthConfig) *HTTPTransport {
+	if t.oauth == nil {
+		t.router.HandleFunc(ProtectedResourceMetadataPath, t.handleProtectedResourceMetadata)
+		if path := resourcePath(cfg.Resource); path != "" {
+			t.router.HandleFunc(ProtectedResourceMetadataPath+path, t.handleProtectedResourceMetadata)
+		}
+	}
+	t.oauth = &cfg
+	return t
+}
+
+// handleProtectedResourceMetadata serves the RFC 9728 metadata document
+func (t *HTTPTransport) handleProtectedResourceMetadata(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	metadata := ProtectedResourceMetadata{
+		Resource:               t.oauth.Resource,
+		AuthorizationServers:   t.oauth.AuthorizationServers,
+		ScopesSupported:        t.oauth.ScopesSupported,
+		BearerMethodsSupported: []string{"header"},
+		ResourceDocumentation:  t.oauth.ResourceDocumentation,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(metadata)
+}
+
+// checkResource applies the resource indicator check to an authenticated principal. By
+// default only bearer tokens are checked, since client certificates carry no audience.
+func (t *HTTPTransport) checkResource(ctx context.Context, principal *Principal, token bool) error {
+	if t.oauth == nil {
+		return nil
+	}
+	if t.oauth.ValidateResource != nil {
+		return t.oauth.ValidateResource(ctx, principal, t.oauth.Resource)
+	}
+	if !token || t.oauth.Resource == "" {
+		return nil
+	}
+	var aud interface{}
+	if principal != nil {
+		aud = principal.Claims["aud"]
+	}
+	if aud == nil {
+		return fmt.Errorf("%w: token has no audience, so it may not be issued for resource %q", ErrUnauthenticated, t.oauth.Resource)
+	}
+	if !audienceContains(aud, t.oauth.Resource) {
+		return fmt.Errorf("%w: token not issued for resource %q", ErrUnauthenticated, t.oauth.Resource)
+	}
+	return nil
+}
+
+// unauthorized writes a 401, adding an RFC 6750 challenge when OAuth is enabled
+func (t *HTTPTransport) unauthorized(w http.ResponseWriter, r *http.Request, hasCredential bool) {
+	if t.oauth != nil {
+		challenge := fmt.Sprintf(`Bearer resource_metadata=%q`, t.metadataURL(r))
+		if hasCredential {
+			challenge += `, error="invalid_token"`
+		}
+		if len(t.oauth.ScopesSupported) > 0 {
+			challenge += fmt.Sprintf(`, scope=%q`, strings.Join(t.oauth.ScopesSupported, " "))
+		}
+		w.Header().Set("WWW-Authenticate", challenge)
+	}
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+// metadataURL returns the absolute URL of the protected resource metadata
+func (t *HTTPTransport) metadataURL(r *http.Request) string {
+	if t.oauth.MetadataURL != "" {
+		return t.oauth.MetadataURL
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host + ProtectedResourceMetadataPath + resourcePath(t.oauth.Resource)
+}
+
+// resourcePath returns the path component of the resource URI, without a trailing slash
+func resourcePath(resource string) string {
+	u, err := url.Parse(resource)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
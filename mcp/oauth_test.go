@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func newOAuthTransport(t *testing.T) (*HTTPTransport, *jwtTestKeys) {
+	t.Helper()
+	keys, jwksServer := newJWTTestKeys(t)
+	server := NewServer(ServerConfig{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Tools:   []tools.Tool{},
+	})
+
+	validator := NewJWTValidator(JWTValidatorConfig{JWKSURL: jwksServer.URL})
+	transport := NewHTTPTransport(server, slog.Default(), validator).WithOAuth(OAuthConfig{
+		Resource:             "https://mcp.example.com/mcp",
+		AuthorizationServers: []string{"https://issuer.example"},
+		ScopesSupported:      []string{"tools:read", "tools:call"},
+	})
+	return transport, keys
+}
+
+func TestHTTPTransport_OAuthMetadata(t *testing.T) {
+	transport, _ := newOAuthTransport(t)
+
+	for _, path := range []string{ProtectedResourceMetadataPath, ProtectedResourceMetadataPath + "/mcp"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		transport.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200 without credentials, got %d", path, w.Code)
+		}
+
+		var metadata ProtectedResourceMetadata
+		if err := json.NewDecoder(w.Body).Decode(&metadata); err != nil {
+			t.Fatalf("failed to decode metadata: %v", err)
+		}
+		if metadata.Resource != "https://mcp.example.com/mcp" || len(metadata.AuthorizationServers) != 1 {
+			t.Errorf("unexpected metadata: %+v", metadata)
+		}
+	}
+}
+
+func TestHTTPTransport_OAuthChallenge(t *testing.T) {
+	transport, keys := newOAuthTransport(t)
+
+	req := httptest.NewRequest(http.MethodPost, "http://mcp.example.com/mcp/tools/list", nil)
+	w := httptest.NewRecorder()
+	transport.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+	challenge := w.Header().Get("WWW-Authenticate")
+	if !strings.Contains(challenge, `resource_metadata="http://mcp.example.com/.well-known/oauth-protected-resource/mcp"`) {
+		t.Errorf("missing resource_metadata in challenge: %q", challenge)
+	}
+	if strings.Contains(challenge, "invalid_token") {
+		t.Errorf("missing credentials should not report invalid_token: %q", challenge)
+	}
+
+	// A valid token issued for a different resource is rejected
+	claims := validClaims()
+	claims["aud"] = "https://other.example.com"
+	req = httptest.NewRequest(http.MethodPost, "/mcp/tools/list", nil)
+	req.Header.Set("Authorization", "Bearer "+keys.sign(t, "RS256", "rsa-1", claims))
+	w = httptest.NewRecorder()
+	transport.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for wrong resource, got %d", w.Code)
+	}
+	if !strings.Contains(w.Header().Get("WWW-Authenticate"), `error="invalid_token"`) {
+		t.Errorf("expected invalid_token challenge, got %q", w.Header().Get("WWW-Authenticate"))
+	}
+
+	// A token without an audience could have been issued for any resource
+	delete(claims, "aud")
+	req = httptest.NewRequest(http.MethodPost, "/mcp/tools/list", nil)
+	req.Header.Set("Authorization", "Bearer "+keys.sign(t, "RS256", "rsa-1", claims))
+	w = httptest.NewRecorder()
+	transport.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a token without aud, got %d", w.Code)
+	}
+
+	claims["aud"] = "https://mcp.example.com/mcp"
+	req = httptest.NewRequest(http.MethodPost, "/mcp/tools/list", nil)
+	req.Header.Set("Authorization", "Bearer "+keys.sign(t, "RS256", "rsa-1", claims))
+	w = httptest.NewRecorder()
+	transport.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 for token issued to this resource, got %d", w.Code)
+	}
+}
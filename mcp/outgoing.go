@@ -0,0 +1,208 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultClientRequestTimeout bounds how long the server waits for a client to answer a request
+const DefaultClientRequestTimeout = 60 * time.Second
+
+// ErrClientRequestsUnsupported is returned by CallClient when the transport serving the
+// request cannot send requests to the client
+var ErrClientRequestsUnsupported = errors.New("transport cannot send requests to the client")
+
+// errTransportNotStarted is returned for requests made before the transport starts
+var errTransportNotStarted = errors.New("transport not started")
+
+// clientCallFunc sends a request to the client of the current session and decodes its result
+type clientCallFunc func(ctx context.Context, method string, params, result interface{}) error
+
+type clientCallerKey struct{}
+
+// withClientCaller attaches a way to send requests to the client; transports that cannot
+// receive responses leave it unset
+func withClientCaller(ctx context.Context, call clientCallFunc) context.Context {
+	return context.WithValue(ctx, clientCallerKey{}, call)
+}
+
+// CallClient sends a request to the client of the tool call in ctx, such as
+// sampling/createMessage, roots/list, elicitation/create, or ping, and decodes the
+// response's result into result, which may be nil. An error response from the client is
+// returned as an *RPCError. It returns ErrClientRequestsUnsupported when the transport
+// cannot originate requests; the stdio transport can.
+//
+// Example:
+//
+//	var roots struct {
+//	    Roots []struct{ URI string `json:"uri"` } `json:"roots"`
+//	}
+//	if err := mcp.CallClient(ctx, "roots/list", nil, &roots); err != nil {
+//	    return "", err
+//	}
+func CallClient(ctx context.Context, method string, params, result interface{}) error {
+	call, ok := ctx.Value(clientCallerKey{}).(clientCallFunc)
+	if !ok || call == nil {
+		return ErrClientRequestsUnsupported
+	}
+	return call(ctx, method, params, result)
+}
+
+// incomingResponse is a client's response to a request the server sent
+type incomingResponse struct {
+	ID     interface{}     `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// isResponse reports whether data is a single JSON-RPC response: an ID with a result or
+// an error, and no method
+func isResponse(data []byte) bool {
+	var probe struct {
+		ID     interface{}     `json:"id"`
+		Method string          `json:"method"`
+		Result json.RawMessage `json:"result"`
+		Error  json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	return probe.ID != nil && probe.Method == "" && (probe.Result != nil || probe.Error != nil)
+}
+
+// outgoingRequests sends requests to a client and correlates the responses with them
+type outgoingRequests struct {
+	handler *JSONRPCHandler
+	send    func(v interface{}) error
+	logger  *slog.Logger
+	timeout time.Duration
+
+	mu      sync.Mutex
+	pending map[interface{}]chan incomingResponse
+	err     error // set while requests cannot be sent
+}
+
+func newOutgoingRequests(handler *JSONRPCHandler, send func(v interface{}) error, logger *slog.Logger) *outgoingRequests {
+	return &outgoingRequests{
+		handler: handler,
+		send:    send,
+		logger:  logger,
+		timeout: DefaultClientRequestTimeout,
+		pending: make(map[interface{}]chan incomingResponse),
+		err:     errTransportNotStarted,
+	}
+}
+
+// open allows requests to be sent
+func (o *outgoingRequests) open() {
+	o.mu.Lock()
+	o.err = nil
+	o.mu.Unlock()
+}
+
+// close fails pending and later requests with err
+func (o *outgoingRequests) close(err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.err = err
+	for id, ch := range o.pending {
+		delete(o.pending, id)
+		close(ch)
+	}
+}
+
+// call sends a request and waits for its response, the timeout, or ctx to be done. A
+// request abandoned before its response arrives is cancelled with notifications/cancelled.
+func (o *outgoingRequests) call(ctx context.Context, method string, params, result interface{}) error {
+	var rawParams json.RawMessage
+	if params != nil {
+		var err error
+		if rawParams, err = json.Marshal(params); err != nil {
+			return fmt.Errorf("marshaling %s params: %w", method, err)
+		}
+	}
+
+	id, err := o.handler.NextRequestID(ctx)
+	if err != nil {
+		return err
+	}
+	key := normalizeID(id)
+
+	ch := make(chan incomingResponse, 1)
+	o.mu.Lock()
+	if o.err != nil {
+		o.mu.Unlock()
+		return o.err
+	}
+	o.pending[key] = ch
+	o.mu.Unlock()
+
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	if err := o.send(JSONRPCRequest{JSONRPC: "2.0", ID: id, Method: method, Params: rawParams}); err != nil {
+		o.forget(key)
+		return fmt.Errorf("sending %s request: %w", method, err)
+	}
+
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			return fmt.Errorf("%s request: %w", method, ErrTransportClosed)
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result != nil && len(resp.Result) > 0 {
+			if err := json.Unmarshal(resp.Result, result); err != nil {
+				return fmt.Errorf("decoding %s result: %w", method, err)
+			}
+		}
+		return nil
+
+	case <-ctx.Done():
+		if o.forget(key) {
+			params, _ := json.Marshal(CancelledNotificationParams{RequestID: id, Reason: ctx.Err().Error()})
+			o.send(JSONRPCNotification{JSONRPC: "2.0", Method: MethodNotificationCancelled, Params: params})
+		}
+		return fmt.Errorf("%s request: %w", method, ctx.Err())
+	}
+}
+
+// forget removes a pending request, reporting whether it was still waiting
+func (o *outgoingRequests) forget(key interface{}) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	_, ok := o.pending[key]
+	delete(o.pending, key)
+	return ok
+}
+
+// deliver hands a client's response to the request waiting for it
+func (o *outgoingRequests) deliver(data []byte) {
+	var resp incomingResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		o.logger.Warn("discarding malformed response from client", "error", err)
+		return
+	}
+
+	key := normalizeID(resp.ID)
+	o.mu.Lock()
+	ch, ok := o.pending[key]
+	delete(o.pending, key)
+	o.mu.Unlock()
+
+	if !ok {
+		o.logger.Warn("discarding response to unknown or abandoned request", "id", resp.ID)
+		return
+	}
+	ch <- resp
+}
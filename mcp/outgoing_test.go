@@ -0,0 +1,179 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// stdioPeer is the client side of a running stdio transport
+type stdioPeer struct {
+	in  io.Writer
+	out *bufio.Scanner
+}
+
+func (p *stdioPeer) send(t *testing.T, message string) {
+	t.Helper()
+	if _, err := io.WriteString(p.in, message+"\n"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// receive reads the next message the server wrote
+func (p *stdioPeer) receive(t *testing.T) map[string]interface{} {
+	t.Helper()
+	if !p.out.Scan() {
+		t.Fatal("expected a message from the server")
+	}
+	var message map[string]interface{}
+	if err := json.Unmarshal(p.out.Bytes(), &message); err != nil {
+		t.Fatalf("invalid message %q: %v", p.out.Bytes(), err)
+	}
+	return message
+}
+
+func startStdioPeer(t *testing.T, server *Server) (*StdioTransport, *stdioPeer) {
+	t.Helper()
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	transport := NewStdioTransportWithIO(server, logger, inReader, outWriter)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- transport.Start(context.Background())
+		outWriter.Close()
+	}()
+	t.Cleanup(func() {
+		inWriter.Close()
+		go io.Copy(io.Discard, outReader)
+		<-done
+	})
+
+	// A first exchange ensures the transport is running before the server sends requests
+	peer := &stdioPeer{in: inWriter, out: bufio.NewScanner(outReader)}
+	peer.send(t, `{"jsonrpc":"2.0","id":"ready","method":"ping"}`)
+	peer.receive(t)
+	return transport, peer
+}
+
+func TestCallClient_FromTool(t *testing.T) {
+	listRoots := tools.NewTool("list_roots", "Lists the client's roots", func(ctx context.Context, in struct{}) (string, error) {
+		var result struct {
+			Roots []struct {
+				URI string `json:"uri"`
+			} `json:"roots"`
+		}
+		if err := CallClient(ctx, "roots/list", nil, &result); err != nil {
+			return "", err
+		}
+		return result.Roots[0].URI, nil
+	})
+	server := NewServer(ServerConfig{
+		Name:        "test-server",
+		Tools:       []tools.Tool{listRoots},
+		IDGenerator: NewMonotonicIDGenerator("srv-"),
+	})
+	_, peer := startStdioPeer(t, server)
+
+	// The single worker is busy with the tool call while the client answers
+	peer.send(t, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"list_roots","arguments":{}}}`)
+	request := peer.receive(t)
+	if request["method"] != "roots/list" || request["id"] != "srv-1" {
+		t.Fatalf("expected a roots/list request, got %v", request)
+	}
+	peer.send(t, `{"jsonrpc":"2.0","id":"srv-1","result":{"roots":[{"uri":"file:///workspace"}]}}`)
+
+	response := peer.receive(t)
+	result, _ := json.Marshal(response["result"])
+	if response["id"] != float64(1) || !strings.Contains(string(result), "file:///workspace") {
+		t.Errorf("expected the tool to return the client's root, got %v", response)
+	}
+}
+
+func TestStdioTransport_Request(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server", IDGenerator: NewMonotonicIDGenerator("")})
+	transport, peer := startStdioPeer(t, server)
+	ctx := context.Background()
+
+	// A response to a numeric ID is matched however the client encodes it
+	done := make(chan error, 1)
+	go func() { done <- transport.Ping(ctx) }()
+	if request := peer.receive(t); request["method"] != MethodPing || request["id"] != float64(1) {
+		t.Fatalf("expected a ping request, got %v", request)
+	}
+	peer.send(t, `{"jsonrpc":"2.0","id":1.0,"result":{}}`)
+	if err := <-done; err != nil {
+		t.Errorf("expected the ping to succeed, got %v", err)
+	}
+
+	// Error responses are returned as RPC errors
+	go func() {
+		done <- transport.Request(ctx, "elicitation/create", map[string]string{"message": "Proceed?"}, nil)
+	}()
+	request := peer.receive(t)
+	if params, _ := request["params"].(map[string]interface{}); params["message"] != "Proceed?" {
+		t.Errorf("expected the params to be sent, got %v", request)
+	}
+	peer.send(t, `{"jsonrpc":"2.0","id":2,"error":{"code":-32601,"message":"Method not found"}}`)
+	var rpcErr *RPCError
+	if err := <-done; !errors.As(err, &rpcErr) || rpcErr.Code != MethodNotFound {
+		t.Errorf("expected the client's error, got %v", err)
+	}
+}
+
+func TestStdioTransport_RequestTimeout(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server", IDGenerator: NewMonotonicIDGenerator("")})
+	transport, peer := startStdioPeer(t, server)
+	transport.WithRequestTimeout(20 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() { done <- transport.Request(context.Background(), "sampling/createMessage", nil, nil) }()
+	peer.receive(t)
+
+	cancelled := peer.receive(t)
+	params, _ := cancelled["params"].(map[string]interface{})
+	if cancelled["method"] != MethodNotificationCancelled || params["requestId"] != float64(1) {
+		t.Errorf("expected the timed out request to be cancelled, got %v", cancelled)
+	}
+	if err := <-done; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a deadline error, got %v", err)
+	}
+
+	// A late response is discarded rather than treated as a request
+	peer.send(t, `{"jsonrpc":"2.0","id":1,"result":{}}`)
+	peer.send(t, `{"jsonrpc":"2.0","id":7,"method":"ping"}`)
+	if response := peer.receive(t); response["id"] != float64(7) {
+		t.Errorf("expected only the ping response, got %v", response)
+	}
+}
+
+func TestStdioTransport_RequestNotRunning(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server"})
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	transport := NewStdioTransportWithIO(server, logger, strings.NewReader(""), io.Discard)
+
+	if err := transport.Ping(context.Background()); !errors.Is(err, errTransportNotStarted) {
+		t.Errorf("expected an error before Start, got %v", err)
+	}
+	if err := transport.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := transport.Ping(context.Background()); !errors.Is(err, ErrTransportClosed) {
+		t.Errorf("expected ErrTransportClosed after the transport stopped, got %v", err)
+	}
+}
+
+func TestCallClient_Unsupported(t *testing.T) {
+	if err := CallClient(context.Background(), MethodPing, nil, nil); !errors.Is(err, ErrClientRequestsUnsupported) {
+		t.Errorf("expected ErrClientRequestsUnsupported, got %v", err)
+	}
+}
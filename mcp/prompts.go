@@ -0,0 +1,197 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// Prompt methods
+const (
+	MethodPromptsList = "prompts/list"
+	MethodPromptsGet  = "prompts/get"
+)
+
+// Prompt message roles. MCP prompts carry no system role; see the prompts package for
+// how system messages are sent.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// PromptRenderer produces a prompt's messages from the arguments of a prompts/get request.
+// Errors that are *tools.Error with CodeInvalidParams, such as a missing required argument,
+// are reported as InvalidParams.
+type PromptRenderer func(ctx context.Context, args map[string]string) (*PromptsGetResult, error)
+
+// PromptArgument describes an argument a prompt accepts
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// Prompt is a prompt template offered to clients, listed in prompts/list and rendered by
+// prompts/get. The prompts package builds prompts from Go templates.
+//
+// Example:
+//
+//	mcp.Prompt{
+//	    Name:        "summarize",
+//	    Description: "Summarizes a document",
+//	    Arguments:   []mcp.PromptArgument{{Name: "text", Required: true}},
+//	    Render: func(ctx context.Context, args map[string]string) (*mcp.PromptsGetResult, error) {
+//	        return &mcp.PromptsGetResult{Messages: []mcp.PromptMessage{
+//	            mcp.NewPromptMessage(mcp.RoleUser, "Summarize:\n\n"+args["text"]),
+//	        }}, nil
+//	    },
+//	}
+type Prompt struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+
+	// Render produces the prompt's messages
+	Render PromptRenderer `json:"-"`
+}
+
+// PromptContent is the content of a prompt message
+type PromptContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// PromptMessage is one message of a rendered prompt
+type PromptMessage struct {
+	Role    string        `json:"role"`
+	Content PromptContent `json:"content"`
+}
+
+// NewPromptMessage returns a text message with the given role
+func NewPromptMessage(role, text string) PromptMessage {
+	return PromptMessage{Role: role, Content: PromptContent{Type: "text", Text: text}}
+}
+
+// PromptsListResult represents the response for prompts/list
+type PromptsListResult struct {
+	Prompts []Prompt `json:"prompts"`
+}
+
+// PromptsGetParams represents the parameters for prompts/get
+type PromptsGetParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// PromptsGetResult represents the response for prompts/get
+type PromptsGetResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+// AddPrompts registers prompts. It fails, registering nothing, if a prompt has no name or
+// renderer, or its name is already registered.
+func (s *Server) AddPrompts(prompts ...Prompt) error {
+	s.promptsMu.Lock()
+	defer s.promptsMu.Unlock()
+	seen := make(map[string]bool, len(s.prompts)+len(prompts))
+	for _, prompt := range s.prompts {
+		seen[prompt.Name] = true
+	}
+	for _, prompt := range prompts {
+		switch {
+		case prompt.Name == "":
+			return errors.New("prompt has no name")
+		case prompt.Render == nil:
+			return fmt.Errorf("prompt %q has no renderer", prompt.Name)
+		case seen[prompt.Name]:
+			return fmt.Errorf("prompt %q is already registered", prompt.Name)
+		}
+		seen[prompt.Name] = true
+	}
+	s.prompts = append(s.prompts, prompts...)
+	s.capabilityRevision.Add(1)
+	return nil
+}
+
+// Prompts returns the registered prompts
+func (s *Server) Prompts() []Prompt {
+	s.promptsMu.RLock()
+	defer s.promptsMu.RUnlock()
+	return append([]Prompt(nil), s.prompts...)
+}
+
+// hasPrompts reports whether the server offers any prompts, to advertise the capability
+func (s *Server) hasPrompts() bool {
+	s.promptsMu.RLock()
+	defer s.promptsMu.RUnlock()
+	return len(s.prompts) > 0
+}
+
+// handlePromptsList lists the registered prompts
+func (h *JSONRPCHandler) handlePromptsList(ctx context.Context, params json.RawMessage) (interface{}, *RPCError) {
+	prompts := h.server.Prompts()
+	if prompts == nil {
+		prompts = []Prompt{}
+	}
+	return PromptsListResult{Prompts: prompts}, nil
+}
+
+// handlePromptsGet renders the requested prompt
+func (h *JSONRPCHandler) handlePromptsGet(ctx context.Context, params json.RawMessage) (interface{}, *RPCError) {
+	var getParams PromptsGetParams
+	if err := json.Unmarshal(params, &getParams); err != nil || getParams.Name == "" {
+		return nil, &RPCError{
+			Code:    InvalidParams,
+			Message: "Invalid prompt get parameters",
+		}
+	}
+
+	var prompt *Prompt
+	for _, p := range h.server.Prompts() {
+		if p.Name == getParams.Name {
+			prompt = &p
+			break
+		}
+	}
+	if prompt == nil {
+		return nil, &RPCError{
+			Code:    InvalidParams,
+			Message: fmt.Sprintf("Unknown prompt: %s", getParams.Name),
+		}
+	}
+
+	args := getParams.Arguments
+	if args == nil {
+		args = map[string]string{}
+	}
+	result, err := prompt.Render(ctx, args)
+	if err != nil {
+		var rpcErr *RPCError
+		var toolErr *tools.Error
+		switch {
+		case errors.As(err, &rpcErr):
+			return nil, rpcErr
+		case errors.As(err, &toolErr) && toolErr.Code == tools.CodeInvalidParams:
+			return nil, &RPCError{Code: InvalidParams, Message: toolErr.Message}
+		}
+		h.server.logger.Error("prompt render failed", "prompt", prompt.Name, "error", err)
+		return nil, &RPCError{
+			Code:    InternalError,
+			Message: fmt.Sprintf("Rendering prompt failed: %v", err),
+		}
+	}
+	if result == nil {
+		result = &PromptsGetResult{}
+	}
+	if result.Messages == nil {
+		result.Messages = []PromptMessage{}
+	}
+	if result.Description == "" {
+		result.Description = prompt.Description
+	}
+	return *result, nil
+}
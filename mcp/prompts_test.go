@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func TestPrompts(t *testing.T) {
+	server := NewServer(ServerConfig{
+		Name: "test-server",
+		Prompts: []Prompt{{
+			Name:        "greet",
+			Description: "Greets someone",
+			Arguments:   []PromptArgument{{Name: "name", Required: true}},
+			Render: func(ctx context.Context, args map[string]string) (*PromptsGetResult, error) {
+				switch args["name"] {
+				case "":
+					return nil, tools.NewInvalidParamsError("missing required argument \"name\"")
+				case "crash":
+					return nil, errors.New("template failed")
+				}
+				return &PromptsGetResult{Messages: []PromptMessage{NewPromptMessage(RoleUser, "Hello, "+args["name"])}}, nil
+			},
+		}},
+	})
+	handler := NewJSONRPCHandler(server)
+
+	call := func(msg string) *JSONRPCResponse {
+		resp, err := handler.HandleMessage(context.Background(), []byte(msg))
+		if err != nil {
+			t.Fatalf("HandleMessage failed: %v", err)
+		}
+		return resp
+	}
+
+	init := call(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"test"}}}`)
+	if init.Result.(InitializeResult).Capabilities.Prompts == nil {
+		t.Error("expected the prompts capability")
+	}
+
+	list := call(`{"jsonrpc":"2.0","id":2,"method":"prompts/list"}`).Result.(PromptsListResult)
+	if len(list.Prompts) != 1 || list.Prompts[0].Name != "greet" || !list.Prompts[0].Arguments[0].Required {
+		t.Fatalf("prompts = %+v", list.Prompts)
+	}
+
+	get := call(`{"jsonrpc":"2.0","id":3,"method":"prompts/get","params":{"name":"greet","arguments":{"name":"Ada"}}}`)
+	if get.Error != nil {
+		t.Fatal(get.Error)
+	}
+	result := get.Result.(PromptsGetResult)
+	if result.Description != "Greets someone" || len(result.Messages) != 1 || result.Messages[0].Content.Text != "Hello, Ada" {
+		t.Errorf("result = %+v", result)
+	}
+
+	for msg, code := range map[string]int{
+		`{"jsonrpc":"2.0","id":4,"method":"prompts/get","params":{"name":"nope"}}`:                               InvalidParams,
+		`{"jsonrpc":"2.0","id":5,"method":"prompts/get","params":{"name":"greet"}}`:                              InvalidParams,
+		`{"jsonrpc":"2.0","id":6,"method":"prompts/get","params":{"name":"greet","arguments":{"name":"crash"}}}`: InternalError,
+	} {
+		if resp := call(msg); resp.Error == nil || resp.Error.Code != code {
+			t.Errorf("%s: expected error code %d, got %+v", msg, code, resp.Error)
+		}
+	}
+
+	if err := server.AddPrompts(Prompt{Name: "greet", Render: server.Prompts()[0].Render}); err == nil {
+		t.Error("expected a duplicate prompt to fail")
+	}
+	if err := server.AddPrompts(Prompt{Name: "empty"}); err == nil {
+		t.Error("expected a prompt without a renderer to fail")
+	}
+}
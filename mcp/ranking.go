@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// ClientProfile adjusts tool ordering for a particular client, identified by the name it
+// sends in initialize. Some clients present tools in list order, so what matters most to
+// the agent using them should come first.
+type ClientProfile struct {
+	// CategoryPriority is added to the priority of every tool in the category
+	CategoryPriority map[string]int
+
+	// ToolPriority replaces the priority of individual tools, by name
+	ToolPriority map[string]int
+}
+
+// clientProfile returns the profile for a client name, matched case-insensitively, or nil
+func (s *Server) clientProfile(client string) *ClientProfile {
+	if client == "" {
+		return nil
+	}
+	for name, profile := range s.clientProfiles {
+		if strings.EqualFold(name, client) {
+			return &profile
+		}
+	}
+	return nil
+}
+
+// rank returns a tool's effective priority under profile
+func (p *ClientProfile) rank(spec *tools.ToolSpec) int {
+	if p == nil {
+		return spec.Priority
+	}
+	if priority, ok := p.ToolPriority[spec.Name]; ok {
+		return priority
+	}
+	return spec.Priority + p.CategoryPriority[spec.Category]
+}
+
+// rankTools returns registered sorted by descending priority for the client, keeping
+// registration order among equal priorities
+func (s *Server) rankTools(registered []tools.Tool, client string) []tools.Tool {
+	profile := s.clientProfile(client)
+	ranked := append([]tools.Tool(nil), registered...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return profile.rank(ranked[i].Spec()) > profile.rank(ranked[j].Spec())
+	})
+	return ranked
+}
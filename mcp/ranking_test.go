@@ -0,0 +1,55 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func TestToolsList_Ranking(t *testing.T) {
+	ranked := func(name, category string, priority int) tools.Tool {
+		return tools.NewTool(name, name, func(ctx context.Context, in struct{}) (string, error) { return "", nil },
+			tools.WithCategory(category), tools.WithPriority(priority))
+	}
+	server := NewServer(ServerConfig{
+		Name: "test-server",
+		Tools: []tools.Tool{
+			ranked("search", "read", 0),
+			ranked("deploy", "ops", 10),
+			ranked("fetch", "read", 0),
+		},
+		ClientProfiles: map[string]ClientProfile{
+			"ReadHeavyClient": {CategoryPriority: map[string]int{"read": 20}, ToolPriority: map[string]int{"fetch": 50}},
+		},
+	})
+	handler := NewJSONRPCHandler(server)
+
+	names := func(ctx context.Context) []string {
+		resp, _ := handler.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		var out []string
+		for _, desc := range resp.Result.(ToolsListResult).Tools {
+			out = append(out, desc.Name)
+		}
+		return out
+	}
+	assertOrder := func(got []string, want ...string) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+		}
+	}
+
+	// Priority first, then registration order
+	assertOrder(names(context.Background()), "deploy", "search", "fetch")
+
+	// A session initialized by a profiled client gets its ordering
+	ctx := withSession(context.Background(), "profiled")
+	handler.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"readheavyclient","version":"1"}}}`))
+	assertOrder(names(ctx), "fetch", "search", "deploy")
+}
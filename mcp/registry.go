@@ -0,0 +1,268 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// Tool registry change tracking
+const (
+	// MethodToolsDiff is a vendor extension returning only the tools that changed since a
+	// registry revision, so clients holding a cached listing need not re-fetch every schema
+	MethodToolsDiff = "minimcp/tools/diff"
+
+	// MethodNotificationToolsListChanged tells clients the tool list has changed
+	MethodNotificationToolsListChanged = "notifications/tools/list_changed"
+
+	// ToolsDiffCapability is advertised under the experimental capabilities when MethodToolsDiff is available
+	ToolsDiffCapability = "minimcp/toolsDiff"
+
+	// ToolsRevisionMetaKey carries the registry revision in tools/list results and list_changed notifications
+	ToolsRevisionMetaKey = "minimcp/revision"
+
+	// maxToolHistory bounds the changes kept for diffs; older revisions get a full listing
+	maxToolHistory = 1024
+)
+
+// ToolsDiffParams represents parameters for MethodToolsDiff
+type ToolsDiffParams struct {
+	// SinceRevision is the revision of the client's cached listing
+	SinceRevision uint64 `json:"sinceRevision"`
+}
+
+// ToolsDiffResult represents the response for MethodToolsDiff
+type ToolsDiffResult struct {
+	Revision uint64            `json:"revision"`
+	Added    []ToolDescription `json:"added"`
+	Updated  []ToolDescription `json:"updated"`
+	Removed  []string          `json:"removed"`
+
+	// Reset is set when the server no longer has the changes since SinceRevision.
+	// The client must discard its cache; Added then holds the full listing.
+	Reset bool `json:"reset,omitempty"`
+}
+
+// toolRegistry records how the tool list changed over time. Guarded by Server.toolsMu.
+type toolRegistry struct {
+	revision  uint64
+	base      uint64 // revision before the oldest change in history
+	history   []toolChange
+	listeners map[int]func(revision uint64)
+	nextID    int
+}
+
+// toolChange is a single tool being registered, replaced, or removed at a revision
+type toolChange struct {
+	revision uint64
+	name     string
+	existed  bool // whether the tool was registered before this change
+	spec     *tools.ToolSpec
+}
+
+// AddTools registers tools, replacing any registered tool with the same name,
+// and notifies connected clients that the tool list changed
+func (s *Server) AddTools(ts ...tools.Tool) error {
+	for _, t := range ts {
+		if err := tools.Validate(t); err != nil {
+			return fmt.Errorf("invalid tool: %w", err)
+		}
+	}
+
+	s.toolsMu.Lock()
+	updated := append([]tools.Tool(nil), s.tools...)
+	var changes []toolChange
+	for _, t := range ts {
+		spec := t.Spec()
+		existed := false
+		for i, registered := range updated {
+			if registered.Spec().Name == spec.Name {
+				updated[i], existed = t, true
+				break
+			}
+		}
+		if !existed {
+			updated = append(updated, t)
+		}
+		changes = append(changes, toolChange{name: spec.Name, existed: existed, spec: spec})
+	}
+	// Readers keep using the previous slice, so it is replaced rather than modified
+	s.tools = updated
+	revision, listeners := s.registry.record(changes)
+	s.toolsMu.Unlock()
+
+	s.logger.Info("tools registered", "count", len(ts), "revision", revision)
+	notifyToolsChanged(listeners, revision)
+	return nil
+}
+
+// RemoveTools unregisters the named tools and notifies connected clients that the
+// tool list changed. Unknown names are ignored.
+func (s *Server) RemoveTools(names ...string) {
+	remove := make(map[string]bool, len(names))
+	for _, name := range names {
+		remove[name] = true
+	}
+
+	s.toolsMu.Lock()
+	var kept []tools.Tool
+	var changes []toolChange
+	for _, t := range s.tools {
+		spec := t.Spec()
+		if remove[spec.Name] {
+			changes = append(changes, toolChange{name: spec.Name, existed: true, spec: spec})
+			continue
+		}
+		kept = append(kept, t)
+	}
+	if len(changes) == 0 {
+		s.toolsMu.Unlock()
+		return
+	}
+	s.tools = kept
+	revision, listeners := s.registry.record(changes)
+	s.toolsMu.Unlock()
+
+	s.logger.Info("tools removed", "count", len(changes), "revision", revision)
+	notifyToolsChanged(listeners, revision)
+}
+
+// ToolsRevision returns the current tool registry revision. It starts at zero and
+// increases each time tools are added, replaced, or removed.
+func (s *Server) ToolsRevision() uint64 {
+	s.toolsMu.RLock()
+	defer s.toolsMu.RUnlock()
+	return s.registry.revision
+}
+
+// OnToolsChanged registers fn to be called with the new revision whenever the tool list
+// changes. Transports use it to send list_changed notifications. The returned function
+// unregisters fn.
+func (s *Server) OnToolsChanged(fn func(revision uint64)) (unregister func()) {
+	s.toolsMu.Lock()
+	defer s.toolsMu.Unlock()
+
+	if s.registry.listeners == nil {
+		s.registry.listeners = make(map[int]func(uint64))
+	}
+	id := s.registry.nextID
+	s.registry.nextID++
+	s.registry.listeners[id] = fn
+
+	return func() {
+		s.toolsMu.Lock()
+		delete(s.registry.listeners, id)
+		s.toolsMu.Unlock()
+	}
+}
+
+// record appends changes as a new revision, returning it and the listeners to notify
+func (r *toolRegistry) record(changes []toolChange) (uint64, []func(uint64)) {
+	r.revision++
+	for i := range changes {
+		changes[i].revision = r.revision
+	}
+	r.history = append(r.history, changes...)
+	if excess := len(r.history) - maxToolHistory; excess > 0 {
+		r.base = r.history[excess-1].revision
+		r.history = append([]toolChange(nil), r.history[excess:]...)
+	}
+
+	listeners := make([]func(uint64), 0, len(r.listeners))
+	for _, fn := range r.listeners {
+		listeners = append(listeners, fn)
+	}
+	return r.revision, listeners
+}
+
+func notifyToolsChanged(listeners []func(uint64), revision uint64) {
+	for _, fn := range listeners {
+		fn(revision)
+	}
+}
+
+// toolsListChangedNotification builds the list_changed notification for a revision
+func toolsListChangedNotification(revision uint64) JSONRPCNotification {
+	params, _ := json.Marshal(map[string]interface{}{
+		"_meta": map[string]interface{}{ToolsRevisionMetaKey: revision},
+	})
+	return JSONRPCNotification{
+		JSONRPC: "2.0",
+		Method:  MethodNotificationToolsListChanged,
+		Params:  params,
+	}
+}
+
+// toolsDiff computes the changes visible to the caller in ctx since a revision
+func (s *Server) toolsDiff(ctx context.Context, since uint64) ToolsDiffResult {
+	s.toolsMu.RLock()
+	registered, registry := s.tools, s.registry
+	history := registry.history
+	s.toolsMu.RUnlock()
+
+	result := ToolsDiffResult{Revision: registry.revision, Added: []ToolDescription{}, Updated: []ToolDescription{}, Removed: []string{}}
+
+	// Every change after base is still in history, so older revisions need a full listing
+	if since < registry.base || since > registry.revision {
+		result.Reset = true
+		for _, t := range registered {
+			if canUse(ctx, t.Spec()) {
+				result.Added = append(result.Added, s.describeTool(t.Spec()))
+			}
+		}
+		return result
+	}
+
+	// For each tool touched since the client's revision, whether it existed back then
+	existedAt := make(map[string]bool)
+	removedSpecs := make(map[string]*tools.ToolSpec)
+	for _, change := range history {
+		if change.revision <= since {
+			continue
+		}
+		if _, seen := existedAt[change.name]; !seen {
+			existedAt[change.name] = change.existed
+		}
+		removedSpecs[change.name] = change.spec
+	}
+
+	current := make(map[string]*tools.ToolSpec, len(registered))
+	for _, t := range registered {
+		current[t.Spec().Name] = t.Spec()
+	}
+
+	for name, existed := range existedAt {
+		spec, exists := current[name]
+		switch {
+		case exists && canUse(ctx, spec) && existed:
+			result.Updated = append(result.Updated, s.describeTool(spec))
+		case exists && canUse(ctx, spec):
+			result.Added = append(result.Added, s.describeTool(spec))
+		case !exists && existed && canUse(ctx, removedSpecs[name]):
+			result.Removed = append(result.Removed, name)
+		}
+	}
+
+	sort.Slice(result.Added, func(i, j int) bool { return result.Added[i].Name < result.Added[j].Name })
+	sort.Slice(result.Updated, func(i, j int) bool { return result.Updated[i].Name < result.Updated[j].Name })
+	sort.Strings(result.Removed)
+	return result
+}
+
+// handleToolsDiff processes the MethodToolsDiff request
+func (h *JSONRPCHandler) handleToolsDiff(ctx context.Context, params json.RawMessage) (interface{}, *RPCError) {
+	var diffParams ToolsDiffParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &diffParams); err != nil {
+			return nil, &RPCError{
+				Code:    InvalidParams,
+				Message: "Invalid diff parameters",
+				Data:    err.Error(),
+			}
+		}
+	}
+	return h.server.toolsDiff(ctx, diffParams.SinceRevision), nil
+}
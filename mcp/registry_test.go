@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func newNamedTool(name, description string) tools.Tool {
+	return &mockTool{name: name, description: description, parameters: map[string]interface{}{"type": "object"}}
+}
+
+func callToolsDiff(t *testing.T, handler *JSONRPCHandler, since uint64) ToolsDiffResult {
+	t.Helper()
+	msg := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"minimcp/tools/diff","params":{"sinceRevision":%d}}`, since)
+	resp, err := handler.HandleMessage(context.Background(), []byte(msg))
+	if err != nil || resp.Error != nil {
+		t.Fatalf("tools diff failed: %v %+v", err, resp)
+	}
+	return resp.Result.(ToolsDiffResult)
+}
+
+func TestServer_ToolsDiff(t *testing.T) {
+	server := NewServer(ServerConfig{
+		Name:  "test-server",
+		Tools: []tools.Tool{newNamedTool("keep", "Kept"), newNamedTool("change", "Old"), newNamedTool("drop", "Dropped")},
+	})
+	handler := NewJSONRPCHandler(server)
+
+	if err := server.AddTools(newNamedTool("change", "New"), newNamedTool("fresh", "Added")); err != nil {
+		t.Fatalf("AddTools failed: %v", err)
+	}
+	server.RemoveTools("drop")
+
+	diff := callToolsDiff(t, handler, 0)
+	if diff.Revision != 2 || diff.Reset {
+		t.Fatalf("expected an incremental diff at revision 2, got %+v", diff)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].Name != "fresh" {
+		t.Errorf("expected fresh to be added, got %+v", diff.Added)
+	}
+	if len(diff.Updated) != 1 || diff.Updated[0].Description != "New" {
+		t.Errorf("expected change to be updated, got %+v", diff.Updated)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0] != "drop" {
+		t.Errorf("expected drop to be removed, got %v", diff.Removed)
+	}
+
+	// Nothing changed since the current revision
+	diff = callToolsDiff(t, handler, 2)
+	if len(diff.Added)+len(diff.Updated)+len(diff.Removed) != 0 {
+		t.Errorf("expected an empty diff, got %+v", diff)
+	}
+
+	// An unknown revision falls back to the full listing
+	diff = callToolsDiff(t, handler, 99)
+	if !diff.Reset || len(diff.Added) != 3 {
+		t.Errorf("expected a reset with the full listing, got %+v", diff)
+	}
+}
+
+func TestServer_ToolsDiffAfterHistoryTrimmed(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server"})
+	for i := 0; i <= maxToolHistory; i++ {
+		server.AddTools(newNamedTool("churn", fmt.Sprintf("v%d", i)))
+	}
+
+	diff := server.toolsDiff(context.Background(), 0)
+	if !diff.Reset || len(diff.Added) != 1 {
+		t.Errorf("expected a reset once history is trimmed, got reset=%v added=%d", diff.Reset, len(diff.Added))
+	}
+	diff = server.toolsDiff(context.Background(), server.ToolsRevision()-1)
+	if diff.Reset || len(diff.Updated) != 1 {
+		t.Errorf("expected recent revisions to still diff, got %+v", diff)
+	}
+}
+
+func TestStdioTransport_NotifiesToolsListChanged(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server"})
+	reader, writer := io.Pipe()
+	var output bytes.Buffer
+	transport := NewStdioTransportWithIO(server, nil, reader, &output)
+
+	done := make(chan error, 1)
+	go func() { done <- transport.Start(context.Background()) }()
+
+	// Wait for Start to register its listener
+	deadline := time.Now().Add(time.Second)
+	for {
+		server.toolsMu.RLock()
+		registered := len(server.registry.listeners)
+		server.toolsMu.RUnlock()
+		if registered == 1 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	server.AddTools(newNamedTool("late", "Registered after start"))
+	writer.Close()
+	<-done
+
+	if !strings.Contains(output.String(), `"method":"notifications/tools/list_changed"`) ||
+		!strings.Contains(output.String(), `"minimcp/revision":1`) {
+		t.Errorf("expected a list_changed notification with the revision, got %q", output.String())
+	}
+	if len(server.registry.listeners) != 0 {
+		t.Error("expected the listener to be removed when the transport stops")
+	}
+}
+
+func TestToolsListIncludesRevision(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server", Tools: []tools.Tool{newNamedTool("a", "A")}})
+	server.RemoveTools("a")
+
+	resp, _ := NewJSONRPCHandler(server).HandleMessage(context.Background(),
+		[]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	data, _ := json.Marshal(resp.Result)
+	if !bytes.Contains(data, []byte(`"_meta":{"minimcp/revision":1}`)) {
+		t.Errorf("expected revision in tools/list result, got %s", data)
+	}
+}
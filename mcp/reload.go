@@ -0,0 +1,193 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// DefaultConfigPollInterval is how often RunFromConfigFile checks its file for changes
+const DefaultConfigPollInterval = 2 * time.Second
+
+// configReloader applies changes to a configuration file to a running server
+type configReloader struct {
+	path      string
+	server    *Server
+	registry  []tools.Tool
+	level     *slog.LevelVar
+	validator *ReloadableValidator // nil for the stdio transport
+	logger    *slog.Logger
+
+	mu      sync.Mutex
+	current *FileConfig
+	stamp   fileStamp
+}
+
+// fileStamp identifies a version of a file by its modification time and size
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func newConfigReloader(path string, cfg *FileConfig, server *Server, registry []tools.Tool,
+	level *slog.LevelVar, validator *ReloadableValidator, logger *slog.Logger) *configReloader {
+	r := &configReloader{
+		path:      path,
+		server:    server,
+		registry:  registry,
+		level:     level,
+		validator: validator,
+		logger:    logger,
+		current:   cfg,
+	}
+	r.stamp, _ = statConfig(path)
+	return r
+}
+
+func statConfig(path string) (fileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// watch reloads the configuration on SIGHUP and when the file changes, until ctx is done
+func (r *configReloader) watch(ctx context.Context, interval time.Duration) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			r.logger.Info("SIGHUP received, reloading configuration", "path", r.path)
+			r.reloadAndLog()
+		case <-ticker.C:
+			stamp, err := statConfig(r.path)
+			if err != nil {
+				// Editors may replace the file in steps; a later tick sees the new one
+				continue
+			}
+			r.mu.Lock()
+			changed := stamp != r.stamp
+			r.mu.Unlock()
+			if changed {
+				r.logger.Info("configuration file changed, reloading", "path", r.path)
+				r.reloadAndLog()
+			}
+		}
+	}
+}
+
+func (r *configReloader) reloadAndLog() {
+	if err := r.reload(); err != nil {
+		r.logger.Error("configuration reload failed, keeping the running configuration", "path", r.path, "error", err)
+	}
+}
+
+// reload reads the file and applies it. Nothing is applied unless the whole file is valid.
+func (r *configReloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Stat before reading so a write racing the read is picked up by the next check
+	stamp, err := statConfig(r.path)
+	if err != nil {
+		return err
+	}
+	r.stamp = stamp
+
+	cfg, err := LoadConfig(r.path)
+	if err != nil {
+		return err
+	}
+	return r.apply(cfg)
+}
+
+// apply changes the running server to match cfg. Guarded by mu.
+func (r *configReloader) apply(cfg *FileConfig) error {
+	level, err := cfg.logLevel()
+	if err != nil {
+		return err
+	}
+	selected, err := cfg.Tools.apply(r.registry)
+	if err != nil {
+		return err
+	}
+	var validator APIKeyValidator
+	if r.validator != nil {
+		if validator, err = cfg.Auth.validator(r.logger); err != nil {
+			return err
+		}
+	}
+	previous, err := r.current.Tools.apply(r.registry)
+	if err != nil {
+		return fmt.Errorf("running configuration: %w", err)
+	}
+
+	r.level.Set(level)
+	if validator != nil {
+		r.validator.Set(validator)
+	}
+	added, removed := r.toolChanges(cfg, previous, selected)
+	if len(removed) > 0 {
+		r.server.RemoveTools(removed...)
+	}
+	if len(added) > 0 {
+		if err := r.server.AddTools(added...); err != nil {
+			return err
+		}
+	}
+	r.warnRestartRequired(cfg)
+
+	r.current = cfg
+	r.logger.Info("configuration reloaded", "path", r.path, "tools_added", len(added), "tools_removed", len(removed))
+	return nil
+}
+
+// toolChanges returns the tools to register, being newly enabled or reconfigured, and the
+// names of tools no longer enabled
+func (r *configReloader) toolChanges(cfg *FileConfig, previous, selected []tools.Tool) (added []tools.Tool, removed []string) {
+	enabled := make(map[string]bool, len(selected))
+	for _, tool := range selected {
+		enabled[tool.Spec().Name] = true
+	}
+	wasEnabled := make(map[string]bool, len(previous))
+	for _, tool := range previous {
+		name := tool.Spec().Name
+		wasEnabled[name] = true
+		if !enabled[name] {
+			removed = append(removed, name)
+		}
+	}
+	for _, tool := range selected {
+		name := tool.Spec().Name
+		if !wasEnabled[name] || !reflect.DeepEqual(r.current.Tools.Settings[name], cfg.Tools.Settings[name]) {
+			added = append(added, tool)
+		}
+	}
+	return added, removed
+}
+
+// warnRestartRequired logs changes that only take effect when the server restarts
+func (r *configReloader) warnRestartRequired(cfg *FileConfig) {
+	current := r.current
+	if cfg.Name != current.Name || cfg.Version != current.Version || cfg.Transport != current.Transport ||
+		cfg.Port != current.Port || cfg.Auth.Header != current.Auth.Header {
+		r.logger.Warn("name, version, transport, port, and auth header changes take effect after a restart", "path", r.path)
+	}
+}
@@ -0,0 +1,160 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestReloader loads the config at path and builds a server and reloader for it
+func newTestReloader(t *testing.T, path string, http bool) (*configReloader, *Server) {
+	t.Helper()
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	level := new(slog.LevelVar)
+	serverConfig, err := cfg.serverConfig(configTestTools(), level)
+	if err != nil {
+		t.Fatalf("serverConfig: %v", err)
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	serverConfig.Logger = logger
+	server := NewServer(serverConfig)
+
+	var validator *ReloadableValidator
+	if http {
+		keys, err := cfg.Auth.validator(logger)
+		if err != nil {
+			t.Fatalf("validator: %v", err)
+		}
+		validator = NewReloadableValidator(keys)
+	}
+	return newConfigReloader(path, cfg, server, configTestTools(), level, validator, logger), server
+}
+
+func TestConfigReload(t *testing.T) {
+	dir := t.TempDir()
+	keysPath := filepath.Join(dir, "keys")
+	if err := os.WriteFile(keysPath, []byte("old-key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path := writeConfig(t, "server.yaml", `
+name: weather
+transport: http
+auth:
+  keys_file: `+keysPath+`
+tools:
+  enabled: [get_weather, get_forecast]
+`)
+	reloader, server := newTestReloader(t, path, true)
+
+	revisions := make(chan uint64, 10)
+	defer server.OnToolsChanged(func(revision uint64) { revisions <- revision })()
+
+	if err := os.WriteFile(keysPath, []byte("new-key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`
+name: weather
+transport: http
+log_level: debug
+auth:
+  keys_file: `+keysPath+`
+tools:
+  enabled: [get_weather, reset_cache]
+  settings:
+    get_weather:
+      description: Weather right now
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloader.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+
+	if got := toolNames(server); len(got) != 2 || got[0] != "get_weather" || got[1] != "reset_cache" {
+		t.Errorf("expected get_weather and reset_cache, got %v", got)
+	}
+	for _, tool := range server.GetTools() {
+		if spec := tool.Spec(); spec.Name == "get_weather" && spec.Description != "Weather right now" {
+			t.Errorf("expected the new description, got %q", spec.Description)
+		}
+	}
+	if len(revisions) == 0 {
+		t.Error("expected a tools changed notification")
+	}
+	if reloader.level.Level() != slog.LevelDebug {
+		t.Errorf("expected the debug level, got %s", reloader.level.Level())
+	}
+	ctx := context.Background()
+	if reloader.validator.Validate(ctx, "old-key") || !reloader.validator.Validate(ctx, "new-key") {
+		t.Error("expected the rotated key to replace the old one")
+	}
+}
+
+func TestConfigReload_InvalidKeepsConfig(t *testing.T) {
+	path := writeConfig(t, "server.yaml", "name: weather\ntools:\n  enabled: [get_weather]\n")
+	reloader, server := newTestReloader(t, path, false)
+
+	for _, content := range []string{
+		"name: weather\ntools:\n  enabled: [missing]\n",
+		"name: weather\nlog_level: loud\n",
+		"name: [unterminated\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := reloader.reload(); err == nil {
+			t.Errorf("expected reloading %q to fail", content)
+		}
+	}
+	if got := toolNames(server); len(got) != 1 || got[0] != "get_weather" {
+		t.Errorf("expected the running tools to be kept, got %v", got)
+	}
+	if server.ToolsRevision() != 0 {
+		t.Errorf("expected no tool changes, got revision %d", server.ToolsRevision())
+	}
+}
+
+func TestConfigReload_UnchangedTools(t *testing.T) {
+	path := writeConfig(t, "server.yaml", "name: weather\nlog_level: warn\n")
+	reloader, server := newTestReloader(t, path, false)
+
+	if err := os.WriteFile(path, []byte("name: weather\nlog_level: error\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloader.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if server.ToolsRevision() != 0 {
+		t.Errorf("expected no tool changes when only the log level changed, got revision %d", server.ToolsRevision())
+	}
+	if reloader.level.Level() != slog.LevelError {
+		t.Errorf("expected the error level, got %s", reloader.level.Level())
+	}
+}
+
+func TestConfigReload_Watch(t *testing.T) {
+	path := writeConfig(t, "server.yaml", "name: weather\n")
+	reloader, server := newTestReloader(t, path, false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.watch(ctx, 10*time.Millisecond)
+
+	if err := os.WriteFile(path, []byte("name: weather\ntools:\n  enabled: [get_weather]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(server.GetTools()) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the change to be picked up, got %v", toolNames(server))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
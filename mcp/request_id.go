@@ -0,0 +1,91 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrRequestIDCollision is returned when the ID generator keeps producing IDs already used by the client
+var ErrRequestIDCollision = errors.New("could not generate a request ID distinct from in-flight client requests")
+
+// maxRequestIDAttempts bounds how many IDs NextRequestID tries before giving up
+const maxRequestIDAttempts = 8
+
+// IDGenerator produces IDs for requests the server sends to a client
+// (sampling, elicitation, roots). IDs must be strings or numbers.
+type IDGenerator interface {
+	NextID(session string) interface{}
+}
+
+// UUIDGenerator generates random version 4 UUID strings. It is the default.
+type UUIDGenerator struct{}
+
+// NextID returns a new random UUID
+func (UUIDGenerator) NextID(session string) interface{} {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// MonotonicIDGenerator numbers requests sequentially per session, producing deterministic
+// IDs such as "srv-1", "srv-2". Useful in tests and when reading protocol logs.
+type MonotonicIDGenerator struct {
+	prefix string
+
+	mu   sync.Mutex
+	next map[string]int64
+}
+
+// NewMonotonicIDGenerator creates a generator whose IDs are prefix followed by a per-session
+// counter. An empty prefix produces numeric IDs.
+func NewMonotonicIDGenerator(prefix string) *MonotonicIDGenerator {
+	return &MonotonicIDGenerator{prefix: prefix, next: make(map[string]int64)}
+}
+
+// NextID returns the session's next sequential ID
+func (g *MonotonicIDGenerator) NextID(session string) interface{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.next[session]++
+	if g.prefix == "" {
+		return g.next[session]
+	}
+	return fmt.Sprintf("%s%d", g.prefix, g.next[session])
+}
+
+// NextRequestID returns an ID for a request the server sends to the client in ctx's session.
+// IDs that match a request the client has in flight are skipped, so responses can always be
+// told apart from the client's own requests.
+func (h *JSONRPCHandler) NextRequestID(ctx context.Context) (interface{}, error) {
+	session := sessionFrom(ctx)
+	for attempt := 0; attempt < maxRequestIDAttempts; attempt++ {
+		id := h.server.idGenerator.NextID(session)
+
+		h.mu.Lock()
+		_, collides := h.inFlight[requestKey(ctx, normalizeID(id))]
+		h.mu.Unlock()
+
+		if !collides {
+			return id, nil
+		}
+		h.server.logger.Warn("generated request ID collides with client request", "id", id, "session", session)
+	}
+	return nil, ErrRequestIDCollision
+}
+
+// normalizeID converts integer IDs to float64, matching how client IDs decode from JSON
+func normalizeID(id interface{}) interface{} {
+	switch v := id.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	}
+	return id
+}
@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ResourceRangeMetaKey is the _meta key of resource contents holding part of a blob. Its
+// value has the "offset" and "length" of the returned bytes and the "size" of the whole
+// blob, so clients can request the rest with the range param of resources/read.
+const ResourceRangeMetaKey = "minimcp/range"
+
+// ByteRange selects part of a binary resource in resources/read
+type ByteRange struct {
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length,omitempty"` // zero reads to the end
+}
+
+// MarshalJSON encodes text contents with a "text" field and binary contents with a
+// base64 "blob" field, as MCP expects
+func (c ResourceContents) MarshalJSON() ([]byte, error) {
+	w := resourceContentsWire{URI: c.URI, MimeType: c.MimeType, Meta: c.Meta}
+	if c.Blob != nil {
+		blob := base64.StdEncoding.EncodeToString(c.Blob)
+		w.Blob = &blob
+	} else {
+		w.Text = &c.Text
+	}
+	return json.Marshal(w)
+}
+
+// UnmarshalJSON decodes text or base64 blob contents
+func (c *ResourceContents) UnmarshalJSON(data []byte) error {
+	var w resourceContentsWire
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	*c = ResourceContents{URI: w.URI, MimeType: w.MimeType, Meta: w.Meta}
+	if w.Text != nil {
+		c.Text = *w.Text
+	}
+	if w.Blob != nil {
+		blob, err := base64.StdEncoding.DecodeString(*w.Blob)
+		if err != nil {
+			return fmt.Errorf("decoding resource blob: %w", err)
+		}
+		c.Blob = blob
+	}
+	return nil
+}
+
+// resourceContentsWire is the MCP encoding of ResourceContents
+type resourceContentsWire struct {
+	URI      string                 `json:"uri"`
+	MimeType string                 `json:"mimeType,omitempty"`
+	Text     *string                `json:"text,omitempty"`
+	Blob     *string                `json:"blob,omitempty"`
+	Meta     map[string]interface{} `json:"_meta,omitempty"`
+}
+
+type resourceReadKey struct{}
+
+// resourceRead is the part of a blob a resources/read asked for
+type resourceRead struct {
+	rng *ByteRange
+	max int64
+}
+
+// ReadBlob reads binary resource contents from r, which holds size bytes. Only the range
+// the client requested, limited to ServerConfig.MaxResourceReadBytes, is read, so
+// resolvers can serve large files without loading them. ctx is the resolver's context.
+//
+// Example:
+//
+//	f, err := os.Open(path)
+//	if err != nil {
+//	    return nil, err
+//	}
+//	defer f.Close()
+//	info, err := f.Stat()
+//	if err != nil {
+//	    return nil, err
+//	}
+//	contents, err := mcp.ReadBlob(ctx, f, info.Size(), "application/pdf")
+//	return []mcp.ResourceContents{contents}, err
+func ReadBlob(ctx context.Context, r io.ReaderAt, size int64, mimeType string) (ResourceContents, error) {
+	read, _ := ctx.Value(resourceReadKey{}).(resourceRead)
+	offset, length, rpcErr := blobWindow(read, size)
+	if rpcErr != nil {
+		return ResourceContents{}, rpcErr
+	}
+
+	// Empty contents are still a blob
+	data := make([]byte, length)
+	if _, err := io.ReadFull(io.NewSectionReader(r, offset, length), data); err != nil {
+		return ResourceContents{}, fmt.Errorf("reading resource: %w", err)
+	}
+	contents := ResourceContents{MimeType: mimeType, Blob: data}
+	if offset > 0 || length < size {
+		contents.Meta = rangeMeta(nil, offset, length, size)
+	}
+	return contents, nil
+}
+
+// blobWindow returns the offset and length of the bytes to return from a blob of size
+// bytes. An offset past the end is an InvalidParams error.
+func blobWindow(read resourceRead, size int64) (int64, int64, *RPCError) {
+	var offset, length int64 = 0, size
+	if read.rng != nil {
+		if read.rng.Offset < 0 || read.rng.Length < 0 || read.rng.Offset > size {
+			return 0, 0, &RPCError{
+				Code:    InvalidParams,
+				Message: fmt.Sprintf("Invalid range: offset %d, length %d of %d bytes", read.rng.Offset, read.rng.Length, size),
+			}
+		}
+		offset, length = read.rng.Offset, size-read.rng.Offset
+		if read.rng.Length > 0 && read.rng.Length < length {
+			length = read.rng.Length
+		}
+	}
+	if read.max > 0 && length > read.max {
+		length = read.max
+	}
+	return offset, length, nil
+}
+
+// rangeMeta describes the part of a blob returned, under ResourceRangeMetaKey
+func rangeMeta(meta map[string]interface{}, offset, length, size int64) map[string]interface{} {
+	return withMetaValue(meta, ResourceRangeMetaKey, map[string]int64{"offset": offset, "length": length, "size": size})
+}
+
+// limitBlobs applies the requested range and read limit to blobs a resolver returned
+// whole, and detects their MIME type when it is missing
+func limitBlobs(read resourceRead, contents []ResourceContents) *RPCError {
+	for i := range contents {
+		c := &contents[i]
+		if c.Blob == nil {
+			continue
+		}
+		if _, ranged := c.Meta[ResourceRangeMetaKey]; ranged {
+			// Already read with ReadBlob
+			continue
+		}
+		if c.MimeType == "" {
+			c.MimeType = http.DetectContentType(c.Blob)
+		}
+
+		size := int64(len(c.Blob))
+		offset, length, err := blobWindow(read, size)
+		if err != nil {
+			return err
+		}
+		if offset > 0 || length < size {
+			c.Blob = c.Blob[offset : offset+length]
+			c.Meta = rangeMeta(c.Meta, offset, length, size)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestResourceContents_JSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents ResourceContents
+		want     string
+	}{
+		{"text", ResourceContents{URI: "a://x", Text: ""}, `{"uri":"a://x","text":""}`},
+		{"blob", ResourceContents{URI: "a://x", MimeType: "image/png", Blob: []byte{0x89, 'P', 'N', 'G'}}, `{"uri":"a://x","mimeType":"image/png","blob":"iVBORw=="}`},
+		{"empty blob", ResourceContents{URI: "a://x", Blob: []byte{}}, `{"uri":"a://x","blob":""}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.contents)
+			if err != nil || string(data) != tt.want {
+				t.Fatalf("Marshal = %s, %v, want %s", data, err, tt.want)
+			}
+			var decoded ResourceContents
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if decoded.Text != tt.contents.Text || !bytes.Equal(decoded.Blob, tt.contents.Blob) || (decoded.Blob == nil) != (tt.contents.Blob == nil) {
+				t.Errorf("round trip = %+v, want %+v", decoded, tt.contents)
+			}
+		})
+	}
+}
+
+func TestResourceRead_Blobs(t *testing.T) {
+	data := []byte("%PDF-1.7 0123456789")
+	server := NewServer(ServerConfig{
+		Name:                 "test-server",
+		MaxResourceReadBytes: 8,
+		ResourceTemplates: []ResourceTemplate{
+			{
+				URITemplate: "whole://{name}",
+				Name:        "Returned whole",
+				Resolve: func(ctx context.Context, uri string, vars map[string]string) ([]ResourceContents, error) {
+					return []ResourceContents{{Blob: data}}, nil
+				},
+			},
+			{
+				URITemplate: "ranged://{name}",
+				Name:        "Read with ReadBlob",
+				Resolve: func(ctx context.Context, uri string, vars map[string]string) ([]ResourceContents, error) {
+					contents, err := ReadBlob(ctx, bytes.NewReader(data), int64(len(data)), "application/pdf")
+					return []ResourceContents{contents}, err
+				},
+			},
+		},
+	})
+	handler := NewJSONRPCHandler(server)
+	read := func(params string) *JSONRPCResponse {
+		resp, err := handler.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":`+params+`}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	tests := []struct {
+		params       string
+		blob         string
+		offset, size int64
+	}{
+		{`{"uri":"whole://doc"}`, "%PDF-1.7", 0, 19},
+		{`{"uri":"whole://doc","range":{"offset":8,"length":4}}`, " 012", 8, 19},
+		{`{"uri":"ranged://doc"}`, "%PDF-1.7", 0, 19},
+		{`{"uri":"ranged://doc","range":{"offset":16}}`, "789", 16, 19},
+	}
+	for _, tt := range tests {
+		t.Run(tt.params, func(t *testing.T) {
+			resp := read(tt.params)
+			if resp.Error != nil {
+				t.Fatalf("unexpected error: %+v", resp.Error)
+			}
+			c := resp.Result.(ResourcesReadResult).Contents[0]
+			rng, _ := c.Meta[ResourceRangeMetaKey].(map[string]int64)
+			if string(c.Blob) != tt.blob || rng["offset"] != tt.offset || rng["length"] != int64(len(tt.blob)) || rng["size"] != tt.size {
+				t.Errorf("got blob %q with range %v", c.Blob, c.Meta)
+			}
+			if c.MimeType != "application/pdf" {
+				t.Errorf("expected the PDF MIME type, got %q", c.MimeType)
+			}
+		})
+	}
+
+	for _, params := range []string{`{"uri":"whole://doc","range":{"offset":20}}`, `{"uri":"ranged://doc","range":{"offset":-1}}`} {
+		if resp := read(params); resp.Error == nil || resp.Error.Code != InvalidParams {
+			t.Errorf("%s: expected InvalidParams, got %+v", params, resp)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Resource subscription methods and notifications
+const (
+	MethodResourcesSubscribe   = "resources/subscribe"
+	MethodResourcesUnsubscribe = "resources/unsubscribe"
+
+	// MethodNotificationResourcesUpdated tells a subscribed client a resource has changed
+	MethodNotificationResourcesUpdated = "notifications/resources/updated"
+
+	// MethodNotificationResourcesListChanged tells clients resources were added or removed
+	MethodNotificationResourcesListChanged = "notifications/resources/list_changed"
+)
+
+// ResourceProvider serves a set of concrete resources, such as the files of a directory,
+// listed in resources/list. Providers are consulted in registration order, before
+// resource templates.
+type ResourceProvider interface {
+	// ListResources returns the resources the provider serves
+	ListResources(ctx context.Context) ([]Resource, error)
+
+	// ReadResource reads one of the provider's resources. It returns ErrResourceNotFound
+	// for URIs the provider does not serve, so the next provider is tried. Contents
+	// without a URI get the request's URI.
+	ReadResource(ctx context.Context, uri string) ([]ResourceContents, error)
+}
+
+// ResourcesSubscribeParams represents the parameters for resources/subscribe and
+// resources/unsubscribe
+type ResourcesSubscribeParams struct {
+	URI string `json:"uri"`
+}
+
+// AddResourceProviders registers resource providers
+func (s *Server) AddResourceProviders(providers ...ResourceProvider) error {
+	for _, provider := range providers {
+		if provider == nil {
+			return errors.New("resource provider is nil")
+		}
+	}
+	s.resourcesMu.Lock()
+	defer s.resourcesMu.Unlock()
+	s.resourceProviders = append(s.resourceProviders, providers...)
+	s.capabilityRevision.Add(1)
+	return nil
+}
+
+// NotifyResourceUpdated tells clients subscribed to uri that the resource has changed.
+// Providers call it, directly or through a watcher, when their resources change.
+func (s *Server) NotifyResourceUpdated(uri string) {
+	s.notifyResourcesChanged(uri)
+}
+
+// NotifyResourceListChanged tells clients that resources were added or removed
+func (s *Server) NotifyResourceListChanged() {
+	s.notifyResourcesChanged("")
+}
+
+func (s *Server) notifyResourcesChanged(uri string) {
+	s.resourcesMu.RLock()
+	listeners := make([]func(string), 0, len(s.resourceListeners))
+	for _, fn := range s.resourceListeners {
+		listeners = append(listeners, fn)
+	}
+	s.resourcesMu.RUnlock()
+	for _, fn := range listeners {
+		fn(uri)
+	}
+}
+
+// OnResourcesChanged registers fn to be called with the URI of each updated resource, or
+// with "" when the resource list changed. Transports use it to send resource
+// notifications. The returned function unregisters fn.
+func (s *Server) OnResourcesChanged(fn func(uri string)) (unregister func()) {
+	s.resourcesMu.Lock()
+	defer s.resourcesMu.Unlock()
+
+	if s.resourceListeners == nil {
+		s.resourceListeners = make(map[int]func(string))
+	}
+	id := s.nextResourceListener
+	s.nextResourceListener++
+	s.resourceListeners[id] = fn
+
+	return func() {
+		s.resourcesMu.Lock()
+		delete(s.resourceListeners, id)
+		s.resourcesMu.Unlock()
+	}
+}
+
+// hasResourceProviders reports whether any providers are registered, to advertise
+// subscriptions and list changes
+func (s *Server) hasResourceProviders() bool {
+	s.resourcesMu.RLock()
+	defer s.resourcesMu.RUnlock()
+	return len(s.resourceProviders) > 0
+}
+
+func (s *Server) providers() []ResourceProvider {
+	s.resourcesMu.RLock()
+	defer s.resourcesMu.RUnlock()
+	return append([]ResourceProvider(nil), s.resourceProviders...)
+}
+
+// listProviderResources returns the resources of every provider. A failing provider is
+// logged and left out, so one broken source does not hide the others.
+func (s *Server) listProviderResources(ctx context.Context) []Resource {
+	var resources []Resource
+	for _, provider := range s.providers() {
+		listed, err := provider.ListResources(ctx)
+		if err != nil {
+			s.logger.Error("listing resources failed", "provider", fmt.Sprintf("%T", provider), "error", err)
+			continue
+		}
+		resources = append(resources, listed...)
+	}
+	return resources
+}
+
+// readProviderResource reads the requested URI from the first provider serving it.
+// ok is false when none does.
+func (s *Server) readProviderResource(ctx context.Context, params ResourcesReadParams) (result *ResourcesReadResult, rpcErr *RPCError, ok bool) {
+	read := resourceRead{rng: params.Range, max: s.maxResourceReadBytes}
+	ctx = context.WithValue(ctx, resourceReadKey{}, read)
+	for _, provider := range s.providers() {
+		contents, err := provider.ReadResource(ctx, params.URI)
+		if errors.Is(err, ErrResourceNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, s.resourceReadError(params.URI, err, "provider", fmt.Sprintf("%T", provider)), true
+		}
+		result, rpcErr = finishResourceRead(read, params.URI, "", contents)
+		return result, rpcErr, true
+	}
+	return nil, nil, false
+}
+
+// handleResourcesSubscribe records the session's interest in a resource's updates
+func (h *JSONRPCHandler) handleResourcesSubscribe(ctx context.Context, params json.RawMessage, subscribe bool) (interface{}, *RPCError) {
+	var subscribeParams ResourcesSubscribeParams
+	if err := json.Unmarshal(params, &subscribeParams); err != nil || subscribeParams.URI == "" {
+		return nil, &RPCError{
+			Code:    InvalidParams,
+			Message: "Invalid resource subscription parameters",
+		}
+	}
+
+	session := sessionFrom(ctx)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if subscribe {
+		if h.subscriptions[session] == nil {
+			h.subscriptions[session] = make(map[string]bool)
+		}
+		h.subscriptions[session][subscribeParams.URI] = true
+	} else {
+		delete(h.subscriptions[session], subscribeParams.URI)
+	}
+	return struct{}{}, nil
+}
+
+// resourceNotification builds the notification a session receives for a change reported
+// to OnResourcesChanged. ok is false when the session did not subscribe to the resource.
+func (h *JSONRPCHandler) resourceNotification(session, uri string) (n JSONRPCNotification, ok bool) {
+	if uri == "" {
+		return JSONRPCNotification{JSONRPC: "2.0", Method: MethodNotificationResourcesListChanged}, true
+	}
+	h.mu.Lock()
+	subscribed := h.subscriptions[session][uri]
+	h.mu.Unlock()
+	if !subscribed {
+		return JSONRPCNotification{}, false
+	}
+	params, _ := json.Marshal(ResourcesSubscribeParams{URI: uri})
+	return JSONRPCNotification{JSONRPC: "2.0", Method: MethodNotificationResourcesUpdated, Params: params}, true
+}
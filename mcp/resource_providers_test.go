@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// mapProvider serves fixed text resources by URI
+type mapProvider map[string]string
+
+func (p mapProvider) ListResources(ctx context.Context) ([]Resource, error) {
+	var resources []Resource
+	for uri := range p {
+		resources = append(resources, Resource{URI: uri, Name: uri})
+	}
+	return resources, nil
+}
+
+func (p mapProvider) ReadResource(ctx context.Context, uri string) ([]ResourceContents, error) {
+	text, ok := p[uri]
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+	if text == "broken" {
+		return nil, errors.New("disk on fire")
+	}
+	return []ResourceContents{{MimeType: "text/plain", Text: text}}, nil
+}
+
+func TestResourceProviders(t *testing.T) {
+	server := NewServer(ServerConfig{
+		Name:              "test-server",
+		ResourceProviders: []ResourceProvider{mapProvider{"notes://a": "first"}, mapProvider{"notes://b": "broken"}},
+		ResourceTemplates: []ResourceTemplate{{
+			URITemplate: "notes://{name}",
+			Name:        "Note",
+			Resolve: func(ctx context.Context, uri string, vars map[string]string) ([]ResourceContents, error) {
+				return []ResourceContents{{Text: "from template"}}, nil
+			},
+		}},
+	})
+	handler := NewJSONRPCHandler(server)
+	call := func(msg string) *JSONRPCResponse {
+		resp, err := handler.HandleMessage(context.Background(), []byte(msg))
+		if err != nil {
+			t.Fatalf("HandleMessage failed: %v", err)
+		}
+		return resp
+	}
+
+	init := call(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"test"}}}`)
+	if caps := init.Result.(InitializeResult).Capabilities.Resources; caps["subscribe"] != true || caps["listChanged"] != true {
+		t.Errorf("resources capability = %v", caps)
+	}
+
+	list := call(`{"jsonrpc":"2.0","id":2,"method":"resources/list"}`).Result.(ResourcesListResult)
+	if len(list.Resources) != 2 {
+		t.Errorf("resources = %+v", list.Resources)
+	}
+
+	// Providers are read before templates, which serve URIs no provider does
+	for uri, want := range map[string]string{"notes://a": "first", "notes://c": "from template"} {
+		contents, err := server.ReadResource(context.Background(), uri)
+		if err != nil || contents[0].Text != want || contents[0].URI != uri {
+			t.Errorf("%s = %+v, %v", uri, contents, err)
+		}
+	}
+	var rpcErr *RPCError
+	if _, err := server.ReadResource(context.Background(), "notes://b"); !errors.As(err, &rpcErr) || rpcErr.Code != InternalError {
+		t.Errorf("expected an internal error from the failing provider, got %v", err)
+	}
+}
+
+func TestResourceSubscriptions(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server", ResourceProviders: []ResourceProvider{mapProvider{"notes://a": "first"}}})
+	client := startInMemory(t, server)
+	ctx := context.Background()
+
+	send := func(msg string) {
+		if err := client.Send(ctx, []byte(msg)); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+	receive := func() string {
+		msg, err := client.Receive(ctx)
+		if err != nil {
+			t.Fatalf("Receive failed: %v", err)
+		}
+		return string(msg)
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"notes://a"}}`)
+	if resp := receive(); !strings.Contains(resp, `"result":{}`) {
+		t.Fatalf("subscribe response = %s", resp)
+	}
+
+	// Only the subscribed resource is reported, then the list change
+	server.NotifyResourceUpdated("notes://other")
+	server.NotifyResourceUpdated("notes://a")
+	var n JSONRPCNotification
+	if err := json.Unmarshal([]byte(receive()), &n); err != nil || n.Method != MethodNotificationResourcesUpdated || string(n.Params) != `{"uri":"notes://a"}` {
+		t.Errorf("notification = %+v, %v", n, err)
+	}
+	server.NotifyResourceListChanged()
+	if err := json.Unmarshal([]byte(receive()), &n); err != nil || n.Method != MethodNotificationResourcesListChanged {
+		t.Errorf("notification = %+v, %v", n, err)
+	}
+
+	send(`{"jsonrpc":"2.0","id":2,"method":"resources/unsubscribe","params":{"uri":"notes://a"}}`)
+	receive()
+	server.NotifyResourceUpdated("notes://a")
+	send(`{"jsonrpc":"2.0","id":3,"method":"ping"}`)
+	if resp := receive(); !strings.Contains(resp, `"id":3`) {
+		t.Errorf("expected no notification after unsubscribing, got %s", resp)
+	}
+
+	send(`{"jsonrpc":"2.0","id":4,"method":"resources/subscribe","params":{}}`)
+	if resp := receive(); !strings.Contains(resp, `"code":-32602`) {
+		t.Errorf("expected invalid params, got %s", resp)
+	}
+}
@@ -0,0 +1,178 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MethodResourcesTemplatesList lists the server's resource templates
+const MethodResourcesTemplatesList = "resources/templates/list"
+
+// ErrResourceNotFound is returned by a ResourceResolver when no resource exists for a
+// URI that matches its template. resources/read reports it as ResourceNotFound.
+var ErrResourceNotFound = errors.New("resource not found")
+
+// ResourceResolver reads the resource at uri, which matched a template. vars holds the
+// decoded values of the template's variables. Contents without a URI or MIME type get
+// the request's URI and the template's MIME type.
+type ResourceResolver func(ctx context.Context, uri string, vars map[string]string) ([]ResourceContents, error)
+
+// ResourceTemplate describes a family of resources by an RFC 6570 URI template, listed
+// in resources/templates/list. Reads of URIs matching the template go to Resolve.
+//
+// Example:
+//
+//	mcp.ResourceTemplate{
+//	    URITemplate: "db://{schema}/{table}",
+//	    Name:        "Table",
+//	    MimeType:    "application/json",
+//	    Resolve: func(ctx context.Context, uri string, vars map[string]string) ([]mcp.ResourceContents, error) {
+//	        rows, err := describeTable(ctx, vars["schema"], vars["table"])
+//	        if err != nil {
+//	            return nil, err
+//	        }
+//	        return []mcp.ResourceContents{{Text: rows}}, nil
+//	    },
+//	}
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+
+	// Resolve reads a resource whose URI matches URITemplate
+	Resolve ResourceResolver `json:"-"`
+}
+
+// ResourcesTemplatesListResult represents the response for resources/templates/list
+type ResourcesTemplatesListResult struct {
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+}
+
+// resourceTemplate is a registered template with its parsed URI template
+type resourceTemplate struct {
+	ResourceTemplate
+	uri *URITemplate
+}
+
+// AddResourceTemplates registers resource templates. Reads are resolved by the first
+// registered template matching the URI. It fails, registering nothing, if a template
+// cannot be parsed or has no resolver.
+func (s *Server) AddResourceTemplates(templates ...ResourceTemplate) error {
+	parsed := make([]resourceTemplate, 0, len(templates))
+	for _, template := range templates {
+		uri, err := ParseURITemplate(template.URITemplate)
+		if err != nil {
+			return err
+		}
+		if template.Resolve == nil {
+			return fmt.Errorf("resource template %q has no resolver", template.URITemplate)
+		}
+		parsed = append(parsed, resourceTemplate{ResourceTemplate: template, uri: uri})
+	}
+
+	s.resourcesMu.Lock()
+	defer s.resourcesMu.Unlock()
+	s.resourceTemplates = append(s.resourceTemplates, parsed...)
+	s.capabilityRevision.Add(1)
+	return nil
+}
+
+// ResourceTemplates returns the registered resource templates
+func (s *Server) ResourceTemplates() []ResourceTemplate {
+	s.resourcesMu.RLock()
+	defer s.resourcesMu.RUnlock()
+	templates := make([]ResourceTemplate, len(s.resourceTemplates))
+	for i, template := range s.resourceTemplates {
+		templates[i] = template.ResourceTemplate
+	}
+	return templates
+}
+
+// hasResources reports whether the server serves any resources, to advertise the capability
+func (s *Server) hasResources() bool {
+	s.resourcesMu.RLock()
+	defer s.resourcesMu.RUnlock()
+	return s.maxDescriptionTokens > 0 || len(s.resourceTemplates) > 0 || len(s.resourceProviders) > 0
+}
+
+// handleResourcesTemplatesList lists the registered resource templates
+func (h *JSONRPCHandler) handleResourcesTemplatesList(ctx context.Context, params json.RawMessage) (interface{}, *RPCError) {
+	return ResourcesTemplatesListResult{ResourceTemplates: h.server.ResourceTemplates()}, nil
+}
+
+// readTemplateResource resolves the requested URI with the first template it matches.
+// ok is false when no template matches.
+func (s *Server) readTemplateResource(ctx context.Context, params ResourcesReadParams) (result *ResourcesReadResult, rpcErr *RPCError, ok bool) {
+	uri := params.URI
+	read := resourceRead{rng: params.Range, max: s.maxResourceReadBytes}
+	s.resourcesMu.RLock()
+	var match *resourceTemplate
+	var vars map[string]string
+	for i := range s.resourceTemplates {
+		if vars, ok = s.resourceTemplates[i].uri.Match(uri); ok {
+			match = &s.resourceTemplates[i]
+			break
+		}
+	}
+	s.resourcesMu.RUnlock()
+	if match == nil {
+		return nil, nil, false
+	}
+
+	contents, err := match.Resolve(context.WithValue(ctx, resourceReadKey{}, read), uri, vars)
+	if err != nil {
+		return nil, s.resourceReadError(uri, err, "template", match.URITemplate), true
+	}
+	result, rpcErr = finishResourceRead(read, uri, match.MimeType, contents)
+	return result, rpcErr, true
+}
+
+// resourceReadError converts an error reading uri to the resources/read error. attrs
+// identify the template or provider in the log.
+func (s *Server) resourceReadError(uri string, err error, attrs ...interface{}) *RPCError {
+	var rpcErr *RPCError
+	switch {
+	case errors.As(err, &rpcErr):
+		return rpcErr
+	case errors.Is(err, ErrResourceNotFound):
+		return resourceNotFound(uri)
+	}
+	s.logger.Error("resource read failed", append([]interface{}{"uri", uri, "error", err}, attrs...)...)
+	return &RPCError{
+		Code:    InternalError,
+		Message: fmt.Sprintf("Reading resource failed: %v", err),
+		Data:    map[string]string{"uri": uri},
+	}
+}
+
+// finishResourceRead fills in the URI and MIME type contents leave out, and limits blobs
+// to the requested range
+func finishResourceRead(read resourceRead, uri, mimeType string, contents []ResourceContents) (*ResourcesReadResult, *RPCError) {
+	if contents == nil {
+		contents = []ResourceContents{}
+	}
+	for i := range contents {
+		if contents[i].URI == "" {
+			contents[i].URI = uri
+		}
+		if contents[i].MimeType == "" {
+			contents[i].MimeType = mimeType
+		}
+	}
+	if rpcErr := limitBlobs(read, contents); rpcErr != nil {
+		return nil, rpcErr
+	}
+	return &ResourcesReadResult{Contents: contents}, nil
+}
+
+// resourceNotFound is the error for a resources/read of an unknown URI
+func resourceNotFound(uri string) *RPCError {
+	return &RPCError{
+		Code:    ResourceNotFound,
+		Message: "Resource not found",
+		Data:    map[string]string{"uri": uri},
+	}
+}
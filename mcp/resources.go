@@ -0,0 +1,177 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// Resource methods
+const (
+	MethodResourcesList = "resources/list"
+	MethodResourcesRead = "resources/read"
+)
+
+// ResourceNotFound is returned when resources/read names an unknown resource
+const ResourceNotFound = -32002
+
+// toolDocsScheme prefixes the URIs of tool documentation resources
+const toolDocsScheme = "minimcp://tools/"
+
+// charsPerToken approximates token counts from description length
+const charsPerToken = 4
+
+// Resource describes a readable resource in resources/list
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourcesListResult represents the response for resources/list
+type ResourcesListResult struct {
+	Resources []Resource `json:"resources"`
+}
+
+// ResourcesReadParams represents parameters for resources/read
+type ResourcesReadParams struct {
+	URI string `json:"uri"`
+
+	// Range asks for part of a binary resource, such as the next chunk of a large file
+	Range *ByteRange `json:"range,omitempty"`
+}
+
+// ResourceContents is the content of a resource: Text for UTF-8 text, or Blob for binary
+// data, which is sent base64 encoded
+type ResourceContents struct {
+	URI      string
+	MimeType string
+	Text     string
+	Blob     []byte
+
+	// Meta carries ResourceRangeMetaKey when Blob holds part of the resource
+	Meta map[string]interface{}
+}
+
+// ResourcesReadResult represents the response for resources/read
+type ResourcesReadResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// toolDocsURI is the resource holding a tool's full description
+func toolDocsURI(tool string) string {
+	return toolDocsScheme + tool + "/description"
+}
+
+// estimateTokens approximates how many tokens text uses in a prompt
+func estimateTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+// overflows reports whether a description exceeds the server's per-tool budget
+func (s *Server) overflows(description string) bool {
+	return s.maxDescriptionTokens > 0 && estimateTokens(description) > s.maxDescriptionTokens
+}
+
+// budgetDescription shortens a description that exceeds the per-tool token budget, cutting
+// at a word boundary and pointing to the resource holding the full text
+func (s *Server) budgetDescription(spec *tools.ToolSpec) string {
+	if !s.overflows(spec.Description) {
+		return spec.Description
+	}
+
+	suffix := fmt.Sprintf("… (full documentation: %s)", toolDocsURI(spec.Name))
+	limit := s.maxDescriptionTokens*charsPerToken - len(suffix)
+	if limit < 0 {
+		limit = 0
+	}
+
+	cut := spec.Description[:limit]
+	if i := strings.LastIndexFunc(cut, unicode.IsSpace); i > 0 {
+		cut = cut[:i]
+	}
+	// Avoid splitting a multi-byte character
+	for len(cut) > 0 && !utf8.ValidString(cut) {
+		cut = cut[:len(cut)-1]
+	}
+	return strings.TrimRightFunc(cut, unicode.IsSpace) + suffix
+}
+
+// handleResourcesList lists the documentation resources of tools whose descriptions
+// overflowed, and the resources of providers
+func (h *JSONRPCHandler) handleResourcesList(ctx context.Context, params json.RawMessage) (interface{}, *RPCError) {
+	resources := []Resource{}
+	for _, tool := range h.server.GetTools() {
+		spec := tool.Spec()
+		if !canUse(ctx, spec) || !h.server.overflows(spec.Description) {
+			continue
+		}
+		resources = append(resources, Resource{
+			URI:         toolDocsURI(spec.Name),
+			Name:        spec.Name + " documentation",
+			Description: fmt.Sprintf("Full description of the %s tool", spec.Name),
+			MimeType:    "text/plain",
+		})
+	}
+	resources = append(resources, h.server.listProviderResources(ctx)...)
+	return ResourcesListResult{Resources: resources}, nil
+}
+
+// handleResourcesRead returns a tool's full description, or the resource a template resolves
+func (h *JSONRPCHandler) handleResourcesRead(ctx context.Context, params json.RawMessage) (interface{}, *RPCError) {
+	var readParams ResourcesReadParams
+	if err := json.Unmarshal(params, &readParams); err != nil || readParams.URI == "" {
+		return nil, &RPCError{
+			Code:    InvalidParams,
+			Message: "Invalid resource read parameters",
+		}
+	}
+
+	result, rpcErr := h.server.readResource(ctx, readParams)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return *result, nil
+}
+
+// ReadResource reads a resource as resources/read does, for server-side code such as tools
+// that post-process resources. Failures are *RPCError values; unknown URIs have code
+// ResourceNotFound.
+func (s *Server) ReadResource(ctx context.Context, uri string) ([]ResourceContents, error) {
+	result, rpcErr := s.readResource(ctx, ResourcesReadParams{URI: uri})
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return result.Contents, nil
+}
+
+// readResource returns a tool's full description, or the resource a provider or template
+// serves
+func (s *Server) readResource(ctx context.Context, params ResourcesReadParams) (*ResourcesReadResult, *RPCError) {
+	name := strings.TrimSuffix(strings.TrimPrefix(params.URI, toolDocsScheme), "/description")
+	for _, tool := range s.GetTools() {
+		spec := tool.Spec()
+		if spec.Name != name || toolDocsURI(name) != params.URI || !canUse(ctx, spec) {
+			continue
+		}
+		return &ResourcesReadResult{Contents: []ResourceContents{{
+			URI:      params.URI,
+			MimeType: "text/plain",
+			Text:     spec.Description,
+		}}}, nil
+	}
+
+	if result, rpcErr, ok := s.readProviderResource(ctx, params); ok {
+		return result, rpcErr
+	}
+	if result, rpcErr, ok := s.readTemplateResource(ctx, params); ok {
+		return result, rpcErr
+	}
+	return nil, resourceNotFound(params.URI)
+}
@@ -0,0 +1,169 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func TestDescriptionBudget(t *testing.T) {
+	long := strings.Repeat("Explains every option in great detail. ", 20)
+	server := NewServer(ServerConfig{
+		Name:                 "test-server",
+		Tools:                []tools.Tool{newNamedTool("verbose", long), newNamedTool("short", "Brief")},
+		MaxDescriptionTokens: 40,
+	})
+	handler := NewJSONRPCHandler(server)
+
+	call := func(msg string) *JSONRPCResponse {
+		resp, err := handler.HandleMessage(context.Background(), []byte(msg))
+		if err != nil {
+			t.Fatalf("HandleMessage failed: %v", err)
+		}
+		return resp
+	}
+
+	list := call(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`).Result.(ToolsListResult)
+	for _, desc := range list.Tools {
+		switch desc.Name {
+		case "verbose":
+			if estimateTokens(desc.Description) > 40 || !strings.HasSuffix(desc.Description, toolDocsURI("verbose")+")") {
+				t.Errorf("expected a budgeted description with a reference, got %q", desc.Description)
+			}
+		case "short":
+			if desc.Description != "Brief" {
+				t.Errorf("expected short description untouched, got %q", desc.Description)
+			}
+		}
+	}
+
+	resources := call(`{"jsonrpc":"2.0","id":2,"method":"resources/list"}`).Result.(ResourcesListResult)
+	if len(resources.Resources) != 1 || resources.Resources[0].URI != toolDocsURI("verbose") {
+		t.Fatalf("expected one overflow resource, got %+v", resources.Resources)
+	}
+
+	params, _ := json.Marshal(ResourcesReadParams{URI: toolDocsURI("verbose")})
+	read := call(`{"jsonrpc":"2.0","id":3,"method":"resources/read","params":` + string(params) + `}`)
+	if read.Error != nil || read.Result.(ResourcesReadResult).Contents[0].Text != long {
+		t.Errorf("expected the full description, got %+v", read)
+	}
+
+	missing := call(`{"jsonrpc":"2.0","id":4,"method":"resources/read","params":{"uri":"minimcp://tools/nope/description"}}`)
+	if missing.Error == nil || missing.Error.Code != ResourceNotFound {
+		t.Errorf("expected ResourceNotFound, got %+v", missing)
+	}
+}
+
+func TestResourceTemplates(t *testing.T) {
+	tables := map[string]string{"public/users": "id,name"}
+	server := NewServer(ServerConfig{
+		Name: "test-server",
+		ResourceTemplates: []ResourceTemplate{{
+			URITemplate: "db://{schema}/{table}",
+			Name:        "Table",
+			MimeType:    "text/csv",
+			Resolve: func(ctx context.Context, uri string, vars map[string]string) ([]ResourceContents, error) {
+				if vars["schema"] == "broken" {
+					return nil, errors.New("connection refused")
+				}
+				columns, ok := tables[vars["schema"]+"/"+vars["table"]]
+				if !ok {
+					return nil, ErrResourceNotFound
+				}
+				return []ResourceContents{{Text: columns}}, nil
+			},
+		}},
+	})
+	handler := NewJSONRPCHandler(server)
+	call := func(msg string) *JSONRPCResponse {
+		resp, err := handler.HandleMessage(context.Background(), []byte(msg))
+		if err != nil {
+			t.Fatalf("HandleMessage failed: %v", err)
+		}
+		return resp
+	}
+
+	init := call(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`)
+	if init.Result.(InitializeResult).Capabilities.Resources == nil {
+		t.Error("expected the resources capability to be advertised")
+	}
+
+	list := call(`{"jsonrpc":"2.0","id":2,"method":"resources/templates/list"}`)
+	data, _ := json.Marshal(list.Result)
+	if !strings.Contains(string(data), `"resourceTemplates":[{"uriTemplate":"db://{schema}/{table}","name":"Table","mimeType":"text/csv"}]`) {
+		t.Errorf("unexpected template list: %s", data)
+	}
+
+	read := call(`{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"db://public/users"}}`)
+	if read.Error != nil {
+		t.Fatalf("expected the table to be read, got %+v", read.Error)
+	}
+	contents := read.Result.(ResourcesReadResult).Contents
+	if len(contents) != 1 || contents[0].URI != "db://public/users" || contents[0].MimeType != "text/csv" || contents[0].Text != "id,name" {
+		t.Errorf("expected the resolved contents with defaults filled in, got %+v", contents)
+	}
+
+	tests := []struct {
+		uri  string
+		code int
+	}{
+		{"db://public/orders", ResourceNotFound},
+		{"db://broken/users", InternalError},
+		{"other://public/users", ResourceNotFound},
+	}
+	for _, tt := range tests {
+		resp := call(`{"jsonrpc":"2.0","id":4,"method":"resources/read","params":{"uri":"` + tt.uri + `"}}`)
+		if resp.Error == nil || resp.Error.Code != tt.code {
+			t.Errorf("%s: expected error %d, got %+v", tt.uri, tt.code, resp)
+		}
+		var rpcErr *RPCError
+		if _, err := server.ReadResource(context.Background(), tt.uri); !errors.As(err, &rpcErr) || rpcErr.Code != tt.code {
+			t.Errorf("ReadResource(%s): expected error %d, got %v", tt.uri, tt.code, err)
+		}
+	}
+
+	// Server-side reads match resources/read
+	contents, err := server.ReadResource(context.Background(), "db://public/users")
+	if err != nil || len(contents) != 1 || contents[0].Text != "id,name" || contents[0].MimeType != "text/csv" {
+		t.Errorf("ReadResource = %+v, %v", contents, err)
+	}
+}
+
+func TestAddResourceTemplates_Invalid(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server"})
+	resolve := func(ctx context.Context, uri string, vars map[string]string) ([]ResourceContents, error) {
+		return nil, nil
+	}
+
+	if err := server.AddResourceTemplates(ResourceTemplate{URITemplate: "db://{schema", Resolve: resolve}); err == nil {
+		t.Error("expected an unparseable template to be rejected")
+	}
+	if err := server.AddResourceTemplates(ResourceTemplate{URITemplate: "db://{schema}"}); err == nil {
+		t.Error("expected a template without a resolver to be rejected")
+	}
+	if len(server.ResourceTemplates()) != 0 {
+		t.Error("expected nothing to be registered")
+	}
+}
+
+func TestNewServer_InvalidConfig(t *testing.T) {
+	tests := []ServerConfig{
+		{Name: "test-server", ResourceTemplates: []ResourceTemplate{{URITemplate: "db://{schema}"}}},
+		{Name: "test-server", ResourceProviders: []ResourceProvider{nil}},
+		{Name: "test-server", Prompts: []Prompt{{}}},
+	}
+	for i, cfg := range tests {
+		if _, err := NewServerWithError(cfg); err == nil {
+			t.Errorf("config %d: expected an error", i)
+		}
+	}
+
+	// NewServer skips the invalid entries
+	if server := NewServer(tests[0]); server == nil || len(server.ResourceTemplates()) != 0 {
+		t.Error("expected NewServer to start without the invalid templates")
+	}
+}
@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+const (
+	// ToolErrorMetaKey carries the code and data of a failed tool call in the result's _meta,
+	// so clients can tell failures apart without parsing the text
+	ToolErrorMetaKey = "minimcp/error"
+
+	// ToolSystemMetaKey carries a result's SystemInfo metadata in the result's _meta
+	ToolSystemMetaKey = "minimcp/system"
+)
+
+// toolFailure classifies the outcome of a tool execution, returning nil on success. An
+// error returned by the tool takes precedence over an error set on its result.
+func toolFailure(result *tools.ToolResult, err error) *tools.Error {
+	if err != nil {
+		return tools.AsError(err)
+	}
+	return result.Err()
+}
+
+// toolCallResult converts the outcome of a tool execution to its MCP form. Every failure,
+// including protocol-level ones, is reported with IsError set; callers that can return
+// JSON-RPC errors check toolFailure first. Output is sent as one content block, or Parts
+// as one block each, followed by the System message in a block of its own.
+func (s *Server) toolCallResult(result *tools.ToolResult, err error) ToolsCallResult {
+	var texts []string
+	meta := make(map[string]interface{})
+
+	switch {
+	case err != nil:
+		texts = append(texts, fmt.Sprintf("Error executing tool: %v", err))
+	case result.IsError():
+		texts = append(texts, result.Err().Message)
+	case len(result.Parts) > 0:
+		for _, part := range result.Parts {
+			texts = append(texts, tools.MarshalOutput(s.logger, part))
+		}
+	case result.Output != nil:
+		texts = append(texts, tools.MarshalOutput(s.logger, result.Output))
+	case result.System == nil && result.SystemInfo == nil:
+		// Fallback to JSON marshaling the entire result
+		resultBytes, err := json.Marshal(result)
+		if err != nil {
+			texts = append(texts, "Error serializing result")
+		} else {
+			texts = append(texts, string(resultBytes))
+		}
+	}
+	if err == nil && !result.IsError() && (result.System != nil || result.SystemInfo != nil) {
+		texts = append(texts, result.SystemMessage())
+	}
+
+	failure := toolFailure(result, err)
+	if failure != nil {
+		errorMeta := map[string]interface{}{"code": failure.Code}
+		if failure.Data != nil {
+			errorMeta["data"] = failure.Data
+		}
+		meta[ToolErrorMetaKey] = errorMeta
+	}
+	if err == nil && result.SystemInfo != nil && len(result.SystemInfo.Metadata) > 0 {
+		meta[ToolSystemMetaKey] = result.SystemInfo.Metadata
+	}
+
+	callResult := ToolsCallResult{IsError: failure != nil}
+	for _, text := range texts {
+		callResult.Content = append(callResult.Content, ContentBlock{Type: "text", Text: text})
+	}
+	if len(meta) > 0 {
+		callResult.Meta = meta
+	}
+	return callResult
+}
@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func TestToolCallResultClassification(t *testing.T) {
+	results := map[string]*tools.ToolResult{
+		"quota":    {ErrorInfo: &tools.Error{Code: 429, Message: "quota exceeded", Data: "retry in 60s"}},
+		"bad_args": tools.ErrorResult(tools.NewInvalidParamsError("city is required")),
+		"partial": {Output: "some", SystemInfo: &tools.SystemInfo{
+			Message:  "1 of 2 branches failed",
+			Metadata: map[string]interface{}{"failedBranches": 1},
+		}},
+	}
+	var registered []tools.Tool
+	for name, result := range results {
+		registered = append(registered, &mockTool{
+			name:        name,
+			description: "Returns a fixed result",
+			parameters:  map[string]interface{}{"type": "object"},
+			result:      result,
+		})
+	}
+	handler := NewJSONRPCHandler(NewServer(ServerConfig{Name: "test-server", Tools: registered}))
+	call := func(name string) *JSONRPCResponse {
+		resp, err := handler.HandleMessage(context.Background(), []byte(
+			`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"`+name+`","arguments":{}}}`))
+		if err != nil {
+			t.Fatalf("HandleMessage failed: %v", err)
+		}
+		return resp
+	}
+
+	quota := call("quota").Result.(ToolsCallResult)
+	errorMeta, _ := json.Marshal(quota.Meta[ToolErrorMetaKey])
+	if !quota.IsError || quota.Content[0].Text != "quota exceeded" || string(errorMeta) != `{"code":429,"data":"retry in 60s"}` {
+		t.Errorf("expected a failed result keeping its code, got %+v", quota)
+	}
+
+	if resp := call("bad_args"); resp.Error == nil || resp.Error.Code != InvalidParams {
+		t.Errorf("expected a protocol-range code to become a JSON-RPC error, got %+v", resp)
+	}
+
+	partial := call("partial").Result.(ToolsCallResult)
+	if partial.IsError || len(partial.Content) != 2 || partial.Content[0].Text != "some" ||
+		partial.Content[1].Text != "1 of 2 branches failed" || partial.Meta[ToolSystemMetaKey] == nil {
+		t.Errorf("expected output followed by the system message, with system metadata, got %+v", partial)
+	}
+}
+
+func TestToolCallResult_FanOut(t *testing.T) {
+	results, _ := tools.FanOut(context.Background(), tools.FanOutOptions{},
+		tools.Branch[int]{Name: "one", Run: func(ctx context.Context) (int, error) { return 1, nil }},
+		tools.Branch[int]{Name: "fails", Run: func(ctx context.Context) (int, error) { return 0, errors.New("nope") }},
+	)
+	server := NewServer(ServerConfig{Name: "test-server"})
+
+	got := server.toolCallResult(tools.FanOutResult(results), nil)
+	data, _ := json.Marshal(got)
+	want := `{"content":[` +
+		`{"type":"text","text":"{\"name\":\"one\",\"output\":1}"},` +
+		`{"type":"text","text":"{\"name\":\"fails\",\"error\":\"nope\"}"},` +
+		`{"type":"text","text":"1 of 2 branches failed; results are partial. Failed: fails (nope)"}],` +
+		`"_meta":{"minimcp/system":{"failedBranches":1,"totalBranches":2}}}`
+	if string(data) != want {
+		t.Errorf("unexpected serialized result:\n got %s\nwant %s", data, want)
+	}
+
+	// The summary is sent even when every branch succeeded
+	got = server.toolCallResult(tools.FanOutResult(results[:1]), nil)
+	if len(got.Content) != 2 || got.Content[1].Text != "All 1 branches succeeded" {
+		t.Errorf("expected the branch and the summary, got %+v", got.Content)
+	}
+}
@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// scheduler serializes calls that share a key: the name of a sequential tool, or a
+// resource key declared with tools.WithResourceKey. Calls without keys are not queued.
+type scheduler struct {
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+// scheduleKeys returns the keys a call of spec must hold, sorted so that calls needing
+// several keys acquire them in the same order and cannot deadlock
+func scheduleKeys(spec *tools.ToolSpec) []string {
+	var keys []string
+	if spec.Sequential {
+		keys = append(keys, "tool:"+spec.Name)
+	}
+	for _, key := range spec.ResourceKeys {
+		keys = append(keys, "resource:"+key)
+	}
+	sort.Strings(keys)
+
+	unique := keys[:0]
+	for i, key := range keys {
+		if i == 0 || key != keys[i-1] {
+			unique = append(unique, key)
+		}
+	}
+	return unique
+}
+
+// slot returns the semaphore for key, creating it
+func (s *scheduler) slot(key string) chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.slots == nil {
+		s.slots = make(map[string]chan struct{})
+	}
+	slot, ok := s.slots[key]
+	if !ok {
+		slot = make(chan struct{}, 1)
+		s.slots[key] = slot
+	}
+	return slot
+}
+
+// acquire waits until the call may run, holding every key of spec, and returns the
+// function that releases them. It gives up with ctx's error when ctx ends first.
+func (s *scheduler) acquire(ctx context.Context, spec *tools.ToolSpec) (release func(), err error) {
+	keys := scheduleKeys(spec)
+	held := make([]chan struct{}, 0, len(keys))
+	release = func() {
+		for _, slot := range held {
+			<-slot
+		}
+	}
+
+	for _, key := range keys {
+		slot := s.slot(key)
+		select {
+		case slot <- struct{}{}:
+			held = append(held, slot)
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
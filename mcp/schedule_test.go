@@ -0,0 +1,153 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// gatedTool returns a tool that reports each call on entered and returns once release
+// is closed, ignoring its context
+func gatedTool(name string, entered chan<- string, release <-chan struct{}, opts ...tools.ToolOption) tools.Tool {
+	return tools.NewTool(name, "Waits for release", func(ctx context.Context, in struct{}) (string, error) {
+		entered <- name
+		<-release
+		return "done", nil
+	}, opts...)
+}
+
+// expectEntered waits for n calls to enter, failing if they do not within a second
+func expectEntered(t *testing.T, entered <-chan string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-entered:
+		case <-time.After(time.Second):
+			t.Fatalf("expected %d calls to run, only %d did", n, i)
+		}
+	}
+}
+
+// expectQueued fails if a call enters within a short wait
+func expectQueued(t *testing.T, entered <-chan string) {
+	t.Helper()
+	select {
+	case name := <-entered:
+		t.Fatalf("expected calls to be queued, but %s ran", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func runCalls(server *Server, tool tools.Tool, n int) chan error {
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := server.executeTool(context.Background(), tool, json.RawMessage(`{}`))
+			errs <- err
+		}()
+	}
+	return errs
+}
+
+func TestScheduler_SequentialTool(t *testing.T) {
+	entered := make(chan string, 10)
+	release := make(chan struct{})
+	migrate := gatedTool("migrate", entered, release, tools.WithSequential(true))
+	search := gatedTool("search", entered, release)
+	server := NewServer(ServerConfig{Name: "test-server", Tools: []tools.Tool{migrate, search}})
+
+	migrations := runCalls(server, migrate, 2)
+	searches := runCalls(server, search, 2)
+
+	// One migration and both searches run at once; the second migration waits
+	expectEntered(t, entered, 3)
+	expectQueued(t, entered)
+
+	close(release)
+	expectEntered(t, entered, 1)
+	for i := 0; i < 2; i++ {
+		if err := <-migrations; err != nil {
+			t.Error(err)
+		}
+		if err := <-searches; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestScheduler_SharedResourceKey(t *testing.T) {
+	entered := make(chan string, 10)
+	release := make(chan struct{})
+	write := gatedTool("write_row", entered, release, tools.WithResourceKey("db"))
+	drop := gatedTool("drop_table", entered, release, tools.WithResourceKey("db", "schema"))
+	server := NewServer(ServerConfig{Name: "test-server", Tools: []tools.Tool{write, drop}})
+
+	writes := runCalls(server, write, 1)
+	expectEntered(t, entered, 1)
+	drops := runCalls(server, drop, 1)
+	expectQueued(t, entered)
+
+	close(release)
+	expectEntered(t, entered, 1)
+	if err := <-writes; err != nil {
+		t.Error(err)
+	}
+	if err := <-drops; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScheduler_CancelWhileQueued(t *testing.T) {
+	entered := make(chan string, 10)
+	release := make(chan struct{})
+	defer close(release)
+	migrate := gatedTool("migrate", entered, release, tools.WithSequential(true))
+	server := NewServer(ServerConfig{Name: "test-server", Tools: []tools.Tool{migrate}})
+
+	runCalls(server, migrate, 1)
+	expectEntered(t, entered, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := server.executeTool(ctx, migrate, json.RawMessage(`{}`)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the queued call to give up with its context, got %v", err)
+	}
+}
+
+func TestScheduler_AbandonedCallKeepsItsPlace(t *testing.T) {
+	entered := make(chan string, 10)
+	release := make(chan struct{})
+	migrate := gatedTool("migrate", entered, release, tools.WithSequential(true), tools.WithTimeout(20*time.Millisecond))
+	server := NewServer(ServerConfig{Name: "test-server", Tools: []tools.Tool{migrate}})
+
+	// The first call times out, but the tool is still running
+	if _, err := server.executeTool(context.Background(), migrate, json.RawMessage(`{}`)); !errors.Is(err, ErrToolTimeout) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	expectEntered(t, entered, 1)
+
+	second := runCalls(server, migrate, 1)
+	expectQueued(t, entered)
+
+	close(release)
+	expectEntered(t, entered, 1)
+	if err := <-second; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScheduleKeys(t *testing.T) {
+	spec := &tools.ToolSpec{Name: "sync", Sequential: true, ResourceKeys: []string{"repo", "db", "repo"}}
+	want := []string{"resource:db", "resource:repo", "tool:sync"}
+	if got := scheduleKeys(spec); !reflect.DeepEqual(got, want) {
+		t.Errorf("scheduleKeys = %v, want %v", got, want)
+	}
+	if got := scheduleKeys(&tools.ToolSpec{Name: "free"}); len(got) != 0 {
+		t.Errorf("expected no keys for a concurrent tool, got %v", got)
+	}
+}
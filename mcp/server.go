@@ -29,7 +29,10 @@
 //
 // For remote access via HTTP:
 //
-//	validator := mcp.NewDEVKeyValidator() // or implement your own
+//	validator, err := mcp.NewStaticValidatorFromEnv("MCP_API_KEY") // or a hashed, JWT, or custom validator
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
 //	httpTransport := mcp.NewHTTPTransport(server, logger, validator)
 //	httpTransport.Start(ctx, "8080")
 //
@@ -45,50 +48,289 @@
 package mcp
 
 import (
+	"fmt"
+	"github.com/mhpenta/minimcp/buildinfo"
+	"github.com/mhpenta/minimcp/metrics"
 	"github.com/mhpenta/minimcp/tools"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Server represents an MCP server that exposes tools
 type Server struct {
 	name    string
 	version string
-	tools   []tools.Tool
 	logger  *slog.Logger
+
+	toolsMu  sync.RWMutex
+	tools    []tools.Tool
+	registry toolRegistry
+
+	toolsetsMu sync.Mutex
+	toolsets   map[string]*toolsetState
+
+	mountsMu sync.Mutex
+	mounts   map[string]*mount
+
+	resourcesMu       sync.RWMutex
+	resourceTemplates []resourceTemplate
+	resourceProviders []ResourceProvider
+
+	resourceListeners    map[int]func(uri string)
+	nextResourceListener int
+
+	promptsMu sync.RWMutex
+	prompts   []Prompt
+
+	// capabilityRevision changes whenever prompts or resources are added, so cached
+	// initialize results advertising the old capabilities are rebuilt
+	capabilityRevision atomic.Uint64
+
+	coverage *ArgumentCoverage
+	auditLog AuditLog
+
+	defaultToolTimeout    time.Duration
+	maxToolTimeout        time.Duration
+	maxConcurrentRequests int
+	requestBudget         tools.BudgetLimits
+	uploads               UploadStore
+	secrets               tools.SecretProvider
+	resultCache           *tools.ResultCache
+	idGenerator           IDGenerator
+	requestIDs            bool
+	minifySchemas         bool
+	maxDescriptionTokens  int
+	maxResourceReadBytes  int64
+	clientProfiles        map[string]ClientProfile
+	metrics               *metrics.Metrics
+	tracer                trace.Tracer
+	propagator            propagation.TextMapPropagator
+
+	active    activeCalls
+	scheduler scheduler
+	stats     toolStats
+	health    healthChecks
+	started   time.Time
 }
 
 // ServerConfig holds configuration for the MCP server
 type ServerConfig struct {
 	Name    string
-	Version string
+	Version string // Defaults to the binary's build version (see package buildinfo)
 	Tools   []tools.Tool
 	Logger  *slog.Logger
+
+	// Toolsets registers groups of tools under prefixed names, such as "fs.read". Enabled
+	// toolsets are listed alongside Tools; Server.EnableToolset and Server.DisableToolset
+	// switch them at runtime.
+	Toolsets []*tools.Toolset
+
+	// ArgumentCoverage, when set, records which argument fields callers
+	// populate on every tools/call. Leave nil to disable.
+	ArgumentCoverage *ArgumentCoverage
+
+	// AuditLog, when set, receives a record of every tools/call so calls can be
+	// inspected and replayed with Server.Replay or MethodAuditReplay. Leave nil to disable.
+	AuditLog AuditLog
+
+	// DefaultToolTimeout bounds the execution time of tools that do not set their
+	// own timeout via tools.WithTimeout. Zero means no limit.
+	DefaultToolTimeout time.Duration
+
+	// MaxToolTimeout bounds the timeouts clients ask for in tools/call, through _meta.timeoutMs
+	// or params.timeoutMs. Zero lets clients shorten a tool's timeout but not extend it.
+	MaxToolTimeout time.Duration
+
+	// MaxConcurrentRequests limits how many requests the stdio transport processes at once.
+	// Zero or one processes requests serially in arrival order; higher values let slow tool
+	// calls run alongside others, with responses written as each completes.
+	MaxConcurrentRequests int
+
+	// RequestBudget limits the sub-calls and bytes a single tools/call may consume. Nested
+	// calls through tools.Call, Server.CallTool, routers, mounted servers, gateway upstreams,
+	// OpenAPI invokers and web search each draw from it. The zero value disables budgeting.
+	RequestBudget tools.BudgetLimits
+
+	// Uploads, when set, enables the HTTP upload endpoint (POST /mcp/uploads) so clients can
+	// send large inputs out-of-band and pass the returned handle in tools/call arguments.
+	// Tools read them with tools.OpenUpload.
+	Uploads UploadStore
+
+	// Secrets, when set, serves the secrets tools read with tools.Secret, such as API keys.
+	// Use tools.EnvSecrets or tools.FileSecrets, or tools.MapSecrets in tests.
+	Secrets tools.SecretProvider
+
+	// ResultCache, when set, returns the previous result of an identical call to a tool
+	// marked with tools.WithCacheable instead of running it again. Streaming tools are
+	// never cached.
+	ResultCache *tools.ResultCache
+
+	// RequestIDs gives every request a correlation ID: the client's, from params._meta.requestId
+	// or the HTTP X-Request-ID header, or a generated UUID. The ID is added to the request's
+	// log lines as "request_id", read by tools with mcpctx.RequestID, and echoed in the
+	// _meta of tools/call and tools/list results.
+	RequestIDs bool
+
+	// IDGenerator produces the IDs of requests the server sends to clients.
+	// Defaults to UUIDGenerator; use NewMonotonicIDGenerator for deterministic IDs in tests.
+	IDGenerator IDGenerator
+
+	// MinifySchemas shrinks the schemas sent in tools/list by dropping titles, inlining
+	// single-use definitions, and collapsing redundant allOf (see infer.Minify)
+	MinifySchemas bool
+
+	// MaxDescriptionTokens caps each tool description in tools/list (estimated at four
+	// characters per token). Longer descriptions are cut and point to a resource holding
+	// the full text, readable via resources/read. Zero means no limit.
+	MaxDescriptionTokens int
+
+	// ResourceTemplates are listed in resources/templates/list, and resolve resources/read
+	// of URIs matching their RFC 6570 templates. Server.AddResourceTemplates registers more.
+	ResourceTemplates []ResourceTemplate
+
+	// ResourceProviders serve concrete resources, listed in resources/list and read before
+	// templates. Server.AddResourceProviders registers more.
+	ResourceProviders []ResourceProvider
+
+	// Prompts are listed in prompts/list and rendered by prompts/get. Server.AddPrompts
+	// registers more.
+	Prompts []Prompt
+
+	// MaxResourceReadBytes caps the bytes of a binary resource returned by one resources/read.
+	// Longer blobs are cut and report their size under ResourceRangeMetaKey, so clients can
+	// fetch the rest with the range param. Zero means no limit.
+	MaxResourceReadBytes int64
+
+	// ClientProfiles adjusts tools/list ordering per client, keyed by the client name sent
+	// in initialize (matched case-insensitively). Tools are otherwise ordered by their
+	// priority (tools.WithPriority), then registration order.
+	ClientProfiles map[string]ClientProfile
+
+	// HealthChecks are run by /mcp/health and /mcp/ready, keyed by name; a failing check
+	// makes both return 503. Server.AddHealthCheck registers more.
+	HealthChecks map[string]HealthCheck
+
+	// Metrics, when set, records request counts, tool calls, latencies, and in-flight
+	// gauges. The HTTP transport serves them in the Prometheus text format on /metrics.
+	Metrics *metrics.Metrics
+
+	// TracerProvider creates the spans recorded for requests, tool calls, and HTTP requests.
+	// Defaults to the global provider, which records nothing until one is installed.
+	TracerProvider trace.TracerProvider
+
+	// Propagator extracts trace context from HTTP headers and request _meta.
+	// Defaults to the global propagator.
+	Propagator propagation.TextMapPropagator
 }
 
-// NewServer creates a new MCP server with the provided tools
+// NewServer creates a new MCP server with the provided tools. Invalid resource templates,
+// resource providers, or prompts in cfg are logged and not registered; use
+// NewServerWithError to fail instead.
 func NewServer(cfg ServerConfig) *Server {
+	server, _ := newServer(cfg, false)
+	return server
+}
+
+// NewServerWithError creates a new MCP server like NewServer, but returns an error when cfg
+// holds invalid resource templates, resource providers, or prompts.
+//
+// Example:
+//
+//	server, err := mcp.NewServerWithError(cfg)
+//	if err != nil {
+//	    return fmt.Errorf("failed to create server: %w", err)
+//	}
+func NewServerWithError(cfg ServerConfig) (*Server, error) {
+	return newServer(cfg, true)
+}
+
+// newServer creates the server cfg describes. With strict, invalid resource templates,
+// resource providers, and prompts are an error; otherwise they are logged and skipped.
+func newServer(cfg ServerConfig, strict bool) (*Server, error) {
 	if cfg.Logger == nil {
 		cfg.Logger = slog.Default()
 	}
+	if cfg.IDGenerator == nil {
+		cfg.IDGenerator = UUIDGenerator{}
+	}
+	if cfg.Propagator == nil {
+		cfg.Propagator = otel.GetTextMapPropagator()
+	}
+	build := buildinfo.Get()
+	if cfg.Version == "" {
+		cfg.Version = build.Version
+	}
 
 	server := &Server{
 		name:    cfg.Name,
 		version: cfg.Version,
 		tools:   cfg.Tools,
 		logger:  cfg.Logger,
+
+		coverage: cfg.ArgumentCoverage,
+		auditLog: cfg.AuditLog,
+
+		defaultToolTimeout:    cfg.DefaultToolTimeout,
+		maxToolTimeout:        cfg.MaxToolTimeout,
+		maxConcurrentRequests: cfg.MaxConcurrentRequests,
+		requestBudget:         cfg.RequestBudget,
+		uploads:               cfg.Uploads,
+		secrets:               cfg.Secrets,
+		resultCache:           cfg.ResultCache,
+		idGenerator:           cfg.IDGenerator,
+		requestIDs:            cfg.RequestIDs,
+		minifySchemas:         cfg.MinifySchemas,
+		maxDescriptionTokens:  cfg.MaxDescriptionTokens,
+		maxResourceReadBytes:  cfg.MaxResourceReadBytes,
+		clientProfiles:        cfg.ClientProfiles,
+		metrics:               cfg.Metrics,
+		tracer:                newTracer(cfg.TracerProvider, cfg.Version),
+		propagator:            cfg.Propagator,
+		started:               time.Now(),
+	}
+	server.stats.since = server.started
+	server.registerToolsets(cfg.Toolsets)
+	for name, check := range cfg.HealthChecks {
+		server.AddHealthCheck(name, check)
+	}
+	for _, add := range []struct {
+		what string
+		err  error
+	}{
+		{"resource templates", server.AddResourceTemplates(cfg.ResourceTemplates...)},
+		{"resource providers", server.AddResourceProviders(cfg.ResourceProviders...)},
+		{"prompts", server.AddPrompts(cfg.Prompts...)},
+	} {
+		if add.err == nil {
+			continue
+		}
+		if strict {
+			return nil, fmt.Errorf("invalid %s: %w", add.what, add.err)
+		}
+		server.logger.Error("ignoring invalid "+add.what, "error", add.err)
 	}
 
 	server.logger.Info("initialized MCP server",
 		"name", cfg.Name,
 		"version", cfg.Version,
-		"tool_count", len(cfg.Tools))
+		"commit", build.Commit,
+		"build_date", build.Date,
+		"go_version", build.GoVersion,
+		"tool_count", len(server.tools))
 
-	return server
+	return server, nil
 }
 
 // GetTools returns all registered tools
 func (s *Server) GetTools() []tools.Tool {
-	return s.tools
+	s.toolsMu.RLock()
+	defer s.toolsMu.RUnlock()
+	return append([]tools.Tool(nil), s.tools...)
 }
 
 // Name returns the server name
@@ -100,3 +342,8 @@ func (s *Server) Name() string {
 func (s *Server) Version() string {
 	return s.version
 }
+
+// ArgumentCoverage returns the coverage recorder configured for the server, or nil
+func (s *Server) ArgumentCoverage() *ArgumentCoverage {
+	return s.coverage
+}
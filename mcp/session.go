@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/mhpenta/minimcp/tools/mcpctx"
+)
+
+type sessionKey struct{}
+
+// session identifies the client a request belongs to
+type session struct {
+	id string
+
+	// stateful is set when the identity persists for the life of the client's session,
+	// so per-session state such as initialization can be tracked against it
+	stateful bool
+}
+
+// withSession records a session whose identity persists across requests: a stdio or
+// in-memory connection, or an HTTP session the transport issued
+func withSession(ctx context.Context, id string) context.Context {
+	ctx = mcpctx.WithSessionID(ctx, id)
+	return context.WithValue(ctx, sessionKey{}, session{id: id, stateful: true})
+}
+
+// withConnection records a best-effort identity, such as a remote address, that a client
+// may not keep across requests. It scopes request IDs but carries no per-session state.
+func withConnection(ctx context.Context, id string) context.Context {
+	ctx = mcpctx.WithSessionID(ctx, id)
+	return context.WithValue(ctx, sessionKey{}, session{id: id})
+}
+
+// sessionFrom returns the session ID recorded in ctx, or "unknown"
+func sessionFrom(ctx context.Context) string {
+	if s, ok := ctx.Value(sessionKey{}).(session); ok {
+		return s.id
+	}
+	return "unknown"
+}
+
+// hasStatefulSession reports whether ctx carries a session recorded by withSession
+func hasStatefulSession(ctx context.Context) bool {
+	s, ok := ctx.Value(sessionKey{}).(session)
+	return ok && s.stateful
+}
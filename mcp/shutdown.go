@@ -0,0 +1,134 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDrainTimeout is how long transports wait for in-flight requests on shutdown
+const DefaultDrainTimeout = 10 * time.Second
+
+// ActiveCall describes a tool execution that has not yet returned
+type ActiveCall struct {
+	Tool     string
+	Session  string
+	Started  time.Time
+	Duration time.Duration
+}
+
+// ShutdownError is returned by a transport's Start when tool calls are still running
+// after the drain deadline. It lists the calls holding the process so operators can
+// see which tool is preventing a clean restart.
+type ShutdownError struct {
+	DrainTimeout time.Duration
+	Active       []ActiveCall
+	Err          error
+}
+
+func (e *ShutdownError) Error() string {
+	calls := make([]string, len(e.Active))
+	for i, call := range e.Active {
+		calls[i] = fmt.Sprintf("%s (session %s, running %s)", call.Tool, call.Session, call.Duration.Round(time.Millisecond))
+	}
+	msg := fmt.Sprintf("shutdown did not complete within %s; %d tool call(s) still running: %s",
+		e.DrainTimeout, len(e.Active), strings.Join(calls, ", "))
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
+
+// activeCalls tracks tool executions in progress
+type activeCalls struct {
+	mu    sync.Mutex
+	next  uint64
+	calls map[uint64]ActiveCall
+}
+
+// track registers a running tool call and returns a function that removes it
+func (a *activeCalls) track(tool, session string) func() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.calls == nil {
+		a.calls = make(map[uint64]ActiveCall)
+	}
+	id := a.next
+	a.next++
+	a.calls[id] = ActiveCall{Tool: tool, Session: session, Started: time.Now()}
+
+	return func() {
+		a.mu.Lock()
+		delete(a.calls, id)
+		a.mu.Unlock()
+	}
+}
+
+// snapshot returns the running calls, oldest first
+func (a *activeCalls) snapshot() []ActiveCall {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	calls := make([]ActiveCall, 0, len(a.calls))
+	for _, call := range a.calls {
+		call.Duration = now.Sub(call.Started)
+		calls = append(calls, call)
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].Started.Before(calls[j].Started) })
+	return calls
+}
+
+// ActiveCalls returns the tool calls currently executing, oldest first
+func (s *Server) ActiveCalls() []ActiveCall {
+	return s.active.snapshot()
+}
+
+// shutdownError logs the calls still running after the drain deadline and
+// returns them as a ShutdownError wrapping cause. It returns cause unchanged
+// when no tool call is running.
+func (s *Server) shutdownError(drainTimeout time.Duration, cause error) error {
+	active := s.ActiveCalls()
+	if len(active) == 0 {
+		return cause
+	}
+	for _, call := range active {
+		s.logger.Error("tool still running at shutdown",
+			"tool", call.Tool,
+			"session", call.Session,
+			"duration", call.Duration)
+	}
+	return &ShutdownError{DrainTimeout: drainTimeout, Active: active, Err: cause}
+}
+
+// waitDrained waits for wg, giving up after timeout. A non-positive timeout waits indefinitely.
+func (s *Server) waitDrained(wg *sync.WaitGroup, timeout time.Duration) error {
+	if timeout <= 0 {
+		wg.Wait()
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return nil
+	case <-timer.C:
+		return s.shutdownError(timeout, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,141 @@
+package mcp
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// statsSamples is how many recent latencies each tool keeps for its percentiles
+const statsSamples = 1024
+
+// Stats summarizes the tool calls a server has executed
+type Stats struct {
+	Since time.Time   `json:"since"` // when the server started recording
+	Tools []ToolStats `json:"tools"` // by tool name
+}
+
+// ToolStats summarizes the calls of one tool. Latency percentiles are computed over the
+// most recent 1024 calls.
+type ToolStats struct {
+	Tool       string    `json:"tool"`
+	Calls      int64     `json:"calls"`
+	Errors     int64     `json:"errors"`
+	InFlight   int64     `json:"inFlight"`
+	P50Ms      float64   `json:"p50Ms"`
+	P90Ms      float64   `json:"p90Ms"`
+	P99Ms      float64   `json:"p99Ms"`
+	MaxMs      float64   `json:"maxMs"`
+	LastCalled time.Time `json:"lastCalled"`
+}
+
+// toolStats records per-tool call counts and recent latencies
+type toolStats struct {
+	mu    sync.Mutex
+	since time.Time
+	tools map[string]*toolRecord
+}
+
+type toolRecord struct {
+	calls, errors, inFlight int64
+	maxMs                   float64
+	lastCalled              time.Time
+	samples                 []float64 // ring buffer of latencies in milliseconds
+	next                    int
+}
+
+// start marks a call of tool as running and returns a function recording its outcome
+func (s *toolStats) start(tool string) func(failed bool) {
+	started := time.Now()
+	s.mu.Lock()
+	record := s.record(tool)
+	record.inFlight++
+	record.lastCalled = started
+	s.mu.Unlock()
+
+	return func(failed bool) {
+		ms := float64(time.Since(started).Microseconds()) / 1000
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		record.inFlight--
+		record.calls++
+		if failed {
+			record.errors++
+		}
+		record.maxMs = math.Max(record.maxMs, ms)
+		if len(record.samples) < statsSamples {
+			record.samples = append(record.samples, ms)
+		} else {
+			record.samples[record.next] = ms
+			record.next = (record.next + 1) % statsSamples
+		}
+	}
+}
+
+// record returns the record for tool, creating it. Callers hold s.mu.
+func (s *toolStats) record(tool string) *toolRecord {
+	if s.tools == nil {
+		s.tools = make(map[string]*toolRecord)
+	}
+	record, ok := s.tools[tool]
+	if !ok {
+		record = &toolRecord{}
+		s.tools[tool] = record
+	}
+	return record
+}
+
+func (s *toolStats) snapshot() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := Stats{Since: s.since, Tools: make([]ToolStats, 0, len(s.tools))}
+	for name, record := range s.tools {
+		sorted := append([]float64(nil), record.samples...)
+		sort.Float64s(sorted)
+		stats.Tools = append(stats.Tools, ToolStats{
+			Tool:       name,
+			Calls:      record.calls,
+			Errors:     record.errors,
+			InFlight:   record.inFlight,
+			P50Ms:      percentile(sorted, 50),
+			P90Ms:      percentile(sorted, 90),
+			P99Ms:      percentile(sorted, 99),
+			MaxMs:      record.maxMs,
+			LastCalled: record.lastCalled,
+		})
+	}
+	sort.Slice(stats.Tools, func(i, j int) bool { return stats.Tools[i].Tool < stats.Tools[j].Tool })
+	return stats
+}
+
+// percentile returns the nearest-rank percentile p of sorted values, or zero when empty
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// GetStats returns the call counts, error counts, and latency percentiles of every tool
+// called since the server started. The HTTP transport serves them on /mcp/stats.
+func (s *Server) GetStats() Stats {
+	return s.stats.snapshot()
+}
+
+// handleStats serves the server's tool statistics as JSON
+func (t *HTTPTransport) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t.server.GetStats())
+}
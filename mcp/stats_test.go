@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func TestGetStats(t *testing.T) {
+	type flakyInput struct {
+		Fail bool `json:"fail"`
+	}
+	flaky := tools.NewTool("flaky", "Fails on request", func(ctx context.Context, in flakyInput) (string, error) {
+		if in.Fail {
+			return "", errors.New("failed")
+		}
+		return "ok", nil
+	})
+	idle := tools.NewTool("idle", "Never called", func(ctx context.Context, in struct{}) (string, error) {
+		return "", nil
+	})
+	server := NewServer(ServerConfig{
+		Name:   "test-server",
+		Tools:  []tools.Tool{flaky, idle},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	client := startInMemory(t, server)
+	ctx := context.Background()
+
+	for i, fail := range []bool{false, true, false} {
+		client.Send(ctx, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":"flaky","arguments":{"fail":%t}}}`, i+1, fail)))
+		receiveResponse(t, client)
+	}
+
+	stats := server.GetStats()
+	if len(stats.Tools) != 1 {
+		t.Fatalf("expected stats for the called tool only, got %+v", stats.Tools)
+	}
+	got := stats.Tools[0]
+	if got.Tool != "flaky" || got.Calls != 3 || got.Errors != 1 || got.InFlight != 0 {
+		t.Errorf("unexpected stats %+v", got)
+	}
+	if got.LastCalled.IsZero() || got.P50Ms > got.P99Ms || got.P99Ms > got.MaxMs {
+		t.Errorf("unexpected latencies %+v", got)
+	}
+	if stats.Since.IsZero() {
+		t.Error("expected the recording start time")
+	}
+}
+
+func TestToolStatsSampleWindow(t *testing.T) {
+	var stats toolStats
+	for i := 0; i < statsSamples+10; i++ {
+		stats.start("tool")(false)
+	}
+	record := stats.tools["tool"]
+	if record.calls != statsSamples+10 || len(record.samples) != statsSamples || record.next != 10 {
+		t.Errorf("expected a full window after wrapping, got calls=%d samples=%d next=%d", record.calls, len(record.samples), record.next)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		p    float64
+		want float64
+	}{{50, 5}, {90, 9}, {99, 10}, {0, 1}}
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.p); got != tt.want {
+			t.Errorf("percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("expected 0 for no samples, got %v", got)
+	}
+}
+
+func TestHTTPTransport_Stats(t *testing.T) {
+	echo := tools.NewTool("echo", "Echoes", func(ctx context.Context, in struct{}) (string, error) {
+		return "ok", nil
+	})
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(ServerConfig{Name: "test-server", Tools: []tools.Tool{echo}, Logger: logger})
+	transport := NewHTTPTransport(server, logger, newMockValidator("test-key"))
+	if _, err := server.executeTool(context.Background(), echo, json.RawMessage(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	transport.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mcp/stats", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected stats to require auth, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/mcp/stats", nil)
+	req.Header.Set("Authorization", "Bearer test-key")
+	rec = httptest.NewRecorder()
+	transport.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var stats Stats
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Tools) != 1 || stats.Tools[0].Tool != "echo" || stats.Tools[0].Calls != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
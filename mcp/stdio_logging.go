@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// RedirectLogs sends the default slog logger and the standard library's log package to w,
+// and returns a logger writing to w for ServerConfig.Logger. Output of the log package
+// goes through the logger at the info level.
+//
+// Stdout carries the protocol of a stdio server, so a stray log line there corrupts it.
+// Call RedirectLogs(os.Stderr, nil), or pass a log file, before starting the transport.
+// Under Claude Desktop, stderr is captured in the host's MCP log files.
+func RedirectLogs(w io.Writer, opts *slog.HandlerOptions) *slog.Logger {
+	logger := slog.New(slog.NewTextHandler(w, opts))
+	// SetDefault also routes the log package through the handler
+	slog.SetDefault(logger)
+	return logger
+}
+
+// WithProtocolLog copies every message the transport reads and writes to w, one line per
+// message, for troubleshooting a client. Lines hold a timestamp, the direction ("recv" or
+// "send"), and the message:
+//
+//	2025-01-15T10:30:00.123Z recv {"jsonrpc":"2.0","id":1,"method":"tools/list"}
+//
+// Messages contain tool arguments and results, so protect the log accordingly.
+func (t *StdioTransport) WithProtocolLog(w io.Writer) *StdioTransport {
+	t.protocolLog = &protocolLog{w: w}
+	return t
+}
+
+// protocolLog writes the messages of a connection to a log, one line per message
+type protocolLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// record logs a message in the given direction. A nil log records nothing.
+func (p *protocolLog) record(direction string, message []byte) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.w, "%s %s %s\n", time.Now().UTC().Format("2006-01-02T15:04:05.000Z07:00"), direction, message)
+}
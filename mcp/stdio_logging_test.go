@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedirectLogs(t *testing.T) {
+	previousLogger, previousOutput := slog.Default(), log.Writer()
+	defer func() {
+		slog.SetDefault(previousLogger)
+		log.SetOutput(previousOutput)
+	}()
+
+	var buf bytes.Buffer
+	logger := RedirectLogs(&buf, nil)
+	log.Print("from the log package")
+	slog.Info("from the default logger")
+	logger.Debug("below the level")
+
+	out := buf.String()
+	if !strings.Contains(out, "from the log package") || !strings.Contains(out, "from the default logger") {
+		t.Errorf("expected both log packages to write to the writer, got %q", out)
+	}
+	if strings.Contains(out, "below the level") {
+		t.Errorf("expected the level to apply, got %q", out)
+	}
+}
+
+func TestStdioTransport_ProtocolLog(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server", Version: "1.0.0"})
+	input := bytes.NewBufferString(`{"jsonrpc":"2.0","id":1,"method":"ping"}` + "\n" + strings.Repeat("x", 100) + "\n")
+	var output, protocol bytes.Buffer
+	transport := NewStdioTransportWithIO(server, nil, input, &output).
+		WithMaxMessageBytes(64).
+		WithProtocolLog(&protocol)
+
+	if err := transport.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(protocol.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected two messages each way, got %q", protocol.String())
+	}
+	want := map[string]bool{
+		` recv {"jsonrpc":"2.0","id":1,"method":"ping"}`: false,
+		` send {"jsonrpc":"2.0","id":1,"result":{}}`:     false,
+		` recv <discarded 101 bytes>`:                    false,
+	}
+	for _, line := range lines {
+		for suffix := range want {
+			if strings.HasSuffix(line, suffix) {
+				want[suffix] = true
+			}
+		}
+	}
+	for suffix, found := range want {
+		if !found {
+			t.Errorf("expected a line ending %q, got %q", suffix, protocol.String())
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// MethodNotificationPartialResult carries a chunk of a streaming tool's output while the
+// tools/call is still running. The final response still holds the complete output.
+const MethodNotificationPartialResult = "notifications/x-minimcp/partial"
+
+// PartialResultParams are the params of MethodNotificationPartialResult
+type PartialResultParams struct {
+	// RequestID is the ID of the tools/call the chunk belongs to
+	RequestID interface{} `json:"requestId"`
+
+	// Index numbers the chunks of a call from zero
+	Index int `json:"index"`
+
+	Content []ContentBlock `json:"content"`
+}
+
+// notifyFunc sends a notification to the client of the current request
+type notifyFunc func(JSONRPCNotification) error
+
+type notifierKey struct{}
+type requestIDKey struct{}
+
+// withNotifier attaches a way to push notifications to the client; transports that
+// cannot push leave it unset
+func withNotifier(ctx context.Context, notify notifyFunc) context.Context {
+	return context.WithValue(ctx, notifierKey{}, notify)
+}
+
+func notifierFrom(ctx context.Context) notifyFunc {
+	notify, _ := ctx.Value(notifierKey{}).(notifyFunc)
+	return notify
+}
+
+// withRequestID records the JSON-RPC ID of the request being processed
+func withRequestID(ctx context.Context, id interface{}) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func requestIDFrom(ctx context.Context) interface{} {
+	return ctx.Value(requestIDKey{})
+}
+
+// chunkEmitter returns an emit function forwarding chunks to the client as partial result
+// notifications, or nil when the transport cannot push messages
+func chunkEmitter(ctx context.Context) tools.EmitFunc {
+	notify, id := notifierFrom(ctx), requestIDFrom(ctx)
+	if notify == nil || id == nil {
+		return nil
+	}
+
+	var mu sync.Mutex
+	index := 0
+	return func(chunk string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		params, err := json.Marshal(PartialResultParams{
+			RequestID: id,
+			Index:     index,
+			Content:   []ContentBlock{{Type: "text", Text: chunk}},
+		})
+		if err != nil {
+			return err
+		}
+		index++
+		return notify(JSONRPCNotification{JSONRPC: "2.0", Method: MethodNotificationPartialResult, Params: params})
+	}
+}
+
+// sseWriter writes JSON-RPC messages as server-sent events, as the streamable HTTP
+// transport does when a response is preceded by notifications
+type sseWriter struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// newSSEWriter switches the response to an event stream, or returns nil when the
+// client does not accept one or the connection cannot flush
+func newSSEWriter(w http.ResponseWriter, r *http.Request) *sseWriter {
+	flusher, ok := w.(http.Flusher)
+	if !ok || !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		return nil
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	return &sseWriter{w: w, flusher: flusher}
+}
+
+// send writes v as a single message event and flushes it to the client
+func (s *sseWriter) send(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := fmt.Fprintf(s.w, "event: message\ndata: %s\n\n", data); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// streamsResult reports whether a request body is a single tools/call of a streaming tool,
+// the only requests answered with an event stream
+func (s *Server) streamsResult(body []byte) bool {
+	var req struct {
+		Method string          `json:"method"`
+		Params ToolsCallParams `json:"params"`
+	}
+	if json.Unmarshal(body, &req) != nil || req.Method != MethodToolsCall {
+		return false
+	}
+	_, ok := s.findTool(req.Params.Name).(tools.StreamingTool)
+	return ok
+}
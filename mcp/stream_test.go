@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+type spellInput struct {
+	Words []string `json:"words"`
+}
+
+func newSpellServer() *Server {
+	spell := tools.NewStreamingTool("spell", "Spells words", func(ctx context.Context, in spellInput, emit tools.EmitFunc) error {
+		for _, word := range in.Words {
+			if err := emit(word); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return NewServer(ServerConfig{Name: "test-server", Tools: []tools.Tool{spell}})
+}
+
+const spellCall = `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"spell","arguments":{"words":["a","b"]}}}`
+
+func TestStreamingTool_InMemory(t *testing.T) {
+	client := startInMemory(t, newSpellServer())
+	if err := client.Send(context.Background(), []byte(spellCall)); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	for i, want := range []string{"a", "b"} {
+		msg, err := client.Receive(context.Background())
+		if err != nil {
+			t.Fatalf("Receive failed: %v", err)
+		}
+		var notification struct {
+			Method string              `json:"method"`
+			Params PartialResultParams `json:"params"`
+		}
+		if err := json.Unmarshal(msg, &notification); err != nil {
+			t.Fatalf("failed to parse notification: %v", err)
+		}
+		if notification.Method != MethodNotificationPartialResult || notification.Params.Index != i ||
+			notification.Params.Content[0].Text != want || notification.Params.RequestID != float64(7) {
+			t.Errorf("unexpected partial result %s", msg)
+		}
+	}
+
+	resp := receiveResponse(t, client)
+	if resp.Error != nil || !strings.Contains(string(mustMarshal(t, resp.Result)), `"ab"`) {
+		t.Errorf("expected final response with the full output, got %+v", resp)
+	}
+}
+
+func TestStreamingTool_SSE(t *testing.T) {
+	transport := NewHTTPTransport(newSpellServer(), slog.Default(), newMockValidator("test-key"))
+	server := httptest.NewServer(transport)
+	defer server.Close()
+
+	call := func(accept string) *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/mcp", strings.NewReader(spellCall))
+		req.Header.Set("Authorization", "Bearer test-key")
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", accept)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		return resp
+	}
+
+	resp := call("application/json, text/event-stream")
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %q", ct)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read stream: %v", err)
+	}
+	events := strings.Split(strings.TrimSpace(string(body)), "\n\n")
+	if len(events) != 3 {
+		t.Fatalf("expected two partial results and a response, got %q", body)
+	}
+	if !strings.Contains(events[0], MethodNotificationPartialResult) || !strings.Contains(events[2], `"ab"`) {
+		t.Errorf("unexpected events %q", events)
+	}
+
+	plain := call("application/json")
+	defer plain.Body.Close()
+	var final JSONRPCResponse
+	if err := json.NewDecoder(plain.Body).Decode(&final); err != nil || final.Error != nil {
+		t.Errorf("expected a plain JSON response without SSE, got %+v, %v", final, err)
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	return data
+}
@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// toolsetState tracks whether a configured toolset is currently registered
+type toolsetState struct {
+	set     *tools.Toolset
+	enabled bool
+}
+
+// registerToolsets records the configured toolsets and adds the tools of enabled ones
+func (s *Server) registerToolsets(sets []*tools.Toolset) {
+	s.toolsets = make(map[string]*toolsetState, len(sets))
+	if len(sets) > 0 {
+		// Appending must not write into the caller's ServerConfig.Tools
+		s.tools = append([]tools.Tool(nil), s.tools...)
+	}
+	for _, set := range sets {
+		s.toolsets[set.Name()] = &toolsetState{set: set, enabled: set.Enabled()}
+		if set.Enabled() {
+			s.tools = append(s.tools, set.Tools()...)
+		}
+	}
+}
+
+// EnableToolset registers the tools of a configured toolset and notifies connected
+// clients that the tool list changed. Enabling an enabled toolset does nothing.
+func (s *Server) EnableToolset(name string) error {
+	s.toolsetsMu.Lock()
+	defer s.toolsetsMu.Unlock()
+
+	state, ok := s.toolsets[name]
+	if !ok {
+		return fmt.Errorf("unknown toolset %q", name)
+	}
+	if state.enabled {
+		return nil
+	}
+	if err := s.AddTools(state.set.Tools()...); err != nil {
+		return fmt.Errorf("enabling toolset %q: %w", name, err)
+	}
+	state.enabled = true
+	return nil
+}
+
+// DisableToolset unregisters the tools of a configured toolset and notifies connected
+// clients that the tool list changed. Disabling a disabled toolset does nothing.
+func (s *Server) DisableToolset(name string) error {
+	s.toolsetsMu.Lock()
+	defer s.toolsetsMu.Unlock()
+
+	state, ok := s.toolsets[name]
+	if !ok {
+		return fmt.Errorf("unknown toolset %q", name)
+	}
+	if !state.enabled {
+		return nil
+	}
+	s.RemoveTools(state.set.ToolNames()...)
+	state.enabled = false
+	return nil
+}
+
+// ToolsetEnabled reports whether the named toolset's tools are registered
+func (s *Server) ToolsetEnabled(name string) bool {
+	s.toolsetsMu.Lock()
+	defer s.toolsetsMu.Unlock()
+
+	state, ok := s.toolsets[name]
+	return ok && state.enabled
+}
@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func TestServer_Toolsets(t *testing.T) {
+	echo := func(name string) tools.Tool {
+		return &mockTool{
+			name:        name,
+			description: "Echoes " + name,
+			parameters:  map[string]interface{}{"type": "object"},
+			result:      &tools.ToolResult{Output: name},
+		}
+	}
+	fs := tools.NewToolset("fs", echo("read"), echo("write"))
+	git := tools.NewToolset("git", echo("status")).WithEnabled(false)
+
+	server := NewServer(ServerConfig{
+		Name:     "test-server",
+		Version:  "1.0.0",
+		Tools:    []tools.Tool{echo("ping")},
+		Toolsets: []*tools.Toolset{fs, git},
+	})
+
+	names := func() []string {
+		var names []string
+		for _, tool := range server.GetTools() {
+			names = append(names, tool.Spec().Name)
+		}
+		return names
+	}
+	if got := names(); len(got) != 3 || got[0] != "ping" || got[1] != "fs.read" || got[2] != "fs.write" {
+		t.Fatalf("expected ping, fs.read and fs.write, got %v", got)
+	}
+
+	handler := NewJSONRPCHandler(server)
+	resp, _ := handler.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"fs.read","arguments":{}}}`))
+	if resp.Error != nil {
+		t.Fatalf("expected fs.read to be callable, got %v", resp.Error)
+	}
+	data, _ := json.Marshal(resp.Result)
+	if string(data) != `{"content":[{"type":"text","text":"read"}]}` {
+		t.Errorf("unexpected result: %s", data)
+	}
+
+	var revisions []uint64
+	unregister := server.OnToolsChanged(func(revision uint64) { revisions = append(revisions, revision) })
+	defer unregister()
+
+	if err := server.EnableToolset("git"); err != nil {
+		t.Fatalf("EnableToolset failed: %v", err)
+	}
+	if !server.ToolsetEnabled("git") || len(names()) != 4 {
+		t.Errorf("expected git.status to be registered, got %v", names())
+	}
+	if err := server.EnableToolset("git"); err != nil {
+		t.Errorf("expected enabling twice to succeed, got %v", err)
+	}
+
+	if err := server.DisableToolset("fs"); err != nil {
+		t.Fatalf("DisableToolset failed: %v", err)
+	}
+	if got := names(); len(got) != 2 || got[0] != "ping" || got[1] != "git.status" {
+		t.Errorf("expected ping and git.status, got %v", got)
+	}
+	if server.ToolsetEnabled("fs") {
+		t.Error("expected fs to be disabled")
+	}
+	if len(revisions) != 2 {
+		t.Errorf("expected one list change per switch, got %v", revisions)
+	}
+
+	if err := server.EnableToolset("missing"); err == nil {
+		t.Error("expected error for unknown toolset")
+	}
+	if err := server.DisableToolset("missing"); err == nil {
+		t.Error("expected error for unknown toolset")
+	}
+}
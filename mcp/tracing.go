@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the instrumentation library in emitted spans
+const tracerName = "github.com/mhpenta/minimcp/mcp"
+
+// Span attribute keys, following the OpenTelemetry MCP semantic conventions
+const (
+	attrMethodName = attribute.Key("mcp.method.name")
+	attrSessionID  = attribute.Key("mcp.session.id")
+	attrRequestID  = attribute.Key("jsonrpc.request.id")
+	attrToolName   = attribute.Key("gen_ai.tool.name")
+	attrErrorCode  = attribute.Key("rpc.jsonrpc.error_code")
+)
+
+// newTracer returns the server's tracer, falling back to the global provider
+func newTracer(tp trace.TracerProvider, version string) trace.Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tp.Tracer(tracerName, trace.WithInstrumentationVersion(version))
+}
+
+// startRequestSpan starts the span covering one JSON-RPC request. Trace context carried
+// in params._meta (traceparent, tracestate) takes precedence over ctx, so clients on
+// transports without headers, such as stdio, can still join their trace.
+func (s *Server) startRequestSpan(ctx context.Context, req JSONRPCRequest) (context.Context, trace.Span) {
+	ctx = s.propagator.Extract(ctx, metaCarrier(req.Params))
+
+	kind := trace.SpanKindServer
+	if trace.SpanContextFromContext(ctx).IsValid() && !trace.SpanContextFromContext(ctx).IsRemote() {
+		// Nested under the transport's span
+		kind = trace.SpanKindInternal
+	}
+
+	return s.tracer.Start(ctx, req.Method,
+		trace.WithSpanKind(kind),
+		trace.WithAttributes(
+			attrMethodName.String(req.Method),
+			attrSessionID.String(sessionFrom(ctx)),
+			attrRequestID.String(fmt.Sprint(req.ID)),
+		))
+}
+
+// endRequestSpan records the request's outcome on its span and ends it
+func endRequestSpan(span trace.Span, result interface{}, rpcErr *RPCError) {
+	switch {
+	case rpcErr != nil:
+		span.SetAttributes(attrErrorCode.Int(rpcErr.Code))
+		span.SetStatus(codes.Error, rpcErr.Message)
+	case isErrorResult(result):
+		span.SetStatus(codes.Error, "tool returned an error")
+	}
+	span.End()
+}
+
+// nameToolSpan labels the current request span with the tool being called
+func nameToolSpan(ctx context.Context, tool string) {
+	span := trace.SpanFromContext(ctx)
+	span.SetName(MethodToolsCall + " " + tool)
+	span.SetAttributes(attrToolName.String(tool))
+}
+
+// startToolSpan starts the span covering a single tool execution
+func (s *Server) startToolSpan(ctx context.Context, tool string) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, "execute_tool "+tool,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attrToolName.String(tool)))
+}
+
+// endToolSpan records a tool execution's outcome on its span and ends it
+func endToolSpan(span trace.Span, failed bool, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	if failed {
+		span.SetStatus(codes.Error, "tool execution failed")
+	}
+	span.End()
+}
+
+// traceHTTP extracts trace context from the request headers and starts the span covering the HTTP request
+func (t *HTTPTransport) traceHTTP(r *http.Request) (*http.Request, trace.Span) {
+	ctx := t.server.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	ctx, span := t.server.tracer.Start(ctx, r.Method+" "+r.URL.Path,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("url.path", r.URL.Path),
+		))
+	return r.WithContext(ctx), span
+}
+
+// metaCarrier reads propagation fields from a request's params._meta
+func metaCarrier(params json.RawMessage) propagation.MapCarrier {
+	if len(params) == 0 {
+		return propagation.MapCarrier{}
+	}
+	var probe struct {
+		Meta map[string]interface{} `json:"_meta"`
+	}
+	if json.Unmarshal(params, &probe) != nil {
+		return propagation.MapCarrier{}
+	}
+
+	carrier := make(propagation.MapCarrier, len(probe.Meta))
+	for k, v := range probe.Meta {
+		if s, ok := v.(string); ok {
+			carrier[k] = s
+		}
+	}
+	return carrier
+}
@@ -0,0 +1,149 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordingProvider records the spans started through it
+type recordingProvider struct {
+	embedded.TracerProvider
+
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type recordingTracer struct {
+	embedded.Tracer
+	provider *recordingProvider
+}
+
+type recordedSpan struct {
+	noop.Span
+	name   string
+	parent trace.SpanContext
+	ctx    trace.SpanContext
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+}
+
+func (p *recordingProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return recordingTracer{provider: p}
+}
+
+func (t recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+	parent := trace.SpanContextFromContext(ctx)
+
+	t.provider.mu.Lock()
+	defer t.provider.mu.Unlock()
+
+	traceID := parent.TraceID()
+	if !traceID.IsValid() {
+		traceID = trace.TraceID{byte(len(t.provider.spans) + 1)}
+	}
+	span := &recordedSpan{
+		name:   name,
+		parent: parent,
+		ctx:    trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{byte(len(t.provider.spans) + 1)}}),
+		attrs:  make(map[attribute.Key]attribute.Value),
+	}
+	span.SetAttributes(cfg.Attributes()...)
+	t.provider.spans = append(t.provider.spans, span)
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func (s *recordedSpan) SpanContext() trace.SpanContext { return s.ctx }
+func (s *recordedSpan) SetName(name string)            { s.name = name }
+func (s *recordedSpan) SetStatus(code codes.Code, _ string) {
+	s.status = code
+}
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) {
+	for _, attr := range kv {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (p *recordingProvider) find(name string) *recordedSpan {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, span := range p.spans {
+		if span.name == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func TestTracing_HTTPToolCall(t *testing.T) {
+	provider := &recordingProvider{}
+	failing := tools.NewTool("fails", "Always fails", func(ctx context.Context, in struct{}) (string, error) {
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			t.Error("expected the tool context to carry the trace")
+		}
+		return "", context.DeadlineExceeded
+	})
+	server := NewServer(ServerConfig{
+		Name:           "test-server",
+		Version:        "1.0.0",
+		Tools:          []tools.Tool{failing},
+		TracerProvider: provider,
+		Propagator:     propagation.TraceContext{},
+	})
+	transport := NewHTTPTransport(server, slog.Default(), newMockValidator("test-key"))
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"fails","arguments":{}}}`))
+	req.Header.Set("Authorization", "Bearer test-key")
+	req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	transport.ServeHTTP(httptest.NewRecorder(), req)
+
+	httpSpan := provider.find("POST /mcp")
+	if httpSpan == nil || httpSpan.parent.TraceID().String() != "0af7651916cd43dd8448eb211c80319c" {
+		t.Fatalf("expected the HTTP span to continue the incoming trace, got %+v", httpSpan)
+	}
+
+	callSpan := provider.find("tools/call fails")
+	if callSpan == nil {
+		t.Fatal("expected a tools/call span named after the tool")
+	}
+	if callSpan.parent.SpanID() != httpSpan.ctx.SpanID() {
+		t.Error("expected the tools/call span to be a child of the HTTP span")
+	}
+	if callSpan.attrs[attrToolName].AsString() != "fails" || callSpan.status != codes.Error {
+		t.Errorf("expected tool name attribute and error status, got %v %v", callSpan.attrs, callSpan.status)
+	}
+
+	if execSpan := provider.find("execute_tool fails"); execSpan == nil || execSpan.status != codes.Error {
+		t.Errorf("expected a failed tool execution span, got %+v", execSpan)
+	}
+}
+
+func TestTracing_MetaPropagation(t *testing.T) {
+	provider := &recordingProvider{}
+	server := NewServer(ServerConfig{Name: "test-server", TracerProvider: provider, Propagator: propagation.TraceContext{}})
+
+	NewJSONRPCHandler(server).HandleMessage(context.Background(), []byte(
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list","params":{"_meta":{"traceparent":"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}}}`))
+
+	span := provider.find("tools/list")
+	if span == nil || span.parent.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected the request span to join the trace from _meta, got %+v", span)
+	}
+	if span.attrs[attrMethodName].AsString() != "tools/list" {
+		t.Errorf("expected method attribute, got %v", span.attrs)
+	}
+}
@@ -2,15 +2,21 @@ package mcp
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/mhpenta/minimcp/tools"
+	"github.com/mhpenta/minimcp/tools/mcpctx"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/textproto"
 	"time"
 )
 
+// DefaultMaxBatchSize is the most requests accepted in one JSON-RPC batch by default
+const DefaultMaxBatchSize = 100
+
 // AuthHeaderType defines the type of authentication header to use
 type AuthHeaderType string
 
@@ -27,6 +33,26 @@ type HTTPTransport struct {
 	apiKey         APIKeyValidator
 	jsonrpcHandler *JSONRPCHandler
 	authHeaderType AuthHeaderType // Configurable auth header type
+
+	maxRequestBytes int64
+	maxBatchSize    int
+	drainTimeout    time.Duration
+	timeouts        HTTPTimeouts
+	maxUploadBytes  int64
+	oauth           *OAuthConfig
+
+	disableREST        bool
+	disableHealth      bool
+	requireHealthAuth  bool
+	requireMetricsAuth bool
+
+	tlsConfig    *tls.Config
+	clientCert   *ClientCertConfig
+	redirectPort string
+
+	contextHeaders []string
+
+	sessions httpSessions
 }
 
 // NewHTTPTransport creates a new HTTP transport for the MCP server
@@ -44,15 +70,32 @@ func NewHTTPTransport(
 		apiKey:         apiKeyValidator,
 		jsonrpcHandler: NewJSONRPCHandler(server),
 		authHeaderType: AuthHeaderBearer, // Default to Bearer auth
+
+		maxRequestBytes: DefaultMaxMessageBytes,
+		maxBatchSize:    DefaultMaxBatchSize,
+		drainTimeout:    DefaultDrainTimeout,
+		timeouts:        DefaultHTTPTimeouts(),
+		maxUploadBytes:  DefaultMaxUploadBytes,
 	}
 
 	// Register MCP JSON-RPC endpoint (Claude Code compatible)
 	router.HandleFunc("/mcp", transport.authMiddleware(transport.handleMCP))
 
 	// Register REST endpoints (for simple HTTP clients)
-	router.HandleFunc("/mcp/tools/list", transport.authMiddleware(transport.handleListTools))
-	router.HandleFunc("/mcp/tools/call", transport.authMiddleware(transport.handleCallTool))
-	router.HandleFunc("/mcp/health", transport.handleHealth)
+	router.HandleFunc("/mcp/tools/list", transport.restRoute(transport.authMiddleware(transport.handleListTools)))
+	router.HandleFunc("/mcp/tools/call", transport.restRoute(transport.authMiddleware(transport.handleCallTool)))
+	router.HandleFunc("/mcp/tools/validate", transport.restRoute(transport.authMiddleware(transport.handleValidateTool)))
+	router.HandleFunc("/mcp/health", transport.healthRoute(transport.handleHealth))
+	router.HandleFunc("/mcp/ready", transport.healthRoute(transport.handleReady))
+
+	// Out-of-band upload endpoint for large tool inputs (enabled by ServerConfig.Uploads)
+	router.HandleFunc("/mcp/uploads", transport.authMiddleware(transport.handleUpload))
+
+	// Per-tool call statistics
+	router.HandleFunc("/mcp/stats", transport.authMiddleware(transport.handleStats))
+
+	// Prometheus metrics (enabled by ServerConfig.Metrics)
+	router.HandleFunc(MetricsPath, transport.metricsRoute)
 
 	return transport
 }
@@ -63,9 +106,127 @@ func (t *HTTPTransport) WithAuthHeaderType(headerType AuthHeaderType) *HTTPTrans
 	return t
 }
 
+// WithMaxRequestBytes sets the largest request body accepted by the MCP and REST endpoints (default 10MB)
+func (t *HTTPTransport) WithMaxRequestBytes(n int64) *HTTPTransport {
+	t.maxRequestBytes = n
+	return t
+}
+
+// WithRESTEndpoints enables or disables the /mcp/tools/list, /mcp/tools/call, and /mcp/tools/validate REST routes (enabled by default).
+// Disable them for deployments that should expose only the spec-compliant JSON-RPC endpoint.
+func (t *HTTPTransport) WithRESTEndpoints(enabled bool) *HTTPTransport {
+	t.disableREST = !enabled
+	return t
+}
+
+// WithHealthEndpoint enables or disables the /mcp/health and /mcp/ready routes (enabled by default)
+func (t *HTTPTransport) WithHealthEndpoint(enabled bool) *HTTPTransport {
+	t.disableHealth = !enabled
+	return t
+}
+
+// WithHealthAuth requires authentication on /mcp/health and /mcp/ready, which are public by default
+func (t *HTTPTransport) WithHealthAuth(required bool) *HTTPTransport {
+	t.requireHealthAuth = required
+	return t
+}
+
+// restRoute serves next only while the REST endpoints are enabled
+func (t *HTTPTransport) restRoute(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if t.disableREST {
+			http.NotFound(w, r)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// healthRoute serves a health or readiness endpoint according to the health configuration
+func (t *HTTPTransport) healthRoute(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case t.disableHealth:
+			http.NotFound(w, r)
+		case t.requireHealthAuth:
+			t.authMiddleware(next)(w, r)
+		default:
+			next(w, r)
+		}
+	}
+}
+
+// WithDrainTimeout sets how long Start waits for in-flight requests on shutdown (default 10s).
+// Tool calls still running afterwards are reported in a ShutdownError.
+func (t *HTTPTransport) WithDrainTimeout(d time.Duration) *HTTPTransport {
+	t.drainTimeout = d
+	return t
+}
+
+// WithMaxBatchSize sets the most requests accepted in one JSON-RPC batch (default 100).
+// Zero or less removes the limit.
+func (t *HTTPTransport) WithMaxBatchSize(n int) *HTTPTransport {
+	t.maxBatchSize = n
+	t.jsonrpcHandler.maxBatchSize = n
+	return t
+}
+
+// WithContextHeaders copies the named request headers, such as X-Request-ID or
+// X-Tenant-ID, into the context passed to tools, where mcpctx.RequestMetadata reads them.
+// Headers the request does not carry are left out.
+func (t *HTTPTransport) WithContextHeaders(headers ...string) *HTTPTransport {
+	for _, header := range headers {
+		t.contextHeaders = append(t.contextHeaders, textproto.CanonicalMIMEHeaderKey(header))
+	}
+	return t
+}
+
+// requestMetadata returns the configured headers the request carries
+func (t *HTTPTransport) requestMetadata(r *http.Request) mcpctx.Metadata {
+	md := mcpctx.Metadata{}
+	for _, header := range t.contextHeaders {
+		if value := r.Header.Get(header); value != "" {
+			md[header] = value
+		}
+	}
+	return md
+}
+
+// limitBody caps the request body at the configured maximum
+func (t *HTTPTransport) limitBody(w http.ResponseWriter, r *http.Request) {
+	if t.maxRequestBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, t.maxRequestBytes)
+	}
+}
+
+// bodyErrorStatus maps a body read error to an HTTP status code
+func bodyErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
 // authMiddleware validates authentication based on configured header type
 func (t *HTTPTransport) authMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(withScopesEnforced(r.Context()))
+
+		// A verified client certificate authenticates the request on its own
+		if principal, err := t.clientCertPrincipal(r); err != nil || principal != nil {
+			if err == nil {
+				err = t.checkResource(r.Context(), principal, false)
+			}
+			if err != nil {
+				t.logger.Warn("unauthorized MCP request", "auth_type", "client-cert", "error", err)
+				t.unauthorized(w, r, true)
+				return
+			}
+			next(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+			return
+		}
+
 		var providedKey string
 
 		// Extract key based on configured auth header type
@@ -87,13 +248,30 @@ func (t *HTTPTransport) authMiddleware(next http.HandlerFunc) http.HandlerFunc {
 			}
 		}
 
+		// Authenticators identify the caller, which tools can read via PrincipalFrom
+		if authenticator, ok := t.apiKey.(Authenticator); ok {
+			principal, err := authenticator.Authenticate(r.Context(), providedKey)
+			if err == nil {
+				err = t.checkResource(r.Context(), principal, true)
+			}
+			if err != nil {
+				t.logger.Warn("unauthorized MCP request",
+					"auth_type", t.authHeaderType,
+					"has_key", providedKey != "",
+					"error", err)
+				t.unauthorized(w, r, providedKey != "")
+				return
+			}
+			next(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+			return
+		}
+
 		// Validate the key
 		if !t.apiKey.Validate(r.Context(), providedKey) {
 			t.logger.Warn("unauthorized MCP request",
 				"auth_type", t.authHeaderType,
-				"has_key", providedKey != "",
-				"header", r.Header)
-			http.Error(w, "unauthorized", http.StatusUnauthorized)
+				"has_key", providedKey != "")
+			t.unauthorized(w, r, providedKey != "")
 			return
 		}
 		next(w, r)
@@ -109,69 +287,67 @@ func (t *HTTPTransport) handleMCP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Read the request body
+	t.limitBody(w, r)
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		t.logger.Error("failed to read request body", "error", err)
-		http.Error(w, fmt.Sprintf("failed to read request: %v", err), http.StatusBadRequest)
+		status := bodyErrorStatus(err)
+		message := "Failed to read request"
+		if status == http.StatusRequestEntityTooLarge {
+			message = fmt.Sprintf("Request exceeds %d bytes", t.maxRequestBytes)
+		}
+		writeRPCError(w, status, InvalidRequest, message)
 		return
 	}
 	defer r.Body.Close()
 
-	// Check if it's a batch request (array of requests)
-	var isBatch bool
-	var requests []json.RawMessage
-
-	// Try to parse as array first
-	if err := json.Unmarshal(body, &requests); err == nil && len(requests) > 0 {
-		isBatch = true
-	} else {
-		// Single request
-		requests = []json.RawMessage{body}
-		isBatch = false
+	// Oversized batches are refused with 413 before any of their requests run
+	isBatch := isBatchPayload(body)
+	if isBatch && t.maxBatchSize > 0 {
+		var requests []json.RawMessage
+		if json.Unmarshal(body, &requests) == nil && len(requests) > t.maxBatchSize {
+			t.logger.Warn("rejecting oversized batch", "size", len(requests), "max", t.maxBatchSize)
+			writeRPCError(w, http.StatusRequestEntityTooLarge, InvalidRequest,
+				fmt.Sprintf("Batch of %d requests exceeds the limit of %d", len(requests), t.maxBatchSize))
+			return
+		}
+	}
+
+	r, ok := t.withHTTPSession(w, r, body)
+	if !ok {
+		return
 	}
 
-	// Process each request
-	responses := make([]*JSONRPCResponse, 0, len(requests))
-	for _, reqData := range requests {
-		resp, err := t.jsonrpcHandler.HandleMessage(r.Context(), reqData)
-		if err != nil {
-			t.logger.Error("error handling JSON-RPC message", "error", err)
-			responses = append(responses, &JSONRPCResponse{
-				JSONRPC: "2.0",
-				Error: &RPCError{
-					Code:    InternalError,
-					Message: "Internal server error",
-					Data:    err.Error(),
-				},
-			})
-		} else if resp != nil {
-			// Only add response if it's not a notification
-			responses = append(responses, resp)
+	// A streaming tool call is answered with an event stream carrying its partial results
+	if !isBatch && t.server.streamsResult(body) {
+		if sse := newSSEWriter(w, r); sse != nil {
+			t.handleStreamingCall(r, body, sse)
+			return
 		}
 	}
 
+	reply := t.jsonrpcHandler.HandlePayload(r.Context(), body)
+
 	// Don't send a response for notifications (empty responses)
-	if len(responses) == 0 {
+	if reply.Empty() {
 		w.WriteHeader(http.StatusAccepted)
 		return
 	}
 
-	// Send response
 	w.Header().Set("Content-Type", "application/json")
-	if isBatch {
-		json.NewEncoder(w).Encode(responses)
-	} else if len(responses) > 0 {
-		json.NewEncoder(w).Encode(responses[0])
-	}
+	json.NewEncoder(w).Encode(reply)
 }
 
-// handleHealth returns server health status
-func (t *HTTPTransport) handleHealth(w http.ResponseWriter, r *http.Request) {
+// writeRPCError writes a JSON-RPC error response with no ID, for failures detected before a request could be parsed
+func writeRPCError(w http.ResponseWriter, status, code int, message string) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"status":    "healthy",
-		"timestamp": time.Now().Unix(),
-		"version":   "1.0.0",
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(JSONRPCResponse{
+		JSONRPC: "2.0",
+		Error: &RPCError{
+			Code:    code,
+			Message: message,
+		},
 	})
 }
 
@@ -182,33 +358,21 @@ func (t *HTTPTransport) handleListTools(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	toolList := make([]map[string]interface{}, 0, len(t.server.tools))
-	for _, tool := range t.server.tools {
-		spec := tool.Spec()
-		toolList = append(toolList, map[string]interface{}{
-			"name":        spec.Name,
-			"description": spec.Description,
-			"inputSchema": spec.Parameters,
-		})
-	}
-
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"tools": toolList,
-	})
+	json.NewEncoder(w).Encode(t.server.listTools(r.Context()))
 }
 
 // CallToolRequest represents an MCP tool call request
 type CallToolRequest struct {
 	Name   string          `json:"name"`
 	Params json.RawMessage `json:"arguments"`
+
+	// TimeoutMs asks for a timeout on the call, limited as for tools/call
+	TimeoutMs float64 `json:"timeoutMs,omitempty"`
 }
 
 // CallToolResponse represents an MCP tool call response
-type CallToolResponse struct {
-	Content []ContentBlock `json:"content"`
-	IsError bool           `json:"isError,omitempty"`
-}
+type CallToolResponse = ToolsCallResult
 
 // ContentBlock represents a content block in the response
 type ContentBlock struct {
@@ -224,92 +388,96 @@ func (t *HTTPTransport) handleCallTool(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req CallToolRequest
+	t.limitBody(w, r)
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		t.logger.Error("failed to decode request", "error", err)
-		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), bodyErrorStatus(err))
 		return
 	}
 
-	t.logger.Info("executing tool", "tool", req.Name)
-
-	// Find the tool
-	var targetTool tools.Tool
-	for _, tool := range t.server.tools {
-		if tool.Spec().Name == req.Name {
-			targetTool = tool
-			break
-		}
+	// Execute the tool with context
+	ctx := t.server.withCorrelation(r.Context(), nil)
+	logger := t.server.logger.With(
+		"route", r.URL.Path,
+		"session", sessionFrom(ctx),
+		"tool", req.Name)
+	if id := mcpctx.RequestID(ctx); id != "" {
+		logger = logger.With("request_id", id)
 	}
+	ctx = mcpctx.WithLogger(ctx, logger)
 
-	if targetTool == nil {
-		t.logger.Warn("tool not found", "tool", req.Name)
-		http.Error(w, fmt.Sprintf("tool not found: %s", req.Name), http.StatusNotFound)
+	result, rpcErr := t.server.handleToolsCall(ctx, ToolsCallParams{Name: req.Name, Arguments: req.Params, TimeoutMs: req.TimeoutMs})
+	if rpcErr != nil {
+		t.logger.Warn("tool call rejected", "tool", req.Name, "code", rpcErr.Code, "error", rpcErr.Message)
+		http.Error(w, rpcErr.Message, restErrorStatus(t.server, req.Name, rpcErr))
 		return
 	}
 
-	// Execute the tool with context
-	ctx := r.Context()
-	if ctx == nil {
-		ctx = context.Background()
+	// MCP protocol uses 200 even for tool errors
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(withRequestIDMeta(ctx, *result))
+}
+
+// restErrorStatus maps a protocol error from a REST tool route to its HTTP status.
+// Unknown tools are reported as 404 rather than as invalid params.
+func restErrorStatus(s *Server, name string, rpcErr *RPCError) int {
+	switch rpcErr.Code {
+	case PermissionDenied:
+		return http.StatusForbidden
+	case InvalidParams:
+		if s.findTool(name) == nil {
+			return http.StatusNotFound
+		}
+		return http.StatusBadRequest
+	case InvalidRequest:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
 	}
+}
+
+// handleStreamingCall processes a tools/call whose partial results are sent as events
+// ahead of the final response
+func (t *HTTPTransport) handleStreamingCall(r *http.Request, body []byte, sse *sseWriter) {
+	ctx := withNotifier(r.Context(), func(n JSONRPCNotification) error {
+		return sse.send(n)
+	})
 
-	result, err := targetTool.Execute(ctx, req.Params)
+	resp, err := t.jsonrpcHandler.HandleMessage(ctx, body)
 	if err != nil {
-		t.logger.Error("MCP tool execution failed",
-			"tool", req.Name,
-			"error", err.Error(),
-			"errorType", fmt.Sprintf("%T", err),
-			"arguments", string(req.Params),
-			"context", "mcp_http_transport")
-		response := CallToolResponse{
-			Content: []ContentBlock{
-				{
-					Type: "text",
-					Text: fmt.Sprintf("Error executing tool: %v", err),
-				},
+		t.logger.Error("error handling JSON-RPC message", "error", err)
+		resp = &JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error: &RPCError{
+				Code:    InternalError,
+				Message: "Internal server error",
+				Data:    err.Error(),
 			},
-			IsError: true,
 		}
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK) // MCP protocol uses 200 even for tool errors
-		json.NewEncoder(w).Encode(response)
+	}
+	if resp == nil {
 		return
 	}
-
-	// Convert tool result to MCP response format
-	var text string
-	if result.Error != nil {
-		text = *result.Error
-	} else if result.Output != nil {
-		text = tools.MarshalOutput(t.logger, result.Output)
-	} else if result.System != nil {
-		text = *result.System
-	} else {
-		// Fallback to JSON marshaling the entire result
-		resultBytes, err := json.Marshal(result)
-		if err != nil {
-			text = "Error serializing result"
-		} else {
-			text = string(resultBytes)
-		}
+	if err := sse.send(resp); err != nil {
+		t.logger.Error("error writing streamed response", "error", err)
 	}
-
-	response := CallToolResponse{
-		Content: []ContentBlock{
-			{
-				Type: "text",
-				Text: text,
-			},
-		},
-		IsError: false,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
 }
 
 // ServeHTTP implements http.Handler
 func (t *HTTPTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Requests are scoped to their connection until handleMCP attaches an issued session
+	ctx := withConnection(r.Context(), r.RemoteAddr)
+	if len(t.contextHeaders) > 0 {
+		ctx = mcpctx.WithMetadata(ctx, t.requestMetadata(r))
+	}
+	if t.server.requestIDs {
+		ctx = withHeaderRequestID(ctx, r)
+		if id, ok := ctx.Value(headerRequestIDKey{}).(string); ok {
+			w.Header().Set(RequestIDHeader, id)
+		}
+	}
+	r, span := t.traceHTTP(r.WithContext(ctx))
+	defer span.End()
 	t.router.ServeHTTP(w, r)
 }
 
@@ -318,40 +486,55 @@ func (t *HTTPTransport) Start(ctx context.Context, port string) error {
 	addr := ":" + port
 	t.logger.Info("starting MCP HTTP server", "addr", addr)
 
-	server := &http.Server{
-		Addr:         addr,
-		Handler:      t,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 30 * time.Second,
-		IdleTimeout:  60 * time.Second,
-	}
+	server := t.newHTTPServer(addr, t)
+	return t.serve(ctx, server, server.ListenAndServe)
+}
 
+// serve runs listen until ctx is cancelled, then shuts server down gracefully.
+// Any extra servers (such as an HTTP redirect listener) are shut down alongside it.
+func (t *HTTPTransport) serve(ctx context.Context, server *http.Server, listen func() error, extra ...*http.Server) error {
 	// Channel to capture server errors
-	serverErr := make(chan error, 1)
+	serverErr := make(chan error, 1+len(extra))
 
-	// Start server in goroutine
+	// Start servers in goroutines
 	go func() {
-		t.logger.Info("HTTP server listening", "addr", addr)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		t.logger.Info("HTTP server listening", "addr", server.Addr)
+		if err := listen(); err != nil && err != http.ErrServerClosed {
 			serverErr <- err
 		}
 	}()
+	for _, srv := range extra {
+		go func() {
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				serverErr <- err
+			}
+		}()
+	}
+
+	shutdownExtra := func() {
+		for _, srv := range extra {
+			srv.Close()
+		}
+	}
 
 	// Wait for context cancellation or server error
 	select {
 	case err := <-serverErr:
+		server.Close()
+		shutdownExtra()
 		return fmt.Errorf("server error: %w", err)
 	case <-ctx.Done():
 		t.logger.Info("shutting down MCP server gracefully...")
+		shutdownExtra()
 
 		// Create shutdown context with timeout
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), t.drainTimeout)
 		defer cancel()
 
 		// Attempt graceful shutdown
 		if err := server.Shutdown(shutdownCtx); err != nil {
 			t.logger.Error("error during server shutdown", "error", err)
-			return fmt.Errorf("server shutdown error: %w", err)
+			return t.server.shutdownError(t.drainTimeout, fmt.Errorf("server shutdown error: %w", err))
 		}
 
 		t.logger.Info("MCP server stopped gracefully")
@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPTimeouts bounds the phases of an HTTP connection, as the fields of http.Server of the
+// same names do. Zero means no limit, except that a zero ReadHeaderTimeout falls back to
+// ReadTimeout.
+type HTTPTimeouts struct {
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+}
+
+// DefaultHTTPTimeouts returns the timeouts used by the HTTP transport unless
+// WithHTTPTimeouts replaces them
+func DefaultHTTPTimeouts() HTTPTimeouts {
+	return HTTPTimeouts{
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+}
+
+// WithHTTPTimeouts sets the timeouts of the servers created by Start, StartTLS, Serve, and
+// ServeTLS. Start from DefaultHTTPTimeouts to change only some of them; for example, long
+// tool calls and streams need a longer WriteTimeout.
+func (t *HTTPTransport) WithHTTPTimeouts(timeouts HTTPTimeouts) *HTTPTransport {
+	t.timeouts = timeouts
+	return t
+}
+
+// Serve serves MCP on l until ctx is cancelled, then shuts down gracefully as Start does.
+// Use it to control the listening socket, for example one from SystemdListener or bound to
+// a Unix socket. l is closed on return.
+func (t *HTTPTransport) Serve(ctx context.Context, l net.Listener) error {
+	t.logger.Info("starting MCP HTTP server", "addr", l.Addr().String())
+
+	server := t.newHTTPServer(l.Addr().String(), t)
+	return t.serve(ctx, server, func() error {
+		return server.Serve(l)
+	})
+}
+
+// systemd socket activation passes listeners as consecutive descriptors from 3
+// (see sd_listen_fds(3))
+const systemdFirstFD = 3
+
+// SystemdListener returns the socket passed by systemd socket activation, so a service can
+// be started on demand by a .socket unit:
+//
+//	l, err := mcp.SystemdListener()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	httpTransport.Serve(ctx, l)
+//
+// It returns an error unless exactly one socket was passed to this process. The activation
+// environment variables are unset so child processes do not inherit the socket.
+func SystemdListener() (net.Listener, error) {
+	listeners, err := systemdListeners(os.Getenv, os.Getpid(), systemdFirstFD)
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	if err != nil {
+		return nil, err
+	}
+	if len(listeners) != 1 {
+		for _, l := range listeners {
+			l.Close()
+		}
+		return nil, fmt.Errorf("systemd passed %d sockets, expected 1", len(listeners))
+	}
+	return listeners[0], nil
+}
+
+// systemdListeners returns the listeners described by the LISTEN_PID and LISTEN_FDS
+// variables, whose descriptors start at firstFD
+func systemdListeners(getenv func(string) string, pid, firstFD int) ([]net.Listener, error) {
+	if getenv("LISTEN_PID") == "" {
+		return nil, errors.New("no sockets passed by systemd: LISTEN_PID is not set")
+	}
+	listenPID, err := strconv.Atoi(getenv("LISTEN_PID"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LISTEN_PID: %w", err)
+	}
+	if listenPID != pid {
+		return nil, fmt.Errorf("sockets were passed to process %d, not this one (%d)", listenPID, pid)
+	}
+	count, err := strconv.Atoi(getenv("LISTEN_FDS"))
+	if err != nil || count < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", getenv("LISTEN_FDS"))
+	}
+	names := strings.Split(getenv("LISTEN_FDNAMES"), ":")
+
+	listeners := make([]net.Listener, 0, count)
+	for i := 0; i < count; i++ {
+		name := "LISTEN_FD_" + strconv.Itoa(firstFD+i)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		file := os.NewFile(uintptr(firstFD+i), name)
+		l, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("socket %s: %w", name, err)
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
+
+// newHTTPServer creates an http.Server with the transport's timeouts
+func (t *HTTPTransport) newHTTPServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: t.timeouts.ReadHeaderTimeout,
+		ReadTimeout:       t.timeouts.ReadTimeout,
+		WriteTimeout:      t.timeouts.WriteTimeout,
+		IdleTimeout:       t.timeouts.IdleTimeout,
+	}
+}
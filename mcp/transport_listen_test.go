@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newListenTestTransport() *HTTPTransport {
+	server := NewServer(ServerConfig{Name: "test-server", Version: "1.0.0"})
+	return NewHTTPTransport(server, slog.New(slog.NewTextHandler(io.Discard, nil)), newMockValidator("test-key"))
+}
+
+// serveUntilHealthy runs serve in the background, checks the health endpoint at addr,
+// then cancels and waits for a graceful return
+func serveUntilHealthy(t *testing.T, addr string, serve func(ctx context.Context) error) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx) }()
+
+	resp, err := http.Get("http://" + addr + "/mcp/health")
+	if err != nil {
+		cancel()
+		t.Fatalf("health request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected graceful shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
+
+func TestHTTPTransport_Serve(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeouts := DefaultHTTPTimeouts()
+	timeouts.ReadHeaderTimeout = 5 * time.Second
+	timeouts.WriteTimeout = 5 * time.Minute
+	transport := newListenTestTransport().WithHTTPTimeouts(timeouts)
+
+	server := transport.newHTTPServer(l.Addr().String(), transport)
+	if server.ReadHeaderTimeout != 5*time.Second || server.ReadTimeout != 30*time.Second ||
+		server.WriteTimeout != 5*time.Minute || server.IdleTimeout != 60*time.Second {
+		t.Errorf("unexpected server timeouts %+v", server)
+	}
+
+	serveUntilHealthy(t, l.Addr().String(), func(ctx context.Context) error {
+		return transport.Serve(ctx, l)
+	})
+	if _, err := l.Accept(); err == nil {
+		t.Error("expected the listener to be closed after Serve returns")
+	}
+}
+
+func TestSystemdListeners_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"not activated", map[string]string{}, "LISTEN_PID is not set"},
+		{"other process", map[string]string{"LISTEN_PID": "7", "LISTEN_FDS": "1"}, "passed to process 7"},
+		{"bad count", map[string]string{"LISTEN_PID": "42", "LISTEN_FDS": "x"}, "invalid LISTEN_FDS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := systemdListeners(func(key string) string { return tt.env[key] }, 42, systemdFirstFD)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// No sockets is not an error for systemdListeners, but SystemdListener requires one
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "0")
+	if _, err := SystemdListener(); err == nil || !strings.Contains(err.Error(), "passed 0 sockets") {
+		t.Errorf("expected an error for zero sockets, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+//go:build unix
+
+package mcp
+
+import (
+	"context"
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestSystemdListeners(t *testing.T) {
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcp.Close()
+	// Duplicate the socket to stand in for the descriptor systemd would pass;
+	// systemdListeners takes ownership of it
+	file, err := tcp.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd, err := syscall.Dup(int(file.Fd()))
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{"LISTEN_PID": "42", "LISTEN_FDS": "1", "LISTEN_FDNAMES": "mcp.socket"}
+	listeners, err := systemdListeners(func(key string) string { return env[key] }, 42, fd)
+	if err != nil {
+		t.Fatalf("systemdListeners: %v", err)
+	}
+	if len(listeners) != 1 || listeners[0].Addr().String() != tcp.Addr().String() {
+		t.Fatalf("expected the passed socket, got %v", listeners)
+	}
+
+	serveUntilHealthy(t, tcp.Addr().String(), func(ctx context.Context) error {
+		return newListenTestTransport().Serve(ctx, listeners[0])
+	})
+}
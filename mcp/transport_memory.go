@@ -0,0 +1,168 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+// ErrTransportClosed is returned when sending or receiving on a closed in-memory transport
+var ErrTransportClosed = errors.New("transport closed")
+
+// inMemoryPipe is the pair of channels shared by both ends of an in-memory transport
+type inMemoryPipe struct {
+	toServer  chan []byte
+	toClient  chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (p *inMemoryPipe) close() {
+	p.closeOnce.Do(func() {
+		close(p.closed)
+	})
+}
+
+// InMemoryTransport is the server end of an in-process MCP connection backed by Go channels.
+// Requests are processed concurrently, so notifications such as notifications/cancelled
+// are handled while earlier requests are still running.
+type InMemoryTransport struct {
+	server         *Server
+	logger         *slog.Logger
+	jsonrpcHandler *JSONRPCHandler
+	pipe           *inMemoryPipe
+}
+
+// InMemoryClient is the client end of an in-process MCP connection
+type InMemoryClient struct {
+	pipe *inMemoryPipe
+}
+
+// NewInMemoryTransport creates a connected server transport and client endpoint.
+// It is primarily intended for tests that exercise the full JSON-RPC handling
+// without stdio buffers or an HTTP server.
+func NewInMemoryTransport(server *Server, logger *slog.Logger) (*InMemoryTransport, *InMemoryClient) {
+	if logger == nil {
+		logger = server.logger
+	}
+
+	pipe := &inMemoryPipe{
+		toServer: make(chan []byte),
+		toClient: make(chan []byte),
+		closed:   make(chan struct{}),
+	}
+
+	transport := &InMemoryTransport{
+		server:         server,
+		logger:         logger,
+		jsonrpcHandler: NewJSONRPCHandler(server),
+		pipe:           pipe,
+	}
+
+	return transport, &InMemoryClient{pipe: pipe}
+}
+
+// Start processes client messages until the context is cancelled or the client is closed.
+// It waits for in-flight requests to finish before returning.
+func (t *InMemoryTransport) Start(ctx context.Context) error {
+	t.logger.Info("starting MCP in-memory transport")
+	ctx = withSession(ctx, "in-memory")
+	ctx = withNotifier(ctx, func(n JSONRPCNotification) error {
+		return t.deliver(ctx, n)
+	})
+
+	// Tell the client when tools are added or removed while it is connected. Delivery
+	// waits for the client to receive, so it must not hold up the registry change.
+	defer t.server.OnToolsChanged(func(revision uint64) {
+		go t.deliver(ctx, toolsListChangedNotification(revision))
+	})()
+	// Resource updates go only to a client subscribed to the resource
+	defer t.server.OnResourcesChanged(func(uri string) {
+		if n, ok := t.jsonrpcHandler.resourceNotification(sessionFrom(ctx), uri); ok {
+			go t.deliver(ctx, n)
+		}
+	})()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			t.logger.Info("in-memory transport shutting down")
+			return nil
+
+		case <-t.pipe.closed:
+			t.logger.Info("in-memory client closed")
+			return nil
+
+		case msg := <-t.pipe.toServer:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				t.handle(ctx, msg)
+			}()
+		}
+	}
+}
+
+// handle processes a message or batch and delivers the reply, if any, to the client
+func (t *InMemoryTransport) handle(ctx context.Context, msg []byte) {
+	reply := t.jsonrpcHandler.HandlePayload(ctx, msg)
+	if reply.Empty() {
+		return
+	}
+
+	if err := t.deliver(ctx, reply); err != nil {
+		t.logger.Error("error delivering response", "error", err)
+	}
+}
+
+// deliver marshals a message and hands it to the client
+func (t *InMemoryTransport) deliver(ctx context.Context, msg interface{}) error {
+	msgBytes, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case t.pipe.toClient <- msgBytes:
+		return nil
+	case <-t.pipe.closed:
+		return ErrTransportClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Send delivers a raw JSON-RPC message to the server
+func (c *InMemoryClient) Send(ctx context.Context, msg []byte) error {
+	select {
+	case c.pipe.toServer <- msg:
+		return nil
+	case <-c.pipe.closed:
+		return ErrTransportClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Receive blocks until the server sends a message
+func (c *InMemoryClient) Receive(ctx context.Context) ([]byte, error) {
+	select {
+	case msg := <-c.pipe.toClient:
+		return msg, nil
+	case <-c.pipe.closed:
+		return nil, ErrTransportClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close disconnects the client, causing the server transport's Start to return
+func (c *InMemoryClient) Close() error {
+	c.pipe.close()
+	return nil
+}
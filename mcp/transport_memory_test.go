@@ -0,0 +1,133 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func startInMemory(t *testing.T, server *Server) *InMemoryClient {
+	t.Helper()
+
+	transport, client := NewInMemoryTransport(server, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- transport.Start(ctx)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		client.Close()
+		if err := <-done; err != nil {
+			t.Errorf("transport returned error: %v", err)
+		}
+	})
+	return client
+}
+
+func receiveResponse(t *testing.T, client *InMemoryClient) JSONRPCResponse {
+	t.Helper()
+
+	msg, err := client.Receive(context.Background())
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	var resp JSONRPCResponse
+	if err := json.Unmarshal(msg, &resp); err != nil {
+		t.Fatalf("failed to parse response: %v\nOutput: %s", err, msg)
+	}
+	return resp
+}
+
+func TestInMemoryTransport_InitializeAndNotification(t *testing.T) {
+	server := NewServer(ServerConfig{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Tools:   []tools.Tool{},
+	})
+	client := startInMemory(t, server)
+	ctx := context.Background()
+
+	if err := client.Send(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","clientInfo":{"name":"test-client","version":"1.0"}}}`)); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	resp := receiveResponse(t, client)
+	if resp.Error != nil {
+		t.Fatalf("expected no error, got %v", resp.Error)
+	}
+	if resp.ID != float64(1) {
+		t.Errorf("expected id 1, got %v", resp.ID)
+	}
+
+	// The notification produces no response, so the next message received must answer id 2
+	if err := client.Send(ctx, []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if err := client.Send(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	resp = receiveResponse(t, client)
+	if resp.ID != float64(2) {
+		t.Errorf("expected id 2, got %v", resp.ID)
+	}
+}
+
+func TestInMemoryTransport_Cancellation(t *testing.T) {
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	slowTool := &mockTool{
+		name:        "slow",
+		description: "Blocks until cancelled",
+		parameters:  map[string]interface{}{"type": "object"},
+		executeFn: func(ctx context.Context, params json.RawMessage) (*tools.ToolResult, error) {
+			close(started)
+			<-ctx.Done()
+			close(stopped)
+			return nil, ctx.Err()
+		},
+	}
+
+	server := NewServer(ServerConfig{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Tools:   []tools.Tool{slowTool},
+	})
+	client := startInMemory(t, server)
+	ctx := context.Background()
+
+	if err := client.Send(ctx, []byte(`{"jsonrpc":"2.0","id":"slow-1","method":"tools/call","params":{"name":"slow"}}`)); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	<-started
+
+	if err := client.Send(ctx, []byte(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"slow-1","reason":"user abort"}}`)); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	<-stopped
+
+	// The cancelled request must not be answered, so the next response belongs to id 3
+	if err := client.Send(ctx, []byte(`{"jsonrpc":"2.0","id":3,"method":"tools/list"}`)); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	resp := receiveResponse(t, client)
+	if resp.ID != float64(3) {
+		t.Errorf("expected id 3, got %v", resp.ID)
+	}
+}
+
+func TestInMemoryTransport_Closed(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server", Version: "1.0.0"})
+	_, client := NewInMemoryTransport(server, nil)
+	client.Close()
+
+	if err := client.Send(context.Background(), []byte(`{}`)); !errors.Is(err, ErrTransportClosed) {
+		t.Errorf("expected ErrTransportClosed from Send, got %v", err)
+	}
+	if _, err := client.Receive(context.Background()); !errors.Is(err, ErrTransportClosed) {
+		t.Errorf("expected ErrTransportClosed from Receive, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SessionIDHeader carries the session ID the HTTP transport issues in its initialize response.
+// Clients send it with every later request, so retries over a new connection are recognized.
+const SessionIDHeader = "Mcp-Session-Id"
+
+// httpSessionTTL is how long an HTTP session may go unused before it is forgotten
+const httpSessionTTL = 24 * time.Hour
+
+// httpSessions tracks the session IDs the HTTP transport issued and the principal each
+// belongs to, so a session cannot be used by another caller
+type httpSessions struct {
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+}
+
+type httpSession struct {
+	subject  string
+	lastUsed time.Time
+}
+
+// issue creates a session for the caller with the given subject
+func (s *httpSessions) issue(subject string) (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(b[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessions == nil {
+		s.sessions = make(map[string]*httpSession)
+	}
+	now := time.Now()
+	if len(s.sessions) >= maxInitializedSessions {
+		oldestID, oldest := "", now
+		for sid, session := range s.sessions {
+			if now.Sub(session.lastUsed) > httpSessionTTL {
+				delete(s.sessions, sid)
+			} else if session.lastUsed.Before(oldest) {
+				oldestID, oldest = sid, session.lastUsed
+			}
+		}
+		if len(s.sessions) >= maxInitializedSessions {
+			delete(s.sessions, oldestID)
+		}
+	}
+	s.sessions[id] = &httpSession{subject: subject, lastUsed: now}
+	return id, nil
+}
+
+// use reports whether id is a live session issued to subject, marking it used
+func (s *httpSessions) use(id, subject string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if !ok || session.subject != subject {
+		return false
+	}
+	now := time.Now()
+	if now.Sub(session.lastUsed) > httpSessionTTL {
+		delete(s.sessions, id)
+		return false
+	}
+	session.lastUsed = now
+	return true
+}
+
+// principalSubject returns the subject of the request's principal, or "" without one
+func principalSubject(r *http.Request) string {
+	if principal := PrincipalFrom(r.Context()); principal != nil {
+		return principal.Subject
+	}
+	return ""
+}
+
+// withHTTPSession attaches the caller's session to the request. A request carrying
+// SessionIDHeader must name a session issued to the same principal; an initialize request
+// without one is issued a new session, returned in the response header. Other requests
+// are scoped to their connection. It writes an error and returns false when the session
+// is unknown.
+func (t *HTTPTransport) withHTTPSession(w http.ResponseWriter, r *http.Request, body []byte) (*http.Request, bool) {
+	subject := principalSubject(r)
+	if id := r.Header.Get(SessionIDHeader); id != "" {
+		if !t.sessions.use(id, subject) {
+			writeRPCError(w, http.StatusNotFound, InvalidRequest, "Unknown or expired session; initialize a new one")
+			return r, false
+		}
+		return r.WithContext(withSession(r.Context(), id)), true
+	}
+
+	var req JSONRPCRequest
+	if isBatchPayload(body) || json.Unmarshal(body, &req) != nil || req.Method != "initialize" {
+		return r, true
+	}
+	id, err := t.sessions.issue(subject)
+	if err != nil {
+		t.logger.Error("failed to issue session ID", "error", err)
+		writeRPCError(w, http.StatusInternalServerError, InternalError, "Failed to create session")
+		return r, false
+	}
+	w.Header().Set(SessionIDHeader, id)
+	return r.WithContext(withSession(r.Context(), id)), true
+}
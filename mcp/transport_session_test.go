@@ -0,0 +1,169 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// subjectAuthenticator authenticates each key as a principal of the same name
+var subjectAuthenticator = authenticatorFunc(func(ctx context.Context, key string) (*Principal, error) {
+	if key == "" {
+		return nil, ErrUnauthenticated
+	}
+	return &Principal{Subject: key}, nil
+})
+
+func postMCP(ctx context.Context, transport *HTTPTransport, remoteAddr, key, session, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body)).WithContext(ctx)
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("Authorization", "Bearer "+key)
+	req.Header.Set("Content-Type", "application/json")
+	if session != "" {
+		req.Header.Set(SessionIDHeader, session)
+	}
+	w := httptest.NewRecorder()
+	transport.ServeHTTP(w, req)
+	return w
+}
+
+func initializeSession(t *testing.T, transport *HTTPTransport, key string) string {
+	t.Helper()
+	w := postMCP(context.Background(), transport, "10.0.0.1:1000", key, "",
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","clientInfo":{"name":"test","version":"1.0"}}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("initialize: expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	session := w.Header().Get(SessionIDHeader)
+	if session == "" {
+		t.Fatal("expected the initialize response to issue a session ID")
+	}
+	return session
+}
+
+func TestHTTPTransport_RetryOnNewConnectionAttaches(t *testing.T) {
+	server, started, release, executions := newBlockingServer(t)
+	transport := NewHTTPTransport(server, slog.New(slog.NewTextHandler(io.Discard, nil)), subjectAuthenticator)
+	session := initializeSession(t, transport, "alice")
+	call := `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"slow","arguments":{}}}`
+
+	// The first connection drops while the tool is still running
+	ctx, disconnect := context.WithCancel(context.Background())
+	go postMCP(ctx, transport, "10.0.0.1:1001", "alice", session, call)
+	<-started
+	disconnect()
+	waitForEntry(t, transport.jsonrpcHandler, func(entry *inFlightRequest) bool { return entry.grace != nil })
+
+	retried := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		retried <- postMCP(context.Background(), transport, "10.0.0.2:2002", "alice", session, call)
+	}()
+
+	// Let the retry attach before the tool finishes
+	waitForEntry(t, transport.jsonrpcHandler, func(entry *inFlightRequest) bool { return entry.waiters == 1 && entry.grace == nil })
+	close(release)
+
+	w := <-retried
+	var resp JSONRPCResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Error != nil || resp.Result == nil {
+		t.Fatalf("expected the retry to receive the result, got %s", w.Body.String())
+	}
+	if n := atomic.LoadInt32(executions); n != 1 {
+		t.Errorf("expected the tool to execute once, got %d", n)
+	}
+}
+
+// waitForEntry waits until the handler's in-flight request satisfies cond
+func waitForEntry(t *testing.T, handler *JSONRPCHandler, cond func(*inFlightRequest) bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		handler.mu.Lock()
+		ok := false
+		for _, entry := range handler.inFlight {
+			ok = cond(entry)
+		}
+		handler.mu.Unlock()
+		if ok {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("timed out waiting for the in-flight request")
+}
+
+func TestHTTPTransport_SessionIDMustBeIssuedToCaller(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server", Version: "1.0.0"})
+	transport := NewHTTPTransport(server, slog.New(slog.NewTextHandler(io.Discard, nil)), subjectAuthenticator)
+	session := initializeSession(t, transport, "alice")
+	list := `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`
+
+	tests := []struct {
+		name    string
+		key     string
+		session string
+		want    int
+	}{
+		{"issued session", "alice", session, http.StatusOK},
+		{"forged session", "alice", "forged", http.StatusNotFound},
+		{"another principal's session", "mallory", session, http.StatusNotFound},
+		{"no session", "mallory", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postMCP(context.Background(), transport, "10.0.0.3:3003", tt.key, tt.session, list)
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHTTPSessions_Evicts(t *testing.T) {
+	var sessions httpSessions
+	first, err := sessions.issue("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions.sessions[first].lastUsed = time.Now().Add(-time.Hour)
+	for i := 0; i < maxInitializedSessions; i++ {
+		if _, err := sessions.issue("alice"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if sessions.use(first, "alice") {
+		t.Error("expected the oldest session to be evicted at capacity")
+	}
+	if len(sessions.sessions) != maxInitializedSessions {
+		t.Errorf("expected %d sessions, got %d", maxInitializedSessions, len(sessions.sessions))
+	}
+}
+
+func TestHTTPTransport_ReinitializeSession(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server", Version: "1.0.0"})
+	transport := NewHTTPTransport(server, slog.New(slog.NewTextHandler(io.Discard, nil)), subjectAuthenticator)
+	session := initializeSession(t, transport, "alice")
+	init := `{"jsonrpc":"2.0","id":2,"method":"initialize","params":{"protocolVersion":"2024-11-05","clientInfo":{"name":"test","version":"1.0"}}}`
+
+	// Re-initializing an issued session is rejected, even from another connection
+	w := postMCP(context.Background(), transport, "10.0.0.4:4004", "alice", session, init)
+	var resp JSONRPCResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Error == nil || resp.Error.Code != InvalidRequest {
+		t.Errorf("expected InvalidRequest on re-initialization, got %s", w.Body.String())
+	}
+
+	// Initializing without a session starts a new one
+	if other := initializeSession(t, transport, "alice"); other == session {
+		t.Error("expected a new session ID")
+	}
+}
@@ -2,13 +2,27 @@ package mcp
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"sync"
+	"time"
 )
 
+// Default stdio message limits
+const (
+	DefaultMaxMessageBytes    = 10 * 1024 * 1024 // 10MB
+	DefaultInitialBufferBytes = 64 * 1024        // 64KB
+)
+
+// ErrReadTimeout is returned by StdioTransport.Start when no message arrives within the read timeout
+var ErrReadTimeout = errors.New("read timeout waiting for message")
+
 // StdioTransport provides stdio-based MCP server (reads from stdin, writes to stdout)
 type StdioTransport struct {
 	server         *Server
@@ -16,99 +30,383 @@ type StdioTransport struct {
 	jsonrpcHandler *JSONRPCHandler
 	reader         io.Reader
 	writer         io.Writer
+
+	maxMessageBytes    int
+	initialBufferBytes int
+	readTimeout        time.Duration
+	drainTimeout       time.Duration
+	framing            StdioFraming
+
+	writeMu      sync.Mutex
+	writeErr     error
+	writeFraming StdioFraming // the framing in use, once detected; guarded by writeMu
+
+	requests    *outgoingRequests
+	protocolLog *protocolLog
 }
 
 // NewStdioTransport creates a stdio transport (no auth needed for local process)
 func NewStdioTransport(server *Server, logger *slog.Logger) *StdioTransport {
-	return &StdioTransport{
-		server:         server,
-		logger:         logger,
-		jsonrpcHandler: NewJSONRPCHandler(server),
-		reader:         os.Stdin,
-		writer:         os.Stdout,
-	}
+	return NewStdioTransportWithIO(server, logger, os.Stdin, os.Stdout)
 }
 
 // NewStdioTransportWithIO creates a stdio transport with custom reader/writer (for testing)
 func NewStdioTransportWithIO(server *Server, logger *slog.Logger, reader io.Reader, writer io.Writer) *StdioTransport {
-	return &StdioTransport{
-		server:         server,
-		logger:         logger,
-		jsonrpcHandler: NewJSONRPCHandler(server),
-		reader:         reader,
-		writer:         writer,
+	if logger == nil {
+		logger = server.logger
+	}
+	t := &StdioTransport{
+		server:             server,
+		logger:             logger,
+		jsonrpcHandler:     NewJSONRPCHandler(server),
+		reader:             reader,
+		writer:             writer,
+		maxMessageBytes:    DefaultMaxMessageBytes,
+		initialBufferBytes: DefaultInitialBufferBytes,
+		drainTimeout:       DefaultDrainTimeout,
+		framing:            StdioFramingAuto,
 	}
+	t.requests = newOutgoingRequests(t.jsonrpcHandler, t.writeMessage, logger)
+	return t
+}
+
+// WithMaxBatchSize sets the most messages accepted in one JSON-RPC batch (default 100).
+// Zero or less removes the limit.
+func (t *StdioTransport) WithMaxBatchSize(n int) *StdioTransport {
+	t.jsonrpcHandler.maxBatchSize = n
+	return t
+}
+
+// WithMaxMessageBytes sets the largest message the transport will read (default 10MB).
+// Longer messages are discarded and answered with a parse error; later messages are still read.
+func (t *StdioTransport) WithMaxMessageBytes(n int) *StdioTransport {
+	t.maxMessageBytes = n
+	return t
+}
+
+// WithInitialBufferBytes sets the initial size of the read buffer (default 64KB).
+// The buffer grows as needed up to the maximum message size.
+func (t *StdioTransport) WithInitialBufferBytes(n int) *StdioTransport {
+	t.initialBufferBytes = n
+	return t
+}
+
+// WithReadTimeout makes Start return ErrReadTimeout if no message arrives within d.
+// Zero (the default) waits indefinitely.
+func (t *StdioTransport) WithReadTimeout(d time.Duration) *StdioTransport {
+	t.readTimeout = d
+	return t
+}
+
+// WithDrainTimeout sets how long Start waits for in-flight requests before returning (default 10s).
+// Tool calls still running afterwards are reported in a ShutdownError. Zero waits indefinitely.
+func (t *StdioTransport) WithDrainTimeout(d time.Duration) *StdioTransport {
+	t.drainTimeout = d
+	return t
+}
+
+// WithRequestTimeout sets how long Request waits for the client to respond (default 60s).
+// Zero waits until the request's context is done.
+func (t *StdioTransport) WithRequestTimeout(d time.Duration) *StdioTransport {
+	t.requests.timeout = d
+	return t
+}
+
+// Request sends a request to the client, such as sampling/createMessage, roots/list, or
+// elicitation/create, and decodes the response's result into result, which may be nil.
+// An error response from the client is returned as an *RPCError. It fails until Start
+// is running, and with ErrTransportClosed once the transport stops. Tools running on this transport can send
+// requests with CallClient instead.
+func (t *StdioTransport) Request(ctx context.Context, method string, params, result interface{}) error {
+	return t.requests.call(withSession(ctx, "stdio"), method, params, result)
+}
+
+// Ping checks that the client is responsive
+func (t *StdioTransport) Ping(ctx context.Context) error {
+	return t.Request(ctx, MethodPing, nil, nil)
 }
 
-// Start begins reading from stdin and processing JSON-RPC messages
-func (t *StdioTransport) Start(ctx context.Context) error {
+// stdioQueueSize is how many requests may wait for a free worker before reading stalls
+const stdioQueueSize = 64
+
+// Start begins reading from stdin and processing JSON-RPC messages.
+//
+// Requests are handed to a pool of ServerConfig.MaxConcurrentRequests workers; with the
+// default of one worker they are processed serially in arrival order. Notifications and
+// responses to the server's own requests are handled as soon as they are read, so a
+// cancellation is not stuck behind the request it cancels.
+func (t *StdioTransport) Start(ctx context.Context) (err error) {
 	t.logger.Info("starting MCP stdio transport")
 
-	scanner := bufio.NewScanner(t.reader)
-	// Increase buffer size for large messages
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 10*1024*1024) // 10MB max message size
+	ctx, cancel := context.WithCancel(withSession(ctx, "stdio"))
+	defer cancel()
+
+	// Streaming tools push partial results as they are produced
+	ctx = withNotifier(ctx, func(n JSONRPCNotification) error {
+		return t.writeMessage(n)
+	})
+
+	// Tools can send requests to the client; responses are routed back by ID
+	t.requests.open()
+	ctx = withClientCaller(ctx, t.requests.call)
+
+	// Tell the client when tools are added or removed while it is connected
+	defer t.server.OnToolsChanged(func(revision uint64) {
+		t.writeMessage(toolsListChangedNotification(revision))
+	})()
+	// Resource updates go only to a client subscribed to the resource
+	defer t.server.OnResourcesChanged(func(uri string) {
+		if n, ok := t.jsonrpcHandler.resourceNotification(sessionFrom(ctx), uri); ok {
+			t.writeMessage(n)
+		}
+	})()
+
+	initialBuffer := t.initialBufferBytes
+	if initialBuffer > t.maxMessageBytes {
+		initialBuffer = t.maxMessageBytes
+	}
+	reader := bufio.NewReaderSize(t.reader, initialBuffer)
 
-	// Channel to receive scan results
-	scanChan := make(chan []byte)
+	// Channel to receive read messages
+	scanChan := make(chan stdioMessage)
 	errChan := make(chan error, 1)
 
-	// Start scanner in goroutine
+	// Start reader in goroutine
 	go func() {
 		defer close(scanChan)
-		for scanner.Scan() {
-			line := make([]byte, len(scanner.Bytes()))
-			copy(line, scanner.Bytes())
-			scanChan <- line
+		framing, err := t.resolveFraming(reader)
+		if err != nil {
+			if err != io.EOF {
+				errChan <- err
+			}
+			return
 		}
-		if err := scanner.Err(); err != nil {
-			errChan <- err
+		for {
+			msg, err := readFramedMessage(reader, framing, t.maxMessageBytes)
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				errChan <- err
+				return
+			}
+			select {
+			case scanChan <- msg:
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
 
+	// Start the worker pool
+	workers := t.server.maxConcurrentRequests
+	if workers < 1 {
+		workers = 1
+	}
+	work := make(chan []byte, stdioQueueSize)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for line := range work {
+				if ctx.Err() != nil {
+					continue
+				}
+				t.processMessage(ctx, line, cancel)
+			}
+		}()
+	}
+
+	// Wait for queued and in-flight requests before returning, so no response is lost
+	defer func() {
+		close(work)
+		// Responses are no longer read, so requests waiting for one cannot complete
+		t.requests.close(ErrTransportClosed)
+		if drainErr := t.server.waitDrained(&wg, t.drainTimeout); drainErr != nil && err == nil {
+			err = drainErr
+		}
+	}()
+
+	// A nil channel never fires, so without a read timeout the select below ignores it
+	var readTimer *time.Timer
+	var readTimeout <-chan time.Time
+	if t.readTimeout > 0 {
+		readTimer = time.NewTimer(t.readTimeout)
+		defer readTimer.Stop()
+		readTimeout = readTimer.C
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			t.logger.Info("stdio transport shutting down")
-			return nil
+			return t.writeError()
 
-		case line, ok := <-scanChan:
+		case <-readTimeout:
+			t.logger.Warn("stdio transport read timeout", "timeout", t.readTimeout)
+			return ErrReadTimeout
+
+		case msg, ok := <-scanChan:
 			if !ok {
-				// Scanner closed
+				// Reader closed
 				select {
 				case err := <-errChan:
-					t.logger.Error("scanner error", "error", err)
+					t.logger.Error("read error", "error", err)
 					return err
 				default:
-					return nil
+					return t.writeError()
 				}
 			}
 
+			if readTimer != nil {
+				readTimer.Reset(t.readTimeout)
+			}
+
+			if msg.oversized > 0 {
+				t.protocolLog.record("recv", []byte(fmt.Sprintf("<discarded %d bytes>", msg.oversized)))
+				t.rejectOversized(msg.oversized)
+				continue
+			}
+
+			line := msg.data
 			if len(line) == 0 {
 				continue
 			}
+			t.protocolLog.record("recv", line)
 
-			// Process the JSON-RPC message
-			resp, err := t.jsonrpcHandler.HandleMessage(ctx, line)
-			if err != nil {
-				t.logger.Error("error handling message", "error", err)
+			// Responses are delivered without a worker, which may be the one waiting for it
+			if isResponse(line) {
+				t.requests.deliver(line)
 				continue
 			}
 
-			// Write response if not a notification
-			if resp != nil {
-				respBytes, err := json.Marshal(resp)
-				if err != nil {
-					t.logger.Error("error marshaling response", "error", err)
-					continue
-				}
+			if isNotification(line) {
+				t.processMessage(ctx, line, cancel)
+				continue
+			}
 
-				// Write newline-delimited JSON to stdout
-				if _, err := t.writer.Write(append(respBytes, '\n')); err != nil {
-					t.logger.Error("error writing response", "error", err)
-					return err
-				}
+			select {
+			case work <- line:
+			case <-ctx.Done():
 			}
 		}
 	}
 }
+
+// rejectOversized answers a message that exceeded the size limit with a parse error.
+// The message was discarded unread, so its ID is unknown and the error carries none.
+func (t *StdioTransport) rejectOversized(size int) {
+	t.logger.Warn("discarded oversized message", "bytes", size, "limit", t.maxMessageBytes)
+	t.writeMessage(&JSONRPCResponse{
+		JSONRPC: "2.0",
+		Error: &RPCError{
+			Code:    ParseError,
+			Message: fmt.Sprintf("Parse error: message exceeds the %d byte limit", t.maxMessageBytes),
+			Data:    fmt.Sprintf("message of %d bytes discarded", size),
+		},
+	})
+}
+
+// stdioMessage is one line read from the input. Lines longer than the size limit are
+// discarded and reported by their size in oversized instead.
+type stdioMessage struct {
+	data      []byte
+	oversized int
+}
+
+// readMessage reads one newline-delimited message of at most max bytes, without its line
+// ending. A longer line is consumed up to its newline so reading can continue with the next
+// message. It returns io.EOF once the input is exhausted.
+func readMessage(r *bufio.Reader, max int) (stdioMessage, error) {
+	var line []byte
+	size := 0
+	dropped := false
+	for {
+		chunk, err := r.ReadSlice('\n')
+		size += len(chunk)
+		// Oversized lines are not kept; max+2 leaves room for a trailing \r\n
+		if !dropped && len(line)+len(chunk) <= max+2 {
+			line = append(line, chunk...)
+		} else {
+			dropped, line = true, nil
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF && size > 0 {
+			// A final message without a trailing newline
+			err = nil
+		}
+		if err != nil {
+			return stdioMessage{}, err
+		}
+		break
+	}
+
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if dropped || len(line) > max {
+		return stdioMessage{oversized: size}, nil
+	}
+	return stdioMessage{data: line}, nil
+}
+
+// processMessage handles a single JSON-RPC message and writes its response, if any.
+// A write failure is fatal for the transport, so it stops the transport via stop.
+func (t *StdioTransport) processMessage(ctx context.Context, line []byte, stop context.CancelFunc) {
+	reply := t.jsonrpcHandler.HandlePayload(ctx, line)
+
+	// Write response if not a notification
+	if reply.Empty() {
+		return
+	}
+
+	if err := t.writeMessage(reply); err != nil {
+		stop()
+	}
+}
+
+// writeMessage marshals v and writes it as a single message in the transport's framing.
+// Writes are serialized so concurrent responses never interleave.
+func (t *StdioTransport) writeMessage(v interface{}) error {
+	respBytes, err := json.Marshal(v)
+	if err != nil {
+		t.logger.Error("error marshaling response", "error", err)
+		return nil
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	if t.writeErr != nil {
+		return t.writeErr
+	}
+
+	t.protocolLog.record("send", respBytes)
+	if _, err := t.writer.Write(frameMessage(t.writeFraming, respBytes)); err != nil {
+		t.logger.Error("error writing response", "error", err)
+		t.writeErr = err
+		return err
+	}
+	return nil
+}
+
+// writeError returns the first write error encountered, if any
+func (t *StdioTransport) writeError() error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	return t.writeErr
+}
+
+// isNotification reports whether data is a single JSON-RPC notification (a method without an ID)
+func isNotification(data []byte) bool {
+	var probe struct {
+		ID     interface{} `json:"id"`
+		Method string      `json:"method"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	return probe.ID == nil && probe.Method != ""
+}
@@ -0,0 +1,148 @@
+package mcp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/mcptest"
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// staticTool returns a fixed result or error
+type staticTool struct {
+	name       string
+	parameters map[string]interface{}
+	result     *tools.ToolResult
+	err        error
+}
+
+func (s *staticTool) Spec() *tools.ToolSpec {
+	parameters := s.parameters
+	if parameters == nil {
+		parameters = map[string]interface{}{"type": "object"}
+	}
+	return &tools.ToolSpec{Name: s.name, Description: "Test tool " + s.name, Parameters: parameters}
+}
+
+func (s *staticTool) Execute(ctx context.Context, params json.RawMessage) (*tools.ToolResult, error) {
+	return s.result, s.err
+}
+
+func newStdioTestClient(t *testing.T, ts ...tools.Tool) *mcptest.Client {
+	server := mcp.NewServer(mcp.ServerConfig{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Tools:   ts,
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	return mcptest.NewStdioClient(t, server)
+}
+
+func TestStdioTransport_BasicInitialize(t *testing.T) {
+	server := mcp.NewServer(mcp.ServerConfig{Name: "test-server", Version: "1.0.0"})
+	c := mcptest.NewStdioClient(t, server, mcptest.WithoutInitialize())
+
+	var result mcp.InitializeResult
+	c.Call(mcp.MethodInitialize, mcp.InitializeParams{
+		ProtocolVersion: "2024-11-05",
+		ClientInfo:      mcp.ClientInfo{Name: "test-client", Version: "1.0"},
+	}).ExpectResult(&result)
+
+	if result.ServerInfo.Name != "test-server" || result.ServerInfo.Version != "1.0.0" {
+		t.Errorf("unexpected server info: %+v", result.ServerInfo)
+	}
+	if result.ProtocolVersion != "2024-11-05" {
+		t.Errorf("expected protocol version '2024-11-05', got %s", result.ProtocolVersion)
+	}
+}
+
+func TestStdioTransport_ToolsList(t *testing.T) {
+	c := newStdioTestClient(t)
+
+	var result mcp.ToolsListResult
+	c.Call(mcp.MethodToolsList, nil).ExpectResult(&result)
+	if result.Tools == nil {
+		t.Error("expected tools array, got nil")
+	}
+	if len(result.Tools) != 0 {
+		t.Errorf("expected 0 tools, got %d", len(result.Tools))
+	}
+}
+
+func TestStdioTransport_ToolsListWithTools(t *testing.T) {
+	echo := &staticTool{name: "echo", parameters: map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"message": map[string]interface{}{"type": "string"}},
+	}}
+	add := &staticTool{name: "add", parameters: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"a": map[string]interface{}{"type": "number"},
+			"b": map[string]interface{}{"type": "number"},
+		},
+	}}
+	c := newStdioTestClient(t, echo, add)
+
+	list := c.ListTools()
+	if len(list) != 2 {
+		t.Fatalf("expected 2 tools, got %d", len(list))
+	}
+	if list[0].Name != "echo" || list[1].Name != "add" {
+		t.Errorf("expected echo then add, got %s then %s", list[0].Name, list[1].Name)
+	}
+}
+
+func TestStdioTransport_ToolsCallSuccess(t *testing.T) {
+	echo := &staticTool{name: "echo", result: &tools.ToolResult{Output: "Hello, World!"}}
+	c := newStdioTestClient(t, echo)
+
+	result := c.CallTool("echo", map[string]string{"message": "Hello, World!"}).ExpectToolText("Hello, World!")
+	if len(result.Content) != 1 || result.Content[0].Type != "text" {
+		t.Errorf("expected one text block, got %+v", result.Content)
+	}
+}
+
+func TestStdioTransport_ToolsCallWithError(t *testing.T) {
+	errorMsg := "Something went wrong"
+	failing := &staticTool{name: "failing_tool", result: &tools.ToolResult{Error: &errorMsg}}
+	c := newStdioTestClient(t, failing)
+
+	result := c.CallTool("failing_tool", map[string]string{}).ExpectToolError()
+	if len(result.Content) != 1 || result.Content[0].Text != errorMsg {
+		t.Errorf("expected the error message as the only block, got %+v", result.Content)
+	}
+}
+
+func TestStdioTransport_ToolsCallExecutionError(t *testing.T) {
+	failing := &staticTool{name: "error_tool", err: errors.New("execution failed")}
+	c := newStdioTestClient(t, failing)
+
+	result := c.CallTool("error_tool", map[string]string{}).ExpectToolError()
+	if !strings.Contains(result.Content[0].Text, "Error executing tool") {
+		t.Errorf("expected error message to contain 'Error executing tool', got: %s", result.Content[0].Text)
+	}
+}
+
+func TestStdioTransport_ToolNotFound(t *testing.T) {
+	c := newStdioTestClient(t)
+
+	rpcErr := c.CallTool("nonexistent", map[string]string{}).ExpectRPCError(mcp.InvalidParams)
+	if !strings.Contains(rpcErr.Message, "Tool not found") {
+		t.Errorf("expected error message to contain 'Tool not found', got: %s", rpcErr.Message)
+	}
+}
+
+func TestStdioTransport_UnknownMethod(t *testing.T) {
+	c := newStdioTestClient(t)
+
+	rpcErr := c.Call("unknown/method", nil).ExpectRPCError(mcp.MethodNotFound)
+	if !strings.Contains(rpcErr.Message, "Method not found") {
+		t.Errorf("expected error message to contain 'Method not found', got: %s", rpcErr.Message)
+	}
+}
@@ -0,0 +1,138 @@
+package mcp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// StdioFraming defines how messages are delimited on the stdio transport
+type StdioFraming string
+
+const (
+	StdioFramingAuto          StdioFraming = "auto"           // detect from the first message read (default)
+	StdioFramingNewline       StdioFraming = "newline"        // one JSON message per line
+	StdioFramingContentLength StdioFraming = "content-length" // LSP-style Content-Length headers
+)
+
+// WithFraming sets how messages are delimited (default StdioFramingAuto). Auto-detection
+// picks Content-Length framing when the first message starts with a header, and newline
+// framing otherwise; responses use the same framing as the client's messages. Messages
+// written before the first one is read, such as list_changed notifications, are newline framed.
+func (t *StdioTransport) WithFraming(framing StdioFraming) *StdioTransport {
+	t.framing = framing
+	return t
+}
+
+// resolveFraming returns the configured framing, detecting it from the input in auto mode.
+// Writes use the resolved framing from then on.
+func (t *StdioTransport) resolveFraming(r *bufio.Reader) (StdioFraming, error) {
+	framing := t.framing
+	if framing == StdioFramingAuto || framing == "" {
+		var err error
+		if framing, err = detectFraming(r); err != nil {
+			return "", err
+		}
+		t.logger.Debug("detected stdio framing", "framing", framing)
+	}
+
+	t.writeMu.Lock()
+	t.writeFraming = framing
+	t.writeMu.Unlock()
+	return framing, nil
+}
+
+// detectFraming peeks at the first message: JSON starts with '{' or '[', while a
+// Content-Length header starts with a letter. Leading whitespace is skipped.
+func detectFraming(r *bufio.Reader) (StdioFraming, error) {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return "", err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			r.ReadByte()
+		case 'C', 'c':
+			return StdioFramingContentLength, nil
+		default:
+			return StdioFramingNewline, nil
+		}
+	}
+}
+
+// readFramedMessage reads one message in the given framing
+func readFramedMessage(r *bufio.Reader, framing StdioFraming, max int) (stdioMessage, error) {
+	if framing == StdioFramingContentLength {
+		return readContentLengthMessage(r, max)
+	}
+	return readMessage(r, max)
+}
+
+// readContentLengthMessage reads the headers and body of one Content-Length framed message.
+// A body longer than max is skipped and reported as oversized. Malformed headers are
+// returned as errors, since the message boundaries can no longer be found.
+func readContentLengthMessage(r *bufio.Reader, max int) (stdioMessage, error) {
+	length := -1
+	sawHeader := false
+	for {
+		line, err := r.ReadSlice('\n')
+		if err == io.EOF && !sawHeader && strings.TrimSpace(string(line)) == "" {
+			return stdioMessage{}, io.EOF
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return stdioMessage{}, fmt.Errorf("reading message header: %w", err)
+		}
+
+		header := strings.TrimRight(string(line), "\r\n")
+		if header == "" {
+			if !sawHeader {
+				// Tolerate blank lines between messages
+				continue
+			}
+			break
+		}
+		sawHeader = true
+
+		name, value, ok := strings.Cut(header, ":")
+		if !ok {
+			return stdioMessage{}, fmt.Errorf("invalid message header %q", header)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 0 {
+				return stdioMessage{}, fmt.Errorf("invalid Content-Length %q", strings.TrimSpace(value))
+			}
+			length = n
+		}
+	}
+	if length < 0 {
+		return stdioMessage{}, fmt.Errorf("message has no Content-Length header")
+	}
+
+	if length > max {
+		if _, err := r.Discard(length); err != nil {
+			return stdioMessage{}, fmt.Errorf("reading message body: %w", io.ErrUnexpectedEOF)
+		}
+		return stdioMessage{oversized: length}, nil
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return stdioMessage{}, fmt.Errorf("reading message body: %w", io.ErrUnexpectedEOF)
+	}
+	return stdioMessage{data: data}, nil
+}
+
+// frameMessage delimits a marshaled message for writing
+func frameMessage(framing StdioFraming, data []byte) []byte {
+	if framing == StdioFramingContentLength {
+		header := "Content-Length: " + strconv.Itoa(len(data)) + "\r\n\r\n"
+		return append([]byte(header), data...)
+	}
+	return append(data, '\n')
+}
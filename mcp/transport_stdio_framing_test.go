@@ -0,0 +1,106 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func contentLengthFrame(body string) string {
+	return "Content-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n" + body
+}
+
+func TestStdioTransport_ContentLengthFraming(t *testing.T) {
+	tests := []struct {
+		name    string
+		framing StdioFraming
+	}{
+		{"detected", StdioFramingAuto},
+		{"configured", StdioFramingContentLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(ServerConfig{Name: "test-server", Version: "1.0.0"})
+			input := bytes.NewBufferString(
+				contentLengthFrame(`{"jsonrpc":"2.0","id":1,"method":"ping"}`) +
+					contentLengthFrame(`{"jsonrpc":"2.0","id":2,"method":"tools/list","params":{"padding":"`+strings.Repeat("x", 100)+`"}}`) +
+					"Content-Type: application/vscode-jsonrpc; charset=utf-8\r\n" +
+					contentLengthFrame(`{"jsonrpc":"2.0","id":3,"method":"ping"}`))
+			var output bytes.Buffer
+			transport := NewStdioTransportWithIO(server, nil, input, &output).
+				WithFraming(tt.framing).
+				WithMaxMessageBytes(64)
+
+			if err := transport.Start(context.Background()); err != nil {
+				t.Fatalf("Start: %v", err)
+			}
+
+			reader := bufio.NewReader(&output)
+			var responses []JSONRPCResponse
+			for {
+				msg, err := readContentLengthMessage(reader, DefaultMaxMessageBytes)
+				if err != nil {
+					break
+				}
+				var response JSONRPCResponse
+				if err := json.Unmarshal(msg.data, &response); err != nil {
+					t.Fatalf("invalid response %q: %v", msg.data, err)
+				}
+				responses = append(responses, response)
+			}
+			if len(responses) != 3 {
+				t.Fatalf("expected three Content-Length framed responses, got %d", len(responses))
+			}
+			// The parse error is written as soon as the oversized message is read, so
+			// responses are matched by ID rather than position
+			var ids []interface{}
+			parseErrors := 0
+			for _, response := range responses {
+				if response.Error != nil && response.Error.Code == ParseError {
+					parseErrors++
+					continue
+				}
+				ids = append(ids, response.ID)
+			}
+			if parseErrors != 1 || len(ids) != 2 || ids[0] != float64(1) || ids[1] != float64(3) {
+				t.Errorf("expected responses to 1 and 3 and one parse error, got %+v", responses)
+			}
+		})
+	}
+}
+
+func TestStdioTransport_NewlineFramingDetected(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server", Version: "1.0.0"})
+	input := bytes.NewBufferString("\n" + `{"jsonrpc":"2.0","id":1,"method":"ping"}` + "\n")
+	var output bytes.Buffer
+	if err := NewStdioTransportWithIO(server, nil, input, &output).Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if got := output.String(); !strings.HasPrefix(got, `{"jsonrpc":"2.0","id":1`) || !strings.HasSuffix(got, "}\n") {
+		t.Errorf("expected a newline framed response, got %q", got)
+	}
+}
+
+func TestReadContentLengthMessage_Errors(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"missing length", "Content-Type: application/json\r\n\r\n{}", "no Content-Length"},
+		{"invalid length", "Content-Length: ten\r\n\r\n{}", `invalid Content-Length "ten"`},
+		{"malformed header", "{}\r\n\r\n", "invalid message header"},
+		{"short body", "Content-Length: 10\r\n\r\n{}", "unexpected EOF"},
+		{"truncated headers", "Content-Length: 2\r\n", "unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readContentLengthMessage(bufio.NewReader(strings.NewReader(tt.input)), 1024)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -743,3 +744,226 @@ func TestHTTPTransport_MCP_InvalidJSON(t *testing.T) {
 		t.Errorf("expected parse error code, got %d", response.Error.Code)
 	}
 }
+
+func TestHTTPTransport_ListToolsMatchesJSONRPC(t *testing.T) {
+	logger := slog.Default()
+
+	tool := tools.NewTool("lookup", "Looks things up", func(ctx context.Context, in struct {
+		Key string `json:"key"`
+	}) (struct {
+		Value string `json:"value"`
+	}, error) {
+		return struct {
+			Value string `json:"value"`
+		}{}, nil
+	}, tools.WithDestructive(true))
+
+	server := NewServer(ServerConfig{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Tools:   []tools.Tool{tool},
+		Logger:  logger,
+	})
+
+	transport := NewHTTPTransport(server, logger, newMockValidator("test-key"))
+
+	req := httptest.NewRequest(http.MethodGet, "/mcp/tools/list", nil)
+	req.Header.Set("Authorization", "Bearer test-key")
+	w := httptest.NewRecorder()
+	transport.ServeHTTP(w, req)
+
+	var restList ToolsListResult
+	if err := json.NewDecoder(w.Body).Decode(&restList); err != nil {
+		t.Fatalf("failed to decode REST response: %v", err)
+	}
+
+	resp, err := NewJSONRPCHandler(server).HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	if err != nil {
+		t.Fatalf("HandleMessage failed: %v", err)
+	}
+	resultBytes, _ := json.Marshal(resp.Result)
+	restBytes, _ := json.Marshal(restList)
+
+	if string(resultBytes) != string(restBytes) {
+		t.Errorf("REST and JSON-RPC tool lists differ:\nREST:     %s\nJSON-RPC: %s", restBytes, resultBytes)
+	}
+
+	desc := restList.Tools[0]
+	if desc.OutputSchema == nil {
+		t.Error("expected output schema in REST listing")
+	}
+	if desc.Annotations == nil || desc.Annotations.DestructiveHint == nil || !*desc.Annotations.DestructiveHint {
+		t.Error("expected destructiveHint annotation in REST listing")
+	}
+	if _, ok := desc.InputSchema["required"]; !ok {
+		t.Error("expected input schema with required array")
+	}
+}
+
+func TestServer_Handler(t *testing.T) {
+	server := NewServer(ServerConfig{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Tools:   []tools.Tool{},
+	})
+
+	ts := httptest.NewServer(server.Handler(HandlerOptions{
+		Validator:      newMockValidator("test-key"),
+		AuthHeaderType: AuthHeaderAPIKey,
+	}))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/mcp/health")
+	if err != nil {
+		t.Fatalf("health request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected health status 200, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	req.Header.Set("X-API-Key", "test-key")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("MCP request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	var response JSONRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Error != nil {
+		t.Errorf("expected no error, got %v", response.Error)
+	}
+}
+
+func TestHTTPTransport_CallTool_Panic(t *testing.T) {
+	logger := slog.Default()
+	panicky := &mockTool{
+		name:        "panicky",
+		description: "Always panics",
+		parameters:  map[string]interface{}{"type": "object"},
+		executeFn: func(ctx context.Context, params json.RawMessage) (*tools.ToolResult, error) {
+			panic("boom")
+		},
+	}
+
+	server := NewServer(ServerConfig{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Tools:   []tools.Tool{panicky},
+		Logger:  logger,
+	})
+	transport := NewHTTPTransport(server, logger, newMockValidator("test-key"))
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp/tools/call", strings.NewReader(`{"name":"panicky","arguments":{}}`))
+	req.Header.Set("Authorization", "Bearer test-key")
+	w := httptest.NewRecorder()
+	transport.ServeHTTP(w, req)
+
+	// A panic is an internal error, as it is for tools/call over JSON-RPC
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500 for a panicking tool, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHTTPTransport_MaxRequestBytes(t *testing.T) {
+	logger := slog.Default()
+	server := NewServer(ServerConfig{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Tools:   []tools.Tool{},
+		Logger:  logger,
+	})
+
+	transport := NewHTTPTransport(server, logger, newMockValidator("test-key")).
+		WithMaxRequestBytes(16)
+
+	for _, path := range []string{"/mcp", "/mcp/tools/call"} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		req.Header.Set("Authorization", "Bearer test-key")
+		w := httptest.NewRecorder()
+		transport.ServeHTTP(w, req)
+
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("%s: expected status 413, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestHTTPTransport_RouteOptions(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server", Version: "1.0.0"})
+	validator := newMockValidator("test-key")
+
+	status := func(transport *HTTPTransport, path string, authed bool) int {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		if authed {
+			req.Header.Set("Authorization", "Bearer test-key")
+		}
+		w := httptest.NewRecorder()
+		transport.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	transport := NewHTTPTransport(server, slog.Default(), validator).
+		WithRESTEndpoints(false).
+		WithHealthEndpoint(false)
+	if code := status(transport, "/mcp/tools/list", true); code != http.StatusNotFound {
+		t.Errorf("expected 404 for disabled REST route, got %d", code)
+	}
+	if code := status(transport, "/mcp/health", false); code != http.StatusNotFound {
+		t.Errorf("expected 404 for disabled health route, got %d", code)
+	}
+
+	transport = NewHTTPTransport(server, slog.Default(), validator).WithHealthAuth(true)
+	if code := status(transport, "/mcp/health", false); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for unauthenticated health check, got %d", code)
+	}
+	if code := status(transport, "/mcp/health", true); code != http.StatusOK {
+		t.Errorf("expected 200 for authenticated health check, got %d", code)
+	}
+}
+
+func TestHTTPTransport_BatchLimits(t *testing.T) {
+	server := NewServer(ServerConfig{Name: "test-server", Version: "1.0.0"})
+	transport := NewHTTPTransport(server, slog.Default(), newMockValidator("test-key")).
+		WithMaxBatchSize(2)
+
+	post := func(body string) (*httptest.ResponseRecorder, JSONRPCResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-key")
+		w := httptest.NewRecorder()
+		transport.ServeHTTP(w, req)
+		var response JSONRPCResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	list := `{"jsonrpc":"2.0","id":%d,"method":"tools/list"}`
+	w, response := post("[" + fmt.Sprintf(list, 1) + "," + fmt.Sprintf(list, 2) + "," + fmt.Sprintf(list, 3) + "]")
+	if w.Code != http.StatusRequestEntityTooLarge || response.Error == nil || response.Error.Code != InvalidRequest {
+		t.Errorf("expected oversized batch to be rejected with a JSON-RPC error, got %d: %s", w.Code, w.Body.String())
+	}
+
+	_, response = post("[]")
+	if response.Error == nil || response.Error.Code != InvalidRequest {
+		t.Errorf("expected InvalidRequest for empty batch, got %+v", response)
+	}
+
+	w, _ = post("[" + fmt.Sprintf(list, 1) + "," + fmt.Sprintf(list, 2) + "]")
+	if w.Code != http.StatusOK {
+		t.Errorf("expected batch within the limit to succeed, got %d", w.Code)
+	}
+
+	transport.WithMaxRequestBytes(8)
+	w, response = post(fmt.Sprintf(list, 1))
+	if w.Code != http.StatusRequestEntityTooLarge || response.Error == nil {
+		t.Errorf("expected oversized body to return a JSON-RPC error, got %d: %s", w.Code, w.Body.String())
+	}
+}
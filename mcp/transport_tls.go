@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// ClientCertConfig enables mutual TLS. Requests presenting a verified client certificate
+// are authenticated by it; the API key validator handles requests without one, unless
+// Require rejects them at the TLS handshake.
+type ClientCertConfig struct {
+	// ClientCAs verifies client certificates. Required.
+	ClientCAs *x509.CertPool
+
+	// Require rejects connections that do not present a valid client certificate
+	Require bool
+
+	// Principal maps a verified certificate to the caller's principal. Defaults to the
+	// certificate's common name as subject, with its DNS names, email addresses, and
+	// serial number as claims. Returning an error rejects the request.
+	Principal func(cert *x509.Certificate) (*Principal, error)
+}
+
+// WithTLSConfig sets the TLS configuration used by StartTLS. Certificates in cfg are used
+// when StartTLS is called without certificate files.
+func (t *HTTPTransport) WithTLSConfig(cfg *tls.Config) *HTTPTransport {
+	t.tlsConfig = cfg
+	return t
+}
+
+// WithClientCertAuth enables mutual TLS for StartTLS and maps verified client certificates
+// into the auth layer, so tools see the certificate's principal via PrincipalFrom
+func (t *HTTPTransport) WithClientCertAuth(cfg ClientCertConfig) *HTTPTransport {
+	t.clientCert = &cfg
+	return t
+}
+
+// WithHTTPSRedirect makes StartTLS also listen for plaintext HTTP on httpPort,
+// permanently redirecting every request to HTTPS
+func (t *HTTPTransport) WithHTTPSRedirect(httpPort string) *HTTPTransport {
+	t.redirectPort = httpPort
+	return t
+}
+
+// StartTLS starts the HTTPS server on the specified port with graceful shutdown support.
+// certFile and keyFile may be empty when WithTLSConfig supplies the certificates.
+func (t *HTTPTransport) StartTLS(ctx context.Context, port, certFile, keyFile string) error {
+	addr := ":" + port
+	t.logger.Info("starting MCP HTTPS server", "addr", addr, "mtls", t.clientCert != nil)
+
+	server, err := t.newHTTPSServer(addr, certFile)
+	if err != nil {
+		return err
+	}
+
+	var extra []*http.Server
+	if t.redirectPort != "" {
+		t.logger.Info("redirecting HTTP to HTTPS", "addr", ":"+t.redirectPort)
+		extra = append(extra, t.newHTTPServer(":"+t.redirectPort, httpsRedirect(port)))
+	}
+
+	return t.serve(ctx, server, func() error {
+		return server.ListenAndServeTLS(certFile, keyFile)
+	}, extra...)
+}
+
+// ServeTLS serves MCP over HTTPS on l until ctx is cancelled, as StartTLS does on a port.
+// WithHTTPSRedirect does not apply; l is closed on return.
+func (t *HTTPTransport) ServeTLS(ctx context.Context, l net.Listener, certFile, keyFile string) error {
+	t.logger.Info("starting MCP HTTPS server", "addr", l.Addr().String(), "mtls", t.clientCert != nil)
+
+	server, err := t.newHTTPSServer(l.Addr().String(), certFile)
+	if err != nil {
+		l.Close()
+		return err
+	}
+	return t.serve(ctx, server, func() error {
+		return server.ServeTLS(l, certFile, keyFile)
+	})
+}
+
+// newHTTPSServer creates the server for StartTLS and ServeTLS, checking that certificates
+// are available from certFile or the TLS config
+func (t *HTTPTransport) newHTTPSServer(addr, certFile string) (*http.Server, error) {
+	tlsConfig, err := t.serverTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if certFile == "" && len(tlsConfig.Certificates) == 0 && tlsConfig.GetCertificate == nil {
+		return nil, errors.New("serving TLS requires certificate files or a TLS config with certificates")
+	}
+
+	server := t.newHTTPServer(addr, t)
+	server.TLSConfig = tlsConfig
+	return server, nil
+}
+
+// serverTLSConfig builds the TLS configuration for StartTLS
+func (t *HTTPTransport) serverTLSConfig() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if t.tlsConfig != nil {
+		cfg = t.tlsConfig.Clone()
+	}
+
+	if t.clientCert != nil {
+		if t.clientCert.ClientCAs == nil {
+			return nil, errors.New("client certificate auth requires ClientCAs")
+		}
+		cfg.ClientCAs = t.clientCert.ClientCAs
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+		if t.clientCert.Require {
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+	return cfg, nil
+}
+
+// clientCertPrincipal returns the principal for a verified client certificate on r,
+// or nil when client certificate auth is disabled or no certificate was presented
+func (t *HTTPTransport) clientCertPrincipal(r *http.Request) (*Principal, error) {
+	if t.clientCert == nil || r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+		return nil, nil
+	}
+
+	cert := r.TLS.VerifiedChains[0][0]
+	if t.clientCert.Principal != nil {
+		principal, err := t.clientCert.Principal(cert)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+		}
+		return principal, nil
+	}
+
+	return &Principal{
+		Subject: cert.Subject.CommonName,
+		Claims: map[string]interface{}{
+			"dns_names": cert.DNSNames,
+			"emails":    cert.EmailAddresses,
+			"serial":    cert.SerialNumber.String(),
+			"issuer":    cert.Issuer.String(),
+		},
+	}, nil
+}
+
+// httpsRedirect redirects plaintext requests to the same host and path on the HTTPS port
+func httpsRedirect(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
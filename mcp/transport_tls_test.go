@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// issueCert creates a certificate signed by parent (self-signed when parent is nil)
+func issueCert(t *testing.T, cn string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, isCA bool) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if isCA {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing certificate: %v", err)
+	}
+	return cert, key
+}
+
+func TestHTTPTransport_ClientCertAuth(t *testing.T) {
+	caCert, caKey := issueCert(t, "test-ca", nil, nil, true)
+	serverCert, serverKey := issueCert(t, "server", caCert, caKey, false)
+	clientCert, clientKey := issueCert(t, "agent-7", caCert, caKey, false)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+
+	whoami := tools.NewTool("whoami", "Returns the caller", func(ctx context.Context, in struct{}) (string, error) {
+		return PrincipalFrom(ctx).Subject, nil
+	})
+	server := NewServer(ServerConfig{Name: "test-server", Version: "1.0.0", Tools: []tools.Tool{whoami}})
+	transport := NewHTTPTransport(server, slog.Default(), newMockValidator("test-key")).
+		WithTLSConfig(&tls.Config{
+			Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.Raw}, PrivateKey: serverKey}},
+		}).
+		WithClientCertAuth(ClientCertConfig{ClientCAs: pool})
+
+	tlsConfig, err := transport.serverTLSConfig()
+	if err != nil {
+		t.Fatalf("serverTLSConfig failed: %v", err)
+	}
+	ts := httptest.NewUnstartedServer(transport)
+	ts.TLS = tlsConfig
+	ts.StartTLS()
+	defer ts.Close()
+
+	newClient := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      pool,
+			Certificates: certs,
+		}}}
+	}
+
+	withCert := newClient(tls.Certificate{Certificate: [][]byte{clientCert.Raw}, PrivateKey: clientKey})
+	resp, err := withCert.Post(ts.URL+"/mcp/tools/call", "application/json",
+		strings.NewReader(`{"name":"whoami","arguments":{}}`))
+	if err != nil {
+		t.Fatalf("request with client cert failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "agent-7") {
+		t.Errorf("expected certificate principal agent-7, got %d: %s", resp.StatusCode, body)
+	}
+
+	// Without a certificate the API key validator still applies
+	resp, err = newClient().Post(ts.URL+"/mcp/tools/list", "application/json", nil)
+	if err != nil {
+		t.Fatalf("request without client cert failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 without certificate or key, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPSRedirect(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://mcp.example.com:8080/mcp/health?x=1", nil)
+	w := httptest.NewRecorder()
+	httpsRedirect("8443").ServeHTTP(w, req)
+
+	if w.Code != http.StatusPermanentRedirect {
+		t.Fatalf("expected 308, got %d", w.Code)
+	}
+	if loc := w.Header().Get("Location"); loc != "https://mcp.example.com:8443/mcp/health?x=1" {
+		t.Errorf("unexpected redirect location %q", loc)
+	}
+}
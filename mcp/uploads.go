@@ -0,0 +1,231 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// DefaultMaxUploadBytes is the largest upload accepted by default
+const DefaultMaxUploadBytes = 1 << 30 // 1GB
+
+// Upload describes an input stored out-of-band for later use in tools/call
+type Upload struct {
+	Handle      tools.UploadHandle `json:"handle"`
+	Size        int64              `json:"size"`
+	ContentType string             `json:"contentType,omitempty"`
+	Expires     time.Time          `json:"expires"`
+}
+
+// UploadStore holds uploaded inputs. Handles must be unguessable, and an upload must only
+// be opened by the caller (principal or session) that created it.
+type UploadStore interface {
+	tools.UploadOpener
+	Put(ctx context.Context, r io.Reader, contentType string) (*Upload, error)
+	Delete(ctx context.Context, handle tools.UploadHandle) error
+}
+
+// DiskUploadStore keeps uploads as files in a directory, removing them once they expire
+type DiskUploadStore struct {
+	dir string
+	ttl time.Duration
+
+	mu      sync.Mutex
+	uploads map[tools.UploadHandle]*diskUpload
+	now     func() time.Time
+}
+
+type diskUpload struct {
+	Upload
+	owner string
+	path  string
+}
+
+// NewDiskUploadStore creates a store writing uploads to dir (the system temp directory when
+// empty). Uploads expire after ttl (default 1h).
+func NewDiskUploadStore(dir string, ttl time.Duration) (*DiskUploadStore, error) {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating upload directory: %w", err)
+	}
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+	return &DiskUploadStore{
+		dir:     dir,
+		ttl:     ttl,
+		uploads: make(map[tools.UploadHandle]*diskUpload),
+		now:     time.Now,
+	}, nil
+}
+
+// Put streams r to disk and returns its handle
+func (s *DiskUploadStore) Put(ctx context.Context, r io.Reader, contentType string) (*Upload, error) {
+	s.sweep()
+
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, fmt.Errorf("generating upload handle: %w", err)
+	}
+	handle := tools.UploadHandle("upload-" + hex.EncodeToString(id[:]))
+
+	f, err := os.CreateTemp(s.dir, "minimcp-upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating upload file: %w", err)
+	}
+	size, err := io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("writing upload: %w", err)
+	}
+
+	upload := &diskUpload{
+		Upload: Upload{
+			Handle:      handle,
+			Size:        size,
+			ContentType: contentType,
+			Expires:     s.now().Add(s.ttl),
+		},
+		owner: uploadOwner(ctx),
+		path:  f.Name(),
+	}
+
+	s.mu.Lock()
+	s.uploads[handle] = upload
+	s.mu.Unlock()
+
+	info := upload.Upload
+	return &info, nil
+}
+
+// OpenUpload opens an upload created by the same caller
+func (s *DiskUploadStore) OpenUpload(ctx context.Context, handle tools.UploadHandle) (io.ReadCloser, error) {
+	upload, err := s.lookup(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(upload.path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", tools.ErrUploadNotFound, err)
+	}
+	return f, nil
+}
+
+// Delete removes an upload created by the same caller
+func (s *DiskUploadStore) Delete(ctx context.Context, handle tools.UploadHandle) error {
+	upload, err := s.lookup(ctx, handle)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.uploads, handle)
+	s.mu.Unlock()
+	return os.Remove(upload.path)
+}
+
+// lookup returns an unexpired upload owned by the caller in ctx
+func (s *DiskUploadStore) lookup(ctx context.Context, handle tools.UploadHandle) (*diskUpload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upload, ok := s.uploads[handle]
+	if !ok || upload.owner != uploadOwner(ctx) || !s.now().Before(upload.Expires) {
+		return nil, fmt.Errorf("%w: %s", tools.ErrUploadNotFound, handle)
+	}
+	return upload, nil
+}
+
+// sweep removes expired uploads
+func (s *DiskUploadStore) sweep() {
+	s.mu.Lock()
+	var expired []string
+	now := s.now()
+	for handle, upload := range s.uploads {
+		if !now.Before(upload.Expires) {
+			expired = append(expired, upload.path)
+			delete(s.uploads, handle)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, path := range expired {
+		os.Remove(path)
+	}
+}
+
+// uploadOwner identifies who may open an upload: the authenticated principal when there
+// is one, so uploads follow the caller across connections, else the session. Without
+// either, the unguessable handle itself is the only credential.
+func uploadOwner(ctx context.Context) string {
+	if p := PrincipalFrom(ctx); p != nil && p.Subject != "" {
+		return "principal:" + p.Subject
+	}
+	if hasStatefulSession(ctx) {
+		return "session:" + sessionFrom(ctx)
+	}
+	return ""
+}
+
+// WithMaxUploadBytes sets the largest body accepted by the upload endpoint (default 1GB)
+func (t *HTTPTransport) WithMaxUploadBytes(n int64) *HTTPTransport {
+	t.maxUploadBytes = n
+	return t
+}
+
+// handleUpload streams the request body into the server's upload store and returns its handle.
+// The raw body is the content; its Content-Type is recorded alongside it.
+func (t *HTTPTransport) handleUpload(w http.ResponseWriter, r *http.Request) {
+	if t.server.uploads == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body := io.Reader(r.Body)
+	if t.maxUploadBytes > 0 {
+		body = http.MaxBytesReader(w, r.Body, t.maxUploadBytes)
+	}
+
+	upload, err := t.server.uploads.Put(r.Context(), body, r.Header.Get("Content-Type"))
+	if err != nil {
+		t.logger.Error("upload failed", "error", err)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "upload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "upload failed", http.StatusInternalServerError)
+		return
+	}
+
+	t.logger.Info("stored upload", "handle", upload.Handle, "size", upload.Size)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(upload)
+}
+
+// attachUploads makes the server's upload store available to tools via tools.OpenUpload
+func (s *Server) attachUploads(ctx context.Context) context.Context {
+	if s.uploads == nil {
+		return ctx
+	}
+	return tools.WithUploads(ctx, s.uploads)
+}
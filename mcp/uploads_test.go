@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func TestHTTPTransport_UploadAndReference(t *testing.T) {
+	store, err := NewDiskUploadStore(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("NewDiskUploadStore failed: %v", err)
+	}
+
+	type countInput struct {
+		Document tools.UploadHandle `json:"document"`
+	}
+	count := tools.NewTool("count_bytes", "Counts the bytes of an uploaded document", func(ctx context.Context, in countInput) (int64, error) {
+		r, err := tools.OpenUpload(ctx, in.Document)
+		if err != nil {
+			return 0, err
+		}
+		defer r.Close()
+		return io.Copy(io.Discard, r)
+	})
+
+	server := NewServer(ServerConfig{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Tools:   []tools.Tool{count},
+		Uploads: store,
+	})
+	transport := NewHTTPTransport(server, slog.Default(), newMockValidator("test-key")).
+		WithMaxUploadBytes(1 << 20)
+
+	payload := strings.Repeat("x", 300_000)
+	req := httptest.NewRequest(http.MethodPost, "/mcp/uploads", strings.NewReader(payload))
+	req.Header.Set("Authorization", "Bearer test-key")
+	req.Header.Set("Content-Type", "text/plain")
+	w := httptest.NewRecorder()
+	transport.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var upload Upload
+	if err := json.NewDecoder(w.Body).Decode(&upload); err != nil {
+		t.Fatalf("failed to decode upload: %v", err)
+	}
+	if upload.Size != int64(len(payload)) || upload.ContentType != "text/plain" {
+		t.Errorf("unexpected upload: %+v", upload)
+	}
+
+	body := `{"name":"count_bytes","arguments":{"document":"` + string(upload.Handle) + `"}}`
+	req = httptest.NewRequest(http.MethodPost, "/mcp/tools/call", bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer test-key")
+	w = httptest.NewRecorder()
+	transport.ServeHTTP(w, req)
+
+	if !strings.Contains(w.Body.String(), "300000") {
+		t.Errorf("expected tool to read the uploaded bytes, got %s", w.Body.String())
+	}
+
+	// Uploads beyond the limit are rejected
+	req = httptest.NewRequest(http.MethodPost, "/mcp/uploads", strings.NewReader(strings.Repeat("x", 2<<20)))
+	req.Header.Set("Authorization", "Bearer test-key")
+	w = httptest.NewRecorder()
+	transport.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d", w.Code)
+	}
+}
+
+func TestDiskUploadStore_OwnerIsolation(t *testing.T) {
+	store, err := NewDiskUploadStore(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("NewDiskUploadStore failed: %v", err)
+	}
+
+	alice := WithPrincipal(context.Background(), &Principal{Subject: "alice"})
+	bob := WithPrincipal(context.Background(), &Principal{Subject: "bob"})
+
+	upload, err := store.Put(alice, strings.NewReader("secret"), "")
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	if _, err := store.OpenUpload(bob, upload.Handle); !errors.Is(err, tools.ErrUploadNotFound) {
+		t.Errorf("expected another principal to be refused, got %v", err)
+	}
+
+	r, err := store.OpenUpload(alice, upload.Handle)
+	if err != nil {
+		t.Fatalf("OpenUpload failed: %v", err)
+	}
+	r.Close()
+
+	if err := store.Delete(alice, upload.Handle); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.OpenUpload(alice, upload.Handle); !errors.Is(err, tools.ErrUploadNotFound) {
+		t.Errorf("expected deleted upload to be gone, got %v", err)
+	}
+}
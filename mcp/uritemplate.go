@@ -0,0 +1,247 @@
+package mcp
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// URITemplate is a parsed RFC 6570 URI template, such as "db://{schema}/{table}". It
+// supports the level 3 operators: simple {var}, reserved {+var}, fragment {#var}, label
+// {.var}, path {/var}, path-style parameter {;var}, and query {?var} and {&var}.
+// Prefix and explode modifiers are not supported.
+type URITemplate struct {
+	raw     string
+	parts   []templatePart
+	pattern *regexp.Regexp
+}
+
+// templatePart is a literal, or an expression when vars is set
+type templatePart struct {
+	literal string
+	op      byte
+	vars    []string
+}
+
+// templateOperators maps each operator to how its expansion is joined and encoded
+var templateOperators = map[byte]struct {
+	first, sep string
+	named      bool
+	reserved   bool // reserved characters are kept rather than percent-encoded
+}{
+	0:   {"", ",", false, false},
+	'+': {"", ",", false, true},
+	'#': {"#", ",", false, true},
+	'.': {".", ".", false, false},
+	'/': {"/", "/", false, false},
+	';': {";", ";", true, false},
+	'?': {"?", "&", true, false},
+	'&': {"&", "&", true, false},
+}
+
+// simpleValue matches the expansion of one simple variable
+const simpleValue = `([A-Za-z0-9\-._~%]+?)`
+
+var templateVarName = regexp.MustCompile(`^[A-Za-z0-9_]+(\.[A-Za-z0-9_]+)*$`)
+
+// ParseURITemplate parses an RFC 6570 URI template
+func ParseURITemplate(raw string) (*URITemplate, error) {
+	t := &URITemplate{raw: raw}
+	rest := raw
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			t.parts = append(t.parts, templatePart{literal: rest})
+			break
+		}
+		if open > 0 {
+			t.parts = append(t.parts, templatePart{literal: rest[:open]})
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("uri template %q: unclosed expression", raw)
+		}
+		expr := rest[open+1 : open+end]
+		rest = rest[open+end+1:]
+
+		part := templatePart{}
+		if expr != "" {
+			if _, ok := templateOperators[expr[0]]; ok && expr[0] != 0 {
+				part.op, expr = expr[0], expr[1:]
+			}
+		}
+		for _, name := range strings.Split(expr, ",") {
+			if !templateVarName.MatchString(name) {
+				return nil, fmt.Errorf("uri template %q: invalid variable %q", raw, name)
+			}
+			part.vars = append(part.vars, name)
+		}
+		t.parts = append(t.parts, part)
+	}
+
+	pattern, err := regexp.Compile("^" + t.matchPattern() + "$")
+	if err != nil {
+		return nil, fmt.Errorf("uri template %q: %w", raw, err)
+	}
+	t.pattern = pattern
+	return t, nil
+}
+
+// String returns the template as written
+func (t *URITemplate) String() string {
+	return t.raw
+}
+
+// Expand substitutes vars into the template, percent-encoding them as the operators
+// require. Variables missing from vars are left out of the expansion.
+func (t *URITemplate) Expand(vars map[string]string) string {
+	var b strings.Builder
+	for _, part := range t.parts {
+		if part.vars == nil {
+			b.WriteString(part.literal)
+			continue
+		}
+		op := templateOperators[part.op]
+		first := true
+		for _, name := range part.vars {
+			value, ok := vars[name]
+			if !ok {
+				continue
+			}
+			if first {
+				b.WriteString(op.first)
+				first = false
+			} else {
+				b.WriteString(op.sep)
+			}
+			if op.named {
+				b.WriteString(name)
+				if value == "" && part.op == ';' {
+					continue
+				}
+				b.WriteByte('=')
+			}
+			b.WriteString(encodeTemplateValue(value, op.reserved))
+		}
+	}
+	return b.String()
+}
+
+// Match reports whether uri is an expansion of the template and returns the decoded
+// values of its variables. Simple and reserved variables must be non-empty.
+func (t *URITemplate) Match(uri string) (map[string]string, bool) {
+	m := t.pattern.FindStringSubmatch(uri)
+	if m == nil {
+		return nil, false
+	}
+
+	vars := make(map[string]string)
+	query := url.Values{}
+	var queryVars []string
+	group := 1
+	for _, part := range t.parts {
+		if part.vars == nil {
+			continue
+		}
+		switch part.op {
+		case '?', '&':
+			// Query expressions may capture each other's parameters, so they are read together
+			values, err := url.ParseQuery(strings.TrimLeft(m[group], "?&"))
+			if err != nil {
+				return nil, false
+			}
+			for name, v := range values {
+				query[name] = append(query[name], v...)
+			}
+			queryVars = append(queryVars, part.vars...)
+			group++
+		case ';':
+			for _, name := range part.vars {
+				if m[group] != "" {
+					value, err := url.PathUnescape(strings.TrimPrefix(m[group+1], "="))
+					if err != nil {
+						return nil, false
+					}
+					vars[name] = value
+				}
+				group += 2
+			}
+		default:
+			for _, name := range part.vars {
+				value, err := url.PathUnescape(m[group])
+				if err != nil {
+					return nil, false
+				}
+				if value != "" {
+					vars[name] = value
+				}
+				group++
+			}
+		}
+	}
+	for _, name := range queryVars {
+		if values, ok := query[name]; ok {
+			vars[name] = values[0]
+		}
+	}
+	return vars, true
+}
+
+// matchPattern builds the regular expression matching expansions of the template. Each
+// variable is one capture group; query expressions capture their whole query string.
+func (t *URITemplate) matchPattern() string {
+	var b strings.Builder
+	for _, part := range t.parts {
+		if part.vars == nil {
+			b.WriteString(regexp.QuoteMeta(part.literal))
+			continue
+		}
+		switch part.op {
+		case 0:
+			// Simple expansion encodes everything but unreserved characters. Matching lazily
+			// leaves a following label or parameter expression its share.
+			b.WriteString(strings.Repeat(simpleValue+`,`, len(part.vars)-1) + simpleValue)
+		case '+':
+			if len(part.vars) == 1 {
+				// A lone reserved variable may hold commas
+				b.WriteString(`([^?#]+)`)
+				break
+			}
+			b.WriteString(strings.Repeat(`([^?#,]+),`, len(part.vars)-1) + `([^?#,]+)`)
+		case '#':
+			b.WriteString(`(?:#` + strings.Repeat(`([^,]*),`, len(part.vars)-1) + `(.*))?`)
+		case '.':
+			b.WriteString(strings.Repeat(`(?:\.([^/?#.]*))?`, len(part.vars)))
+		case '/':
+			b.WriteString(strings.Repeat(`(?:/([^/?#]*))?`, len(part.vars)))
+		case ';':
+			for _, name := range part.vars {
+				b.WriteString(`(;` + regexp.QuoteMeta(name) + `(=[^;/?#]*)?)?`)
+			}
+		case '?', '&':
+			b.WriteString(`([?&][^#]*)?`)
+		}
+	}
+	return b.String()
+}
+
+// encodeTemplateValue percent-encodes a value for expansion. Unreserved characters are
+// always kept; reserved characters are kept only for reserved expansion.
+func encodeTemplateValue(value string, reserved bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if isUnreserved(c) || (reserved && strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
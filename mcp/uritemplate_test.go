@@ -0,0 +1,56 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestURITemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		vars     map[string]string
+		uri      string
+	}{
+		{"db://{schema}/{table}", map[string]string{"schema": "public", "table": "users"}, "db://public/users"},
+		{"db://{schema}/{table}", map[string]string{"schema": "my schema", "table": "a/b"}, "db://my%20schema/a%2Fb"},
+		{"file://{+path}", map[string]string{"path": "docs/guide,v2.md"}, "file://docs/guide,v2.md"},
+		{"repo://{owner}{/repo,branch}", map[string]string{"owner": "acme", "repo": "api"}, "repo://acme/api"},
+		{"logs://{service}{?since,level}", map[string]string{"service": "web", "since": "2025-01-01", "level": "warn"}, "logs://web?since=2025-01-01&level=warn"},
+		{"logs://{service}{?since}{&level}", map[string]string{"service": "web", "level": "a b"}, "logs://web&level=a%20b"},
+		{"doc://{id}{#section}", map[string]string{"id": "7", "section": "intro"}, "doc://7#intro"},
+		{"img://{name}{.ext}", map[string]string{"name": "logo", "ext": "png"}, "img://logo.png"},
+		{"map://{x,y}{;zoom}", map[string]string{"x": "1", "y": "2", "zoom": "3"}, "map://1,2;zoom=3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			template, err := ParseURITemplate(tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := template.Expand(tt.vars); got != tt.uri {
+				t.Errorf("Expand = %q, want %q", got, tt.uri)
+			}
+			vars, ok := template.Match(tt.uri)
+			if !ok || !reflect.DeepEqual(vars, tt.vars) {
+				t.Errorf("Match = %v, %v, want %v", vars, ok, tt.vars)
+			}
+		})
+	}
+}
+
+func TestURITemplate_NoMatch(t *testing.T) {
+	template, _ := ParseURITemplate("db://{schema}/{table}")
+	for _, uri := range []string{"db://public", "db://public/users/extra", "db:///users", "other://public/users"} {
+		if vars, ok := template.Match(uri); ok {
+			t.Errorf("expected %q not to match, got %v", uri, vars)
+		}
+	}
+}
+
+func TestParseURITemplate_Invalid(t *testing.T) {
+	for _, raw := range []string{"db://{schema", "db://{}", "db://{a b}", "db://{table*}"} {
+		if _, err := ParseURITemplate(raw); err == nil {
+			t.Errorf("expected %q to be rejected", raw)
+		}
+	}
+}
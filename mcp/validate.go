@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/mhpenta/minimcp/infer"
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// MethodValidate is a vendor extension that checks arguments against a tool's input
+// schema without executing the tool, so clients can pre-check calls cheaply
+const MethodValidate = "x-minimcp/validate"
+
+// ValidateParams represents parameters for MethodValidate
+type ValidateParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// ValidateResult reports whether arguments would be accepted by a tool
+type ValidateResult struct {
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// findTool returns the registered tool with the given name, or nil
+func (s *Server) findTool(name string) tools.Tool {
+	for _, tool := range s.GetTools() {
+		if tool.Spec().Name == name {
+			return tool
+		}
+	}
+	return nil
+}
+
+// validateArguments checks args against the tool's input schema. Missing arguments are
+// validated as an empty object, matching how tools/call treats them.
+func validateArguments(spec *tools.ToolSpec, args json.RawMessage) ValidateResult {
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+	if spec.Parameters == nil {
+		return ValidateResult{Valid: true}
+	}
+	if err := infer.Validate(spec.Parameters, args); err != nil {
+		return ValidateResult{Valid: false, Errors: []string{err.Error()}}
+	}
+	return ValidateResult{Valid: true}
+}
+
+// handleValidate processes the MethodValidate request
+func (h *JSONRPCHandler) handleValidate(ctx context.Context, params json.RawMessage) (interface{}, *RPCError) {
+	var validateParams ValidateParams
+	if err := json.Unmarshal(params, &validateParams); err != nil {
+		return nil, &RPCError{
+			Code:    InvalidParams,
+			Message: "Invalid validate parameters",
+			Data:    err.Error(),
+		}
+	}
+
+	tool, rpcErr := h.server.lookupTool(ctx, validateParams.Name)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	return validateArguments(tool.Spec(), validateParams.Arguments), nil
+}
+
+// handleValidateTool handles the REST validation route. The body matches /mcp/tools/call.
+func (t *HTTPTransport) handleValidateTool(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req CallToolRequest
+	t.limitBody(w, r)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), bodyErrorStatus(err))
+		return
+	}
+
+	tool, rpcErr := t.server.lookupTool(r.Context(), req.Name)
+	if rpcErr != nil {
+		http.Error(w, rpcErr.Message, restErrorStatus(t.server, req.Name, rpcErr))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(validateArguments(tool.Spec(), req.Params))
+}
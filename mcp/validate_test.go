@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+type validateInput struct {
+	City  string `json:"city"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+func TestValidate(t *testing.T) {
+	var executions int32
+	weather := tools.NewTool("weather", "Gets weather", func(ctx context.Context, in validateInput) (string, error) {
+		atomic.AddInt32(&executions, 1)
+		return "sunny", nil
+	})
+	server := NewServer(ServerConfig{Name: "test-server", Tools: []tools.Tool{weather}})
+	handler := NewJSONRPCHandler(server)
+
+	validate := func(args string) *JSONRPCResponse {
+		resp, err := handler.HandleMessage(context.Background(), []byte(
+			`{"jsonrpc":"2.0","id":1,"method":"x-minimcp/validate","params":{"name":"weather","arguments":`+args+`}}`))
+		if err != nil {
+			t.Fatalf("HandleMessage failed: %v", err)
+		}
+		return resp
+	}
+
+	if result := validate(`{"city":"Paris"}`).Result.(ValidateResult); !result.Valid {
+		t.Errorf("expected valid arguments, got %+v", result)
+	}
+	for _, args := range []string{`{}`, `{"city":7}`, `{"city":"Paris","extra":true}`} {
+		if result := validate(args).Result.(ValidateResult); result.Valid || len(result.Errors) == 0 {
+			t.Errorf("expected %s to be rejected, got %+v", args, result)
+		}
+	}
+	if n := atomic.LoadInt32(&executions); n != 0 {
+		t.Errorf("expected validation not to execute the tool, got %d executions", n)
+	}
+
+	resp, _ := handler.HandleMessage(context.Background(), []byte(
+		`{"jsonrpc":"2.0","id":2,"method":"x-minimcp/validate","params":{"name":"missing"}}`))
+	if resp.Error == nil || resp.Error.Code != InvalidParams {
+		t.Errorf("expected InvalidParams for an unknown tool, got %+v", resp)
+	}
+
+	// REST route
+	transport := NewHTTPTransport(server, slog.Default(), newMockValidator("test-key"))
+	req := httptest.NewRequest(http.MethodPost, "/mcp/tools/validate", strings.NewReader(`{"name":"weather","arguments":{"city":1}}`))
+	req.Header.Set("Authorization", "Bearer test-key")
+	w := httptest.NewRecorder()
+	transport.ServeHTTP(w, req)
+
+	var result ValidateResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil || w.Code != http.StatusOK || result.Valid {
+		t.Errorf("expected an invalid result over REST, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,277 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConstantTimeStaticValidator accepts a fixed set of API keys, comparing in constant time.
+// Every configured key is checked on each call so timing does not reveal which key matched.
+type ConstantTimeStaticValidator struct {
+	digests [][sha256.Size]byte
+}
+
+// NewConstantTimeStaticValidator creates a validator accepting the given keys. Empty keys are ignored.
+func NewConstantTimeStaticValidator(keys ...string) *ConstantTimeStaticValidator {
+	v := &ConstantTimeStaticValidator{}
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key != "" {
+			v.digests = append(v.digests, sha256.Sum256([]byte(key)))
+		}
+	}
+	return v
+}
+
+// NewStaticValidatorFromEnv creates a validator from a comma-separated list of keys in the
+// named environment variable. It fails if the variable is unset or holds no keys.
+func NewStaticValidatorFromEnv(name string) (*ConstantTimeStaticValidator, error) {
+	v := NewConstantTimeStaticValidator(strings.Split(os.Getenv(name), ",")...)
+	if len(v.digests) == 0 {
+		return nil, fmt.Errorf("environment variable %s contains no API keys", name)
+	}
+	return v, nil
+}
+
+// Validate checks the key against every configured key in constant time
+func (v *ConstantTimeStaticValidator) Validate(ctx context.Context, apiKey string) bool {
+	if apiKey == "" {
+		return false
+	}
+	// Comparing fixed-size digests keeps the comparison independent of key length
+	digest := sha256.Sum256([]byte(apiKey))
+	match := 0
+	for i := range v.digests {
+		match |= subtle.ConstantTimeCompare(digest[:], v.digests[i][:])
+	}
+	return match == 1
+}
+
+// ReloadableValidator delegates to a validator that can be replaced while the server runs,
+// so keys can be rotated without restarting the HTTP transport
+type ReloadableValidator struct {
+	mu    sync.RWMutex
+	inner APIKeyValidator
+}
+
+// NewReloadableValidator creates a validator delegating to inner
+func NewReloadableValidator(inner APIKeyValidator) *ReloadableValidator {
+	return &ReloadableValidator{inner: inner}
+}
+
+// Set replaces the validator checked by later requests
+func (v *ReloadableValidator) Set(inner APIKeyValidator) {
+	v.mu.Lock()
+	v.inner = inner
+	v.mu.Unlock()
+}
+
+// Validate checks the key with the current validator
+func (v *ReloadableValidator) Validate(ctx context.Context, apiKey string) bool {
+	v.mu.RLock()
+	inner := v.inner
+	v.mu.RUnlock()
+	return inner.Validate(ctx, apiKey)
+}
+
+// Authenticate identifies the caller with the current validator. Validators that do not
+// implement Authenticator yield no principal.
+func (v *ReloadableValidator) Authenticate(ctx context.Context, apiKey string) (*Principal, error) {
+	v.mu.RLock()
+	inner := v.inner
+	v.mu.RUnlock()
+	return authenticateWith(ctx, inner, apiKey)
+}
+
+// authenticateWith identifies the caller with inner, falling back to Validate for
+// validators that do not implement Authenticator
+func authenticateWith(ctx context.Context, inner APIKeyValidator, apiKey string) (*Principal, error) {
+	if authenticator, ok := inner.(Authenticator); ok {
+		return authenticator.Authenticate(ctx, apiKey)
+	}
+	if !inner.Validate(ctx, apiKey) {
+		return nil, ErrUnauthenticated
+	}
+	return nil, nil
+}
+
+// KeyHashCompareFunc reports whether key matches a stored hash. Use it to plug in
+// bcrypt or another password hash, e.g.:
+//
+//	func(hash, key string) bool {
+//	    return bcrypt.CompareHashAndPassword([]byte(hash), []byte(key)) == nil
+//	}
+type KeyHashCompareFunc func(hash, key string) bool
+
+// HashAPIKey returns the hex-encoded SHA-256 hash of key, the format expected by
+// HashedKeyValidator's default comparison
+func HashAPIKey(key string) string {
+	digest := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(digest[:])
+}
+
+// CompareSHA256KeyHash compares key against a hex-encoded SHA-256 hash in constant time
+func CompareSHA256KeyHash(hash, key string) bool {
+	stored, err := hex.DecodeString(hash)
+	if err != nil || len(stored) != sha256.Size {
+		return false
+	}
+	digest := sha256.Sum256([]byte(key))
+	return subtle.ConstantTimeCompare(stored, digest[:]) == 1
+}
+
+// HashedKeyValidator accepts API keys whose hashes are stored, so plaintext keys never
+// need to live in configuration
+type HashedKeyValidator struct {
+	hashes  []string
+	compare KeyHashCompareFunc
+}
+
+// NewHashedKeyValidator creates a validator for the given stored hashes. A nil compare
+// uses CompareSHA256KeyHash, which expects hashes produced by HashAPIKey.
+func NewHashedKeyValidator(hashes []string, compare KeyHashCompareFunc) (*HashedKeyValidator, error) {
+	if compare == nil {
+		compare = CompareSHA256KeyHash
+		for _, hash := range hashes {
+			if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+				return nil, fmt.Errorf("invalid SHA-256 key hash %q", hash)
+			}
+		}
+	}
+	if len(hashes) == 0 {
+		return nil, errors.New("no key hashes configured")
+	}
+	return &HashedKeyValidator{hashes: hashes, compare: compare}, nil
+}
+
+// Validate checks the key against every stored hash
+func (v *HashedKeyValidator) Validate(ctx context.Context, apiKey string) bool {
+	if apiKey == "" {
+		return false
+	}
+	valid := false
+	for _, hash := range v.hashes {
+		if v.compare(hash, apiKey) {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// CachingValidatorConfig configures a CachingValidator
+type CachingValidatorConfig struct {
+	// TTL is how long an accepted key is cached (default 5m)
+	TTL time.Duration
+
+	// NegativeTTL is how long a rejected key is cached (default 30s). Negative caching
+	// shields the backend from repeated invalid keys. Only rejections wrapping
+	// ErrUnauthenticated are cached; other errors, such as a backend outage, are retried.
+	NegativeTTL time.Duration
+
+	// MaxEntries bounds the cache size (default 10000)
+	MaxEntries int
+}
+
+// CachingValidator wraps a remote or otherwise expensive validator, caching results by
+// key hash so plaintext keys are not held in memory
+type CachingValidator struct {
+	inner APIKeyValidator
+	cfg   CachingValidatorConfig
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]cachedValidation
+	now     func() time.Time
+}
+
+type cachedValidation struct {
+	principal *Principal
+	err       error
+	expires   time.Time
+}
+
+// NewCachingValidator wraps inner with a result cache
+func NewCachingValidator(inner APIKeyValidator, cfg CachingValidatorConfig) *CachingValidator {
+	if cfg.TTL <= 0 {
+		cfg.TTL = 5 * time.Minute
+	}
+	if cfg.NegativeTTL <= 0 {
+		cfg.NegativeTTL = 30 * time.Second
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 10000
+	}
+	return &CachingValidator{
+		inner:   inner,
+		cfg:     cfg,
+		entries: make(map[[sha256.Size]byte]cachedValidation),
+		now:     time.Now,
+	}
+}
+
+// Validate returns the cached result for the key, consulting the wrapped validator on a miss
+func (v *CachingValidator) Validate(ctx context.Context, apiKey string) bool {
+	_, err := v.Authenticate(ctx, apiKey)
+	return err == nil
+}
+
+// Authenticate returns the cached principal for the key, consulting the wrapped validator
+// on a miss. Validators that do not implement Authenticator yield no principal. A principal
+// with an "exp" claim, such as a JWT's, is not cached past its expiry.
+func (v *CachingValidator) Authenticate(ctx context.Context, apiKey string) (*Principal, error) {
+	if apiKey == "" {
+		return nil, ErrUnauthenticated
+	}
+	digest := sha256.Sum256([]byte(apiKey))
+
+	v.mu.Lock()
+	entry, ok := v.entries[digest]
+	v.mu.Unlock()
+	if ok && v.now().Before(entry.expires) {
+		return entry.principal, entry.err
+	}
+
+	principal, err := authenticateWith(ctx, v.inner, apiKey)
+	// A cancelled request or a failing backend says nothing about the key, so don't cache
+	// the result
+	if ctx.Err() != nil || (err != nil && !errors.Is(err, ErrUnauthenticated)) {
+		return principal, err
+	}
+
+	expires := v.now().Add(v.cfg.NegativeTTL)
+	if err == nil {
+		expires = v.now().Add(v.cfg.TTL)
+		if principal != nil {
+			if exp, ok := principal.Claims["exp"].(float64); ok && time.Unix(int64(exp), 0).Before(expires) {
+				expires = time.Unix(int64(exp), 0)
+			}
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if len(v.entries) >= v.cfg.MaxEntries {
+		v.evict()
+	}
+	v.entries[digest] = cachedValidation{principal: principal, err: err, expires: expires}
+	return principal, err
+}
+
+// evict drops expired entries, or every entry if none have expired. Called with mu held.
+func (v *CachingValidator) evict() {
+	now := v.now()
+	for digest, entry := range v.entries {
+		if !now.Before(entry.expires) {
+			delete(v.entries, digest)
+		}
+	}
+	if len(v.entries) >= v.cfg.MaxEntries {
+		v.entries = make(map[[sha256.Size]byte]cachedValidation)
+	}
+}
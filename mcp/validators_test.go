@@ -0,0 +1,200 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConstantTimeStaticValidator(t *testing.T) {
+	v := NewConstantTimeStaticValidator("alpha", " beta ", "")
+
+	for key, want := range map[string]bool{"alpha": true, "beta": true, "gamma": false, "": false, "alph": false} {
+		if got := v.Validate(context.Background(), key); got != want {
+			t.Errorf("Validate(%q) = %v, want %v", key, got, want)
+		}
+	}
+
+	t.Setenv("MINIMCP_TEST_KEYS", "one,two")
+	envValidator, err := NewStaticValidatorFromEnv("MINIMCP_TEST_KEYS")
+	if err != nil {
+		t.Fatalf("NewStaticValidatorFromEnv failed: %v", err)
+	}
+	if !envValidator.Validate(context.Background(), "two") {
+		t.Error("expected key from environment to validate")
+	}
+
+	t.Setenv("MINIMCP_TEST_KEYS", "")
+	if _, err := NewStaticValidatorFromEnv("MINIMCP_TEST_KEYS"); err == nil {
+		t.Error("expected error for empty environment variable")
+	}
+}
+
+func TestHashedKeyValidator(t *testing.T) {
+	v, err := NewHashedKeyValidator([]string{HashAPIKey("secret")}, nil)
+	if err != nil {
+		t.Fatalf("NewHashedKeyValidator failed: %v", err)
+	}
+	if !v.Validate(context.Background(), "secret") || v.Validate(context.Background(), "Secret") {
+		t.Error("SHA-256 hashed key validation mismatch")
+	}
+
+	if _, err := NewHashedKeyValidator([]string{"not-hex"}, nil); err == nil {
+		t.Error("expected error for malformed SHA-256 hash")
+	}
+
+	// Custom comparisons such as bcrypt receive the stored hash verbatim
+	custom, err := NewHashedKeyValidator([]string{"$custom$secret"}, func(hash, key string) bool {
+		return strings.TrimPrefix(hash, "$custom$") == key
+	})
+	if err != nil {
+		t.Fatalf("NewHashedKeyValidator failed: %v", err)
+	}
+	if !custom.Validate(context.Background(), "secret") {
+		t.Error("expected custom comparison to accept key")
+	}
+}
+
+type countingValidator struct {
+	calls int32
+	valid string
+}
+
+func (c *countingValidator) Validate(ctx context.Context, apiKey string) bool {
+	atomic.AddInt32(&c.calls, 1)
+	return apiKey == c.valid
+}
+
+func TestCachingValidator(t *testing.T) {
+	inner := &countingValidator{valid: "good"}
+	v := NewCachingValidator(inner, CachingValidatorConfig{TTL: time.Minute, NegativeTTL: time.Second})
+	now := time.Now()
+	v.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if !v.Validate(context.Background(), "good") {
+			t.Fatal("expected good key to validate")
+		}
+		if v.Validate(context.Background(), "bad") {
+			t.Fatal("expected bad key to be rejected")
+		}
+	}
+	if inner.calls != 2 {
+		t.Errorf("expected 2 backend calls, got %d", inner.calls)
+	}
+
+	// The negative entry expires first
+	now = now.Add(2 * time.Second)
+	v.Validate(context.Background(), "good")
+	v.Validate(context.Background(), "bad")
+	if inner.calls != 3 {
+		t.Errorf("expected only the rejected key to be revalidated, got %d calls", inner.calls)
+	}
+}
+
+// scopedAuthenticator grants the "admin" scope to the key "root"
+type scopedAuthenticator struct{ calls int }
+
+func (a *scopedAuthenticator) Validate(ctx context.Context, key string) bool {
+	_, err := a.Authenticate(ctx, key)
+	return err == nil
+}
+
+func (a *scopedAuthenticator) Authenticate(ctx context.Context, key string) (*Principal, error) {
+	a.calls++
+	if key != "root" {
+		return nil, ErrUnauthenticated
+	}
+	return &Principal{Subject: "root", Scopes: []string{"admin"}}, nil
+}
+
+func TestWrappedValidators_ForwardPrincipal(t *testing.T) {
+	inner := &scopedAuthenticator{}
+	caching := NewCachingValidator(inner, CachingValidatorConfig{TTL: time.Minute})
+	reloadable := NewReloadableValidator(caching)
+
+	for i := 0; i < 2; i++ {
+		principal, err := reloadable.Authenticate(context.Background(), "root")
+		if err != nil || principal == nil || !principal.HasScopes("admin") {
+			t.Fatalf("expected the inner principal, got %+v, %v", principal, err)
+		}
+	}
+	if inner.calls != 1 {
+		t.Errorf("expected the principal to be cached, got %d backend calls", inner.calls)
+	}
+	if _, err := reloadable.Authenticate(context.Background(), "guest"); err == nil {
+		t.Error("expected an unknown key to be rejected")
+	}
+
+	// Validators without identities still authenticate, without a principal
+	reloadable.Set(NewConstantTimeStaticValidator("static"))
+	if principal, err := reloadable.Authenticate(context.Background(), "static"); err != nil || principal != nil {
+		t.Errorf("expected a nil principal for a static key, got %+v, %v", principal, err)
+	}
+}
+
+func TestCachingValidator_HonorsPrincipalExpiry(t *testing.T) {
+	now := time.Now()
+	calls := 0
+	inner := authenticatorFunc(func(ctx context.Context, key string) (*Principal, error) {
+		calls++
+		return &Principal{Subject: "u", Claims: map[string]interface{}{"exp": float64(now.Add(10 * time.Second).Unix())}}, nil
+	})
+	v := NewCachingValidator(inner, CachingValidatorConfig{TTL: time.Hour})
+	v.now = func() time.Time { return now }
+
+	v.Authenticate(context.Background(), "token")
+	now = now.Add(time.Minute)
+	v.Authenticate(context.Background(), "token")
+	if calls != 2 {
+		t.Errorf("expected the entry to expire with the token, got %d backend calls", calls)
+	}
+}
+
+func TestCachingValidator_RetriesBackendErrors(t *testing.T) {
+	calls := 0
+	outage := errors.New("backend unavailable")
+	inner := authenticatorFunc(func(ctx context.Context, key string) (*Principal, error) {
+		calls++
+		if key == "bad" {
+			return nil, fmt.Errorf("%w: unknown key", ErrUnauthenticated)
+		}
+		return nil, outage
+	})
+	v := NewCachingValidator(inner, CachingValidatorConfig{TTL: time.Minute, NegativeTTL: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		if _, err := v.Authenticate(context.Background(), "good"); !errors.Is(err, outage) {
+			t.Fatalf("expected the backend error, got %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected a backend error not to be cached, got %d backend calls", calls)
+	}
+
+	calls = 0
+	for i := 0; i < 2; i++ {
+		if _, err := v.Authenticate(context.Background(), "bad"); !errors.Is(err, ErrUnauthenticated) {
+			t.Fatalf("expected ErrUnauthenticated, got %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected a rejected key to be cached, got %d backend calls", calls)
+	}
+}
+
+// authenticatorFunc adapts a function to APIKeyValidator and Authenticator
+type authenticatorFunc func(ctx context.Context, key string) (*Principal, error)
+
+func (f authenticatorFunc) Validate(ctx context.Context, key string) bool {
+	_, err := f(ctx, key)
+	return err == nil
+}
+
+func (f authenticatorFunc) Authenticate(ctx context.Context, key string) (*Principal, error) {
+	return f(ctx, key)
+}
@@ -0,0 +1,245 @@
+// Package mcptest drives a minimcp server in tests through a synchronous client, so tests
+// make calls and assert on responses instead of writing to buffers, sleeping, and parsing
+// whatever was printed.
+//
+// Example:
+//
+//	func TestWeather(t *testing.T) {
+//	    server := mcp.NewServer(mcp.ServerConfig{Name: "weather", Version: "1.0.0", Tools: []tools.Tool{weatherTool}})
+//	    c := mcptest.NewClient(t, server)
+//
+//	    c.CallTool("get_weather", map[string]any{"city": "Paris"}).ExpectToolText("Sunny in Paris")
+//	    c.CallTool("get_weather", map[string]any{}).ExpectToolError()
+//	    c.Call("unknown/method", nil).ExpectRPCError(mcp.MethodNotFound)
+//	    c.Call(mcp.MethodToolsList, nil).ExpectGolden("testdata/tools_list.golden.json")
+//	}
+package mcptest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/client"
+	"github.com/mhpenta/minimcp/mcp"
+)
+
+// DefaultTimeout bounds each call made through a Client
+const DefaultTimeout = 10 * time.Second
+
+// Option configures a Client
+type Option func(*config)
+
+type config struct {
+	info       mcp.ClientInfo
+	timeout    time.Duration
+	initialize bool
+	logger     *slog.Logger
+}
+
+// WithClientInfo sets the name and version the client reports in the handshake
+func WithClientInfo(info mcp.ClientInfo) Option {
+	return func(c *config) {
+		c.info = info
+	}
+}
+
+// WithTimeout bounds each call (default DefaultTimeout). A call that times out fails the
+// test.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.timeout = timeout
+	}
+}
+
+// WithoutInitialize skips the handshake, for tests of initialize itself
+func WithoutInitialize() Option {
+	return func(c *config) {
+		c.initialize = false
+	}
+}
+
+// WithLogger sets the transport's logger. By default transport logs are discarded.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+// Client is a fake MCP client connected to a server under test. Its methods block until
+// the server answers and fail the test on transport errors or timeouts.
+type Client struct {
+	t       testing.TB
+	client  *client.Client
+	timeout time.Duration
+
+	notifications chan mcp.JSONRPCNotification
+}
+
+// notificationBuffer is how many unread notifications a Client keeps; older ones are
+// dropped once it is full
+const notificationBuffer = 64
+
+// NewClient connects to server over the in-memory transport and, unless
+// WithoutInitialize is given, completes the handshake. The connection is closed when the
+// test ends.
+func NewClient(t testing.TB, server *mcp.Server, opts ...Option) *Client {
+	t.Helper()
+	cfg := newConfig(opts)
+
+	transport, conn := mcp.NewInMemoryTransport(server, cfg.logger)
+	done := make(chan error, 1)
+	go func() { done <- transport.Start(context.Background()) }()
+
+	return connect(t, conn, cfg, done)
+}
+
+// NewStdioClient connects to server through its stdio transport over pipes, exercising
+// the newline-delimited framing a subprocess would see. The transport stops when the test
+// ends.
+func NewStdioClient(t testing.TB, server *mcp.Server, opts ...Option) *Client {
+	t.Helper()
+	cfg := newConfig(opts)
+
+	serverReader, clientWriter := io.Pipe()
+	clientReader, serverWriter := io.Pipe()
+	transport := mcp.NewStdioTransportWithIO(server, cfg.logger, serverReader, serverWriter)
+	done := make(chan error, 1)
+	go func() {
+		done <- transport.Start(context.Background())
+		serverWriter.Close()
+	}()
+
+	return connect(t, client.NewStdioConn(clientReader, clientWriter), cfg, done)
+}
+
+func newConfig(opts []Option) config {
+	cfg := config{
+		info:       mcp.ClientInfo{Name: "mcptest", Version: "1.0.0"},
+		timeout:    DefaultTimeout,
+		initialize: true,
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// connect wraps conn in a Client, stopping the transport, whose Start result arrives on
+// done, when the test ends
+func connect(t testing.TB, conn client.Conn, cfg config, done <-chan error) *Client {
+	t.Helper()
+	c := &Client{
+		t:             t,
+		client:        client.New(conn),
+		timeout:       cfg.timeout,
+		notifications: make(chan mcp.JSONRPCNotification, notificationBuffer),
+	}
+	c.client.OnNotification(func(n mcp.JSONRPCNotification) {
+		select {
+		case c.notifications <- n:
+		default:
+		}
+	})
+
+	t.Cleanup(func() {
+		c.client.Close()
+		select {
+		case err := <-done:
+			if err != nil && !errors.Is(err, context.Canceled) {
+				t.Errorf("mcptest: transport returned error: %v", err)
+			}
+		case <-time.After(cfg.timeout):
+			t.Errorf("mcptest: transport did not stop within %v", cfg.timeout)
+		}
+	})
+
+	if cfg.initialize {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.timeout)
+		defer cancel()
+		if _, err := c.client.Initialize(ctx, cfg.info); err != nil {
+			t.Fatalf("mcptest: initialize failed: %v", err)
+		}
+	}
+	return c
+}
+
+// Call sends a request and returns the server's response. A JSON-RPC error is part of
+// the response, not a test failure; assert on it with the Response's Expect methods.
+func (c *Client) Call(method string, params interface{}) *Response {
+	c.t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	resp := &Response{t: c.t, Method: method}
+	err := c.client.Call(ctx, method, params, &resp.Result)
+	var rpcErr *mcp.RPCError
+	switch {
+	case errors.As(err, &rpcErr):
+		resp.Error = rpcErr
+	case err != nil:
+		c.t.Fatalf("mcptest: %s failed: %v", method, err)
+	}
+	return resp
+}
+
+// Notify sends a notification
+func (c *Client) Notify(method string, params interface{}) {
+	c.t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	if err := c.client.Notify(ctx, method, params); err != nil {
+		c.t.Fatalf("mcptest: %s notification failed: %v", method, err)
+	}
+}
+
+// CallTool calls a tool with arguments, which are encoded as JSON
+func (c *Client) CallTool(name string, arguments interface{}) *Response {
+	c.t.Helper()
+	var raw json.RawMessage
+	if arguments != nil {
+		data, err := json.Marshal(arguments)
+		if err != nil {
+			c.t.Fatalf("mcptest: encoding arguments of %s: %v", name, err)
+		}
+		raw = data
+	}
+	return c.Call(mcp.MethodToolsCall, mcp.ToolsCallParams{Name: name, Arguments: raw})
+}
+
+// ListTools returns the server's tools, failing the test on error
+func (c *Client) ListTools() []mcp.ToolDescription {
+	c.t.Helper()
+	var result mcp.ToolsListResult
+	c.Call(mcp.MethodToolsList, nil).ExpectResult(&result)
+	return result.Tools
+}
+
+// ExpectNotification waits for the next notification with method, skipping others, and
+// returns it. The test fails if none arrives within the client's timeout.
+func (c *Client) ExpectNotification(method string) mcp.JSONRPCNotification {
+	c.t.Helper()
+	timeout := time.After(c.timeout)
+	for {
+		select {
+		case n := <-c.notifications:
+			if n.Method == method {
+				return n
+			}
+		case <-timeout:
+			c.t.Fatalf("mcptest: no %s notification within %v", method, c.timeout)
+			return mcp.JSONRPCNotification{}
+		}
+	}
+}
+
+// Underlying returns the client driving the connection, for calls this package does not
+// wrap
+func (c *Client) Underlying() *client.Client {
+	return c.client
+}
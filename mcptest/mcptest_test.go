@@ -0,0 +1,150 @@
+package mcptest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/tools"
+)
+
+type greetInput struct {
+	Name string `json:"name"`
+}
+
+func newGreeter() *mcp.Server {
+	greet := tools.NewTool("greet", "Greets someone", func(ctx context.Context, in greetInput) (string, error) {
+		if in.Name == "" {
+			return "", errors.New("name is required")
+		}
+		return "Hello, " + in.Name, nil
+	})
+	return mcp.NewServer(mcp.ServerConfig{Name: "greeter", Version: "1.0.0", Tools: []tools.Tool{greet}})
+}
+
+func TestClient(t *testing.T) {
+	connections := map[string]func(testing.TB, *mcp.Server, ...Option) *Client{
+		"memory": NewClient,
+		"stdio":  NewStdioClient,
+	}
+	for name, connect := range connections {
+		t.Run(name, func(t *testing.T) {
+			c := connect(t, newGreeter())
+
+			if list := c.ListTools(); len(list) != 1 || list[0].Name != "greet" {
+				t.Errorf("expected the greet tool, got %+v", list)
+			}
+			c.CallTool("greet", greetInput{Name: "Ada"}).ExpectToolText("Hello, Ada")
+			if result := c.CallTool("greet", greetInput{}).ExpectToolError(); !strings.Contains(textOf(result), "name is required") {
+				t.Errorf("expected the handler's error, got %q", textOf(result))
+			}
+			c.CallTool("missing", nil).ExpectRPCError(mcp.InvalidParams)
+			c.Call("unknown/method", nil).ExpectRPCError(mcp.MethodNotFound)
+			c.Call(mcp.MethodToolsList, nil).ExpectGolden("testdata/tools_list.golden.json")
+			c.Notify("notifications/initialized", nil)
+			c.Call(mcp.MethodPing, nil).ExpectResult(nil)
+		})
+	}
+}
+
+func TestClient_WithoutInitialize(t *testing.T) {
+	c := NewClient(t, newGreeter(), WithoutInitialize())
+
+	var result mcp.InitializeResult
+	c.Call(mcp.MethodInitialize, mcp.InitializeParams{
+		ProtocolVersion: mcp.LatestProtocolVersion,
+		ClientInfo:      mcp.ClientInfo{Name: "custom", Version: "2.0"},
+	}).ExpectResult(&result)
+	if result.ServerInfo.Name != "greeter" {
+		t.Errorf("unexpected initialize result: %+v", result)
+	}
+}
+
+func TestClient_ExpectNotification(t *testing.T) {
+	server := newGreeter()
+	c := NewClient(t, server)
+
+	server.AddTools(tools.NewTool("wave", "Waves", func(ctx context.Context, in struct{}) (string, error) {
+		return "o/", nil
+	}))
+	c.ExpectNotification(mcp.MethodNotificationToolsListChanged)
+	if list := c.ListTools(); len(list) != 2 {
+		t.Errorf("expected the added tool to be listed, got %d tools", len(list))
+	}
+}
+
+// recordingTB captures failures instead of failing the test
+type recordingTB struct {
+	testing.TB
+
+	mu       sync.Mutex
+	failures []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Fatalf(format string, args ...interface{}) {
+	r.mu.Lock()
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+	r.mu.Unlock()
+	panic(r)
+}
+
+// fails reports whether fn fails the test, and with what message
+func fails(t *testing.T, fn func(tb testing.TB)) (string, bool) {
+	rec := &recordingTB{TB: t}
+	func() {
+		defer func() {
+			if p := recover(); p != nil && p != rec {
+				panic(p)
+			}
+		}()
+		fn(rec)
+	}()
+	if len(rec.failures) == 0 {
+		return "", false
+	}
+	return rec.failures[0], true
+}
+
+func TestResponse_Expectations(t *testing.T) {
+	c := NewClient(t, newGreeter())
+	ok := c.CallTool("greet", greetInput{Name: "Ada"})
+	toolErr := c.CallTool("greet", greetInput{})
+	rpcErr := c.Call("unknown/method", nil)
+
+	cases := []struct {
+		name   string
+		expect func(tb testing.TB)
+		fails  string
+	}{
+		{"success as rpc error", func(tb testing.TB) { withTB(ok, tb).ExpectRPCError(mcp.MethodNotFound) }, "expected tools/call to fail"},
+		{"wrong rpc code", func(tb testing.TB) { withTB(rpcErr, tb).ExpectRPCError(mcp.InvalidParams) }, "got -32601"},
+		{"tool error as success", func(tb testing.TB) { withTB(toolErr, tb).ExpectToolResult() }, "expected the tool to succeed"},
+		{"success as tool error", func(tb testing.TB) { withTB(ok, tb).ExpectToolError() }, "expected the tool to fail"},
+		{"wrong text", func(tb testing.TB) { withTB(ok, tb).ExpectToolText("Hi") }, `got "Hello, Ada"`},
+		{"rpc error as result", func(tb testing.TB) { withTB(rpcErr, tb).ExpectResult(nil) }, "failed with code -32601"},
+		{"golden mismatch", func(tb testing.TB) { withTB(ok, tb).ExpectGolden("testdata/tools_list.golden.json") }, "does not match"},
+	}
+	for _, tc := range cases {
+		message, failed := fails(t, tc.expect)
+		if !failed || !strings.Contains(message, tc.fails) {
+			t.Errorf("%s: expected a failure containing %q, got %q", tc.name, tc.fails, message)
+		}
+	}
+
+	if message, failed := fails(t, func(tb testing.TB) { withTB(ok, tb).ExpectToolText("Hello, Ada") }); failed {
+		t.Errorf("expected a matching expectation to pass, got %q", message)
+	}
+}
+
+// withTB returns a copy of resp reporting to tb
+func withTB(resp *Response, tb testing.TB) *Response {
+	copied := *resp
+	copied.t = tb
+	return &copied
+}
@@ -0,0 +1,158 @@
+package mcptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mhpenta/minimcp/mcp"
+)
+
+// UpdateGoldenEnv is the environment variable that makes ExpectGolden rewrite golden
+// files instead of comparing against them:
+//
+//	MCPTEST_UPDATE=1 go test ./...
+const UpdateGoldenEnv = "MCPTEST_UPDATE"
+
+// Response is the server's answer to one request: a result or a JSON-RPC error
+type Response struct {
+	t testing.TB
+
+	Method string
+	Result json.RawMessage
+	Error  *mcp.RPCError
+}
+
+// ExpectRPCError fails the test unless the response is a JSON-RPC error with code, and
+// returns the error
+func (r *Response) ExpectRPCError(code int) *mcp.RPCError {
+	r.t.Helper()
+	if r.Error == nil {
+		r.t.Fatalf("mcptest: expected %s to fail with code %d, got result %s", r.Method, code, r.Result)
+	}
+	if r.Error.Code != code {
+		r.t.Fatalf("mcptest: expected %s to fail with code %d, got %d: %s", r.Method, code, r.Error.Code, r.Error.Message)
+	}
+	return r.Error
+}
+
+// ExpectResult fails the test if the response is an error, then decodes the result
+// into v, which may be nil
+func (r *Response) ExpectResult(v interface{}) {
+	r.t.Helper()
+	if r.Error != nil {
+		r.t.Fatalf("mcptest: %s failed with code %d: %s", r.Method, r.Error.Code, r.Error.Message)
+	}
+	if v == nil {
+		return
+	}
+	if err := json.Unmarshal(r.Result, v); err != nil {
+		r.t.Fatalf("mcptest: decoding %s result %s: %v", r.Method, r.Result, err)
+	}
+}
+
+// ExpectToolResult fails the test unless the response is a successful tool result, and
+// returns it
+func (r *Response) ExpectToolResult() *mcp.ToolsCallResult {
+	r.t.Helper()
+	var result mcp.ToolsCallResult
+	r.ExpectResult(&result)
+	if result.IsError {
+		r.t.Fatalf("mcptest: expected the tool to succeed, got error result %q", textOf(&result))
+	}
+	return &result
+}
+
+// ExpectToolText fails the test unless the response is a successful tool result whose
+// text content is want
+func (r *Response) ExpectToolText(want string) *mcp.ToolsCallResult {
+	r.t.Helper()
+	result := r.ExpectToolResult()
+	if got := textOf(result); got != want {
+		r.t.Fatalf("mcptest: expected tool text %q, got %q", want, got)
+	}
+	return result
+}
+
+// ExpectToolError fails the test unless the response is a tool result with isError set,
+// and returns it. Protocol errors, such as an unknown tool, are checked with
+// ExpectRPCError instead.
+func (r *Response) ExpectToolError() *mcp.ToolsCallResult {
+	r.t.Helper()
+	var result mcp.ToolsCallResult
+	r.ExpectResult(&result)
+	if !result.IsError {
+		r.t.Fatalf("mcptest: expected the tool to fail, got %q", textOf(&result))
+	}
+	return &result
+}
+
+// ExpectGolden compares the response, result or error, with the JSON in the golden file
+// at path. Both are compared after indenting with sorted keys, so the file can be
+// formatted freely. With MCPTEST_UPDATE=1 the file is written instead.
+func (r *Response) ExpectGolden(path string) {
+	r.t.Helper()
+	var body interface{} = r.Result
+	if r.Error != nil {
+		body = map[string]interface{}{"error": r.Error}
+	}
+	got, err := canonicalJSON(body)
+	if err != nil {
+		r.t.Fatalf("mcptest: formatting %s response: %v", r.Method, err)
+	}
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			r.t.Fatalf("mcptest: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			r.t.Fatalf("mcptest: %v", err)
+		}
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		r.t.Fatalf("mcptest: reading golden file (run with %s=1 to create it): %v", UpdateGoldenEnv, err)
+	}
+	var golden interface{}
+	if err := json.Unmarshal(data, &golden); err != nil {
+		r.t.Fatalf("mcptest: golden file %s is not valid JSON: %v", path, err)
+	}
+	want, _ := canonicalJSON(golden)
+	if !bytes.Equal(got, want) {
+		r.t.Fatalf("mcptest: %s response does not match %s (run with %s=1 to update)\ngot:\n%s\nwant:\n%s",
+			r.Method, path, UpdateGoldenEnv, got, want)
+	}
+}
+
+// canonicalJSON indents v with object keys sorted
+func canonicalJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	out, err := json.MarshalIndent(generic, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// textOf joins the text blocks of a tool result
+func textOf(result *mcp.ToolsCallResult) string {
+	var parts []string
+	for _, block := range result.Content {
+		if block.Type == "text" {
+			parts = append(parts, block.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,306 @@
+// Package metrics records MCP server activity and exposes it in the Prometheus text format.
+//
+// Set ServerConfig.Metrics to have the server record every request and tool call; the HTTP
+// transport then serves the metrics on /metrics:
+//
+//	m := metrics.New()
+//	server := mcp.NewServer(mcp.ServerConfig{Name: "my-server", Tools: myTools, Metrics: m})
+//
+// Tools executed outside the server (for example through tools.Call from another tool) can
+// be instrumented with the Tool middleware:
+//
+//	quote = m.Tool(quote)
+//
+// Exposed series:
+//
+//	minimcp_requests_total{method,status}            JSON-RPC requests by method and outcome
+//	minimcp_request_duration_seconds{method}         request latency histogram
+//	minimcp_requests_in_flight                       requests currently being processed
+//	minimcp_tool_calls_total{tool,status}            tool calls by tool and outcome
+//	minimcp_tool_call_duration_seconds{tool}         tool latency histogram
+//	minimcp_tool_calls_in_flight{tool}               tool calls currently running
+//
+// Status is "ok" or "error", so error rates are the ratio of the two.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the latency histogram bucket upper bounds, in seconds
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Status label values
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// Metrics collects request and tool call metrics. It is safe for concurrent use.
+type Metrics struct {
+	requests        *counterVec
+	requestDuration *histogramVec
+	requestsActive  *gaugeVec
+
+	toolCalls    *counterVec
+	toolDuration *histogramVec
+	toolsActive  *gaugeVec
+}
+
+// New creates a collector using DefaultBuckets
+func New() *Metrics {
+	return NewWithBuckets(DefaultBuckets)
+}
+
+// NewWithBuckets creates a collector whose latency histograms use buckets (in seconds, ascending)
+func NewWithBuckets(buckets []float64) *Metrics {
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &Metrics{
+		requests: newCounterVec("minimcp_requests_total",
+			"Total JSON-RPC requests by method and status.", "method", "status"),
+		requestDuration: newHistogramVec("minimcp_request_duration_seconds",
+			"JSON-RPC request latency in seconds.", buckets, "method"),
+		requestsActive: newGaugeVec("minimcp_requests_in_flight",
+			"JSON-RPC requests currently being processed."),
+
+		toolCalls: newCounterVec("minimcp_tool_calls_total",
+			"Total tool calls by tool and status.", "tool", "status"),
+		toolDuration: newHistogramVec("minimcp_tool_call_duration_seconds",
+			"Tool call latency in seconds.", buckets, "tool"),
+		toolsActive: newGaugeVec("minimcp_tool_calls_in_flight",
+			"Tool calls currently running.", "tool"),
+	}
+}
+
+// StartRequest marks a request as in flight. Call the returned function with the
+// request's outcome when it completes.
+func (m *Metrics) StartRequest(method string) func(err bool) {
+	start := time.Now()
+	m.requestsActive.add(1)
+	return func(err bool) {
+		m.requestsActive.add(-1)
+		m.requests.inc(method, status(err))
+		m.requestDuration.observe(time.Since(start).Seconds(), method)
+	}
+}
+
+// StartToolCall marks a tool call as running. Call the returned function with the
+// call's outcome when it completes.
+func (m *Metrics) StartToolCall(tool string) func(err bool) {
+	start := time.Now()
+	m.toolsActive.add(1, tool)
+	return func(err bool) {
+		m.toolsActive.add(-1, tool)
+		m.toolCalls.inc(tool, status(err))
+		m.toolDuration.observe(time.Since(start).Seconds(), tool)
+	}
+}
+
+// ServeHTTP writes all metrics in the Prometheus text exposition format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes all metrics in the Prometheus text exposition format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	m.requests.write(&b)
+	m.requestDuration.write(&b)
+	m.requestsActive.write(&b)
+	m.toolCalls.write(&b)
+	m.toolDuration.write(&b)
+	m.toolsActive.write(&b)
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func status(err bool) string {
+	if err {
+		return StatusError
+	}
+	return StatusOK
+}
+
+// family holds the series of one metric, keyed by their label values
+type family struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string][]string // key -> label values
+}
+
+func newFamily(name, help, kind string, labels []string) family {
+	return family{name: name, help: help, kind: kind, labels: labels, series: make(map[string][]string)}
+}
+
+// key registers the label values and returns their series key. Callers hold f.mu.
+func (f *family) key(values []string) string {
+	k := strings.Join(values, "\xff")
+	if _, ok := f.series[k]; !ok {
+		f.series[k] = append([]string(nil), values...)
+	}
+	return k
+}
+
+// sortedKeys returns series keys in a stable order. Callers hold f.mu.
+func (f *family) sortedKeys() []string {
+	keys := make([]string, 0, len(f.series))
+	for k := range f.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (f *family) header(b *strings.Builder) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.kind)
+}
+
+// labelString formats label pairs, with optional extra trailing pairs
+func (f *family) labelString(values []string, extra ...string) string {
+	if len(f.labels) == 0 && len(extra) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(f.labels)+len(extra)/2)
+	for i, label := range f.labels {
+		pairs = append(pairs, label+`="`+escapeLabel(values[i])+`"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+escapeLabel(extra[i+1])+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+type counterVec struct {
+	family
+	values map[string]float64
+}
+
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	return &counterVec{family: newFamily(name, help, "counter", labels), values: make(map[string]float64)}
+}
+
+func (c *counterVec) inc(values ...string) {
+	c.mu.Lock()
+	c.values[c.key(values)]++
+	c.mu.Unlock()
+}
+
+func (c *counterVec) write(b *strings.Builder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.header(b)
+	for _, k := range c.sortedKeys() {
+		fmt.Fprintf(b, "%s%s %s\n", c.name, c.labelString(c.series[k]), formatFloat(c.values[k]))
+	}
+}
+
+type gaugeVec struct {
+	family
+	values map[string]float64
+}
+
+func newGaugeVec(name, help string, labels ...string) *gaugeVec {
+	g := &gaugeVec{family: newFamily(name, help, "gauge", labels), values: make(map[string]float64)}
+	if len(labels) == 0 {
+		g.values[g.key(nil)] = 0
+	}
+	return g
+}
+
+func (g *gaugeVec) add(delta float64, values ...string) {
+	g.mu.Lock()
+	g.values[g.key(values)] += delta
+	g.mu.Unlock()
+}
+
+func (g *gaugeVec) write(b *strings.Builder) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.header(b)
+	for _, k := range g.sortedKeys() {
+		fmt.Fprintf(b, "%s%s %s\n", g.name, g.labelString(g.series[k]), formatFloat(g.values[k]))
+	}
+}
+
+type histogramVec struct {
+	family
+	buckets []float64
+	values  map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, non-cumulative
+	count  uint64
+	sum    float64
+}
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{
+		family:  newFamily(name, help, "histogram", labels),
+		buckets: buckets,
+		values:  make(map[string]*histogram),
+	}
+}
+
+func (h *histogramVec) observe(v float64, values ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	k := h.key(values)
+	hist, ok := h.values[k]
+	if !ok {
+		hist = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.values[k] = hist
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		hist.counts[i]++
+	}
+	hist.count++
+	hist.sum += v
+}
+
+func (h *histogramVec) write(b *strings.Builder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.header(b)
+	for _, k := range h.sortedKeys() {
+		values, hist := h.series[k], h.values[k]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += hist.counts[i]
+			fmt.Fprintf(b, "%s_bucket%s %d\n", h.name, h.labelString(values, "le", formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(b, "%s_bucket%s %d\n", h.name, h.labelString(values, "le", "+Inf"), hist.count)
+		fmt.Fprintf(b, "%s_sum%s %s\n", h.name, h.labelString(values), formatFloat(hist.sum))
+		fmt.Fprintf(b, "%s_count%s %d\n", h.name, h.labelString(values), hist.count)
+	}
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func TestMetrics_Exposition(t *testing.T) {
+	m := NewWithBuckets([]float64{1, 10})
+
+	m.StartRequest("tools/call")(false)
+	m.StartRequest("tools/call")(true)
+	done := m.StartRequest("tools/list")
+
+	var b strings.Builder
+	if _, err := m.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"# TYPE minimcp_requests_total counter",
+		`minimcp_requests_total{method="tools/call",status="error"} 1`,
+		`minimcp_requests_total{method="tools/call",status="ok"} 1`,
+		`minimcp_request_duration_seconds_bucket{method="tools/call",le="1"} 2`,
+		`minimcp_request_duration_seconds_bucket{method="tools/call",le="+Inf"} 2`,
+		`minimcp_request_duration_seconds_count{method="tools/call"} 2`,
+		"minimcp_requests_in_flight 1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in output:\n%s", want, out)
+		}
+	}
+
+	done(false)
+	b.Reset()
+	m.WriteTo(&b)
+	if !strings.Contains(b.String(), "minimcp_requests_in_flight 0") {
+		t.Errorf("expected in-flight gauge to return to zero:\n%s", b.String())
+	}
+}
+
+func TestMetrics_ToolMiddleware(t *testing.T) {
+	m := New()
+	failing := m.Tool(tools.NewTool("fails", "Always fails", func(ctx context.Context, in struct{}) (string, error) {
+		return "", errors.New("boom")
+	}))
+
+	if _, err := failing.Execute(context.Background(), json.RawMessage(`{}`)); err == nil {
+		t.Fatal("expected tool error")
+	}
+	if failing.Spec().Name != "fails" {
+		t.Errorf("expected wrapped spec to be preserved, got %q", failing.Spec().Name)
+	}
+
+	var b strings.Builder
+	m.WriteTo(&b)
+	for _, want := range []string{
+		`minimcp_tool_calls_total{tool="fails",status="error"} 1`,
+		`minimcp_tool_calls_in_flight{tool="fails"} 0`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if got := escapeLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Errorf("unexpected escaping %q", got)
+	}
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// instrumentedTool records call counts, latency, and in-flight calls for a wrapped tool
+type instrumentedTool struct {
+	tools.Tool
+	metrics *Metrics
+}
+
+// Tool wraps t so every Execute is recorded under t's name. A call counts as an error
+// when Execute returns an error or a result with Error set.
+//
+// Servers configured with ServerConfig.Metrics already record their tool calls; wrapping
+// their tools as well would count each call twice.
+func (m *Metrics) Tool(t tools.Tool) tools.Tool {
+	return &instrumentedTool{Tool: t, metrics: m}
+}
+
+// Execute runs the wrapped tool and records the call
+func (t *instrumentedTool) Execute(ctx context.Context, params json.RawMessage) (result *tools.ToolResult, err error) {
+	done := t.metrics.StartToolCall(t.Spec().Name)
+	failed := true // stays set if Execute panics
+	defer func() { done(failed) }()
+
+	result, err = t.Tool.Execute(ctx, params)
+	failed = err != nil || result.IsError()
+	return result, err
+}
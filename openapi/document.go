@@ -0,0 +1,273 @@
+// Package openapi turns the operations of an OpenAPI 3 document into MCP tools.
+//
+// Generate emits Go source declaring an input struct and a TypedTool for each operation;
+// the tools call the API through an Invoker at run time. The minimcp-gen command wraps
+// Generate:
+//
+//	//go:generate go run github.com/mhpenta/minimcp/cmd/minimcp-gen -in petstore.json -out petstore_gen.go -package petstore
+//
+// The generated NewTools function takes the Invoker:
+//
+//	invoker := openapi.NewInvoker(petstore.DefaultBaseURL).WithHeader("Authorization", "Bearer "+token)
+//	server := mcp.NewServer(mcp.ServerConfig{Name: "petstore", Version: "1.0.0", Tools: petstore.NewTools(invoker)})
+//
+// Documents must be JSON; convert YAML documents first.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Document is the subset of an OpenAPI 3 document used to generate tools
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+}
+
+// Server is a base URL the API is served at
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description"`
+}
+
+// PathItem holds the operations on one path
+type PathItem struct {
+	Parameters []*Parameter `json:"parameters"`
+	Get        *Operation   `json:"get"`
+	Put        *Operation   `json:"put"`
+	Post       *Operation   `json:"post"`
+	Delete     *Operation   `json:"delete"`
+	Patch      *Operation   `json:"patch"`
+	Head       *Operation   `json:"head"`
+	Options    *Operation   `json:"options"`
+}
+
+// Operation is one method on one path
+type Operation struct {
+	OperationID string       `json:"operationId"`
+	Summary     string       `json:"summary"`
+	Description string       `json:"description"`
+	Parameters  []*Parameter `json:"parameters"`
+	RequestBody *RequestBody `json:"requestBody"`
+	Deprecated  bool         `json:"deprecated"`
+}
+
+// Parameter is a path, query, header, or cookie parameter
+type Parameter struct {
+	Ref         string  `json:"$ref"`
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is an operation's request body
+type RequestBody struct {
+	Ref         string               `json:"$ref"`
+	Description string               `json:"description"`
+	Required    bool                 `json:"required"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+// MediaType is the schema of one content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the definitions operations refer to with $ref
+type Components struct {
+	Schemas       map[string]*Schema      `json:"schemas"`
+	Parameters    map[string]*Parameter   `json:"parameters"`
+	RequestBodies map[string]*RequestBody `json:"requestBodies"`
+}
+
+// Schema is the subset of a JSON Schema that maps onto Go types and tag constraints
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 SchemaType         `json:"type"`
+	Format               string             `json:"format"`
+	Description          string             `json:"description"`
+	Enum                 []interface{}      `json:"enum"`
+	Default              interface{}        `json:"default"`
+	Nullable             bool               `json:"nullable"`
+	Items                *Schema            `json:"items"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+	AllOf                []*Schema          `json:"allOf"`
+	OneOf                []*Schema          `json:"oneOf"`
+	AnyOf                []*Schema          `json:"anyOf"`
+	Pattern              string             `json:"pattern"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+}
+
+// SchemaType is a schema's type: a single name in OpenAPI 3.0, or a list that may
+// include "null" in OpenAPI 3.1
+type SchemaType []string
+
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = SchemaType{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("schema type must be a string or an array of strings: %w", err)
+	}
+	*t = names
+	return nil
+}
+
+// Name returns the type other than "null", or "" when there is none or several
+func (t SchemaType) Name() string {
+	name := ""
+	for _, n := range t {
+		if n == "null" {
+			continue
+		}
+		if name != "" {
+			return ""
+		}
+		name = n
+	}
+	return name
+}
+
+// Load reads an OpenAPI document from a JSON file
+func Load(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse decodes an OpenAPI 3 document from JSON
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing OpenAPI document: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q, expected 3.x", doc.OpenAPI)
+	}
+	return &doc, nil
+}
+
+// MethodOperation is an operation with the method and path it is served at
+type MethodOperation struct {
+	Method string
+	Path   string
+	*Operation
+
+	// Parameters merges the path item's parameters with the operation's, which take
+	// precedence, with references resolved
+	Parameters []*Parameter
+}
+
+// Operations lists the document's operations sorted by path, then by method in the
+// order GET, PUT, POST, DELETE, PATCH, HEAD, OPTIONS
+func (d *Document) Operations() ([]MethodOperation, error) {
+	paths := make([]string, 0, len(d.Paths))
+	for path := range d.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var ops []MethodOperation
+	for _, path := range paths {
+		item := d.Paths[path]
+		if item == nil {
+			continue
+		}
+		for _, m := range []struct {
+			method string
+			op     *Operation
+		}{
+			{"GET", item.Get}, {"PUT", item.Put}, {"POST", item.Post}, {"DELETE", item.Delete},
+			{"PATCH", item.Patch}, {"HEAD", item.Head}, {"OPTIONS", item.Options},
+		} {
+			if m.op == nil {
+				continue
+			}
+			params, err := d.mergeParameters(item.Parameters, m.op.Parameters)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", m.method, path, err)
+			}
+			ops = append(ops, MethodOperation{Method: m.method, Path: path, Operation: m.op, Parameters: params})
+		}
+	}
+	return ops, nil
+}
+
+// mergeParameters resolves both lists, letting operation parameters override path item
+// parameters with the same name and location
+func (d *Document) mergeParameters(shared, own []*Parameter) ([]*Parameter, error) {
+	var merged []*Parameter
+	index := map[string]int{}
+	for _, list := range [][]*Parameter{shared, own} {
+		for _, param := range list {
+			resolved, err := d.resolveParameter(param)
+			if err != nil {
+				return nil, err
+			}
+			key := resolved.In + ":" + resolved.Name
+			if i, ok := index[key]; ok {
+				merged[i] = resolved
+				continue
+			}
+			index[key] = len(merged)
+			merged = append(merged, resolved)
+		}
+	}
+	return merged, nil
+}
+
+func (d *Document) resolveParameter(param *Parameter) (*Parameter, error) {
+	if param.Ref == "" {
+		return param, nil
+	}
+	name, ok := strings.CutPrefix(param.Ref, "#/components/parameters/")
+	if resolved := d.Components.Parameters[name]; ok && resolved != nil {
+		return d.resolveParameter(resolved)
+	}
+	return nil, fmt.Errorf("unresolved parameter reference %q", param.Ref)
+}
+
+// ResolveRequestBody follows a request body reference
+func (d *Document) ResolveRequestBody(body *RequestBody) (*RequestBody, error) {
+	if body == nil || body.Ref == "" {
+		return body, nil
+	}
+	name, ok := strings.CutPrefix(body.Ref, "#/components/requestBodies/")
+	if resolved := d.Components.RequestBodies[name]; ok && resolved != nil {
+		return d.ResolveRequestBody(resolved)
+	}
+	return nil, fmt.Errorf("unresolved request body reference %q", body.Ref)
+}
+
+// schemaRefName returns the component name of a schema reference
+func schemaRefName(ref string) (string, bool) {
+	return strings.CutPrefix(ref, "#/components/schemas/")
+}
@@ -0,0 +1,685 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GenerateOptions configures Generate
+type GenerateOptions struct {
+	// Package is the generated file's package name
+	Package string
+
+	// Source names the document in the generated file's header, such as its file name
+	Source string
+}
+
+// Generate returns formatted Go source declaring, for each of the document's operations,
+// an input struct and a TypedTool that sends the request through an openapi.Invoker.
+//
+// Parameters become struct fields whose description and jsonschema tags carry the
+// document's descriptions and constraints; a JSON request body becomes a "body" field.
+// Object schemas in components become named types, except recursive ones, which become
+// maps. Operations with a request body that is not JSON are skipped with a comment.
+func Generate(doc *Document, opts GenerateOptions) ([]byte, error) {
+	if opts.Package == "" {
+		return nil, fmt.Errorf("package name is required")
+	}
+	if !token.IsIdentifier(opts.Package) {
+		return nil, fmt.Errorf("invalid package name %q", opts.Package)
+	}
+	ops, err := doc.Operations()
+	if err != nil {
+		return nil, err
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("document has no operations")
+	}
+
+	g := &generator{
+		doc:       doc,
+		recursive: recursiveComponents(doc),
+		typeNames: map[string]string{},
+		decls:     map[string]string{},
+	}
+
+	var constructors []string
+	var tools bytes.Buffer
+	toolNames := map[string]string{}
+	for _, op := range ops {
+		opName, toolName := operationNames(op)
+		where := op.Method + " " + op.Path
+		if other, ok := toolNames[toolName]; ok {
+			return nil, fmt.Errorf("%s and %s both generate tool %q", other, where, toolName)
+		}
+		toolNames[toolName] = where
+
+		source, skip, err := g.operation(op, opName, toolName)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", where, err)
+		}
+		if skip != "" {
+			constructors = append(constructors, fmt.Sprintf("// Skipped %s: %s", where, skip))
+			continue
+		}
+		constructors = append(constructors, "new"+opName+"Tool(invoker),")
+		tools.WriteString(source)
+	}
+
+	var out bytes.Buffer
+	source := ""
+	if opts.Source != "" {
+		source = " from " + opts.Source
+	}
+	fmt.Fprintf(&out, "// Code generated by minimcp-gen%s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&out, "package %s\n\n", opts.Package)
+	out.WriteString("import (\n\t\"context\"\n\n\t\"github.com/mhpenta/minimcp/openapi\"\n\t\"github.com/mhpenta/minimcp/tools\"\n)\n\n")
+
+	baseURL := ""
+	if len(doc.Servers) > 0 {
+		baseURL = doc.Servers[0].URL
+	}
+	out.WriteString("// DefaultBaseURL is the first server listed in the document\n")
+	fmt.Fprintf(&out, "const DefaultBaseURL = %s\n\n", strconv.Quote(baseURL))
+
+	title := strings.TrimSpace(doc.Info.Title + " " + doc.Info.Version)
+	if title == "" {
+		title = "the API"
+	}
+	fmt.Fprintf(&out, "// NewTools returns a tool for each operation of %s\n", oneLine(title))
+	out.WriteString("func NewTools(invoker *openapi.Invoker) []tools.Tool {\n\treturn []tools.Tool{\n")
+	for _, line := range constructors {
+		out.WriteString("\t\t" + line + "\n")
+	}
+	out.WriteString("\t}\n}\n")
+	out.Write(tools.Bytes())
+
+	names := make([]string, 0, len(g.decls))
+	for name := range g.decls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		out.WriteString(g.decls[name])
+	}
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, out.Bytes())
+	}
+	return formatted, nil
+}
+
+// generator accumulates the type declarations operations refer to
+type generator struct {
+	doc *Document
+
+	// recursive holds the components that refer to themselves
+	recursive map[string]bool
+
+	// typeNames maps component names to the Go types declared for them
+	typeNames map[string]string
+
+	// decls maps Go type names to their declarations
+	decls map[string]string
+}
+
+// field is one field of a generated struct
+type field struct {
+	goName      string
+	jsonName    string
+	goType      string
+	required    bool
+	description string
+	constraints string
+}
+
+// operation returns the input struct and tool constructor of op, or why it was skipped
+func (g *generator) operation(op MethodOperation, opName, toolName string) (string, string, error) {
+	inputName := opName + "Input"
+	var fields []field
+	used := map[string]bool{}
+	type paramField struct{ in, name string }
+	var paramFields []paramField
+
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "path", "query", "header":
+		default:
+			continue
+		}
+		jsonName := param.Name
+		if used[jsonName] {
+			jsonName = param.In + "_" + param.Name
+		}
+		used[jsonName] = true
+
+		required := param.Required || param.In == "path"
+		f, err := g.field(inputName, jsonName, param.Schema, required, param.Description)
+		if err != nil {
+			return "", "", fmt.Errorf("parameter %q: %w", param.Name, err)
+		}
+		fields = append(fields, f)
+		paramFields = append(paramFields, paramField{param.In, param.Name})
+	}
+
+	body, err := g.doc.ResolveRequestBody(op.RequestBody)
+	if err != nil {
+		return "", "", err
+	}
+	hasBody := false
+	if body != nil && len(body.Content) > 0 {
+		schema, ok := jsonContent(body.Content)
+		if !ok {
+			return "", "request body is not JSON", nil
+		}
+		jsonName := "body"
+		for used[jsonName] {
+			jsonName = "request_" + jsonName
+		}
+		f, err := g.field(inputName, jsonName, schema, body.Required, body.Description)
+		if err != nil {
+			return "", "", fmt.Errorf("request body: %w", err)
+		}
+		fields = append(fields, f)
+		hasBody = true
+	}
+	uniqueGoNames(fields)
+	params := map[string][]string{}
+	for i, p := range paramFields {
+		params[p.in] = append(params[p.in], fmt.Sprintf("%s: in.%s,", strconv.Quote(p.name), fields[i].goName))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n// %s holds the parameters of %s %s\n", inputName, op.Method, op.Path)
+	b.WriteString(structDecl(inputName, fields))
+
+	fmt.Fprintf(&b, "\nfunc new%sTool(invoker *openapi.Invoker) tools.Tool {\n", opName)
+	b.WriteString("\treturn tools.NewTool(\n")
+	fmt.Fprintf(&b, "\t\t%s,\n\t\t%s,\n", strconv.Quote(toolName), strconv.Quote(toolDescription(op)))
+	fmt.Fprintf(&b, "\t\tfunc(ctx context.Context, in %s) (interface{}, error) {\n", inputName)
+	b.WriteString("\t\t\treturn invoker.Do(ctx, openapi.Request{\n")
+	fmt.Fprintf(&b, "\t\t\t\tMethod: %s,\n\t\t\t\tPath: %s,\n", strconv.Quote(op.Method), strconv.Quote(op.Path))
+	for _, in := range []struct{ location, field string }{{"path", "PathParams"}, {"query", "Query"}, {"header", "Header"}} {
+		if len(params[in.location]) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\t\t\t\t%s: map[string]interface{}{\n", in.field)
+		for _, entry := range params[in.location] {
+			b.WriteString("\t\t\t\t\t" + entry + "\n")
+		}
+		b.WriteString("\t\t\t\t},\n")
+	}
+	if hasBody {
+		fmt.Fprintf(&b, "\t\t\t\tBody: in.%s,\n", fields[len(fields)-1].goName)
+	}
+	b.WriteString("\t\t\t})\n\t\t},\n")
+	b.WriteString("\t\ttools.WithInputValidation(true),\n")
+	if op.Method == "DELETE" {
+		b.WriteString("\t\ttools.WithDestructive(true),\n")
+	}
+	b.WriteString("\t)\n}\n")
+	return b.String(), "", nil
+}
+
+// field maps a property or parameter onto a struct field. Optional scalars and structs
+// become pointers so that unset values are left out of the request.
+func (g *generator) field(parent, jsonName string, schema *Schema, required bool, description string) (field, error) {
+	goName := exportedName(jsonName)
+	goType, err := g.goType(schema, parent+goName)
+	if err != nil {
+		return field{}, err
+	}
+	if !required && !strings.HasPrefix(goType, "[]") && !strings.HasPrefix(goType, "map[") && goType != "interface{}" {
+		goType = "*" + goType
+	}
+
+	resolved := g.resolve(schema)
+	if description == "" && resolved != nil {
+		description = resolved.Description
+	}
+	return field{
+		goName:      goName,
+		jsonName:    jsonName,
+		goType:      goType,
+		required:    required,
+		description: description,
+		constraints: constraintTag(resolved, g.resolve(itemsOf(resolved))),
+	}, nil
+}
+
+// resolve follows references to components that are not declared as named types, so
+// that their constraints apply to the fields using them
+func (g *generator) resolve(schema *Schema) *Schema {
+	for depth := 0; schema != nil && schema.Ref != "" && depth < 32; depth++ {
+		name, ok := schemaRefName(schema.Ref)
+		target := g.doc.Components.Schemas[name]
+		if !ok || target == nil || isObject(target) {
+			return nil
+		}
+		schema = target
+	}
+	return schema
+}
+
+// goType returns the Go type of schema, declaring named types as needed. name is used for
+// inline objects.
+func (g *generator) goType(schema *Schema, name string) (string, error) {
+	if schema == nil {
+		return "interface{}", nil
+	}
+	if schema.Ref != "" {
+		return g.refType(schema.Ref)
+	}
+	if len(schema.AllOf) == 1 && len(schema.OneOf) == 0 && len(schema.AnyOf) == 0 {
+		return g.goType(schema.AllOf[0], name)
+	}
+	if len(schema.AllOf) > 0 || len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 {
+		return "interface{}", nil
+	}
+
+	switch typeName(schema) {
+	case "string":
+		return "string", nil
+	case "integer":
+		if schema.Format == "int32" {
+			return "int32", nil
+		}
+		return "int64", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		elem, err := g.goType(schema.Items, name+"Item")
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case "object":
+		if len(schema.Properties) > 0 {
+			return g.declareStruct(name, schema, "an inline schema")
+		}
+		var additional Schema
+		if len(schema.AdditionalProperties) > 0 && json.Unmarshal(schema.AdditionalProperties, &additional) == nil {
+			elem, err := g.goType(&additional, name+"Value")
+			if err != nil {
+				return "", err
+			}
+			return "map[string]" + elem, nil
+		}
+		return "map[string]interface{}", nil
+	}
+	return "interface{}", nil
+}
+
+// refType returns the Go type of a component reference
+func (g *generator) refType(ref string) (string, error) {
+	name, ok := schemaRefName(ref)
+	target := g.doc.Components.Schemas[name]
+	if !ok || target == nil {
+		return "", fmt.Errorf("unresolved schema reference %q", ref)
+	}
+	if g.recursive[name] {
+		if isObject(target) {
+			return "map[string]interface{}", nil
+		}
+		return "interface{}", nil
+	}
+	if !isObject(target) {
+		return g.goType(target, exportedName(name))
+	}
+	if goName, ok := g.typeNames[name]; ok {
+		return goName, nil
+	}
+	goName := exportedName(name)
+	g.typeNames[name] = goName
+	return g.declareStruct(goName, target, "the "+name+" schema")
+}
+
+// declareStruct declares a struct type for an object schema and returns its name. origin
+// describes the schema in the type's doc comment.
+func (g *generator) declareStruct(name string, schema *Schema, origin string) (string, error) {
+	if _, ok := g.decls[name]; ok {
+		return "", fmt.Errorf("more than one type named %s", name)
+	}
+	// Reserve the name before declaring fields, which may declare more types
+	g.decls[name] = ""
+
+	required := map[string]bool{}
+	for _, prop := range schema.Required {
+		required[prop] = true
+	}
+	props := make([]string, 0, len(schema.Properties))
+	for prop := range schema.Properties {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+
+	fields := make([]field, 0, len(props))
+	for _, prop := range props {
+		f, err := g.field(name, prop, schema.Properties[prop], required[prop], "")
+		if err != nil {
+			return "", fmt.Errorf("property %q: %w", prop, err)
+		}
+		fields = append(fields, f)
+	}
+	uniqueGoNames(fields)
+
+	doc := fmt.Sprintf("// %s is generated from %s\n", name, origin)
+	if schema.Description != "" {
+		doc += "//\n// " + oneLine(schema.Description) + "\n"
+	}
+	g.decls[name] = "\n" + doc + structDecl(name, fields)
+	return name, nil
+}
+
+// structDecl returns a struct type declaration
+func structDecl(name string, fields []field) string {
+	if len(fields) == 0 {
+		return fmt.Sprintf("type %s struct{}\n", name)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	for _, f := range fields {
+		tags := []string{"json:" + strconv.Quote(f.jsonName+omitEmpty(f))}
+		if f.description != "" {
+			tags = append(tags, "description:"+strconv.Quote(oneLine(f.description)))
+		}
+		if f.constraints != "" {
+			tags = append(tags, "jsonschema:"+strconv.Quote(f.constraints))
+		}
+		tag := strings.ReplaceAll(strings.Join(tags, " "), "`", "'")
+		fmt.Fprintf(&b, "\t%s %s `%s`\n", f.goName, f.goType, tag)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func omitEmpty(f field) string {
+	if f.required {
+		return ""
+	}
+	return ",omitempty"
+}
+
+// constraintTag returns the jsonschema constraint tag of a schema. On arrays, value
+// constraints come from the items, as the tag applies them there. Values the tag syntax
+// cannot hold are left out.
+func constraintTag(schema, items *Schema) string {
+	if schema == nil {
+		return ""
+	}
+	var pairs []string
+	add := func(key, value string) {
+		if value != "" && !strings.ContainsAny(value, ",\n") {
+			pairs = append(pairs, key+"="+value)
+		}
+	}
+
+	values := schema
+	if typeName(schema) == "array" {
+		values = items
+	}
+	if values != nil {
+		if len(values.Enum) > 0 {
+			options := make([]string, 0, len(values.Enum))
+			for _, v := range values.Enum {
+				s, ok := scalarString(v)
+				if !ok || strings.ContainsAny(s, "|,") {
+					options = nil
+					break
+				}
+				options = append(options, s)
+			}
+			add("enum", strings.Join(options, "|"))
+		}
+		switch values.Format {
+		case "date", "date-time", "time", "email", "uri", "uuid", "hostname", "ipv4", "ipv6":
+			add("format", values.Format)
+		}
+		add("pattern", values.Pattern)
+		add("minimum", formatFloat(values.Minimum))
+		add("maximum", formatFloat(values.Maximum))
+		add("minLength", formatInt(values.MinLength))
+		add("maxLength", formatInt(values.MaxLength))
+	}
+	if s, ok := scalarString(schema.Default); ok {
+		add("default", s)
+	}
+	add("minItems", formatInt(schema.MinItems))
+	add("maxItems", formatInt(schema.MaxItems))
+	return strings.Join(pairs, ",")
+}
+
+func scalarString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+func formatFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
+
+func formatInt(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+// jsonContent returns the schema of the JSON media type in content
+func jsonContent(content map[string]MediaType) (*Schema, bool) {
+	types := make([]string, 0, len(content))
+	for mediaType := range content {
+		types = append(types, mediaType)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		if parsed, _, err := mime.ParseMediaType(t); err == nil && isJSON(parsed) {
+			return content[t].Schema, true
+		}
+	}
+	return nil, false
+}
+
+func itemsOf(schema *Schema) *Schema {
+	if schema == nil {
+		return nil
+	}
+	return schema.Items
+}
+
+// typeName returns a schema's type, inferring object from properties
+func typeName(schema *Schema) string {
+	if name := schema.Type.Name(); name != "" {
+		return name
+	}
+	if len(schema.Properties) > 0 {
+		return "object"
+	}
+	return ""
+}
+
+// isObject reports whether a component is declared as a struct type
+func isObject(schema *Schema) bool {
+	return schema.Ref == "" && typeName(schema) == "object" && len(schema.Properties) > 0 &&
+		len(schema.AllOf) == 0 && len(schema.OneOf) == 0 && len(schema.AnyOf) == 0
+}
+
+// recursiveComponents returns the component schemas that refer back to themselves
+func recursiveComponents(doc *Document) map[string]bool {
+	refs := map[string][]string{}
+	for name, schema := range doc.Components.Schemas {
+		refs[name] = collectRefs(schema, nil)
+	}
+	recursive := map[string]bool{}
+	for name := range refs {
+		seen := map[string]bool{}
+		queue := append([]string(nil), refs[name]...)
+		for len(queue) > 0 {
+			next := queue[0]
+			queue = queue[1:]
+			if next == name {
+				recursive[name] = true
+				break
+			}
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, refs[next]...)
+			}
+		}
+	}
+	return recursive
+}
+
+// collectRefs appends the component names schema refers to
+func collectRefs(schema *Schema, refs []string) []string {
+	if schema == nil {
+		return refs
+	}
+	if name, ok := schemaRefName(schema.Ref); ok {
+		refs = append(refs, name)
+	}
+	refs = collectRefs(schema.Items, refs)
+	for _, prop := range schema.Properties {
+		refs = collectRefs(prop, refs)
+	}
+	for _, list := range [][]*Schema{schema.AllOf, schema.OneOf, schema.AnyOf} {
+		for _, s := range list {
+			refs = collectRefs(s, refs)
+		}
+	}
+	var additional Schema
+	if len(schema.AdditionalProperties) > 0 && json.Unmarshal(schema.AdditionalProperties, &additional) == nil {
+		refs = collectRefs(&additional, refs)
+	}
+	return refs
+}
+
+// operationNames returns the Go and tool names of an operation, derived from its
+// operationId or, without one, from its method and path
+func operationNames(op MethodOperation) (string, string) {
+	source := op.OperationID
+	if source == "" {
+		source = strings.ToLower(op.Method) + " " + op.Path
+	}
+	words := splitWords(source)
+	lower := make([]string, len(words))
+	for i, w := range words {
+		lower[i] = strings.ToLower(w)
+	}
+	return exportedName(source), strings.Join(lower, "_")
+}
+
+// toolDescription combines an operation's summary and description
+func toolDescription(op MethodOperation) string {
+	summary := strings.TrimSpace(op.Summary)
+	description := strings.TrimSpace(op.Description)
+	text := summary
+	switch {
+	case text == "":
+		text = description
+	case description != "" && description != summary:
+		text += "\n\n" + description
+	}
+	if text == "" {
+		text = op.Method + " " + op.Path
+	}
+	if op.Deprecated {
+		text = "Deprecated. " + text
+	}
+	return text
+}
+
+// commonInitialisms are written in upper case in Go names
+var commonInitialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true,
+	"SQL": true, "TLS": true, "UI": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// exportedName converts a name such as "pet-id" or "listPets" to an exported Go
+// identifier such as "PetID" or "ListPets"
+func exportedName(s string) string {
+	var b strings.Builder
+	for _, w := range splitWords(s) {
+		if upper := strings.ToUpper(w); commonInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(strings.ToLower(w))
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// splitWords splits a name at non-alphanumeric characters and case changes:
+// "getPetByID" becomes get, Pet, By, ID
+func splitWords(s string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(s)
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := word[len(word)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return words
+}
+
+// uniqueGoNames renames fields whose Go names collide
+func uniqueGoNames(fields []field) {
+	seen := map[string]bool{}
+	for i := range fields {
+		name := fields[i].goName
+		for n := 2; seen[name]; n++ {
+			name = fmt.Sprintf("%s%d", fields[i].goName, n)
+		}
+		seen[name] = true
+		fields[i].goName = name
+	}
+}
+
+// oneLine collapses whitespace so text fits in a comment or tag
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
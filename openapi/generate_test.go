@@ -0,0 +1,158 @@
+package openapi
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+// The example's generated file must match what Generate produces, so that the generator's
+// output is reviewed whenever it changes. Run go generate ./examples/... to update it.
+func TestGenerate_Petstore(t *testing.T) {
+	doc, err := Load("../examples/petstore/petstore/petstore.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Generate(doc, GenerateOptions{Package: "petstore", Source: "petstore.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("../examples/petstore/petstore/petstore_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("generated code differs from petstore_gen.go; run go generate ./examples/...\n%s", got)
+	}
+}
+
+func TestGenerate_Schemas(t *testing.T) {
+	doc, err := Parse([]byte(`{
+		"openapi": "3.1.0",
+		"paths": {
+			"/nodes/{node-id}": {
+				"patch": {
+					"parameters": [
+						{"name": "node-id", "in": "path", "schema": {"type": "string", "format": "uuid"}},
+						{"name": "node_id", "in": "query", "description": "Has a ` + "`" + `backquote` + "`" + `", "schema": {"type": ["integer", "null"]}},
+						{"name": "session", "in": "cookie", "schema": {"type": "string"}}
+					],
+					"requestBody": {"content": {"application/merge-patch+json": {"schema": {"$ref": "#/components/schemas/Node"}}}}
+				}
+			}
+		},
+		"components": {"schemas": {
+			"Node": {"type": "object", "properties": {
+				"children": {"type": "array", "items": {"$ref": "#/components/schemas/Node"}},
+				"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+				"value": {"oneOf": [{"type": "string"}, {"type": "number"}]}
+			}}
+		}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := Generate(doc, GenerateOptions{Package: "nodes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := oneLine(string(source))
+	for _, want := range []string{
+		"// Code generated by minimcp-gen. DO NOT EDIT.",
+		`const DefaultBaseURL = ""`,
+		`"patch_nodes_node_id"`,
+		"type PatchNodesNodeIDInput struct",
+		"NodeID string `json:\"node-id\" jsonschema:\"format=uuid\"`",
+		"NodeID2 *int64 `json:\"node_id,omitempty\" description:\"Has a 'backquote'\"`",
+		// Node refers to itself, so it is left untyped
+		"Body map[string]interface{} `json:\"body,omitempty\"`",
+		`"node-id": in.NodeID,`,
+		`"node_id": in.NodeID2,`,
+		"Body: in.Body,",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "session") || strings.Contains(got, "type Node ") {
+		t.Errorf("expected no cookie parameters and no Node type:\n%s", got)
+	}
+}
+
+func TestGenerate_NestedTypes(t *testing.T) {
+	doc, err := Parse([]byte(`{
+		"openapi": "3.0.0",
+		"paths": {"/orders": {"post": {
+			"operationId": "createOrder",
+			"requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Order"}}}}
+		}}},
+		"components": {"schemas": {
+			"Order": {"type": "object", "required": ["lines"], "properties": {
+				"lines": {"type": "array", "minItems": 1, "items": {"$ref": "#/components/schemas/Line"}},
+				"note": {"type": "string", "pattern": "a,b"}
+			}},
+			"Line": {"type": "object", "properties": {"sku": {"type": "string", "enum": ["a|b", "c"]}, "qty": {"type": "integer", "format": "int32"}}}
+		}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := Generate(doc, GenerateOptions{Package: "orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := oneLine(string(source))
+	for _, want := range []string{
+		"Body Order `json:\"body\"`",
+		"Lines []Line `json:\"lines\" jsonschema:\"minItems=1\"`",
+		// Neither value fits the tag syntax
+		"Note *string `json:\"note,omitempty\"`",
+		"Qty *int32 `json:\"qty,omitempty\"`",
+		"Sku *string `json:\"sku,omitempty\"`",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	cases := map[string]string{
+		`{"openapi": "2.0", "paths": {}}`:   "unsupported OpenAPI version",
+		`{"openapi": "3.0.0", "paths": {}}`: "no operations",
+		`{"openapi": "3.0.0", "paths": {"/a": {"get": {"operationId": "same"}}, "/b": {"get": {"operationId": "same"}}}}`:                                    `both generate tool "same"`,
+		`{"openapi": "3.0.0", "paths": {"/a": {"get": {"parameters": [{"$ref": "#/components/parameters/Missing"}]}}}}`:                                      "unresolved parameter reference",
+		`{"openapi": "3.0.0", "paths": {"/a": {"get": {"parameters": [{"name": "x", "in": "query", "schema": {"$ref": "#/components/schemas/Missing"}}]}}}}`: "unresolved schema reference",
+	}
+	for doc, want := range cases {
+		parsed, err := Parse([]byte(doc))
+		if err == nil {
+			_, err = Generate(parsed, GenerateOptions{Package: "api"})
+		}
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected error containing %q, got %v", doc, want, err)
+		}
+	}
+
+	doc, _ := Parse([]byte(`{"openapi": "3.0.0", "paths": {"/a": {"get": {}}}}`))
+	if _, err := Generate(doc, GenerateOptions{Package: "not-valid"}); err == nil {
+		t.Error("expected an invalid package name to fail")
+	}
+}
+
+func TestNames(t *testing.T) {
+	cases := []struct{ in, exported, tool string }{
+		{"listPets", "ListPets", "list_pets"},
+		{"getPetByID", "GetPetByID", "get_pet_by_id"},
+		{"HTTPServerStatus", "HTTPServerStatus", "http_server_status"},
+		{"get /users/{user_id}/api-keys", "GetUsersUserIDAPIKeys", "get_users_user_id_api_keys"},
+		{"2fa.verify", "X2faVerify", "2fa_verify"},
+	}
+	for _, tc := range cases {
+		exported, tool := operationNames(MethodOperation{Operation: &Operation{OperationID: tc.in}})
+		if exported != tc.exported || tool != tc.tool {
+			t.Errorf("%q: got %q, %q, want %q, %q", tc.in, exported, tool, tc.exported, tc.tool)
+		}
+	}
+}
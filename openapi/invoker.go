@@ -0,0 +1,243 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// DefaultMaxResponseBytes is the largest response body an Invoker reads by default
+const DefaultMaxResponseBytes = 10 * 1024 * 1024
+
+// Request is one call to an API operation, built by generated tools
+type Request struct {
+	Method string
+
+	// Path is the operation's path template, such as "/pets/{petId}"
+	Path string
+
+	// PathParams, Query, and Header map parameter names to values. Nil pointers are
+	// left out, slices become repeated query parameters, and other values are
+	// formatted with fmt.
+	PathParams map[string]interface{}
+	Query      map[string]interface{}
+	Header     map[string]interface{}
+
+	// Body, unless nil, is sent as JSON
+	Body interface{}
+}
+
+// StatusError is returned for responses with a 4xx or 5xx status
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("%s %s: %s", e.Method, e.URL, e.Status)
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// Invoker sends the requests of generated tools to an API
+type Invoker struct {
+	baseURL          string
+	client           *http.Client
+	header           http.Header
+	maxResponseBytes int64
+}
+
+// NewInvoker creates an invoker for the API served at baseURL
+func NewInvoker(baseURL string) *Invoker {
+	return &Invoker{
+		baseURL:          strings.TrimRight(baseURL, "/"),
+		client:           http.DefaultClient,
+		header:           http.Header{},
+		maxResponseBytes: DefaultMaxResponseBytes,
+	}
+}
+
+// WithHeader adds a header sent with every request, such as Authorization
+func (i *Invoker) WithHeader(key, value string) *Invoker {
+	i.header.Add(key, value)
+	return i
+}
+
+// WithHTTPClient sets the client used to send requests (default http.DefaultClient)
+func (i *Invoker) WithHTTPClient(client *http.Client) *Invoker {
+	i.client = client
+	return i
+}
+
+// WithMaxResponseBytes sets the largest response body read (default 10MB); larger
+// responses fail
+func (i *Invoker) WithMaxResponseBytes(n int64) *Invoker {
+	i.maxResponseBytes = n
+	return i
+}
+
+// Do sends req and returns the decoded JSON response, or the body as a string when the
+// response is not JSON. Responses with a 4xx or 5xx status return a *StatusError. Each
+// request draws one sub-call, and the bytes of its response, from the request budget in ctx.
+func (i *Invoker) Do(ctx context.Context, req Request) (interface{}, error) {
+	if err := tools.SpendCall(ctx); err != nil {
+		return nil, err
+	}
+
+	path, err := expandPath(req.Path, req.PathParams)
+	if err != nil {
+		return nil, err
+	}
+	target := i.baseURL + path
+	if query := encodeQuery(req.Query); query != "" {
+		target += "?" + query
+	}
+
+	var body io.Reader
+	if req.Body != nil && !isNil(reflect.ValueOf(req.Body)) {
+		data, err := json.Marshal(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("encoding request body: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range i.header {
+		httpReq.Header[key] = append([]string(nil), values...)
+	}
+	for name, value := range req.Header {
+		for _, v := range formatValues(value) {
+			httpReq.Header.Add(name, v)
+		}
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	httpReq.Header.Set("Accept", "application/json")
+
+	resp, err := i.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, i.maxResponseBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	if int64(len(data)) > i.maxResponseBytes {
+		return nil, fmt.Errorf("%s %s: response exceeds %d bytes", req.Method, target, i.maxResponseBytes)
+	}
+	if err := tools.SpendBytes(ctx, int64(len(data))); err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, &StatusError{
+			Method:     req.Method,
+			URL:        target,
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       strings.TrimSpace(string(data)),
+		}
+	}
+	if len(data) == 0 {
+		return resp.Status, nil
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); isJSON(mediaType) {
+		var decoded interface{}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return nil, fmt.Errorf("decoding response: %w", err)
+		}
+		return decoded, nil
+	}
+	return string(data), nil
+}
+
+// isJSON reports whether a media type carries JSON, such as application/problem+json
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// expandPath substitutes path parameters into a path template
+func expandPath(template string, params map[string]interface{}) (string, error) {
+	path := template
+	for name, value := range params {
+		values := formatValues(value)
+		if len(values) == 0 {
+			return "", fmt.Errorf("path parameter %q is required", name)
+		}
+		path = strings.ReplaceAll(path, "{"+name+"}", url.PathEscape(strings.Join(values, ",")))
+	}
+	if strings.Contains(path, "{") {
+		return "", fmt.Errorf("path %q has unfilled parameters", path)
+	}
+	return path, nil
+}
+
+// encodeQuery encodes query parameters in a stable order
+func encodeQuery(params map[string]interface{}) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	query := url.Values{}
+	for _, name := range names {
+		for _, v := range formatValues(params[name]) {
+			query.Add(name, v)
+		}
+	}
+	return query.Encode()
+}
+
+// formatValues formats a parameter value: nothing for nil, one string per element for
+// slices, and one string otherwise
+func formatValues(value interface{}) []string {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		values := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, formatValues(v.Index(i).Interface())...)
+		}
+		return values
+	}
+	return []string{fmt.Sprint(v.Interface())}
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func TestInvoker_Do(t *testing.T) {
+	var got *http.Request
+	var gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		switch r.URL.Path {
+		case "/api/text":
+			w.Write([]byte("plain"))
+		case "/api/fail":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"title":"taken"}` + "\n"))
+		default:
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	defer server.Close()
+
+	invoker := NewInvoker(server.URL+"/api/").WithHeader("Authorization", "Bearer token")
+	limit := int32(5)
+	var unset *string
+	result, err := invoker.Do(context.Background(), Request{
+		Method:     "POST",
+		Path:       "/items/{name}",
+		PathParams: map[string]interface{}{"name": "a b/c"},
+		Query:      map[string]interface{}{"limit": &limit, "tag": []string{"x", "y"}, "cursor": unset},
+		Header:     map[string]interface{}{"X-Trace": "t1", "X-Unset": unset},
+		Body:       map[string]string{"k": "v"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := result.(map[string]interface{}); !ok || m["ok"] != true {
+		t.Errorf("expected the decoded JSON response, got %#v", result)
+	}
+	if got.URL.EscapedPath() != "/api/items/a%20b%2Fc" || got.URL.RawQuery != "limit=5&tag=x&tag=y" {
+		t.Errorf("unexpected URL %s", got.URL)
+	}
+	if got.Header.Get("Authorization") != "Bearer token" || got.Header.Get("X-Trace") != "t1" || got.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected headers %v", got.Header)
+	}
+	if _, ok := got.Header["X-Unset"]; ok {
+		t.Error("expected nil header values to be left out")
+	}
+	if gotBody != `{"k":"v"}` {
+		t.Errorf("unexpected body %q", gotBody)
+	}
+
+	var nilBody *struct{}
+	if result, err := invoker.Do(context.Background(), Request{Method: "GET", Path: "/text", Body: nilBody}); err != nil || result != "plain" {
+		t.Errorf("expected text response, got %v, %v", result, err)
+	}
+	if gotBody != "" || got.Header.Get("Content-Type") != "" {
+		t.Errorf("expected a nil body to send nothing, got %q", gotBody)
+	}
+
+	_, err = invoker.Do(context.Background(), Request{Method: "PUT", Path: "/fail"})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusConflict || statusErr.Body != `{"title":"taken"}` {
+		t.Errorf("expected a StatusError, got %v", err)
+	}
+
+	if _, err := invoker.Do(context.Background(), Request{Method: "GET", Path: "/items/{name}"}); err == nil || !strings.Contains(err.Error(), "unfilled") {
+		t.Errorf("expected a missing path parameter to fail, got %v", err)
+	}
+	if _, err := invoker.WithMaxResponseBytes(5).Do(context.Background(), Request{Method: "GET", Path: "/text"}); err != nil {
+		t.Errorf("expected a response at the limit to succeed, got %v", err)
+	}
+	if _, err := invoker.WithMaxResponseBytes(4).Do(context.Background(), Request{Method: "GET", Path: "/text"}); err == nil {
+		t.Error("expected a response over the limit to fail")
+	}
+}
+
+func TestInvoker_SpendsBudget(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("plain"))
+	}))
+	defer server.Close()
+	invoker := NewInvoker(server.URL)
+	req := Request{Method: "GET", Path: "/text"}
+
+	tests := []struct {
+		name   string
+		limits tools.BudgetLimits
+	}{
+		{"calls", tools.BudgetLimits{MaxCalls: 1}},
+		{"bytes", tools.BudgetLimits{MaxBytes: 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tools.WithBudget(context.Background(), tools.NewBudget(tt.limits))
+			if _, err := invoker.Do(ctx, req); err != nil {
+				t.Fatalf("expected the first request to fit the budget, got %v", err)
+			}
+			if _, err := invoker.Do(ctx, req); !errors.Is(err, tools.ErrBudgetExceeded) {
+				t.Errorf("expected ErrBudgetExceeded, got %v", err)
+			}
+		})
+	}
+}
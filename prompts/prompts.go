@@ -0,0 +1,394 @@
+// Package prompts renders MCP prompts from Go text/template templates.
+//
+// A Library holds prompt templates and the partials they share. Each prompt takes a typed
+// argument struct: its MCP arguments are derived from the struct with infer, and the
+// strings clients send are validated and decoded into it before the template runs.
+// Templates split their output into messages with {{system}}, {{user}}, and {{assistant}}:
+//
+//	type ReviewArgs struct {
+//	    Language string `json:"language" jsonschema:"language of the code"`
+//	    Diff     string `json:"diff" jsonschema:"unified diff to review"`
+//	    Strict   bool   `json:"strict,omitempty" jsonschema:"flag every style issue"`
+//	}
+//
+//	lib := prompts.NewLibrary()
+//	if err := lib.AddPartial("tone", "Be direct and specific."); err != nil {
+//	    return err
+//	}
+//	err := prompts.Add[ReviewArgs](lib, "review", "Reviews a diff", `
+//	{{system}}You review {{.Language}} code. {{template "tone"}}
+//	{{user}}Review this diff{{if .Strict}}, flagging every style issue{{end}}:
+//	{{.Diff}}`)
+//	if err != nil {
+//	    return err
+//	}
+//	server := mcp.NewServer(mcp.ServerConfig{Name: "reviews", Prompts: lib.Prompts()})
+package prompts
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/mhpenta/minimcp/infer"
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// Message roles. MCP prompts have no system role, so Library.Prompts sends system
+// messages with the user role.
+const (
+	RoleSystem    = "system"
+	RoleUser      = mcp.RoleUser
+	RoleAssistant = mcp.RoleAssistant
+)
+
+// Message is one message of a rendered prompt
+type Message struct {
+	Role string
+	Text string
+}
+
+// Library is a set of prompt templates and the partials they share. It is safe for
+// concurrent use.
+type Library struct {
+	mu      sync.RWMutex
+	set     *template.Template
+	marker  string // starts a role marker; followed by the role and a NUL
+	prompts []*prompt
+}
+
+type prompt struct {
+	name        string
+	description string
+	arguments   []mcp.PromptArgument
+	properties  map[string]interface{}
+	validator   *infer.Validator
+
+	// decode unmarshals validated arguments into the prompt's argument struct
+	decode func(data []byte) (interface{}, error)
+}
+
+// NewLibrary creates an empty library
+func NewLibrary() *Library {
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		panic(fmt.Sprintf("prompts: reading random nonce: %v", err))
+	}
+	l := &Library{marker: "\x00role-" + hex.EncodeToString(nonce) + ":"}
+	l.set = template.New("").Funcs(template.FuncMap{
+		"system":    func() string { return l.marker + RoleSystem + "\x00" },
+		"user":      func() string { return l.marker + RoleUser + "\x00" },
+		"assistant": func() string { return l.marker + RoleAssistant + "\x00" },
+		"join":      strings.Join,
+		"trim":      strings.TrimSpace,
+		"indent":    indent,
+	})
+	return l
+}
+
+// WithFuncs adds functions templates may call. Add them before the templates that use
+// them.
+func (l *Library) WithFuncs(funcs template.FuncMap) *Library {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.set.Funcs(funcs)
+	return l
+}
+
+// AddPartial defines a template prompts include with {{template "name" .}}. Redefining a
+// partial replaces it.
+func (l *Library) AddPartial(name, text string) error {
+	if name == "" || strings.HasPrefix(name, promptPrefix) {
+		return fmt.Errorf("invalid partial name %q", name)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.set.New(name).Parse(text); err != nil {
+		return fmt.Errorf("parsing partial %q: %w", name, err)
+	}
+	return nil
+}
+
+// LoadPartials defines a partial for each file of fsys matching the patterns, named by
+// its base name without extension, so partials/tone.tmpl is included with
+// {{template "tone" .}}
+func (l *Library) LoadPartials(fsys fs.FS, patterns ...string) error {
+	for _, pattern := range patterns {
+		files, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			text, err := fs.ReadFile(fsys, file)
+			if err != nil {
+				return err
+			}
+			if err := l.AddPartial(baseName(file), string(text)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Add registers a prompt rendered from text with arguments of type T, a struct. Each
+// field is an MCP argument described by its jsonschema tag, and is required unless it
+// is a pointer or omitempty.
+func Add[T any](l *Library, name, description, text string) error {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("prompt %q: arguments must be a struct, not %s", name, t)
+	}
+	schema, err := infer.FromType[T]()
+	if err != nil {
+		return fmt.Errorf("prompt %q: %w", name, err)
+	}
+	schemaMap, err := infer.ToMap(schema)
+	if err != nil {
+		return fmt.Errorf("prompt %q: %w", name, err)
+	}
+	validator, err := infer.Compile(schemaMap)
+	if err != nil {
+		return fmt.Errorf("prompt %q: %w", name, err)
+	}
+
+	p := &prompt{
+		name:        name,
+		description: description,
+		validator:   validator,
+		decode: func(data []byte) (interface{}, error) {
+			var args T
+			err := json.Unmarshal(data, &args)
+			return args, err
+		},
+	}
+	p.properties, _ = schemaMap["properties"].(map[string]interface{})
+	required := make(map[string]bool)
+	if list, ok := schemaMap["required"].([]interface{}); ok {
+		for _, name := range list {
+			required[fmt.Sprint(name)] = true
+		}
+	}
+	for _, arg := range argumentNames(t, p.properties) {
+		description, _ := p.properties[arg].(map[string]interface{})["description"].(string)
+		p.arguments = append(p.arguments, mcp.PromptArgument{Name: arg, Description: description, Required: required[arg]})
+	}
+	return l.add(p, text)
+}
+
+// AddFile registers a prompt read from a template file of fsys, named by the file's base
+// name without extension. Partials may be defined in the file with {{define}}.
+func AddFile[T any](l *Library, fsys fs.FS, file, description string) error {
+	text, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return err
+	}
+	return Add[T](l, baseName(file), description, string(text))
+}
+
+// promptPrefix keeps prompt template names apart from partial names
+const promptPrefix = "prompt:"
+
+func (l *Library) add(p *prompt, text string) error {
+	if p.name == "" {
+		return errors.New("prompt has no name")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, existing := range l.prompts {
+		if existing.name == p.name {
+			return fmt.Errorf("prompt %q is already registered", p.name)
+		}
+	}
+	if _, err := l.set.New(promptPrefix + p.name).Parse(text); err != nil {
+		return fmt.Errorf("parsing prompt %q: %w", p.name, err)
+	}
+	l.prompts = append(l.prompts, p)
+	return nil
+}
+
+// Render renders the named prompt with the arguments of a prompts/get request. Unknown,
+// missing, and malformed arguments are reported as tools.NewInvalidParamsError.
+func (l *Library) Render(name string, args map[string]string) ([]Message, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	var p *prompt
+	for _, candidate := range l.prompts {
+		if candidate.name == name {
+			p = candidate
+			break
+		}
+	}
+	if p == nil {
+		return nil, fmt.Errorf("unknown prompt %q", name)
+	}
+
+	data, err := p.decodeArguments(args)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := l.set.ExecuteTemplate(&out, promptPrefix+name, data); err != nil {
+		return nil, fmt.Errorf("rendering prompt %q: %w", name, err)
+	}
+	return l.split(out.String()), nil
+}
+
+// decodeArguments converts the string arguments of a request to the prompt's argument
+// struct. Non-string arguments must hold JSON, such as 3, true, or ["a","b"].
+func (p *prompt) decodeArguments(args map[string]string) (interface{}, error) {
+	for _, arg := range p.arguments {
+		if _, ok := args[arg.Name]; arg.Required && !ok {
+			return nil, tools.NewInvalidParamsError(fmt.Sprintf("missing required argument %q", arg.Name))
+		}
+	}
+	object := make(map[string]json.RawMessage, len(args))
+	for name, value := range args {
+		property, ok := p.properties[name].(map[string]interface{})
+		if !ok {
+			return nil, tools.NewInvalidParamsError(fmt.Sprintf("unknown argument %q", name))
+		}
+		if acceptsString(property) {
+			object[name], _ = json.Marshal(value)
+			continue
+		}
+		raw := json.RawMessage(strings.TrimSpace(value))
+		if !json.Valid(raw) {
+			return nil, tools.NewInvalidParamsError(fmt.Sprintf("argument %q must be %v, got %q", name, property["type"], value))
+		}
+		object[name] = raw
+	}
+
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.validator.Validate(data); err != nil {
+		return nil, tools.NewInvalidParamsError(fmt.Sprintf("invalid arguments: %v", err))
+	}
+	return p.decode(data)
+}
+
+// split cuts rendered output into messages at role markers. Text before the first marker
+// is a user message; messages are trimmed and empty ones dropped.
+func (l *Library) split(out string) []Message {
+	var messages []Message
+	role := RoleUser
+	for {
+		text, rest, found := strings.Cut(out, l.marker)
+		if text = strings.TrimSpace(text); text != "" {
+			messages = append(messages, Message{Role: role, Text: text})
+		}
+		if !found {
+			return messages
+		}
+		role, out, _ = strings.Cut(rest, "\x00")
+	}
+}
+
+// Prompts returns the library's prompts for mcp.ServerConfig.Prompts or
+// Server.AddPrompts. System messages are sent as user messages, as MCP prompts have no
+// system role.
+func (l *Library) Prompts() []mcp.Prompt {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	prompts := make([]mcp.Prompt, 0, len(l.prompts))
+	for _, p := range l.prompts {
+		name := p.name
+		prompts = append(prompts, mcp.Prompt{
+			Name:        name,
+			Description: p.description,
+			Arguments:   p.arguments,
+			Render: func(ctx context.Context, args map[string]string) (*mcp.PromptsGetResult, error) {
+				messages, err := l.Render(name, args)
+				if err != nil {
+					return nil, err
+				}
+				result := &mcp.PromptsGetResult{Messages: make([]mcp.PromptMessage, len(messages))}
+				for i, message := range messages {
+					role := message.Role
+					if role == RoleSystem {
+						role = RoleUser
+					}
+					result.Messages[i] = mcp.NewPromptMessage(role, message.Text)
+				}
+				return result, nil
+			},
+		})
+	}
+	return prompts
+}
+
+// argumentNames returns the schema's property names in struct field order
+func argumentNames(t reflect.Type, properties map[string]interface{}) []string {
+	var names []string
+	seen := make(map[string]bool)
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+				walk(field.Type)
+				continue
+			}
+			name := tag
+			if name == "" {
+				name = field.Name
+			}
+			if _, ok := properties[name]; ok && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	walk(t)
+
+	// Properties the walk missed, such as those of embedded pointers, follow in name order
+	var rest []string
+	for name := range properties {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	return append(names, rest...)
+}
+
+// acceptsString reports whether a property schema's type includes string
+func acceptsString(property map[string]interface{}) bool {
+	switch typ := property["type"].(type) {
+	case string:
+		return typ == "string"
+	case []interface{}:
+		for _, t := range typ {
+			if t == "string" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// indent prefixes every line of s with n spaces
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+func baseName(file string) string {
+	base := path.Base(file)
+	return strings.TrimSuffix(base, path.Ext(base))
+}
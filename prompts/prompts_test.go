@@ -0,0 +1,165 @@
+package prompts
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/tools"
+)
+
+type reviewArgs struct {
+	Language string   `json:"language" jsonschema:"language of the code"`
+	Diff     string   `json:"diff" jsonschema:"unified diff to review"`
+	Strict   bool     `json:"strict,omitempty" jsonschema:"flag every style issue"`
+	Focus    []string `json:"focus,omitempty"`
+	MaxNotes *int     `json:"max_notes,omitempty" jsonschema:"minimum=1,description=most notes to leave"`
+}
+
+const reviewTemplate = `
+{{system}}You review {{.Language}} code. {{template "tone" .}}
+{{user}}Review this diff{{if .Strict}}, flagging every style issue{{end}}:
+{{.Diff}}
+{{- with .Focus}}
+Focus on {{join . ", "}}.{{end}}
+{{- with .MaxNotes}}
+Leave at most {{.}} notes.{{end}}
+{{assistant}}Here is my review of the {{.Language}} change:`
+
+func newReviewLibrary(t *testing.T) *Library {
+	t.Helper()
+	lib := NewLibrary()
+	if err := lib.AddPartial("tone", "Be direct."); err != nil {
+		t.Fatal(err)
+	}
+	if err := Add[reviewArgs](lib, "review", "Reviews a diff", reviewTemplate); err != nil {
+		t.Fatal(err)
+	}
+	return lib
+}
+
+func TestRender(t *testing.T) {
+	lib := newReviewLibrary(t)
+
+	messages, err := lib.Render("review", map[string]string{
+		"language":  "Go",
+		"diff":      "+x := 1",
+		"strict":    "true",
+		"focus":     `["naming", "errors"]`,
+		"max_notes": " 3 ",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Message{
+		{Role: RoleSystem, Text: "You review Go code. Be direct."},
+		{Role: RoleUser, Text: "Review this diff, flagging every style issue:\n+x := 1\nFocus on naming, errors.\nLeave at most 3 notes."},
+		{Role: RoleAssistant, Text: "Here is my review of the Go change:"},
+	}
+	if !reflect.DeepEqual(messages, want) {
+		t.Errorf("messages = %#v", messages)
+	}
+
+	// Text before the first role marker is a user message
+	if err := Add[struct{}](lib, "plain", "", "  Hello {{template \"tone\"}}  "); err != nil {
+		t.Fatal(err)
+	}
+	if messages, err := lib.Render("plain", nil); err != nil || !reflect.DeepEqual(messages, []Message{{RoleUser, "Hello Be direct."}}) {
+		t.Errorf("plain = %#v, %v", messages, err)
+	}
+
+	for _, args := range []map[string]string{
+		{"language": "Go"},
+		{"language": "Go", "diff": "x", "colour": "red"},
+		{"language": "Go", "diff": "x", "strict": "yes"},
+		{"language": "Go", "diff": "x", "max_notes": "0"},
+	} {
+		_, err := lib.Render("review", args)
+		var toolErr *tools.Error
+		if !errors.As(err, &toolErr) || toolErr.Code != tools.CodeInvalidParams {
+			t.Errorf("%v: expected invalid params, got %v", args, err)
+		}
+	}
+}
+
+func TestAdd_Errors(t *testing.T) {
+	lib := newReviewLibrary(t)
+	if err := Add[reviewArgs](lib, "review", "", "again"); err == nil {
+		t.Error("expected a duplicate name to fail")
+	}
+	if err := Add[string](lib, "text", "", "{{.}}"); err == nil {
+		t.Error("expected non-struct arguments to fail")
+	}
+	if err := Add[struct{}](lib, "broken", "", "{{if}}"); err == nil {
+		t.Error("expected a parse error")
+	}
+	if err := lib.AddPartial("prompt:review", "x"); err == nil {
+		t.Error("expected a partial shadowing a prompt to fail")
+	}
+}
+
+func TestFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"partials/tone.tmpl":   {Data: []byte("Be kind.")},
+		"partials/footer.tmpl": {Data: []byte("Thanks, {{.Name}}.")},
+		"greet.tmpl":           {Data: []byte(`{{system}}{{template "tone"}}{{user}}Hi, I'm {{.Name}}. {{template "footer" .}}`)},
+	}
+	lib := NewLibrary()
+	if err := lib.LoadPartials(fsys, "partials/*.tmpl"); err != nil {
+		t.Fatal(err)
+	}
+	type greetArgs struct {
+		Name string `json:"name"`
+	}
+	if err := AddFile[greetArgs](lib, fsys, "greet.tmpl", "Greets"); err != nil {
+		t.Fatal(err)
+	}
+	messages, err := lib.Render("greet", map[string]string{"name": "Ada"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 || messages[0].Text != "Be kind." || messages[1].Text != "Hi, I'm Ada. Thanks, Ada." {
+		t.Errorf("messages = %#v", messages)
+	}
+}
+
+func TestPrompts(t *testing.T) {
+	lib := newReviewLibrary(t).WithFuncs(map[string]interface{}{"shout": strings.ToUpper})
+	if err := Add[struct{}](lib, "loud", "", `{{shout "hi"}}`); err != nil {
+		t.Fatal(err)
+	}
+	prompts := lib.Prompts()
+	if len(prompts) != 2 || prompts[0].Name != "review" || prompts[0].Description != "Reviews a diff" {
+		t.Fatalf("prompts = %+v", prompts)
+	}
+	wantArgs := []mcp.PromptArgument{
+		{Name: "language", Description: "language of the code", Required: true},
+		{Name: "diff", Description: "unified diff to review", Required: true},
+		{Name: "strict", Description: "flag every style issue"},
+		{Name: "focus"},
+		{Name: "max_notes", Description: "most notes to leave"},
+	}
+	if !reflect.DeepEqual(prompts[0].Arguments, wantArgs) {
+		t.Errorf("arguments = %+v", prompts[0].Arguments)
+	}
+
+	result, err := prompts[0].Render(context.Background(), map[string]string{"language": "Go", "diff": "+x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roles []string
+	for _, message := range result.Messages {
+		roles = append(roles, message.Role)
+	}
+	if strings.Join(roles, ",") != "user,user,assistant" {
+		t.Errorf("roles = %v; system messages should be sent as user messages", roles)
+	}
+
+	if result, err := prompts[1].Render(context.Background(), nil); err != nil || result.Messages[0].Content.Text != "HI" {
+		t.Errorf("loud = %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,145 @@
+package safeunmarshal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// DuplicateKeyPolicy decides how an object that repeats a key decodes. In LLM output, a
+// repeated key often means the output is corrupted.
+type DuplicateKeyPolicy int
+
+const (
+	// DuplicateKeysLastWins keeps the last value, as encoding/json does
+	DuplicateKeysLastWins DuplicateKeyPolicy = iota
+
+	// DuplicateKeysFirstWins keeps the first value
+	DuplicateKeysFirstWins
+
+	// DuplicateKeysError fails with ErrDuplicateKey, naming the key and the object
+	DuplicateKeysError
+
+	// DuplicateKeysMerge collects the values of a repeated key into an array, in order,
+	// so {"a": 1, "a": 2} decodes as {"a": [1, 2]}
+	DuplicateKeysMerge
+)
+
+// unmarshalWithPolicy unmarshals data into v, resolving repeated keys by policy
+func unmarshalWithPolicy(data []byte, v interface{}, policy DuplicateKeyPolicy) error {
+	if policy != DuplicateKeysLastWins && json.Valid(data) {
+		resolved, err := resolveDuplicateKeys(data, policy)
+		if err != nil {
+			return err
+		}
+		data = resolved
+	}
+	return json.Unmarshal(data, v)
+}
+
+// resolveDuplicateKeys rewrites valid JSON so that no object repeats a key, keeping the
+// values policy chooses. data is returned as is when no key repeats.
+func resolveDuplicateKeys(data []byte, policy DuplicateKeyPolicy) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	r := &keyResolver{dec: dec, policy: policy}
+	resolved, err := r.value("$")
+	if err != nil {
+		return nil, err
+	}
+	if !r.found {
+		return data, nil
+	}
+	return resolved, nil
+}
+
+// keyResolver re-encodes a JSON document token by token, resolving repeated keys
+type keyResolver struct {
+	dec    *json.Decoder
+	policy DuplicateKeyPolicy
+	found  bool // a key repeated
+}
+
+// value re-encodes the value at path, named like $.steps[0]
+func (r *keyResolver) value(path string) ([]byte, error) {
+	tok, err := r.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		return r.object(path)
+	case json.Delim('['):
+		return r.array(path)
+	}
+	return json.Marshal(tok)
+}
+
+// object re-encodes the members of an object whose opening brace has been read
+func (r *keyResolver) object(path string) ([]byte, error) {
+	var keys []string
+	values := make(map[string][][]byte)
+	for r.dec.More() {
+		tok, err := r.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := tok.(string) // Token only returns strings for keys
+		value, err := r.value(path + "." + key)
+		if err != nil {
+			return nil, err
+		}
+		if _, seen := values[key]; !seen {
+			keys = append(keys, key)
+		} else {
+			r.found = true
+			if r.policy == DuplicateKeysError {
+				return nil, fmt.Errorf("%w %q in %s", ErrDuplicateKey, key, path)
+			}
+			if r.policy == DuplicateKeysFirstWins {
+				continue
+			}
+		}
+		values[key] = append(values[key], value)
+	}
+	if _, err := r.dec.Token(); err != nil {
+		return nil, err
+	}
+
+	out := []byte{'{'}
+	for i, key := range keys {
+		if i > 0 {
+			out = append(out, ',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		out = append(append(out, name...), ':')
+		if repeated := values[key]; len(repeated) > 1 {
+			out = append(append(append(out, '['), bytes.Join(repeated, []byte{','})...), ']')
+		} else {
+			out = append(out, repeated[0]...)
+		}
+	}
+	return append(out, '}'), nil
+}
+
+// array re-encodes the items of an array whose opening bracket has been read
+func (r *keyResolver) array(path string) ([]byte, error) {
+	out := []byte{'['}
+	for i := 0; r.dec.More(); i++ {
+		value, err := r.value(fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			out = append(out, ',')
+		}
+		out = append(out, value...)
+	}
+	if _, err := r.dec.Token(); err != nil {
+		return nil, err
+	}
+	return append(out, ']'), nil
+}
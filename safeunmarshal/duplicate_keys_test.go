@@ -0,0 +1,68 @@
+package safeunmarshal
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestToWithOptions_DuplicateKeys(t *testing.T) {
+	input := []byte(`{"name": "Ada", "tags": [{"id": 1, "id": 2}], "name": "Grace"}`)
+
+	tests := []struct {
+		policy DuplicateKeyPolicy
+		want   map[string]interface{}
+	}{
+		{DuplicateKeysLastWins, map[string]interface{}{
+			"name": "Grace",
+			"tags": []interface{}{map[string]interface{}{"id": 2.0}},
+		}},
+		{DuplicateKeysFirstWins, map[string]interface{}{
+			"name": "Ada",
+			"tags": []interface{}{map[string]interface{}{"id": 1.0}},
+		}},
+		{DuplicateKeysMerge, map[string]interface{}{
+			"name": []interface{}{"Ada", "Grace"},
+			"tags": []interface{}{map[string]interface{}{"id": []interface{}{1.0, 2.0}}},
+		}},
+	}
+	for _, tt := range tests {
+		opts := StrictOptions()
+		opts.DuplicateKeys = tt.policy
+		got, err := ToWithOptions[map[string]interface{}](input, opts)
+		if err != nil {
+			t.Fatalf("policy %d: %v", tt.policy, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("policy %d: got %v, want %v", tt.policy, got, tt.want)
+		}
+	}
+
+	opts := DefaultOptions()
+	opts.DuplicateKeys = DuplicateKeysError
+	_, err := ToWithOptions[map[string]interface{}](input, opts)
+	if !errors.Is(err, ErrDuplicateKey) || !strings.Contains(err.Error(), `"id" in $.tags[0]`) {
+		t.Errorf("expected ErrDuplicateKey for $.tags[0].id, got %v", err)
+	}
+
+	// Repaired JSON is checked too
+	_, err = ToWithOptions[map[string]interface{}]([]byte(`{a: 1, a: 2`), opts)
+	if !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("expected ErrDuplicateKey after repair, got %v", err)
+	}
+
+	// Without repeated keys, numbers keep their precision
+	type Big struct {
+		ID int64 `json:"id"`
+	}
+	got, err := ToWithOptions[Big]([]byte(`{"id": 9007199254740993}`), opts)
+	if err != nil || got.ID != 9007199254740993 {
+		t.Errorf("got %+v, %v", got, err)
+	}
+	opts.DuplicateKeys = DuplicateKeysFirstWins
+	got, err = ToWithOptions[Big]([]byte(`{"id": 9007199254740993, "id": 1}`), opts)
+	if err != nil || got.ID != 9007199254740993 {
+		t.Errorf("got %+v, %v", got, err)
+	}
+}
@@ -7,6 +7,15 @@ var (
 	// an array type
 	ErrExpectedJSONArray = errors.New("expected JSON array for array type")
 
-	// ErrJSONRepairFailed is returned when JSON repair attempts fail
+	// ErrJSONRepairFailed is returned when JSON repair attempts fail, or would discard
+	// content when AllowLossyRepair is off
 	ErrJSONRepairFailed = errors.New("JSON repair failed")
+
+	// ErrNoJSONFound is returned by ExtractAll and ToEach when the input contains no JSON
+	// object or array
+	ErrNoJSONFound = errors.New("no JSON object or array found")
+
+	// ErrDuplicateKey is returned when an object repeats a key and UnmarshalOptions has
+	// DuplicateKeysError
+	ErrDuplicateKey = errors.New("duplicate object key")
 )
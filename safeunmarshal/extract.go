@@ -0,0 +1,96 @@
+package safeunmarshal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// ExtractAll finds every JSON object and array embedded in raw, such as an LLM response
+// with several JSON blocks between prose, and returns them in order. Objects and arrays
+// nested in another are part of it, not separate results. Text that merely looks like
+// JSON, such as "[1]" in a footnote, is returned too if it is well-formed; malformed
+// blocks are skipped. It returns ErrNoJSONFound when raw contains no JSON.
+//
+// Usage:
+//
+//	blocks, err := safeunmarshal.ExtractAll(response)
+//	for _, block := range blocks {
+//	    // each block is a complete JSON object or array
+//	}
+func ExtractAll(raw []byte) ([][]byte, error) {
+	if len(raw) > DefaultMaxInputSize {
+		return nil, fmt.Errorf("input size %d exceeds maximum allowed size %d", len(raw), DefaultMaxInputSize)
+	}
+
+	var blocks [][]byte
+	for i := 0; i < len(raw); {
+		start := bytes.IndexAny(raw[i:], "{[")
+		if start < 0 {
+			break
+		}
+		found, next := scanJSONBlocks(raw, start+i)
+		blocks = append(blocks, found...)
+		i = next
+	}
+	if len(blocks) == 0 {
+		return nil, ErrNoJSONFound
+	}
+	return blocks, nil
+}
+
+// ToEach decodes every JSON object and array that ExtractAll finds in raw into a value of
+// type T. A block that does not decode into T fails the whole call, with its index.
+//
+// Usage:
+//
+//	steps, err := safeunmarshal.ToEach[Step](response)
+func ToEach[T any](raw []byte) ([]T, error) {
+	blocks, err := ExtractAll(raw)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]T, len(blocks))
+	for i, block := range blocks {
+		if err := json.Unmarshal(block, &values[i]); err != nil {
+			return nil, fmt.Errorf("JSON block %d: %w", i, err)
+		}
+	}
+	return values, nil
+}
+
+// scanJSONBlocks tokenizes the JSON value that starts at raw[start]. If the value is
+// well-formed, it is the only block; otherwise the blocks are the outermost objects and
+// arrays completed inside it before the syntax error. It also returns where to resume:
+// openers before the error would fail at the same place, so skipping them keeps
+// ExtractAll linear.
+func scanJSONBlocks(raw []byte, start int) ([][]byte, int) {
+	dec := json.NewDecoder(bytes.NewReader(raw[start:]))
+	var blocks [][]byte
+	var blockStarts, opens []int
+	for {
+		offset := int(dec.InputOffset())
+		tok, err := dec.Token()
+		if err != nil {
+			return blocks, start + max(offset, 1)
+		}
+		delim, ok := tok.(json.Delim)
+		if !ok {
+			continue
+		}
+		end := start + int(dec.InputOffset())
+		if delim == '{' || delim == '[' {
+			opens = append(opens, end-1)
+			continue
+		}
+		open := opens[len(opens)-1]
+		opens = opens[:len(opens)-1]
+		if len(opens) == 0 {
+			return [][]byte{raw[open:end]}, end
+		}
+		for len(blockStarts) > 0 && blockStarts[len(blockStarts)-1] > open {
+			blocks, blockStarts = blocks[:len(blocks)-1], blockStarts[:len(blockStarts)-1]
+		}
+		blocks, blockStarts = append(blocks, raw[open:end]), append(blockStarts, open)
+	}
+}
@@ -0,0 +1,74 @@
+package safeunmarshal
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractAll(t *testing.T) {
+	response := "First the user:\n```json\n{\"name\": \"Ada\", \"tags\": [\"math\"]}\n```\n" +
+		"Then a note with {broken json} and a brace in a string:\n" +
+		`{"name": "Grace", "bio": "likes } and ["}` + " and finally [1, 2, 3]."
+
+	blocks, err := ExtractAll([]byte(response))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, block := range blocks {
+		got = append(got, string(block))
+	}
+	want := []string{
+		`{"name": "Ada", "tags": ["math"]}`,
+		`{"name": "Grace", "bio": "likes } and ["}`,
+		`[1, 2, 3]`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("blocks = %q, want %q", got, want)
+	}
+
+	if _, err := ExtractAll([]byte("no json here, just [brackets")); !errors.Is(err, ErrNoJSONFound) {
+		t.Errorf("expected ErrNoJSONFound, got %v", err)
+	}
+}
+
+func TestToEach(t *testing.T) {
+	type Person struct {
+		Name string `json:"name"`
+	}
+
+	people, err := ToEach[Person]([]byte(`Here: {"name": "Ada"} and {"name": "Grace"}.`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Person{{Name: "Ada"}, {Name: "Grace"}}; !reflect.DeepEqual(people, want) {
+		t.Errorf("people = %+v, want %+v", people, want)
+	}
+
+	_, err = ToEach[Person]([]byte(`{"name": "Ada"} then [1, 2]`))
+	if err == nil || !strings.Contains(err.Error(), "JSON block 1") {
+		t.Errorf("expected the second block to fail, got %v", err)
+	}
+}
+
+func TestExtractAll_Nested(t *testing.T) {
+	blocks, err := ExtractAll([]byte(`[1, {"a": [2]}, {"b": 3}, oops [4]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, block := range blocks {
+		got = append(got, string(block))
+	}
+	if want := []string{`{"a": [2]}`, `{"b": 3}`, `[4]`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("blocks = %q, want %q", got, want)
+	}
+
+	// Unclosed openers are each tokenized once
+	raw := strings.Repeat("[", 100000) + strings.Repeat(`{"a": "x`, 20000) + `{"ok": true}`
+	if blocks, err := ExtractAll([]byte(raw)); err != nil || len(blocks) != 1 || string(blocks[0]) != `{"ok": true}` {
+		t.Errorf("ExtractAll = %q, %v", blocks, err)
+	}
+}
@@ -0,0 +1,212 @@
+package safeunmarshal
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// NonFiniteMode decides how the JSON5 numbers NaN, Infinity, and -Infinity are decoded
+type NonFiniteMode int
+
+const (
+	// NonFiniteReject leaves them invalid, as encoding/json does. Repair, if enabled,
+	// quotes them as text.
+	NonFiniteReject NonFiniteMode = iota
+
+	// NonFiniteNull decodes them as null
+	NonFiniteNull
+
+	// NonFiniteString decodes them as the strings "NaN", "Infinity", and "-Infinity", for
+	// fields with a custom UnmarshalJSON
+	NonFiniteString
+)
+
+// usesJSON5 reports whether opts accept any JSON5 syntax
+func (opts UnmarshalOptions) usesJSON5() bool {
+	return opts.AllowComments || opts.NonFinite != NonFiniteReject || opts.AllowHexNumbers || opts.AllowNewlinesInStrings
+}
+
+// offsetShift records that from out offset pos on, input offsets are delta further on
+type offsetShift struct {
+	pos, delta int
+}
+
+// json5Normalizer rewrites the JSON5 syntax that opts accept into JSON, from the first
+// object or array on. Prose before it is copied as is, so its apostrophes and URLs are
+// not taken for strings and comments.
+type json5Normalizer struct {
+	src    []byte
+	pos    int
+	out    []byte
+	opts   UnmarshalOptions
+	shifts []offsetShift
+}
+
+// normalizeJSON5 returns src with the JSON5 syntax that opts accept rewritten into JSON,
+// and the shifts that map offsets in it back to src
+func normalizeJSON5(src []byte, opts UnmarshalOptions) ([]byte, []offsetShift) {
+	start := bytes.IndexAny(src, "{[")
+	if start < 0 {
+		return src, nil
+	}
+	n := &json5Normalizer{src: src, pos: start, out: append(make([]byte, 0, len(src)), src[:start]...), opts: opts}
+	valueStart := true // a string may open here, after { [ , or :
+	for n.pos < len(src) {
+		c := src[n.pos]
+		switch {
+		case c == '"' || c == '\'' && valueStart:
+			n.quoted(c)
+		case c == '/' && opts.AllowComments && n.comment():
+			continue
+		case isJSON5WordByte(c):
+			n.word()
+		default:
+			n.out = append(n.out, c)
+			n.pos++
+		}
+		if !isJSONSpace(c) {
+			valueStart = c == '{' || c == '[' || c == ',' || c == ':'
+		}
+	}
+	return n.out, n.shifts
+}
+
+// quoted copies a string, escaping its line breaks if allowed
+func (n *json5Normalizer) quoted(quote byte) {
+	n.out = append(n.out, quote)
+	n.pos++
+	for n.pos < len(n.src) {
+		c := n.src[n.pos]
+		switch {
+		case c == '\\' && n.pos+1 < len(n.src):
+			n.out = append(n.out, c, n.src[n.pos+1])
+			n.pos += 2
+			continue
+		case c == quote:
+			n.out = append(n.out, c)
+			n.pos++
+			return
+		case (c == '\n' || c == '\r') && n.opts.AllowNewlinesInStrings:
+			escaped := "\\n"
+			if c == '\r' {
+				escaped = "\\r"
+			}
+			n.replace(1, escaped)
+			continue
+		}
+		n.out = append(n.out, c)
+		n.pos++
+	}
+}
+
+// comment replaces the // or /* */ comment at pos with a space, reporting whether
+// there was one. A line comment keeps its line break.
+func (n *json5Normalizer) comment() bool {
+	rest := n.src[n.pos:]
+	var length int
+	switch {
+	case bytes.HasPrefix(rest, []byte("//")):
+		length = bytes.IndexByte(rest, '\n')
+		if length < 0 {
+			length = len(rest)
+		}
+	case bytes.HasPrefix(rest, []byte("/*")):
+		length = bytes.Index(rest[2:], []byte("*/"))
+		if length < 0 {
+			length = len(rest)
+		} else {
+			length += 4
+		}
+	default:
+		return false
+	}
+	n.replace(length, " ")
+	return true
+}
+
+// word copies an unquoted literal or number, rewriting non-finite and hex numbers if
+// allowed
+func (n *json5Normalizer) word() {
+	end := n.pos
+	for end < len(n.src) && isJSON5WordByte(n.src[end]) {
+		end++
+	}
+	word := string(n.src[n.pos:end])
+	if n.opts.NonFinite != NonFiniteReject {
+		if name, ok := nonFiniteName(word); ok {
+			replacement := "null"
+			if n.opts.NonFinite == NonFiniteString {
+				replacement = `"` + name + `"`
+			}
+			n.replace(len(word), replacement)
+			return
+		}
+	}
+	if n.opts.AllowHexNumbers {
+		if number, ok := hexNumber(word); ok {
+			n.replace(len(word), number)
+			return
+		}
+	}
+	n.out = append(n.out, word...)
+	n.pos = end
+}
+
+// replace writes s in place of the next length bytes of input
+func (n *json5Normalizer) replace(length int, s string) {
+	n.out = append(n.out, s...)
+	n.pos += length
+	if delta := n.pos - len(n.out); len(n.shifts) == 0 || n.shifts[len(n.shifts)-1].delta != delta {
+		n.shifts = append(n.shifts, offsetShift{pos: len(n.out), delta: delta})
+	}
+}
+
+// inputOffset maps an offset in normalized input back to the input
+func inputOffset(shifts []offsetShift, offset int) int {
+	i := sort.Search(len(shifts), func(i int) bool { return shifts[i].pos > offset })
+	if i == 0 {
+		return offset
+	}
+	return offset + shifts[i-1].delta
+}
+
+func isJSON5WordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '+' || c == '-' || c == '.'
+}
+
+// nonFiniteName returns the JSON5 name of NaN or an infinity, without a plus sign
+func nonFiniteName(word string) (string, bool) {
+	switch word {
+	case "NaN", "+NaN", "-NaN":
+		return "NaN", true
+	case "Infinity", "+Infinity":
+		return "Infinity", true
+	case "-Infinity":
+		return "-Infinity", true
+	}
+	return "", false
+}
+
+// hexNumber returns the decimal form of a hexadecimal integer such as 0x1F or -0xff
+func hexNumber(word string) (string, bool) {
+	sign := ""
+	switch {
+	case strings.HasPrefix(word, "-"):
+		sign, word = "-", word[1:]
+	case strings.HasPrefix(word, "+"):
+		word = word[1:]
+	}
+	if len(word) < 3 || word[0] != '0' || word[1] != 'x' && word[1] != 'X' || !isHex(word[2:]) {
+		return "", false
+	}
+	value, ok := new(big.Int).SetString(word[2:], 16)
+	if !ok {
+		return "", false
+	}
+	if value.Sign() == 0 {
+		sign = ""
+	}
+	return sign + value.String(), true
+}
@@ -0,0 +1,75 @@
+package safeunmarshal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_normalizeJSON5(t *testing.T) {
+	all := JSON5Options()
+	asStrings := JSON5Options()
+	asStrings.NonFinite = NonFiniteString
+
+	tests := []struct {
+		name     string
+		input    string
+		opts     UnmarshalOptions
+		expected string
+	}{
+		{"line comment", "{\"a\": 1, // the count\n\"b\": 2}", all, "{\"a\": 1,  \n\"b\": 2}"},
+		{"block comment", `[1, /* two */ 2]`, all, `[1,   2]`},
+		{"comment in string", `{"url": "http://x/*y*/"}`, all, `{"url": "http://x/*y*/"}`},
+		{"prose before JSON", `see http://x.com, it's here: {"a": 1}`, all, `see http://x.com, it's here: {"a": 1}`},
+		{"non-finite as null", `[NaN, Infinity, -Infinity, +Infinity]`, all, `[null, null, null, null]`},
+		{"non-finite as strings", `[NaN, -Infinity]`, asStrings, `["NaN", "-Infinity"]`},
+		{"non-finite in string", `{"a": "NaN"}`, all, `{"a": "NaN"}`},
+		{"hex numbers", `[0x1F, -0xff, 0X0, 0xffffffffffffffffff]`, all, `[31, -255, 0, 4722366482869645213695]`},
+		{"not hex", `[0x, 0xZZ, 10]`, all, `[0x, 0xZZ, 10]`},
+		{"newlines in strings", "{\"a\": \"one\ntwo\r\n\"}", all, `{"a": "one\ntwo\r\n"}`},
+		{"single-quoted string", `{'a': 'x // y'}`, all, `{'a': 'x // y'}`},
+		{"apostrophe in a word", "{a: O'Brien // name\n}", all, "{a: O'Brien  \n}"},
+		{"disabled", `[NaN, 0x1F] // note`, UnmarshalOptions{AllowComments: true}, `[NaN, 0x1F]  `},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := normalizeJSON5([]byte(tt.input), tt.opts)
+			if string(got) != tt.expected {
+				t.Errorf("normalizeJSON5(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestToWithOptions_JSON5(t *testing.T) {
+	type Config struct {
+		Name    string   `json:"name"`
+		Mask    int      `json:"mask"`
+		Ratio   *float64 `json:"ratio"`
+		Comment string   `json:"comment"`
+	}
+	input := []byte("```json\n{\n  // generated\n  \"name\": \"demo\",\n  \"mask\": 0xFF, /* bits */\n  \"ratio\": NaN,\n  \"comment\": \"first line\nsecond line\"\n}\n```")
+
+	got, err := ToWithOptions[Config](input, JSON5Options())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Config{Name: "demo", Mask: 255, Comment: "first line\nsecond line"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := ToWithOptions[Config](input, StrictOptions()); err == nil {
+		t.Error("expected strict options to reject JSON5")
+	}
+
+	// Repairs after rewritten syntax are reported at their offset in the input
+	opts := DefaultOptions()
+	opts.AllowHexNumbers = true
+	_, report, err := ToWithReport[map[string]interface{}]([]byte(`{"a": 0xFFFF, "b": 1,}`), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Repairs) != 1 || report.Repairs[0].Kind != RepairTrailingComma || report.Repairs[0].Offset != 20 {
+		t.Errorf("report = %v, want a trailing comma at 20", report)
+	}
+}
@@ -5,68 +5,45 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"regexp"
+	"sort"
 	"strings"
+	"unicode"
 )
 
-var (
-	ellipsisRe             = regexp.MustCompile(`\s*\.\.\.`)
-	arrayExtractRe         = regexp.MustCompile(`\[(.*?)(?:\]|$)`)
-	trailingCommaArrayRe   = regexp.MustCompile(`\[(.*?),\s*(?:\]|$)`)
-	quotedStringsRe        = regexp.MustCompile(`\[\s*"([^"]+)"\s+"([^"]+)"\s+"([^"]+)"\s+(\d+)`)
-	apostropheRe           = regexp.MustCompile(`\\'t`)
-	arrayMissingCommaRe    = regexp.MustCompile(`("[^"]*"|\d+|\w+)\s+("[^"]*"|\d+|\w+)`)
-	keyValPatternRe        = regexp.MustCompile(`"([^"]+)"\s*:\s*("([^"]*)"|\d+|true|false|null)`)
-	boolNullRe             = regexp.MustCompile(`(?i):\s*(true|false|null)(\s*[,}]|\s*$)`)
-	trueCaseRe             = regexp.MustCompile(`(?i)true`)
-	falseCaseRe            = regexp.MustCompile(`(?i)false`)
-	nullCaseRe             = regexp.MustCompile(`(?i)null`)
-	unquotedValueRe        = regexp.MustCompile(`(:\s*)([a-zA-Z][a-zA-Z0-9_]*)(\s*[,}]|\s*$)`)
-	unquotedValueEndRe     = regexp.MustCompile(`:\s*([a-zA-Z][a-zA-Z0-9_]*)$`)
-	unquotedKeyRe          = regexp.MustCompile(`([{,]\s*)([a-zA-Z0-9_]+)(\s*:)`)
-	trailingCommaBraceRe   = regexp.MustCompile(`,\s*}`)
-	trailingCommaBracketRe = regexp.MustCompile(`,\s*]`)
-)
+// maxRepairDepth bounds the nesting repairJSON follows, as encoding/json does
+const maxRepairDepth = 10000
 
 // repairJSON attempts to fix common JSON syntax errors and returns a valid JSON string.
-// This function handles several common JSON formatting issues including:
-// - Missing quotes around keys and string values
-// - Trailing commas in objects and arrays
-// - Missing closing brackets and braces
-// - Single quotes instead of double quotes
-// - Unquoted values that should be strings
+// It parses src with a character-level state machine that repairs as it goes, keeping as
+// much of the original data as possible:
+// - Single-quoted, unquoted, and unterminated strings
+// - Unescaped quotes, invalid escapes, and control characters inside strings
+// - Unquoted keys, missing colons, and missing or trailing commas
+// - Missing and mismatched closing brackets and braces
+// - Comments (//, /* */, and #), ellipses, and case variants of true, false, and null
 //
-// Note: This function tries to repair JSON even in cases where significant
-// modifications are needed. In some cases, the returned JSON may be a minimal
-// valid structure (like "{}" or "[]") if the original cannot be properly repaired.
+// Prose and Markdown fences around the first object or array are ignored. Input with no
+// object, array, or string repairs to "" (an empty JSON string). A string cut off before
+// any of its content, or a key without a value, is dropped.
 func repairJSON(src string) (string, error) {
+	return repairJSONWithReport(src, 0, nil)
+}
+
+// repairJSONWithReport is repairJSON, adding the repairs it makes to report, if not nil,
+// at their offset in src plus base
+func repairJSONWithReport(src string, base int, report *RepairReport) (string, error) {
 	if src == "" {
 		return "", nil // Maintain compatibility with existing code
 	}
 
-	src = strings.TrimSpace(src)
-	src = strings.TrimPrefix(src, "```json")
-	src = strings.TrimSuffix(src, "```")
-	src = strings.TrimSpace(src)
-
-	if src == "\"" {
-		return "\"\"", nil
-	}
-	if src == "\n" || src == " " {
-		return "\"\"", nil
-	}
-	if src == "]" || src == "}" {
-		return "\"\"", nil
-	}
-
-	if !strings.HasPrefix(src, "{") && !strings.HasPrefix(src, "[") && !strings.HasPrefix(src, "\"") {
-		objectStart := strings.IndexAny(src, "{[")
-		if objectStart >= 0 {
-			src = src[objectStart:]
-		} else {
-			return "\"\"", nil // For plain strings without JSON markers, return empty string as tests expect
+	trimmed, offset, ok := trimToJSON(src)
+	if !ok {
+		if report != nil {
+			report.Repairs = append(report.Repairs, Repair{Offset: base, Kind: RepairDataDropped, Detail: "found no JSON object, array, or string"})
 		}
+		return "\"\"", nil // For plain strings without JSON markers, return empty string as tests expect
 	}
+	src = trimmed
 
 	if json.Valid([]byte(src)) {
 		buf := &bytes.Buffer{}
@@ -77,238 +54,600 @@ func repairJSON(src string) (string, error) {
 		return buf.String(), nil
 	}
 
-	// Special cases for minimal inputs - don't return errors for basic structural repairs
-	if src == "[" {
-		return "[]", nil
+	r := &repairer{src: src, out: make([]byte, 0, len(src)+16), report: report, base: base + offset}
+	ok = r.value(ctxTop)
+	if report != nil {
+		sort.SliceStable(report.Repairs, func(i, j int) bool { return report.Repairs[i].Offset < report.Repairs[j].Offset })
 	}
-	if src == "{" {
-		return "{}", nil
+	if !ok {
+		if r.err != nil {
+			return "", r.err
+		}
+		return "\"\"", nil
 	}
-	if src == "[{]" {
-		return "[{}]", nil
+	if r.err != nil {
+		return "", r.err
 	}
-
-	if strings.HasPrefix(src, "[") && strings.Contains(src, "...") {
-		src = ellipsisRe.ReplaceAllString(src, "")
-		matches := arrayExtractRe.FindStringSubmatch(src)
-		if len(matches) > 1 {
-			elements := strings.Split(matches[1], ",")
-			var cleanElements []string
-			for _, elem := range elements {
-				elem = strings.TrimSpace(elem)
-				if elem != "" {
-					cleanElements = append(cleanElements, elem)
-				}
-			}
-			if len(cleanElements) > 0 {
-				return "[" + strings.Join(cleanElements, ",") + "]", nil
-			}
-		}
+	if !json.Valid(r.out) {
+		return "", fmt.Errorf("%w: unable to repair JSON", ErrJSONRepairFailed)
 	}
+	return string(r.out), nil
+}
 
-	if strings.HasPrefix(src, "[") && strings.HasSuffix(strings.TrimSpace(src), ",") {
-		matches := trailingCommaArrayRe.FindStringSubmatch(src)
-		if len(matches) > 1 {
-			return "[" + matches[1] + "]", nil
-		}
+// repairPartialJSON repairs JSON that may still be arriving, like repairJSON, except that
+// it does not reinterpret what is still being written: an unterminated string is kept
+// whole, and a trailing word that may be an incomplete literal or number is left out.
+// complete reports whether the document was closed. It returns "" until src contains an
+// object, array, or string.
+func repairPartialJSON(src string) (repaired string, complete bool, err error) {
+	src, _, ok := trimToJSON(src)
+	if !ok {
+		return "", false, nil
 	}
-
-	if strings.HasPrefix(src, "[\"") || strings.HasPrefix(src, "['") {
-		if quotedStringsRe.MatchString(src) {
-			return "[\"a\",\"b\",\"c\",1]", nil
-		}
+	r := &repairer{src: src, out: make([]byte, 0, len(src)+16), partial: true}
+	if !r.value(ctxTop) || r.err != nil {
+		return "", false, r.err
 	}
-
-	if apostropheRe.MatchString(src) {
-		src = apostropheRe.ReplaceAllString(src, "'t")
+	if !json.Valid(r.out) {
+		return "", false, fmt.Errorf("%w: unable to repair JSON", ErrJSONRepairFailed)
 	}
+	return string(r.out), !r.truncated, nil
+}
 
-	if strings.HasPrefix(src, "{\"") && len(src) <= 2 {
-		return "{}", nil
+// trimToJSON strips Markdown fences and prose before the first object or array, returning
+// the offset of what remains in src. ok is false when src has neither and does not start
+// with a string.
+func trimToJSON(src string) (trimmed string, offset int, ok bool) {
+	trimmed = strings.TrimLeftFunc(src, unicode.IsSpace)
+	trimmed = strings.TrimLeftFunc(strings.TrimPrefix(trimmed, "```json"), unicode.IsSpace)
+	offset = len(src) - len(trimmed)
+	trimmed = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+	trimmed = strings.TrimRightFunc(strings.TrimSuffix(trimmed, "```"), unicode.IsSpace)
+
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "\"") {
+		return trimmed, offset, true
 	}
-	if strings.HasPrefix(src, "[\"") && len(src) <= 2 {
-		return "[]", nil
+	objectStart := strings.IndexAny(trimmed, "{[")
+	if objectStart < 0 {
+		return "", 0, false
 	}
+	return trimmed[objectStart:], offset + objectStart, true
+}
 
-	repaired := src
-	repaired = replaceQuotes(repaired)
-	repaired = fixUnquotedKeys(repaired)
-	repaired = fixUnquotedValues(repaired)
-	repaired = removeTrailingCommas(repaired)
-	repaired = balanceBrackets(repaired)
-	if strings.Contains(repaired, "...") {
-		repaired = ellipsisRe.ReplaceAllString(repaired, "")
+// valueContext is where the repairer is reading a value, which decides what ends strings
+// and unquoted words
+type valueContext uint8
+
+const (
+	ctxTop         valueContext = iota // the document itself
+	ctxKey                             // an object key
+	ctxObjectValue                     // a value in an object
+	ctxArrayValue                      // a value in an array
+)
+
+// repairer parses malformed JSON, writing the repaired document to out. Each method
+// reads from pos and always makes progress, so the parse ends on any input.
+type repairer struct {
+	src   string
+	pos   int
+	out   []byte
+	stack []byte // the open containers, '{' or '['
+	err   error
+
+	partial   bool // the input may still be arriving, see repairPartialJSON
+	truncated bool // the input ended inside a string or container
+
+	report *RepairReport // receives each repair, if not nil
+	base   int           // the offset of src in the input the report describes
+}
+
+// note adds a repair at pos to the report
+func (r *repairer) note(pos int, kind RepairKind, format string, args ...interface{}) {
+	if r.report == nil {
+		return
 	}
+	r.report.Repairs = append(r.report.Repairs, Repair{Offset: r.base + pos, Kind: kind, Detail: fmt.Sprintf(format, args...)})
+}
 
-	if strings.HasPrefix(repaired, "[") {
-		repaired = arrayMissingCommaRe.ReplaceAllString(repaired, "$1,$2")
+// notes returns how many repairs have been reported, to withdraw later ones
+func (r *repairer) notes() int {
+	if r.report == nil {
+		return 0
 	}
+	return len(r.report.Repairs)
+}
 
-	if json.Valid([]byte(repaired)) {
-		buf := &bytes.Buffer{}
-		err := json.Compact(buf, []byte(repaired))
-		if err != nil {
-			return "", fmt.Errorf("error compacting repaired JSON: %w", err)
-		}
-		// Successfully repaired without data loss
-		return buf.String(), nil
+// withdraw removes the repairs reported after the first n
+func (r *repairer) withdraw(n int) {
+	if r.report != nil {
+		r.report.Repairs = r.report.Repairs[:n]
 	}
+}
 
-	if !json.Valid([]byte(repaired)) && strings.HasPrefix(repaired, "{") &&
-		(strings.Count(repaired, "{") > strings.Count(repaired, "}") ||
-			strings.Count(repaired, "[") > strings.Count(repaired, "]")) {
-		matches := keyValPatternRe.FindAllStringSubmatch(repaired, -1)
-
-		if len(matches) > 0 {
-			result := "{"
-			for i, match := range matches {
-				if i > 0 {
-					result += ","
-				}
-				key := match[1]
-				value := match[2]
-				result += "\"" + key + "\":" + value
-			}
-			result += "}"
-			repaired = result
-		}
+// value reads a value and writes it, reporting whether there was one
+func (r *repairer) value(ctx valueContext) bool {
+	r.skipSpace()
+	if r.pos >= len(r.src) {
+		return false
+	}
+	switch c := r.src[r.pos]; c {
+	case '{', '[':
+		return r.container(c)
+	case '"', '\'':
+		return r.quoted(c, ctx)
+	case '}', ']', ',', ':':
+		return false
 	}
+	return r.word(ctx)
+}
 
-	if json.Valid([]byte(repaired)) {
-		buf := &bytes.Buffer{}
-		err := json.Compact(buf, []byte(repaired))
-		if err != nil {
-			return "", fmt.Errorf("error compacting repaired JSON: %w", err)
-		}
-		// Successfully repaired with significant changes - return success per existing API
-		return buf.String(), nil
+// container reads an object or array, closing it if the input ends first
+func (r *repairer) container(open byte) bool {
+	if len(r.stack) >= maxRepairDepth {
+		r.err = fmt.Errorf("%w: nesting exceeds %d levels", ErrJSONRepairFailed, maxRepairDepth)
+		r.pos = len(r.src)
+		return false
+	}
+	r.stack = append(r.stack, open)
+	defer func() { r.stack = r.stack[:len(r.stack)-1] }()
+	r.pos++
+	r.out = append(r.out, open)
+
+	closer, other := byte('}'), byte(']')
+	if open == '[' {
+		closer, other = ']', '}'
 	}
+	first := true
+	comma := -1 // the comma after the last item, if not yet followed by another
+	for r.err == nil {
+		r.skipSpace()
+		if r.pos >= len(r.src) {
+			r.truncated = true
+			break
+		}
+		switch c := r.src[r.pos]; c {
+		case closer, other:
+			if comma >= 0 {
+				r.note(comma, RepairTrailingComma, "removed trailing comma")
+			}
+			if c == closer {
+				r.pos++
+				r.out = append(r.out, closer)
+				return true
+			}
+			// A mismatched closer ends this container if it closes an enclosing one
+			if r.encloses(other) {
+				r.note(r.pos, RepairBrackets, "closed %q before %q", open, other)
+				r.out = append(r.out, closer)
+				return true
+			}
+			r.note(r.pos, RepairBrackets, "removed unmatched %q", other)
+			r.pos++
+			continue
+		case ',', ':':
+			if c == ',' && !first && comma < 0 {
+				comma = r.pos
+			} else {
+				r.note(r.pos, RepairTrailingComma, "removed extra %q", c)
+			}
+			r.pos++
+			continue
+		}
 
-	// Last resort - return empty structures without errors to maintain compatibility
-	if !json.Valid([]byte(repaired)) {
-		if strings.HasPrefix(repaired, "{") {
-			return "{}", nil
+		mark, start := len(r.out), r.pos
+		if !first {
+			r.out = append(r.out, ',')
 		}
-		if strings.HasPrefix(repaired, "[") {
-			return "[]", nil
+		var ok bool
+		if open == '{' {
+			ok = r.member()
+		} else {
+			ok = r.value(ctxArrayValue)
 		}
+		if !ok {
+			r.out = r.out[:mark]
+			if r.pos == start {
+				r.note(r.pos, RepairDataDropped, "removed unexpected %q", r.src[r.pos])
+				r.pos++
+			} else {
+				// The comma before a dropped item separates the next one
+				comma = -1
+			}
+			continue
+		}
+		if !first && comma < 0 {
+			r.note(start, RepairMissingComma, "inserted missing comma")
+		}
+		first, comma = false, -1
 	}
-
-	// Complete failure - this is the only case where we return an error
-	return "", fmt.Errorf("%w: unable to repair JSON", ErrJSONRepairFailed)
+	if comma >= 0 {
+		r.note(comma, RepairTrailingComma, "removed trailing comma")
+	}
+	r.note(r.pos, RepairBrackets, "added missing %q", closer)
+	r.out = append(r.out, closer)
+	return true
 }
 
-// replaceQuotes converts single quotes to double quotes, handling escaping.
-func replaceQuotes(s string) string {
-	result := ""
-	inString := false
-	escape := false
+// encloses reports whether an enclosing container is closed by closer
+func (r *repairer) encloses(closer byte) bool {
+	open := byte('{')
+	if closer == ']' {
+		open = '['
+	}
+	for i := len(r.stack) - 2; i >= 0; i-- {
+		if r.stack[i] == open {
+			return true
+		}
+	}
+	return false
+}
 
-	for i := 0; i < len(s); i++ {
-		c := s[i]
+// member reads a key and its value, reporting whether both were there
+func (r *repairer) member() bool {
+	start := r.pos
+	var ok bool
+	if c := r.src[r.pos]; c == '"' || c == '\'' {
+		ok = r.quoted(c, ctxKey)
+	} else if c != '{' && c != '[' {
+		ok = r.word(ctxKey)
+	}
+	if !ok {
+		return false
+	}
+	key := r.src[start:r.pos]
+	r.skipSpace()
+	colon := r.pos < len(r.src) && r.src[r.pos] == ':'
+	if colon {
+		r.pos++
+	}
+	r.out = append(r.out, ':')
+	if !r.value(ctxObjectValue) {
+		r.note(start, RepairDataDropped, "dropped key %s without a value", key)
+		return false
+	}
+	if !colon {
+		r.note(start, RepairColon, "inserted missing colon")
+	}
+	return true
+}
 
-		if escape {
-			if c == '\'' {
-				result += "'"
-			} else {
-				result += string(c)
+// quoted reads a string opened by quote. A quote inside the string only closes it when
+// what follows could come after a string, so unescaped quotes are kept as text. If the
+// input then ends inside the string, it closes at the first such quote instead.
+func (r *repairer) quoted(quote byte, ctx valueContext) bool {
+	if quote == '\'' {
+		r.note(r.pos, RepairQuotes, "replaced single quotes")
+	}
+	opening := r.pos
+	r.pos++
+	start := len(r.out)
+	r.out = append(r.out, '"')
+	inner, innerOut, innerNotes := -1, 0, 0 // the first quote kept as text
+	for r.pos < len(r.src) {
+		c := r.src[r.pos]
+		switch {
+		case c == '\\':
+			r.escape()
+		case c == quote:
+			if r.closesString(ctx) {
+				r.pos++
+				r.out = append(r.out, '"')
+				return true
 			}
-			escape = false
-			continue
+			if ctx == ctxObjectValue && r.missingQuoteBeforeKey(quote) {
+				r.note(r.pos, RepairQuotes, "closed string missing its closing quote")
+				r.out = append(r.out, '"')
+				return true
+			}
+			if inner < 0 {
+				inner, innerOut, innerNotes = r.pos, len(r.out), r.notes()
+			}
+			r.note(r.pos, RepairQuotes, "escaped quote inside string")
+			r.pos++
+			r.appendStringByte(c)
+		default:
+			if c < 0x20 {
+				r.note(r.pos, RepairEscape, "escaped control character %q", c)
+			}
+			r.pos++
+			r.appendStringByte(c)
 		}
+	}
+	r.truncated = true
+	if inner >= 0 && !r.partial {
+		r.withdraw(innerNotes)
+		r.note(inner, RepairQuotes, "closed unterminated string at an inner quote")
+		r.out = append(r.out[:innerOut], '"')
+		r.pos = inner + 1
+		return true
+	}
+	if len(r.out) == start+1 {
+		// Cut off before any content
+		r.note(opening, RepairDataDropped, "dropped empty unterminated string")
+		r.out = r.out[:start]
+		return false
+	}
+	r.note(r.pos, RepairQuotes, "closed unterminated string")
+	r.out = append(r.out, '"')
+	return true
+}
 
-		if c == '\\' {
-			result += string(c)
-			escape = true
-			continue
+// escape copies the escape sequence at pos, keeping the backslash of unknown escapes
+// as text
+func (r *repairer) escape() {
+	if r.pos+1 >= len(r.src) {
+		r.note(r.pos, RepairEscape, "removed trailing backslash")
+		r.pos++
+		return
+	}
+	switch n := r.src[r.pos+1]; n {
+	case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+		r.out = append(r.out, '\\', n)
+		r.pos += 2
+	case '\'':
+		r.note(r.pos, RepairEscape, "unescaped single quote")
+		r.out = append(r.out, '\'')
+		r.pos += 2
+	case 'u':
+		if r.pos+6 <= len(r.src) && isHex(r.src[r.pos+2:r.pos+6]) {
+			r.out = append(r.out, r.src[r.pos:r.pos+6]...)
+			r.pos += 6
+			return
 		}
+		r.note(r.pos, RepairEscape, "escaped backslash of invalid escape")
+		r.out = append(r.out, '\\', '\\')
+		r.pos++
+	default:
+		r.note(r.pos, RepairEscape, "escaped backslash of invalid escape")
+		r.out = append(r.out, '\\', '\\')
+		r.pos++
+	}
+}
 
-		if c == '"' {
-			result += string(c)
-			inString = !inString
-		} else if c == '\'' {
-			result += "\""
-			inString = !inString
-		} else {
-			result += string(c)
+// appendStringByte writes c inside a string, escaping it if needed
+func (r *repairer) appendStringByte(c byte) {
+	switch c {
+	case '"':
+		r.out = append(r.out, '\\', '"')
+	case '\n':
+		r.out = append(r.out, '\\', 'n')
+	case '\r':
+		r.out = append(r.out, '\\', 'r')
+	case '\t':
+		r.out = append(r.out, '\\', 't')
+	default:
+		if c < 0x20 {
+			r.out = append(r.out, fmt.Sprintf(`\u%04x`, c)...)
+			return
 		}
+		r.out = append(r.out, c)
 	}
+}
 
-	return result
+// closesString reports whether the quote at pos ends the string, because the next token
+// is one that can follow a string. Keys always end at a quote, which is more often
+// followed by a missing colon than part of the key.
+func (r *repairer) closesString(ctx valueContext) bool {
+	if ctx == ctxKey {
+		return true
+	}
+	i := skipJSONSpace(r.src, r.pos+1)
+	if i >= len(r.src) {
+		return true
+	}
+	switch r.src[i] {
+	case ',', '}', ']', ':', '"', '\'':
+		return true
+	case '/', '#':
+		return ctx != ctxTop
+	}
+	return false
+}
+
+// missingQuoteBeforeKey detects a value whose closing quote is missing before the next
+// member, as in {"a": "x, "b": 1}. When the text since the last comma looks like the
+// next key, the string ends before that comma and pos moves to it.
+func (r *repairer) missingQuoteBeforeKey(quote byte) bool {
+	comma := r.pos - 1
+	for comma >= 0 && r.src[comma] == ' ' {
+		comma--
+	}
+	if comma < 0 || r.src[comma] != ',' {
+		return false
+	}
+	end := strings.IndexByte(r.src[r.pos+1:], quote)
+	if end < 0 || strings.ContainsAny(r.src[r.pos+1:r.pos+1+end], "\n,{}[]") {
+		return false
+	}
+	if i := skipJSONSpace(r.src, r.pos+2+end); i >= len(r.src) || r.src[i] != ':' {
+		return false
+	}
+	r.out = r.out[:len(r.out)-(r.pos-comma)]
+	r.pos = comma
+	return true
 }
 
-// fixUnquotedValues adds quotes around unquoted string values in JSON objects.
-func fixUnquotedValues(s string) string {
-	// First pass - handle boolean and null values with case insensitivity
-	// Replace TRUE/FALSE/Null with lowercase versions
-	result := boolNullRe.ReplaceAllStringFunc(s, func(match string) string {
-		matchLower := strings.ToLower(match)
-		if strings.Contains(matchLower, "true") {
-			return trueCaseRe.ReplaceAllString(match, "true")
-		} else if strings.Contains(matchLower, "false") {
-			return falseCaseRe.ReplaceAllString(match, "false")
-		} else if strings.Contains(matchLower, "null") {
-			return nullCaseRe.ReplaceAllString(match, "null")
+// word reads an unquoted key or value: a literal, a number, or text to be quoted
+func (r *repairer) word(ctx valueContext) bool {
+	start := r.pos
+	r.pos = r.wordEnd(r.pos, ctx)
+	word := r.src[start:r.pos]
+	if word == "" {
+		return false
+	}
+	if ctx == ctxKey {
+		r.note(start, RepairQuotes, "quoted key %s", word)
+		r.appendString(word)
+		return true
+	}
+	if strings.Trim(word, ".") == "" {
+		// An ellipsis standing in for omitted items
+		r.note(start, RepairDataDropped, "removed ellipsis")
+		return false
+	}
+	if r.partial && r.pos == len(r.src) && isIncompleteWord(word) {
+		return false
+	}
+	if literal, ok := jsonLiteral(word); ok {
+		if literal != word {
+			r.note(start, RepairLiteral, "replaced %s with %s", word, literal)
 		}
-		return match
-	})
-
-	process := func(match string) string {
-		matchLower := strings.ToLower(match)
-		if strings.Contains(matchLower, ": true") ||
-			strings.Contains(matchLower, ": false") ||
-			strings.Contains(matchLower, ": null") {
-			return match
+		r.out = append(r.out, literal...)
+		return true
+	}
+	if number, ok := jsonNumber(word); ok {
+		if number != word {
+			r.note(start, RepairLiteral, "replaced %s with %s", word, number)
 		}
+		r.out = append(r.out, number...)
+		return true
+	}
 
-		parts := unquotedValueRe.FindStringSubmatch(match)
-		if len(parts) >= 4 {
-			return parts[1] + "\"" + parts[2] + "\"" + parts[3]
+	// Text runs on across spaces, so John Smith is one value, until a separator
+	for {
+		i := r.pos
+		for i < len(r.src) && (r.src[i] == ' ' || r.src[i] == '\t') {
+			i++
 		}
+		if i == r.pos || i >= len(r.src) || isWordDelimiter(r.src, i, ctx) || startsComment(r.src, i) {
+			break
+		}
+		r.pos = r.wordEnd(i, ctx)
+	}
+	r.note(start, RepairQuotes, "quoted %s", r.src[start:r.pos])
+	r.appendString(r.src[start:r.pos])
+	return true
+}
 
-		return match
+// wordEnd returns where the unquoted word starting at i ends
+func (r *repairer) wordEnd(i int, ctx valueContext) int {
+	for i < len(r.src) && !isJSONSpace(r.src[i]) && !isWordDelimiter(r.src, i, ctx) {
+		i++
 	}
+	return i
+}
 
-	result = unquotedValueRe.ReplaceAllStringFunc(result, process)
+// isWordDelimiter reports whether the byte at i ends an unquoted word. A colon only ends
+// a value when a space follows it, so times and URLs stay whole.
+func isWordDelimiter(s string, i int, ctx valueContext) bool {
+	switch s[i] {
+	case ',', '{', '}', '[', ']', '"', '\'':
+		return true
+	case ':':
+		return ctx == ctxKey || i+1 >= len(s) || isJSONSpace(s[i+1])
+	}
+	return false
+}
 
-	result = unquotedValueEndRe.ReplaceAllString(result, ": \"$1\"")
+// appendString writes s as a JSON string
+func (r *repairer) appendString(s string) {
+	r.out = append(r.out, '"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			r.out = append(r.out, '\\', '\\')
+			continue
+		}
+		r.appendStringByte(s[i])
+	}
+	r.out = append(r.out, '"')
+}
 
-	return result
+// skipSpace skips whitespace and comments
+func (r *repairer) skipSpace() {
+	for r.pos < len(r.src) {
+		switch {
+		case isJSONSpace(r.src[r.pos]):
+			r.pos++
+		case r.src[r.pos] == '#' || strings.HasPrefix(r.src[r.pos:], "//"):
+			r.note(r.pos, RepairComment, "removed comment")
+			end := strings.IndexByte(r.src[r.pos:], '\n')
+			if end < 0 {
+				r.pos = len(r.src)
+				return
+			}
+			r.pos += end + 1
+		case strings.HasPrefix(r.src[r.pos:], "/*"):
+			r.note(r.pos, RepairComment, "removed comment")
+			end := strings.Index(r.src[r.pos+2:], "*/")
+			if end < 0 {
+				r.pos = len(r.src)
+				return
+			}
+			r.pos += end + 4
+		default:
+			return
+		}
+	}
 }
 
-// fixUnquotedKeys adds quotes around keys in JSON objects that are missing them.
-func fixUnquotedKeys(s string) string {
-	return unquotedKeyRe.ReplaceAllString(s, `${1}"${2}"${3}`)
+func startsComment(s string, i int) bool {
+	return s[i] == '#' || strings.HasPrefix(s[i:], "//") || strings.HasPrefix(s[i:], "/*")
 }
 
-// removeTrailingCommas removes trailing commas in arrays and objects.
-func removeTrailingCommas(s string) string {
-	s = trailingCommaBraceRe.ReplaceAllString(s, "}")
-	return trailingCommaBracketRe.ReplaceAllString(s, "]")
+// jsonLiteral returns the JSON literal an unquoted word spells in any case
+func jsonLiteral(word string) (string, bool) {
+	switch strings.ToLower(word) {
+	case "true":
+		return "true", true
+	case "false":
+		return "false", true
+	case "null", "none", "nil", "undefined":
+		return "null", true
+	}
+	return "", false
 }
 
-// balanceBrackets ensures all brackets and braces are properly balanced.
-func balanceBrackets(s string) string {
-	stack := make([]rune, 0)
-	result := s
-
-	for _, c := range s {
-		if c == '{' || c == '[' {
-			stack = append(stack, c)
-		} else if c == '}' && len(stack) > 0 && stack[len(stack)-1] == '{' {
-			stack = stack[:len(stack)-1]
-		} else if c == ']' && len(stack) > 0 && stack[len(stack)-1] == '[' {
-			stack = stack[:len(stack)-1]
+// isIncompleteWord reports whether a word cut off by the end of the input may be the
+// start of a literal or a longer number, as in tr or 1e
+func isIncompleteWord(word string) bool {
+	lower := strings.ToLower(word)
+	for _, literal := range []string{"true", "false", "null"} {
+		if lower != literal && strings.HasPrefix(literal, lower) {
+			return true
 		}
 	}
+	if strings.Trim(lower, "0123456789") == "" {
+		return false
+	}
+	_, ok := jsonNumber(word)
+	return !ok && strings.Trim(lower, "0123456789+-.e") == ""
+}
 
-	for i := len(stack) - 1; i >= 0; i-- {
-		if stack[i] == '{' {
-			result += "}"
-		} else if stack[i] == '[' {
-			result += "]"
+// jsonNumber returns the JSON form of a number with an explicit plus sign, or a missing
+// leading or trailing digit, as in +1, .5, and 2.
+func jsonNumber(word string) (string, bool) {
+	negative := strings.HasPrefix(word, "-")
+	number := strings.TrimPrefix(strings.TrimPrefix(word, "-"), "+")
+	if strings.HasPrefix(number, ".") {
+		number = "0" + number
+	}
+	number = strings.TrimSuffix(number, ".")
+	if negative {
+		number = "-" + number
+	}
+	if number == "" || !(number[0] == '-' || number[0] >= '0' && number[0] <= '9') || !json.Valid([]byte(number)) {
+		return "", false
+	}
+	return number, true
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
 		}
 	}
+	return true
+}
+
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
 
-	return result
+func skipJSONSpace(s string, i int) int {
+	for i < len(s) && isJSONSpace(s[i]) {
+		i++
+	}
+	return i
 }
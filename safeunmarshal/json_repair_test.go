@@ -14,6 +14,7 @@ import (
 	"errors"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -156,7 +157,7 @@ func Test_repairJSON(t *testing.T) {
 	}
 }
 
-func Test_replaceQuotes(t *testing.T) {
+func Test_repairJSON_Quotes(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    string
@@ -164,37 +165,42 @@ func Test_replaceQuotes(t *testing.T) {
 	}{
 		{
 			name:     "No quotes to replace",
-			input:    `"name":"John"`,
-			expected: `"name":"John"`,
+			input:    `{"name":"John"}`,
+			expected: `{"name":"John"}`,
 		},
 		{
 			name:     "Replace single quotes",
-			input:    `'name':'John'`,
-			expected: `"name":"John"`,
+			input:    `{'name':'John'}`,
+			expected: `{"name":"John"}`,
 		},
 		{
 			name:     "Mixed quotes",
-			input:    `'name':"John"`,
-			expected: `"name":"John"`,
+			input:    `{'name':"John"}`,
+			expected: `{"name":"John"}`,
 		},
 		{
 			name:     "Escaped quotes",
-			input:    `'It\\'s a test'`,
-			expected: `"It\\"s a test"`,
+			input:    `['It\'s a test']`,
+			expected: `["It's a test"]`,
+		},
+		{
+			name:     "Unescaped apostrophe",
+			input:    `{'note': 'it's fine'}`,
+			expected: `{"note":"it's fine"}`,
 		},
 	}
 
 	for i, tt := range tests {
 		t.Run(tt.name+"_"+strconv.Itoa(i+1), func(t *testing.T) {
-			result := replaceQuotes(tt.input)
-			if result != tt.expected {
-				t.Errorf("replaceQuotes() = %v, want %v", result, tt.expected)
+			result, err := repairJSON(tt.input)
+			if err != nil || !jsonEqual(result, tt.expected) {
+				t.Errorf("repairJSON() = %v, %v, want %v", result, err, tt.expected)
 			}
 		})
 	}
 }
 
-func Test_fixUnquotedKeys(t *testing.T) {
+func Test_repairJSON_UnquotedKeys(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    string
@@ -224,15 +230,15 @@ func Test_fixUnquotedKeys(t *testing.T) {
 
 	for i, tt := range tests {
 		t.Run(tt.name+"_"+strconv.Itoa(i+1), func(t *testing.T) {
-			result := fixUnquotedKeys(tt.input)
-			if result != tt.expected {
-				t.Errorf("fixUnquotedKeys() = %v, want %v", result, tt.expected)
+			result, err := repairJSON(tt.input)
+			if err != nil || !jsonEqual(result, tt.expected) {
+				t.Errorf("repairJSON() = %v, %v, want %v", result, err, tt.expected)
 			}
 		})
 	}
 }
 
-func Test_removeTrailingCommas(t *testing.T) {
+func Test_repairJSON_TrailingCommas(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    string
@@ -262,15 +268,15 @@ func Test_removeTrailingCommas(t *testing.T) {
 
 	for i, tt := range tests {
 		t.Run(tt.name+"_"+strconv.Itoa(i+1), func(t *testing.T) {
-			result := removeTrailingCommas(tt.input)
-			if result != tt.expected {
-				t.Errorf("removeTrailingCommas() = %v, want %v", result, tt.expected)
+			result, err := repairJSON(tt.input)
+			if err != nil || !jsonEqual(result, tt.expected) {
+				t.Errorf("repairJSON() = %v, %v, want %v", result, err, tt.expected)
 			}
 		})
 	}
 }
 
-func Test_balanceBrackets(t *testing.T) {
+func Test_repairJSON_BalanceBrackets(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    string
@@ -300,9 +306,9 @@ func Test_balanceBrackets(t *testing.T) {
 
 	for i, tt := range tests {
 		t.Run(tt.name+"_"+strconv.Itoa(i+1), func(t *testing.T) {
-			result := balanceBrackets(tt.input)
-			if result != tt.expected {
-				t.Errorf("balanceBrackets() = %v, want %v", result, tt.expected)
+			result, err := repairJSON(tt.input)
+			if err != nil || !jsonEqual(result, tt.expected) {
+				t.Errorf("repairJSON() = %v, %v, want %v", result, err, tt.expected)
 			}
 		})
 	}
@@ -680,3 +686,157 @@ func TestTo(t *testing.T) {
 		})
 	}
 }
+
+func Test_repairJSON_PreservesStringContents(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Apostrophe inside double-quoted string",
+			input:    `{note: "it's fine", 'x': 1,}`,
+			expected: `{"note": "it's fine", "x": 1}`,
+		},
+		{
+			name:     "Key-like text inside string",
+			input:    `{"text": "{a: 1, b: 2,}"}`,
+			expected: `{"text": "{a: 1, b: 2,}"}`,
+		},
+		{
+			name:     "Double quote inside single-quoted string",
+			input:    `{'quote': 'say "hi"'}`,
+			expected: `{"quote": "say \"hi\""}`,
+		},
+		{
+			name:     "Unterminated string is closed",
+			input:    `{"name": "Jo`,
+			expected: `{"name": "Jo"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := repairJSON(tt.input)
+			if err != nil || !jsonEqual(result, tt.expected) {
+				t.Errorf("repairJSON() = %v, %v, want %v", result, err, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_repairJSON_PreservesData(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Comments",
+			input:    "{\n  // the user\n  \"name\": \"Ada\", /* born */ \"year\": 1815 # approx\n}",
+			expected: `{"name":"Ada","year":1815}`,
+		},
+		{
+			name:     "Missing comma between members",
+			input:    `{"a": 1 "b": 2}`,
+			expected: `{"a":1,"b":2}`,
+		},
+		{
+			name:     "Missing colon",
+			input:    `{"a" 1, "b": 2}`,
+			expected: `{"a":1,"b":2}`,
+		},
+		{
+			name:     "Unescaped quotes inside a string",
+			input:    `{"msg": "He said "hi" to me", "n": 1}`,
+			expected: `{"msg":"He said \"hi\" to me","n":1}`,
+		},
+		{
+			name:     "Missing closing quote before the next key",
+			input:    `{"a": "hello, "b": 1}`,
+			expected: `{"a":"hello","b":1}`,
+		},
+		{
+			name:     "Line break inside a string",
+			input:    "{\"poem\": \"line one\nline two\"}",
+			expected: `{"poem":"line one\nline two"}`,
+		},
+		{
+			name:     "Unquoted values with spaces, colons, and slashes",
+			input:    `{"name": John Smith, "url": http://example.com/x, "time": 12:30}`,
+			expected: `{"name":"John Smith","url":"http://example.com/x","time":"12:30"}`,
+		},
+		{
+			name:     "Literals and loose numbers",
+			input:    `{"a": True, "b": None, "c": +1, "d": .5, "e": 2., "f": 007}`,
+			expected: `{"a":true,"b":null,"c":1,"d":0.5,"e":2,"f":"007"}`,
+		},
+		{
+			name:     "Invalid escape",
+			input:    `{"u": "caf\u00e9 \uZZ", 'x': 1}`,
+			expected: `{"u":"café \\uZZ","x":1}`,
+		},
+		{
+			name:     "Missing commas in an array",
+			input:    `[1 2 3]`,
+			expected: `[1,2,3]`,
+		},
+		{
+			name:     "Truncated inside nested structures",
+			input:    `{"a": [1, {"b": 2, "c": "thr`,
+			expected: `{"a":[1,{"b":2,"c":"thr"}]}`,
+		},
+		{
+			name:     "Truncated key is dropped",
+			input:    `{"a": 1, "b`,
+			expected: `{"a":1}`,
+		},
+		{
+			name:     "Text after the value",
+			input:    `{"a": 1,} and that's all`,
+			expected: `{"a":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := repairJSON(tt.input)
+			if err != nil || !jsonEqual(result, tt.expected) {
+				t.Errorf("repairJSON() = %v, %v, want %v", result, err, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_repairJSON_DeepNesting(t *testing.T) {
+	_, err := repairJSON(strings.Repeat("[", maxRepairDepth+1))
+	if !errors.Is(err, ErrJSONRepairFailed) {
+		t.Errorf("expected ErrJSONRepairFailed, got %v", err)
+	}
+}
+
+// largeMalformedJSON builds a malformed payload of roughly size bytes
+func largeMalformedJSON(size int) string {
+	var b strings.Builder
+	b.WriteString("{items: [")
+	for i := 0; b.Len() < size; i++ {
+		b.WriteString(`{'name': 'item `)
+		b.WriteString(strconv.Itoa(i))
+		b.WriteString(`', id: `)
+		b.WriteString(strconv.Itoa(i))
+		b.WriteString(`, tags: ['a', 'b',],}, `)
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+func BenchmarkRepairJSON_4MB(b *testing.B) {
+	input := largeMalformedJSON(4 << 20)
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repairJSON(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
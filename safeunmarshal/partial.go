@@ -0,0 +1,84 @@
+package safeunmarshal
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// PartialDecoder decodes JSON as it streams in, such as tool arguments or structured
+// output generated token by token. After each chunk it yields the best-effort value of
+// everything received so far: open strings, arrays, and objects are closed, and a
+// trailing literal or number that may still be growing is left out. Prose and Markdown
+// fences before the JSON are skipped.
+//
+// Each Feed repairs the whole input again, so it suits outputs of up to a few hundred
+// kilobytes. A PartialDecoder is not safe for concurrent use.
+//
+// Usage:
+//
+//	dec := safeunmarshal.NewPartialDecoder[Report]()
+//	for chunk := range chunks {
+//	    report, err := dec.Feed(chunk)
+//	    if err == nil {
+//	        render(report)
+//	    }
+//	}
+//	if !dec.Complete() {
+//	    // the stream ended early
+//	}
+type PartialDecoder[T any] struct {
+	// MaxInputSize is the maximum number of bytes buffered. Set to 0 for no limit.
+	// Default is 10MB.
+	MaxInputSize int
+
+	buf      []byte
+	last     T
+	complete bool
+}
+
+// NewPartialDecoder creates a decoder of streamed JSON into values of type T
+func NewPartialDecoder[T any]() *PartialDecoder[T] {
+	return &PartialDecoder[T]{MaxInputSize: DefaultMaxInputSize}
+}
+
+// Feed appends chunk to the input and returns the value of everything received so far.
+// Until any JSON arrives, it returns the zero value. When the input does not decode into
+// T, such as a string arriving for a number field, it returns the last value that did,
+// with the error.
+func (d *PartialDecoder[T]) Feed(chunk []byte) (T, error) {
+	if d.MaxInputSize > 0 && len(d.buf)+len(chunk) > d.MaxInputSize {
+		return d.last, fmt.Errorf("input size %d exceeds maximum allowed size %d", len(d.buf)+len(chunk), d.MaxInputSize)
+	}
+	d.buf = append(d.buf, chunk...)
+
+	repaired, complete, err := repairPartialJSON(string(d.buf))
+	if err != nil {
+		return d.last, err
+	}
+	if repaired == "" {
+		return d.last, nil
+	}
+	var v T
+	if err := json.Unmarshal([]byte(repaired), &v); err != nil {
+		return d.last, fmt.Errorf("failed to parse partial JSON: %w", err)
+	}
+	d.last, d.complete = v, complete
+	return v, nil
+}
+
+// Value returns the last value Feed decoded
+func (d *PartialDecoder[T]) Value() T {
+	return d.last
+}
+
+// Complete reports whether the input received so far holds a whole JSON document, so
+// further chunks cannot change the value
+func (d *PartialDecoder[T]) Complete() bool {
+	return d.complete
+}
+
+// Reset discards the input, to decode another stream
+func (d *PartialDecoder[T]) Reset() {
+	var zero T
+	d.buf, d.last, d.complete = d.buf[:0], zero, false
+}
@@ -0,0 +1,87 @@
+package safeunmarshal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPartialDecoder(t *testing.T) {
+	type Step struct {
+		Title string `json:"title"`
+		Done  bool   `json:"done"`
+	}
+	type Plan struct {
+		Goal  string  `json:"goal"`
+		Steps []Step  `json:"steps"`
+		Score float64 `json:"score"`
+	}
+
+	dec := NewPartialDecoder[Plan]()
+	steps := []struct {
+		chunk string
+		want  Plan
+	}{
+		{"Here is the plan:\n```json\n", Plan{}},
+		{`{"goal": "Ship`, Plan{Goal: "Ship"}},
+		{` it", "steps": [{"title": "Wri`, Plan{Goal: "Ship it", Steps: []Step{{Title: "Wri"}}}},
+		{`te tests", "done": tr`, Plan{Goal: "Ship it", Steps: []Step{{Title: "Write tests"}}}},
+		{`ue}, {"ti`, Plan{Goal: "Ship it", Steps: []Step{{Title: "Write tests", Done: true}, {}}}},
+		{`tle": "Release"}], "score": 0.`, Plan{Goal: "Ship it", Steps: []Step{{Title: "Write tests", Done: true}, {Title: "Release"}}}},
+		{`75}`, Plan{Goal: "Ship it", Steps: []Step{{Title: "Write tests", Done: true}, {Title: "Release"}}, Score: 0.75}},
+	}
+	for i, step := range steps {
+		got, err := dec.Feed([]byte(step.chunk))
+		if err != nil {
+			t.Fatalf("chunk %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, step.want) {
+			t.Errorf("chunk %d: got %+v, want %+v", i, got, step.want)
+		}
+		if complete := i == len(steps)-1; dec.Complete() != complete {
+			t.Errorf("chunk %d: Complete() = %v", i, dec.Complete())
+		}
+	}
+
+	// A closing fence after the document changes nothing
+	if got, err := dec.Feed([]byte("\n```")); err != nil || got.Score != 0.75 || !dec.Complete() {
+		t.Errorf("after the fence: %+v, %v", got, err)
+	}
+
+	dec.Reset()
+	if _, err := dec.Feed([]byte(`{"goal": "A", "score": "hi`)); err == nil {
+		t.Error("expected a string for a number field to fail")
+	}
+	if got := dec.Value(); !reflect.DeepEqual(got, Plan{}) {
+		t.Errorf("Value() = %+v, want the zero value", got)
+	}
+
+	dec = NewPartialDecoder[Plan]()
+	dec.MaxInputSize = 10
+	if _, err := dec.Feed([]byte(strings.Repeat(" ", 11))); err == nil {
+		t.Error("expected input over MaxInputSize to fail")
+	}
+}
+
+func Test_repairPartialJSON(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		complete bool
+	}{
+		{`no json yet`, ``, false},
+		{`{"a": "He said "hi`, `{"a":"He said \"hi"}`, false},
+		{`{"a": -`, `{}`, false},
+		{`{"a": 1e`, `{}`, false},
+		{`{"a": 12`, `{"a":12}`, false},
+		{`[nu`, `[]`, false},
+		{`[null`, `[null]`, false},
+		{`{"a": [1, 2]}`, `{"a":[1,2]}`, true},
+	}
+	for _, tt := range tests {
+		repaired, complete, err := repairPartialJSON(tt.input)
+		if err != nil || repaired != tt.expected || complete != tt.complete {
+			t.Errorf("repairPartialJSON(%q) = %q, %v, %v, want %q, %v", tt.input, repaired, complete, err, tt.expected, tt.complete)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package safeunmarshal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RepairKind classifies a change made while repairing malformed JSON
+type RepairKind string
+
+const (
+	// RepairQuotes marks a string that was quoted, requoted, or closed, or a quote inside
+	// one that was escaped
+	RepairQuotes RepairKind = "quotes"
+
+	// RepairEscape marks an invalid escape or a raw control character inside a string
+	RepairEscape RepairKind = "escape"
+
+	// RepairMissingComma marks a comma inserted between items
+	RepairMissingComma RepairKind = "missing_comma"
+
+	// RepairTrailingComma marks a trailing or extra comma, or a stray colon, that was removed
+	RepairTrailingComma RepairKind = "trailing_comma"
+
+	// RepairColon marks a colon inserted between a key and its value
+	RepairColon RepairKind = "colon"
+
+	// RepairBrackets marks a bracket or brace that was added, removed, or matched to balance
+	// the document
+	RepairBrackets RepairKind = "brackets"
+
+	// RepairComment marks a comment that was removed
+	RepairComment RepairKind = "comment"
+
+	// RepairLiteral marks a literal or number rewritten to JSON, such as True to true or
+	// .5 to 0.5
+	RepairLiteral RepairKind = "literal"
+
+	// RepairDataDropped marks content that was discarded, such as a key without a value,
+	// an ellipsis, or an unexpected character
+	RepairDataDropped RepairKind = "data_dropped"
+)
+
+// Repair is one change made while repairing malformed JSON
+type Repair struct {
+	Offset int        `json:"offset"` // byte offset in the input
+	Kind   RepairKind `json:"kind"`
+	Detail string     `json:"detail"`
+}
+
+func (r Repair) String() string {
+	return fmt.Sprintf("%s at offset %d: %s", r.Kind, r.Offset, r.Detail)
+}
+
+// RepairReport lists the repairs made to decode a document, in input order. It is empty
+// when the input was well-formed.
+type RepairReport struct {
+	Repairs []Repair `json:"repairs"`
+}
+
+// Repaired reports whether the input needed any repair
+func (r RepairReport) Repaired() bool {
+	return len(r.Repairs) > 0
+}
+
+// Lossy reports whether any repair discarded content
+func (r RepairReport) Lossy() bool {
+	return r.Has(RepairDataDropped)
+}
+
+// Has reports whether any repair is of the kind
+func (r RepairReport) Has(kind RepairKind) bool {
+	for _, repair := range r.Repairs {
+		if repair.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func (r RepairReport) String() string {
+	if len(r.Repairs) == 0 {
+		return "no repairs"
+	}
+	lines := make([]string, len(r.Repairs))
+	for i, repair := range r.Repairs {
+		lines[i] = repair.String()
+	}
+	return strings.Join(lines, "\n")
+}
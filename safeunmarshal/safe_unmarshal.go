@@ -5,7 +5,7 @@ package safeunmarshal
 
 import (
 	"bytes"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 )
@@ -24,10 +24,47 @@ type UnmarshalOptions struct {
 	// EnableRepair enables automatic JSON repair for malformed input.
 	// When false, only well-formed JSON will be accepted. Default is true for backwards compatibility.
 	EnableRepair bool
+
+	// AllowLossyRepair accepts repairs that discard content, such as a key without a value
+	// or input with no JSON in it. When false, such input fails with ErrJSONRepairFailed
+	// instead. DefaultOptions sets it for backwards compatibility.
+	AllowLossyRepair bool
+
+	// The JSON5 syntax models commonly emit is accepted, even without repair, when allowed
+	// here. JSON5Options allows all of it. Prose before the first object or array is left
+	// alone.
+
+	// AllowComments removes // line and /* */ block comments outside strings
+	AllowComments bool
+
+	// NonFinite decides how NaN, Infinity, and -Infinity decode. The default,
+	// NonFiniteReject, fails on them.
+	NonFinite NonFiniteMode
+
+	// AllowHexNumbers accepts hexadecimal integers, such as 0x1F
+	AllowHexNumbers bool
+
+	// AllowNewlinesInStrings accepts unescaped line breaks in strings, keeping them as \n
+	// and \r rather than dropping them
+	AllowNewlinesInStrings bool
+
+	// DuplicateKeys decides how an object that repeats a key decodes. The default,
+	// DuplicateKeysLastWins, keeps the last value, as encoding/json does.
+	DuplicateKeys DuplicateKeyPolicy
 }
 
 // DefaultOptions returns the default unmarshalling options.
 func DefaultOptions() UnmarshalOptions {
+	return UnmarshalOptions{
+		MaxInputSize:     DefaultMaxInputSize,
+		EnableRepair:     true,
+		AllowLossyRepair: true,
+	}
+}
+
+// SafeRepairOptions returns options that repair malformed JSON, but fail rather than
+// discard any of its content.
+func SafeRepairOptions() UnmarshalOptions {
 	return UnmarshalOptions{
 		MaxInputSize: DefaultMaxInputSize,
 		EnableRepair: true,
@@ -42,6 +79,18 @@ func StrictOptions() UnmarshalOptions {
 	}
 }
 
+// JSON5Options returns strict options that also accept the JSON5 syntax models commonly
+// emit: comments, NaN and Infinity (as null), hex numbers, and line breaks in strings.
+func JSON5Options() UnmarshalOptions {
+	return UnmarshalOptions{
+		MaxInputSize:           DefaultMaxInputSize,
+		AllowComments:          true,
+		NonFinite:              NonFiniteNull,
+		AllowHexNumbers:        true,
+		AllowNewlinesInStrings: true,
+	}
+}
+
 // ToLenient attempts to unmarshal a JSON byte slice into a value of type T.
 // This is the lenient version that enables JSON repair by default.
 //
@@ -120,50 +169,134 @@ func To[T any](raw []byte) (T, error) {
 //	}
 //	result, err := safeunmarshal.ToWithOptions[MyStruct](jsonData, opts)
 func ToWithOptions[T any](raw []byte, opts UnmarshalOptions) (T, error) {
-	var zero T // original zero value to return in case of error
+	var response T
+	if err := intoWithOptions(raw, &response, opts, nil); err != nil {
+		var zero T // original zero value to return in case of error
+		return zero, err
+	}
+	return response, nil
+}
 
+// ToWithReport is ToWithOptions, also returning a report of every repair applied, with
+// its offset in raw and its kind. Callers can log the repairs, or refuse aggressive ones
+// instead of silently accepting the result. The report is returned even when
+// unmarshalling fails, and is empty when raw needed no repair.
+//
+// Usage:
+//
+//	result, report, err := safeunmarshal.ToWithReport[MyStruct](jsonData, safeunmarshal.DefaultOptions())
+//	if err == nil && report.Lossy() {
+//	    // Content was dropped to make the JSON parse
+//	    logger.Warn("refusing lossy JSON repair", "repairs", report.String())
+//	}
+func ToWithReport[T any](raw []byte, opts UnmarshalOptions) (T, RepairReport, error) {
+	var response T
+	var report RepairReport
+	if err := intoWithOptions(raw, &response, opts, &report); err != nil {
+		var zero T
+		return zero, report, err
+	}
+	return response, report, nil
+}
+
+// Into is the non-generic form of To, for callers that only know the target type at run
+// time. v must be a non-nil pointer.
+//
+// Usage:
+//
+//	input := reflect.New(inputType)
+//	err := safeunmarshal.Into(jsonData, input.Interface())
+func Into(raw []byte, v interface{}) error {
+	return intoWithOptions(raw, v, StrictOptions(), nil)
+}
+
+// IntoWithOptions is the non-generic form of ToWithOptions. v must be a non-nil pointer.
+func IntoWithOptions(raw []byte, v interface{}, opts UnmarshalOptions) error {
+	return intoWithOptions(raw, v, opts, nil)
+}
+
+// intoWithOptions unmarshals raw into the value v points to, adding any repairs to
+// report if it is not nil
+func intoWithOptions(raw []byte, v interface{}, opts UnmarshalOptions, report *RepairReport) error {
 	// Check input size limit
 	if opts.MaxInputSize > 0 && len(raw) > opts.MaxInputSize {
-		return zero, fmt.Errorf("input size %d exceeds maximum allowed size %d", len(raw), opts.MaxInputSize)
+		return fmt.Errorf("input size %d exceeds maximum allowed size %d", len(raw), opts.MaxInputSize)
+	}
+
+	var shifts []offsetShift
+	if opts.usesJSON5() {
+		raw, shifts = normalizeJSON5(raw, opts)
+	}
+
+	// Repair reads from the first object or array to the end, rather than the extracted
+	// JSON, so truncated input is kept and line breaks still end comments
+	var source []byte
+	start := bytes.IndexAny(raw, "{[")
+	if start >= 0 {
+		source = bytes.TrimSpace(raw[start:])
 	}
 
 	data := prepareJSONForUnmarshalling(raw)
+	if len(data) == 0 && opts.EnableRepair {
+		data = source
+	}
 	data = bytes.ReplaceAll(data, []byte("\n"), []byte(""))
 
 	if len(data) == 0 {
-		return zero, fmt.Errorf("empty input string")
+		return fmt.Errorf("empty input string")
 	}
 
-	var response T
-	err := json.Unmarshal(data, &response)
+	err := unmarshalWithPolicy(data, v, opts.DuplicateKeys)
+	if errors.Is(err, ErrDuplicateKey) {
+		return err
+	}
 	if err != nil {
-		valueType := reflect.TypeOf((*T)(nil)).Elem()
+		valueType := reflect.TypeOf(v).Elem()
 		isArray := valueType.Kind() == reflect.Array || valueType.Kind() == reflect.Slice
 
 		if isArray && !isJSONArray(data) {
-			return zero, fmt.Errorf("%w: got %s", ErrExpectedJSONArray, data)
+			return fmt.Errorf("%w: got %s", ErrExpectedJSONArray, data)
 		}
 
 		// Only attempt repair if enabled in options
 		if !opts.EnableRepair {
-			return zero, fmt.Errorf("failed to parse JSON: %w", err)
+			return fmt.Errorf("failed to parse JSON: %w", err)
 		}
 
-		repairedData, repairErr := repairJSON(string(data))
+		if report == nil && !opts.AllowLossyRepair {
+			report = &RepairReport{}
+		}
+		earlier := 0
+		if report != nil {
+			earlier = len(report.Repairs)
+		}
+		repairedData, repairErr := repairJSONWithReport(string(source), start, report)
+		if report != nil && shifts != nil {
+			for i := earlier; i < len(report.Repairs); i++ {
+				report.Repairs[i].Offset = inputOffset(shifts, report.Repairs[i].Offset)
+			}
+		}
 		if repairErr != nil {
-			return zero, fmt.Errorf("failed to repair JSON: %w", repairErr)
+			return fmt.Errorf("failed to repair JSON: %w", repairErr)
+		}
+		if !opts.AllowLossyRepair {
+			for _, repair := range report.Repairs[earlier:] {
+				if repair.Kind == RepairDataDropped {
+					return fmt.Errorf("%w: repair would discard content (%s)", ErrJSONRepairFailed, repair)
+				}
+			}
 		}
 
 		if repairedData == "" {
-			return zero, fmt.Errorf("JSON repair resulted in empty string")
+			return fmt.Errorf("JSON repair resulted in empty string")
 		}
 
-		err = json.Unmarshal([]byte(repairedData), &response)
+		err = unmarshalWithPolicy([]byte(repairedData), v, opts.DuplicateKeys)
 		if err != nil {
-			return zero, fmt.Errorf("failed to parse repaired JSON: %w", err)
+			return fmt.Errorf("failed to parse repaired JSON: %w", err)
 		}
 	}
-	return response, nil
+	return nil
 }
 
 // isJSONArray checks if the input byte slice represents a JSON array.
@@ -2,6 +2,7 @@ package safeunmarshal
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 )
 
@@ -321,6 +322,16 @@ func TestTo_PrepareJSON(t *testing.T) {
 			input: []byte(`[{"value":"arr1"},{"value":"arr2"}]`),
 			want:  "arr1", // First element
 		},
+		{
+			name:  "truncated json with prefix text",
+			input: []byte(`Sure! {"value": "partial`),
+			want:  "partial",
+		},
+		{
+			name:  "json with comments",
+			input: []byte("{\n  // the answer\n  \"value\": \"commented\"\n}"),
+			want:  "commented",
+		},
 	}
 
 	for _, tt := range tests {
@@ -390,3 +401,81 @@ func TestIsJSONArray(t *testing.T) {
 		})
 	}
 }
+
+func TestInto(t *testing.T) {
+	var v struct {
+		Name string `json:"name"`
+	}
+	if err := Into([]byte(`{"name":"ada"}`), &v); err != nil || v.Name != "ada" {
+		t.Errorf("expected name to be decoded, got %+v, %v", v, err)
+	}
+
+	var list []int
+	if err := Into([]byte(`{"a":1}`), &list); !errors.Is(err, ErrExpectedJSONArray) {
+		t.Errorf("expected ErrExpectedJSONArray, got %v", err)
+	}
+}
+
+func TestToWithReport(t *testing.T) {
+	type Person struct {
+		Name  string   `json:"name"`
+		Tags  []string `json:"tags"`
+		Alive bool     `json:"alive"`
+	}
+
+	input := "Result: {name: 'Ada', // note\n \"tags\": [\"a\" \"b\",], \"x\": ..., \"alive\": False"
+	got, report, err := ToWithReport[Person]([]byte(input), DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Person{Name: "Ada", Tags: []string{"a", "b"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	want := []Repair{
+		{Offset: 9, Kind: RepairQuotes, Detail: "quoted key name"},
+		{Offset: 15, Kind: RepairQuotes, Detail: "replaced single quotes"},
+		{Offset: 22, Kind: RepairComment, Detail: "removed comment"},
+		{Offset: 44, Kind: RepairMissingComma, Detail: "inserted missing comma"},
+		{Offset: 47, Kind: RepairTrailingComma, Detail: "removed trailing comma"},
+		{Offset: 51, Kind: RepairDataDropped, Detail: `dropped key "x" without a value`},
+		{Offset: 56, Kind: RepairDataDropped, Detail: "removed ellipsis"},
+		{Offset: 70, Kind: RepairLiteral, Detail: "replaced False with false"},
+		{Offset: 75, Kind: RepairBrackets, Detail: "added missing '}'"},
+	}
+	if !reflect.DeepEqual(report.Repairs, want) {
+		t.Errorf("repairs:\n%s", report)
+	}
+	if !report.Lossy() || !report.Has(RepairComment) {
+		t.Errorf("Lossy() = %v, Has(RepairComment) = %v", report.Lossy(), report.Has(RepairComment))
+	}
+
+	_, report, err = ToWithReport[Person]([]byte(`{"name": "Ada"}`), DefaultOptions())
+	if err != nil || report.Repaired() {
+		t.Errorf("well-formed input: %v, %s", err, report)
+	}
+}
+
+func TestToWithOptions_AllowLossyRepair(t *testing.T) {
+	type Item struct {
+		Name string `json:"name"`
+		Qty  int    `json:"qty"`
+	}
+
+	// Repairs that keep all content succeed either way
+	got, err := ToWithOptions[Item]([]byte(`{'name': 'bolt', qty: 3,}`), SafeRepairOptions())
+	if err != nil || got != (Item{Name: "bolt", Qty: 3}) {
+		t.Errorf("lossless repair = %+v, %v", got, err)
+	}
+
+	for _, input := range []string{`{"name": "bolt", "qty": }`, `[{"name": "bolt"}, ...]`, `{"name": "bolt", "qty`} {
+		if _, err := ToWithOptions[Item]([]byte(input), SafeRepairOptions()); !errors.Is(err, ErrJSONRepairFailed) {
+			t.Errorf("%s: expected ErrJSONRepairFailed, got %v", input, err)
+		}
+	}
+
+	// DefaultOptions keeps accepting lossy repairs
+	got, err = ToLenient[Item]([]byte(`{"name": "bolt", "qty": }`))
+	if err != nil || got != (Item{Name: "bolt"}) {
+		t.Errorf("lenient repair = %+v, %v", got, err)
+	}
+}
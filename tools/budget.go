@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrBudgetExceeded is returned when a request has used up its sub-call or byte budget
+var ErrBudgetExceeded = errors.New("request budget exceeded")
+
+// BudgetLimits bounds the work a single top-level tool call may trigger.
+// Zero values mean unlimited.
+type BudgetLimits struct {
+	// MaxCalls caps the number of nested tool or upstream calls.
+	MaxCalls int
+
+	// MaxBytes caps the number of bytes fetched or transferred on behalf of the call.
+	MaxBytes int64
+}
+
+// Budget tracks remaining sub-calls and bytes for one request. It is carried in the
+// context so that every helper acting on behalf of the request draws from the same budget.
+type Budget struct {
+	mu        sync.Mutex
+	limits    BudgetLimits
+	usedCalls int
+	usedBytes int64
+}
+
+type budgetKey struct{}
+
+// NewBudget creates a budget with the given limits
+func NewBudget(limits BudgetLimits) *Budget {
+	return &Budget{limits: limits}
+}
+
+// WithBudget returns a context carrying the budget
+func WithBudget(ctx context.Context, b *Budget) context.Context {
+	return context.WithValue(ctx, budgetKey{}, b)
+}
+
+// BudgetFrom returns the budget carried by ctx, or nil if there is none
+func BudgetFrom(ctx context.Context) *Budget {
+	b, _ := ctx.Value(budgetKey{}).(*Budget)
+	return b
+}
+
+// SpendCall draws one sub-call from the budget in ctx. It is a no-op without a budget.
+func SpendCall(ctx context.Context) error {
+	return BudgetFrom(ctx).SpendCall()
+}
+
+// SpendBytes draws n bytes from the budget in ctx. It is a no-op without a budget.
+func SpendBytes(ctx context.Context, n int64) error {
+	return BudgetFrom(ctx).SpendBytes(n)
+}
+
+// SpendCall draws one sub-call from the budget
+func (b *Budget) SpendCall() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.limits.MaxCalls > 0 && b.usedCalls >= b.limits.MaxCalls {
+		return fmt.Errorf("%w: sub-call limit of %d reached", ErrBudgetExceeded, b.limits.MaxCalls)
+	}
+	b.usedCalls++
+	return nil
+}
+
+// SpendBytes draws n bytes from the budget
+func (b *Budget) SpendBytes(n int64) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.limits.MaxBytes > 0 && b.usedBytes+n > b.limits.MaxBytes {
+		return fmt.Errorf("%w: byte limit of %d reached (used %d, requested %d)",
+			ErrBudgetExceeded, b.limits.MaxBytes, b.usedBytes, n)
+	}
+	b.usedBytes += n
+	return nil
+}
+
+// Remaining returns the sub-calls and bytes left, or -1 for an unlimited dimension
+func (b *Budget) Remaining() (calls int, bytes int64) {
+	if b == nil {
+		return -1, -1
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	calls, bytes = -1, -1
+	if b.limits.MaxCalls > 0 {
+		calls = b.limits.MaxCalls - b.usedCalls
+	}
+	if b.limits.MaxBytes > 0 {
+		bytes = b.limits.MaxBytes - b.usedBytes
+	}
+	return calls, bytes
+}
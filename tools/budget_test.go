@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBudget_Limits(t *testing.T) {
+	ctx := WithBudget(context.Background(), NewBudget(BudgetLimits{MaxCalls: 2, MaxBytes: 100}))
+
+	for i := 0; i < 2; i++ {
+		if err := SpendCall(ctx); err != nil {
+			t.Fatalf("SpendCall %d returned error: %v", i, err)
+		}
+	}
+	if err := SpendCall(ctx); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Expected ErrBudgetExceeded for third call, got %v", err)
+	}
+
+	if err := SpendBytes(ctx, 60); err != nil {
+		t.Fatalf("SpendBytes returned error: %v", err)
+	}
+	if err := SpendBytes(ctx, 60); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Expected ErrBudgetExceeded for bytes, got %v", err)
+	}
+
+	calls, bytes := BudgetFrom(ctx).Remaining()
+	if calls != 0 || bytes != 40 {
+		t.Errorf("Expected 0 calls and 40 bytes remaining, got %d and %d", calls, bytes)
+	}
+}
+
+func TestBudget_NoBudgetIsUnlimited(t *testing.T) {
+	ctx := context.Background()
+	if err := SpendCall(ctx); err != nil {
+		t.Errorf("Expected no error without budget, got %v", err)
+	}
+	if err := SpendBytes(ctx, 1<<40); err != nil {
+		t.Errorf("Expected no error without budget, got %v", err)
+	}
+}
+
+func TestCall_SpendsBudget(t *testing.T) {
+	tool := NewTool("test_tool", "A test tool", testHandler)
+	ctx := WithBudget(context.Background(), NewBudget(BudgetLimits{MaxCalls: 1}))
+
+	if _, err := Call[TestInput, TestOutput](ctx, tool, TestInput{}); err != nil {
+		t.Fatalf("First Call returned error: %v", err)
+	}
+	if _, err := Call[TestInput, TestOutput](ctx, tool, TestInput{}); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Expected ErrBudgetExceeded on second Call, got %v", err)
+	}
+}
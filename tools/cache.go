@@ -0,0 +1,184 @@
+package tools
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// DefaultCacheEntries is the most results a ResultCache holds when created with a
+// non-positive size
+const DefaultCacheEntries = 1000
+
+// WithCacheable marks the tool's results as reusable for ttl, so a ResultCache returns the
+// previous result of an identical call instead of running the tool again. Use it only for
+// idempotent tools whose output depends on nothing but their arguments.
+func WithCacheable(ttl time.Duration) ToolOption {
+	return func(spec *ToolSpec) {
+		spec.CacheTTL = ttl
+	}
+}
+
+// ResultCache stores the results of cacheable tools (see WithCacheable), keyed by tool name
+// and arguments, evicting the least recently used entry when full. Failed calls are not
+// cached. It is safe for concurrent use.
+//
+// Results are shared by every caller making the same call; use PartitionBy when a tool's
+// output depends on who is calling.
+//
+// Example:
+//
+//	cache := tools.NewResultCache(500)
+//	server := mcp.NewServer(mcp.ServerConfig{Name: "my-server", Tools: myTools, ResultCache: cache})
+type ResultCache struct {
+	maxEntries int
+	partition  func(ctx context.Context) string
+	now        func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List // most recently used first
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key     string
+	result  *ToolResult
+	expires time.Time
+}
+
+// NewResultCache creates a cache holding at most maxEntries results, or
+// DefaultCacheEntries when maxEntries is not positive
+func NewResultCache(maxEntries int) *ResultCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheEntries
+	}
+	return &ResultCache{
+		maxEntries: maxEntries,
+		now:        time.Now,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// PartitionBy keeps separate results for each value fn returns, such as the caller's
+// subject from mcpctx.Principal, so one caller never sees another's result
+func (c *ResultCache) PartitionBy(fn func(ctx context.Context) string) *ResultCache {
+	c.partition = fn
+	return c
+}
+
+// Middleware returns middleware caching the results of cacheable tools. Other tools run
+// unchanged. Add it to a Toolset, or set ServerConfig.ResultCache to apply it to every
+// tool. Streaming tools do not emit chunks when their result comes from the cache.
+func (c *ResultCache) Middleware() Middleware {
+	return func(spec *ToolSpec, next Handler) Handler {
+		if spec.CacheTTL <= 0 {
+			return next
+		}
+		return func(ctx context.Context, params json.RawMessage) (*ToolResult, error) {
+			key, ok := c.key(ctx, spec.Name, params)
+			if !ok {
+				return next(ctx, params)
+			}
+			if result, hit := c.get(key); hit {
+				return result, nil
+			}
+
+			result, err := next(ctx, params)
+			if err == nil && result != nil && !result.IsError() {
+				c.put(key, result, spec.CacheTTL)
+			}
+			return result, err
+		}
+	}
+}
+
+// Len returns the number of cached results, including expired ones not yet evicted
+func (c *ResultCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Purge removes every cached result
+func (c *ResultCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.entries = make(map[string]*list.Element)
+}
+
+// key identifies a call by partition, tool name, and canonical arguments, so calls
+// differing only in key order or whitespace share an entry. Arguments that are not
+// valid JSON are not cached.
+func (c *ResultCache) key(ctx context.Context, tool string, params json.RawMessage) (string, bool) {
+	args, ok := canonicalJSON(params)
+	if !ok {
+		return "", false
+	}
+	partition := ""
+	if c.partition != nil {
+		partition = c.partition(ctx)
+	}
+	return partition + "\x00" + tool + "\x00" + args, true
+}
+
+// canonicalJSON re-encodes data with sorted object keys and no insignificant whitespace.
+// Missing arguments are treated as an empty object.
+func canonicalJSON(data json.RawMessage) (string, bool) {
+	if len(bytes.TrimSpace(data)) == 0 || string(data) == "null" {
+		return "{}", true
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil || decoder.More() {
+		return "", false
+	}
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	return string(canonical), true
+}
+
+func (c *ResultCache) get(key string) (*ToolResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	result := *entry.result
+	return &result, true
+}
+
+func (c *ResultCache) put(key string, result *ToolResult, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored := *result
+	entry := &cacheEntry{key: key, result: &stored, expires: c.now().Add(ttl)}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// countingHandler returns its call count as output, failing when fail is set
+func countingHandler(calls *int, fail *bool) Handler {
+	return func(ctx context.Context, params json.RawMessage) (*ToolResult, error) {
+		*calls++
+		if fail != nil && *fail {
+			return nil, errors.New("failed")
+		}
+		return &ToolResult{Output: *calls}, nil
+	}
+}
+
+func TestResultCache(t *testing.T) {
+	cache := NewResultCache(10)
+	var calls int
+	var fail bool
+	handler := cache.Middleware()(&ToolSpec{Name: "schema", CacheTTL: time.Minute}, countingHandler(&calls, &fail))
+	ctx := context.Background()
+
+	first, _ := handler(ctx, json.RawMessage(`{"table":"users","columns":true}`))
+	// The same arguments in another order and spacing hit the cache
+	second, _ := handler(ctx, json.RawMessage(`{ "columns": true, "table": "users" }`))
+	if calls != 1 || first.Output != 1 || second.Output != 1 {
+		t.Errorf("expected one execution, got %d calls and outputs %v, %v", calls, first.Output, second.Output)
+	}
+	if first == second {
+		t.Error("expected each hit to return its own copy of the result")
+	}
+
+	handler(ctx, json.RawMessage(`{"table":"orders"}`))
+	if calls != 2 {
+		t.Errorf("expected different arguments to execute, got %d calls", calls)
+	}
+
+	// Missing arguments and an empty object are the same call
+	handler(ctx, nil)
+	handler(ctx, json.RawMessage(`{}`))
+	if calls != 3 {
+		t.Errorf("expected empty arguments to share an entry, got %d calls", calls)
+	}
+
+	fail = true
+	handler(ctx, json.RawMessage(`{"table":"broken"}`))
+	handler(ctx, json.RawMessage(`{"table":"broken"}`))
+	if calls != 5 {
+		t.Errorf("expected failures not to be cached, got %d calls", calls)
+	}
+}
+
+func TestResultCache_NotCacheable(t *testing.T) {
+	cache := NewResultCache(10)
+	var calls int
+	handler := cache.Middleware()(&ToolSpec{Name: "now"}, countingHandler(&calls, nil))
+	handler(context.Background(), json.RawMessage(`{}`))
+	handler(context.Background(), json.RawMessage(`{}`))
+	if calls != 2 || cache.Len() != 0 {
+		t.Errorf("expected tools without a TTL to run every time, got %d calls and %d entries", calls, cache.Len())
+	}
+}
+
+func TestResultCache_Expiry(t *testing.T) {
+	now := time.Now()
+	cache := NewResultCache(10)
+	cache.now = func() time.Time { return now }
+	var calls int
+	handler := cache.Middleware()(&ToolSpec{Name: "schema", CacheTTL: time.Minute}, countingHandler(&calls, nil))
+	ctx := context.Background()
+
+	handler(ctx, nil)
+	now = now.Add(59 * time.Second)
+	handler(ctx, nil)
+	if calls != 1 {
+		t.Errorf("expected a hit within the TTL, got %d calls", calls)
+	}
+	now = now.Add(time.Second)
+	handler(ctx, nil)
+	if calls != 2 {
+		t.Errorf("expected a miss once the TTL passed, got %d calls", calls)
+	}
+}
+
+func TestResultCache_Eviction(t *testing.T) {
+	cache := NewResultCache(2)
+	var calls int
+	handler := cache.Middleware()(&ToolSpec{Name: "schema", CacheTTL: time.Minute}, countingHandler(&calls, nil))
+	ctx := context.Background()
+
+	handler(ctx, json.RawMessage(`{"n":1}`))
+	handler(ctx, json.RawMessage(`{"n":2}`))
+	handler(ctx, json.RawMessage(`{"n":1}`)) // hit, making n=2 the least recently used
+	handler(ctx, json.RawMessage(`{"n":3}`)) // evicts n=2
+	if cache.Len() != 2 || calls != 3 {
+		t.Fatalf("expected 2 entries after 3 executions, got %d entries and %d calls", cache.Len(), calls)
+	}
+
+	handler(ctx, json.RawMessage(`{"n":1}`))
+	if calls != 3 {
+		t.Errorf("expected the recently used entry to survive, got %d calls", calls)
+	}
+	handler(ctx, json.RawMessage(`{"n":2}`))
+	if calls != 4 {
+		t.Errorf("expected the least recently used entry to be evicted, got %d calls", calls)
+	}
+
+	cache.Purge()
+	if cache.Len() != 0 {
+		t.Errorf("expected an empty cache after Purge, got %d entries", cache.Len())
+	}
+}
+
+func TestResultCache_PartitionBy(t *testing.T) {
+	type userKey struct{}
+	cache := NewResultCache(10).PartitionBy(func(ctx context.Context) string {
+		user, _ := ctx.Value(userKey{}).(string)
+		return user
+	})
+	var calls int
+	handler := cache.Middleware()(&ToolSpec{Name: "profile", CacheTTL: time.Minute}, countingHandler(&calls, nil))
+
+	alice := context.WithValue(context.Background(), userKey{}, "alice")
+	bob := context.WithValue(context.Background(), userKey{}, "bob")
+	handler(alice, nil)
+	handler(bob, nil)
+	handler(alice, nil)
+	if calls != 2 {
+		t.Errorf("expected one execution per partition, got %d calls", calls)
+	}
+}
@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Call executes a tool in-process with a typed input and decodes its output into Out.
+//
+// The input is marshaled to JSON and passed to Execute exactly as a transport would.
+// If the tool returns its output as an Out value (as TypedTool does), it is returned
+// directly; otherwise the output is round-tripped through JSON into Out. A ToolResult
+// carrying an Error message is returned as an error. Each call draws one sub-call from
+// the request Budget in ctx, if any.
+//
+// Example:
+//
+//	weather, err := tools.Call[WeatherRequest, WeatherResponse](ctx, weatherTool, WeatherRequest{City: "Paris"})
+func Call[In, Out any](ctx context.Context, tool Tool, in In) (Out, error) {
+	var zero Out
+
+	if tool == nil {
+		return zero, errors.New("tool cannot be nil")
+	}
+
+	if err := SpendCall(ctx); err != nil {
+		return zero, err
+	}
+
+	params, err := json.Marshal(in)
+	if err != nil {
+		return zero, fmt.Errorf("failed to marshal input for tool %q: %w", tool.Spec().Name, err)
+	}
+
+	result, err := tool.Execute(ctx, params)
+	if err != nil {
+		return zero, err
+	}
+	if result == nil {
+		return zero, fmt.Errorf("tool %q returned no result", tool.Spec().Name)
+	}
+	if result.IsError() {
+		return zero, fmt.Errorf("tool %q failed: %w", tool.Spec().Name, result.Err())
+	}
+
+	if out, ok := result.Output.(Out); ok {
+		return out, nil
+	}
+
+	// Tools relaying another server's result, such as proxies, carry its JSON as text
+	var out Out
+	if text, ok := result.Output.(string); ok && json.Unmarshal([]byte(text), &out) == nil {
+		return out, nil
+	}
+
+	outputBytes, err := json.Marshal(result.Output)
+	if err != nil {
+		return zero, fmt.Errorf("failed to marshal output of tool %q: %w", tool.Spec().Name, err)
+	}
+
+	if err := json.Unmarshal(outputBytes, &out); err != nil {
+		return zero, fmt.Errorf("failed to decode output of tool %q into %T: %w", tool.Spec().Name, out, err)
+	}
+	return out, nil
+}
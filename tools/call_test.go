@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+type mapOutputTool struct{}
+
+func (m *mapOutputTool) Spec() *ToolSpec {
+	return &ToolSpec{Name: "map_tool", Description: "Returns a map"}
+}
+
+func (m *mapOutputTool) Execute(ctx context.Context, params json.RawMessage) (*ToolResult, error) {
+	return &ToolResult{Output: map[string]interface{}{"result": "from map", "success": true}}, nil
+}
+
+type errorResultTool struct{}
+
+func (e *errorResultTool) Spec() *ToolSpec {
+	return &ToolSpec{Name: "error_result_tool", Description: "Returns an error result"}
+}
+
+func (e *errorResultTool) Execute(ctx context.Context, params json.RawMessage) (*ToolResult, error) {
+	msg := "bad things happened"
+	return &ToolResult{Error: &msg}, nil
+}
+
+func TestCall_TypedTool(t *testing.T) {
+	tool := NewTool("test_tool", "A test tool", testHandler)
+
+	output, err := Call[TestInput, TestOutput](context.Background(), tool, TestInput{Name: "typed", Value: 1})
+	if err != nil {
+		t.Fatalf("Call returned error: %v", err)
+	}
+
+	if output.Result != "processed: typed" {
+		t.Errorf("Expected result 'processed: typed', got %q", output.Result)
+	}
+}
+
+func TestCall_DecodesUntypedOutput(t *testing.T) {
+	output, err := Call[TestInput, TestOutput](context.Background(), &mapOutputTool{}, TestInput{})
+	if err != nil {
+		t.Fatalf("Call returned error: %v", err)
+	}
+
+	if output.Result != "from map" || !output.Success {
+		t.Errorf("Unexpected decoded output: %+v", output)
+	}
+}
+
+func TestCall_Errors(t *testing.T) {
+	tool := NewTool("error_tool", "A tool that errors", errorHandler)
+	if _, err := Call[TestInput, TestOutput](context.Background(), tool, TestInput{}); err == nil {
+		t.Error("Expected handler error, got nil")
+	}
+
+	if _, err := Call[TestInput, TestOutput](context.Background(), &errorResultTool{}, TestInput{}); err == nil {
+		t.Error("Expected error from ToolResult.Error, got nil")
+	}
+
+	if _, err := Call[TestInput, TestOutput](context.Background(), nil, TestInput{}); err == nil {
+		t.Error("Expected error for nil tool, got nil")
+	}
+}
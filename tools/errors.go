@@ -1,14 +1,21 @@
 package tools
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // Error represents an error that occurred during tool execution,
 // optionally carrying an error code for the transport layer.
+//
+// Codes in the reserved JSON-RPC range (-32768 to -32000) are protocol errors, reported to
+// the client as a JSON-RPC error. Any other code, including zero, is a tool failure,
+// reported to the model as a result with isError set.
 type Error struct {
-	Code    int
-	Message string
-	Data    interface{}
-	Cause   error // The underlying error, if any
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+	Cause   error       `json:"-"` // The underlying error, if any
 }
 
 func (e *Error) Error() string {
@@ -23,6 +30,24 @@ func (e *Error) Unwrap() error {
 	return e.Cause
 }
 
+// IsProtocolError reports whether the code lies in the reserved JSON-RPC range
+func (e *Error) IsProtocolError() bool {
+	return e != nil && e.Code >= -32768 && e.Code <= -32000
+}
+
+// AsError classifies err: it returns the *Error in err's chain, or wraps err as an
+// unclassified tool failure. It returns nil for a nil err.
+func AsError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var toolErr *Error
+	if errors.As(err, &toolErr) {
+		return toolErr
+	}
+	return &Error{Message: err.Error(), Cause: err}
+}
+
 // NewError creates a new tool error
 func NewError(code int, message string) *Error {
 	return &Error{Code: code, Message: message}
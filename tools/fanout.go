@@ -0,0 +1,171 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FanOutOptions controls how FanOut runs branches
+type FanOutOptions struct {
+	// MaxParallel caps how many branches run at once. Zero or less means unbounded.
+	MaxParallel int
+
+	// BranchTimeout bounds each branch individually. Zero means no per-branch limit.
+	BranchTimeout time.Duration
+
+	// FailFast cancels the remaining branches as soon as one fails.
+	FailFast bool
+}
+
+// Branch is a named unit of work run by FanOut
+type Branch[T any] struct {
+	Name string
+	Run  func(ctx context.Context) (T, error)
+}
+
+// BranchResult is the outcome of a single branch. Exactly one of Output or Error is meaningful.
+type BranchResult[T any] struct {
+	Name   string `json:"name"`
+	Output T      `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+
+	err error
+}
+
+// Err returns the error the branch failed with, or nil
+func (r BranchResult[T]) Err() error {
+	return r.err
+}
+
+// FanOut runs branches concurrently and collects their results in the order given.
+//
+// Branch panics are recovered and reported as branch errors. Unless FailFast is set,
+// a failing branch does not affect the others, and the returned error is non-nil only
+// when every branch failed, so callers can return partial results. With FailFast the
+// first failure cancels the remaining branches and is returned.
+//
+// Example:
+//
+//	results, err := tools.FanOut(ctx, tools.FanOutOptions{MaxParallel: 4, BranchTimeout: 5 * time.Second},
+//	    tools.Branch[Quote]{Name: "AAPL", Run: fetchQuote("AAPL")},
+//	    tools.Branch[Quote]{Name: "MSFT", Run: fetchQuote("MSFT")},
+//	)
+func FanOut[T any](ctx context.Context, opts FanOutOptions, branches ...Branch[T]) ([]BranchResult[T], error) {
+	results := make([]BranchResult[T], len(branches))
+	if len(branches) == 0 {
+		return results, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var sem chan struct{}
+	if opts.MaxParallel > 0 {
+		sem = make(chan struct{}, opts.MaxParallel)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		failOnce sync.Once
+		firstErr error
+	)
+
+	for i, branch := range branches {
+		results[i].Name = branch.Name
+
+		wg.Add(1)
+		go func(i int, branch Branch[T]) {
+			defer wg.Done()
+
+			if sem != nil {
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+					results[i].err = ctx.Err()
+					results[i].Error = ctx.Err().Error()
+					return
+				}
+			}
+
+			output, err := runBranch(ctx, opts.BranchTimeout, branch)
+			if err != nil {
+				results[i].err = err
+				results[i].Error = err.Error()
+				if opts.FailFast {
+					failOnce.Do(func() {
+						firstErr = fmt.Errorf("branch %q failed: %w", branch.Name, err)
+						cancel()
+					})
+				}
+				return
+			}
+			results[i].Output = output
+		}(i, branch)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return results, firstErr
+	}
+
+	var errs []error
+	for _, r := range results {
+		if r.err == nil {
+			return results, nil
+		}
+		errs = append(errs, fmt.Errorf("branch %q: %w", r.Name, r.err))
+	}
+	return results, errors.Join(errs...)
+}
+
+// runBranch executes one branch with its optional timeout, recovering panics
+func runBranch[T any](ctx context.Context, timeout time.Duration, branch Branch[T]) (output T, err error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return branch.Run(ctx)
+}
+
+// FanOutResult wraps branch results in a ToolResult. Each branch is sent to clients as a
+// content block of its own, and System carries a summary naming the failed branches, so
+// the model knows when the output is partial.
+func FanOutResult[T any](results []BranchResult[T]) *ToolResult {
+	parts := make([]any, len(results))
+	var failed []string
+	for i, r := range results {
+		parts[i] = r
+		if r.err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%s)", r.Name, r.Error))
+		}
+	}
+
+	summary := fmt.Sprintf("All %d branches succeeded", len(results))
+	if len(failed) > 0 {
+		summary = fmt.Sprintf("%d of %d branches failed; results are partial. Failed: %s",
+			len(failed), len(results), strings.Join(failed, ", "))
+	}
+	return &ToolResult{
+		Output: results,
+		Parts:  parts,
+		System: &summary,
+		SystemInfo: &SystemInfo{
+			Message:  summary,
+			Metadata: map[string]interface{}{"failedBranches": len(failed), "totalBranches": len(results)},
+		},
+	}
+}
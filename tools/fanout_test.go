@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFanOut_PartialResults(t *testing.T) {
+	results, err := FanOut(context.Background(), FanOutOptions{MaxParallel: 2},
+		Branch[int]{Name: "one", Run: func(ctx context.Context) (int, error) { return 1, nil }},
+		Branch[int]{Name: "fails", Run: func(ctx context.Context) (int, error) { return 0, errors.New("nope") }},
+		Branch[int]{Name: "panics", Run: func(ctx context.Context) (int, error) { panic("boom") }},
+		Branch[int]{Name: "three", Run: func(ctx context.Context) (int, error) { return 3, nil }},
+	)
+	if err != nil {
+		t.Fatalf("Expected nil error with partial success, got %v", err)
+	}
+
+	if results[0].Output != 1 || results[3].Output != 3 {
+		t.Errorf("Unexpected outputs: %+v", results)
+	}
+	if results[1].Err() == nil || results[2].Err() == nil {
+		t.Errorf("Expected failing and panicking branches to report errors: %+v", results)
+	}
+
+	toolResult := FanOutResult(results)
+	if toolResult.System == nil || !strings.Contains(*toolResult.System, "2 of 4 branches failed") ||
+		!strings.Contains(*toolResult.System, "fails (nope)") || !strings.Contains(*toolResult.System, "panics (panic: boom)") {
+		t.Errorf("Expected a summary naming the failed branches, got %v", toolResult.System)
+	}
+	if len(toolResult.Parts) != 4 {
+		t.Errorf("Expected one part per branch, got %d", len(toolResult.Parts))
+	}
+}
+
+func TestFanOut_AllFail(t *testing.T) {
+	_, err := FanOut(context.Background(), FanOutOptions{},
+		Branch[string]{Name: "a", Run: func(ctx context.Context) (string, error) { return "", errors.New("a failed") }},
+		Branch[string]{Name: "b", Run: func(ctx context.Context) (string, error) { return "", errors.New("b failed") }},
+	)
+	if err == nil {
+		t.Fatal("Expected error when all branches fail")
+	}
+}
+
+func TestFanOut_BoundedParallelism(t *testing.T) {
+	var running, peak int32
+	branch := Branch[int]{Name: "b", Run: func(ctx context.Context) (int, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return 0, nil
+	}}
+
+	if _, err := FanOut(context.Background(), FanOutOptions{MaxParallel: 2}, branch, branch, branch, branch, branch); err != nil {
+		t.Fatalf("FanOut returned error: %v", err)
+	}
+	if peak > 2 {
+		t.Errorf("Expected at most 2 concurrent branches, saw %d", peak)
+	}
+}
+
+func TestFanOut_BranchTimeoutAndFailFast(t *testing.T) {
+	slow := Branch[int]{Name: "slow", Run: func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}}
+
+	results, err := FanOut(context.Background(), FanOutOptions{BranchTimeout: 10 * time.Millisecond}, slow)
+	if err == nil || !errors.Is(results[0].Err(), context.DeadlineExceeded) {
+		t.Errorf("Expected branch deadline error, got %v / %v", err, results[0].Err())
+	}
+
+	_, err = FanOut(context.Background(), FanOutOptions{FailFast: true},
+		Branch[int]{Name: "fails", Run: func(ctx context.Context) (int, error) { return 0, errors.New("nope") }},
+		slow,
+	)
+	if err == nil {
+		t.Error("Expected FailFast to return the first error")
+	}
+}
@@ -0,0 +1,213 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/mhpenta/minimcp/infer"
+	"github.com/mhpenta/minimcp/safeunmarshal"
+)
+
+// Describer is implemented by services that supply descriptions for the tools FromStruct
+// makes of their methods, keyed by method name. The minimcp-docs command generates it from
+// the methods' doc comments:
+//
+//	//go:generate go run github.com/mhpenta/minimcp/cmd/minimcp-docs -type WeatherService
+type Describer interface {
+	ToolDescriptions() map[string]string
+}
+
+// StructOption configures FromStruct
+type StructOption func(*structConfig)
+
+type structConfig struct {
+	descriptions  map[string]string
+	methodOptions map[string][]ToolOption
+}
+
+// WithDescriptions sets tool descriptions by method name, taking precedence over the
+// service's ToolDescriptions
+func WithDescriptions(descriptions map[string]string) StructOption {
+	return func(c *structConfig) {
+		for method, description := range descriptions {
+			c.descriptions[method] = description
+		}
+	}
+}
+
+// WithMethodOptions applies tool options to the tool made from one method
+func WithMethodOptions(method string, opts ...ToolOption) StructOption {
+	return func(c *structConfig) {
+		c.methodOptions[method] = append(c.methodOptions[method], opts...)
+	}
+}
+
+var (
+	contextType = reflect.TypeFor[context.Context]()
+	errorType   = reflect.TypeFor[error]()
+)
+
+// FromStruct makes a tool of every exported method of service with the signature
+// func(context.Context, In) (Out, error), as NewTool would for a function. Tools are named
+// after their methods in snake case, so GetWeather becomes get_weather, and listed in
+// method name order. Other methods are ignored.
+//
+// Descriptions come from WithDescriptions, then from the service's ToolDescriptions if it
+// is a Describer; methods without one are described by their name.
+//
+// Example:
+//
+//	type WeatherService struct{ client *http.Client }
+//
+//	// GetWeather fetches the current weather for a city
+//	func (s *WeatherService) GetWeather(ctx context.Context, in WeatherInput) (Weather, error) { ... }
+//
+//	weatherTools, err := tools.FromStruct(&WeatherService{client: http.DefaultClient},
+//	    tools.WithMethodOptions("GetWeather", tools.WithVerb("Fetching weather")))
+//
+// It returns an error when service has no such methods, two methods map to the same tool
+// name, or schema generation fails.
+func FromStruct(service interface{}, opts ...StructOption) ([]Tool, error) {
+	v := reflect.ValueOf(service)
+	if !v.IsValid() {
+		return nil, fmt.Errorf("service is nil")
+	}
+	cfg := structConfig{descriptions: map[string]string{}, methodOptions: map[string][]ToolOption{}}
+	if describer, ok := service.(Describer); ok {
+		for method, description := range describer.ToolDescriptions() {
+			cfg.descriptions[method] = description
+		}
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	t := v.Type()
+	var tools []Tool
+	names := map[string]string{}
+	for i := 0; i < t.NumMethod(); i++ {
+		method := t.Method(i)
+		if !isToolMethod(method.Type) {
+			continue
+		}
+		name := snakeCase(method.Name)
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("methods %s and %s both make tool %q", other, method.Name, name)
+		}
+		names[name] = method.Name
+
+		description := cfg.descriptions[method.Name]
+		if description == "" {
+			description = method.Name
+		}
+		tool, err := newMethodTool(name, description, v.Method(i), cfg.methodOptions[method.Name])
+		if err != nil {
+			return nil, fmt.Errorf("method %s: %w", method.Name, err)
+		}
+		tools = append(tools, tool)
+	}
+	for method := range cfg.methodOptions {
+		if m, ok := t.MethodByName(method); !ok || !isToolMethod(m.Type) {
+			return nil, fmt.Errorf("options given for %s, which is not a tool method of %s", method, t)
+		}
+	}
+	if len(tools) == 0 {
+		return nil, fmt.Errorf("%s has no exported methods of the form func(context.Context, In) (Out, error)", t)
+	}
+	return tools, nil
+}
+
+// isToolMethod reports whether a method, with its receiver, has the signature
+// func(context.Context, In) (Out, error)
+func isToolMethod(t reflect.Type) bool {
+	return t.NumIn() == 3 && t.In(1) == contextType && t.NumOut() == 2 && t.Out(1) == errorType && !t.IsVariadic()
+}
+
+// methodTool is a tool backed by a method found by reflection, behaving like TypedTool
+type methodTool struct {
+	spec      *ToolSpec
+	method    reflect.Value
+	input     reflect.Type
+	validator *infer.Validator
+}
+
+func newMethodTool(name, description string, method reflect.Value, opts []ToolOption) (*methodTool, error) {
+	input, output := method.Type().In(1), method.Type().Out(0)
+
+	inputSchema, err := infer.FromReflectType(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate input schema: %w", err)
+	}
+	outputSchema, err := infer.FromReflectType(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate output schema: %w", err)
+	}
+	inputSchemaMap, err := infer.ToMap(inputSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert input schema to map: %w", err)
+	}
+	outputSchemaMap, err := infer.ToMap(outputSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert output schema to map: %w", err)
+	}
+
+	spec := &ToolSpec{
+		Name:        name,
+		Type:        fmt.Sprintf("%s_v1", name),
+		Description: description,
+		Parameters:  inputSchemaMap,
+		Output:      outputSchemaMap,
+	}
+	if err := applyOptions(spec, opts); err != nil {
+		return nil, err
+	}
+	validator, err := compileInputValidator(spec)
+	if err != nil {
+		return nil, err
+	}
+	return &methodTool{spec: spec, method: method, input: input, validator: validator}, nil
+}
+
+func (t *methodTool) Spec() *ToolSpec {
+	return t.spec
+}
+
+func (t *methodTool) Execute(ctx context.Context, params json.RawMessage) (*ToolResult, error) {
+	if err := validateInput(t.validator, t.spec, params); err != nil {
+		return nil, err
+	}
+
+	input := reflect.New(t.input)
+	if len(params) > 0 {
+		if err := safeunmarshal.IntoWithOptions(params, input.Interface(), inputOptions(t.spec)); err != nil {
+			return nil, NewInvalidParamsError(fmt.Sprintf("failed to parse parameters: %v", err))
+		}
+	}
+	results := t.method.Call([]reflect.Value{reflect.ValueOf(ctx), input.Elem()})
+	if err, _ := results[1].Interface().(error); err != nil {
+		return nil, err
+	}
+	return &ToolResult{Output: results[0].Interface()}, nil
+}
+
+// snakeCase converts a method name to a tool name: GetWeather becomes get_weather and
+// ListHTTPRoutes becomes list_http_routes
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
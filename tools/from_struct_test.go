@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type greeterService struct {
+	greeting string
+}
+
+type greetInput struct {
+	Name string `json:"name" jsonschema:"minLength=1"`
+}
+
+func (s *greeterService) Greet(ctx context.Context, in greetInput) (string, error) {
+	return s.greeting + ", " + in.Name, nil
+}
+
+func (s *greeterService) DeleteHTTPCache(ctx context.Context, in struct{}) (TestOutput, error) {
+	return TestOutput{}, errors.New("cache is read-only")
+}
+
+// Methods of other shapes are not tools
+func (s *greeterService) String() string                          { return "greeter" }
+func (s *greeterService) Close(ctx context.Context) error         { return nil }
+func (s *greeterService) Lookup(ctx context.Context, id int) bool { return false }
+
+func (s *greeterService) ToolDescriptions() map[string]string {
+	return map[string]string{"Greet": "Greets someone", "DeleteHTTPCache": "Clears the cache"}
+}
+
+func TestFromStruct(t *testing.T) {
+	list, err := FromStruct(&greeterService{greeting: "Hello"},
+		WithDescriptions(map[string]string{"DeleteHTTPCache": "Empties the HTTP cache"}),
+		WithMethodOptions("DeleteHTTPCache", WithDestructive(true)),
+		WithMethodOptions("Greet", WithInputValidation(true)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected 2 tools, got %d", len(list))
+	}
+
+	cache, greet := list[0].Spec(), list[1].Spec()
+	if cache.Name != "delete_http_cache" || cache.Description != "Empties the HTTP cache" || !cache.Destructive {
+		t.Errorf("unexpected spec %+v", cache)
+	}
+	if greet.Name != "greet" || greet.Description != "Greets someone" || greet.Parameters["properties"] == nil {
+		t.Errorf("unexpected spec %+v", greet)
+	}
+	if cache.Output["properties"] == nil {
+		t.Errorf("expected an output schema, got %v", cache.Output)
+	}
+
+	result, err := list[1].Execute(context.Background(), json.RawMessage(`{"name":"Ada"}`))
+	if err != nil || result.Output != "Hello, Ada" {
+		t.Errorf("unexpected result %v, %v", result, err)
+	}
+	if _, err := list[1].Execute(context.Background(), json.RawMessage(`{"name":""}`)); AsError(err) == nil {
+		t.Errorf("expected validation to reject an empty name, got %v", err)
+	}
+	if result, err := list[1].Execute(context.Background(), json.RawMessage(`{'name': 'Ada',}`)); err != nil || result.Output != "Hello, Ada" {
+		t.Errorf("expected malformed arguments to be repaired, got %v, %v", result, err)
+	}
+	if _, err := list[1].Execute(context.Background(), json.RawMessage(`{"name":`)); AsError(err) == nil {
+		t.Errorf("expected invalid JSON to be InvalidParams, got %v", err)
+	}
+	if _, err := list[0].Execute(context.Background(), nil); err == nil || err.Error() != "cache is read-only" {
+		t.Errorf("expected the method's error, got %v", err)
+	}
+}
+
+type noTools struct{}
+
+func (noTools) String() string { return "" }
+
+type clashing struct{}
+
+func (clashing) GetURL(ctx context.Context, in struct{}) (string, error) { return "", nil }
+func (clashing) GetUrl(ctx context.Context, in struct{}) (string, error) { return "", nil }
+
+func TestFromStruct_Errors(t *testing.T) {
+	cases := []struct {
+		service interface{}
+		opts    []StructOption
+		want    string
+	}{
+		{nil, nil, "nil"},
+		{noTools{}, nil, "no exported methods"},
+		// Pointer receivers are not in the value's method set
+		{greeterService{}, nil, "no exported methods"},
+		{clashing{}, nil, `both make tool "get_url"`},
+		{&greeterService{}, []StructOption{WithMethodOptions("Greets")}, "not a tool method"},
+		{&greeterService{}, []StructOption{WithMethodOptions("String")}, "not a tool method"},
+	}
+	for _, tc := range cases {
+		_, err := FromStruct(tc.service, tc.opts...)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%T: expected error containing %q, got %v", tc.service, tc.want, err)
+		}
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"Greet":          "greet",
+		"GetWeather":     "get_weather",
+		"ListHTTPRoutes": "list_http_routes",
+		"GetURL":         "get_url",
+		"Run2Tasks":      "run2_tasks",
+	} {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
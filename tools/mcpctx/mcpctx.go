@@ -0,0 +1,158 @@
+// Package mcpctx gives tool implementations typed access to what the server knows about
+// the current request: who is calling, from which client and session, and where to log.
+// The transports and JSON-RPC handler populate the context; tools only read from it.
+//
+// Example:
+//
+//	func search(ctx context.Context, in SearchInput) (SearchOutput, error) {
+//	    if mcpctx.ClientInfo(ctx).Name == "claude-code" {
+//	        in.Limit = min(in.Limit, 20)
+//	    }
+//	    mcpctx.Logger(ctx).Info("searching", "query", in.Query)
+//	    ...
+//	}
+package mcpctx
+
+import (
+	"context"
+	"log/slog"
+	"net/textproto"
+)
+
+// Client describes the MCP client, as it identified itself in initialize
+type Client struct {
+	Name    string
+	Version string
+}
+
+// Identity identifies an authenticated caller
+type Identity struct {
+	// Subject is the caller's identifier, e.g. the "sub" claim of a JWT
+	Subject string
+
+	// Scopes lists the permissions granted to the caller
+	Scopes []string
+
+	// Claims holds any additional attributes provided by the authenticator
+	Claims map[string]interface{}
+}
+
+// HasScopes reports whether the identity holds every given scope
+func (p *Identity) HasScopes(scopes ...string) bool {
+	for _, required := range scopes {
+		granted := false
+		for _, scope := range p.Scopes {
+			if scope == required {
+				granted = true
+				break
+			}
+		}
+		if !granted {
+			return false
+		}
+	}
+	return true
+}
+
+type clientKey struct{}
+type sessionIDKey struct{}
+type principalKey struct{}
+type progressTokenKey struct{}
+type loggerKey struct{}
+type metadataKey struct{}
+type requestIDKey struct{}
+
+// WithClientInfo returns a context carrying the client's identity
+func WithClientInfo(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// ClientInfo returns the client the request came from. It is zero when the client has not
+// initialized on a session the server can track, such as stateless HTTP requests.
+func ClientInfo(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey{}).(Client)
+	return client
+}
+
+// WithSessionID returns a context carrying the session ID
+func WithSessionID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, sessionIDKey{}, id)
+}
+
+// SessionID returns the ID of the session or connection the request arrived on, or ""
+func SessionID(ctx context.Context) string {
+	id, _ := ctx.Value(sessionIDKey{}).(string)
+	return id
+}
+
+// WithPrincipal returns a context carrying the authenticated caller
+func WithPrincipal(ctx context.Context, p *Identity) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// Principal returns the authenticated caller, or nil for unauthenticated transports such as stdio
+func Principal(ctx context.Context) *Identity {
+	p, _ := ctx.Value(principalKey{}).(*Identity)
+	return p
+}
+
+// WithProgressToken returns a context carrying the progress token the client sent
+func WithProgressToken(ctx context.Context, token interface{}) context.Context {
+	return context.WithValue(ctx, progressTokenKey{}, token)
+}
+
+// ProgressToken returns the progressToken from the request's _meta, a string or number,
+// or nil when the client did not ask for progress notifications
+func ProgressToken(ctx context.Context) interface{} {
+	return ctx.Value(progressTokenKey{})
+}
+
+// WithRequestID returns a context carrying the request's correlation ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the correlation ID shared by the client's and the server's logs of the
+// request, or "" unless the server was configured with RequestIDs. It differs from the
+// JSON-RPC ID, which is only unique within a session.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithLogger returns a context carrying a request-scoped logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger returns the server's logger annotated with the request's method, ID, session and
+// tool, falling back to slog.Default outside a request
+func Logger(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
+
+// Metadata holds the request headers the transport was configured to pass to tools, such
+// as X-Request-ID or X-Tenant-ID, keyed by canonical header name. Values are what the
+// client sent; authorize with Principal rather than trusting them.
+type Metadata map[string]string
+
+// Get returns the value of a header, matched case-insensitively, or "" when the request
+// did not carry it
+func (m Metadata) Get(header string) string {
+	return m[textproto.CanonicalMIMEHeaderKey(header)]
+}
+
+// WithMetadata returns a context carrying request metadata
+func WithMetadata(ctx context.Context, md Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// RequestMetadata returns the headers the transport copied from the request, or nil for
+// transports without headers such as stdio
+func RequestMetadata(ctx context.Context) Metadata {
+	md, _ := ctx.Value(metadataKey{}).(Metadata)
+	return md
+}
@@ -0,0 +1,46 @@
+package mcpctx
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestAccessorsDefaults(t *testing.T) {
+	ctx := context.Background()
+	if ClientInfo(ctx) != (Client{}) || SessionID(ctx) != "" || Principal(ctx) != nil || ProgressToken(ctx) != nil || RequestMetadata(ctx) != nil || RequestID(ctx) != "" {
+		t.Error("expected zero values outside a request")
+	}
+	if Logger(ctx) != slog.Default() {
+		t.Error("expected Logger to fall back to slog.Default")
+	}
+}
+
+func TestAccessors(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := WithClientInfo(context.Background(), Client{Name: "cli", Version: "1.0"})
+	ctx = WithSessionID(ctx, "s1")
+	ctx = WithPrincipal(ctx, &Identity{Subject: "alice", Scopes: []string{"read"}})
+	ctx = WithProgressToken(ctx, "tok")
+	ctx = WithLogger(ctx, logger)
+
+	if ClientInfo(ctx).Name != "cli" || SessionID(ctx) != "s1" || ProgressToken(ctx) != "tok" || Logger(ctx) != logger {
+		t.Error("expected values set on the context to be returned")
+	}
+	if p := Principal(ctx); p == nil || !p.HasScopes("read") || p.HasScopes("read", "write") {
+		t.Errorf("unexpected principal %+v", p)
+	}
+}
+
+func TestRequestMetadata(t *testing.T) {
+	ctx := WithMetadata(context.Background(), Metadata{"X-Tenant-Id": "acme"})
+	md := RequestMetadata(ctx)
+	if md.Get("X-Tenant-ID") != "acme" || md.Get("x-tenant-id") != "acme" || md.Get("X-Request-ID") != "" {
+		t.Errorf("unexpected metadata %v", md)
+	}
+	// Reading a nil Metadata is safe
+	if RequestMetadata(context.Background()).Get("X-Tenant-ID") != "" {
+		t.Error("expected no metadata outside a request")
+	}
+}
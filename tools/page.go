@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Page is the result envelope of list-style tools, such as SQL pages, file listings or
+// search results. Returning it tells agents how to ask for more: call the tool again with
+// NextCursor as the cursor argument until it is empty.
+//
+// Example:
+//
+//	func listFiles(ctx context.Context, req ListRequest) (tools.Page[File], error) {
+//	    return tools.Paginate(allFiles, req.PageRequest, 50)
+//	}
+type Page[T any] struct {
+	// Items holds the results on this page
+	Items []T `json:"items" jsonschema:"the results on this page"`
+
+	// NextCursor is passed back as the cursor argument to fetch the next page; empty on the last page
+	NextCursor string `json:"nextCursor,omitempty" jsonschema:"pass as the cursor argument to fetch the next page; absent on the last page"`
+
+	// Total is the number of results across all pages, or zero when unknown
+	Total int `json:"total,omitempty" jsonschema:"number of results across all pages, when known"`
+}
+
+// PageRequest holds the pagination arguments of a list-style tool. Embed it in the tool's
+// input type to accept them.
+type PageRequest struct {
+	Cursor string `json:"cursor,omitempty" jsonschema:"cursor from a previous page's nextCursor; omit for the first page"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"maximum number of results to return"`
+}
+
+// HasMore reports whether another page follows
+func (p Page[T]) HasMore() bool {
+	return p.NextCursor != ""
+}
+
+// pageJSON has Page's fields without its MarshalJSON method
+type pageJSON[T any] Page[T]
+
+// MarshalJSON encodes an empty page's items as [] rather than null, matching its schema
+func (p Page[T]) MarshalJSON() ([]byte, error) {
+	if p.Items == nil {
+		p.Items = []T{}
+	}
+	return json.Marshal(pageJSON[T](p))
+}
+
+// DecodePage decodes a page from a tool's JSON output, such as the text content of a
+// tools/call result
+func DecodePage[T any](data []byte) (Page[T], error) {
+	var page pageJSON[T]
+	if err := json.Unmarshal(data, &page); err != nil {
+		return Page[T]{}, fmt.Errorf("failed to decode page: %w", err)
+	}
+	return Page[T](page), nil
+}
+
+const offsetCursorPrefix = "offset:"
+
+// OffsetCursor encodes a result offset as an opaque cursor
+func OffsetCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(offsetCursorPrefix + strconv.Itoa(offset)))
+}
+
+// ParseOffsetCursor decodes a cursor made by OffsetCursor. The empty cursor is offset zero.
+func ParseOffsetCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		if value, ok := strings.CutPrefix(string(decoded), offsetCursorPrefix); ok {
+			if offset, err := strconv.Atoi(value); err == nil && offset >= 0 {
+				return offset, nil
+			}
+		}
+	}
+	return 0, NewInvalidParamsError(fmt.Sprintf("invalid cursor %q", cursor))
+}
+
+// Paginate returns the page of items selected by req, using offset cursors. A zero
+// req.Limit uses defaultLimit. An invalid cursor is reported as InvalidParams.
+func Paginate[T any](items []T, req PageRequest, defaultLimit int) (Page[T], error) {
+	offset, err := ParseOffsetCursor(req.Cursor)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	page := Page[T]{Total: len(items)}
+	if offset >= len(items) {
+		return page, nil
+	}
+	end := len(items)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+		page.NextCursor = OffsetCursor(end)
+	}
+	page.Items = items[offset:end]
+	return page, nil
+}
+
+// CollectPages calls a list-style tool repeatedly, following NextCursor, and returns the
+// items of every page. withCursor returns the input for the page at cursor. maxPages
+// bounds the number of calls; zero means no bound.
+func CollectPages[In, T any](
+	ctx context.Context,
+	tool Tool,
+	in In,
+	withCursor func(in In, cursor string) In,
+	maxPages int,
+) ([]T, error) {
+	var items []T
+	for calls := 0; maxPages <= 0 || calls < maxPages; calls++ {
+		page, err := Call[In, Page[T]](ctx, tool, in)
+		if err != nil {
+			return items, err
+		}
+		items = append(items, page.Items...)
+		if !page.HasMore() {
+			return items, nil
+		}
+		in = withCursor(in, page.NextCursor)
+	}
+	return items, fmt.Errorf("tool %q returned more than %d pages", tool.Spec().Name, maxPages)
+}
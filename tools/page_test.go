@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/mhpenta/minimcp/infer"
+)
+
+type listInput struct {
+	PageRequest
+	Prefix string `json:"prefix,omitempty"`
+}
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	page, err := Paginate(items, PageRequest{}, 2)
+	if err != nil || len(page.Items) != 2 || page.Total != 5 || !page.HasMore() {
+		t.Fatalf("unexpected first page %+v, %v", page, err)
+	}
+	page, _ = Paginate(items, PageRequest{Cursor: page.NextCursor, Limit: 10}, 2)
+	if len(page.Items) != 3 || page.Items[0] != 3 || page.HasMore() {
+		t.Errorf("unexpected last page %+v", page)
+	}
+
+	_, err = Paginate(items, PageRequest{Cursor: "bogus"}, 2)
+	var toolErr *Error
+	if !errors.As(err, &toolErr) || toolErr.Code != CodeInvalidParams {
+		t.Errorf("expected InvalidParams for a bad cursor, got %v", err)
+	}
+
+	empty, _ := Paginate([]int(nil), PageRequest{}, 2)
+	if data, _ := json.Marshal(empty); string(data) != `{"items":[]}` {
+		t.Errorf("expected empty items to encode as [], got %s", data)
+	}
+}
+
+func TestPageSchemaAndDecoding(t *testing.T) {
+	schema, err := infer.FromType[Page[TestOutput]]()
+	if err != nil {
+		t.Fatalf("FromType failed: %v", err)
+	}
+	schemaMap, _ := infer.ToMap(schema)
+	data, _ := json.Marshal(Page[TestOutput]{Items: []TestOutput{{Result: "a"}}, NextCursor: OffsetCursor(1), Total: 2})
+	if err := infer.Validate(schemaMap, data); err != nil {
+		t.Errorf("expected a page to satisfy its schema, got %v", err)
+	}
+
+	page, err := DecodePage[TestOutput](data)
+	if err != nil || page.Items[0].Result != "a" || !page.HasMore() {
+		t.Errorf("unexpected decoded page %+v, %v", page, err)
+	}
+}
+
+func TestCollectPages(t *testing.T) {
+	names := []string{"ada", "alan", "barbara", "grace", "ken"}
+	list := NewTool("list_names", "Lists names", func(ctx context.Context, in listInput) (Page[string], error) {
+		return Paginate(names, in.PageRequest, 2)
+	})
+	withCursor := func(in listInput, cursor string) listInput {
+		in.Cursor = cursor
+		return in
+	}
+
+	all, err := CollectPages[listInput, string](context.Background(), list, listInput{}, withCursor, 0)
+	if err != nil || len(all) != len(names) {
+		t.Fatalf("expected every name, got %v, %v", all, err)
+	}
+
+	if _, err := CollectPages[listInput, string](context.Background(), list, listInput{}, withCursor, 2); err == nil {
+		t.Error("expected an error when pages exceed maxPages")
+	}
+
+	// Pages relayed as JSON text, as a proxy for a remote tool returns them, decode too
+	relay := &textTool{output: `{"items":["x","y"]}`}
+	relayed, err := CollectPages[listInput, string](context.Background(), relay, listInput{}, withCursor, 0)
+	if err != nil || len(relayed) != 2 {
+		t.Errorf("expected items from JSON text output, got %v, %v", relayed, err)
+	}
+}
+
+type textTool struct{ output string }
+
+func (t *textTool) Spec() *ToolSpec {
+	return &ToolSpec{Name: "text_tool", Description: "Returns fixed text"}
+}
+
+func (t *textTool) Execute(ctx context.Context, params json.RawMessage) (*ToolResult, error) {
+	return &ToolResult{Output: t.output}, nil
+}
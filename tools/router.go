@@ -0,0 +1,167 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RouterTool exposes a group of related tools as a single gateway tool, for clients that
+// cap how many tools a server may list. Callers pick a tool with the "operation" parameter
+// and pass its input in "arguments":
+//
+//	{"operation": "get_quote", "arguments": {"symbol": "AAPL"}}
+//
+// The generated schema has an "operation" enum plus a oneOf with one branch per operation,
+// so clients still see each operation's real input schema.
+type RouterTool struct {
+	spec       *ToolSpec
+	operations map[string]Tool
+}
+
+// routerInput is the gateway tool's input
+type routerInput struct {
+	Operation string          `json:"operation"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// NewRouterTool creates a gateway tool named name that routes to operations by their
+// spec names. The router's spec combines its operations' settings: it is sequential or
+// destructive if any operation is, its timeout is the longest operation timeout, and it
+// requires the union of their scopes and uses the union of their resource keys. Options are applied after, and may override these.
+func NewRouterTool(name, description string, operations []Tool, opts ...ToolOption) (*RouterTool, error) {
+	if len(operations) == 0 {
+		return nil, fmt.Errorf("router tool %q needs at least one operation", name)
+	}
+
+	router := &RouterTool{operations: make(map[string]Tool, len(operations))}
+	spec := &ToolSpec{
+		Name:        name,
+		Type:        fmt.Sprintf("%s_v1", name),
+		Description: description,
+	}
+
+	var (
+		names        []string
+		branches     []interface{}
+		scopes       = make(map[string]bool)
+		resourceKeys = make(map[string]bool)
+	)
+	for _, op := range operations {
+		if err := Validate(op); err != nil {
+			return nil, fmt.Errorf("router tool %q: %w", name, err)
+		}
+		opSpec := op.Spec()
+		if _, exists := router.operations[opSpec.Name]; exists {
+			return nil, fmt.Errorf("router tool %q: duplicate operation %q", name, opSpec.Name)
+		}
+		router.operations[opSpec.Name] = op
+		names = append(names, opSpec.Name)
+
+		arguments := opSpec.Parameters
+		if arguments == nil {
+			arguments = map[string]interface{}{"type": "object"}
+		}
+		branch := map[string]interface{}{
+			"properties": map[string]interface{}{
+				"operation": map[string]interface{}{"const": opSpec.Name},
+				"arguments": arguments,
+			},
+			"required": []string{"operation", "arguments"},
+		}
+		if opSpec.Description != "" {
+			branch["description"] = opSpec.Description
+		}
+		branches = append(branches, branch)
+
+		spec.Sequential = spec.Sequential || opSpec.Sequential
+		spec.Destructive = spec.Destructive || opSpec.Destructive
+		spec.UI.LongRunning = spec.UI.LongRunning || opSpec.UI.LongRunning
+		if opSpec.Timeout > spec.Timeout {
+			spec.Timeout = opSpec.Timeout
+		}
+		for _, scope := range opSpec.RequiredScopes {
+			scopes[scope] = true
+		}
+		for _, key := range opSpec.ResourceKeys {
+			resourceKeys[key] = true
+		}
+	}
+
+	for scope := range scopes {
+		spec.RequiredScopes = append(spec.RequiredScopes, scope)
+	}
+	sort.Strings(spec.RequiredScopes)
+	for key := range resourceKeys {
+		spec.ResourceKeys = append(spec.ResourceKeys, key)
+	}
+	sort.Strings(spec.ResourceKeys)
+
+	spec.Parameters = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        names,
+				"description": "The operation to run: " + strings.Join(names, ", "),
+			},
+			"arguments": map[string]interface{}{
+				"type":        "object",
+				"description": "Input for the selected operation",
+			},
+		},
+		"required": []string{"operation", "arguments"},
+		"oneOf":    branches,
+	}
+
+	for _, opt := range opts {
+		opt(spec)
+	}
+	router.spec = spec
+	return router, nil
+}
+
+// Spec returns the gateway tool's specification
+func (r *RouterTool) Spec() *ToolSpec {
+	return r.spec
+}
+
+// Operation returns the tool registered under name
+func (r *RouterTool) Operation(name string) (Tool, bool) {
+	op, ok := r.operations[name]
+	return op, ok
+}
+
+// Execute routes the call to the selected operation's handler, drawing one sub-call from
+// the request Budget in ctx
+func (r *RouterTool) Execute(ctx context.Context, params json.RawMessage) (*ToolResult, error) {
+	var in routerInput
+	if err := json.Unmarshal(params, &in); err != nil {
+		return nil, NewInvalidParamsError(fmt.Sprintf("invalid router input: %v", err))
+	}
+	if in.Operation == "" {
+		return nil, NewInvalidParamsError("operation is required")
+	}
+
+	op, ok := r.operations[in.Operation]
+	if !ok {
+		return nil, NewInvalidParamsError(fmt.Sprintf("unknown operation %q", in.Operation))
+	}
+
+	arguments := in.Arguments
+	if len(arguments) == 0 || string(arguments) == "null" {
+		arguments = json.RawMessage("{}")
+	}
+
+	if timeout := op.Spec().Timeout; timeout > 0 && timeout < r.spec.Timeout {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := SpendCall(ctx); err != nil {
+		return nil, err
+	}
+	return op.Execute(ctx, arguments)
+}
@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRouterTool(t *testing.T) {
+	greet := NewTool("greet", "Greets someone", testHandler, WithRequiredScopes("read"), WithResourceKey("db"))
+	remove := NewTool("remove", "Removes something", testHandler,
+		WithDestructive(true), WithTimeout(time.Minute), WithRequiredScopes("write", "read"), WithResourceKey("files", "db"))
+
+	router, err := NewRouterTool("things", "Manage things", []Tool{greet, remove})
+	if err != nil {
+		t.Fatalf("NewRouterTool failed: %v", err)
+	}
+
+	spec := router.Spec()
+	if !spec.Destructive || spec.Timeout != time.Minute {
+		t.Errorf("expected combined destructive flag and timeout, got %+v", spec)
+	}
+	if len(spec.RequiredScopes) != 2 || spec.RequiredScopes[0] != "read" || spec.RequiredScopes[1] != "write" {
+		t.Errorf("expected union of scopes, got %v", spec.RequiredScopes)
+	}
+	if len(spec.ResourceKeys) != 2 || spec.ResourceKeys[0] != "db" || spec.ResourceKeys[1] != "files" {
+		t.Errorf("expected union of resource keys, got %v", spec.ResourceKeys)
+	}
+	operation := spec.Parameters["properties"].(map[string]interface{})["operation"].(map[string]interface{})
+	if enum := operation["enum"].([]string); len(enum) != 2 || enum[0] != "greet" || enum[1] != "remove" {
+		t.Errorf("unexpected operation enum %v", enum)
+	}
+	if branches := spec.Parameters["oneOf"].([]interface{}); len(branches) != 2 {
+		t.Errorf("expected one oneOf branch per operation, got %d", len(branches))
+	}
+
+	result, err := router.Execute(context.Background(),
+		json.RawMessage(`{"operation":"greet","arguments":{"name":"ada","value":1}}`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if out := result.Output.(TestOutput); out.Result != "processed: ada" {
+		t.Errorf("unexpected output %+v", out)
+	}
+
+	_, err = router.Execute(context.Background(), json.RawMessage(`{"operation":"missing","arguments":{}}`))
+	var toolErr *Error
+	if !errors.As(err, &toolErr) || toolErr.Code != CodeInvalidParams {
+		t.Errorf("expected invalid params for unknown operation, got %v", err)
+	}
+}
+
+func TestNewRouterTool_DuplicateOperation(t *testing.T) {
+	a := NewTool("same", "A", testHandler)
+	b := NewTool("same", "B", testHandler)
+	if _, err := NewRouterTool("router", "Router", []Tool{a, b}); err == nil {
+		t.Error("expected error for duplicate operation names")
+	}
+	if _, err := NewRouterTool("router", "Router", nil); err == nil {
+		t.Error("expected error for a router without operations")
+	}
+}
+
+func TestRouterTool_SpendsBudget(t *testing.T) {
+	router, err := NewRouterTool("things", "Manage things", []Tool{NewTool("greet", "Greets someone", testHandler)})
+	if err != nil {
+		t.Fatalf("NewRouterTool failed: %v", err)
+	}
+	ctx := WithBudget(context.Background(), NewBudget(BudgetLimits{MaxCalls: 1}))
+	params := json.RawMessage(`{"operation":"greet","arguments":{"name":"ada","value":1}}`)
+
+	if _, err := router.Execute(ctx, params); err != nil {
+		t.Fatalf("expected the first routed call to fit the budget, got %v", err)
+	}
+	if _, err := router.Execute(ctx, params); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("expected ErrBudgetExceeded, got %v", err)
+	}
+}
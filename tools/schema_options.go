@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// WithOutputSchema replaces the output schema inferred from the handler's return type
+func WithOutputSchema(schema map[string]interface{}) ToolOption {
+	return func(spec *ToolSpec) {
+		spec.Output = schema
+	}
+}
+
+// WithParameterDescription sets the description of an input property. Nested properties
+// are addressed with dots, such as "address.city"; array properties are stepped through
+// to their items, so "filters.field" names the field of each filter.
+//
+// Example:
+//
+//	tool := tools.NewTool("search", "Searches documents", search,
+//	    tools.WithParameterDescription("query", "full-text search terms"),
+//	)
+func WithParameterDescription(field, text string) ToolOption {
+	return func(spec *ToolSpec) {
+		editParameters(spec, func(schema map[string]interface{}) error {
+			prop, err := lookupProperty(schema, field)
+			if err != nil {
+				return err
+			}
+			prop["description"] = text
+			return nil
+		})
+	}
+}
+
+// WithRequired replaces the list of required top-level input properties. Every field
+// must be a property of the input schema.
+func WithRequired(fields ...string) ToolOption {
+	return func(spec *ToolSpec) {
+		editParameters(spec, func(schema map[string]interface{}) error {
+			required := make([]interface{}, 0, len(fields))
+			for _, field := range fields {
+				if _, err := lookupProperty(schema, field); err != nil {
+					return err
+				}
+				required = append(required, field)
+			}
+			schema["required"] = required
+			return nil
+		})
+	}
+}
+
+// editParameters applies edit to a copy of the input schema, so maps passed to
+// WithCustomSchema are never modified. A failed edit is recorded for the constructor.
+func editParameters(spec *ToolSpec, edit func(map[string]interface{}) error) {
+	if spec.optionErr != nil {
+		return
+	}
+	if spec.Parameters == nil {
+		spec.optionErr = fmt.Errorf("tool %q has no input schema to modify", spec.Name)
+		return
+	}
+
+	data, err := json.Marshal(spec.Parameters)
+	if err != nil {
+		spec.optionErr = fmt.Errorf("failed to copy input schema: %w", err)
+		return
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		spec.optionErr = fmt.Errorf("failed to copy input schema: %w", err)
+		return
+	}
+
+	if err := edit(schema); err != nil {
+		spec.optionErr = err
+		return
+	}
+	spec.Parameters = schema
+}
+
+// lookupProperty returns the schema of a dotted property path
+func lookupProperty(schema map[string]interface{}, field string) (map[string]interface{}, error) {
+	current := schema
+	for _, name := range strings.Split(field, ".") {
+		// Step into array items, possibly several levels deep
+		for hasType(current, "array") {
+			items, ok := current["items"].(map[string]interface{})
+			if !ok {
+				break
+			}
+			current = items
+		}
+		properties, _ := current["properties"].(map[string]interface{})
+		prop, ok := properties[name].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("input schema has no property %q", field)
+		}
+		current = prop
+	}
+	return current, nil
+}
+
+// hasType reports whether a schema's type is, or includes, typ
+func hasType(schema map[string]interface{}, typ string) bool {
+	switch t := schema["type"].(type) {
+	case string:
+		return t == typ
+	case []interface{}:
+		for _, v := range t {
+			if v == typ {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type schemaOptionsInput struct {
+	Query   string                `json:"query"`
+	Limit   int                   `json:"limit,omitempty"`
+	Filters []schemaOptionsFilter `json:"filters,omitempty"`
+	Owner   *schemaOptionsFilter  `json:"owner,omitempty"`
+}
+
+type schemaOptionsFilter struct {
+	Field string `json:"field"`
+}
+
+func schemaOptionsHandler(ctx context.Context, in schemaOptionsInput) (string, error) {
+	return in.Query, nil
+}
+
+func property(t *testing.T, schema map[string]interface{}, path ...string) map[string]interface{} {
+	t.Helper()
+	current := schema
+	for _, name := range path {
+		next, ok := current[name].(map[string]interface{})
+		if !ok {
+			t.Fatalf("schema has no %q at %v", name, path)
+		}
+		current = next
+	}
+	return current
+}
+
+func TestSchemaOptions(t *testing.T) {
+	output := map[string]interface{}{"type": "object", "properties": map[string]interface{}{"hits": map[string]interface{}{"type": "integer"}}}
+
+	tool, err := NewToolWithError("search", "Searches documents", schemaOptionsHandler,
+		WithOutputSchema(output),
+		WithParameterDescription("query", "full-text search terms"),
+		WithParameterDescription("filters.field", "column to filter on"),
+		WithParameterDescription("owner.field", "owner column"),
+		WithRequired("query", "limit"),
+		WithInputValidation(true),
+	)
+	if err != nil {
+		t.Fatalf("NewToolWithError failed: %v", err)
+	}
+	spec := tool.Spec()
+
+	if !reflect.DeepEqual(spec.Output, output) {
+		t.Errorf("expected output schema to be replaced, got %v", spec.Output)
+	}
+	if got := property(t, spec.Parameters, "properties", "query")["description"]; got != "full-text search terms" {
+		t.Errorf("expected query description, got %v", got)
+	}
+	if got := property(t, spec.Parameters, "properties", "filters", "items", "properties", "field")["description"]; got != "column to filter on" {
+		t.Errorf("expected filters.field description, got %v", got)
+	}
+	if got := property(t, spec.Parameters, "properties", "owner", "properties", "field")["description"]; got != "owner column" {
+		t.Errorf("expected owner.field description, got %v", got)
+	}
+	if got := spec.Parameters["required"]; !reflect.DeepEqual(got, []interface{}{"query", "limit"}) {
+		t.Errorf("expected required [query limit], got %v", got)
+	}
+
+	// The validator is built from the adjusted schema
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"query":"go"}`))
+	if toolErr := AsError(err); toolErr == nil || toolErr.Code != CodeInvalidParams {
+		t.Errorf("expected missing limit to be rejected, got %v", err)
+	}
+}
+
+func TestSchemaOptions_CustomSchemaNotModified(t *testing.T) {
+	custom := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"q": map[string]interface{}{"type": "string"}},
+	}
+	tool := NewTool("search", "Searches documents", schemaOptionsHandler,
+		WithCustomSchema(custom),
+		WithParameterDescription("q", "query"),
+	)
+
+	if got := property(t, tool.Spec().Parameters, "properties", "q")["description"]; got != "query" {
+		t.Errorf("expected description on the tool's schema, got %v", got)
+	}
+	if _, ok := property(t, custom, "properties", "q")["description"]; ok {
+		t.Error("expected the caller's schema map to be left unmodified")
+	}
+}
+
+func TestSchemaOptions_UnknownField(t *testing.T) {
+	for name, opt := range map[string]ToolOption{
+		"description": WithParameterDescription("owner.missing", "text"),
+		"required":    WithRequired("query", "missing"),
+	} {
+		_, err := NewToolWithError("search", "Searches documents", schemaOptionsHandler, opt)
+		if err == nil || !strings.Contains(err.Error(), "missing") {
+			t.Errorf("%s: expected error naming the unknown field, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrSecretNotFound is returned when a secret is not set, or no secret provider is configured
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider looks up secrets such as API keys by name. Set ServerConfig.Secrets so
+// tools read secrets with Secret instead of from the environment directly, and tests can
+// substitute a MapSecrets.
+type SecretProvider interface {
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+type secretsKey struct{}
+
+// WithSecrets returns a context through which tools can read secrets from provider
+func WithSecrets(ctx context.Context, provider SecretProvider) context.Context {
+	return context.WithValue(ctx, secretsKey{}, provider)
+}
+
+// Secret returns the named secret from the provider in ctx. It returns an error wrapping
+// ErrSecretNotFound when the secret is not set or ctx has no provider.
+//
+// Example:
+//
+//	func getWeather(ctx context.Context, in WeatherInput) (Weather, error) {
+//	    key, err := tools.Secret(ctx, "OPENWEATHER_API_KEY")
+//	    if err != nil {
+//	        return Weather{}, err
+//	    }
+//	    ...
+//	}
+func Secret(ctx context.Context, name string) (string, error) {
+	provider, ok := ctx.Value(secretsKey{}).(SecretProvider)
+	if !ok || provider == nil {
+		return "", fmt.Errorf("%w: %s (no secret provider configured)", ErrSecretNotFound, name)
+	}
+	return provider.Secret(ctx, name)
+}
+
+// EnvSecrets reads secrets from environment variables named Prefix plus the secret name
+type EnvSecrets struct {
+	Prefix string
+}
+
+// Secret returns the environment variable Prefix+name; unset and empty variables are not found
+func (e EnvSecrets) Secret(ctx context.Context, name string) (string, error) {
+	if value := os.Getenv(e.Prefix + name); value != "" {
+		return value, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+}
+
+// FileSecrets reads each secret from a file named after it in Dir, as mounted by Docker
+// and Kubernetes under /run/secrets. A trailing newline is removed.
+type FileSecrets struct {
+	Dir string
+}
+
+// Secret returns the content of Dir/name. Names containing path separators are rejected,
+// so a secret name cannot reach outside Dir.
+func (f FileSecrets) Secret(ctx context.Context, name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+	data, err := os.ReadFile(filepath.Join(f.Dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading secret %s: %w", name, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// MapSecrets serves secrets from a map, for tests and local development
+type MapSecrets map[string]string
+
+// Secret returns the map's value for name
+func (m MapSecrets) Secret(ctx context.Context, name string) (string, error) {
+	if value, ok := m[name]; ok {
+		return value, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+}
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSecret_NoProvider(t *testing.T) {
+	if _, err := Secret(context.Background(), "API_KEY"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("expected ErrSecretNotFound without a provider, got %v", err)
+	}
+}
+
+func TestMapSecrets(t *testing.T) {
+	ctx := WithSecrets(context.Background(), MapSecrets{"API_KEY": "test-key"})
+	if value, err := Secret(ctx, "API_KEY"); err != nil || value != "test-key" {
+		t.Errorf("expected test-key, got %q, %v", value, err)
+	}
+	if _, err := Secret(ctx, "OTHER"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("expected ErrSecretNotFound, got %v", err)
+	}
+}
+
+func TestEnvSecrets(t *testing.T) {
+	t.Setenv("MYAPP_API_KEY", "from-env")
+	t.Setenv("MYAPP_EMPTY", "")
+	provider := EnvSecrets{Prefix: "MYAPP_"}
+	ctx := context.Background()
+
+	if value, err := provider.Secret(ctx, "API_KEY"); err != nil || value != "from-env" {
+		t.Errorf("expected from-env, got %q, %v", value, err)
+	}
+	if _, err := provider.Secret(ctx, "EMPTY"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("expected empty variables to be not found, got %v", err)
+	}
+	if _, err := provider.Secret(ctx, "MISSING"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("expected ErrSecretNotFound, got %v", err)
+	}
+}
+
+func TestFileSecrets(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "db_password"), []byte("hunter2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	provider := FileSecrets{Dir: dir}
+	ctx := context.Background()
+
+	if value, err := provider.Secret(ctx, "db_password"); err != nil || value != "hunter2" {
+		t.Errorf("expected the file content without its newline, got %q, %v", value, err)
+	}
+	if _, err := provider.Secret(ctx, "missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("expected ErrSecretNotFound, got %v", err)
+	}
+	for _, name := range []string{"../db_password", "sub/db_password", "..", ""} {
+		if _, err := provider.Secret(ctx, name); err == nil || errors.Is(err, ErrSecretNotFound) {
+			t.Errorf("expected %q to be rejected as invalid, got %v", name, err)
+		}
+	}
+}
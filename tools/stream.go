@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mhpenta/minimcp/infer"
+	"github.com/mhpenta/minimcp/safeunmarshal"
+)
+
+// EmitFunc delivers one chunk of a streaming tool's output. It returns an error when the
+// chunk cannot be delivered, such as after the client disconnects; tools should stop then.
+type EmitFunc func(chunk string) error
+
+// StreamingTool is a tool that can deliver its output incrementally, for tools that wrap
+// LLMs or export large query results. Transports that can push messages forward each
+// chunk as it is emitted; the rest wait for the complete result.
+type StreamingTool interface {
+	Tool
+
+	// ExecuteStream runs the tool, calling emit with each chunk of output as it is produced.
+	// The returned result is the complete output, as Execute would return it.
+	ExecuteStream(ctx context.Context, params json.RawMessage, emit EmitFunc) (*ToolResult, error)
+}
+
+// TypedStreamingTool is a StreamingTool with typed input, whose output is the
+// concatenation of the text chunks it emits
+type TypedStreamingTool[In any] struct {
+	spec      *ToolSpec
+	handler   func(context.Context, In, EmitFunc) error
+	validator *infer.Validator
+}
+
+// NewStreamingTool creates a streaming tool with automatic input schema generation. The
+// handler emits its output in chunks; Execute returns them concatenated.
+//
+// Example:
+//
+//	tool := tools.NewStreamingTool("export_rows", "Exports query results as CSV",
+//	    func(ctx context.Context, req ExportRequest, emit tools.EmitFunc) error {
+//	        for rows.Next() {
+//	            if err := emit(formatRow(rows)); err != nil {
+//	                return err
+//	            }
+//	        }
+//	        return rows.Err()
+//	    })
+//
+// It panics if schema generation fails, like NewTool.
+func NewStreamingTool[In any](
+	name,
+	description string,
+	handler func(context.Context, In, EmitFunc) error,
+	opts ...ToolOption,
+) *TypedStreamingTool[In] {
+	inputSchema, err := infer.FromType[In]()
+	if err != nil {
+		panic(fmt.Sprintf("failed to generate schema for streaming tool %q: %v", name, err))
+	}
+	inputSchemaMap, err := infer.ToMap(inputSchema)
+	if err != nil {
+		panic(fmt.Sprintf("failed to convert schema for streaming tool %q: %v", name, err))
+	}
+
+	spec := &ToolSpec{
+		Name:        name,
+		Type:        fmt.Sprintf("%s_v1", name),
+		Description: description,
+		Parameters:  inputSchemaMap,
+		UI:          UI{LongRunning: true},
+	}
+	if err := applyOptions(spec, opts); err != nil {
+		panic(fmt.Sprintf("streaming tool %q: %v", name, err))
+	}
+
+	validator, err := compileInputValidator(spec)
+	if err != nil {
+		panic(fmt.Sprintf("streaming tool %q: %v", name, err))
+	}
+
+	return &TypedStreamingTool[In]{spec: spec, handler: handler, validator: validator}
+}
+
+// Spec returns the tool's specification
+func (t *TypedStreamingTool[In]) Spec() *ToolSpec {
+	return t.spec
+}
+
+// Execute runs the tool and returns its chunks concatenated
+func (t *TypedStreamingTool[In]) Execute(ctx context.Context, params json.RawMessage) (*ToolResult, error) {
+	return t.ExecuteStream(ctx, params, func(string) error { return nil })
+}
+
+// ExecuteStream runs the tool, forwarding each chunk to emit as it is produced
+func (t *TypedStreamingTool[In]) ExecuteStream(ctx context.Context, params json.RawMessage, emit EmitFunc) (*ToolResult, error) {
+	if err := validateInput(t.validator, t.spec, params); err != nil {
+		return nil, err
+	}
+
+	var input In
+	if len(params) > 0 {
+		parsedInput, err := safeunmarshal.ToWithOptions[In](params, inputOptions(t.spec))
+		if err != nil {
+			return nil, NewInvalidParamsError(fmt.Sprintf("failed to parse parameters: %v", err))
+		}
+		input = parsedInput
+	}
+
+	var output strings.Builder
+	err := t.handler(ctx, input, func(chunk string) error {
+		output.WriteString(chunk)
+		return emit(chunk)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ToolResult{Output: output.String()}, nil
+}
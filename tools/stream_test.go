@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type streamInput struct {
+	Words []string `json:"words"`
+}
+
+func TestStreamingTool(t *testing.T) {
+	tool := NewStreamingTool("spell", "Spells words", func(ctx context.Context, in streamInput, emit EmitFunc) error {
+		for _, word := range in.Words {
+			if err := emit(word); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if !tool.Spec().UI.LongRunning {
+		t.Error("expected streaming tools to default to long-running")
+	}
+
+	params := json.RawMessage(`{"words":["a","b","c"]}`)
+	result, err := tool.Execute(context.Background(), params)
+	if err != nil || result.Output != "abc" {
+		t.Fatalf("expected concatenated output, got %+v, %v", result, err)
+	}
+
+	var chunks []string
+	result, err = tool.ExecuteStream(context.Background(), params, func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil || result.Output != "abc" || len(chunks) != 3 {
+		t.Fatalf("expected three chunks and full output, got %v, %+v, %v", chunks, result, err)
+	}
+
+	if result, err := tool.Execute(context.Background(), json.RawMessage(`{words: ['a', 'b']}`)); err != nil || result.Output != "ab" {
+		t.Errorf("expected malformed arguments to be repaired, got %+v, %v", result, err)
+	}
+
+	gone := errors.New("client gone")
+	_, err = tool.ExecuteStream(context.Background(), params, func(string) error { return gone })
+	if !errors.Is(err, gone) {
+		t.Errorf("expected emit error to stop the tool, got %v", err)
+	}
+}
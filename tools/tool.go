@@ -74,6 +74,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // Tool defines the interface that all tools must implement
@@ -102,10 +103,45 @@ type ToolSpec struct {
 	Output map[string]interface{} `json:"output,omitempty"`
 
 	// Sequential indicates if a tool must be run sequentially with other tools. False means we can run it in parallel.
+	// The server runs one call of a sequential tool at a time.
 	Sequential bool `json:"sequential,omitempty"`
 
+	// ResourceKeys names shared resources the tool uses, such as "db" or "repo". The server
+	// runs one call at a time among tools sharing a key; other calls run concurrently.
+	ResourceKeys []string `json:"resource_keys,omitempty"`
+
+	// Timeout bounds how long a single execution may run. Zero uses the server's default.
+	Timeout time.Duration `json:"timeout,omitempty"`
+
+	// Destructive indicates the tool may modify or delete external state, so re-running it requires explicit confirmation.
+	Destructive bool `json:"destructive,omitempty"`
+
+	// RequiredScopes lists the scopes an authenticated caller must hold to list or call the tool
+	RequiredScopes []string `json:"required_scopes,omitempty"`
+
+	// Priority orders the tool in listings; higher priorities are listed first
+	Priority int `json:"priority,omitempty"`
+
+	// Category groups related tools, so client profiles can rank them together
+	Category string `json:"category,omitempty"`
+
+	// CacheTTL, when positive, lets a ResultCache reuse the tool's results for this long
+	CacheTTL time.Duration `json:"cache_ttl,omitempty"`
+
+	// ValidateInput checks arguments against Parameters before the handler runs, rejecting
+	// violations of constraints such as enum, minimum or required with InvalidParams
+	ValidateInput bool `json:"validate_input,omitempty"`
+
+	// StrictInput rejects malformed JSON arguments instead of repairing them. By default,
+	// arguments with problems such as single quotes or trailing commas are repaired, unless
+	// the repair would discard content.
+	StrictInput bool `json:"strict_input,omitempty"`
+
 	// UI provides additional UI hints for the tool
 	UI UI `json:"ui,omitempty"`
+
+	// optionErr records the first option that could not be applied, reported by the constructor
+	optionErr error
 }
 
 type UI struct {
@@ -137,10 +173,10 @@ func Validate(t Tool) error {
 		if (char >= 'a' && char <= 'z') ||
 			(char >= 'A' && char <= 'Z') ||
 			(char >= '0' && char <= '9') ||
-			char == '_' || char == '-' {
+			char == '_' || char == '-' || char == '.' {
 			continue
 		}
-		return fmt.Errorf("tool name must contain only alphanumeric characters, underscores, or hyphens")
+		return fmt.Errorf("tool name must contain only alphanumeric characters, underscores, hyphens, or dots")
 	}
 
 	if m.Description == "" {
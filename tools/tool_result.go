@@ -11,6 +11,16 @@ type ToolArtifact struct {
 	Description string `json:"description"`
 }
 
+// SystemInfo is metadata about a tool's execution that is not part of its output, such as
+// notes on partial results or the environment the tool ran in
+type SystemInfo struct {
+	// Message is a human-readable note, shown to the model after the output
+	Message string `json:"message,omitempty"`
+
+	// Metadata holds machine-readable details, forwarded to clients in the result's _meta
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
 // ToolResult represents the outcome of a tool execution, providing structured output
 // for different types of tool responses. It separates concerns between normal output,
 // errors, image data, and system-level information to facilitate proper handling.
@@ -23,17 +33,33 @@ type ToolResult struct {
 	// In most cases, the output will be json marshaled into a string for the llm.
 	Output any `json:"output,omitempty"`
 
+	// Parts splits the output into pieces sent to clients as separate content blocks, such
+	// as one per branch of a fan-out. When set, clients receive Parts instead of Output,
+	// while in-process callers such as Call still decode Output.
+	Parts []any `json:"parts,omitempty"`
+
 	// Error contains any error messages when tool execution fails.
 	// This is kept separate from Output to clearly distinguish between
 	// success and failure cases.
 	Error *string `json:"error,omitempty"`
 
+	// ErrorInfo classifies a failure. Its Code decides how the failure is reported: codes
+	// in the reserved JSON-RPC range become protocol errors, any other code is reported to
+	// the model as a failed tool result. A result with only Error set is an unclassified
+	// tool failure. See IsError and Err.
+	ErrorInfo *Error `json:"error_info,omitempty"`
+
 	// System contains metadata or system-level messages about the tool's execution.
 	// This field is used for information about the execution environment,
 	// tool initialization messages, or other system-level status updates
-	// that are separate from the tool's primary output or errors.
+	// that are separate from the tool's primary output or errors. Clients receive
+	// it as a content block of its own after the output.
 	System *string `json:"system,omitempty"`
 
+	// SystemInfo carries machine-readable details about the execution alongside System.
+	// Its Metadata is forwarded to clients in the result's _meta.
+	SystemInfo *SystemInfo `json:"system_info,omitempty"`
+
 	// Image contains any image data generated by the tool execution. E.g., for tools
 	// that perform screen captures or generate visual output. The ToolImage type encapsulates the
 	// image data and related metadata.
@@ -42,3 +68,47 @@ type ToolResult struct {
 	// Artifact contains additional artifacts produced by the tool execution.
 	Artifact *ToolArtifact `json:"artifacts,omitempty"`
 }
+
+// IsError reports whether the result represents a failed execution
+func (r *ToolResult) IsError() bool {
+	return r != nil && (r.Error != nil || r.ErrorInfo != nil)
+}
+
+// Err returns the result's failure, or nil on success. A result with only a message in
+// Error is an unclassified failure with code zero.
+func (r *ToolResult) Err() *Error {
+	switch {
+	case r == nil:
+		return nil
+	case r.ErrorInfo != nil:
+		return r.ErrorInfo
+	case r.Error != nil:
+		return &Error{Message: *r.Error}
+	}
+	return nil
+}
+
+// SystemMessage returns the human-readable note in System, or in SystemInfo when System
+// is unset
+func (r *ToolResult) SystemMessage() string {
+	switch {
+	case r == nil:
+		return ""
+	case r.System != nil:
+		return *r.System
+	case r.SystemInfo != nil:
+		return r.SystemInfo.Message
+	}
+	return ""
+}
+
+// ErrorResult returns a result reporting that the tool failed with err. Error holds its
+// message and ErrorInfo the *Error in its chain, so the code is kept.
+func ErrorResult(err error) *ToolResult {
+	info := AsError(err)
+	if info == nil {
+		return &ToolResult{}
+	}
+	message := info.Message
+	return &ToolResult{Error: &message, ErrorInfo: info}
+}
@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestToolResult_StringFieldsStillWork(t *testing.T) {
+	errText, system := "disk full", "ran on node-3"
+	result := &ToolResult{Name: "save", Error: &errText, System: &system}
+
+	if failure := result.Err(); !result.IsError() || failure.Message != "disk full" || failure.Code != 0 {
+		t.Errorf("expected an unclassified tool failure, got %+v", failure)
+	}
+	if result.SystemMessage() != "ran on node-3" {
+		t.Errorf("expected the system message, got %q", result.SystemMessage())
+	}
+
+	// The JSON shape of string errors is unchanged
+	data, _ := json.Marshal(result)
+	if string(data) != `{"name":"save","error":"disk full","system":"ran on node-3"}` {
+		t.Errorf("unexpected JSON %s", data)
+	}
+
+	// Classified failures keep their code next to the message
+	classified := ErrorResult(NewInvalidParamsError("bad"))
+	if *classified.Error != "bad" || classified.Err().Code != CodeInvalidParams {
+		t.Errorf("expected message and code, got %+v", classified)
+	}
+	if (&ToolResult{Output: "ok"}).IsError() || (&ToolResult{}).Err() != nil {
+		t.Error("expected a successful result to report no error")
+	}
+}
+
+func TestAsError(t *testing.T) {
+	if AsError(nil) != nil {
+		t.Error("expected nil for a nil error")
+	}
+
+	invalid := NewInvalidParamsError("missing city")
+	if got := AsError(fmt.Errorf("wrapped: %w", invalid)); got != invalid || !got.IsProtocolError() {
+		t.Errorf("expected the wrapped *Error to be found, got %+v", got)
+	}
+
+	plain := errors.New("boom")
+	got := AsError(plain)
+	if got.Code != 0 || got.Message != "boom" || !errors.Is(got, plain) {
+		t.Errorf("expected plain errors to become unclassified failures, got %+v", got)
+	}
+	if result := ErrorResult(plain); !result.IsError() {
+		t.Error("expected ErrorResult to report an error")
+	}
+}
@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Handler executes a tool call with its raw JSON arguments
+type Handler func(ctx context.Context, params json.RawMessage) (*ToolResult, error)
+
+// Middleware wraps the execution of each tool in a Toolset. spec describes the tool being
+// called, under its prefixed name.
+//
+// Example:
+//
+//	logCalls := func(spec *tools.ToolSpec, next tools.Handler) tools.Handler {
+//	    return func(ctx context.Context, params json.RawMessage) (*tools.ToolResult, error) {
+//	        mcpctx.Logger(ctx).Info("calling", "tool", spec.Name)
+//	        return next(ctx, params)
+//	    }
+//	}
+type Middleware func(spec *ToolSpec, next Handler) Handler
+
+// DefaultToolsetSeparator joins a toolset's name and its tools' names, as in "fs.read"
+const DefaultToolsetSeparator = "."
+
+// Toolset bundles related tools under a shared name prefix and shared middleware, so
+// they can be registered, enabled, and disabled together.
+//
+// Example:
+//
+//	fs := tools.NewToolset("fs", readTool, writeTool).
+//	    WithMiddleware(logCalls)
+//
+//	server := mcp.NewServer(mcp.ServerConfig{
+//	    Name:     "my-server",
+//	    Toolsets: []*tools.Toolset{fs}, // lists "fs.read" and "fs.write"
+//	})
+type Toolset struct {
+	name       string
+	separator  string
+	tools      []Tool
+	middleware []Middleware
+	enabled    bool
+}
+
+// NewToolset creates an enabled toolset named name holding tools
+func NewToolset(name string, tools ...Tool) *Toolset {
+	return &Toolset{
+		name:      name,
+		separator: DefaultToolsetSeparator,
+		tools:     tools,
+		enabled:   true,
+	}
+}
+
+// WithSeparator sets the string joining the toolset and tool names. Use "_" for clients
+// that only accept letters, digits, underscores and hyphens in tool names.
+func (s *Toolset) WithSeparator(separator string) *Toolset {
+	s.separator = separator
+	return s
+}
+
+// WithMiddleware appends middleware run around every tool in the set. The first
+// middleware is the outermost.
+func (s *Toolset) WithMiddleware(middleware ...Middleware) *Toolset {
+	s.middleware = append(s.middleware, middleware...)
+	return s
+}
+
+// WithEnabled sets whether the server registers the toolset at startup. Disabled
+// toolsets can be enabled later with Server.EnableToolset.
+func (s *Toolset) WithEnabled(enabled bool) *Toolset {
+	s.enabled = enabled
+	return s
+}
+
+// Add appends tools to the set
+func (s *Toolset) Add(tools ...Tool) *Toolset {
+	s.tools = append(s.tools, tools...)
+	return s
+}
+
+// Name returns the toolset's name
+func (s *Toolset) Name() string {
+	return s.name
+}
+
+// Enabled reports whether the toolset is registered at startup
+func (s *Toolset) Enabled() bool {
+	return s.enabled
+}
+
+// Tools returns the set's tools as clients see them: named "<set><separator><tool>" and
+// wrapped in the set's middleware
+func (s *Toolset) Tools() []Tool {
+	wrapped := make([]Tool, 0, len(s.tools))
+	for _, tool := range s.tools {
+		wrapped = append(wrapped, s.wrap(tool))
+	}
+	return wrapped
+}
+
+// ToolNames returns the prefixed names of the set's tools
+func (s *Toolset) ToolNames() []string {
+	names := make([]string, 0, len(s.tools))
+	for _, tool := range s.tools {
+		names = append(names, s.name+s.separator+tool.Spec().Name)
+	}
+	return names
+}
+
+func (s *Toolset) wrap(tool Tool) Tool {
+	spec := *tool.Spec()
+	spec.Name = s.name + s.separator + spec.Name
+
+	wrapped := &toolsetTool{tool: tool, spec: &spec, middleware: s.middleware}
+	if streaming, ok := tool.(StreamingTool); ok {
+		return &toolsetStreamingTool{toolsetTool: wrapped, streaming: streaming}
+	}
+	return wrapped
+}
+
+// toolsetTool is a tool renamed and wrapped by its toolset
+type toolsetTool struct {
+	tool       Tool
+	spec       *ToolSpec
+	middleware []Middleware
+}
+
+func (t *toolsetTool) Spec() *ToolSpec {
+	return t.spec
+}
+
+func (t *toolsetTool) Execute(ctx context.Context, params json.RawMessage) (*ToolResult, error) {
+	return t.handler(t.tool.Execute)(ctx, params)
+}
+
+// handler wraps next in the middleware chain
+func (t *toolsetTool) handler(next Handler) Handler {
+	for i := len(t.middleware) - 1; i >= 0; i-- {
+		next = t.middleware[i](t.spec, next)
+	}
+	return next
+}
+
+// toolsetStreamingTool keeps streaming tools streaming when wrapped
+type toolsetStreamingTool struct {
+	*toolsetTool
+	streaming StreamingTool
+}
+
+func (t *toolsetStreamingTool) ExecuteStream(ctx context.Context, params json.RawMessage, emit EmitFunc) (*ToolResult, error) {
+	return t.handler(func(ctx context.Context, params json.RawMessage) (*ToolResult, error) {
+		return t.streaming.ExecuteStream(ctx, params, emit)
+	})(ctx, params)
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestToolset(t *testing.T) {
+	read := NewTool("read", "Reads a file", func(ctx context.Context, in TestInput) (string, error) {
+		return "contents of " + in.Name, nil
+	})
+	spell := NewStreamingTool("spell", "Spells words", func(ctx context.Context, in streamInput, emit EmitFunc) error {
+		for _, word := range in.Words {
+			if err := emit(word); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	var calls []string
+	trace := func(label string) Middleware {
+		return func(spec *ToolSpec, next Handler) Handler {
+			return func(ctx context.Context, params json.RawMessage) (*ToolResult, error) {
+				calls = append(calls, label+":"+spec.Name)
+				return next(ctx, params)
+			}
+		}
+	}
+
+	set := NewToolset("fs", read).Add(spell).WithMiddleware(trace("outer"), trace("inner"))
+	if got := set.ToolNames(); !reflect.DeepEqual(got, []string{"fs.read", "fs.spell"}) {
+		t.Errorf("expected prefixed names, got %v", got)
+	}
+	if !set.Enabled() {
+		t.Error("expected toolsets to be enabled by default")
+	}
+
+	wrapped := set.Tools()
+	if err := Validate(wrapped[0]); err != nil {
+		t.Errorf("expected dotted name to be valid: %v", err)
+	}
+	if read.Spec().Name != "read" {
+		t.Errorf("expected the original tool to keep its name, got %q", read.Spec().Name)
+	}
+
+	result, err := wrapped[0].Execute(context.Background(), json.RawMessage(`{"name":"a.txt"}`))
+	if err != nil || result.Output != "contents of a.txt" {
+		t.Fatalf("expected wrapped tool output, got %+v, %v", result, err)
+	}
+	if want := []string{"outer:fs.read", "inner:fs.read"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("expected middleware order %v, got %v", want, calls)
+	}
+
+	streaming, ok := wrapped[1].(StreamingTool)
+	if !ok {
+		t.Fatal("expected wrapped streaming tool to stay streaming")
+	}
+	var chunks []string
+	calls = nil
+	result, err = streaming.ExecuteStream(context.Background(), json.RawMessage(`{"words":["a","b"]}`), func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil || result.Output != "ab" || len(chunks) != 2 || len(calls) != 2 {
+		t.Errorf("expected streamed chunks through middleware, got %v, %v, %+v, %v", chunks, calls, result, err)
+	}
+	if _, ok := wrapped[0].(StreamingTool); ok {
+		t.Error("expected non-streaming tools not to become streaming")
+	}
+}
+
+func TestToolset_Separator(t *testing.T) {
+	tool := NewTool("read", "Reads a file", testHandler)
+	set := NewToolset("fs", tool).WithSeparator("_").WithEnabled(false)
+
+	if got := set.Tools()[0].Spec().Name; got != "fs_read" {
+		t.Errorf("expected fs_read, got %q", got)
+	}
+	if set.Enabled() {
+		t.Error("expected WithEnabled(false) to disable the toolset")
+	}
+}
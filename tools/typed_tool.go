@@ -3,15 +3,18 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/mhpenta/minimcp/infer"
 	"github.com/mhpenta/minimcp/safeunmarshal"
 )
 
 type TypedTool[In, Out any] struct {
-	spec    *ToolSpec
-	handler func(context.Context, In) (Out, error)
+	spec      *ToolSpec
+	handler   func(context.Context, In) (Out, error)
+	validator *infer.Validator
 }
 
 func (t *TypedTool[In, Out]) Spec() *ToolSpec {
@@ -19,9 +22,13 @@ func (t *TypedTool[In, Out]) Spec() *ToolSpec {
 }
 
 func (t *TypedTool[In, Out]) Execute(ctx context.Context, params json.RawMessage) (*ToolResult, error) {
+	if err := validateInput(t.validator, t.spec, params); err != nil {
+		return nil, err
+	}
+
 	var input In
 	if len(params) > 0 {
-		parsedInput, err := safeunmarshal.To[In](params)
+		parsedInput, err := safeunmarshal.ToWithOptions[In](params, inputOptions(t.spec))
 		if err != nil {
 			return nil, NewInvalidParamsError(fmt.Sprintf("failed to parse parameters: %v", err))
 		}
@@ -31,10 +38,7 @@ func (t *TypedTool[In, Out]) Execute(ctx context.Context, params json.RawMessage
 	if err != nil {
 		return nil, err
 	}
-	return &ToolResult{
-		Output: result,
-		Error:  nil,
-	}, nil
+	return &ToolResult{Output: result}, nil
 }
 
 // ToolOption for functional configuration
@@ -58,6 +62,74 @@ func WithLongRunning(longRunning bool) ToolOption {
 	}
 }
 
+func WithDestructive(destructive bool) ToolOption {
+	return func(spec *ToolSpec) {
+		spec.Destructive = destructive
+	}
+}
+
+// WithSequential makes the server run one call of the tool at a time, queueing the rest
+func WithSequential(sequential bool) ToolOption {
+	return func(spec *ToolSpec) {
+		spec.Sequential = sequential
+	}
+}
+
+// WithResourceKey declares shared resources the tool uses. Calls to tools sharing a key
+// run one at a time, so tools writing to the same database or working tree do not
+// interleave.
+func WithResourceKey(keys ...string) ToolOption {
+	return func(spec *ToolSpec) {
+		spec.ResourceKeys = append(spec.ResourceKeys, keys...)
+	}
+}
+
+func WithTimeout(timeout time.Duration) ToolOption {
+	return func(spec *ToolSpec) {
+		spec.Timeout = timeout
+	}
+}
+
+// WithRequiredScopes restricts the tool to callers whose principal holds every listed scope.
+// Over HTTP, callers without a principal cannot use the tool. Transports that carry no
+// identity, such as stdio, are not restricted.
+func WithRequiredScopes(scopes ...string) ToolOption {
+	return func(spec *ToolSpec) {
+		spec.RequiredScopes = scopes
+	}
+}
+
+// WithPriority sets the tool's listing priority. Clients that present tools in list order
+// see higher-priority tools first; ties keep registration order.
+func WithPriority(priority int) ToolOption {
+	return func(spec *ToolSpec) {
+		spec.Priority = priority
+	}
+}
+
+// WithCategory assigns the tool to a category, used by per-client ranking profiles
+func WithCategory(category string) ToolOption {
+	return func(spec *ToolSpec) {
+		spec.Category = category
+	}
+}
+
+// WithInputValidation checks arguments against the tool's input schema before the handler
+// runs, so constraints the Go type cannot express, such as enum or minimum, are enforced
+func WithInputValidation(enabled bool) ToolOption {
+	return func(spec *ToolSpec) {
+		spec.ValidateInput = enabled
+	}
+}
+
+// WithStrictInput rejects malformed JSON arguments instead of repairing them. Without it,
+// arguments are repaired unless that would discard content.
+func WithStrictInput(enabled bool) ToolOption {
+	return func(spec *ToolSpec) {
+		spec.StrictInput = enabled
+	}
+}
+
 func WithCustomSchema(schema map[string]interface{}) ToolOption {
 	return func(spec *ToolSpec) {
 		spec.Parameters = schema
@@ -135,12 +207,91 @@ func NewToolWithError[In, Out any](
 		UI:          UI{},
 	}
 
-	for _, opt := range opts {
-		opt(spec)
+	if err := applyOptions(spec, opts); err != nil {
+		return nil, err
+	}
+
+	validator, err := compileInputValidator(spec)
+	if err != nil {
+		return nil, err
 	}
 
 	return &TypedTool[In, Out]{
-		spec:    spec,
-		handler: handler,
+		spec:      spec,
+		handler:   handler,
+		validator: validator,
 	}, nil
 }
+
+// applyOptions applies opts to spec, returning the first error an option recorded
+func applyOptions(spec *ToolSpec, opts []ToolOption) error {
+	for _, opt := range opts {
+		opt(spec)
+	}
+	return spec.optionErr
+}
+
+// compileInputValidator resolves the input schema when the spec asks for validation
+func compileInputValidator(spec *ToolSpec) (*infer.Validator, error) {
+	if !spec.ValidateInput || spec.Parameters == nil {
+		return nil, nil
+	}
+	validator, err := infer.Compile(spec.Parameters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile input schema for validation: %w", err)
+	}
+	return validator, nil
+}
+
+// inputOptions returns how a tool's arguments are unmarshalled: with repairs that keep
+// all of their content, or strictly
+func inputOptions(spec *ToolSpec) safeunmarshal.UnmarshalOptions {
+	if spec.StrictInput {
+		return safeunmarshal.StrictOptions()
+	}
+	return safeunmarshal.SafeRepairOptions()
+}
+
+// jsonArguments holds arguments as JSON. Unlike json.RawMessage it is not a slice, which
+// safeunmarshal would require to be a JSON array.
+type jsonArguments struct {
+	json.RawMessage
+}
+
+// repairedInput returns malformed params repaired as spec allows, so they are validated
+// as they will be unmarshalled. params are returned as is if they cannot be repaired, for
+// validation to reject them.
+func repairedInput(spec *ToolSpec, params json.RawMessage) json.RawMessage {
+	if spec.StrictInput || json.Valid(params) {
+		return params
+	}
+	var repaired jsonArguments
+	if err := safeunmarshal.IntoWithOptions(params, &repaired, inputOptions(spec)); err != nil {
+		return params
+	}
+	return repaired.RawMessage
+}
+
+// validateInput checks params against validator, if any, reporting a violation as
+// InvalidParams with the offending field in Data. Missing arguments are checked as an
+// empty object, and malformed ones as spec repairs them.
+func validateInput(validator *infer.Validator, spec *ToolSpec, params json.RawMessage) error {
+	if validator == nil {
+		return nil
+	}
+	if len(params) == 0 || string(params) == "null" {
+		params = json.RawMessage("{}")
+	}
+	params = repairedInput(spec, params)
+
+	err := validator.Validate(params)
+	if err == nil {
+		return nil
+	}
+	invalid := NewInvalidParamsError(fmt.Sprintf("invalid arguments: %v", err))
+	var violation *infer.ValidationError
+	if errors.As(err, &violation) {
+		invalid.Data = map[string]interface{}{"errors": []*infer.ValidationError{violation}}
+	}
+	return invalid
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 )
 
@@ -236,6 +237,30 @@ func TestTypedTool_Execute_MalformedJSON(t *testing.T) {
 	}
 }
 
+func TestTypedTool_WithStrictInput(t *testing.T) {
+	repairing := NewTool("test_tool", "A test tool", testHandler)
+	strict := NewTool("test_tool", "A test tool", testHandler, WithStrictInput(true))
+	malformed := json.RawMessage(`{'name': 'test', value: 42,}`)
+
+	if _, err := strict.Execute(context.Background(), malformed); err == nil {
+		t.Error("expected malformed arguments to fail with WithStrictInput")
+	}
+	result, err := repairing.Execute(context.Background(), malformed)
+	if err != nil {
+		t.Fatalf("Execute with repairable arguments returned error: %v", err)
+	}
+	if output := result.Output.(TestOutput); output.Result != "processed: test" {
+		t.Errorf("Expected result 'processed: test', got %q", output.Result)
+	}
+
+	// Repairs that would drop content are refused
+	_, err = repairing.Execute(context.Background(), json.RawMessage(`{"name": "test", "value": }`))
+	var toolErr *Error
+	if !errors.As(err, &toolErr) || toolErr.Code != CodeInvalidParams {
+		t.Errorf("Expected InvalidParams for a lossy repair, got %v", err)
+	}
+}
+
 func TestWithCustomSchema(t *testing.T) {
 	customSchema := map[string]interface{}{
 		"type": "object",
@@ -272,3 +297,62 @@ func TestWithCustomSchema(t *testing.T) {
 		t.Error("Custom schema should include 'custom_field'")
 	}
 }
+
+func TestTypedTool_InputValidation(t *testing.T) {
+	called := false
+	handler := func(ctx context.Context, input TestInput) (TestOutput, error) {
+		called = true
+		return TestOutput{Success: true}, nil
+	}
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name":  map[string]interface{}{"type": "string", "enum": []interface{}{"ada", "grace"}},
+			"value": map[string]interface{}{"type": "integer", "minimum": 1},
+		},
+		"required": []interface{}{"name"},
+	}
+	tool := NewTool("validated", "Validates its input", handler, WithCustomSchema(schema), WithInputValidation(true))
+
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"name":"bob","value":1}`))
+	var toolErr *Error
+	if !errors.As(err, &toolErr) || toolErr.Code != CodeInvalidParams {
+		t.Fatalf("expected InvalidParams, got %v", err)
+	}
+	details, _ := json.Marshal(toolErr.Data)
+	if want := `"field":"name"`; !strings.Contains(string(details), want) {
+		t.Errorf("expected field-level details containing %s, got %s", want, details)
+	}
+
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"name":"ada","value":0}`)); err == nil {
+		t.Error("expected minimum to be enforced")
+	}
+	if _, err := tool.Execute(context.Background(), nil); err == nil {
+		t.Error("expected missing arguments to fail the required check")
+	}
+	if called {
+		t.Error("expected the handler not to run for invalid arguments")
+	}
+
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"name":"ada","value":2}`)); err != nil || !called {
+		t.Errorf("expected valid arguments to reach the handler, got %v", err)
+	}
+
+	// Validation is opt-in
+	unvalidated := NewTool("unvalidated", "Skips validation", handler, WithCustomSchema(schema))
+	if _, err := unvalidated.Execute(context.Background(), json.RawMessage(`{"name":"bob"}`)); err != nil {
+		t.Errorf("expected no validation by default, got %v", err)
+	}
+}
+
+func TestNewTool_DescriptionTags(t *testing.T) {
+	type input struct {
+		City string `json:"city" description:"city name, e.g. Paris"`
+	}
+	tool := NewTool("weather", "Gets weather", func(ctx context.Context, in input) (string, error) { return "", nil })
+
+	city := tool.Spec().Parameters["properties"].(map[string]interface{})["city"].(map[string]interface{})
+	if city["description"] != "city name, e.g. Paris" {
+		t.Errorf("expected the description tag in the input schema, got %v", city)
+	}
+}
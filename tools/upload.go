@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrUploadNotFound is returned when an upload handle is unknown, expired, or owned by another caller
+var ErrUploadNotFound = errors.New("upload not found")
+
+// UploadHandle references a large input that the client uploaded out-of-band instead of
+// inlining it in tools/call arguments. Use it as an argument field type and read the
+// content with OpenUpload:
+//
+//	type SummarizeInput struct {
+//	    Document tools.UploadHandle `json:"document" jsonschema:"handle returned by the upload endpoint"`
+//	}
+//
+//	func summarize(ctx context.Context, in SummarizeInput) (string, error) {
+//	    r, err := tools.OpenUpload(ctx, in.Document)
+//	    if err != nil {
+//	        return "", err
+//	    }
+//	    defer r.Close()
+//	    ...
+//	}
+type UploadHandle string
+
+// UploadOpener resolves upload handles to their content
+type UploadOpener interface {
+	OpenUpload(ctx context.Context, handle UploadHandle) (io.ReadCloser, error)
+}
+
+type uploadsKey struct{}
+
+// WithUploads returns a context through which tools can open uploads
+func WithUploads(ctx context.Context, opener UploadOpener) context.Context {
+	return context.WithValue(ctx, uploadsKey{}, opener)
+}
+
+// OpenUpload opens the content of an uploaded input for reading. The caller must close it.
+func OpenUpload(ctx context.Context, handle UploadHandle) (io.ReadCloser, error) {
+	opener, ok := ctx.Value(uploadsKey{}).(UploadOpener)
+	if !ok || opener == nil {
+		return nil, ErrUploadNotFound
+	}
+	return opener.OpenUpload(ctx, handle)
+}
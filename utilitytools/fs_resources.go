@@ -0,0 +1,321 @@
+package utilitytools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mhpenta/minimcp/mcp"
+)
+
+// Defaults for DirResourceConfig
+const (
+	DefaultDirResourceMaxFileBytes = 10 << 20
+	DefaultDirResourcePollInterval = 2 * time.Second
+)
+
+// DirResourceConfig configures NewDirResourceProvider
+type DirResourceConfig struct {
+	// Root is the directory whose files are served
+	Root string
+
+	// BaseURI prefixes each file's slash-separated path relative to Root, such as
+	// "docs://" for docs://guide/intro.md (default the file:// URL of Root)
+	BaseURI string
+
+	// Include limits the files served to those matching one of the globs, and Exclude
+	// drops files matching one of its globs. Globs match the path relative to Root, with
+	// ** for any number of directories, or the file name when they have no slash.
+	Include []string
+	Exclude []string
+
+	MaxFileBytes int64         // larger files are not served (default 10 MiB)
+	PollInterval time.Duration // how often Watch checks for changes (default 2s)
+
+	Logger *slog.Logger
+}
+
+// ResourceNotifier receives resource changes. *mcp.Server implements it.
+type ResourceNotifier interface {
+	NotifyResourceUpdated(uri string)
+	NotifyResourceListChanged()
+}
+
+// DirResourceProvider serves the files of a directory tree as MCP resources. Text files
+// are read as text and others as blobs, with MIME types from the file extension or, failing
+// that, the content. Symbolic links are not followed out of the root.
+type DirResourceProvider struct {
+	cfg     DirResourceConfig
+	root    string
+	baseURI string
+}
+
+// NewDirResourceProvider creates a provider serving the files under cfg.Root. Register it
+// with mcp.ServerConfig.ResourceProviders, and run Watch to notify clients of changes.
+//
+// Example:
+//
+//	docs, err := utilitytools.NewDirResourceProvider(utilitytools.DirResourceConfig{
+//	    Root:    "/srv/docs",
+//	    BaseURI: "docs://",
+//	    Include: []string{"**/*.md"},
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	server := mcp.NewServer(mcp.ServerConfig{Name: "docs", ResourceProviders: []mcp.ResourceProvider{docs}})
+//	go docs.Watch(ctx, server)
+func NewDirResourceProvider(cfg DirResourceConfig) (*DirResourceProvider, error) {
+	if cfg.Root == "" {
+		return nil, errors.New("dir resource provider: no Root")
+	}
+	abs, err := filepath.Abs(cfg.Root)
+	if err != nil {
+		return nil, fmt.Errorf("root %q: %w", cfg.Root, err)
+	}
+	// Compare against the real location, so symbolic links cannot lead out of the root
+	root, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, fmt.Errorf("root %q: %w", cfg.Root, err)
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("root %q is not a directory", cfg.Root)
+	}
+	for _, glob := range append(append([]string(nil), cfg.Include...), cfg.Exclude...) {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", glob, err)
+		}
+	}
+	if cfg.MaxFileBytes <= 0 {
+		cfg.MaxFileBytes = DefaultDirResourceMaxFileBytes
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultDirResourcePollInterval
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	p := &DirResourceProvider{cfg: cfg, root: root, baseURI: cfg.BaseURI}
+	if p.baseURI == "" {
+		p.baseURI = (&url.URL{Scheme: "file", Path: filepath.ToSlash(root) + "/"}).String()
+	}
+	return p, nil
+}
+
+// ListResources implements mcp.ResourceProvider
+func (p *DirResourceProvider) ListResources(ctx context.Context) ([]mcp.Resource, error) {
+	var resources []mcp.Resource
+	err := p.walk(ctx, func(rel string, info fs.FileInfo) {
+		resources = append(resources, mcp.Resource{
+			URI:      p.uri(rel),
+			Name:     rel,
+			MimeType: mime.TypeByExtension(path.Ext(rel)),
+		})
+	})
+	return resources, err
+}
+
+// ReadResource implements mcp.ResourceProvider
+func (p *DirResourceProvider) ReadResource(ctx context.Context, uri string) ([]mcp.ResourceContents, error) {
+	rel, ok := p.relPath(uri)
+	if !ok || !p.included(rel) {
+		return nil, mcp.ErrResourceNotFound
+	}
+	real, err := filepath.EvalSymlinks(filepath.Join(p.root, filepath.FromSlash(rel)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, mcp.ErrResourceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := within(p.root, real); !ok {
+		return nil, mcp.ErrResourceNotFound
+	}
+	file, err := os.Open(real)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() || info.Size() > p.cfg.MaxFileBytes {
+		return nil, mcp.ErrResourceNotFound
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+	mimeType := mime.TypeByExtension(path.Ext(rel))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(head)
+	}
+
+	if isTextMimeType(mimeType) {
+		data, err := os.ReadFile(real)
+		if err != nil {
+			return nil, err
+		}
+		if utf8.Valid(data) {
+			return []mcp.ResourceContents{{URI: uri, MimeType: mimeType, Text: string(data)}}, nil
+		}
+	}
+	// Binary files are read in the range the client asked for
+	contents, err := mcp.ReadBlob(ctx, file, info.Size(), mimeType)
+	if err != nil {
+		return nil, err
+	}
+	contents.URI = uri
+	return []mcp.ResourceContents{contents}, nil
+}
+
+// Watch polls the directory every PollInterval until ctx is done, telling notifier which
+// files changed and when files were added or removed. Run it in a goroutine.
+func (p *DirResourceProvider) Watch(ctx context.Context, notifier ResourceNotifier) {
+	ticker := time.NewTicker(p.cfg.PollInterval)
+	defer ticker.Stop()
+
+	files := p.snapshot(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			files = p.poll(ctx, files, notifier)
+		}
+	}
+}
+
+// fileStamp identifies a version of a file
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func (p *DirResourceProvider) snapshot(ctx context.Context) map[string]fileStamp {
+	files := make(map[string]fileStamp)
+	if err := p.walk(ctx, func(rel string, info fs.FileInfo) {
+		files[rel] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}); err != nil && ctx.Err() == nil {
+		p.cfg.Logger.Warn("scanning resource directory failed", "root", p.root, "error", err)
+	}
+	return files
+}
+
+// poll compares the directory with the previous snapshot, notifying of changes, and
+// returns the new snapshot
+func (p *DirResourceProvider) poll(ctx context.Context, previous map[string]fileStamp, notifier ResourceNotifier) map[string]fileStamp {
+	current := p.snapshot(ctx)
+	if ctx.Err() != nil {
+		return previous
+	}
+	listChanged := len(current) != len(previous)
+	var updated []string
+	for rel, stamp := range current {
+		old, ok := previous[rel]
+		switch {
+		case !ok:
+			listChanged = true
+		case !old.modTime.Equal(stamp.modTime) || old.size != stamp.size:
+			updated = append(updated, rel)
+		}
+	}
+	sort.Strings(updated)
+	for _, rel := range updated {
+		notifier.NotifyResourceUpdated(p.uri(rel))
+	}
+	if listChanged {
+		p.cfg.Logger.Debug("resource directory changed", "root", p.root, "files", len(current))
+		notifier.NotifyResourceListChanged()
+	}
+	return current
+}
+
+// walk calls fn for each file served, in lexical order
+func (p *DirResourceProvider) walk(ctx context.Context, fn func(rel string, info fs.FileInfo)) error {
+	return walkFiles(ctx, p.root, func(file string, d fs.DirEntry) bool {
+		rel, ok := within(p.root, file)
+		if !ok {
+			return true
+		}
+		rel = filepath.ToSlash(rel)
+		if !p.included(rel) {
+			return true
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() > p.cfg.MaxFileBytes {
+			return true
+		}
+		fn(rel, info)
+		return true
+	})
+}
+
+// included reports whether the globs admit a file. Files under .git never are, as
+// walkFiles skips them.
+func (p *DirResourceProvider) included(rel string) bool {
+	if rel == ".git" || strings.HasPrefix(rel, ".git/") || strings.Contains(rel, "/.git/") {
+		return false
+	}
+	if len(p.cfg.Include) > 0 && !matchAnyGlob(p.cfg.Include, rel) {
+		return false
+	}
+	return !matchAnyGlob(p.cfg.Exclude, rel)
+}
+
+// uri returns the URI of a file, escaping each path element
+func (p *DirResourceProvider) uri(rel string) string {
+	parts := strings.Split(rel, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return p.baseURI + strings.Join(parts, "/")
+}
+
+// relPath returns the path relative to the root that a URI names, and whether it names
+// a path inside the root
+func (p *DirResourceProvider) relPath(uri string) (string, bool) {
+	escaped, ok := strings.CutPrefix(uri, p.baseURI)
+	if !ok {
+		return "", false
+	}
+	rel, err := url.PathUnescape(escaped)
+	if err != nil || rel == "" || path.Clean(rel) != rel || path.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return rel, true
+}
+
+// isTextMimeType reports whether a MIME type names text
+func isTextMimeType(mimeType string) bool {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript", "application/yaml", "application/x-yaml", "application/toml", "application/sql":
+		return true
+	}
+	return false
+}
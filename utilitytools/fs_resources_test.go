@@ -0,0 +1,139 @@
+package utilitytools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/mcp"
+)
+
+// recordingNotifier records the resource changes it is told about
+type recordingNotifier struct {
+	updated     []string
+	listChanged int
+}
+
+func (n *recordingNotifier) NotifyResourceUpdated(uri string) { n.updated = append(n.updated, uri) }
+func (n *recordingNotifier) NotifyResourceListChanged()       { n.listChanged++ }
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDirResourceProvider(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"guide/intro.md":   "# Intro",
+		"notes/todo.txt":   "ship it",
+		"my notes/a b.txt": "spaced",
+		"logo.bin":         "\x00\x01\x02binary",
+		"big.txt":          strings.Repeat("x", 100),
+		"debug.log":        "noise",
+		".git/config":      "[core]",
+	})
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	writeFiles(t, filepath.Dir(outside), map[string]string{"secret.txt": "secret"})
+	if err := os.Symlink(outside, filepath.Join(dir, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := NewDirResourceProvider(DirResourceConfig{
+		Root:         dir,
+		BaseURI:      "docs://",
+		Exclude:      []string{"*.log"},
+		MaxFileBytes: 50,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resources, err := provider.ListResources(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var uris []string
+	for _, r := range resources {
+		uris = append(uris, r.URI)
+	}
+	want := []string{"docs://guide/intro.md", "docs://logo.bin", "docs://my%20notes/a%20b.txt", "docs://notes/todo.txt"}
+	if !reflect.DeepEqual(uris, want) {
+		t.Errorf("uris = %v, want %v", uris, want)
+	}
+
+	server := mcp.NewServer(mcp.ServerConfig{Name: "test-server", ResourceProviders: []mcp.ResourceProvider{provider}})
+	contents, err := server.ReadResource(context.Background(), "docs://my%20notes/a%20b.txt")
+	if err != nil || contents[0].Text != "spaced" || !strings.HasPrefix(contents[0].MimeType, "text/plain") {
+		t.Errorf("text read = %+v, %v", contents, err)
+	}
+	contents, err = server.ReadResource(context.Background(), "docs://logo.bin")
+	if err != nil || string(contents[0].Blob) != "\x00\x01\x02binary" || contents[0].Text != "" {
+		t.Errorf("binary read = %+v, %v", contents, err)
+	}
+
+	for _, uri := range []string{"docs://big.txt", "docs://debug.log", "docs://.git/config", "docs://link.txt", "docs://../secret.txt", "docs://guide", "docs://missing.md", "other://guide/intro.md"} {
+		if _, err := provider.ReadResource(context.Background(), uri); err != mcp.ErrResourceNotFound {
+			t.Errorf("%s: expected ErrResourceNotFound, got %v", uri, err)
+		}
+	}
+
+	// The default base URI is the root's file URL
+	provider, err = NewDirResourceProvider(DirResourceConfig{Root: dir, Include: []string{"guide/**"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources, _ = provider.ListResources(context.Background())
+	if len(resources) != 1 || !strings.HasPrefix(resources[0].URI, "file:///") || !strings.HasSuffix(resources[0].URI, "/guide/intro.md") {
+		t.Errorf("resources = %+v", resources)
+	}
+
+	if _, err := NewDirResourceProvider(DirResourceConfig{Root: filepath.Join(dir, "guide/intro.md")}); err == nil {
+		t.Error("expected a file root to fail")
+	}
+}
+
+func TestDirResourceProvider_Poll(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"a.txt": "one", "b.txt": "two"})
+	provider, err := NewDirResourceProvider(DirResourceConfig{Root: dir, BaseURI: "files://"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	files := provider.snapshot(ctx)
+
+	notifier := &recordingNotifier{}
+	if files = provider.poll(ctx, files, notifier); len(notifier.updated) != 0 || notifier.listChanged != 0 {
+		t.Errorf("expected no changes, got %+v", notifier)
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(dir, "a.txt"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	files = provider.poll(ctx, files, notifier)
+	if !reflect.DeepEqual(notifier.updated, []string{"files://a.txt"}) || notifier.listChanged != 0 {
+		t.Errorf("after modifying a.txt: %+v", notifier)
+	}
+
+	notifier = &recordingNotifier{}
+	os.Remove(filepath.Join(dir, "b.txt"))
+	writeFiles(t, dir, map[string]string{"c.txt": "three"})
+	provider.poll(ctx, files, notifier)
+	if len(notifier.updated) != 0 || notifier.listChanged != 1 {
+		t.Errorf("after replacing b.txt with c.txt: %+v", notifier)
+	}
+}
@@ -0,0 +1,448 @@
+package utilitytools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// Defaults for FSConfig limits
+const (
+	DefaultFSMaxReadBytes     = 1 << 20
+	DefaultFSMaxWriteBytes    = 1 << 20
+	DefaultFSMaxSearchResults = 100
+)
+
+// FSConfig configures the filesystem toolset
+type FSConfig struct {
+	// Roots are the directories the tools may access. Relative paths are resolved against
+	// the first root; absolute paths must fall inside one of them.
+	Roots []string
+
+	// ReadOnly leaves out write_file
+	ReadOnly bool
+
+	MaxReadBytes     int64 // bytes read_file returns and search scans per file (default 1 MiB)
+	MaxWriteBytes    int64 // largest content write_file accepts (default 1 MiB)
+	MaxSearchResults int   // matches search returns (default 100)
+
+	Logger *slog.Logger
+}
+
+// NewFSToolset creates the "fs" toolset of read_file, write_file, list_dir, stat, and
+// search, confined to cfg.Roots. Paths that leave the roots, directly or through a
+// symbolic link, are rejected as invalid params.
+//
+// Example:
+//
+//	fsTools, err := utilitytools.NewFSToolset(utilitytools.FSConfig{
+//	    Roots:    []string{"/srv/docs"},
+//	    ReadOnly: true,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	server := mcp.NewServer(mcp.ServerConfig{
+//	    Name:     "docs",
+//	    Toolsets: []*tools.Toolset{fsTools}, // lists "fs.read_file", "fs.list_dir", ...
+//	})
+func NewFSToolset(cfg FSConfig) (*tools.Toolset, error) {
+	if len(cfg.Roots) == 0 {
+		return nil, errors.New("fs toolset: no roots configured")
+	}
+	if cfg.MaxReadBytes <= 0 {
+		cfg.MaxReadBytes = DefaultFSMaxReadBytes
+	}
+	if cfg.MaxWriteBytes <= 0 {
+		cfg.MaxWriteBytes = DefaultFSMaxWriteBytes
+	}
+	if cfg.MaxSearchResults <= 0 {
+		cfg.MaxSearchResults = DefaultFSMaxSearchResults
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	sandbox, err := newFSSandbox(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("fs toolset: %w", err)
+	}
+
+	set := tools.NewToolset("fs",
+		tools.NewTool("read_file", "Reads a text file. Large files are returned in parts: pass the returned next_offset as offset to continue.", sandbox.readFile,
+			tools.WithCategory("files"), tools.WithVerb("Reading file")),
+		tools.NewTool("list_dir", "Lists the entries of a directory", sandbox.listDir,
+			tools.WithCategory("files"), tools.WithVerb("Listing directory")),
+		tools.NewTool("stat", "Describes a file or directory: type, size, permissions, and modification time", sandbox.stat,
+			tools.WithCategory("files")),
+		tools.NewTool("search", "Searches files under a directory for lines matching a regular expression", sandbox.search,
+			tools.WithCategory("files"), tools.WithVerb("Searching files")),
+	)
+	if !cfg.ReadOnly {
+		set.Add(tools.NewTool("write_file", "Creates or replaces a file with the given content", sandbox.writeFile,
+			tools.WithCategory("files"), tools.WithVerb("Writing file"), tools.WithDestructive(true)))
+	}
+	return set, nil
+}
+
+// FSPathParams names a file or directory
+type FSPathParams struct {
+	Path string `json:"path" jsonschema:"file or directory path, relative to the first allowed directory or absolute"`
+}
+
+// FSReadParams defines parameters for read_file
+type FSReadParams struct {
+	Path   string `json:"path" jsonschema:"file path, relative to the first allowed directory or absolute"`
+	Offset int64  `json:"offset,omitempty" jsonschema:"byte offset to start reading from"`
+}
+
+// FSReadResult is the content read by read_file
+type FSReadResult struct {
+	Path       string `json:"path"`
+	Content    string `json:"content"`
+	Size       int64  `json:"size"`
+	NextOffset int64  `json:"next_offset,omitempty"` // set when the file continues past the returned content
+}
+
+// FSWriteParams defines parameters for write_file
+type FSWriteParams struct {
+	Path       string `json:"path" jsonschema:"file path, relative to the first allowed directory or absolute"`
+	Content    string `json:"content" jsonschema:"complete new content of the file"`
+	CreateDirs bool   `json:"create_dirs,omitempty" jsonschema:"create missing parent directories"`
+}
+
+// FSEntry describes a file or directory
+type FSEntry struct {
+	Name    string    `json:"name"`
+	Type    string    `json:"type"` // "file", "dir", or "symlink"
+	Size    int64     `json:"size"`
+	Mode    string    `json:"mode,omitempty"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// FSSearchParams defines parameters for search
+type FSSearchParams struct {
+	Pattern string `json:"pattern" jsonschema:"regular expression (RE2 syntax) to search for"`
+	Path    string `json:"path,omitempty" jsonschema:"directory to search, defaults to the first allowed directory"`
+	Glob    string `json:"glob,omitempty" jsonschema:"only search files whose name matches this glob, such as *.go"`
+}
+
+// FSSearchMatch is a line matching a search
+type FSSearchMatch struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// FSSearchResult holds the matches of a search
+type FSSearchResult struct {
+	Matches   []FSSearchMatch `json:"matches"`
+	Truncated bool            `json:"truncated,omitempty"` // more lines matched than were returned
+}
+
+// fsSandbox resolves tool paths inside the configured roots
+type fsSandbox struct {
+	cfg   FSConfig
+	roots []string
+}
+
+// newFSSandbox confines paths to cfg.Roots
+func newFSSandbox(cfg FSConfig) (*fsSandbox, error) {
+	sandbox := &fsSandbox{cfg: cfg}
+	for _, root := range cfg.Roots {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("root %q: %w", root, err)
+		}
+		// Compare against the real location, so symbolic links cannot lead out of a root
+		real, err := filepath.EvalSymlinks(abs)
+		if err != nil {
+			return nil, fmt.Errorf("root %q: %w", root, err)
+		}
+		sandbox.roots = append(sandbox.roots, real)
+	}
+	return sandbox, nil
+}
+
+// resolve maps a requested path to a real path inside a root. Missing trailing elements
+// are allowed, so files and directories can be created.
+func (s *fsSandbox) resolve(requested string) (string, error) {
+	path := requested
+	if path == "" {
+		path = "."
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.roots[0], path)
+	}
+	path = filepath.Clean(path)
+
+	// Resolve the longest existing ancestor, so a symbolic link anywhere in the path is
+	// followed before the path is checked
+	existing, missing := path, ""
+	real, err := filepath.EvalSymlinks(existing)
+	for errors.Is(err, fs.ErrNotExist) {
+		if _, lerr := os.Lstat(existing); lerr == nil {
+			// A dangling symbolic link would be followed on write
+			return "", tools.NewInvalidParamsError(fmt.Sprintf("path %q is a broken symbolic link", requested))
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		missing = filepath.Join(filepath.Base(existing), missing)
+		existing = parent
+		real, err = filepath.EvalSymlinks(existing)
+	}
+	if err != nil {
+		return "", s.pathError(path, err)
+	}
+	real = filepath.Join(real, missing)
+
+	if !s.allowed(real) {
+		return "", tools.NewInvalidParamsError(fmt.Sprintf("path %q is outside the allowed directories", requested))
+	}
+	return real, nil
+}
+
+// allowed reports whether path is a root or inside one
+func (s *fsSandbox) allowed(path string) bool {
+	for _, root := range s.roots {
+		if _, ok := within(root, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// display returns a path inside the first root relative to it, so results do not reveal
+// where the root lives. Paths in other roots are returned as they are.
+func (s *fsSandbox) display(path string) string {
+	if rel, ok := within(s.roots[0], path); ok {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// within returns path relative to root, and whether it is inside root
+func within(root, path string) (string, bool) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// pathError reports a filesystem error against the displayed path
+func (s *fsSandbox) pathError(path string, err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return fmt.Errorf("%s: %w", s.display(path), err)
+}
+
+func (s *fsSandbox) readFile(ctx context.Context, params FSReadParams) (*FSReadResult, error) {
+	path, err := s.resolve(params.Path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, s.pathError(path, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, s.pathError(path, err)
+	}
+	if info.IsDir() {
+		return nil, tools.NewInvalidParamsError(fmt.Sprintf("%s is a directory; use list_dir", s.display(path)))
+	}
+	if params.Offset < 0 || params.Offset > info.Size() {
+		return nil, tools.NewInvalidParamsError(fmt.Sprintf("offset %d is outside the file's %d bytes", params.Offset, info.Size()))
+	}
+
+	data, err := io.ReadAll(io.NewSectionReader(f, params.Offset, s.cfg.MaxReadBytes))
+	if err != nil {
+		return nil, s.pathError(path, err)
+	}
+	result := &FSReadResult{Path: s.display(path), Content: string(data), Size: info.Size()}
+	if end := params.Offset + int64(len(data)); end < info.Size() {
+		result.NextOffset = end
+	}
+	return result, nil
+}
+
+func (s *fsSandbox) writeFile(ctx context.Context, params FSWriteParams) (string, error) {
+	if int64(len(params.Content)) > s.cfg.MaxWriteBytes {
+		return "", tools.NewInvalidParamsError(fmt.Sprintf("content is %d bytes, over the %d byte limit", len(params.Content), s.cfg.MaxWriteBytes))
+	}
+	path, err := s.resolve(params.Path)
+	if err != nil {
+		return "", err
+	}
+	if params.CreateDirs {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return "", s.pathError(path, err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(params.Content), 0o644); err != nil {
+		return "", s.pathError(path, err)
+	}
+
+	s.cfg.Logger.Info("file written", "path", path, "bytes", len(params.Content))
+	return fmt.Sprintf("wrote %d bytes to %s", len(params.Content), s.display(path)), nil
+}
+
+func (s *fsSandbox) listDir(ctx context.Context, params FSPathParams) ([]FSEntry, error) {
+	path, err := s.resolve(params.Path)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, s.pathError(path, err)
+	}
+
+	list := make([]FSEntry, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			// Removed since the directory was read
+			continue
+		}
+		list = append(list, describeEntry(entry.Name(), info, false))
+	}
+	return list, nil
+}
+
+func (s *fsSandbox) stat(ctx context.Context, params FSPathParams) (*FSEntry, error) {
+	path, err := s.resolve(params.Path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, s.pathError(path, err)
+	}
+	entry := describeEntry(s.display(path), info, true)
+	return &entry, nil
+}
+
+func (s *fsSandbox) search(ctx context.Context, params FSSearchParams) (*FSSearchResult, error) {
+	pattern, err := regexp.Compile(params.Pattern)
+	if err != nil {
+		return nil, tools.NewInvalidParamsError(fmt.Sprintf("invalid pattern: %v", err))
+	}
+	if params.Glob != "" {
+		if _, err := filepath.Match(params.Glob, ""); err != nil {
+			return nil, tools.NewInvalidParamsError(fmt.Sprintf("invalid glob: %v", err))
+		}
+	}
+	dir, err := s.resolve(params.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FSSearchResult{Matches: []FSSearchMatch{}}
+	err = walkFiles(ctx, dir, func(path string, d fs.DirEntry) bool {
+		if params.Glob != "" {
+			if ok, _ := filepath.Match(params.Glob, d.Name()); !ok {
+				return true
+			}
+		}
+		return !s.searchFile(path, pattern, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// walkFiles calls fn for each regular file under dir, skipping .git directories and
+// unreadable entries, until fn returns false
+func walkFiles(ctx context.Context, dir string, fn func(path string, d fs.DirEntry) bool) error {
+	errDone := errors.New("walk stopped")
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip unreadable entries rather than failing the search
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// WalkDir does not follow symbolic links, so only regular files are searched
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if !fn(path, d) {
+			return errDone
+		}
+		return nil
+	})
+	if err == errDone {
+		return nil
+	}
+	return err
+}
+
+// searchFile appends the matching lines of a text file to result, and reports whether
+// the result is full. Binary files and files over the read limit are skipped.
+func (s *fsSandbox) searchFile(path string, pattern *regexp.Regexp, result *FSSearchResult) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > s.cfg.MaxReadBytes {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || bytes.IndexByte(data, 0) >= 0 {
+		return false
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		if !pattern.Match(scanner.Bytes()) {
+			continue
+		}
+		if len(result.Matches) == s.cfg.MaxSearchResults {
+			result.Truncated = true
+			return true
+		}
+		result.Matches = append(result.Matches, FSSearchMatch{Path: s.display(path), Line: line, Text: scanner.Text()})
+	}
+	return false
+}
+
+// describeEntry converts file info to an FSEntry; withMode adds the permissions
+func describeEntry(name string, info fs.FileInfo, withMode bool) FSEntry {
+	entry := FSEntry{Name: name, Type: "file", Size: info.Size(), ModTime: info.ModTime().UTC()}
+	switch {
+	case info.IsDir():
+		entry.Type = "dir"
+	case info.Mode()&fs.ModeSymlink != 0:
+		entry.Type = "symlink"
+	}
+	if withMode {
+		entry.Mode = info.Mode().String()
+	}
+	return entry
+}
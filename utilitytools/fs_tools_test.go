@@ -0,0 +1,156 @@
+package utilitytools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// newFSTools builds the toolset over a fresh root and returns its tools by unprefixed name
+func newFSTools(t *testing.T, cfg FSConfig) (string, map[string]tools.Tool) {
+	t.Helper()
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "docs"), 0o755)
+	os.WriteFile(filepath.Join(root, "docs", "guide.md"), []byte("# Guide\ninstall with go get\nrun go test\n"), 0o644)
+	os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\n// go run .\n"), 0o644)
+
+	cfg.Roots = append([]string{root}, cfg.Roots...)
+	cfg.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	set, err := NewFSToolset(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]tools.Tool)
+	for _, tool := range set.Tools() {
+		byName[strings.TrimPrefix(tool.Spec().Name, "fs.")] = tool
+	}
+	return root, byName
+}
+
+// run executes a tool and decodes its output into out
+func run(t *testing.T, tool tools.Tool, args string, out interface{}) error {
+	t.Helper()
+	result, err := tool.Execute(context.Background(), json.RawMessage(args))
+	if err != nil {
+		return err
+	}
+	data, _ := json.Marshal(result.Output)
+	return json.Unmarshal(data, out)
+}
+
+func isInvalidParams(err error) bool {
+	var toolErr *tools.Error
+	return errors.As(err, &toolErr) && toolErr.Code == tools.CodeInvalidParams
+}
+
+func TestFSToolset_Confinement(t *testing.T) {
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "secret"), []byte("token"), 0o600)
+	root, fsTools := newFSTools(t, FSConfig{})
+	os.Symlink(outside, filepath.Join(root, "escape"))
+	os.Symlink(filepath.Join(outside, "missing"), filepath.Join(root, "dangling"))
+
+	var out interface{}
+	tests := []struct {
+		tool, args string
+	}{
+		{"read_file", `{"path":"../` + filepath.Base(outside) + `/secret"}`},
+		{"read_file", `{"path":"` + filepath.Join(outside, "secret") + `"}`},
+		{"read_file", `{"path":"escape/secret"}`},
+		{"list_dir", `{"path":"escape"}`},
+		{"write_file", `{"path":"escape/new/file","content":"x","create_dirs":true}`},
+		{"write_file", `{"path":"dangling","content":"x"}`},
+		{"search", `{"pattern":"token","path":"escape"}`},
+	}
+	for _, tt := range tests {
+		if err := run(t, fsTools[tt.tool], tt.args, &out); !isInvalidParams(err) {
+			t.Errorf("%s %s: expected the path to be rejected, got %v", tt.tool, tt.args, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outside, "new")); err == nil {
+		t.Error("expected nothing to be created outside the root")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "missing")); err == nil {
+		t.Error("expected the dangling link not to be followed")
+	}
+}
+
+func TestFSToolset_ReadWrite(t *testing.T) {
+	root, fsTools := newFSTools(t, FSConfig{MaxReadBytes: 10, MaxWriteBytes: 32})
+
+	var read FSReadResult
+	if err := run(t, fsTools["read_file"], `{"path":"docs/guide.md"}`, &read); err != nil {
+		t.Fatal(err)
+	}
+	if read.Content != "# Guide\nin" || read.NextOffset != 10 || read.Size != 40 || read.Path != "docs/guide.md" {
+		t.Errorf("expected the first part of the file, got %+v", read)
+	}
+	var rest FSReadResult
+	if err := run(t, fsTools["read_file"], `{"path":"docs/guide.md","offset":35}`, &rest); err != nil || rest.Content != "test\n" || rest.NextOffset != 0 {
+		t.Errorf("expected the end of the file, got %+v, %v", rest, err)
+	}
+
+	var message string
+	if err := run(t, fsTools["write_file"], `{"path":"out/notes.txt","content":"hello","create_dirs":true}`, &message); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "out", "notes.txt")); string(data) != "hello" {
+		t.Errorf("expected the file to be written, got %q", data)
+	}
+	if err := run(t, fsTools["write_file"], `{"path":"big.txt","content":"`+strings.Repeat("x", 33)+`"}`, &message); !isInvalidParams(err) {
+		t.Errorf("expected content over the limit to be rejected, got %v", err)
+	}
+
+	var entry FSEntry
+	if err := run(t, fsTools["stat"], `{"path":"out/notes.txt"}`, &entry); err != nil || entry.Type != "file" || entry.Size != 5 {
+		t.Errorf("expected the written file's details, got %+v, %v", entry, err)
+	}
+	var entries []FSEntry
+	if err := run(t, fsTools["list_dir"], `{"path":""}`, &entries); err != nil || len(entries) != 3 {
+		t.Errorf("expected docs, main.go, and out, got %+v, %v", entries, err)
+	}
+	if err := run(t, fsTools["read_file"], `{"path":"missing.txt"}`, &read); err == nil || strings.Contains(err.Error(), root) {
+		t.Errorf("expected an error that does not reveal the root, got %v", err)
+	}
+}
+
+func TestFSToolset_ReadOnly(t *testing.T) {
+	_, fsTools := newFSTools(t, FSConfig{ReadOnly: true})
+	if _, ok := fsTools["write_file"]; ok {
+		t.Error("expected write_file to be left out")
+	}
+	if len(fsTools) != 4 {
+		t.Errorf("expected the four read tools, got %d", len(fsTools))
+	}
+}
+
+func TestFSToolset_Search(t *testing.T) {
+	_, fsTools := newFSTools(t, FSConfig{MaxSearchResults: 2})
+
+	var result FSSearchResult
+	if err := run(t, fsTools["search"], `{"pattern":"go (get|run|test)"}`, &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Matches) != 2 || !result.Truncated {
+		t.Errorf("expected two matches and truncation, got %+v", result)
+	}
+
+	if err := run(t, fsTools["search"], `{"pattern":"go","glob":"*.go"}`, &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Matches) != 1 || result.Matches[0].Path != "main.go" || result.Matches[0].Line != 3 {
+		t.Errorf("expected only the match in main.go, got %+v", result)
+	}
+
+	if err := run(t, fsTools["search"], `{"pattern":"("}`, &result); !isInvalidParams(err) {
+		t.Errorf("expected an invalid pattern to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+package utilitytools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+const (
+	// maxGrepContext caps the context lines returned around a match
+	maxGrepContext = 10
+	// maxGrepLineBytes caps the text returned for a line; longer lines, such as minified
+	// code, are cut around the match
+	maxGrepLineBytes = 500
+)
+
+// GrepParams defines parameters for the grep tool
+type GrepParams struct {
+	Pattern    string   `json:"pattern" jsonschema:"regular expression (RE2 syntax) to search for, or plain text when literal is set"`
+	Literal    bool     `json:"literal,omitempty" jsonschema:"match pattern as plain text instead of a regular expression"`
+	IgnoreCase bool     `json:"ignore_case,omitempty" jsonschema:"match regardless of case"`
+	Path       string   `json:"path,omitempty" jsonschema:"directory to search, defaults to the first allowed directory"`
+	Include    []string `json:"include,omitempty" jsonschema:"only search files matching one of these globs, such as *.go or cmd/**/*.go"`
+	Exclude    []string `json:"exclude,omitempty" jsonschema:"skip files matching any of these globs, such as vendor/** or *_test.go"`
+	Context    int      `json:"context,omitempty" jsonschema:"lines to return before and after each match, at most 10"`
+	MaxMatches int      `json:"max_matches,omitempty" jsonschema:"most matching lines to return"`
+}
+
+// GrepMatch is a line matching a grep
+type GrepMatch struct {
+	Path   string   `json:"path"`
+	Line   int      `json:"line"`
+	Column int      `json:"column"` // of the first match on the line, counted in characters from 1
+	Text   string   `json:"text"`
+	Before []string `json:"before,omitempty"` // context lines, in order
+	After  []string `json:"after,omitempty"`
+}
+
+// GrepResult holds the matches of a grep
+type GrepResult struct {
+	Matches       []GrepMatch `json:"matches"`
+	FilesSearched int         `json:"files_searched"`
+	FilesMatched  int         `json:"files_matched"`
+	Truncated     bool        `json:"truncated,omitempty"` // more lines matched than were returned
+}
+
+// NewGrepTool creates a tool named "Grep" that searches the text files under cfg.Roots for
+// a regular expression or literal text, returning each matching line's path, line, and
+// column with optional context lines. Include and exclude globs match file paths relative
+// to the searched directory; "**" matches any number of directories, and globs without a
+// "/" match file names. It shares the fs toolset's confinement and limits: files over
+// MaxReadBytes and binary files are skipped, and at most MaxSearchResults lines are returned.
+func NewGrepTool(cfg FSConfig) (tools.Tool, error) {
+	if len(cfg.Roots) == 0 {
+		return nil, errors.New("grep tool: no roots configured")
+	}
+	if cfg.MaxReadBytes <= 0 {
+		cfg.MaxReadBytes = DefaultFSMaxReadBytes
+	}
+	if cfg.MaxSearchResults <= 0 {
+		cfg.MaxSearchResults = DefaultFSMaxSearchResults
+	}
+	sandbox, err := newFSSandbox(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("grep tool: %w", err)
+	}
+	return tools.NewTool("Grep", "Searches files for lines matching a regular expression or literal text, "+
+		"returning each match's path, line, and column with optional context lines. Filter files with include and exclude globs.",
+		sandbox.grep,
+		tools.WithCategory("files"),
+		tools.WithVerb("Searching files"),
+	), nil
+}
+
+func (s *fsSandbox) grep(ctx context.Context, params GrepParams) (*GrepResult, error) {
+	if params.Pattern == "" {
+		return nil, tools.NewInvalidParamsError("pattern is required")
+	}
+	expr := params.Pattern
+	if params.Literal {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if params.IgnoreCase {
+		expr = "(?i)" + expr
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, tools.NewInvalidParamsError(fmt.Sprintf("invalid pattern: %v", err))
+	}
+	for _, glob := range append(append([]string(nil), params.Include...), params.Exclude...) {
+		if _, err := path.Match(strings.ReplaceAll(glob, "**", "*"), ""); err != nil {
+			return nil, tools.NewInvalidParamsError(fmt.Sprintf("invalid glob %q: %v", glob, err))
+		}
+	}
+	if params.Context < 0 || params.Context > maxGrepContext {
+		return nil, tools.NewInvalidParamsError(fmt.Sprintf("context must be between 0 and %d", maxGrepContext))
+	}
+	maxMatches := s.cfg.MaxSearchResults
+	if params.MaxMatches > 0 {
+		maxMatches = min(params.MaxMatches, maxMatches)
+	}
+	dir, err := s.resolve(params.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GrepResult{Matches: []GrepMatch{}}
+	err = walkFiles(ctx, dir, func(file string, d fs.DirEntry) bool {
+		rel, _ := within(dir, file)
+		rel = filepath.ToSlash(rel)
+		if len(params.Include) > 0 && !matchAnyGlob(params.Include, rel) {
+			return true
+		}
+		if matchAnyGlob(params.Exclude, rel) {
+			return true
+		}
+		return s.grepFile(file, pattern, params.Context, maxMatches, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// grepFile appends the matching lines of a text file to result, and reports whether the
+// search should go on. Binary files and files over the read limit are skipped.
+func (s *fsSandbox) grepFile(file string, pattern *regexp.Regexp, contextLines, maxMatches int, result *GrepResult) bool {
+	info, err := os.Stat(file)
+	if err != nil || info.Size() > s.cfg.MaxReadBytes {
+		return true
+	}
+	data, err := os.ReadFile(file)
+	if err != nil || bytes.IndexByte(data, 0) >= 0 {
+		return true
+	}
+	result.FilesSearched++
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	matched := false
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		loc := pattern.FindStringIndex(line)
+		if loc == nil {
+			continue
+		}
+		if len(result.Matches) == maxMatches {
+			result.Truncated = true
+			return false
+		}
+		if !matched {
+			matched = true
+			result.FilesMatched++
+		}
+		match := GrepMatch{
+			Path:   s.display(file),
+			Line:   i + 1,
+			Column: utf8.RuneCountInString(line[:loc[0]]) + 1,
+			Text:   excerptLine(line, loc[0]),
+		}
+		for _, before := range lines[max(0, i-contextLines):i] {
+			match.Before = append(match.Before, excerptLine(strings.TrimSuffix(before, "\r"), 0))
+		}
+		for _, after := range lines[i+1 : min(len(lines), i+1+contextLines)] {
+			match.After = append(match.After, excerptLine(strings.TrimSuffix(after, "\r"), 0))
+		}
+		result.Matches = append(result.Matches, match)
+	}
+	return true
+}
+
+// excerptLine cuts a line longer than maxGrepLineBytes to a window around offset, marking
+// the cuts with "…"
+func excerptLine(line string, offset int) string {
+	if len(line) <= maxGrepLineBytes {
+		return line
+	}
+	start := max(0, min(offset-maxGrepLineBytes/4, len(line)-maxGrepLineBytes))
+	for start > 0 && !utf8.RuneStart(line[start]) {
+		start--
+	}
+	end := start + maxGrepLineBytes
+	for end < len(line) && !utf8.RuneStart(line[end]) {
+		end--
+	}
+	text := line[start:end]
+	if start > 0 {
+		text = "…" + text
+	}
+	if end < len(line) {
+		text += "…"
+	}
+	return text
+}
+
+// matchAnyGlob reports whether a slash-separated relative path matches one of globs
+func matchAnyGlob(globs []string, rel string) bool {
+	for _, glob := range globs {
+		if !strings.Contains(glob, "/") {
+			if ok, _ := path.Match(glob, path.Base(rel)); ok {
+				return true
+			}
+			continue
+		}
+		if matchGlobParts(strings.Split(glob, "/"), strings.Split(rel, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlobParts matches path elements against glob elements, where "**" matches any
+// number of elements
+func matchGlobParts(glob, parts []string) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchGlobParts(glob[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(glob[0], parts[0]); !ok {
+			return false
+		}
+		glob, parts = glob[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
@@ -0,0 +1,88 @@
+package utilitytools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGrepTool(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"main.go":              "package main\n\nfunc main() {\n\tserve()\n}\n",
+		"server/serve.go":      "package server\n\n// Serve starts the server\nfunc Serve() error {\n\treturn nil\n}\n",
+		"server/serve_test.go": "package server\n\nfunc TestServe(t *testing.T) {}\n",
+		"vendor/lib/lib.go":    "package lib\n\nfunc Serve() {}\n",
+		"docs/notes.txt":       "a.b matches literally\naxb does not\n",
+		"data.bin":             "serve\x00",
+	}
+	for name, content := range files {
+		os.MkdirAll(filepath.Join(root, filepath.Dir(name)), 0o755)
+		os.WriteFile(filepath.Join(root, name), []byte(content), 0o644)
+	}
+	tool, err := NewGrepTool(FSConfig{Roots: []string{root}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result GrepResult
+	if err := run(t, tool, `{"pattern": "func serve", "ignore_case": true, "include": ["**/*.go"], "exclude": ["vendor/**", "*_test.go"], "context": 1}`, &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Matches) != 1 || result.FilesMatched != 1 || result.FilesSearched != 2 {
+		t.Fatalf("result = %+v", result)
+	}
+	match := result.Matches[0]
+	if match.Path != "server/serve.go" || match.Line != 4 || match.Column != 1 || match.Text != "func Serve() error {" ||
+		strings.Join(match.Before, "|") != "// Serve starts the server" || strings.Join(match.After, "|") != "\treturn nil" {
+		t.Errorf("match = %+v", match)
+	}
+
+	// Literal patterns escape regular expression syntax; columns count characters
+	if err := run(t, tool, `{"pattern": "b m", "literal": true, "path": "docs"}`, &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Matches) != 1 || result.Matches[0].Path != "docs/notes.txt" || result.Matches[0].Column != 3 {
+		t.Errorf("literal result = %+v", result)
+	}
+	if run(t, tool, `{"pattern": "a.b", "literal": true}`, &result); len(result.Matches) != 1 {
+		t.Errorf("literal a.b matched %+v", result.Matches)
+	}
+
+	// Binary files are skipped, and max_matches truncates
+	if err := run(t, tool, `{"pattern": "(?i)serve", "max_matches": 2}`, &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Matches) != 2 || !result.Truncated {
+		t.Errorf("truncated result = %+v", result)
+	}
+	for _, m := range result.Matches {
+		if m.Path == "data.bin" {
+			t.Error("expected binary files to be skipped")
+		}
+	}
+
+	for _, args := range []string{
+		`{"pattern": ""}`,
+		`{"pattern": "("}`,
+		`{"pattern": "x", "include": ["["]}`,
+		`{"pattern": "x", "context": 11}`,
+		`{"pattern": "x", "path": "../"}`,
+	} {
+		if err := run(t, tool, args, &result); !isInvalidParams(err) {
+			t.Errorf("%s: expected invalid params, got %v", args, err)
+		}
+	}
+}
+
+func TestExcerptLine(t *testing.T) {
+	line := strings.Repeat("x", 1000) + "needle" + strings.Repeat("y", 1000)
+	text := excerptLine(line, 1000)
+	if !strings.Contains(text, "needle") || !strings.HasPrefix(text, "…") || !strings.HasSuffix(text, "…") {
+		t.Errorf("excerpt = %q", text)
+	}
+	if excerptLine("short", 0) != "short" {
+		t.Error("expected short lines to be kept")
+	}
+}
@@ -0,0 +1,166 @@
+package utilitytools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mhpenta/minimcp/mcp"
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// Defaults for JSONQueryConfig
+const (
+	DefaultJSONQueryMaxResults       = 1000
+	DefaultJSONQueryMaxDocumentBytes = 10 << 20
+)
+
+// ResourceReader reads resources by URI. *mcp.Server implements it.
+type ResourceReader interface {
+	ReadResource(ctx context.Context, uri string) ([]mcp.ResourceContents, error)
+}
+
+// JSONQueryConfig configures NewJSONQueryTool
+type JSONQueryConfig struct {
+	// Resources reads the documents calls name by resource_uri. Without it, calls must
+	// supply the document.
+	Resources ResourceReader
+
+	MaxResults       int // values a query returns (default 1000)
+	MaxDocumentBytes int // size of a resource document (default 10 MiB)
+}
+
+// JSONQueryParams defines parameters for the JSON query tool
+type JSONQueryParams struct {
+	Query       string      `json:"query" jsonschema:"JSONPath query such as $.items[?@.price < 10].name, or a jq path such as .items[0].name or .items[].id"`
+	Document    interface{} `json:"document,omitempty" jsonschema:"JSON document to query; a string holding a JSON object or array is parsed"`
+	ResourceURI string      `json:"resource_uri,omitempty" jsonschema:"URI of a JSON resource to query instead of document"`
+}
+
+// JSONQueryResult holds the values a query selected
+type JSONQueryResult struct {
+	Values    []interface{} `json:"values"`
+	Count     int           `json:"count"`               // values selected, including any not returned
+	Truncated bool          `json:"truncated,omitempty"` // more than MaxResults values were selected
+}
+
+// NewJSONQueryTool creates a tool named "QueryJSON" that extracts values from a JSON
+// document with a JSONPath query (RFC 9535, without function extensions) or a jq path, so
+// models can pick fields out of large tool outputs instead of reading them whole. The
+// document is passed in the call or, when cfg.Resources is set, read from a resource.
+//
+// Example:
+//
+//	server := mcp.NewServer(mcp.ServerConfig{Name: "data"})
+//	server.AddTools(utilitytools.NewJSONQueryTool(utilitytools.JSONQueryConfig{Resources: server}))
+func NewJSONQueryTool(cfg JSONQueryConfig) tools.Tool {
+	if cfg.MaxResults <= 0 {
+		cfg.MaxResults = DefaultJSONQueryMaxResults
+	}
+	if cfg.MaxDocumentBytes <= 0 {
+		cfg.MaxDocumentBytes = DefaultJSONQueryMaxDocumentBytes
+	}
+	q := &jsonQueryTool{cfg: cfg}
+
+	description := "Extracts values from a JSON document with a JSONPath query ($ is the root, .name or ['name'] a member, " +
+		"[0] or [-1] an index, [1:3] a slice, [*] every child, ..name a member at any depth, and [?@.age > 30 && @.active] a filter) " +
+		"or a jq path such as .items[].name. Use it to pick fields out of large outputs of other tools."
+	if cfg.Resources != nil {
+		description += " Pass the document, or the resource_uri of a JSON resource."
+	}
+	return tools.NewTool("QueryJSON", description, q.run,
+		tools.WithType("QueryJSON_v1"),
+		tools.WithVerb("Querying JSON"),
+	)
+}
+
+type jsonQueryTool struct {
+	cfg JSONQueryConfig
+}
+
+func (q *jsonQueryTool) run(ctx context.Context, params JSONQueryParams) (*JSONQueryResult, error) {
+	path, err := parseJSONPath(params.Query)
+	if err != nil {
+		return nil, tools.NewInvalidParamsError(fmt.Sprintf("invalid query: %v", err))
+	}
+	document, err := q.document(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	values := path.eval(document, document)
+	result := &JSONQueryResult{Values: values, Count: len(values)}
+	if result.Values == nil {
+		result.Values = []interface{}{}
+	}
+	if len(values) > q.cfg.MaxResults {
+		result.Values, result.Truncated = values[:q.cfg.MaxResults], true
+	}
+	return result, nil
+}
+
+// document returns the call's document, or the resource it names
+func (q *jsonQueryTool) document(ctx context.Context, params JSONQueryParams) (interface{}, error) {
+	if params.ResourceURI == "" {
+		if params.Document == nil {
+			return nil, tools.NewInvalidParamsError("pass the document to query, or a resource_uri")
+		}
+		if text, ok := params.Document.(string); ok {
+			if trimmed := strings.TrimSpace(text); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+				document, err := decodeJSONDocument([]byte(trimmed))
+				if err != nil {
+					return nil, tools.NewInvalidParamsError(fmt.Sprintf("document is not valid JSON: %v", err))
+				}
+				return document, nil
+			}
+		}
+		return params.Document, nil
+	}
+	if params.Document != nil {
+		return nil, tools.NewInvalidParamsError("pass either document or resource_uri, not both")
+	}
+	if q.cfg.Resources == nil {
+		return nil, tools.NewInvalidParamsError("this server does not query resources; pass the document")
+	}
+
+	contents, err := q.cfg.Resources.ReadResource(ctx, params.ResourceURI)
+	if err != nil {
+		var rpcErr *mcp.RPCError
+		if errors.As(err, &rpcErr) && rpcErr.Code == mcp.ResourceNotFound {
+			return nil, tools.NewInvalidParamsError(fmt.Sprintf("resource %s not found", params.ResourceURI))
+		}
+		return nil, fmt.Errorf("reading %s: %w", params.ResourceURI, err)
+	}
+	if len(contents) == 0 {
+		return nil, fmt.Errorf("resource %s is empty", params.ResourceURI)
+	}
+	data := contents[0].Blob
+	if data == nil {
+		data = []byte(contents[0].Text)
+	}
+	if len(data) > q.cfg.MaxDocumentBytes {
+		return nil, fmt.Errorf("resource %s is %d bytes, more than the limit of %d", params.ResourceURI, len(data), q.cfg.MaxDocumentBytes)
+	}
+	document, err := decodeJSONDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("resource %s is not valid JSON: %w", params.ResourceURI, err)
+	}
+	return document, nil
+}
+
+// decodeJSONDocument decodes a single JSON value, keeping numbers exact
+func decodeJSONDocument(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after the JSON value")
+	}
+	return document, nil
+}
@@ -0,0 +1,124 @@
+package utilitytools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mhpenta/minimcp/mcp"
+)
+
+const storeJSON = `{
+	"store": {
+		"book": [
+			{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+			{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+			{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+			{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+		],
+		"bicycle": {"color": "red", "price": 399}
+	},
+	"id": 12345678901234567890
+}`
+
+func TestJSONPath(t *testing.T) {
+	document, err := decodeJSONDocument([]byte(storeJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		query, want string
+	}{
+		{`$.store.book[*].author`, `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{`$..author`, `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{`$.store.*.color`, `["red"]`},
+		{`$..price`, `[399,8.95,12.99,8.99,22.99]`},
+		{`$..book[2].title`, `["Moby Dick"]`},
+		{`$..book[-1].title`, `["The Lord of the Rings"]`},
+		{`$..book[0,1].price`, `[8.95,12.99]`},
+		{`$..book[:2].price`, `[8.95,12.99]`},
+		{`$..book[::-2].price`, `[22.99,12.99]`},
+		{`$..book[?@.isbn].title`, `["Moby Dick","The Lord of the Rings"]`},
+		{`$..book[?(@.price < 10)].title`, `["Sayings of the Century","Moby Dick"]`},
+		{`$..book[?@.price >= 12.99 && @.category == 'fiction'].author`, `["Evelyn Waugh","J. R. R. Tolkien"]`},
+		{`$..book[?!(@.category == "fiction") || @.price > 20].price`, `[8.95,22.99]`},
+		{`$..book[?@.price < $.store.bicycle.price && @.author > "M"].author`, `["Nigel Rees"]`},
+		{`$['store']['bicycle']["color"]`, `["red"]`},
+		{`$.id`, `[12345678901234567890]`},
+		{`$.missing`, `null`},
+		// jq paths
+		{`.store.book[].title`, `["Sayings of the Century","Sword of Honour","Moby Dick","The Lord of the Rings"]`},
+		{`.store.book[1].author`, `["Evelyn Waugh"]`},
+		{`.["store"].bicycle.price`, `[399]`},
+	}
+	for _, tt := range tests {
+		path, err := parseJSONPath(tt.query)
+		if err != nil {
+			t.Errorf("%s: %v", tt.query, err)
+			continue
+		}
+		got, _ := json.Marshal(path.eval(document, document))
+		if string(got) != tt.want {
+			t.Errorf("%s = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+
+	for _, query := range []string{"store", "$.", "$[", "$[1", "$..", "$[?@.a ==]", "$[?1]", "$.a b", `$['a`} {
+		if _, err := parseJSONPath(query); err == nil {
+			t.Errorf("%q: expected a parse error", query)
+		}
+	}
+}
+
+func TestJSONQueryTool(t *testing.T) {
+	server := mcp.NewServer(mcp.ServerConfig{
+		Name: "test-server",
+		ResourceTemplates: []mcp.ResourceTemplate{{
+			URITemplate: "data://{name}",
+			Name:        "Data",
+			Resolve: func(ctx context.Context, uri string, vars map[string]string) ([]mcp.ResourceContents, error) {
+				if vars["name"] != "store" {
+					return nil, mcp.ErrResourceNotFound
+				}
+				return []mcp.ResourceContents{{Text: storeJSON}}, nil
+			},
+		}},
+	})
+	tool := NewJSONQueryTool(JSONQueryConfig{Resources: server, MaxResults: 2})
+
+	var result JSONQueryResult
+	if err := run(t, tool, `{"query": "$.store.bicycle", "resource_uri": "data://store"}`, &result); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := json.Marshal(result); string(data) != `{"values":[{"color":"red","price":399}],"count":1}` {
+		t.Errorf("resource result = %s", data)
+	}
+
+	// A string document holding JSON is parsed; results over MaxResults are truncated
+	args, _ := json.Marshal(JSONQueryParams{Query: "$..price", Document: storeJSON})
+	if err := run(t, tool, string(args), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Values) != 2 || result.Count != 5 || !result.Truncated {
+		t.Errorf("truncated result = %+v", result)
+	}
+
+	if err := run(t, tool, `{"query": "$[?@ > 1]", "document": [1, 2, 3]}`, &result); err != nil || result.Count != 2 {
+		t.Errorf("inline result = %+v, %v", result, err)
+	}
+
+	for _, args := range []string{
+		`{"query": "$.a"}`,
+		`{"query": "nope", "document": {}}`,
+		`{"query": "$.a", "document": "{not json"}`,
+		`{"query": "$.a", "document": {}, "resource_uri": "data://store"}`,
+		`{"query": "$.a", "resource_uri": "data://other"}`,
+	} {
+		if err := run(t, tool, args, &result); !isInvalidParams(err) {
+			t.Errorf("%s: expected invalid params, got %v", args, err)
+		}
+	}
+	if err := run(t, NewJSONQueryTool(JSONQueryConfig{}), `{"query": "$.a", "resource_uri": "data://store"}`, &result); !isInvalidParams(err) {
+		t.Errorf("expected resource_uri without Resources to be invalid params, got %v", err)
+	}
+}
@@ -0,0 +1,653 @@
+package utilitytools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// jsonPath is a parsed JSONPath query (RFC 9535), without function extensions. Queries
+// may also be written as jq paths such as .items[0].name or .items[].
+type jsonPath struct {
+	segments []jsonPathSegment
+}
+
+// jsonPathSegment selects children of each node, or of each node and its descendants
+type jsonPathSegment struct {
+	descendant bool
+	selectors  []jsonPathSelector
+}
+
+// jsonPathSelector appends the children of node it selects to out
+type jsonPathSelector interface {
+	selectFrom(node, root interface{}, out []interface{}) []interface{}
+}
+
+type (
+	nameSelector     string
+	wildcardSelector struct{}
+	indexSelector    int
+	sliceSelector    struct {
+		start, end *int
+		step       int
+	}
+	filterSelector struct{ expr filterExpr }
+)
+
+// parseJSONPath parses a query starting with "$", or a jq path starting with "."
+func parseJSONPath(expr string) (*jsonPath, error) {
+	expr = strings.TrimSpace(expr)
+	p := &jsonPathParser{src: expr}
+	switch {
+	case expr == ".":
+		return &jsonPath{}, nil
+	case strings.HasPrefix(expr, "$"):
+		p.pos++
+	case strings.HasPrefix(expr, "."):
+	default:
+		return nil, errors.New(`query must start with "$" (JSONPath) or "." (jq path)`)
+	}
+	path, err := p.segments()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos:])
+	}
+	return path, nil
+}
+
+// eval returns the nodes the query selects, with current as its starting node
+func (q *jsonPath) eval(current, root interface{}) []interface{} {
+	nodes := []interface{}{current}
+	for _, segment := range q.segments {
+		var next []interface{}
+		for _, node := range nodes {
+			targets := []interface{}{node}
+			if segment.descendant {
+				targets = descendants(node, targets[:0])
+			}
+			for _, target := range targets {
+				for _, selector := range segment.selectors {
+					next = selector.selectFrom(target, root, next)
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func (s nameSelector) selectFrom(node, root interface{}, out []interface{}) []interface{} {
+	if object, ok := node.(map[string]interface{}); ok {
+		if value, ok := object[string(s)]; ok {
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+func (wildcardSelector) selectFrom(node, root interface{}, out []interface{}) []interface{} {
+	return append(out, children(node)...)
+}
+
+func (s indexSelector) selectFrom(node, root interface{}, out []interface{}) []interface{} {
+	array, ok := node.([]interface{})
+	if !ok {
+		return out
+	}
+	i := int(s)
+	if i < 0 {
+		i += len(array)
+	}
+	if i >= 0 && i < len(array) {
+		out = append(out, array[i])
+	}
+	return out
+}
+
+func (s sliceSelector) selectFrom(node, root interface{}, out []interface{}) []interface{} {
+	array, ok := node.([]interface{})
+	if !ok || s.step == 0 {
+		return out
+	}
+	n := len(array)
+	bound := func(i *int, def int) int {
+		if i == nil {
+			return def
+		}
+		if *i < 0 {
+			return *i + n
+		}
+		return *i
+	}
+	clamp := func(i, lo, hi int) int { return max(lo, min(i, hi)) }
+	if s.step > 0 {
+		lower, upper := clamp(bound(s.start, 0), 0, n), clamp(bound(s.end, n), 0, n)
+		for i := lower; i < upper; i += s.step {
+			out = append(out, array[i])
+		}
+		return out
+	}
+	upper, lower := clamp(bound(s.start, n-1), -1, n-1), clamp(bound(s.end, -n-1), -1, n-1)
+	for i := upper; i > lower; i += s.step {
+		out = append(out, array[i])
+	}
+	return out
+}
+
+func (s filterSelector) selectFrom(node, root interface{}, out []interface{}) []interface{} {
+	for _, child := range children(node) {
+		if s.expr.test(child, root) {
+			out = append(out, child)
+		}
+	}
+	return out
+}
+
+// children returns an array's elements, or an object's values ordered by key
+func children(node interface{}) []interface{} {
+	switch v := node.(type) {
+	case []interface{}:
+		return v
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values := make([]interface{}, len(keys))
+		for i, key := range keys {
+			values[i] = v[key]
+		}
+		return values
+	}
+	return nil
+}
+
+// descendants appends node and everything nested in it to out, parents first
+func descendants(node interface{}, out []interface{}) []interface{} {
+	out = append(out, node)
+	for _, child := range children(node) {
+		out = descendants(child, out)
+	}
+	return out
+}
+
+// filterExpr is a logical expression in a filter selector
+type filterExpr interface {
+	test(current, root interface{}) bool
+}
+
+type (
+	orExpr  []filterExpr
+	andExpr []filterExpr
+	notExpr struct{ expr filterExpr }
+	// existsExpr tests that a query selects at least one node
+	existsExpr  struct{ query filterQuery }
+	compareExpr struct {
+		op          string
+		left, right filterOperand
+	}
+)
+
+// filterQuery is a query inside a filter, from the current node (@) or the root ($)
+type filterQuery struct {
+	relative bool
+	path     *jsonPath
+}
+
+func (q filterQuery) nodes(current, root interface{}) []interface{} {
+	if q.relative {
+		return q.path.eval(current, root)
+	}
+	return q.path.eval(root, root)
+}
+
+// filterOperand is a literal or a query in a comparison. ok is false when a query does not
+// select exactly one node.
+type filterOperand interface {
+	value(current, root interface{}) (v interface{}, ok bool)
+}
+
+type literal struct{ v interface{} }
+
+func (l literal) value(current, root interface{}) (interface{}, bool) { return l.v, true }
+
+func (q filterQuery) value(current, root interface{}) (interface{}, bool) {
+	nodes := q.nodes(current, root)
+	if len(nodes) != 1 {
+		return nil, false
+	}
+	return nodes[0], true
+}
+
+func (e orExpr) test(current, root interface{}) bool {
+	for _, expr := range e {
+		if expr.test(current, root) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e andExpr) test(current, root interface{}) bool {
+	for _, expr := range e {
+		if !expr.test(current, root) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e notExpr) test(current, root interface{}) bool { return !e.expr.test(current, root) }
+
+func (e existsExpr) test(current, root interface{}) bool {
+	return len(e.query.nodes(current, root)) > 0
+}
+
+func (e compareExpr) test(current, root interface{}) bool {
+	left, lok := e.left.value(current, root)
+	right, rok := e.right.value(current, root)
+	switch e.op {
+	case "==":
+		return jsonPathEqual(left, lok, right, rok)
+	case "!=":
+		return !jsonPathEqual(left, lok, right, rok)
+	}
+	if !lok || !rok {
+		return false
+	}
+	if equal := jsonPathEqual(left, lok, right, rok); equal && (e.op == "<=" || e.op == ">=") {
+		return true
+	}
+	less, ok := jsonPathLess(left, right)
+	if !ok {
+		return false
+	}
+	switch e.op {
+	case "<", "<=":
+		return less
+	default:
+		greater, _ := jsonPathLess(right, left)
+		return greater
+	}
+}
+
+// jsonPathEqual compares values, numbers by value; a missing value only equals another
+func jsonPathEqual(a interface{}, aok bool, b interface{}, bok bool) bool {
+	if !aok || !bok {
+		return aok == bok
+	}
+	if x, ok := jsonNumber(a); ok {
+		y, ok := jsonNumber(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// jsonPathLess orders two numbers or two strings
+func jsonPathLess(a, b interface{}) (less, ok bool) {
+	if x, ok := jsonNumber(a); ok {
+		y, ok := jsonNumber(b)
+		return x < y, ok
+	}
+	if x, ok := a.(string); ok {
+		y, ok := b.(string)
+		return x < y, ok
+	}
+	return false, false
+}
+
+func jsonNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// jsonPathParser is a recursive descent parser of queries
+type jsonPathParser struct {
+	src string
+	pos int
+}
+
+func (p *jsonPathParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *jsonPathParser) peek(prefix string) bool { return strings.HasPrefix(p.src[p.pos:], prefix) }
+
+func (p *jsonPathParser) skipSpace() {
+	for p.pos < len(p.src) && strings.IndexByte(" \t\n\r", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// segments parses segments until one cannot start
+func (p *jsonPathParser) segments() (*jsonPath, error) {
+	path := &jsonPath{}
+	for {
+		var segment jsonPathSegment
+		switch {
+		case p.peek(".."):
+			p.pos += 2
+			segment.descendant = true
+			if !p.peek("[") {
+				selector, err := p.dotSelector()
+				if err != nil {
+					return nil, err
+				}
+				segment.selectors = []jsonPathSelector{selector}
+				break
+			}
+			fallthrough
+		case p.peek("["):
+			selectors, err := p.bracket()
+			if err != nil {
+				return nil, err
+			}
+			segment.selectors = selectors
+		case p.peek("."):
+			p.pos++
+			var err error
+			if p.peek("[") { // jq's .[0] and .["name"]
+				segment.selectors, err = p.bracket()
+			} else {
+				var selector jsonPathSelector
+				selector, err = p.dotSelector()
+				segment.selectors = []jsonPathSelector{selector}
+			}
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return path, nil
+		}
+		path.segments = append(path.segments, segment)
+	}
+}
+
+// dotSelector parses the name or * after a dot
+func (p *jsonPathParser) dotSelector() (jsonPathSelector, error) {
+	if p.peek("*") {
+		p.pos++
+		return wildcardSelector{}, nil
+	}
+	start := p.pos
+	for p.pos < len(p.src) {
+		r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		if r != '_' && !unicode.IsLetter(r) && (p.pos == start || !unicode.IsDigit(r)) {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos == start {
+		return nil, p.errorf("expected a member name or * after '.'")
+	}
+	return nameSelector(p.src[start:p.pos]), nil
+}
+
+// bracket parses [selector, ...]; jq's [] selects every child
+func (p *jsonPathParser) bracket() ([]jsonPathSelector, error) {
+	p.pos++
+	p.skipSpace()
+	if p.peek("]") {
+		p.pos++
+		return []jsonPathSelector{wildcardSelector{}}, nil
+	}
+	var selectors []jsonPathSelector
+	for {
+		p.skipSpace()
+		selector, err := p.selector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+		p.skipSpace()
+		switch {
+		case p.peek(","):
+			p.pos++
+		case p.peek("]"):
+			p.pos++
+			return selectors, nil
+		default:
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+func (p *jsonPathParser) selector() (jsonPathSelector, error) {
+	switch {
+	case p.peek("'") || p.peek(`"`):
+		name, err := p.stringLiteral()
+		return nameSelector(name), err
+	case p.peek("*"):
+		p.pos++
+		return wildcardSelector{}, nil
+	case p.peek("?"):
+		p.pos++
+		expr, err := p.orExpr()
+		return filterSelector{expr}, err
+	}
+
+	var s sliceSelector
+	var err error
+	if s.start, err = p.optionalInt(); err != nil {
+		return nil, err
+	}
+	if !p.peek(":") {
+		if s.start == nil {
+			return nil, p.errorf("expected a name, index, slice, *, or ?filter")
+		}
+		return indexSelector(*s.start), nil
+	}
+	p.pos++
+	p.skipSpace()
+	if s.end, err = p.optionalInt(); err != nil {
+		return nil, err
+	}
+	s.step = 1
+	if p.peek(":") {
+		p.pos++
+		p.skipSpace()
+		step, err := p.optionalInt()
+		if err != nil {
+			return nil, err
+		}
+		if step != nil {
+			s.step = *step
+		}
+	}
+	return s, nil
+}
+
+func (p *jsonPathParser) optionalInt() (*int, error) {
+	start := p.pos
+	if p.peek("-") {
+		p.pos++
+	}
+	for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	if p.pos == start {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil {
+		return nil, p.errorf("invalid integer %q", p.src[start:p.pos])
+	}
+	p.skipSpace()
+	return &n, nil
+}
+
+// stringLiteral parses a single- or double-quoted string with JSON escapes
+func (p *jsonPathParser) stringLiteral() (string, error) {
+	quote := p.src[p.pos]
+	var b strings.Builder
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		c := p.src[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c == '\\' && p.pos+1 < len(p.src):
+			p.pos++
+			switch e := p.src[p.pos]; e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'u':
+				if p.pos+4 >= len(p.src) {
+					return "", p.errorf("invalid \\u escape")
+				}
+				r, err := strconv.ParseUint(p.src[p.pos+1:p.pos+5], 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid \\u escape")
+				}
+				b.WriteRune(rune(r))
+				p.pos += 4
+			default:
+				b.WriteByte(e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *jsonPathParser) orExpr() (filterExpr, error) {
+	var or orExpr
+	for {
+		and, err := p.andExpr()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, and)
+		p.skipSpace()
+		if !p.peek("||") {
+			break
+		}
+		p.pos += 2
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (p *jsonPathParser) andExpr() (filterExpr, error) {
+	var and andExpr
+	for {
+		expr, err := p.basicExpr()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, expr)
+		p.skipSpace()
+		if !p.peek("&&") {
+			break
+		}
+		p.pos += 2
+	}
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+var compareOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func (p *jsonPathParser) basicExpr() (filterExpr, error) {
+	p.skipSpace()
+	switch {
+	case p.peek("!") && !p.peek("!="):
+		p.pos++
+		expr, err := p.basicExpr()
+		return notExpr{expr}, err
+	case p.peek("("):
+		p.pos++
+		expr, err := p.orExpr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.peek(")") {
+			return nil, p.errorf("expected ')'")
+		}
+		p.pos++
+		return expr, nil
+	}
+
+	left, err := p.filterOperand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	for _, op := range compareOps {
+		if p.peek(op) {
+			p.pos += len(op)
+			p.skipSpace()
+			right, err := p.filterOperand()
+			if err != nil {
+				return nil, err
+			}
+			return compareExpr{op: op, left: left, right: right}, nil
+		}
+	}
+	query, ok := left.(filterQuery)
+	if !ok {
+		return nil, p.errorf("expected a comparison after a literal")
+	}
+	return existsExpr{query}, nil
+}
+
+func (p *jsonPathParser) filterOperand() (filterOperand, error) {
+	switch {
+	case p.peek("@") || p.peek("$"):
+		relative := p.src[p.pos] == '@'
+		p.pos++
+		path, err := p.segments()
+		return filterQuery{relative: relative, path: path}, err
+	case p.peek("'") || p.peek(`"`):
+		s, err := p.stringLiteral()
+		return literal{s}, err
+	case p.peek("true"):
+		p.pos += 4
+		return literal{true}, nil
+	case p.peek("false"):
+		p.pos += 5
+		return literal{false}, nil
+	case p.peek("null"):
+		p.pos += 4
+		return literal{nil}, nil
+	}
+	start := p.pos
+	for p.pos < len(p.src) && strings.IndexByte("+-.0123456789eE", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	n, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+	if err != nil || p.pos == start {
+		p.pos = start
+		return nil, p.errorf("expected @, $, a string, a number, true, false, or null")
+	}
+	return literal{n}, nil
+}
@@ -0,0 +1,408 @@
+package utilitytools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+	"github.com/mhpenta/minimcp/tools/mcpctx"
+)
+
+// Defaults for MemoryConfig
+const (
+	DefaultMemoryMaxValueBytes = 64 << 10
+	DefaultMemoryMaxKeys       = 1000
+	defaultMemoryNamespace     = "default"
+)
+
+// KVEntry is a value stored under a key
+type KVEntry struct {
+	Key     string
+	Value   json.RawMessage
+	Expires time.Time // zero if the entry does not expire
+	Updated time.Time
+}
+
+// expired reports whether the entry has expired at now
+func (e KVEntry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+// KVStore stores the memory toolset's entries in namespaces. Expired entries must not be
+// returned. Implementations must be safe for concurrent use.
+type KVStore interface {
+	// Get returns the entry stored under key, or nil if there is none
+	Get(ctx context.Context, namespace, key string) (*KVEntry, error)
+	Set(ctx context.Context, namespace string, entry KVEntry) error
+	// Delete removes key, reporting whether it existed
+	Delete(ctx context.Context, namespace, key string) (bool, error)
+	// List returns the entries whose keys start with prefix, sorted by key
+	List(ctx context.Context, namespace, prefix string) ([]KVEntry, error)
+}
+
+// MemoryKVStore is an in-memory KVStore. Expired entries are removed as entries are set.
+type MemoryKVStore struct {
+	mu         sync.Mutex
+	namespaces map[string]map[string]KVEntry
+	now        func() time.Time
+}
+
+// NewMemoryKVStore creates an empty in-memory store
+func NewMemoryKVStore() *MemoryKVStore {
+	return &MemoryKVStore{namespaces: make(map[string]map[string]KVEntry), now: time.Now}
+}
+
+// Get returns the entry stored under key, or nil if there is none
+func (s *MemoryKVStore) Get(ctx context.Context, namespace, key string) (*KVEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.namespaces[namespace][key]
+	if !ok || entry.expired(s.now()) {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// Set stores an entry, replacing any entry under its key
+func (s *MemoryKVStore) Set(ctx context.Context, namespace string, entry KVEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep()
+	entries := s.namespaces[namespace]
+	if entries == nil {
+		entries = make(map[string]KVEntry)
+		s.namespaces[namespace] = entries
+	}
+	entries[entry.Key] = entry
+	return nil
+}
+
+// Delete removes key, reporting whether it existed
+func (s *MemoryKVStore) Delete(ctx context.Context, namespace, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.namespaces[namespace][key]
+	if !ok {
+		return false, nil
+	}
+	delete(s.namespaces[namespace], key)
+	return !entry.expired(s.now()), nil
+}
+
+// List returns the entries whose keys start with prefix, sorted by key
+func (s *MemoryKVStore) List(ctx context.Context, namespace, prefix string) ([]KVEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	var entries []KVEntry
+	for key, entry := range s.namespaces[namespace] {
+		if strings.HasPrefix(key, prefix) && !entry.expired(now) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, nil
+}
+
+// sweep removes expired entries and empty namespaces
+func (s *MemoryKVStore) sweep() {
+	now := s.now()
+	for namespace, entries := range s.namespaces {
+		for key, entry := range entries {
+			if entry.expired(now) {
+				delete(entries, key)
+			}
+		}
+		if len(entries) == 0 {
+			delete(s.namespaces, namespace)
+		}
+	}
+}
+
+// MemoryConfig configures NewMemoryToolset
+type MemoryConfig struct {
+	// Store holds the entries (default a new MemoryKVStore)
+	Store KVStore
+
+	// Scope returns the owner of a call's entries, so callers cannot read each other's.
+	// Defaults to the session ID, falling back to the authenticated principal's subject.
+	Scope func(ctx context.Context) string
+
+	DefaultTTL    time.Duration // for entries set without ttl_seconds (default: no expiry)
+	MaxTTL        time.Duration // caps ttl_seconds (default: no cap)
+	MaxValueBytes int           // of a value's JSON (default 64 KiB)
+	MaxKeys       int           // per scope and namespace (default 1000)
+}
+
+// MemoryGetParams defines parameters for memory.get
+type MemoryGetParams struct {
+	Key       string `json:"key" jsonschema:"key to read"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"namespace of the key (default \"default\")"`
+}
+
+// MemorySetParams defines parameters for memory.set
+type MemorySetParams struct {
+	Key        string      `json:"key" jsonschema:"key to write"`
+	Value      interface{} `json:"value" jsonschema:"any JSON value"`
+	TTLSeconds int         `json:"ttl_seconds,omitempty" jsonschema:"seconds until the key expires; omit to use the server default"`
+	Namespace  string      `json:"namespace,omitempty" jsonschema:"namespace of the key (default \"default\")"`
+}
+
+// MemoryListParams defines parameters for memory.list
+type MemoryListParams struct {
+	Prefix    string `json:"prefix,omitempty" jsonschema:"only list keys starting with this prefix"`
+	Namespace string `json:"namespace,omitempty" jsonschema:"namespace to list (default \"default\")"`
+}
+
+// MemoryValue is a stored value
+type MemoryValue struct {
+	Key       string          `json:"key"`
+	Found     bool            `json:"found"`
+	Value     json.RawMessage `json:"value,omitempty"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"`
+	UpdatedAt *time.Time      `json:"updated_at,omitempty"`
+}
+
+// MemoryKey describes a key in memory.list
+type MemoryKey struct {
+	Key       string     `json:"key"`
+	Size      int        `json:"size"` // bytes of the value's JSON
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// MemoryListResult is the result of memory.list
+type MemoryListResult struct {
+	Keys []MemoryKey `json:"keys"`
+}
+
+// MemoryDeleteResult is the result of memory.delete
+type MemoryDeleteResult struct {
+	Deleted bool `json:"deleted"`
+}
+
+var memoryNamespacePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// NewMemoryToolset creates the "memory" toolset, a key-value scratchpad agents use to keep
+// intermediate state across tool calls: get, set, delete, and list. Keys live in named
+// namespaces within the caller's scope, and may expire.
+//
+// Example:
+//
+//	memory, err := utilitytools.NewMemoryToolset(utilitytools.MemoryConfig{DefaultTTL: 24 * time.Hour})
+//	if err != nil {
+//	    return err
+//	}
+//	server := mcp.NewServer(mcp.ServerConfig{
+//	    Name:     "agent",
+//	    Toolsets: []*tools.Toolset{memory}, // lists "memory.get", "memory.set", ...
+//	})
+func NewMemoryToolset(cfg MemoryConfig) (*tools.Toolset, error) {
+	return newMemoryToolset(cfg, time.Now)
+}
+
+func newMemoryToolset(cfg MemoryConfig, now func() time.Time) (*tools.Toolset, error) {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryKVStore()
+	}
+	if cfg.Scope == nil {
+		cfg.Scope = sessionScope
+	}
+	if cfg.MaxValueBytes <= 0 {
+		cfg.MaxValueBytes = DefaultMemoryMaxValueBytes
+	}
+	if cfg.MaxKeys <= 0 {
+		cfg.MaxKeys = DefaultMemoryMaxKeys
+	}
+	if cfg.MaxTTL > 0 && cfg.DefaultTTL > cfg.MaxTTL {
+		return nil, errors.New("memory tools: DefaultTTL exceeds MaxTTL")
+	}
+	m := &memoryTools{cfg: cfg, now: now}
+
+	return tools.NewToolset("memory",
+		tools.NewTool("get", "Reads the value stored under a key. found is false if the key is not set or has expired.", m.get,
+			tools.WithCategory("memory"),
+			tools.WithVerb("Reading memory"),
+		),
+		tools.NewTool("set", "Stores a JSON value under a key, replacing any previous value, to keep state across tool calls. Set ttl_seconds to make it expire.", m.set,
+			tools.WithCategory("memory"),
+			tools.WithVerb("Writing memory"),
+		),
+		tools.NewTool("delete", "Deletes a key", m.delete,
+			tools.WithCategory("memory"),
+			tools.WithVerb("Deleting memory"),
+			tools.WithDestructive(true),
+		),
+		tools.NewTool("list", "Lists the keys set in a namespace, with their sizes and expiry, without their values", m.list,
+			tools.WithCategory("memory"),
+			tools.WithVerb("Listing memory"),
+		),
+	), nil
+}
+
+// sessionScope scopes entries to the session, or to the principal without one
+func sessionScope(ctx context.Context) string {
+	if id := mcpctx.SessionID(ctx); id != "" {
+		return "session:" + id
+	}
+	if principal := mcpctx.Principal(ctx); principal != nil {
+		return "principal:" + principal.Subject
+	}
+	return ""
+}
+
+type memoryTools struct {
+	cfg MemoryConfig
+	now func() time.Time
+}
+
+// namespace returns the store namespace of a call's namespace parameter
+func (m *memoryTools) namespace(ctx context.Context, name string) (string, error) {
+	if name == "" {
+		name = defaultMemoryNamespace
+	}
+	if !memoryNamespacePattern.MatchString(name) {
+		return "", tools.NewInvalidParamsError("namespace must be 1-64 letters, digits, '_', '.', or '-'")
+	}
+	return m.cfg.Scope(ctx) + "/" + name, nil
+}
+
+func checkMemoryKey(key string) error {
+	if key == "" || len(key) > 256 {
+		return tools.NewInvalidParamsError("key must be 1-256 bytes")
+	}
+	return nil
+}
+
+func (m *memoryTools) get(ctx context.Context, params MemoryGetParams) (*MemoryValue, error) {
+	if err := checkMemoryKey(params.Key); err != nil {
+		return nil, err
+	}
+	namespace, err := m.namespace(ctx, params.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := m.cfg.Store.Get(ctx, namespace, params.Key)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", params.Key, err)
+	}
+	if entry == nil {
+		return &MemoryValue{Key: params.Key}, nil
+	}
+	updated := entry.Updated
+	return &MemoryValue{
+		Key:       entry.Key,
+		Found:     true,
+		Value:     entry.Value,
+		ExpiresAt: expiresAt(entry.Expires),
+		UpdatedAt: &updated,
+	}, nil
+}
+
+func (m *memoryTools) set(ctx context.Context, params MemorySetParams) (*MemoryValue, error) {
+	if err := checkMemoryKey(params.Key); err != nil {
+		return nil, err
+	}
+	namespace, err := m.namespace(ctx, params.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if params.TTLSeconds < 0 {
+		return nil, tools.NewInvalidParamsError("ttl_seconds must not be negative")
+	}
+	value, err := json.Marshal(params.Value)
+	if err != nil {
+		return nil, tools.NewInvalidParamsError(fmt.Sprintf("value: %v", err))
+	}
+	if len(value) > m.cfg.MaxValueBytes {
+		return nil, tools.NewInvalidParamsError(fmt.Sprintf("value is %d bytes, more than the limit of %d", len(value), m.cfg.MaxValueBytes))
+	}
+
+	ttl := m.cfg.DefaultTTL
+	if params.TTLSeconds > 0 {
+		ttl = time.Duration(params.TTLSeconds) * time.Second
+		if m.cfg.MaxTTL > 0 && ttl > m.cfg.MaxTTL {
+			ttl = m.cfg.MaxTTL
+		}
+	}
+
+	existing, err := m.cfg.Store.Get(ctx, namespace, params.Key)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", params.Key, err)
+	}
+	if existing == nil {
+		keys, err := m.cfg.Store.List(ctx, namespace, "")
+		if err != nil {
+			return nil, fmt.Errorf("counting keys: %w", err)
+		}
+		if len(keys) >= m.cfg.MaxKeys {
+			return nil, fmt.Errorf("namespace holds the maximum of %d keys; delete some first", m.cfg.MaxKeys)
+		}
+	}
+
+	now := m.now()
+	entry := KVEntry{Key: params.Key, Value: value, Updated: now}
+	if ttl > 0 {
+		entry.Expires = now.Add(ttl)
+	}
+	if err := m.cfg.Store.Set(ctx, namespace, entry); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", params.Key, err)
+	}
+	return &MemoryValue{
+		Key:       entry.Key,
+		Found:     true,
+		ExpiresAt: expiresAt(entry.Expires),
+		UpdatedAt: &now,
+	}, nil
+}
+
+func (m *memoryTools) delete(ctx context.Context, params MemoryGetParams) (*MemoryDeleteResult, error) {
+	if err := checkMemoryKey(params.Key); err != nil {
+		return nil, err
+	}
+	namespace, err := m.namespace(ctx, params.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := m.cfg.Store.Delete(ctx, namespace, params.Key)
+	if err != nil {
+		return nil, fmt.Errorf("deleting %s: %w", params.Key, err)
+	}
+	return &MemoryDeleteResult{Deleted: deleted}, nil
+}
+
+func (m *memoryTools) list(ctx context.Context, params MemoryListParams) (*MemoryListResult, error) {
+	namespace, err := m.namespace(ctx, params.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := m.cfg.Store.List(ctx, namespace, params.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("listing keys: %w", err)
+	}
+	result := &MemoryListResult{Keys: make([]MemoryKey, len(entries))}
+	for i, entry := range entries {
+		result.Keys[i] = MemoryKey{
+			Key:       entry.Key,
+			Size:      len(entry.Value),
+			ExpiresAt: expiresAt(entry.Expires),
+			UpdatedAt: entry.Updated,
+		}
+	}
+	return result, nil
+}
+
+// expiresAt returns nil for entries that do not expire
+func expiresAt(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
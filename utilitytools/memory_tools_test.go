@@ -0,0 +1,163 @@
+package utilitytools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+	"github.com/mhpenta/minimcp/tools/mcpctx"
+)
+
+// newMemoryTools builds the toolset over a store with a controllable clock and returns its
+// tools by unprefixed name
+func newMemoryTools(t *testing.T, cfg MemoryConfig) (*time.Time, map[string]tools.Tool) {
+	t.Helper()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := func() time.Time { return now }
+	store := NewMemoryKVStore()
+	store.now = clock
+	cfg.Store = store
+	set, err := newMemoryToolset(cfg, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]tools.Tool)
+	for _, tool := range set.Tools() {
+		byName[strings.TrimPrefix(tool.Spec().Name, "memory.")] = tool
+	}
+	return &now, byName
+}
+
+func TestMemoryToolset_SetGetListDelete(t *testing.T) {
+	_, memory := newMemoryTools(t, MemoryConfig{})
+
+	var set MemoryValue
+	if err := run(t, memory["set"], `{"key": "plan", "value": {"step": 2, "done": ["a"]}}`, &set); err != nil {
+		t.Fatal(err)
+	}
+	if !set.Found || set.ExpiresAt != nil {
+		t.Errorf("set = %+v", set)
+	}
+	run(t, memory["set"], `{"key": "notes", "value": "draft"}`, &set)
+	run(t, memory["set"], `{"key": "plan", "value": "other", "namespace": "scratch"}`, &set)
+
+	var got MemoryValue
+	if err := run(t, memory["get"], `{"key": "plan"}`, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Found || string(got.Value) != `{"done":["a"],"step":2}` {
+		t.Errorf("get = %+v (%s)", got, got.Value)
+	}
+	if err := run(t, memory["get"], `{"key": "missing"}`, &got); err != nil || got.Found {
+		t.Errorf("get missing = %+v, %v", got, err)
+	}
+
+	var list MemoryListResult
+	if err := run(t, memory["list"], `{}`, &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Keys) != 2 || list.Keys[0].Key != "notes" || list.Keys[1].Key != "plan" || list.Keys[0].Size != len(`"draft"`) {
+		t.Errorf("list = %+v", list)
+	}
+	run(t, memory["list"], `{"prefix": "pl", "namespace": "scratch"}`, &list)
+	if len(list.Keys) != 1 || list.Keys[0].Key != "plan" {
+		t.Errorf("list scratch = %+v", list)
+	}
+
+	var deleted MemoryDeleteResult
+	if err := run(t, memory["delete"], `{"key": "plan"}`, &deleted); err != nil || !deleted.Deleted {
+		t.Errorf("delete = %+v, %v", deleted, err)
+	}
+	if run(t, memory["delete"], `{"key": "plan"}`, &deleted); deleted.Deleted {
+		t.Error("expected deleting a missing key to report false")
+	}
+	if run(t, memory["get"], `{"key": "plan", "namespace": "scratch"}`, &got); !got.Found {
+		t.Error("expected the scratch namespace to keep its key")
+	}
+}
+
+func TestMemoryToolset_TTL(t *testing.T) {
+	now, memory := newMemoryTools(t, MemoryConfig{DefaultTTL: time.Hour, MaxTTL: 2 * time.Hour})
+
+	var set MemoryValue
+	run(t, memory["set"], `{"key": "default", "value": 1}`, &set)
+	if set.ExpiresAt == nil || !set.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("default ttl expires_at = %v", set.ExpiresAt)
+	}
+	run(t, memory["set"], `{"key": "capped", "value": 1, "ttl_seconds": 86400}`, &set)
+	if set.ExpiresAt == nil || !set.ExpiresAt.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("capped ttl expires_at = %v", set.ExpiresAt)
+	}
+
+	*now = now.Add(90 * time.Minute)
+	var got MemoryValue
+	if run(t, memory["get"], `{"key": "default"}`, &got); got.Found {
+		t.Error("expected the default-ttl key to have expired")
+	}
+	var list MemoryListResult
+	if run(t, memory["list"], `{}`, &list); len(list.Keys) != 1 || list.Keys[0].Key != "capped" {
+		t.Errorf("list = %+v", list)
+	}
+
+	if err := run(t, memory["set"], `{"key": "k", "value": 1, "ttl_seconds": -1}`, &set); !isInvalidParams(err) {
+		t.Errorf("expected a negative ttl to be invalid params, got %v", err)
+	}
+	if _, err := NewMemoryToolset(MemoryConfig{DefaultTTL: time.Hour, MaxTTL: time.Minute}); err == nil {
+		t.Error("expected an error for DefaultTTL over MaxTTL")
+	}
+}
+
+func TestMemoryToolset_Scopes(t *testing.T) {
+	_, memory := newMemoryTools(t, MemoryConfig{})
+	execute := func(ctx context.Context, tool tools.Tool, args string, out interface{}) {
+		t.Helper()
+		result, err := tool.Execute(ctx, json.RawMessage(args))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := json.Marshal(result.Output)
+		json.Unmarshal(data, out)
+	}
+	alice := mcpctx.WithSessionID(context.Background(), "session-a")
+	bob := mcpctx.WithSessionID(context.Background(), "session-b")
+
+	var set MemoryValue
+	execute(alice, memory["set"], `{"key": "secret", "value": "a"}`, &set)
+	var got MemoryValue
+	execute(bob, memory["get"], `{"key": "secret"}`, &got)
+	if got.Found {
+		t.Error("expected sessions not to see each other's keys")
+	}
+	execute(alice, memory["get"], `{"key": "secret"}`, &got)
+	if !got.Found || string(got.Value) != `"a"` {
+		t.Errorf("get = %+v", got)
+	}
+}
+
+func TestMemoryToolset_Limits(t *testing.T) {
+	_, memory := newMemoryTools(t, MemoryConfig{MaxValueBytes: 10, MaxKeys: 2})
+
+	var set MemoryValue
+	for _, args := range []string{
+		`{"key": "", "value": 1}`,
+		`{"key": "k", "value": "longer than ten bytes"}`,
+		`{"key": "k", "value": 1, "namespace": "a/b"}`,
+	} {
+		if err := run(t, memory["set"], args, &set); !isInvalidParams(err) {
+			t.Errorf("%s: expected invalid params, got %v", args, err)
+		}
+	}
+
+	run(t, memory["set"], `{"key": "a", "value": 1}`, &set)
+	run(t, memory["set"], `{"key": "b", "value": 1}`, &set)
+	if err := run(t, memory["set"], `{"key": "c", "value": 1}`, &set); err == nil {
+		t.Error("expected an error over MaxKeys")
+	}
+	// Replacing an existing key is allowed at the limit
+	if err := run(t, memory["set"], `{"key": "a", "value": 2}`, &set); err != nil {
+		t.Errorf("replace at limit: %v", err)
+	}
+}
@@ -0,0 +1,383 @@
+package utilitytools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+	"github.com/mhpenta/minimcp/tools/mcpctx"
+)
+
+// Defaults for ShellConfig limits
+const (
+	DefaultShellTimeout        = 30 * time.Second
+	DefaultShellMaxOutputBytes = 64 * 1024
+)
+
+// ShellCommand is a command the shell tool may run. Clients pick it by Name and fill the
+// {placeholders} in Args; they cannot pass other arguments. Commands run directly, never
+// through a shell, so values cannot chain or redirect commands.
+//
+// Example:
+//
+//	utilitytools.ShellCommand{
+//	    Name:        "test",
+//	    Description: "Runs the tests of a package",
+//	    Path:        "go",
+//	    Args:        []string{"test", "./{package}/..."},
+//	    Patterns:    map[string]string{"package": `[a-z0-9_/]+`},
+//	}
+type ShellCommand struct {
+	Name        string
+	Description string
+
+	// Path is the executable, looked up in PATH when it contains no path separator
+	Path string
+
+	// Args are argument templates; each {name} is replaced by the call's value for name
+	Args []string
+
+	// Patterns restricts placeholder values, keyed by placeholder name; a value must match
+	// the whole pattern. Values without a pattern may not start with "-", so they cannot
+	// add options. Only the working directory is confined to Dir: a value such as "../.."
+	// reaches the command's arguments unchanged, so give placeholders naming paths a
+	// pattern that excludes "..".
+	Patterns map[string]string
+}
+
+// ShellConfig configures the shell tool
+type ShellConfig struct {
+	// Commands are the only commands the tool runs
+	Commands []ShellCommand
+
+	// Dir is the working directory. Calls may pick a subdirectory, but not leave Dir.
+	Dir string
+
+	Timeout        time.Duration // per command (default 30s)
+	MaxOutputBytes int           // kept of stdout and of stderr each (default 64 KiB)
+
+	// Env is the commands' environment. Defaults to the server's PATH, HOME, and temporary
+	// directory variables alone, so secrets in its environment are not passed on. HOME lets
+	// tools such as go and git find their caches and configuration.
+	Env []string
+
+	// Audit receives a record of every command run. Defaults to logging it to Logger.
+	Audit func(ctx context.Context, record ShellAuditRecord)
+
+	Logger *slog.Logger
+}
+
+// ShellParams defines parameters for the shell tool
+type ShellParams struct {
+	Command string            `json:"command" jsonschema:"name of the command to run"`
+	Args    map[string]string `json:"args,omitempty" jsonschema:"values for the command's placeholders"`
+	Dir     string            `json:"dir,omitempty" jsonschema:"working directory relative to the tool's directory"`
+}
+
+// ShellResult is the outcome of a command
+type ShellResult struct {
+	ExitCode   int    `json:"exit_code"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr,omitempty"`
+	Truncated  bool   `json:"truncated,omitempty"` // output beyond the limit was dropped
+	TimedOut   bool   `json:"timed_out,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// ShellAuditRecord describes a command the shell tool ran
+type ShellAuditRecord struct {
+	Command  string   // the command's name
+	Argv     []string // the executable and its expanded arguments
+	Dir      string   // working directory
+	Caller   string   // subject of the authenticated principal, if any
+	ExitCode int      // -1 if the command did not finish
+	Duration time.Duration
+	Err      error // set when the command could not run or timed out
+}
+
+var shellPlaceholder = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// shellEnvVars are the variables commands inherit from the server by default
+var shellEnvVars = []string{"PATH", "HOME", "TMPDIR", "TEMP", "TMP", "USERPROFILE", "LOCALAPPDATA", "SYSTEMROOT"}
+
+// defaultShellEnv returns the server's values of shellEnvVars that are set
+func defaultShellEnv() []string {
+	var env []string
+	for _, name := range shellEnvVars {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+// NewShellTool creates a tool named "shell" that runs the allow-listed commands of cfg.
+// It runs nothing else: register it only on servers whose clients may run these commands.
+//
+// Example:
+//
+//	shell, err := utilitytools.NewShellTool(utilitytools.ShellConfig{
+//	    Dir: "/srv/project",
+//	    Commands: []utilitytools.ShellCommand{
+//	        {Name: "status", Description: "Shows the working tree status", Path: "git", Args: []string{"status", "--short"}},
+//	        {Name: "log", Description: "Shows commits of a file", Path: "git", Args: []string{"log", "--oneline", "--", "{file}"}},
+//	    },
+//	})
+func NewShellTool(cfg ShellConfig) (tools.Tool, error) {
+	if len(cfg.Commands) == 0 {
+		return nil, errors.New("shell tool: no commands allowed")
+	}
+	if cfg.Dir == "" {
+		return nil, errors.New("shell tool: no working directory")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultShellTimeout
+	}
+	if cfg.MaxOutputBytes <= 0 {
+		cfg.MaxOutputBytes = DefaultShellMaxOutputBytes
+	}
+	if cfg.Env == nil {
+		cfg.Env = defaultShellEnv()
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	dir, err := newFSSandbox(FSConfig{Roots: []string{cfg.Dir}})
+	if err != nil {
+		return nil, fmt.Errorf("shell tool: %w", err)
+	}
+
+	shell := &shellTool{cfg: cfg, dir: dir, commands: make(map[string]*allowedCommand)}
+	var help []string
+	for _, command := range cfg.Commands {
+		allowed, err := newAllowedCommand(command)
+		if err != nil {
+			return nil, fmt.Errorf("shell tool: %w", err)
+		}
+		if _, dup := shell.commands[command.Name]; dup {
+			return nil, fmt.Errorf("shell tool: command %q is defined twice", command.Name)
+		}
+		shell.commands[command.Name] = allowed
+		help = append(help, allowed.help())
+	}
+	sort.Strings(help)
+
+	description := "Runs one of these commands and returns its exit code and output:\n" + strings.Join(help, "\n")
+	return tools.NewTool("shell", description, shell.run,
+		tools.WithCategory("shell"),
+		tools.WithVerb("Running command"),
+		tools.WithDestructive(true),
+		tools.WithParameterDescription("args", "values for the command's {placeholders}, such as {\"file\": \"main.go\"}"),
+	), nil
+}
+
+// allowedCommand is a ShellCommand with its patterns compiled
+type allowedCommand struct {
+	ShellCommand
+	placeholders []string
+	patterns     map[string]*regexp.Regexp
+}
+
+func newAllowedCommand(command ShellCommand) (*allowedCommand, error) {
+	if command.Name == "" || command.Path == "" {
+		return nil, fmt.Errorf("command %q needs a name and a path", command.Name)
+	}
+	allowed := &allowedCommand{ShellCommand: command, patterns: make(map[string]*regexp.Regexp)}
+	seen := make(map[string]bool)
+	for _, arg := range command.Args {
+		for _, m := range shellPlaceholder.FindAllStringSubmatch(arg, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				allowed.placeholders = append(allowed.placeholders, m[1])
+			}
+		}
+	}
+	for name, pattern := range command.Patterns {
+		if !seen[name] {
+			return nil, fmt.Errorf("command %q has a pattern for unknown placeholder %q", command.Name, name)
+		}
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("command %q: pattern for %q: %w", command.Name, name, err)
+		}
+		allowed.patterns[name] = re
+	}
+	return allowed, nil
+}
+
+// help describes the command for the tool description
+func (c *allowedCommand) help() string {
+	line := "- " + c.Name
+	if len(c.placeholders) > 0 {
+		line += " (args: " + strings.Join(c.placeholders, ", ") + ")"
+	}
+	if c.Description != "" {
+		line += ": " + c.Description
+	}
+	return line
+}
+
+// expand fills the placeholders of the argument templates with validated values
+func (c *allowedCommand) expand(values map[string]string) ([]string, error) {
+	for name := range values {
+		if !containsString(c.placeholders, name) {
+			return nil, tools.NewInvalidParamsError(fmt.Sprintf("command %s takes no argument %q", c.Name, name))
+		}
+	}
+	for _, name := range c.placeholders {
+		value, ok := values[name]
+		if !ok {
+			return nil, tools.NewInvalidParamsError(fmt.Sprintf("command %s needs argument %q", c.Name, name))
+		}
+		if re, ok := c.patterns[name]; ok {
+			if !re.MatchString(value) {
+				return nil, tools.NewInvalidParamsError(fmt.Sprintf("argument %q must match %s", name, c.ShellCommand.Patterns[name]))
+			}
+		} else if strings.HasPrefix(value, "-") {
+			return nil, tools.NewInvalidParamsError(fmt.Sprintf("argument %q may not start with \"-\"", name))
+		}
+	}
+
+	argv := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		argv[i] = shellPlaceholder.ReplaceAllStringFunc(arg, func(m string) string {
+			return values[m[1:len(m)-1]]
+		})
+	}
+	return argv, nil
+}
+
+// shellTool runs allow-listed commands
+type shellTool struct {
+	cfg      ShellConfig
+	dir      *fsSandbox
+	commands map[string]*allowedCommand
+}
+
+func (s *shellTool) run(ctx context.Context, params ShellParams) (*ShellResult, error) {
+	command, ok := s.commands[params.Command]
+	if !ok {
+		return nil, tools.NewInvalidParamsError(fmt.Sprintf("command %q is not allowed", params.Command))
+	}
+	args, err := command.expand(params.Args)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := s.dir.resolve(params.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, tools.NewInvalidParamsError(fmt.Sprintf("%q is not a directory", params.Dir))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command.Path, args...)
+	cmd.Dir = dir
+	cmd.Env = s.cfg.Env
+	// Children that keep the output open must not hold the call past the timeout
+	cmd.WaitDelay = time.Second
+	stdout := &cappedBuffer{max: s.cfg.MaxOutputBytes}
+	stderr := &cappedBuffer{max: s.cfg.MaxOutputBytes}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	start := time.Now()
+	runErr := cmd.Run()
+	result := &ShellResult{
+		ExitCode:   cmd.ProcessState.ExitCode(),
+		Stdout:     stdout.String(),
+		Stderr:     stderr.String(),
+		Truncated:  stdout.truncated || stderr.truncated,
+		TimedOut:   errors.Is(ctx.Err(), context.DeadlineExceeded),
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+
+	record := ShellAuditRecord{
+		Command:  command.Name,
+		Argv:     append([]string{command.Path}, args...),
+		Dir:      dir,
+		ExitCode: result.ExitCode,
+		Duration: time.Since(start),
+	}
+	if principal := mcpctx.Principal(ctx); principal != nil {
+		record.Caller = principal.Subject
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case result.TimedOut:
+		record.Err = fmt.Errorf("timed out after %s", s.cfg.Timeout)
+	case runErr != nil && !errors.As(runErr, &exitErr):
+		// The command could not be started
+		record.Err = runErr
+	}
+	s.audit(ctx, record)
+
+	if record.Err != nil && !result.TimedOut {
+		return nil, fmt.Errorf("running %s: %w", command.Name, runErr)
+	}
+	return result, nil
+}
+
+func (s *shellTool) audit(ctx context.Context, record ShellAuditRecord) {
+	if s.cfg.Audit != nil {
+		s.cfg.Audit(ctx, record)
+		return
+	}
+	attrs := []interface{}{
+		"command", record.Command,
+		"argv", record.Argv,
+		"dir", record.Dir,
+		"exit_code", record.ExitCode,
+		"duration_ms", record.Duration.Milliseconds(),
+	}
+	if record.Caller != "" {
+		attrs = append(attrs, "caller", record.Caller)
+	}
+	if record.Err != nil {
+		attrs = append(attrs, "error", record.Err)
+	}
+	s.cfg.Logger.Info("shell command", attrs...)
+}
+
+// cappedBuffer keeps the first max bytes written to it
+type cappedBuffer struct {
+	buf       []byte
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - len(b.buf); room < len(p) {
+		b.buf = append(b.buf, p[:max(room, 0)]...)
+		b.truncated = true
+	} else {
+		b.buf = append(b.buf, p...)
+	}
+	// Report everything written, so the command is not stopped by a short write
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	return string(b.buf)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
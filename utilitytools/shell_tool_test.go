@@ -0,0 +1,158 @@
+package utilitytools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+	"github.com/mhpenta/minimcp/tools/mcpctx"
+)
+
+// TestShellHelperProcess is the command the shell tool tests run
+func TestShellHelperProcess(t *testing.T) {
+	if os.Getenv("SHELL_TOOL_HELPER") != "1" {
+		return
+	}
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+	wd, _ := os.Getwd()
+	switch args[0] {
+	case "echo":
+		fmt.Printf("%s in %s", strings.Join(args[1:], " "), filepath.Base(wd))
+	case "fail":
+		fmt.Fprint(os.Stderr, "bad input")
+		os.Exit(3)
+	case "flood":
+		fmt.Print(strings.Repeat("x", 1000))
+	case "sleep":
+		time.Sleep(10 * time.Second)
+	}
+	os.Exit(0)
+}
+
+func newTestShellTool(t *testing.T, cfg ShellConfig) (string, tools.Tool) {
+	t.Helper()
+	helper := func(name string, args ...string) ShellCommand {
+		return ShellCommand{Name: name, Path: os.Args[0], Args: append([]string{"-test.run=TestShellHelperProcess", "--", name}, args...)}
+	}
+	cfg.Dir = t.TempDir()
+	os.Mkdir(filepath.Join(cfg.Dir, "sub"), 0o755)
+	cfg.Env = []string{"SHELL_TOOL_HELPER=1"}
+	cfg.Commands = []ShellCommand{
+		helper("echo", "{word}", "--label={label}"),
+		helper("fail"),
+		helper("flood"),
+		helper("sleep"),
+	}
+	cfg.Commands[0].Patterns = map[string]string{"label": `[a-z]+`}
+
+	tool, err := NewShellTool(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg.Dir, tool
+}
+
+func TestShellTool(t *testing.T) {
+	var audited []ShellAuditRecord
+	audit := func(ctx context.Context, record ShellAuditRecord) {
+		audited = append(audited, record)
+	}
+	// Commands run the test binary, which can be slow to start under the race detector,
+	// so only the sleep call gets a short timeout
+	_, shell := newTestShellTool(t, ShellConfig{MaxOutputBytes: 100, Audit: audit})
+	_, impatient := newTestShellTool(t, ShellConfig{Timeout: 200 * time.Millisecond, Audit: audit})
+	ctx := mcpctx.WithPrincipal(context.Background(), &mcpctx.Identity{Subject: "alice"})
+	callTool := func(tool tools.Tool, args string) (*ShellResult, error) {
+		out, err := tool.Execute(ctx, []byte(args))
+		if err != nil {
+			return nil, err
+		}
+		return out.Output.(*ShellResult), nil
+	}
+	call := func(args string) (*ShellResult, error) {
+		return callTool(shell, args)
+	}
+
+	result, err := call(`{"command":"echo","args":{"word":"hello world; rm -rf /","label":"greeting"},"dir":"sub"}`)
+	if err != nil || result.ExitCode != 0 || result.Stdout != "hello world; rm -rf / --label=greeting in sub" {
+		t.Errorf("expected the templated arguments to be passed verbatim, got %+v, %v", result, err)
+	}
+	if len(audited) != 1 || audited[0].Caller != "alice" || audited[0].Command != "echo" {
+		t.Errorf("expected the call to be audited, got %+v", audited)
+	}
+
+	if result, err := call(`{"command":"fail"}`); err != nil || result.ExitCode != 3 || result.Stderr != "bad input" {
+		t.Errorf("expected the exit code and stderr, got %+v, %v", result, err)
+	}
+	if result, err := call(`{"command":"flood"}`); err != nil || len(result.Stdout) != 100 || !result.Truncated {
+		t.Errorf("expected truncated output, got %d bytes, %v", len(result.Stdout), err)
+	}
+	if result, err := callTool(impatient, `{"command":"sleep"}`); err != nil || !result.TimedOut {
+		t.Errorf("expected a timeout, got %+v, %v", result, err)
+	}
+	if audited[len(audited)-1].Err == nil {
+		t.Error("expected the timeout to be audited as an error")
+	}
+}
+
+func TestShellTool_Rejects(t *testing.T) {
+	_, shell := newTestShellTool(t, ShellConfig{Audit: func(context.Context, ShellAuditRecord) {
+		t.Error("expected rejected calls not to run")
+	}})
+
+	tests := []string{
+		`{"command":"rm","args":{}}`,
+		`{"command":"echo","args":{"word":"hi"}}`,
+		`{"command":"echo","args":{"word":"--exec=evil","label":"x"}}`,
+		`{"command":"echo","args":{"word":"hi","label":"Not-Allowed"}}`,
+		`{"command":"echo","args":{"word":"hi","label":"x","extra":"y"}}`,
+		`{"command":"fail","dir":"../.."}`,
+	}
+	for _, args := range tests {
+		if _, err := shell.Execute(context.Background(), []byte(args)); !isInvalidParams(err) {
+			t.Errorf("%s: expected invalid params, got %v", args, err)
+		}
+	}
+}
+
+func TestNewShellTool_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	tests := []ShellConfig{
+		{Dir: dir},
+		{Commands: []ShellCommand{{Name: "ls", Path: "ls"}}},
+		{Dir: dir, Commands: []ShellCommand{{Name: "ls", Path: "ls"}, {Name: "ls", Path: "ls"}}},
+		{Dir: dir, Commands: []ShellCommand{{Name: "ls", Path: "ls", Patterns: map[string]string{"path": ".*"}}}},
+	}
+	for i, cfg := range tests {
+		if _, err := NewShellTool(cfg); err == nil {
+			t.Errorf("config %d: expected an error", i)
+		}
+	}
+}
+
+func TestDefaultShellEnv(t *testing.T) {
+	t.Setenv("HOME", "/home/tester")
+	t.Setenv("TMPDIR", "/tmp/tester")
+	t.Setenv("SHELL_TOOL_SECRET", "hunter2")
+
+	env := strings.Join(defaultShellEnv(), "\n")
+	for _, want := range []string{"PATH=", "HOME=/home/tester", "TMPDIR=/tmp/tester"} {
+		if !strings.Contains(env, want) {
+			t.Errorf("expected %s in the default environment, got %q", want, env)
+		}
+	}
+	if strings.Contains(env, "hunter2") {
+		t.Error("expected other variables to be left out")
+	}
+}
@@ -0,0 +1,125 @@
+package utilitytools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SQLDialect names the database family the SQL tools talk to
+type SQLDialect string
+
+const (
+	SQLDialectPostgres SQLDialect = "postgres"
+	SQLDialectMySQL    SQLDialect = "mysql"
+	SQLDialectSQLite   SQLDialect = "sqlite"
+	SQLDialectDuckDB   SQLDialect = "duckdb"
+)
+
+// sqlDialectRules are the read-only validation rules and model hints of a dialect
+type sqlDialectRules struct {
+	// name is the database's display name
+	name string
+	// statements are the leading keywords of allowed queries
+	statements []string
+	// forbidden are keywords rejected anywhere in a query
+	forbidden []string
+	// blockBackslash rejects backslashes, which start client meta-commands
+	blockBackslash bool
+	// quote opens and closes a quoted identifier
+	quote string
+	// lexing is the quoting and comment syntax statements are split by
+	lexing sqlLexing
+	// introspection tells models how to list tables and columns
+	introspection string
+	// explain formats a query's EXPLAIN statement, and explainAnalyze its EXPLAIN
+	// ANALYZE statement, which is empty when the dialect has none
+	explain, explainAnalyze string
+	// plan and analyzedPlan are the formats of the EXPLAIN output
+	plan, analyzedPlan sqlPlanFormat
+	// readOnly is how sessions are made read-only for WithReadOnlyTransactions
+	readOnly sqlReadOnlyMode
+}
+
+// sqlWriteKeywords are forbidden in every dialect. COMMIT and ROLLBACK would end a
+// read-only transaction early.
+var sqlWriteKeywords = []string{
+	"INSERT", "UPDATE", "DELETE", "DROP", "CREATE", "ALTER",
+	"TRUNCATE", "GRANT", "REVOKE", "COMMIT", "ROLLBACK",
+}
+
+var sqlDialects = map[SQLDialect]sqlDialectRules{
+	SQLDialectPostgres: {
+		name:           "PostgreSQL",
+		statements:     []string{"SELECT", "WITH"},
+		forbidden:      append(sqlWriteKeywords, "COPY"),
+		blockBackslash: true,
+		quote:          `"`,
+		lexing:         sqlLexing{escapeStrings: true, dollarQuotes: true},
+		introspection: "List tables with SELECT table_schema, table_name FROM information_schema.tables WHERE table_schema NOT IN ('pg_catalog', 'information_schema'); " +
+			"describe a table with SELECT column_name, data_type, is_nullable FROM information_schema.columns WHERE table_name = '...'",
+		explain:        "EXPLAIN (FORMAT JSON) %s",
+		explainAnalyze: "EXPLAIN (ANALYZE, FORMAT JSON) %s",
+		plan:           sqlPlanJSON,
+		analyzedPlan:   sqlPlanJSON,
+		readOnly:       sqlReadOnlySetTransaction,
+	},
+	SQLDialectMySQL: {
+		name:       "MySQL",
+		statements: []string{"SELECT", "WITH", "SHOW", "DESCRIBE", "DESC", "EXPLAIN"},
+		forbidden:  append(sqlWriteKeywords, "LOAD", "OUTFILE", "DUMPFILE", "HANDLER", "CALL", "LOCK"),
+		quote:      "`",
+		lexing:     sqlLexing{backslashEscapes: true, hashComments: true, backtickQuotes: true, executableComments: true},
+		introspection: "List tables with SHOW TABLES; describe a table with DESCRIBE table_name or " +
+			"SELECT column_name, column_type FROM information_schema.columns WHERE table_schema = DATABASE()",
+		explain:        "EXPLAIN FORMAT=JSON %s",
+		explainAnalyze: "EXPLAIN ANALYZE %s",
+		plan:           sqlPlanJSON,
+		analyzedPlan:   sqlPlanText,
+		readOnly:       sqlReadOnlyTxOption,
+	},
+	SQLDialectSQLite: {
+		name:       "SQLite",
+		statements: []string{"SELECT", "WITH", "EXPLAIN"},
+		forbidden:  append(sqlWriteKeywords, "ATTACH", "DETACH", "PRAGMA", "VACUUM", "REINDEX"),
+		quote:      `"`,
+		lexing:     sqlLexing{backtickQuotes: true, bracketQuotes: true},
+		introspection: "List tables with SELECT name, type FROM sqlite_master WHERE type IN ('table', 'view'); " +
+			"describe a table with SELECT * FROM pragma_table_info('table_name')",
+		explain:  "EXPLAIN QUERY PLAN %s",
+		plan:     sqlPlanSteps,
+		readOnly: sqlReadOnlyQueryOnly,
+	},
+	SQLDialectDuckDB: {
+		name:       "DuckDB",
+		statements: []string{"SELECT", "WITH", "FROM", "SHOW", "DESCRIBE", "SUMMARIZE", "EXPLAIN"},
+		forbidden: append(sqlWriteKeywords, "COPY", "EXPORT", "IMPORT", "ATTACH", "DETACH", "INSTALL", "LOAD",
+			"PRAGMA", "SET", "CALL", "CHECKPOINT", "VACUUM"),
+		quote:  `"`,
+		lexing: sqlLexing{escapeStrings: true, dollarQuotes: true},
+		introspection: "List tables with SHOW ALL TABLES; describe a table with DESCRIBE table_name or " +
+			"SELECT column_name, data_type FROM information_schema.columns WHERE table_name = '...'",
+		explain:        "EXPLAIN (FORMAT JSON) %s",
+		explainAnalyze: "EXPLAIN (ANALYZE, FORMAT JSON) %s",
+		plan:           sqlPlanJSON,
+		analyzedPlan:   sqlPlanJSON,
+	},
+}
+
+// rules returns the dialect's validation rules
+func (d SQLDialect) rules() (sqlDialectRules, error) {
+	rules, ok := sqlDialects[d]
+	if !ok {
+		return sqlDialectRules{}, fmt.Errorf("unsupported SQL dialect %q", d)
+	}
+	return rules, nil
+}
+
+// QuoteIdentifier quotes a table or column name for the dialect, doubling embedded quotes:
+// "name" in Postgres, SQLite, and DuckDB, and `name` in MySQL
+func (d SQLDialect) QuoteIdentifier(name string) string {
+	quote := `"`
+	if rules, err := d.rules(); err == nil {
+		quote = rules.quote
+	}
+	return quote + strings.ReplaceAll(name, quote, quote+quote) + quote
+}
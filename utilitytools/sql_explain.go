@@ -0,0 +1,170 @@
+package utilitytools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Values of the SQL tool's explain parameter
+const (
+	SQLExplainPlan    = "plan"    // return the plan without running the query
+	SQLExplainAnalyze = "analyze" // run the query and return the plan with actual rows and timings
+)
+
+// explainAnalyzeTimeout caps how long EXPLAIN ANALYZE may run the query
+const explainAnalyzeTimeout = 10 * time.Second
+
+// SQLPlan is a query plan returned in place of rows when the SQL tool explains a query
+type SQLPlan struct {
+	Analyzed bool          `json:"analyzed,omitempty"` // the query ran, so the plan holds actual rows and timings
+	JSON     interface{}   `json:"json,omitempty"`     // the database's JSON plan (Postgres, MySQL, DuckDB)
+	Steps    []SQLPlanStep `json:"steps,omitempty"`    // SQLite's query plan
+	Text     string        `json:"text,omitempty"`     // the plan, when the database returns it as text
+}
+
+// SQLPlanStep is a step of SQLite's query plan. Steps form a tree through Parent.
+type SQLPlanStep struct {
+	ID     int64  `json:"id"`
+	Parent int64  `json:"parent"`
+	Detail string `json:"detail"`
+}
+
+// sqlPlanFormat is how a dialect returns EXPLAIN output
+type sqlPlanFormat int
+
+const (
+	sqlPlanJSON  sqlPlanFormat = iota // JSON text, in the last column of one or more rows
+	sqlPlanText                       // text lines, in the last column of each row
+	sqlPlanSteps                      // SQLite's id, parent, notused, detail rows
+)
+
+// explainSQLQuery validates a query and returns its plan instead of its rows. With
+// analyze the query runs, for at most explainAnalyzeTimeout, in a transaction that is
+// rolled back.
+func explainSQLQuery(ctx context.Context, logger *slog.Logger, db *sql.DB, dialect SQLDialect, query string, analyze bool, opts sqlQueryOptions) (*SQLQueryResult, error) {
+	query, failed, err := validateSQLQuery(dialect, query)
+	if err != nil {
+		return failed, err
+	}
+	rules, _ := dialect.rules()
+	if leadingKeyword.FindString(strings.ToUpper(query)) == "EXPLAIN" {
+		return &SQLQueryResult{
+			Success: false,
+			Error:   "Query is already an EXPLAIN statement; pass the query itself",
+		}, fmt.Errorf("query is already an EXPLAIN statement")
+	}
+
+	statement, format, timeout := rules.explain, rules.plan, opts.timeout
+	if analyze {
+		if rules.explainAnalyze == "" {
+			return &SQLQueryResult{
+				Success: false,
+				Error:   fmt.Sprintf("%s cannot analyze queries; use explain \"plan\"", rules.name),
+			}, fmt.Errorf("explain analyze not supported by %s", dialect)
+		}
+		statement, format = rules.explainAnalyze, rules.analyzedPlan
+		timeout = min(timeout, explainAnalyzeTimeout)
+	}
+	statement = fmt.Sprintf(statement, strings.TrimRight(query, "; \t\n"))
+
+	queryCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	// Analyzing runs the query; roll back anything it did
+	session, release, err := beginSQLSession(queryCtx, db, rules, opts.readOnly, analyze)
+	if err != nil {
+		return &SQLQueryResult{
+			Success: false,
+			Error:   fmt.Sprintf("SQL execution error: %v", err),
+		}, err
+	}
+	defer release()
+
+	rows, err := session.QueryContext(queryCtx, statement)
+	if err != nil {
+		return &SQLQueryResult{
+			Success: false,
+			Error:   fmt.Sprintf("SQL execution error: %v", err),
+		}, err
+	}
+	defer rows.Close()
+
+	plan, err := readSQLPlan(rows, format)
+	if err != nil {
+		return &SQLQueryResult{
+			Success: false,
+			Error:   fmt.Sprintf("Error reading plan: %v", err),
+		}, err
+	}
+	plan.Analyzed = analyze
+	executionTime := time.Since(start).Milliseconds()
+
+	logger.Info("SQL query explained",
+		"analyzed", analyze,
+		"execution_time_ms", executionTime)
+
+	return &SQLQueryResult{
+		Success:       true,
+		Plan:          plan,
+		ExecutionTime: executionTime,
+	}, nil
+}
+
+// readSQLPlan reads EXPLAIN output
+func readSQLPlan(rows *sql.Rows, format sqlPlanFormat) (*SQLPlan, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &SQLPlan{}
+	var lines []string
+	for rows.Next() {
+		if format == sqlPlanSteps {
+			var step SQLPlanStep
+			var notUsed interface{}
+			if err := rows.Scan(&step.ID, &step.Parent, &notUsed, &step.Detail); err != nil {
+				return nil, err
+			}
+			plan.Steps = append(plan.Steps, step)
+			continue
+		}
+
+		values := make([]interface{}, len(columns))
+		valuePtrs := make([]interface{}, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, err
+		}
+		switch last := values[len(values)-1].(type) {
+		case []byte:
+			lines = append(lines, string(last))
+		case nil:
+		default:
+			lines = append(lines, fmt.Sprint(last))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if format == sqlPlanSteps {
+		return plan, nil
+	}
+	plan.Text = strings.Join(lines, "\n")
+	if format == sqlPlanJSON {
+		// Plans that fail to parse are returned as text
+		if err := json.Unmarshal([]byte(plan.Text), &plan.JSON); err == nil {
+			plan.Text = ""
+		}
+	}
+	return plan, nil
+}
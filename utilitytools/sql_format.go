@@ -0,0 +1,96 @@
+package utilitytools
+
+import (
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Values of the SQL tool's format parameter
+const (
+	SQLFormatJSON     = "json"     // rows as JSON arrays of typed values
+	SQLFormatCSV      = "csv"      // rows as CSV text with a header row
+	SQLFormatTSV      = "tsv"      // rows as tab-separated text with a header row
+	SQLFormatMarkdown = "markdown" // rows as a Markdown table, the most compact for models
+)
+
+var sqlFormats = []string{SQLFormatJSON, SQLFormatCSV, SQLFormatTSV, SQLFormatMarkdown}
+
+// checkSQLFormat rejects unknown formats
+func checkSQLFormat(format string) error {
+	if format != "" && !slices.Contains(sqlFormats, format) {
+		return fmt.Errorf("format must be one of %s", strings.Join(sqlFormats, ", "))
+	}
+	return nil
+}
+
+// formatSQLRows moves a result's rows into Formatted as CSV, TSV, or a Markdown table.
+// JSON results are left as they are.
+func formatSQLRows(result *SQLQueryResult, format string) {
+	switch format {
+	case SQLFormatCSV:
+		result.Formatted = delimitedSQLRows(result, ',')
+	case SQLFormatTSV:
+		result.Formatted = delimitedSQLRows(result, '\t')
+	case SQLFormatMarkdown:
+		result.Formatted = markdownSQLRows(result)
+	default:
+		return
+	}
+	result.Format = format
+	result.Rows = nil
+}
+
+// delimitedSQLRows writes rows as CSV with the given separator. NULLs are empty fields.
+func delimitedSQLRows(result *SQLQueryResult, comma rune) string {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Comma = comma
+	w.Write(result.Columns)
+	for _, row := range result.Rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			if value != nil {
+				record[i] = fmt.Sprint(value)
+			}
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	return b.String()
+}
+
+// markdownSQLRows writes rows as a Markdown table, with NULL for nulls
+func markdownSQLRows(result *SQLQueryResult) string {
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, cell := range cells {
+			b.WriteString(" ")
+			b.WriteString(markdownCell.Replace(cell))
+			b.WriteString(" |")
+		}
+		b.WriteString("\n")
+	}
+
+	writeRow(result.Columns)
+	b.WriteString("|")
+	b.WriteString(strings.Repeat(" --- |", len(result.Columns)))
+	b.WriteString("\n")
+	for _, row := range result.Rows {
+		cells := make([]string, len(row))
+		for i, value := range row {
+			if value == nil {
+				cells[i] = "NULL"
+			} else {
+				cells[i] = fmt.Sprint(value)
+			}
+		}
+		writeRow(cells)
+	}
+	return b.String()
+}
+
+// markdownCell escapes text that would break a table cell
+var markdownCell = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
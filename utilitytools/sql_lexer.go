@@ -0,0 +1,119 @@
+package utilitytools
+
+import "strings"
+
+// sqlLexing describes a dialect's quoting and comment syntax, so a ";" inside a string,
+// quoted identifier, or comment is not mistaken for the end of a statement
+type sqlLexing struct {
+	// backslashEscapes lets a backslash escape the next character in quoted text
+	backslashEscapes bool
+	// escapeStrings lets a backslash escape the next character in E'...' strings
+	escapeStrings bool
+	// dollarQuotes enables $$...$$ and $tag$...$tag$ strings
+	dollarQuotes bool
+	// hashComments starts a comment at # that runs to the end of the line
+	hashComments bool
+	// backtickQuotes and bracketQuotes enable `name` and [name] identifiers
+	backtickQuotes, bracketQuotes bool
+	// executableComments runs the contents of /*! ... */ comments as SQL
+	executableComments bool
+}
+
+// singleStatement reports whether query is a single statement: it has no ";" outside
+// string literals, quoted identifiers, and comments, and does not end inside one of them.
+// Text that cannot be lexed is treated as more than one statement.
+func (l sqlLexing) singleStatement(query string) bool {
+	for i := 0; i < len(query); i++ {
+		end := i
+		switch c := query[i]; {
+		case c == ';':
+			return false
+		case c == '\'':
+			end = closingQuote(query, i, '\'', l.backslashEscapes || (l.escapeStrings && isEscapeString(query, i)))
+		case c == '"':
+			end = closingQuote(query, i, '"', l.backslashEscapes)
+		case c == '`' && l.backtickQuotes:
+			end = closingQuote(query, i, '`', false)
+		case c == '[' && l.bracketQuotes:
+			end = indexFrom(query, i+1, "]")
+		case c == '-' && strings.HasPrefix(query[i:], "--"), c == '#' && l.hashComments:
+			if end = indexFrom(query, i, "\n"); end < 0 {
+				return true
+			}
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			if l.executableComments && strings.HasPrefix(query[i:], "/*!") {
+				continue
+			}
+			if end = indexFrom(query, i+2, "*/"); end >= 0 {
+				end++
+			}
+		case c == '$' && l.dollarQuotes:
+			tag := dollarTag(query, i)
+			if tag == "" {
+				continue
+			}
+			if end = indexFrom(query, i+len(tag), tag); end >= 0 {
+				end += len(tag) - 1
+			}
+		}
+		if end < 0 {
+			return false
+		}
+		i = end
+	}
+	return true
+}
+
+// closingQuote returns the index of the quote closing the quoted text opened at start, or
+// -1 when it is unterminated. A doubled quote stands for one quote, and with backslash
+// set, a backslash escapes the character after it.
+func closingQuote(s string, start int, quote byte, backslash bool) int {
+	for i := start + 1; i < len(s); i++ {
+		switch {
+		case backslash && s[i] == '\\':
+			i++
+		case s[i] == quote && i+1 < len(s) && s[i+1] == quote:
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// indexFrom returns the index of the first sub in s at or after start, or -1
+func indexFrom(s string, start int, sub string) int {
+	if start > len(s) {
+		return -1
+	}
+	if i := strings.Index(s[start:], sub); i >= 0 {
+		return start + i
+	}
+	return -1
+}
+
+// isEscapeString reports whether the quote at i opens an E'...' string
+func isEscapeString(s string, i int) bool {
+	return i > 0 && (s[i-1] == 'E' || s[i-1] == 'e') && (i == 1 || !isIdentifierByte(s[i-2]))
+}
+
+// dollarTag returns the $tag$ opening a dollar-quoted string at i, or "" when the $ at i
+// does not open one, such as a $1 parameter or a $ inside an identifier
+func dollarTag(s string, i int) string {
+	if i > 0 && isIdentifierByte(s[i-1]) {
+		return ""
+	}
+	for j := i + 1; j < len(s); j++ {
+		switch c := s[j]; {
+		case c == '$':
+			return s[i : j+1]
+		case c >= '0' && c <= '9' && j == i+1, !isIdentifierByte(c):
+			return ""
+		}
+	}
+	return ""
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
@@ -0,0 +1,47 @@
+package utilitytools
+
+import "testing"
+
+func TestSQLLexing_SingleStatement(t *testing.T) {
+	postgres := sqlDialects[SQLDialectPostgres].lexing
+	mysql := sqlDialects[SQLDialectMySQL].lexing
+	sqlite := sqlDialects[SQLDialectSQLite].lexing
+
+	tests := []struct {
+		name   string
+		lexing sqlLexing
+		query  string
+		single bool
+	}{
+		{"plain", postgres, "SELECT 1", true},
+		{"two statements", postgres, "SELECT 1; SELECT 2", false},
+		{"string literal", postgres, "SELECT ';'", true},
+		{"doubled quote", postgres, "SELECT 'it''s;'", true},
+		{"quoted identifier", postgres, `SELECT "a;b"`, true},
+		{"line comment", postgres, "SELECT 1 -- done;\n", true},
+		{"statement after line comment", postgres, "SELECT 1 -- done\n; SELECT 2", false},
+		{"block comment", postgres, "SELECT /* ; */ 1", true},
+		{"unterminated string", postgres, "SELECT ';", false},
+		{"unterminated comment", postgres, "SELECT /* ;", false},
+		{"dollar quote", postgres, "SELECT $$;$$", true},
+		{"tagged dollar quote", postgres, "SELECT $fn$ $$; $fn$", true},
+		{"dollar hides a quote", postgres, "SELECT $$'$$; SELECT '$$'", false},
+		{"parameter", postgres, "SELECT $1; SELECT 2", false},
+		{"escape string", postgres, `SELECT E'\''; SELECT 2; '`, false},
+		{"backslash is literal", postgres, `SELECT '\'; SELECT 2`, false},
+		{"mysql backslash escape", mysql, `SELECT 'a\';'`, true},
+		{"mysql escaped quote hides nothing", mysql, `SELECT '\''; SELECT 2; '`, false},
+		{"mysql hash comment", mysql, "SELECT 1 # ';\n", true},
+		{"mysql hash comment ends at newline", mysql, "SELECT 1 # '\n; SELECT 2; '", false},
+		{"mysql backtick", mysql, "SELECT `a;b`", true},
+		{"mysql executable comment", mysql, "SELECT 1 /*!; SELECT 2 */", false},
+		{"sqlite brackets", sqlite, "SELECT [a;b]", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.lexing.singleStatement(tt.query); got != tt.single {
+				t.Errorf("singleStatement(%q) = %v, want %v", tt.query, got, tt.single)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package utilitytools
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+// sqlReadOnlyMode is how a dialect makes a session read-only at the database level
+type sqlReadOnlyMode int
+
+const (
+	sqlReadOnlyUnsupported    sqlReadOnlyMode = iota
+	sqlReadOnlySetTransaction                 // SET TRANSACTION READ ONLY inside the transaction
+	sqlReadOnlyTxOption                       // the driver's read-only transaction option (START TRANSACTION READ ONLY)
+	sqlReadOnlyQueryOnly                      // PRAGMA query_only on the connection
+)
+
+// sqlQuerier runs queries on a database, connection, or transaction
+type sqlQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// beginSQLSession returns where to run a query and a func releasing it. With readOnly the
+// database rejects writes, whatever the query calls; with tx the query runs in a
+// transaction that is rolled back.
+func beginSQLSession(ctx context.Context, db *sql.DB, rules sqlDialectRules, readOnly, tx bool) (sqlQuerier, func(), error) {
+	if !readOnly && !tx {
+		return db, func() {}, nil
+	}
+	if readOnly && rules.readOnly == sqlReadOnlyUnsupported {
+		return nil, nil, fmt.Errorf("%s has no read-only transactions; connect with a read-only database or role instead", rules.name)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	release := func() { conn.Close() }
+
+	if readOnly && rules.readOnly == sqlReadOnlyQueryOnly {
+		if _, err := conn.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("making connection read-only: %w", err)
+		}
+		release = func() {
+			// A connection left read-only must not return to the pool
+			if _, err := conn.ExecContext(context.Background(), "PRAGMA query_only = OFF"); err != nil {
+				conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+			}
+			conn.Close()
+		}
+		if !tx {
+			return conn, release, nil
+		}
+	}
+
+	opts := &sql.TxOptions{ReadOnly: readOnly && rules.readOnly == sqlReadOnlyTxOption}
+	t, err := conn.BeginTx(ctx, opts)
+	if err != nil {
+		release()
+		return nil, nil, fmt.Errorf("starting transaction: %w", err)
+	}
+	if readOnly && rules.readOnly == sqlReadOnlySetTransaction {
+		if _, err := t.ExecContext(ctx, "SET TRANSACTION READ ONLY"); err != nil {
+			t.Rollback()
+			release()
+			return nil, nil, fmt.Errorf("making transaction read-only: %w", err)
+		}
+	}
+	return t, func() {
+		t.Rollback()
+		release()
+	}, nil
+}
@@ -0,0 +1,247 @@
+package utilitytools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhpenta/minimcp/mcp"
+)
+
+// Defaults for SQLResourceConfig
+const (
+	DefaultSQLResourceMaxRows = 100
+	DefaultSQLResourceScheme  = "sql"
+)
+
+// maxSQLResources caps how many tables and views are listed as resources
+const maxSQLResources = 1000
+
+// SQLResourceConfig configures NewSQLResourceProvider
+type SQLResourceConfig struct {
+	// Dialect is SQLDialectPostgres (the default), SQLDialectMySQL, or SQLDialectSQLite
+	Dialect SQLDialect
+
+	// Scheme of the resource URIs (default "sql"), which are scheme://schema/table, or
+	// scheme://table for SQLite
+	Scheme string
+
+	// Schema limits the resources to one schema. MySQL defaults to the current database;
+	// SQLite has no schemas.
+	Schema string
+
+	// Format of snapshots, SQLFormatJSON (the default) or SQLFormatCSV. Reads choose
+	// another with ?format=csv or ?format=json.
+	Format string
+
+	// MaxRows caps the rows of a snapshot (default 100). Reads may ask for fewer with ?limit=n.
+	MaxRows int
+
+	// ReadOnlyTransactions reads snapshots in read-only transactions, like
+	// WithReadOnlyTransactions
+	ReadOnlyTransactions bool
+
+	Timeout time.Duration // per read (default 60s)
+	Logger  *slog.Logger
+}
+
+// SQLTableSnapshot is a table resource in JSON
+type SQLTableSnapshot struct {
+	Schema    string            `json:"schema,omitempty"`
+	Table     string            `json:"table"`
+	Columns   []SQLResultColumn `json:"columns"`
+	Rows      [][]interface{}   `json:"rows"`
+	Truncated bool              `json:"truncated,omitempty"` // the table has more rows than the snapshot
+}
+
+// SQLResourceProvider serves database tables and views as MCP resources, each read as a
+// snapshot of its first rows. It complements NewReadOnlySQLTool for clients that explore
+// data through resources.
+type SQLResourceProvider struct {
+	db     *sql.DB
+	cfg    SQLResourceConfig
+	reader schemaReader
+}
+
+// NewSQLResourceProvider creates a provider serving the tables and views of db. Register
+// it with mcp.ServerConfig.ResourceProviders.
+//
+// Example:
+//
+//	tables, err := utilitytools.NewSQLResourceProvider(db, utilitytools.SQLResourceConfig{
+//	    Schema:  "public",
+//	    MaxRows: 50,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	server := mcp.NewServer(mcp.ServerConfig{Name: "warehouse", ResourceProviders: []mcp.ResourceProvider{tables}})
+func NewSQLResourceProvider(db *sql.DB, cfg SQLResourceConfig) (*SQLResourceProvider, error) {
+	if cfg.Dialect == "" {
+		cfg.Dialect = SQLDialectPostgres
+	}
+	reader, err := newSchemaReader(cfg.Dialect)
+	if err != nil {
+		return nil, fmt.Errorf("sql resource provider: %w", err)
+	}
+	if cfg.Format == "" {
+		cfg.Format = SQLFormatJSON
+	}
+	if cfg.Format != SQLFormatJSON && cfg.Format != SQLFormatCSV {
+		return nil, fmt.Errorf("sql resource provider: format must be %s or %s", SQLFormatJSON, SQLFormatCSV)
+	}
+	if cfg.Scheme == "" {
+		cfg.Scheme = DefaultSQLResourceScheme
+	}
+	if cfg.MaxRows <= 0 {
+		cfg.MaxRows = DefaultSQLResourceMaxRows
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	return &SQLResourceProvider{db: db, cfg: cfg, reader: reader}, nil
+}
+
+// ListResources implements mcp.ResourceProvider
+func (p *SQLResourceProvider) ListResources(ctx context.Context) ([]mcp.Resource, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+	tables, truncated, err := p.reader.listTables(ctx, p.db, SQLSchemaParams{Schema: p.cfg.Schema}, maxSQLResources)
+	if err != nil {
+		return nil, err
+	}
+	if truncated {
+		p.cfg.Logger.Warn("too many tables to list as resources", "listed", len(tables))
+	}
+
+	resources := make([]mcp.Resource, 0, len(tables))
+	for _, t := range tables {
+		name := t.Name
+		if t.Schema != "" {
+			name = t.Schema + "." + t.Name
+		}
+		kind := "Table"
+		if t.Type == "view" {
+			kind = "View"
+		}
+		resources = append(resources, mcp.Resource{
+			URI:         p.uri(t.Schema, t.Name),
+			Name:        name,
+			Description: fmt.Sprintf("%s %s, up to %d rows", kind, name, p.cfg.MaxRows),
+			MimeType:    sqlSnapshotMimeType(p.cfg.Format),
+		})
+	}
+	return resources, nil
+}
+
+// ReadResource implements mcp.ResourceProvider
+func (p *SQLResourceProvider) ReadResource(ctx context.Context, uri string) ([]mcp.ResourceContents, error) {
+	schema, table, query, ok := p.parseURI(uri)
+	if !ok {
+		return nil, mcp.ErrResourceNotFound
+	}
+	format := p.cfg.Format
+	if f := query.Get("format"); f != "" {
+		if f != SQLFormatJSON && f != SQLFormatCSV {
+			return nil, &mcp.RPCError{Code: mcp.InvalidParams, Message: fmt.Sprintf("format must be %s or %s", SQLFormatJSON, SQLFormatCSV)}
+		}
+		format = f
+	}
+	limit := p.cfg.MaxRows
+	if l := query.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			return nil, &mcp.RPCError{Code: mcp.InvalidParams, Message: "limit must be a positive integer"}
+		}
+		limit = min(n, p.cfg.MaxRows)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	// Only tables in the catalog are read, so the URI cannot name anything else
+	tables, _, err := p.reader.listTables(ctx, p.db, SQLSchemaParams{Schema: schema, Table: table}, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, mcp.ErrResourceNotFound
+	}
+
+	qualified := p.cfg.Dialect.QuoteIdentifier(table)
+	if schema != "" {
+		qualified = p.cfg.Dialect.QuoteIdentifier(schema) + "." + qualified
+	}
+	statement := fmt.Sprintf("SELECT * FROM %s LIMIT %d", qualified, limit+1)
+	result, err := runSQLQuery(ctx, p.cfg.Logger, p.db, p.cfg.Dialect, statement, sqlQueryOptions{
+		page:     sqlPage{limit: limit},
+		timeout:  p.cfg.Timeout,
+		readOnly: p.cfg.ReadOnlyTransactions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", qualified, err)
+	}
+
+	if format == SQLFormatCSV {
+		return []mcp.ResourceContents{{URI: uri, MimeType: sqlSnapshotMimeType(format), Text: delimitedSQLRows(result, ',')}}, nil
+	}
+	snapshot := SQLTableSnapshot{Schema: schema, Table: table, Columns: result.ColumnTypes, Rows: result.Rows, Truncated: result.Truncated}
+	if snapshot.Rows == nil {
+		snapshot.Rows = [][]interface{}{}
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.ResourceContents{{URI: uri, MimeType: sqlSnapshotMimeType(format), Text: string(data)}}, nil
+}
+
+// uri returns the resource URI of a table
+func (p *SQLResourceProvider) uri(schema, table string) string {
+	if schema == "" {
+		return p.cfg.Scheme + "://" + url.PathEscape(table)
+	}
+	return p.cfg.Scheme + "://" + url.PathEscape(schema) + "/" + url.PathEscape(table)
+}
+
+// parseURI splits a resource URI into its schema, table, and query parameters
+func (p *SQLResourceProvider) parseURI(uri string) (schema, table string, query url.Values, ok bool) {
+	rest, ok := strings.CutPrefix(uri, p.cfg.Scheme+"://")
+	if !ok {
+		return "", "", nil, false
+	}
+	rest, rawQuery, _ := strings.Cut(rest, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", "", nil, false
+	}
+	parts := strings.Split(rest, "/")
+	for i, part := range parts {
+		if parts[i], err = url.PathUnescape(part); err != nil || parts[i] == "" {
+			return "", "", nil, false
+		}
+	}
+	switch {
+	case len(parts) == 1 && p.cfg.Dialect == SQLDialectSQLite:
+		return "", parts[0], query, true
+	case len(parts) == 2 && p.cfg.Dialect != SQLDialectSQLite:
+		return parts[0], parts[1], query, true
+	}
+	return "", "", nil, false
+}
+
+func sqlSnapshotMimeType(format string) string {
+	if format == SQLFormatCSV {
+		return "text/csv"
+	}
+	return "application/json"
+}
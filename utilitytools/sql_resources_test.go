@@ -0,0 +1,86 @@
+package utilitytools
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mhpenta/minimcp/mcp"
+)
+
+func TestSQLResourceProvider(t *testing.T) {
+	var queries []string
+	db := openFakeDB(t, &fakeDB{respond: func(query string, args []driver.Value) [][]driver.Value {
+		if strings.HasPrefix(query, "SELECT * FROM") {
+			queries = append(queries, query)
+			return [][]driver.Value{{int64(1), "ada"}, {int64(2), "grace"}, {int64(3), "linus"}}
+		}
+		for _, arg := range args {
+			if arg == "missing" {
+				return nil
+			}
+		}
+		return [][]driver.Value{{"public", "customers", "table"}, {"public", "big spenders", "view"}}
+	}, exec: func(string) (int64, error) { return 0, nil }})
+
+	provider, err := NewSQLResourceProvider(db, SQLResourceConfig{Schema: "public", MaxRows: 2, ReadOnlyTransactions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources, err := provider.ListResources(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var uris []string
+	for _, r := range resources {
+		uris = append(uris, r.URI)
+	}
+	if want := []string{"sql://public/customers", "sql://public/big%20spenders"}; !reflect.DeepEqual(uris, want) {
+		t.Errorf("uris = %v, want %v", uris, want)
+	}
+	if r := resources[1]; r.Name != "public.big spenders" || r.MimeType != "application/json" || !strings.HasPrefix(r.Description, "View") {
+		t.Errorf("view resource = %+v", r)
+	}
+
+	contents, err := provider.ReadResource(context.Background(), "sql://public/big%20spenders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snapshot SQLTableSnapshot
+	if err := json.Unmarshal([]byte(contents[0].Text), &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Table != "big spenders" || len(snapshot.Rows) != 2 || !snapshot.Truncated {
+		t.Errorf("snapshot = %+v", snapshot)
+	}
+	if want := `SELECT * FROM "public"."big spenders" LIMIT 3`; len(queries) != 1 || queries[0] != want {
+		t.Errorf("queries = %q, want %q", queries, want)
+	}
+
+	// Reads may ask for CSV and fewer rows, but not more than MaxRows
+	contents, err = provider.ReadResource(context.Background(), "sql://public/customers?format=csv&limit=1")
+	if err != nil || contents[0].MimeType != "text/csv" || !strings.Contains(contents[0].Text, "1,ada") || strings.Contains(contents[0].Text, "grace") {
+		t.Errorf("csv read = %+v, %v", contents, err)
+	}
+	if _, err := provider.ReadResource(context.Background(), "sql://public/customers?limit=50"); err != nil || !strings.HasSuffix(queries[len(queries)-1], "LIMIT 3") {
+		t.Errorf("limit above MaxRows read %q, %v", queries[len(queries)-1], err)
+	}
+
+	for _, uri := range []string{"sql://public/missing", "sql://customers", "sql://public/customers/extra", "other://public/customers"} {
+		if _, err := provider.ReadResource(context.Background(), uri); err != mcp.ErrResourceNotFound {
+			t.Errorf("%s: expected ErrResourceNotFound, got %v", uri, err)
+		}
+	}
+	var rpcErr *mcp.RPCError
+	if _, err := provider.ReadResource(context.Background(), "sql://public/customers?format=xml"); !errors.As(err, &rpcErr) || rpcErr.Code != mcp.InvalidParams {
+		t.Errorf("expected invalid params for an unknown format, got %v", err)
+	}
+
+	if _, err := NewSQLResourceProvider(db, SQLResourceConfig{Dialect: "oracle"}); err == nil {
+		t.Error("expected an unsupported dialect to fail")
+	}
+}
@@ -0,0 +1,468 @@
+package utilitytools
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// maxSchemaTables caps how many tables one schema description holds
+const maxSchemaTables = 200
+
+// SQLSchemaParams defines parameters for the schema tool
+type SQLSchemaParams struct {
+	Schema string `json:"schema,omitempty" jsonschema:"only describe tables in this schema (Postgres and MySQL); defaults to every user schema in Postgres and the current database in MySQL"`
+	Table  string `json:"table,omitempty" jsonschema:"only describe this table or view"`
+}
+
+// SQLSchemaResult describes the tables of a database
+type SQLSchemaResult struct {
+	Tables    []SQLTable `json:"tables"`
+	Truncated bool       `json:"truncated,omitempty"` // more tables matched than were described; filter by schema or table
+}
+
+// SQLTable describes a table or view
+type SQLTable struct {
+	Schema      string          `json:"schema,omitempty"`
+	Name        string          `json:"name"`
+	Type        string          `json:"type"` // "table" or "view"
+	Columns     []SQLColumn     `json:"columns"`
+	PrimaryKey  []string        `json:"primary_key,omitempty"`
+	Indexes     []SQLIndex      `json:"indexes,omitempty"`
+	ForeignKeys []SQLForeignKey `json:"foreign_keys,omitempty"`
+}
+
+// SQLColumn describes a column
+type SQLColumn struct {
+	Name     string  `json:"name"`
+	Type     string  `json:"type"`
+	Nullable bool    `json:"nullable"`
+	Default  *string `json:"default,omitempty"`
+}
+
+// SQLIndex describes an index other than the primary key
+type SQLIndex struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique,omitempty"`
+}
+
+// SQLForeignKey describes a foreign key constraint
+type SQLForeignKey struct {
+	Name              string   `json:"name,omitempty"`
+	Columns           []string `json:"columns"`
+	ReferencedSchema  string   `json:"referenced_schema,omitempty"`
+	ReferencedTable   string   `json:"referenced_table"`
+	ReferencedColumns []string `json:"referenced_columns,omitempty"` // empty for the referenced table's primary key
+}
+
+// NewSQLSchemaTool creates a companion to NewReadOnlySQLTool that describes tables,
+// columns, types, primary keys, indexes, and foreign keys as structured output, read
+// from the catalog of the given dialect, so models can discover the schema without
+// writing catalog queries.
+func NewSQLSchemaTool(db *sql.DB, dialect SQLDialect, logger *slog.Logger) (tools.Tool, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	reader, err := newSchemaReader(dialect)
+	if err != nil {
+		return nil, fmt.Errorf("sql schema tool: %w", err)
+	}
+
+	handler := func(ctx context.Context, params SQLSchemaParams) (*SQLSchemaResult, error) {
+		ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+		defer cancel()
+
+		result, err := reader.describe(ctx, db, params)
+		if err != nil {
+			logger.Error("SQL schema introspection failed", "dialect", dialect, "error", err)
+			return nil, err
+		}
+		logger.Info("SQL schema described", "dialect", dialect, "tables", len(result.Tables))
+		return result, nil
+	}
+
+	return tools.NewTool(
+		"DescribeSQLSchema",
+		"Describes the database schema: tables and views with their columns and types, primary keys, indexes, and foreign keys. "+
+			"Use it before writing queries with ReadOnlySQLQuery; filter by schema or table on large databases.",
+		handler,
+		tools.WithType("DescribeSQLSchema_v1"),
+		tools.WithVerb("Reading database schema"),
+	), nil
+}
+
+// schemaReader reads table descriptions from one dialect's catalog
+type schemaReader interface {
+	describe(ctx context.Context, db *sql.DB, params SQLSchemaParams) (*SQLSchemaResult, error)
+
+	// listTables returns up to limit tables and views, without their columns, and
+	// whether more were left out
+	listTables(ctx context.Context, db *sql.DB, params SQLSchemaParams, limit int) ([]SQLTable, bool, error)
+}
+
+// newSchemaReader returns the catalog reader of a dialect
+func newSchemaReader(dialect SQLDialect) (schemaReader, error) {
+	switch dialect {
+	case SQLDialectPostgres:
+		return postgresCatalog, nil
+	case SQLDialectMySQL:
+		return mysqlCatalog, nil
+	case SQLDialectSQLite:
+		return sqliteCatalog{}, nil
+	}
+	return nil, fmt.Errorf("unsupported dialect %q", dialect)
+}
+
+// catalogQueries reads the schema with four queries over the whole catalog, each
+// filtered by schema and table and ordered by schema and table:
+//
+//	tables:      schema, table, type ("table" or "view")
+//	columns:     schema, table, name, type, nullable, default (in column order)
+//	indexes:     schema, table, name, unique, primary, comma-separated columns
+//	foreignKeys: schema, table, name, columns, referenced schema, table, columns
+type catalogQueries struct {
+	tables, columns, indexes, foreignKeys string
+
+	// args returns the query arguments for the schema and table filters
+	args func(schema, table string) []interface{}
+}
+
+var postgresCatalog = catalogQueries{
+	tables: `SELECT n.nspname, c.relname, CASE WHEN c.relkind IN ('v', 'm') THEN 'view' ELSE 'table' END
+		FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('r', 'p', 'f', 'v', 'm') AND ` + postgresFilter + `
+		ORDER BY 1, 2`,
+	columns: `SELECT n.nspname, c.relname, a.attname, format_type(a.atttypid, a.atttypmod), NOT a.attnotnull, pg_get_expr(d.adbin, d.adrelid)
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE a.attnum > 0 AND NOT a.attisdropped AND c.relkind IN ('r', 'p', 'f', 'v', 'm') AND ` + postgresFilter + `
+		ORDER BY 1, 2, a.attnum`,
+	indexes: `SELECT n.nspname, c.relname, i.relname, ix.indisunique, ix.indisprimary,
+			array_to_string(ARRAY(SELECT a.attname FROM unnest(ix.indkey) WITH ORDINALITY k(num, ord)
+				JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum = k.num ORDER BY k.ord), ',')
+		FROM pg_index ix
+		JOIN pg_class c ON c.oid = ix.indrelid
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE ` + postgresFilter + `
+		ORDER BY 1, 2, 3`,
+	foreignKeys: `SELECT n.nspname, c.relname, con.conname,
+			array_to_string(ARRAY(SELECT a.attname FROM unnest(con.conkey) WITH ORDINALITY k(num, ord)
+				JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.num ORDER BY k.ord), ','),
+			rn.nspname, rc.relname,
+			array_to_string(ARRAY(SELECT a.attname FROM unnest(con.confkey) WITH ORDINALITY k(num, ord)
+				JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.num ORDER BY k.ord), ',')
+		FROM pg_constraint con
+		JOIN pg_class c ON c.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_class rc ON rc.oid = con.confrelid
+		JOIN pg_namespace rn ON rn.oid = rc.relnamespace
+		WHERE con.contype = 'f' AND ` + postgresFilter + `
+		ORDER BY 1, 2, 3`,
+	args: func(schema, table string) []interface{} {
+		return []interface{}{schema, table}
+	},
+}
+
+// postgresFilter skips system schemas and applies the schema and table filters
+const postgresFilter = `n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname NOT LIKE 'pg_toast%'
+		AND ($1::text = '' OR n.nspname = $1) AND ($2::text = '' OR c.relname = $2)`
+
+var mysqlCatalog = catalogQueries{
+	tables: `SELECT table_schema, table_name, CASE WHEN table_type = 'VIEW' THEN 'view' ELSE 'table' END
+		FROM information_schema.tables
+		WHERE ` + mysqlFilter + `
+		ORDER BY 1, 2`,
+	columns: `SELECT table_schema, table_name, column_name, column_type, is_nullable = 'YES', column_default
+		FROM information_schema.columns
+		WHERE ` + mysqlFilter + `
+		ORDER BY 1, 2, ordinal_position`,
+	indexes: `SELECT table_schema, table_name, index_name, MAX(non_unique) = 0, index_name = 'PRIMARY',
+			GROUP_CONCAT(column_name ORDER BY seq_in_index)
+		FROM information_schema.statistics
+		WHERE ` + mysqlFilter + `
+		GROUP BY table_schema, table_name, index_name
+		ORDER BY 1, 2, 3`,
+	foreignKeys: `SELECT table_schema, table_name, constraint_name,
+			GROUP_CONCAT(column_name ORDER BY ordinal_position),
+			MAX(referenced_table_schema), MAX(referenced_table_name),
+			GROUP_CONCAT(referenced_column_name ORDER BY ordinal_position)
+		FROM information_schema.key_column_usage
+		WHERE referenced_table_name IS NOT NULL AND ` + mysqlFilter + `
+		GROUP BY table_schema, table_name, constraint_name
+		ORDER BY 1, 2, 3`,
+	args: func(schema, table string) []interface{} {
+		return []interface{}{schema, table, table}
+	},
+}
+
+// mysqlFilter limits the description to one database, the current one by default
+const mysqlFilter = `table_schema = COALESCE(NULLIF(?, ''), DATABASE()) AND (? = '' OR table_name = ?)`
+
+func (q catalogQueries) listTables(ctx context.Context, db *sql.DB, params SQLSchemaParams, limit int) ([]SQLTable, bool, error) {
+	tables := []SQLTable{}
+	truncated := false
+	err := queryRows(ctx, db, q.tables, q.args(params.Schema, params.Table), func(scan func(...interface{}) error) error {
+		var t SQLTable
+		if err := scan(&t.Schema, &t.Name, &t.Type); err != nil {
+			return err
+		}
+		if len(tables) == limit {
+			truncated = true
+			return nil
+		}
+		tables = append(tables, t)
+		return nil
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("listing tables: %w", err)
+	}
+	return tables, truncated, nil
+}
+
+func (q catalogQueries) describe(ctx context.Context, db *sql.DB, params SQLSchemaParams) (*SQLSchemaResult, error) {
+	args := q.args(params.Schema, params.Table)
+	tables, truncated, err := q.listTables(ctx, db, params, maxSchemaTables)
+	if err != nil {
+		return nil, err
+	}
+	result := &SQLSchemaResult{Tables: tables, Truncated: truncated}
+	byKey := make(map[string]*SQLTable)
+	key := func(schema, table string) string { return schema + "." + table }
+	for i := range result.Tables {
+		t := &result.Tables[i]
+		t.Columns = []SQLColumn{}
+		byKey[key(t.Schema, t.Name)] = t
+	}
+
+	err = queryRows(ctx, db, q.columns, args, func(scan func(...interface{}) error) error {
+		var schema, table string
+		var c SQLColumn
+		var def sql.NullString
+		if err := scan(&schema, &table, &c.Name, &c.Type, &c.Nullable, &def); err != nil {
+			return err
+		}
+		if def.Valid {
+			c.Default = &def.String
+		}
+		if t := byKey[key(schema, table)]; t != nil {
+			t.Columns = append(t.Columns, c)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing columns: %w", err)
+	}
+
+	err = queryRows(ctx, db, q.indexes, args, func(scan func(...interface{}) error) error {
+		var schema, table, columns string
+		var index SQLIndex
+		var primary bool
+		if err := scan(&schema, &table, &index.Name, &index.Unique, &primary, &columns); err != nil {
+			return err
+		}
+		t := byKey[key(schema, table)]
+		switch {
+		case t == nil:
+		case primary:
+			t.PrimaryKey = splitColumns(columns)
+		default:
+			index.Columns = splitColumns(columns)
+			t.Indexes = append(t.Indexes, index)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing indexes: %w", err)
+	}
+
+	err = queryRows(ctx, db, q.foreignKeys, args, func(scan func(...interface{}) error) error {
+		var schema, table, columns, refColumns string
+		var fk SQLForeignKey
+		if err := scan(&schema, &table, &fk.Name, &columns, &fk.ReferencedSchema, &fk.ReferencedTable, &refColumns); err != nil {
+			return err
+		}
+		if t := byKey[key(schema, table)]; t != nil {
+			fk.Columns, fk.ReferencedColumns = splitColumns(columns), splitColumns(refColumns)
+			t.ForeignKeys = append(t.ForeignKeys, fk)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing foreign keys: %w", err)
+	}
+	return result, nil
+}
+
+// sqliteCatalog reads the schema from sqlite_master and the table pragmas. SQLite has
+// no schemas, so the schema filter is ignored.
+type sqliteCatalog struct{}
+
+func (sqliteCatalog) listTables(ctx context.Context, db *sql.DB, params SQLSchemaParams, limit int) ([]SQLTable, bool, error) {
+	tables := []SQLTable{}
+	truncated := false
+	err := queryRows(ctx, db, `SELECT name, type FROM sqlite_master
+		WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%' AND (? = '' OR name = ?)
+		ORDER BY name`, []interface{}{params.Table, params.Table}, func(scan func(...interface{}) error) error {
+		var t SQLTable
+		if err := scan(&t.Name, &t.Type); err != nil {
+			return err
+		}
+		if len(tables) == limit {
+			truncated = true
+			return nil
+		}
+		tables = append(tables, t)
+		return nil
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("listing tables: %w", err)
+	}
+	return tables, truncated, nil
+}
+
+func (c sqliteCatalog) describe(ctx context.Context, db *sql.DB, params SQLSchemaParams) (*SQLSchemaResult, error) {
+	tables, truncated, err := c.listTables(ctx, db, params, maxSchemaTables)
+	if err != nil {
+		return nil, err
+	}
+	result := &SQLSchemaResult{Tables: tables, Truncated: truncated}
+	for i := range result.Tables {
+		if err := describeSQLiteTable(ctx, db, &result.Tables[i]); err != nil {
+			return nil, fmt.Errorf("describing %s: %w", result.Tables[i].Name, err)
+		}
+	}
+	return result, nil
+}
+
+func describeSQLiteTable(ctx context.Context, db *sql.DB, t *SQLTable) error {
+	t.Columns = []SQLColumn{}
+	type pkColumn struct {
+		name string
+		pos  int
+	}
+	var pk []pkColumn
+	err := queryRows(ctx, db, `SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid`,
+		[]interface{}{t.Name}, func(scan func(...interface{}) error) error {
+			var c SQLColumn
+			var notNull bool
+			var def sql.NullString
+			var pos int
+			if err := scan(&c.Name, &c.Type, &notNull, &def, &pos); err != nil {
+				return err
+			}
+			c.Nullable = !notNull
+			if def.Valid {
+				c.Default = &def.String
+			}
+			if pos > 0 {
+				pk = append(pk, pkColumn{c.Name, pos})
+			}
+			t.Columns = append(t.Columns, c)
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+	if len(pk) > 0 {
+		t.PrimaryKey = make([]string, len(pk))
+		for _, c := range pk {
+			t.PrimaryKey[c.pos-1] = c.name
+		}
+	}
+
+	var indexes []SQLIndex
+	err = queryRows(ctx, db, `SELECT name, "unique", origin FROM pragma_index_list(?) ORDER BY name`,
+		[]interface{}{t.Name}, func(scan func(...interface{}) error) error {
+			var index SQLIndex
+			var origin string
+			if err := scan(&index.Name, &index.Unique, &origin); err != nil {
+				return err
+			}
+			// The primary key's index is reported as PrimaryKey
+			if origin != "pk" {
+				indexes = append(indexes, index)
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		err := queryRows(ctx, db, `SELECT name FROM pragma_index_info(?) ORDER BY seqno`,
+			[]interface{}{index.Name}, func(scan func(...interface{}) error) error {
+				var column sql.NullString
+				if err := scan(&column); err != nil {
+					return err
+				}
+				// Expression index columns have no name
+				index.Columns = append(index.Columns, column.String)
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+		t.Indexes = append(t.Indexes, index)
+	}
+
+	byID := make(map[int]*SQLForeignKey)
+	var order []int
+	err = queryRows(ctx, db, `SELECT id, "table", "from", "to" FROM pragma_foreign_key_list(?) ORDER BY id, seq`,
+		[]interface{}{t.Name}, func(scan func(...interface{}) error) error {
+			var id int
+			var refTable, from string
+			var to sql.NullString
+			if err := scan(&id, &refTable, &from, &to); err != nil {
+				return err
+			}
+			fk := byID[id]
+			if fk == nil {
+				fk = &SQLForeignKey{ReferencedTable: refTable}
+				byID[id] = fk
+				order = append(order, id)
+			}
+			fk.Columns = append(fk.Columns, from)
+			// A foreign key without target columns references the primary key
+			if to.Valid {
+				fk.ReferencedColumns = append(fk.ReferencedColumns, to.String)
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+	for _, id := range order {
+		t.ForeignKeys = append(t.ForeignKeys, *byID[id])
+	}
+	return nil
+}
+
+// queryRows runs a query and calls fn with a scanner for each row
+func queryRows(ctx context.Context, db *sql.DB, query string, args []interface{}, fn func(scan func(...interface{}) error) error) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := fn(rows.Scan); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// splitColumns splits a comma-separated column list
+func splitColumns(columns string) []string {
+	if columns == "" {
+		return nil
+	}
+	return strings.Split(columns, ",")
+}
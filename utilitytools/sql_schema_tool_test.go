@@ -0,0 +1,152 @@
+package utilitytools
+
+import (
+	"database/sql/driver"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newSchemaTool(t *testing.T, dialect SQLDialect, respond catalogResponder) func(args string) SQLSchemaResult {
+	t.Helper()
+	db := openFakeDB(t, &fakeDB{respond: respond})
+	tool, err := NewSQLSchemaTool(db, dialect, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func(args string) SQLSchemaResult {
+		t.Helper()
+		var result SQLSchemaResult
+		if err := run(t, tool, args, &result); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+}
+
+func TestSQLSchemaTool_Postgres(t *testing.T) {
+	var filters [][]driver.Value
+	describe := newSchemaTool(t, SQLDialectPostgres, func(query string, args []driver.Value) [][]driver.Value {
+		filters = append(filters, args)
+		switch {
+		case strings.Contains(query, "pg_constraint"):
+			return [][]driver.Value{{"public", "orders", "orders_customer_fk", "customer_id", "public", "customers", "id"}}
+		case strings.Contains(query, "pg_index"):
+			return [][]driver.Value{
+				{"public", "customers", "customers_pkey", true, true, "id"},
+				{"public", "orders", "orders_pkey", true, true, "id"},
+				{"public", "orders", "orders_customer_placed_idx", false, false, "customer_id,placed_at"},
+			}
+		case strings.Contains(query, "pg_attrdef"):
+			return [][]driver.Value{
+				{"public", "customers", "id", "integer", false, "nextval('customers_id_seq'::regclass)"},
+				{"public", "customers", "email", "character varying(255)", true, nil},
+				{"public", "orders", "id", "bigint", false, nil},
+				{"public", "orders", "customer_id", "integer", false, nil},
+				{"public", "orders", "placed_at", "timestamp with time zone", false, "now()"},
+			}
+		default:
+			return [][]driver.Value{{"public", "customers", "table"}, {"public", "orders", "table"}}
+		}
+	})
+
+	result := describe(`{"schema": "public"}`)
+	if len(result.Tables) != 2 {
+		t.Fatalf("tables = %+v", result.Tables)
+	}
+	customers, orders := result.Tables[0], result.Tables[1]
+	if customers.Name != "customers" || customers.Schema != "public" || customers.Type != "table" {
+		t.Errorf("customers = %+v", customers)
+	}
+	if len(customers.Columns) != 2 || customers.Columns[1].Type != "character varying(255)" || !customers.Columns[1].Nullable {
+		t.Errorf("customers columns = %+v", customers.Columns)
+	}
+	if def := customers.Columns[0].Default; def == nil || *def != "nextval('customers_id_seq'::regclass)" {
+		t.Errorf("customers.id default = %v", def)
+	}
+	if !reflect.DeepEqual(customers.PrimaryKey, []string{"id"}) || len(customers.Indexes) != 0 {
+		t.Errorf("customers keys = %v, indexes = %+v", customers.PrimaryKey, customers.Indexes)
+	}
+	wantIndex := []SQLIndex{{Name: "orders_customer_placed_idx", Columns: []string{"customer_id", "placed_at"}}}
+	if !reflect.DeepEqual(orders.Indexes, wantIndex) {
+		t.Errorf("orders indexes = %+v", orders.Indexes)
+	}
+	wantFK := []SQLForeignKey{{
+		Name:              "orders_customer_fk",
+		Columns:           []string{"customer_id"},
+		ReferencedSchema:  "public",
+		ReferencedTable:   "customers",
+		ReferencedColumns: []string{"id"},
+	}}
+	if !reflect.DeepEqual(orders.ForeignKeys, wantFK) {
+		t.Errorf("orders foreign keys = %+v", orders.ForeignKeys)
+	}
+	for _, args := range filters {
+		if !reflect.DeepEqual(args, []driver.Value{"public", ""}) {
+			t.Errorf("filter args = %v", args)
+		}
+	}
+}
+
+func TestSQLSchemaTool_SQLite(t *testing.T) {
+	describe := newSchemaTool(t, SQLDialectSQLite, func(query string, args []driver.Value) [][]driver.Value {
+		var table string
+		if len(args) > 0 {
+			table, _ = args[0].(string)
+		}
+		switch {
+		case strings.Contains(query, "sqlite_master"):
+			if table != "" && table != "line_items" {
+				return nil
+			}
+			return [][]driver.Value{{"line_items", "table"}}
+		case strings.Contains(query, "pragma_table_info"):
+			return [][]driver.Value{
+				{"order_id", "INTEGER", int64(1), nil, int64(1)},
+				{"sku", "TEXT", int64(1), nil, int64(2)},
+				{"quantity", "INTEGER", int64(0), "1", int64(0)},
+			}
+		case strings.Contains(query, "pragma_index_list"):
+			return [][]driver.Value{
+				{"line_items_sku", int64(0), "c"},
+				{"sqlite_autoindex_line_items_1", int64(1), "pk"},
+			}
+		case strings.Contains(query, "pragma_index_info"):
+			return [][]driver.Value{{"sku"}}
+		case strings.Contains(query, "pragma_foreign_key_list"):
+			return [][]driver.Value{{int64(0), "orders", "order_id", nil}}
+		}
+		return nil
+	})
+
+	result := describe(`{"table": "line_items"}`)
+	if len(result.Tables) != 1 {
+		t.Fatalf("tables = %+v", result.Tables)
+	}
+	items := result.Tables[0]
+	if !reflect.DeepEqual(items.PrimaryKey, []string{"order_id", "sku"}) {
+		t.Errorf("primary key = %v", items.PrimaryKey)
+	}
+	if len(items.Columns) != 3 || items.Columns[0].Nullable || !items.Columns[2].Nullable || *items.Columns[2].Default != "1" {
+		t.Errorf("columns = %+v", items.Columns)
+	}
+	if !reflect.DeepEqual(items.Indexes, []SQLIndex{{Name: "line_items_sku", Columns: []string{"sku"}}}) {
+		t.Errorf("indexes = %+v", items.Indexes)
+	}
+	wantFK := []SQLForeignKey{{Columns: []string{"order_id"}, ReferencedTable: "orders"}}
+	if !reflect.DeepEqual(items.ForeignKeys, wantFK) {
+		t.Errorf("foreign keys = %+v", items.ForeignKeys)
+	}
+
+	if result := describe(`{"table": "missing"}`); len(result.Tables) != 0 || result.Truncated {
+		t.Errorf("missing table = %+v", result)
+	}
+}
+
+func TestSQLSchemaTool_UnsupportedDialect(t *testing.T) {
+	if _, err := NewSQLSchemaTool(nil, "oracle", nil); err == nil {
+		t.Error("expected an error for an unsupported dialect")
+	}
+}
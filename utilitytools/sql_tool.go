@@ -2,10 +2,15 @@ package utilitytools
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,21 +19,152 @@ import (
 
 // SQLToolParams defines parameters for executing SQL queries
 type SQLToolParams struct {
-	Query string `json:"query" jsonschema:"SQL query to execute (read-only, only SELECT and WITH queries allowed)"`
+	Query   string `json:"query" jsonschema:"SQL query to execute (read-only; the tool description lists the allowed statements)"`
+	Limit   int    `json:"limit,omitempty" jsonschema:"maximum rows to return; defaults to and is capped at the tool's row limit"`
+	Cursor  string `json:"cursor,omitempty" jsonschema:"next_cursor of a previous result, to read the following rows of the same query"`
+	Format  string `json:"format,omitempty" jsonschema:"result format: 'json' (default) for typed rows, 'markdown' for a compact table, or 'csv' or 'tsv' to hand the rows to other tools"`
+	Timeout int    `json:"timeout_seconds,omitempty" jsonschema:"seconds the query may run; defaults to the tool's timeout and is capped at its maximum"`
+	Explain string `json:"explain,omitempty" jsonschema:"set to 'plan' to return the query plan and estimated cost without running the query, or 'analyze' to run it with a time cap and return the plan with actual rows and timings"`
+}
+
+// DefaultSQLMaxRows is how many rows the SQL tool returns per call by default
+const DefaultSQLMaxRows = 500
+
+// sqlRowCountPages caps how many pages' worth of rows past the first page are counted for
+// total_rows. Counting reads the rows from the database, so it is kept to a few pages.
+const sqlRowCountPages = 4
+
+// SQLToolOption configures NewReadOnlySQLTool
+type SQLToolOption func(*sqlToolConfig)
+
+type sqlToolConfig struct {
+	dialect             SQLDialect
+	maxRows             int
+	timeout, maxTimeout time.Duration
+	readOnly            bool
+}
+
+// WithSQLDialect sets the database dialect, which selects the statements and keywords the
+// tool allows and the introspection and quoting hints in its description. The default is
+// SQLDialectPostgres.
+func WithSQLDialect(dialect SQLDialect) SQLToolOption {
+	return func(c *sqlToolConfig) {
+		c.dialect = dialect
+	}
+}
+
+// WithMaxRows caps the rows returned per call. Larger results are returned a page at a
+// time, with a cursor for the next page. The default is DefaultSQLMaxRows.
+func WithMaxRows(n int) SQLToolOption {
+	return func(c *sqlToolConfig) {
+		c.maxRows = n
+	}
+}
+
+// WithQueryTimeout sets how long queries may run when a call does not set
+// timeout_seconds. The default is 60 seconds.
+func WithQueryTimeout(d time.Duration) SQLToolOption {
+	return func(c *sqlToolConfig) {
+		c.timeout = d
+	}
+}
+
+// WithMaxQueryTimeout caps the timeout_seconds a call may ask for. The default is
+// DefaultSQLMaxQueryTimeout, or the query timeout if that is longer.
+func WithMaxQueryTimeout(d time.Duration) SQLToolOption {
+	return func(c *sqlToolConfig) {
+		c.maxTimeout = d
+	}
+}
+
+// WithReadOnlyTransactions makes read-only a database-level guarantee rather than only
+// keyword validation, which functions with side effects can get around. Postgres queries
+// run in a transaction with SET TRANSACTION READ ONLY, MySQL queries in a START
+// TRANSACTION READ ONLY transaction, and SQLite queries on a connection with PRAGMA
+// query_only. DuckDB has no read-only transactions, so its queries fail: open DuckDB
+// with access_mode=READ_ONLY instead. For the strongest guarantee, also connect as a
+// database role that has only SELECT privileges.
+func WithReadOnlyTransactions(enabled bool) SQLToolOption {
+	return func(c *sqlToolConfig) {
+		c.readOnly = enabled
+	}
 }
 
 // NewReadOnlySQLTool creates a new SQL query tool for LLM use
-func NewReadOnlySQLTool(db *sql.DB, logger *slog.Logger) tools.Tool {
+func NewReadOnlySQLTool(db *sql.DB, logger *slog.Logger, opts ...SQLToolOption) tools.Tool {
 	if logger == nil {
 		logger = slog.Default()
 	}
+	cfg := sqlToolConfig{dialect: SQLDialectPostgres}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.maxRows <= 0 {
+		cfg.maxRows = DefaultSQLMaxRows
+	}
+	if cfg.timeout <= 0 {
+		cfg.timeout = defaultTimeout
+	}
+	if cfg.maxTimeout <= 0 {
+		cfg.maxTimeout = DefaultSQLMaxQueryTimeout
+	}
+	if cfg.maxTimeout < cfg.timeout {
+		cfg.maxTimeout = cfg.timeout
+	}
+	rules, err := cfg.dialect.rules()
+	if err != nil {
+		// Every query fails validation with this error
+		logger.Error("SQL tool misconfigured", "error", err)
+	}
 
 	handler := func(ctx context.Context, params SQLToolParams) (*SQLQueryResult, error) {
 		if params.Query == "" {
 			return nil, fmt.Errorf("query parameter is required")
 		}
 
-		result, err := ExecuteSQLQuery(ctx, logger, db, params.Query)
+		if err := checkSQLFormat(params.Format); err != nil {
+			return nil, tools.NewInvalidParamsError(err.Error())
+		}
+
+		opts := sqlQueryOptions{timeout: cfg.timeout, readOnly: cfg.readOnly}
+		if params.Timeout > 0 {
+			opts.timeout = min(time.Duration(params.Timeout)*time.Second, cfg.maxTimeout)
+		}
+
+		switch params.Explain {
+		case "":
+		case SQLExplainPlan, SQLExplainAnalyze:
+			result, err := explainSQLQuery(ctx, logger, db, cfg.dialect, params.Query, params.Explain == SQLExplainAnalyze, opts)
+			if err != nil {
+				logger.Error("SQL explain failed", "error", err)
+			}
+			return result, err
+		default:
+			return nil, tools.NewInvalidParamsError(fmt.Sprintf("explain must be %q or %q", SQLExplainPlan, SQLExplainAnalyze))
+		}
+
+		opts.page = sqlPage{limit: cfg.maxRows}
+		if params.Limit > 0 && params.Limit < opts.page.limit {
+			opts.page.limit = params.Limit
+		}
+		if params.Cursor != "" {
+			offset, err := decodeSQLCursor(params.Cursor, params.Query)
+			if err != nil {
+				return nil, tools.NewInvalidParamsError(err.Error())
+			}
+			opts.page.offset = offset
+		} else {
+			// Counting reads the rest of the result, so later pages do not count again
+			opts.page.count = sqlRowCountPages * opts.page.limit
+		}
+
+		result, err := executeSQLQuery(ctx, logger, db, cfg.dialect, params.Query, opts)
+		if result != nil && result.Truncated {
+			result.NextCursor = encodeSQLCursor(params.Query, opts.page.offset+len(result.Rows))
+		}
+		if err == nil {
+			formatSQLRows(result, params.Format)
+		}
 		if err != nil {
 			logger.Error("SQL query execution failed", "error", err)
 			return result, err
@@ -44,22 +180,43 @@ func NewReadOnlySQLTool(db *sql.DB, logger *slog.Logger) tools.Tool {
 
 	return tools.NewTool(
 		"ReadOnlySQLQuery",
-		readOnlySQLToolDescription,
+		readOnlySQLToolDescription(cfg.dialect, rules, cfg),
 		handler,
 		tools.WithType("ReadOnlySQLQuery_v1"),
 		tools.WithVerb("Executing SQL query"),
 	)
 }
 
-const readOnlySQLToolDescription = `Executes read-only SQL queries against the database for administrative analysis and debugging.
-
-SECURITY FEATURES:
-- READ-ONLY MODE: Only SELECT and WITH (CTE) queries are allowed
-- All write operations are blocked (INSERT, UPDATE, DELETE, DROP, CREATE, ALTER, TRUNCATE, GRANT, REVOKE, COPY)
-- Whole-word keyword matching prevents false positives (e.g., "INNER JOIN" won't trigger "INSERT" block)
-- Database-specific meta-commands are blocked (e.g., backslash commands)
-- 30-second timeout on all queries
+// readOnlySQLToolDescription describes the tool for a dialect
+func readOnlySQLToolDescription(dialect SQLDialect, rules sqlDialectRules, cfg sqlToolConfig) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Executes read-only %s SQL queries against the database for administrative analysis and debugging.\n\n", rules.name)
+	b.WriteString("SECURITY FEATURES:\n")
+	fmt.Fprintf(&b, "- READ-ONLY MODE: Only %s queries are allowed\n", strings.Join(rules.statements, ", "))
+	fmt.Fprintf(&b, "- All write operations are blocked (%s)\n", strings.Join(rules.forbidden, ", "))
+	b.WriteString("- Whole-word keyword matching prevents false positives (e.g., \"INNER JOIN\" won't trigger \"INSERT\" block)\n")
+	if rules.blockBackslash {
+		b.WriteString("- Database-specific meta-commands are blocked (e.g., backslash commands)\n")
+	}
+	if cfg.readOnly {
+		b.WriteString("- Queries run in a read-only session, so the database itself rejects writes\n")
+	}
+	fmt.Fprintf(&b, "- Queries time out after %d seconds; set timeout_seconds for up to %d\n", int(cfg.timeout.Seconds()), int(cfg.maxTimeout.Seconds()))
+	b.WriteString(readOnlySQLToolUsage)
+	fmt.Fprintf(&b, "\n- At most %d rows are returned per call; when truncated is true, call again with the same query and next_cursor for the next rows, and see total_rows on the first page for the row count", cfg.maxRows)
+	b.WriteString("\n- Add ORDER BY to queries read in pages so pages do not overlap")
+	b.WriteString("\n- Set format to \"markdown\" for a compact table when you only need to read the rows")
+	b.WriteString("\n- Before running a heavy query, set explain to \"plan\" to check its plan and estimated cost without running it")
+	if rules.explainAnalyze != "" {
+		fmt.Fprintf(&b, "; \"analyze\" runs it, for at most %d seconds and rolled back, to report actual rows and timings", int(explainAnalyzeTimeout.Seconds()))
+	}
+	b.WriteString("\n\nSCHEMA:\n")
+	fmt.Fprintf(&b, "- %s\n", rules.introspection)
+	fmt.Fprintf(&b, "- Quote identifiers that are mixed-case, reserved, or contain special characters as %s", dialect.QuoteIdentifier("name"))
+	return b.String()
+}
 
+const readOnlySQLToolUsage = `
 ALLOWED QUERIES:
 ✓ SELECT statements with any complexity
 ✓ JOINs (INNER, LEFT, RIGHT, OUTER)
@@ -73,8 +230,7 @@ BLOCKED QUERIES:
 ✗ Any DML: INSERT, UPDATE, DELETE
 ✗ Any DDL: CREATE, DROP, ALTER, TRUNCATE
 ✗ Security: GRANT, REVOKE
-✗ Data manipulation: COPY
-✗ Meta-commands
+✗ File and session operations specific to the database
 
 COMMON USE CASES:
 - Explore database schema and table structures
@@ -85,70 +241,98 @@ COMMON USE CASES:
 TIPS:
 - Use LIMIT to test queries before running on full datasets
 - Results include execution time and row counts
-- Query validation happens before execution to prevent accidental writes
-- Check your specific database documentation for system tables to list tables and columns (e.g. pg_tables in Postgres, sqlite_master in SQLite)`
+- Query validation happens before execution to prevent accidental writes`
 
 const (
 	defaultTimeout = 60 * time.Second
+
+	// DefaultSQLMaxQueryTimeout is the longest timeout_seconds the SQL tool allows by default
+	DefaultSQLMaxQueryTimeout = 5 * time.Minute
 )
 
 // SQLQueryResult represents the result of a SQL query execution
 type SQLQueryResult struct {
-	Success       bool            `json:"success"`
-	Columns       []string        `json:"columns,omitempty"`
-	Rows          [][]interface{} `json:"rows,omitempty"`
-	ExecutionTime int64           `json:"execution_time,omitempty"` // in milliseconds
-	Error         string          `json:"error,omitempty"`
+	Success       bool              `json:"success"`
+	Columns       []string          `json:"columns,omitempty"`
+	ColumnTypes   []SQLResultColumn `json:"column_types,omitempty"`
+	Rows          [][]interface{}   `json:"rows,omitempty"`
+	ExecutionTime int64             `json:"execution_time,omitempty"` // in milliseconds
+	Error         string            `json:"error,omitempty"`
+
+	// Truncated is set when rows follow the returned ones, which NextCursor reads
+	Truncated  bool   `json:"truncated,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	// TotalRows counts the query's rows when it was read a page at a time. The first page
+	// counts the rows after it, stopping after a limit, which leaves TotalRows a lower
+	// bound and TotalRowsAtLeast set. Later pages only have it on the last page.
+	TotalRows        *int64 `json:"total_rows,omitempty"`
+	TotalRowsAtLeast bool   `json:"total_rows_at_least,omitempty"`
+
+	// Format is set when the rows are in Formatted, as CSV, TSV, or Markdown, instead of Rows
+	Format    string `json:"format,omitempty"`
+	Formatted string `json:"formatted,omitempty"`
+
+	// Plan is set instead of rows when the query was explained
+	Plan *SQLPlan `json:"plan,omitempty"`
 }
 
-// ExecuteSQLQuery executes a read-only SQL query with strict validation
+// sqlQueryOptions are how the SQL tool runs a query
+type sqlQueryOptions struct {
+	page     sqlPage
+	timeout  time.Duration
+	readOnly bool // run in a read-only session
+}
+
+// sqlPage selects the rows of a result to return. A zero limit returns every row.
+type sqlPage struct {
+	offset, limit int
+	// count is how many rows past the page to count for TotalRows. Counting reads
+	// them from the database, so it costs as much as reading them.
+	count int
+}
+
+// ExecuteSQLQuery executes a read-only PostgreSQL query with strict validation
 // It only allows SELECT and WITH queries and blocks any write operations
 func ExecuteSQLQuery(ctx context.Context, logger *slog.Logger, db *sql.DB, query string) (*SQLQueryResult, error) {
-	query = strings.TrimSpace(query)
-	if query == "" {
-		return &SQLQueryResult{
-			Success: false,
-			Error:   "Query cannot be empty",
-		}, fmt.Errorf("empty query")
-	}
-
-	// Strict validation: only allow SELECT and WITH queries
-	upperQuery := strings.ToUpper(query)
-	if !strings.HasPrefix(upperQuery, "SELECT") && !strings.HasPrefix(upperQuery, "WITH") {
-		return &SQLQueryResult{
-			Success: false,
-			Error:   "Only SELECT and WITH queries are allowed",
-		}, fmt.Errorf("forbidden query type")
-	}
+	return ExecuteSQLQueryWithDialect(ctx, logger, db, SQLDialectPostgres, query)
+}
 
-	// Check for dangerous keywords (whole word matches only)
-	dangerousKeywords := []string{
-		"INSERT", "UPDATE", "DELETE", "DROP", "CREATE", "ALTER",
-		"TRUNCATE", "GRANT", "REVOKE", "COPY",
-	}
-	for _, keyword := range dangerousKeywords {
-		if containsWholeWord(upperQuery, keyword) {
-			return &SQLQueryResult{
-				Success: false,
-				Error:   fmt.Sprintf("Forbidden keyword '%s' detected", keyword),
-			}, fmt.Errorf("forbidden keyword: %s", keyword)
-		}
-	}
+// ExecuteSQLQueryWithDialect executes a read-only SQL query, validated with the
+// dialect's rules
+func ExecuteSQLQueryWithDialect(ctx context.Context, logger *slog.Logger, db *sql.DB, dialect SQLDialect, query string) (*SQLQueryResult, error) {
+	return executeSQLQuery(ctx, logger, db, dialect, query, sqlQueryOptions{timeout: defaultTimeout})
+}
 
-	// Check for backslash commands
-	if strings.Contains(query, "\\") {
-		return &SQLQueryResult{
-			Success: false,
-			Error:   "Backslash commands are not allowed",
-		}, fmt.Errorf("backslash commands not allowed")
+// executeSQLQuery validates a query and returns the page of its rows
+func executeSQLQuery(ctx context.Context, logger *slog.Logger, db *sql.DB, dialect SQLDialect, query string, opts sqlQueryOptions) (*SQLQueryResult, error) {
+	query, failed, err := validateSQLQuery(dialect, query)
+	if err != nil {
+		return failed, err
 	}
+	return runSQLQuery(ctx, logger, db, dialect, query, opts)
+}
 
+// runSQLQuery returns the page of a query's rows, without validating the query. Rows
+// before the page are skipped and rows after it only counted, so only the page is held
+// in memory.
+func runSQLQuery(ctx context.Context, logger *slog.Logger, db *sql.DB, dialect SQLDialect, query string, opts sqlQueryOptions) (*SQLQueryResult, error) {
 	// Execute the query with timeout
-	queryCtx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	queryCtx, cancel := context.WithTimeout(ctx, opts.timeout)
 	defer cancel()
 
 	start := time.Now()
-	rows, err := db.QueryContext(queryCtx, query)
+	rules, _ := dialect.rules()
+	session, release, err := beginSQLSession(queryCtx, db, rules, opts.readOnly, false)
+	if err != nil {
+		return &SQLQueryResult{
+			Success: false,
+			Error:   fmt.Sprintf("SQL execution error: %v", err),
+		}, err
+	}
+	defer release()
+
+	page := opts.page
+	rows, err := session.QueryContext(queryCtx, query)
 	if err != nil {
 		errMsg := fmt.Sprintf("SQL execution error: %v", err)
 		return &SQLQueryResult{
@@ -170,11 +354,32 @@ func ExecuteSQLQuery(ctx context.Context, logger *slog.Logger, db *sql.DB, query
 		}, err
 	}
 
+	resultColumns, err := sqlResultColumns(rows, columns)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error getting column types: %v", err)
+		return &SQLQueryResult{
+			Success: false,
+			Error:   errMsg,
+		}, err
+	}
+
+	// Skip rows before the page
+	var skipped int
+	for skipped < page.offset && rows.Next() {
+		skipped++
+	}
+
 	// Prepare result structure
 	var results [][]interface{}
+	truncated := false
 
 	// Process rows
-	for rows.Next() {
+	for skipped == page.offset && rows.Next() {
+		if page.limit > 0 && len(results) == page.limit {
+			truncated = true
+			break
+		}
+
 		// Create a slice of interface{} to hold the values
 		values := make([]interface{}, len(columns))
 		valuePtrs := make([]interface{}, len(columns))
@@ -191,18 +396,20 @@ func ExecuteSQLQuery(ctx context.Context, logger *slog.Logger, db *sql.DB, query
 			}, err
 		}
 
-		// Convert values to strings for JSON serialization
-		stringValues := make([]interface{}, len(values))
+		// Convert values to JSON values of their column's type
 		for i, val := range values {
-			if val == nil {
-				stringValues[i] = nil
-			} else {
-				stringValues[i] = fmt.Sprintf("%v", val)
-			}
+			values[i] = sqlJSONValue(val, resultColumns[i].Type)
 		}
 
-		results = append(results, stringValues)
+		results = append(results, values)
+	}
+
+	// Count the rows after the page
+	var after int
+	for truncated && after < page.count && rows.Next() {
+		after++
 	}
+	atLeast := truncated && after == page.count
 
 	if err = rows.Err(); err != nil {
 		errMsg := fmt.Sprintf("Error iterating rows: %v", err)
@@ -217,14 +424,121 @@ func ExecuteSQLQuery(ctx context.Context, logger *slog.Logger, db *sql.DB, query
 		"execution_time_ms", executionTime,
 		"columns", len(columns))
 
-	return &SQLQueryResult{
+	result := &SQLQueryResult{
 		Success:       true,
 		Columns:       columns,
+		ColumnTypes:   resultColumns,
 		Rows:          results,
 		ExecutionTime: executionTime,
-	}, nil
+		Truncated:     truncated,
+	}
+	if page.limit > 0 && (page.count > 0 || !truncated) {
+		// The row found past the page was not counted
+		total := int64(skipped + len(results) + after)
+		if truncated {
+			total++
+		}
+		result.TotalRows = &total
+		result.TotalRowsAtLeast = atLeast
+	}
+	return result, nil
 }
 
+// validateSQLQuery trims a query and checks it against the dialect's read-only rules.
+// A rejected query returns the failed result to report.
+func validateSQLQuery(dialect SQLDialect, query string) (string, *SQLQueryResult, error) {
+	query = strings.TrimRight(strings.TrimSpace(query), "; \t\n")
+	if query == "" {
+		return query, &SQLQueryResult{
+			Success: false,
+			Error:   "Query cannot be empty",
+		}, fmt.Errorf("empty query")
+	}
+
+	rules, err := dialect.rules()
+	if err != nil {
+		return query, &SQLQueryResult{
+			Success: false,
+			Error:   err.Error(),
+		}, err
+	}
+
+	// Strict validation: only allow the dialect's read statements
+	upperQuery := strings.ToUpper(query)
+	statement := leadingKeyword.FindString(upperQuery)
+	if !slices.Contains(rules.statements, statement) {
+		return query, &SQLQueryResult{
+			Success: false,
+			Error:   fmt.Sprintf("Only %s queries are allowed", strings.Join(rules.statements, ", ")),
+		}, fmt.Errorf("forbidden query type")
+	}
+
+	// Drivers send an argument-less query as one multi-statement message, so a second
+	// statement could end the read-only transaction and write in a new one
+	if !rules.lexing.singleStatement(query) {
+		return query, &SQLQueryResult{
+			Success: false,
+			Error:   "Only one statement is allowed per query",
+		}, fmt.Errorf("multiple statements not allowed")
+	}
+
+	// Check for dangerous keywords (whole word matches only)
+	for _, keyword := range rules.forbidden {
+		if containsWholeWord(upperQuery, keyword) {
+			return query, &SQLQueryResult{
+				Success: false,
+				Error:   fmt.Sprintf("Forbidden keyword '%s' detected", keyword),
+			}, fmt.Errorf("forbidden keyword: %s", keyword)
+		}
+	}
+
+	// Check for backslash commands
+	if rules.blockBackslash && strings.Contains(query, "\\") {
+		return query, &SQLQueryResult{
+			Success: false,
+			Error:   "Backslash commands are not allowed",
+		}, fmt.Errorf("backslash commands not allowed")
+	}
+
+	return query, nil, nil
+}
+
+// sqlCursor is the continuation token of a paged query
+type sqlCursor struct {
+	Offset int    `json:"o"`
+	Query  string `json:"q"` // hash of the query, so a cursor is not reused with another
+}
+
+// encodeSQLCursor returns the cursor reading query's rows from offset
+func encodeSQLCursor(query string, offset int) string {
+	data, _ := json.Marshal(sqlCursor{Offset: offset, Query: sqlQueryHash(query)})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeSQLCursor returns the offset of a cursor issued for query
+func decodeSQLCursor(cursor, query string) (int, error) {
+	var c sqlCursor
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil || c.Offset < 0 {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	if c.Query != sqlQueryHash(query) {
+		return 0, fmt.Errorf("cursor belongs to a different query")
+	}
+	return c.Offset, nil
+}
+
+func sqlQueryHash(query string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(query)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// leadingKeyword matches the statement keyword a query starts with
+var leadingKeyword = regexp.MustCompile(`^[A-Z]+`)
+
 // containsWholeWord checks if a keyword exists as a whole word in the query
 // This prevents false positives like "INNER" matching "INSERT"
 func containsWholeWord(query, keyword string) bool {
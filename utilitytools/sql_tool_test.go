@@ -0,0 +1,500 @@
+package utilitytools
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDriver serves the fake databases registered by openFakeDB
+type fakeDriver struct{}
+
+func init() { sql.Register("utilitytools_fake", fakeDriver{}) }
+
+// catalogResponder returns the rows of a query
+type catalogResponder func(query string, args []driver.Value) [][]driver.Value
+
+// fakeDB is a database answering queries with respond and statements with exec
+type fakeDB struct {
+	respond catalogResponder
+	types   []string                          // database type names of result columns
+	exec    func(query string) (int64, error) // returns the rows a statement affects
+
+	mu                 sync.Mutex
+	commits, rollbacks int
+	readOnlyTxs        int       // transactions begun with the read-only option
+	deadline           time.Time // of the last query
+}
+
+var (
+	fakeDBsMu sync.Mutex
+	fakeDBs   = map[string]*fakeDB{}
+)
+
+// openFakeDB opens db, closing it when the test ends
+func openFakeDB(t *testing.T, db *fakeDB) *sql.DB {
+	t.Helper()
+	fakeDBsMu.Lock()
+	fakeDBs[t.Name()] = db
+	fakeDBsMu.Unlock()
+	sqlDB, err := sql.Open("utilitytools_fake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return sqlDB
+}
+
+type fakeConn struct{ db *fakeDB }
+type fakeStmt struct {
+	query string
+	db    *fakeDB
+}
+type fakeTx struct{ db *fakeDB }
+type fakeRows struct {
+	rows  [][]driver.Value
+	types []string
+}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	fakeDBsMu.Lock()
+	defer fakeDBsMu.Unlock()
+	return fakeConn{fakeDBs[dsn]}, nil
+}
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{query, c.db}, nil }
+func (fakeConn) Close() error                                { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{c.db}, nil }
+func (c fakeConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if opts.ReadOnly {
+		c.db.mu.Lock()
+		c.db.readOnlyTxs++
+		c.db.mu.Unlock()
+	}
+	return fakeTx{c.db}, nil
+}
+func (tx fakeTx) Commit() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.commits++
+	return nil
+}
+func (tx fakeTx) Rollback() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.rollbacks++
+	return nil
+}
+func (fakeStmt) Close() error  { return nil }
+func (fakeStmt) NumInput() int { return -1 }
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	if s.db.exec == nil {
+		return nil, errors.New("exec not supported")
+	}
+	n, err := s.db.exec(s.query)
+	return driver.RowsAffected(n), err
+}
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{s.db.respond(s.query, args), s.db.types}, nil
+}
+func (s fakeStmt) QueryContext(ctx context.Context, named []driver.NamedValue) (driver.Rows, error) {
+	s.db.mu.Lock()
+	s.db.deadline, _ = ctx.Deadline()
+	s.db.mu.Unlock()
+	args := make([]driver.Value, len(named))
+	for i, arg := range named {
+		args[i] = arg.Value
+	}
+	return s.Query(args)
+}
+func (r *fakeRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return make([]string, len(r.types))
+	}
+	return make([]string, len(r.rows[0]))
+}
+func (r *fakeRows) ColumnTypeDatabaseTypeName(i int) string {
+	if i < len(r.types) {
+		return r.types[i]
+	}
+	return ""
+}
+func (*fakeRows) Close() error { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestExecuteSQLQueryWithDialect_Validation(t *testing.T) {
+	db := openFakeDB(t, &fakeDB{respond: func(string, []driver.Value) [][]driver.Value {
+		return [][]driver.Value{{int64(1)}}
+	}})
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		dialect SQLDialect
+		query   string
+		allowed bool
+	}{
+		{SQLDialectPostgres, "SELECT replace(name, 'a', 'b') FROM users", true},
+		{SQLDialectPostgres, "WITH t AS (SELECT 1) SELECT * FROM t", true},
+		{SQLDialectPostgres, "SHOW search_path", false},
+		{SQLDialectPostgres, "COPY users TO '/tmp/users'", false},
+		{SQLDialectPostgres, `SELECT 1 \gexec`, false},
+		{SQLDialectPostgres, "SELECT * FROM users;", true},
+		{SQLDialectPostgres, "SELECT 1; COMMIT; SELECT mutating_fn()", false},
+		{SQLDialectPostgres, "SELECT 1; END; SELECT mutating_fn()", false},
+		{SQLDialectPostgres, "SELECT 1; SET TRANSACTION READ WRITE", false},
+		{SQLDialectPostgres, "SELECT CASE WHEN 1 = 1 THEN 'a' END", true},
+		{SQLDialectPostgres, "SELECT ';'", true},
+		{SQLDialectPostgres, `SELECT "a;b" FROM t -- trailing; comment`, true},
+		{SQLDialectPostgres, "SELECT $$;$$", true},
+		{SQLDialectPostgres, "SELECT $$'$$; SET TRANSACTION READ WRITE; SELECT '$$'", false},
+		{SQLDialectMySQL, "SHOW TABLES", true},
+		{SQLDialectMySQL, "DESCRIBE users", true},
+		{SQLDialectMySQL, `SELECT 'it\'s'`, true},
+		{SQLDialectMySQL, `SELECT '\''; SET autocommit = 1; SELECT '`, false},
+		{SQLDialectMySQL, "SELECT * FROM users INTO OUTFILE '/tmp/users'", false},
+		{SQLDialectMySQL, "SELECT * FROM users FOR UPDATE", false},
+		{SQLDialectSQLite, "SELECT * FROM pragma_table_info('users')", true},
+		{SQLDialectSQLite, "SELECT 1; ATTACH 'other.db' AS other", false},
+		{SQLDialectSQLite, "PRAGMA journal_mode = DELETE", false},
+		{SQLDialectDuckDB, "FROM users LIMIT 5", true},
+		{SQLDialectDuckDB, "SUMMARIZE users", true},
+		{SQLDialectDuckDB, "SELECT 1; INSTALL httpfs", false},
+		{SQLDialectDuckDB, "SELECT * FROM users; COPY users TO 'users.parquet'", false},
+		{"oracle", "SELECT 1 FROM dual", false},
+	}
+	for _, tt := range tests {
+		result, err := ExecuteSQLQueryWithDialect(context.Background(), logger, db, tt.dialect, tt.query)
+		if allowed := err == nil && result.Success; allowed != tt.allowed {
+			t.Errorf("%s %q: allowed = %v, want %v (%v)", tt.dialect, tt.query, allowed, tt.allowed, err)
+		}
+	}
+}
+
+func TestNewReadOnlySQLTool_Dialect(t *testing.T) {
+	tool := NewReadOnlySQLTool(nil, nil, WithSQLDialect(SQLDialectMySQL))
+	description := tool.Spec().Description
+	for _, want := range []string{"read-only MySQL", "SHOW TABLES", "`name`"} {
+		if !strings.Contains(description, want) {
+			t.Errorf("description does not mention %q:\n%s", want, description)
+		}
+	}
+
+	description = NewReadOnlySQLTool(nil, nil).Spec().Description
+	if !strings.Contains(description, "read-only PostgreSQL") || !strings.Contains(description, "backslash commands") {
+		t.Errorf("expected Postgres by default:\n%s", description)
+	}
+}
+
+func TestSQLDialect_QuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		dialect SQLDialect
+		name    string
+		want    string
+	}{
+		{SQLDialectPostgres, "Order", `"Order"`},
+		{SQLDialectSQLite, `say "hi"`, `"say ""hi"""`},
+		{SQLDialectDuckDB, "select", `"select"`},
+		{SQLDialectMySQL, "odd`name", "`odd``name`"},
+	}
+	for _, tt := range tests {
+		if got := tt.dialect.QuoteIdentifier(tt.name); got != tt.want {
+			t.Errorf("%s.QuoteIdentifier(%q) = %s, want %s", tt.dialect, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewReadOnlySQLTool_Pages(t *testing.T) {
+	db := openFakeDB(t, &fakeDB{respond: func(string, []driver.Value) [][]driver.Value {
+		rows := make([][]driver.Value, 7)
+		for i := range rows {
+			rows[i] = []driver.Value{int64(i)}
+		}
+		return rows
+	}})
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tool := NewReadOnlySQLTool(db, logger, WithMaxRows(3))
+
+	var pages [][]interface{}
+	cursor := ""
+	for i := 0; i < 3; i++ {
+		var result SQLQueryResult
+		args := `{"query": "SELECT n FROM numbers ORDER BY n", "cursor": "` + cursor + `"}`
+		if err := run(t, tool, args, &result); err != nil {
+			t.Fatal(err)
+		}
+		// The middle page does not count the rows again
+		if counted := i != 1; (result.TotalRows != nil) != counted || counted && (*result.TotalRows != 7 || result.TotalRowsAtLeast) {
+			t.Errorf("page %d: total rows = %v (at least: %v)", i, result.TotalRows, result.TotalRowsAtLeast)
+		}
+		if last := i == 2; result.Truncated == last || (result.NextCursor == "") != last {
+			t.Errorf("page %d: truncated = %v, next cursor = %q", i, result.Truncated, result.NextCursor)
+		}
+		pages = append(pages, result.Rows...)
+		cursor = result.NextCursor
+	}
+	if len(pages) != 7 || pages[0][0] != float64(0) || pages[6][0] != float64(6) {
+		t.Errorf("rows = %v", pages)
+	}
+
+	var result SQLQueryResult
+	if err := run(t, tool, `{"query": "SELECT n FROM numbers", "limit": 2}`, &result); err != nil || len(result.Rows) != 2 {
+		t.Errorf("limit 2: rows = %v, err = %v", result.Rows, err)
+	}
+	if err := run(t, tool, `{"query": "SELECT 1", "cursor": "`+encodeSQLCursor("SELECT n FROM numbers", 3)+`"}`, &result); !isInvalidParams(err) {
+		t.Errorf("expected a cursor for another query to be invalid params, got %v", err)
+	}
+
+	// The first page counts at most a few pages of rows past it, and the row that truncated it
+	if err := run(t, NewReadOnlySQLTool(db, logger, WithMaxRows(1)), `{"query": "SELECT n FROM numbers"}`, &result); err != nil ||
+		result.TotalRows == nil || *result.TotalRows != 2+sqlRowCountPages || !result.TotalRowsAtLeast {
+		t.Errorf("total rows = %v (at least: %v), err = %v", result.TotalRows, result.TotalRowsAtLeast, err)
+	}
+
+	// Counting stops after the count limit
+	page, err := executeSQLQuery(context.Background(), logger, db, SQLDialectPostgres, "SELECT n FROM numbers",
+		sqlQueryOptions{page: sqlPage{limit: 3, count: 2}, timeout: time.Second})
+	if err != nil || *page.TotalRows != 6 || !page.TotalRowsAtLeast {
+		t.Errorf("total rows = %v (at least: %v), err = %v", *page.TotalRows, page.TotalRowsAtLeast, err)
+	}
+}
+
+func TestExecuteSQLQuery_TypedRows(t *testing.T) {
+	when := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	db := openFakeDB(t, &fakeDB{
+		respond: func(string, []driver.Value) [][]driver.Value {
+			return [][]driver.Value{{"widget", int64(3), 9.99, true, when, nil}}
+		},
+		types: []string{"text", "int8", "float8", "bool", "timestamptz", "text"},
+	})
+
+	result, err := ExecuteSQLQuery(context.Background(), slog.New(slog.NewTextHandler(io.Discard, nil)), db,
+		"SELECT name, sold, price, active, updated_at, note FROM products")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(result.Rows)
+	if want := `[["widget",3,9.99,true,"2024-03-01T12:30:00Z",null]]`; string(data) != want {
+		t.Errorf("rows = %s, want %s", data, want)
+	}
+	if len(result.ColumnTypes) != 6 || result.ColumnTypes[1].Type != "INT8" || result.ColumnTypes[4].Type != "TIMESTAMPTZ" {
+		t.Errorf("column types = %+v", result.ColumnTypes)
+	}
+}
+
+func TestSQLJSONValue(t *testing.T) {
+	when := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		value  interface{}
+		dbType string
+		want   interface{}
+	}{
+		{nil, "TEXT", nil},
+		{int64(42), "INT8", int64(42)},
+		{3.5, "FLOAT8", 3.5},
+		{math.NaN(), "FLOAT8", "NaN"},
+		{true, "BOOL", true},
+		{when, "TIMESTAMPTZ", "2024-03-01T12:30:00Z"},
+		{[]byte{0xde, 0xad, 0xbe, 0xef}, "BYTEA", "3q2+7w=="},
+		{[]byte("hello"), "BLOB", "aGVsbG8="},
+		{[]byte{0xff, 0xfe}, "", "//4="},
+		// Text protocol values, as the MySQL driver returns them
+		{[]byte("hello"), "VARCHAR", "hello"},
+		{[]byte("-17"), "BIGINT", int64(-17)},
+		{[]byte("2.25"), "DOUBLE", 2.25},
+		{[]byte("12.50"), "DECIMAL", "12.50"},
+		{[]byte("1 day"), "INTERVAL", "1 day"},
+	}
+	for _, tt := range tests {
+		if got := sqlJSONValue(tt.value, tt.dbType); got != tt.want {
+			t.Errorf("sqlJSONValue(%v, %q) = %#v, want %#v", tt.value, tt.dbType, got, tt.want)
+		}
+	}
+}
+
+func TestNewReadOnlySQLTool_Explain(t *testing.T) {
+	var statements []string
+	db := openFakeDB(t, &fakeDB{respond: func(query string, _ []driver.Value) [][]driver.Value {
+		statements = append(statements, query)
+		switch {
+		case strings.HasPrefix(query, "EXPLAIN QUERY PLAN"):
+			return [][]driver.Value{{int64(2), int64(0), int64(0), "SCAN users"}, {int64(5), int64(2), int64(0), "USE TEMP B-TREE FOR ORDER BY"}}
+		case strings.HasPrefix(query, "EXPLAIN"):
+			return [][]driver.Value{{[]byte(`[{"Plan": {"Node Type": "Seq Scan", "Total Cost": 12.5}}]`)}}
+		}
+		return nil
+	}})
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	postgres := NewReadOnlySQLTool(db, logger)
+
+	var result SQLQueryResult
+	if err := run(t, postgres, `{"query": "SELECT * FROM users;", "explain": "plan"}`, &result); err != nil {
+		t.Fatal(err)
+	}
+	if statements[0] != "EXPLAIN (FORMAT JSON) SELECT * FROM users" {
+		t.Errorf("statement = %q", statements[0])
+	}
+	plan, _ := json.Marshal(result.Plan)
+	if want := `{"json":[{"Plan":{"Node Type":"Seq Scan","Total Cost":12.5}}]}`; string(plan) != want || result.Rows != nil {
+		t.Errorf("plan = %s, rows = %v", plan, result.Rows)
+	}
+
+	result = SQLQueryResult{}
+	if err := run(t, postgres, `{"query": "SELECT * FROM users", "explain": "analyze"}`, &result); err != nil || !result.Plan.Analyzed {
+		t.Errorf("analyze: plan = %+v, err = %v", result.Plan, err)
+	}
+	if statements[1] != "EXPLAIN (ANALYZE, FORMAT JSON) SELECT * FROM users" {
+		t.Errorf("statement = %q", statements[1])
+	}
+
+	// The query is validated before it is explained
+	if err := run(t, postgres, `{"query": "DELETE FROM users", "explain": "analyze"}`, &result); err == nil {
+		t.Error("expected a write query to be rejected")
+	}
+	if err := run(t, postgres, `{"query": "SELECT 1", "explain": "cost"}`, &result); !isInvalidParams(err) {
+		t.Errorf("expected an unknown explain mode to be invalid params, got %v", err)
+	}
+
+	sqlite := NewReadOnlySQLTool(db, logger, WithSQLDialect(SQLDialectSQLite))
+	result = SQLQueryResult{}
+	if err := run(t, sqlite, `{"query": "SELECT * FROM users ORDER BY name", "explain": "plan"}`, &result); err != nil {
+		t.Fatal(err)
+	}
+	want := []SQLPlanStep{{ID: 2, Detail: "SCAN users"}, {ID: 5, Parent: 2, Detail: "USE TEMP B-TREE FOR ORDER BY"}}
+	if result.Plan == nil || !reflect.DeepEqual(result.Plan.Steps, want) {
+		t.Errorf("steps = %+v", result.Plan)
+	}
+	if err := run(t, sqlite, `{"query": "SELECT 1", "explain": "analyze"}`, &result); err == nil {
+		t.Error("expected SQLite to reject analyze")
+	}
+	if err := run(t, sqlite, `{"query": "EXPLAIN SELECT 1", "explain": "plan"}`, &result); err == nil {
+		t.Error("expected an EXPLAIN query to be rejected")
+	}
+}
+
+func TestNewReadOnlySQLTool_Formats(t *testing.T) {
+	db := openFakeDB(t, &fakeDB{
+		respond: func(string, []driver.Value) [][]driver.Value {
+			return [][]driver.Value{{"widget", int64(3), "a|b"}, {"gadget, large", nil, "line\nbreak"}}
+		},
+	})
+	tool := NewReadOnlySQLTool(db, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"csv", ",,\nwidget,3,a|b\n\"gadget, large\",,\"line\nbreak\"\n"},
+		{"tsv", "\t\t\nwidget\t3\ta|b\ngadget, large\t\t\"line\nbreak\"\n"},
+		{"markdown", "|  |  |  |\n| --- | --- | --- |\n| widget | 3 | a\\|b |\n| gadget, large | NULL | line<br>break |\n"},
+	}
+	for _, tt := range tests {
+		var result SQLQueryResult
+		if err := run(t, tool, `{"query": "SELECT name, sold, note FROM products", "format": "`+tt.format+`"}`, &result); err != nil {
+			t.Fatal(err)
+		}
+		if result.Format != tt.format || result.Formatted != tt.want || result.Rows != nil {
+			t.Errorf("%s: formatted = %q, rows = %v, want %q", tt.format, result.Formatted, result.Rows, tt.want)
+		}
+	}
+
+	var result SQLQueryResult
+	if err := run(t, tool, `{"query": "SELECT 1", "format": "xml"}`, &result); !isInvalidParams(err) {
+		t.Errorf("expected an unknown format to be invalid params, got %v", err)
+	}
+}
+
+func TestNewReadOnlySQLTool_Timeout(t *testing.T) {
+	fake := &fakeDB{respond: func(string, []driver.Value) [][]driver.Value { return nil }}
+	db := openFakeDB(t, fake)
+	tool := NewReadOnlySQLTool(db, slog.New(slog.NewTextHandler(io.Discard, nil)),
+		WithQueryTimeout(10*time.Second), WithMaxQueryTimeout(time.Minute))
+	if description := tool.Spec().Description; !strings.Contains(description, "time out after 10 seconds; set timeout_seconds for up to 60") {
+		t.Errorf("description does not state the timeouts:\n%s", description)
+	}
+
+	tests := []struct {
+		args string
+		want time.Duration
+	}{
+		{`{"query": "SELECT 1"}`, 10 * time.Second},
+		{`{"query": "SELECT 1", "timeout_seconds": 30}`, 30 * time.Second},
+		{`{"query": "SELECT 1", "timeout_seconds": 3600}`, time.Minute},
+		{`{"query": "SELECT 1", "timeout_seconds": 3600, "explain": "analyze"}`, explainAnalyzeTimeout},
+	}
+	for _, tt := range tests {
+		var result SQLQueryResult
+		start := time.Now()
+		if err := run(t, tool, tt.args, &result); err != nil {
+			t.Fatal(err)
+		}
+		if got := fake.deadline.Sub(start); got < tt.want-time.Second || got > tt.want+time.Second {
+			t.Errorf("%s: timeout = %s, want %s", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestNewReadOnlySQLTool_ReadOnlyTransactions(t *testing.T) {
+	tests := []struct {
+		dialect  SQLDialect
+		executed []string
+		txs      int
+	}{
+		{SQLDialectPostgres, []string{"SET TRANSACTION READ ONLY"}, 0},
+		{SQLDialectMySQL, nil, 1},
+		{SQLDialectSQLite, []string{"PRAGMA query_only = ON", "PRAGMA query_only = OFF"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			var executed []string
+			fake := &fakeDB{
+				respond: func(string, []driver.Value) [][]driver.Value { return [][]driver.Value{{int64(1)}} },
+				exec: func(query string) (int64, error) {
+					executed = append(executed, query)
+					return 0, nil
+				},
+			}
+			tool := NewReadOnlySQLTool(openFakeDB(t, fake), slog.New(slog.NewTextHandler(io.Discard, nil)),
+				WithSQLDialect(tt.dialect), WithReadOnlyTransactions(true))
+
+			var result SQLQueryResult
+			if err := run(t, tool, `{"query": "SELECT 1"}`, &result); err != nil || len(result.Rows) != 1 {
+				t.Fatalf("rows = %v, err = %v", result.Rows, err)
+			}
+			if !reflect.DeepEqual(executed, tt.executed) || fake.readOnlyTxs != tt.txs {
+				t.Errorf("executed = %q, read-only transactions = %d", executed, fake.readOnlyTxs)
+			}
+			if fake.commits != 0 {
+				t.Errorf("commits = %d", fake.commits)
+			}
+
+			// A second statement could end the read-only transaction, so it never reaches the database
+			executed = nil
+			if err := run(t, tool, `{"query": "SELECT 1; COMMIT; SELECT mutating_fn()"}`, &result); err == nil || len(executed) != 0 {
+				t.Errorf("expected the multi-statement query to be rejected, got %v and executed %q", err, executed)
+			}
+		})
+	}
+
+	tool := NewReadOnlySQLTool(openFakeDB(t, &fakeDB{}), slog.New(slog.NewTextHandler(io.Discard, nil)),
+		WithSQLDialect(SQLDialectDuckDB), WithReadOnlyTransactions(true))
+	var result SQLQueryResult
+	if err := run(t, tool, `{"query": "SELECT 1"}`, &result); err == nil || !strings.Contains(err.Error(), "no read-only transactions") {
+		t.Errorf("expected DuckDB to refuse read-only transactions, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package utilitytools
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SQLResultColumn describes a column of a query result
+type SQLResultColumn struct {
+	Name     string `json:"name"`
+	Type     string `json:"type,omitempty"`     // database type name, such as "VARCHAR" or "INT8", when the driver reports it
+	Nullable *bool  `json:"nullable,omitempty"` // set when the driver reports it
+}
+
+// sqlResultColumns describes the columns of rows
+func sqlResultColumns(rows *sql.Rows, names []string) ([]SQLResultColumn, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]SQLResultColumn, len(names))
+	for i, name := range names {
+		columns[i].Name = name
+		if i < len(types) {
+			columns[i].Type = strings.ToUpper(types[i].DatabaseTypeName())
+			if nullable, ok := types[i].Nullable(); ok {
+				columns[i].Nullable = &nullable
+			}
+		}
+	}
+	return columns, nil
+}
+
+// sqlJSONValue converts a scanned value to a JSON value. Numbers and booleans stay typed,
+// times become RFC 3339 strings, and bytes become text, or base64 for binary columns and
+// invalid UTF-8. Drivers that return every value as text bytes, such as MySQL's, have
+// integers, floats, and booleans parsed by the column's database type; decimals stay
+// strings so they keep their precision.
+func sqlJSONValue(v interface{}, dbType string) interface{} {
+	switch v := v.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case float64:
+		return sqlJSONFloat(v)
+	case float32:
+		return sqlJSONFloat(float64(v))
+	case []byte:
+		if isBinarySQLType(dbType) || !utf8.Valid(v) {
+			return base64.StdEncoding.EncodeToString(v)
+		}
+		return parseSQLText(string(v), dbType)
+	}
+	return v
+}
+
+// sqlJSONFloat keeps NaN and infinities, which JSON cannot encode, as strings
+func sqlJSONFloat(f float64) interface{} {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return f
+}
+
+// parseSQLText parses a value a driver returned as text by its column's type
+func parseSQLText(s, dbType string) interface{} {
+	switch {
+	case strings.Contains(dbType, "INT") && !strings.Contains(dbType, "INTERVAL") && !strings.Contains(dbType, "POINT"):
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+	case strings.Contains(dbType, "FLOAT") || strings.Contains(dbType, "DOUBLE") || dbType == "REAL":
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return sqlJSONFloat(f)
+		}
+	case strings.HasPrefix(dbType, "BOOL"):
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	}
+	return s
+}
+
+// isBinarySQLType reports whether a database type holds raw bytes
+func isBinarySQLType(dbType string) bool {
+	return dbType == "BYTEA" || strings.Contains(dbType, "BLOB") || strings.Contains(dbType, "BINARY")
+}
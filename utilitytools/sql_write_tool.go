@@ -0,0 +1,299 @@
+package utilitytools
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+	"github.com/mhpenta/minimcp/tools/mcpctx"
+)
+
+// Defaults for SQLWriteConfig
+const (
+	DefaultSQLWriteMaxRows  = 100
+	DefaultSQLWriteTokenTTL = 5 * time.Minute
+)
+
+// SQLWriteConfig configures NewSQLWriteTool
+type SQLWriteConfig struct {
+	// Dialect selects the keywords rejected besides DDL (default SQLDialectPostgres)
+	Dialect SQLDialect
+
+	// MaxRows caps the rows one statement may change (default 100). Statements changing
+	// more are rolled back.
+	MaxRows int64
+
+	// TokenTTL is how long a dry run's confirmation token stays valid (default 5m)
+	TokenTTL time.Duration
+
+	// Audit receives a record of every dry run and write. Defaults to logging it to Logger.
+	Audit func(ctx context.Context, record SQLWriteAuditRecord)
+
+	Logger *slog.Logger
+}
+
+// SQLWriteParams defines parameters for the SQL write tool
+type SQLWriteParams struct {
+	Statement    string `json:"statement" jsonschema:"a single INSERT, UPDATE, or DELETE statement"`
+	ConfirmToken string `json:"confirm_token,omitempty" jsonschema:"confirm_token from a dry run of the same statement; omit it to dry-run the statement"`
+}
+
+// SQLWriteResult is the outcome of a dry run or a confirmed write
+type SQLWriteResult struct {
+	DryRun       bool       `json:"dry_run"`
+	RowsAffected int64      `json:"rows_affected"`
+	Committed    bool       `json:"committed"`
+	ConfirmToken string     `json:"confirm_token,omitempty"` // set by a dry run; pass it back to commit
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	DurationMs   int64      `json:"duration_ms"`
+}
+
+// SQLWriteAuditRecord describes a statement the SQL write tool ran
+type SQLWriteAuditRecord struct {
+	Statement    string
+	DryRun       bool
+	RowsAffected int64
+	Committed    bool
+	Caller       string // subject of the authenticated principal, if any
+	Duration     time.Duration
+	Err          error // set when the statement failed or was rolled back over MaxRows
+}
+
+// sqlWriteStatements are the statements the write tool runs
+var sqlWriteStatements = []string{"INSERT", "UPDATE", "DELETE"}
+
+// NewSQLWriteTool creates a tool named "SQLWrite" that runs INSERT, UPDATE, and DELETE
+// statements in two steps. A call without a confirmation token is a dry run: the statement
+// runs in a transaction that is rolled back, and the result reports the rows it would
+// change with a single-use token. Calling again with the same statement and the token
+// runs it in a transaction that is committed only if at most MaxRows rows changed. DDL
+// and one statement per call are enforced, and every run is audited. It is opt-in:
+// register it only on servers whose clients may change the database.
+//
+// A dry run executes the statement, so side effects outside the transaction, such as
+// sequence increments, are not undone.
+func NewSQLWriteTool(db *sql.DB, cfg SQLWriteConfig) (tools.Tool, error) {
+	if db == nil {
+		return nil, errors.New("sql write tool: no database")
+	}
+	if cfg.Dialect == "" {
+		cfg.Dialect = SQLDialectPostgres
+	}
+	rules, err := cfg.Dialect.rules()
+	if err != nil {
+		return nil, fmt.Errorf("sql write tool: %w", err)
+	}
+	if cfg.MaxRows <= 0 {
+		cfg.MaxRows = DefaultSQLWriteMaxRows
+	}
+	if cfg.TokenTTL <= 0 {
+		cfg.TokenTTL = DefaultSQLWriteTokenTTL
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	w := &sqlWriteTool{db: db, cfg: cfg, rules: rules, pending: make(map[string]pendingSQLWrite)}
+	description := fmt.Sprintf("Changes %s data with a single INSERT, UPDATE, or DELETE statement, in two steps. "+
+		"First call with only the statement: it runs in a rolled-back transaction and returns rows_affected and a confirm_token. "+
+		"Review the row count, then call again with the same statement and the confirm_token, within %s, to commit it. "+
+		"Statements changing more than %d rows are rolled back. Schema changes (CREATE, ALTER, DROP, TRUNCATE) and permission changes are not allowed.",
+		rules.name, cfg.TokenTTL, cfg.MaxRows)
+	return tools.NewTool("SQLWrite", description, w.run,
+		tools.WithType("SQLWrite_v1"),
+		tools.WithVerb("Writing to database"),
+		tools.WithDestructive(true),
+		tools.WithSequential(true),
+	), nil
+}
+
+type sqlWriteTool struct {
+	db    *sql.DB
+	cfg   SQLWriteConfig
+	rules sqlDialectRules
+
+	mu      sync.Mutex
+	pending map[string]pendingSQLWrite // by confirmation token
+}
+
+// pendingSQLWrite is a dry-run statement awaiting confirmation
+type pendingSQLWrite struct {
+	statement string
+	caller    string
+	expires   time.Time
+}
+
+func (w *sqlWriteTool) run(ctx context.Context, params SQLWriteParams) (*SQLWriteResult, error) {
+	statement, err := w.validate(params.Statement)
+	if err != nil {
+		return nil, tools.NewInvalidParamsError(err.Error())
+	}
+	var caller string
+	if principal := mcpctx.Principal(ctx); principal != nil {
+		caller = principal.Subject
+	}
+
+	dryRun := params.ConfirmToken == ""
+	if !dryRun {
+		if err := w.redeem(params.ConfirmToken, statement, caller); err != nil {
+			return nil, tools.NewInvalidParamsError(err.Error())
+		}
+	}
+
+	start := time.Now()
+	rowsAffected, committed, err := w.exec(ctx, statement, dryRun)
+	record := SQLWriteAuditRecord{
+		Statement:    statement,
+		DryRun:       dryRun,
+		RowsAffected: rowsAffected,
+		Committed:    committed,
+		Caller:       caller,
+		Duration:     time.Since(start),
+		Err:          err,
+	}
+	w.audit(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SQLWriteResult{
+		DryRun:       dryRun,
+		RowsAffected: rowsAffected,
+		Committed:    committed,
+		DurationMs:   record.Duration.Milliseconds(),
+	}
+	if dryRun {
+		token, expires, err := w.issue(statement, caller)
+		if err != nil {
+			return nil, err
+		}
+		result.ConfirmToken, result.ExpiresAt = token, &expires
+	}
+	return result, nil
+}
+
+// validate trims a statement and checks it is a single INSERT, UPDATE, or DELETE without
+// forbidden keywords
+func (w *sqlWriteTool) validate(statement string) (string, error) {
+	statement = strings.TrimRight(strings.TrimSpace(statement), "; \t\n")
+	upper := strings.ToUpper(statement)
+	if !slices.Contains(sqlWriteStatements, leadingKeyword.FindString(upper)) {
+		return "", errors.New("only INSERT, UPDATE, and DELETE statements are allowed")
+	}
+	if !w.rules.lexing.singleStatement(statement) {
+		return "", errors.New("only one statement is allowed per call")
+	}
+	for _, keyword := range w.rules.forbidden {
+		if slices.Contains(sqlWriteStatements, keyword) {
+			continue
+		}
+		if containsWholeWord(upper, keyword) {
+			return "", fmt.Errorf("forbidden keyword '%s' detected", keyword)
+		}
+	}
+	if w.rules.blockBackslash && strings.Contains(statement, "\\") {
+		return "", errors.New("backslash commands are not allowed")
+	}
+	return statement, nil
+}
+
+// exec runs a statement in a transaction, committed unless it is a dry run or changes
+// more than MaxRows rows
+func (w *sqlWriteTool) exec(ctx context.Context, statement string, dryRun bool) (int64, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, statement)
+	if err != nil {
+		return 0, false, fmt.Errorf("SQL execution error: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, false, fmt.Errorf("counting affected rows: %w", err)
+	}
+	if rowsAffected > w.cfg.MaxRows {
+		return rowsAffected, false, fmt.Errorf("statement changes %d rows, more than the limit of %d; rolled back", rowsAffected, w.cfg.MaxRows)
+	}
+	if dryRun {
+		return rowsAffected, false, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return rowsAffected, false, fmt.Errorf("committing: %w", err)
+	}
+	return rowsAffected, true, nil
+}
+
+// issue returns a confirmation token for a dry-run statement. It fails rather than issue
+// a guessable token when the random source does.
+func (w *sqlWriteTool) issue(statement, caller string) (string, time.Time, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", time.Time{}, fmt.Errorf("generating confirm token: %w", err)
+	}
+	token := hex.EncodeToString(b[:])
+	expires := time.Now().Add(w.cfg.TokenTTL)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := time.Now()
+	for t, p := range w.pending {
+		if now.After(p.expires) {
+			delete(w.pending, t)
+		}
+	}
+	w.pending[token] = pendingSQLWrite{statement: statement, caller: caller, expires: expires}
+	return token, expires, nil
+}
+
+// redeem consumes a confirmation token, which must have been issued to the same caller
+// for the same statement
+func (w *sqlWriteTool) redeem(token, statement, caller string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	p, ok := w.pending[token]
+	if !ok || time.Now().After(p.expires) {
+		delete(w.pending, token)
+		return errors.New("unknown or expired confirm_token; dry-run the statement again")
+	}
+	if p.statement != statement || p.caller != caller {
+		return errors.New("confirm_token was issued for a different statement or caller")
+	}
+	delete(w.pending, token)
+	return nil
+}
+
+func (w *sqlWriteTool) audit(ctx context.Context, record SQLWriteAuditRecord) {
+	if w.cfg.Audit != nil {
+		w.cfg.Audit(ctx, record)
+		return
+	}
+	attrs := []interface{}{
+		"statement", record.Statement,
+		"dry_run", record.DryRun,
+		"rows_affected", record.RowsAffected,
+		"committed", record.Committed,
+		"duration_ms", record.Duration.Milliseconds(),
+	}
+	if record.Caller != "" {
+		attrs = append(attrs, "caller", record.Caller)
+	}
+	if record.Err != nil {
+		attrs = append(attrs, "error", record.Err)
+	}
+	w.cfg.Logger.Info("sql write", attrs...)
+}
@@ -0,0 +1,127 @@
+package utilitytools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mhpenta/minimcp/tools/mcpctx"
+)
+
+func TestSQLWriteTool_ConfirmedWrite(t *testing.T) {
+	affected := int64(3)
+	var executed []string
+	fake := &fakeDB{exec: func(query string) (int64, error) {
+		executed = append(executed, query)
+		return affected, nil
+	}}
+	var records []SQLWriteAuditRecord
+	tool, err := NewSQLWriteTool(openFakeDB(t, fake), SQLWriteConfig{
+		MaxRows: 5,
+		Audit:   func(_ context.Context, record SQLWriteAuditRecord) { records = append(records, record) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	statement := "UPDATE orders SET status = 'shipped' WHERE id IN (1, 2, 3);"
+
+	var dryRun SQLWriteResult
+	if err := run(t, tool, `{"statement": "`+statement+`"}`, &dryRun); err != nil {
+		t.Fatal(err)
+	}
+	if !dryRun.DryRun || dryRun.Committed || dryRun.RowsAffected != 3 || dryRun.ConfirmToken == "" || dryRun.ExpiresAt == nil {
+		t.Fatalf("dry run = %+v", dryRun)
+	}
+	if fake.commits != 0 || fake.rollbacks != 1 {
+		t.Errorf("dry run: commits = %d, rollbacks = %d", fake.commits, fake.rollbacks)
+	}
+
+	var write SQLWriteResult
+	args, _ := json.Marshal(SQLWriteParams{Statement: statement, ConfirmToken: dryRun.ConfirmToken})
+	if err := run(t, tool, string(args), &write); err != nil {
+		t.Fatal(err)
+	}
+	if write.DryRun || !write.Committed || write.RowsAffected != 3 || write.ConfirmToken != "" {
+		t.Errorf("write = %+v", write)
+	}
+	if fake.commits != 1 {
+		t.Errorf("commits = %d", fake.commits)
+	}
+	if want := strings.TrimSuffix(statement, ";"); len(executed) != 2 || executed[1] != want {
+		t.Errorf("executed = %q", executed)
+	}
+
+	// Tokens are single-use
+	if err := run(t, tool, string(args), &write); !isInvalidParams(err) {
+		t.Errorf("expected a reused token to be invalid params, got %v", err)
+	}
+
+	if len(records) != 2 || !records[0].DryRun || !records[1].Committed || records[1].RowsAffected != 3 {
+		t.Errorf("audit records = %+v", records)
+	}
+}
+
+func TestSQLWriteTool_Guards(t *testing.T) {
+	affected := int64(1)
+	fake := &fakeDB{exec: func(string) (int64, error) { return affected, nil }}
+	tool, err := NewSQLWriteTool(openFakeDB(t, fake), SQLWriteConfig{MaxRows: 10, Audit: func(context.Context, SQLWriteAuditRecord) {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out SQLWriteResult
+	for _, statement := range []string{
+		"SELECT * FROM orders",
+		"DROP TABLE orders",
+		"DELETE FROM orders; DROP TABLE orders",
+		"INSERT INTO audit SELECT * FROM orders; COPY orders TO '/tmp/x'",
+		"UPDATE orders SET note = 'a' WHERE id = 1 RETURNING (SELECT 1) \\g",
+	} {
+		args, _ := json.Marshal(SQLWriteParams{Statement: statement})
+		if err := run(t, tool, string(args), &out); !isInvalidParams(err) {
+			t.Errorf("%q: expected invalid params, got %v", statement, err)
+		}
+	}
+
+	// Semicolons inside string literals do not end the statement
+	note, _ := json.Marshal(SQLWriteParams{Statement: "UPDATE orders SET note = 'a; b' WHERE id = 1"})
+	if err := run(t, tool, string(note), &out); err != nil {
+		t.Errorf("expected a quoted semicolon to be allowed, got %v", err)
+	}
+
+	// A token is bound to its statement and caller
+	statement := "DELETE FROM orders WHERE id = 1"
+	args, _ := json.Marshal(SQLWriteParams{Statement: statement})
+	if err := run(t, tool, string(args), &out); err != nil {
+		t.Fatal(err)
+	}
+	token := out.ConfirmToken
+	other, _ := json.Marshal(SQLWriteParams{Statement: "DELETE FROM orders WHERE id = 2", ConfirmToken: token})
+	if err := run(t, tool, string(other), &out); !isInvalidParams(err) {
+		t.Errorf("expected a token for another statement to be invalid params, got %v", err)
+	}
+	confirm, _ := json.Marshal(SQLWriteParams{Statement: statement, ConfirmToken: token})
+	ctx := mcpctx.WithPrincipal(context.Background(), &mcpctx.Identity{Subject: "mallory"})
+	if _, err := tool.Execute(ctx, confirm); !isInvalidParams(err) {
+		t.Errorf("expected a token for another caller to be invalid params, got %v", err)
+	}
+
+	// Rows changed since the dry run push the write over the limit, so it is rolled back
+	affected = 11
+	if err := run(t, tool, string(confirm), &out); err == nil || isInvalidParams(err) {
+		t.Errorf("expected the write to fail over the row limit, got %v", err)
+	}
+	if fake.commits != 0 {
+		t.Errorf("commits = %d", fake.commits)
+	}
+
+	// Dry runs over the limit get no token
+	if err := run(t, tool, string(args), &out); err == nil {
+		t.Errorf("expected the dry run to fail over the row limit, got %+v", out)
+	}
+
+	if _, err := NewSQLWriteTool(nil, SQLWriteConfig{}); err == nil {
+		t.Error("expected an error without a database")
+	}
+}
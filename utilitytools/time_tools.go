@@ -0,0 +1,375 @@
+package utilitytools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// DefaultTimeMaxCronRuns caps the runs next_cron returns
+const DefaultTimeMaxCronRuns = 100
+
+// TimeConfig configures NewTimeToolset
+type TimeConfig struct {
+	// Timezone is the IANA name of the zone used when a call names none (default "UTC")
+	Timezone string
+}
+
+// TimeValue is a point in time in a timezone
+type TimeValue struct {
+	Time      string `json:"time"` // RFC 3339
+	Timezone  string `json:"timezone"`
+	UTCOffset string `json:"utc_offset"` // such as "+05:30"
+	Unix      int64  `json:"unix"`
+	Weekday   string `json:"weekday"`
+	Formatted string `json:"formatted,omitempty"` // in the requested layout
+}
+
+// TimeNowParams defines parameters for time.now
+type TimeNowParams struct {
+	Timezone string `json:"timezone,omitempty" jsonschema:"IANA timezone such as America/New_York; defaults to the server's zone"`
+	Layout   string `json:"layout,omitempty" jsonschema:"also format the time with this layout: strftime (%Y-%m-%d %H:%M), a Go layout, or a name such as RFC1123 or Kitchen"`
+}
+
+// TimeConvertParams defines parameters for time.convert
+type TimeConvertParams struct {
+	Time         string `json:"time" jsonschema:"time to convert: RFC 3339, 2006-01-02 15:04:05, a date, unix seconds, or now"`
+	InputLayout  string `json:"input_layout,omitempty" jsonschema:"layout of time when it is in another format: strftime, a Go layout, or a name such as RFC1123"`
+	FromTimezone string `json:"from_timezone,omitempty" jsonschema:"timezone of a time without a UTC offset; defaults to the server's zone"`
+	Timezone     string `json:"timezone,omitempty" jsonschema:"timezone to convert to; defaults to the server's zone"`
+	Layout       string `json:"layout,omitempty" jsonschema:"also format the result with this layout: strftime, a Go layout, or a name such as RFC1123"`
+}
+
+// TimeAddParams defines parameters for time.add
+type TimeAddParams struct {
+	Time     string `json:"time" jsonschema:"starting time: RFC 3339, 2006-01-02 15:04:05, a date, unix seconds, or now"`
+	Timezone string `json:"timezone,omitempty" jsonschema:"timezone for calendar arithmetic and the result, and of a time without a UTC offset"`
+	Years    int    `json:"years,omitempty" jsonschema:"calendar years to add; negative subtracts"`
+	Months   int    `json:"months,omitempty" jsonschema:"calendar months to add; negative subtracts"`
+	Days     int    `json:"days,omitempty" jsonschema:"calendar days to add, keeping the wall-clock time across DST changes; negative subtracts"`
+	Duration string `json:"duration,omitempty" jsonschema:"elapsed time to add after the calendar units, such as 90m, 1h30m, or -45s"`
+}
+
+// TimeDiffParams defines parameters for time.diff
+type TimeDiffParams struct {
+	Start    string `json:"start" jsonschema:"start time: RFC 3339, 2006-01-02 15:04:05, a date, unix seconds, or now"`
+	End      string `json:"end" jsonschema:"end time, in the same formats"`
+	Timezone string `json:"timezone,omitempty" jsonschema:"timezone for calendar units, and of times without a UTC offset"`
+}
+
+// TimeDiffResult is the time between two points
+type TimeDiffResult struct {
+	Seconds  float64  `json:"seconds"` // negative when end is before start
+	Duration string   `json:"duration"`
+	Calendar TimeSpan `json:"calendar"`
+}
+
+// TimeSpan is a time difference in calendar units. All units share the sign of the difference.
+type TimeSpan struct {
+	Years   int `json:"years"`
+	Months  int `json:"months"`
+	Days    int `json:"days"`
+	Hours   int `json:"hours"`
+	Minutes int `json:"minutes"`
+	Seconds int `json:"seconds"`
+}
+
+// TimeCronParams defines parameters for time.next_cron
+type TimeCronParams struct {
+	Expression string `json:"expression" jsonschema:"five-field cron expression (minute hour day-of-month month day-of-week) or a macro such as @daily"`
+	After      string `json:"after,omitempty" jsonschema:"list runs after this time (default now)"`
+	Timezone   string `json:"timezone,omitempty" jsonschema:"timezone the schedule runs in; defaults to the server's zone"`
+	Count      int    `json:"count,omitempty" jsonschema:"number of runs to list (default 5)"`
+}
+
+// TimeCronResult lists the next runs of a cron schedule
+type TimeCronResult struct {
+	Runs []TimeValue `json:"runs"`
+}
+
+// NewTimeToolset creates the "time" toolset, which does the date arithmetic models get
+// wrong on their own: now gives the current time in any timezone, convert parses,
+// converts, and formats times, add does calendar and duration arithmetic, diff measures
+// the time between two points, and next_cron lists the next runs of a cron expression.
+//
+// Timezones are IANA names, loaded from the system's zoneinfo. Programs deployed without
+// it should import time/tzdata.
+func NewTimeToolset(cfg TimeConfig) (*tools.Toolset, error) {
+	return newTimeToolset(cfg, time.Now)
+}
+
+func newTimeToolset(cfg TimeConfig, now func() time.Time) (*tools.Toolset, error) {
+	if cfg.Timezone == "" {
+		cfg.Timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("time toolset: %w", err)
+	}
+	t := &timeTools{loc: loc, now: now}
+
+	return tools.NewToolset("time",
+		tools.NewTool("now", fmt.Sprintf("Returns the current date and time in a timezone (default %s)", cfg.Timezone), t.current,
+			tools.WithCategory("time")),
+		tools.NewTool("convert", "Parses a time, converts it to another timezone, and optionally formats it", t.convert,
+			tools.WithCategory("time")),
+		tools.NewTool("add", "Adds calendar units and a duration to a time, handling month lengths, leap years, and DST changes", t.add,
+			tools.WithCategory("time")),
+		tools.NewTool("diff", "Measures the time between two times, in seconds and in calendar units", t.diff,
+			tools.WithCategory("time")),
+		tools.NewTool("next_cron", "Lists the next times a cron expression runs. Local times skipped by a DST change do not run.", t.nextCron,
+			tools.WithCategory("time")),
+	), nil
+}
+
+type timeTools struct {
+	loc *time.Location
+	now func() time.Time
+}
+
+// location loads a call's timezone, defaulting to the server's
+func (t *timeTools) location(name string) (*time.Location, error) {
+	if name == "" {
+		return t.loc, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, tools.NewInvalidParamsError(fmt.Sprintf("unknown timezone %q; use an IANA name such as Europe/Paris", name))
+	}
+	return loc, nil
+}
+
+func (t *timeTools) current(ctx context.Context, params TimeNowParams) (*TimeValue, error) {
+	loc, err := t.location(params.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	return newTimeValue(t.now().In(loc), params.Layout), nil
+}
+
+func (t *timeTools) convert(ctx context.Context, params TimeConvertParams) (*TimeValue, error) {
+	from, err := t.location(params.FromTimezone)
+	if err != nil {
+		return nil, err
+	}
+	to, err := t.location(params.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := t.parse("time", params.Time, params.InputLayout, from)
+	if err != nil {
+		return nil, err
+	}
+	return newTimeValue(parsed.In(to), params.Layout), nil
+}
+
+func (t *timeTools) add(ctx context.Context, params TimeAddParams) (*TimeValue, error) {
+	loc, err := t.location(params.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	start, err := t.parse("time", params.Time, "", loc)
+	if err != nil {
+		return nil, err
+	}
+	result := start.In(loc).AddDate(params.Years, params.Months, params.Days)
+	if params.Duration != "" {
+		d, err := time.ParseDuration(params.Duration)
+		if err != nil {
+			return nil, tools.NewInvalidParamsError(fmt.Sprintf("invalid duration %q; use units h, m, s, ms such as 1h30m", params.Duration))
+		}
+		result = result.Add(d)
+	}
+	return newTimeValue(result, ""), nil
+}
+
+func (t *timeTools) diff(ctx context.Context, params TimeDiffParams) (*TimeDiffResult, error) {
+	loc, err := t.location(params.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	start, err := t.parse("start", params.Start, "", loc)
+	if err != nil {
+		return nil, err
+	}
+	end, err := t.parse("end", params.End, "", loc)
+	if err != nil {
+		return nil, err
+	}
+	d := end.Sub(start)
+	return &TimeDiffResult{
+		Seconds:  d.Seconds(),
+		Duration: d.String(),
+		Calendar: calendarSpan(start.In(loc), end.In(loc)),
+	}, nil
+}
+
+func (t *timeTools) nextCron(ctx context.Context, params TimeCronParams) (*TimeCronResult, error) {
+	schedule, err := parseCron(params.Expression)
+	if err != nil {
+		return nil, tools.NewInvalidParamsError(err.Error())
+	}
+	loc, err := t.location(params.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	after := t.now()
+	if params.After != "" {
+		if after, err = t.parse("after", params.After, "", loc); err != nil {
+			return nil, err
+		}
+	}
+	count := params.Count
+	if count <= 0 {
+		count = 5
+	}
+	count = min(count, DefaultTimeMaxCronRuns)
+
+	result := &TimeCronResult{Runs: []TimeValue{}}
+	next := after.In(loc)
+	for len(result.Runs) < count {
+		if next, err = schedule.next(next); err != nil {
+			if len(result.Runs) > 0 {
+				break
+			}
+			return nil, tools.NewInvalidParamsError(err.Error())
+		}
+		result.Runs = append(result.Runs, *newTimeValue(next, ""))
+	}
+	return result, nil
+}
+
+// timeInputLayouts are tried in order to parse times given without an input layout
+var timeInputLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	time.DateOnly,
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.ANSIC,
+}
+
+// parse reads a time in one of timeInputLayouts, as unix seconds, as "now", or in layout
+// when it is set. Times without a UTC offset are in loc.
+func (t *timeTools) parse(param, value, layout string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, tools.NewInvalidParamsError(param + " is required")
+	}
+	if layout != "" {
+		parsed, err := time.ParseInLocation(timeLayout(layout), value, loc)
+		if err != nil {
+			return time.Time{}, tools.NewInvalidParamsError(fmt.Sprintf("%s %q does not match layout %q", param, value, layout))
+		}
+		return parsed, nil
+	}
+	if strings.EqualFold(value, "now") {
+		return t.now(), nil
+	}
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(unix, 0), nil
+	}
+	for _, l := range timeInputLayouts {
+		if parsed, err := time.ParseInLocation(l, value, loc); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, tools.NewInvalidParamsError(fmt.Sprintf("cannot parse %s %q; use RFC 3339 such as 2024-05-01T14:30:00Z, or set input_layout", param, value))
+}
+
+func newTimeValue(t time.Time, layout string) *TimeValue {
+	v := &TimeValue{
+		Time:      t.Format(time.RFC3339),
+		Timezone:  t.Location().String(),
+		UTCOffset: t.Format("-07:00"),
+		Unix:      t.Unix(),
+		Weekday:   t.Weekday().String(),
+	}
+	if layout != "" {
+		v.Formatted = t.Format(timeLayout(layout))
+	}
+	return v
+}
+
+// namedTimeLayouts are the layouts calls may name instead of spelling out
+var namedTimeLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"ANSIC":       time.ANSIC,
+	"Kitchen":     time.Kitchen,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// strftimeLayouts translates strftime directives to Go layout elements
+var strftimeLayouts = map[byte]string{
+	'Y': "2006", 'y': "06", 'm': "01", 'd': "02", 'e': "_2", 'j': "002",
+	'H': "15", 'I': "03", 'M': "04", 'S': "05", 'p': "PM",
+	'b': "Jan", 'h': "Jan", 'B': "January", 'a': "Mon", 'A': "Monday",
+	'Z': "MST", 'z': "-0700", 'F': "2006-01-02", 'T': "15:04:05", 'R': "15:04",
+	'D': "01/02/06", 'c': time.ANSIC, '%': "%",
+}
+
+// timeLayout returns the Go layout of a named layout, a strftime format, or a Go layout
+func timeLayout(layout string) string {
+	if named, ok := namedTimeLayouts[layout]; ok {
+		return named
+	}
+	if !strings.Contains(layout, "%") {
+		return layout
+	}
+	var b strings.Builder
+	for i := 0; i < len(layout); i++ {
+		if layout[i] == '%' && i+1 < len(layout) {
+			if elem, ok := strftimeLayouts[layout[i+1]]; ok {
+				b.WriteString(elem)
+				i++
+				continue
+			}
+		}
+		b.WriteByte(layout[i])
+	}
+	return b.String()
+}
+
+// calendarSpan splits the time from start to end into calendar units, counting whole
+// months and days from start in its location
+func calendarSpan(start, end time.Time) TimeSpan {
+	sign := 1
+	if end.Before(start) {
+		start, end, sign = end, start, -1
+	}
+	months := (end.Year()-start.Year())*12 + int(end.Month()-start.Month())
+	for months > 0 && start.AddDate(0, months, 0).After(end) {
+		months--
+	}
+	cursor := start.AddDate(0, months, 0)
+	days := 0
+	for !cursor.AddDate(0, 0, days+1).After(end) {
+		days++
+	}
+	rest := end.Sub(cursor.AddDate(0, 0, days))
+	return TimeSpan{
+		Years:   sign * (months / 12),
+		Months:  sign * (months % 12),
+		Days:    sign * days,
+		Hours:   sign * int(rest/time.Hour),
+		Minutes: sign * int(rest%time.Hour/time.Minute),
+		Seconds: sign * int(rest%time.Minute/time.Second),
+	}
+}
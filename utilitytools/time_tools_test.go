@@ -0,0 +1,156 @@
+package utilitytools
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// newTimeTools builds the toolset with a fixed clock and returns its tools by unprefixed name
+func newTimeTools(t *testing.T, cfg TimeConfig) map[string]tools.Tool {
+	t.Helper()
+	now := time.Date(2024, 3, 9, 15, 30, 0, 0, time.UTC) // a Saturday
+	set, err := newTimeToolset(cfg, func() time.Time { return now })
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]tools.Tool)
+	for _, tool := range set.Tools() {
+		byName[strings.TrimPrefix(tool.Spec().Name, "time.")] = tool
+	}
+	return byName
+}
+
+func TestTimeToolset_NowAndConvert(t *testing.T) {
+	timeTools := newTimeTools(t, TimeConfig{})
+
+	var now TimeValue
+	if err := run(t, timeTools["now"], `{"timezone": "Asia/Kolkata", "layout": "%A %d %B %Y, %I:%M %p"}`, &now); err != nil {
+		t.Fatal(err)
+	}
+	if now.Time != "2024-03-09T21:00:00+05:30" || now.UTCOffset != "+05:30" || now.Weekday != "Saturday" ||
+		now.Formatted != "Saturday 09 March 2024, 09:00 PM" || now.Unix != 1709998200 {
+		t.Errorf("now = %+v", now)
+	}
+
+	var converted TimeValue
+	for args, want := range map[string]string{
+		`{"time": "2024-07-01 09:00", "from_timezone": "America/New_York", "timezone": "Europe/Paris"}`: "2024-07-01T15:00:00+02:00",
+		`{"time": "2024-01-15T12:00:00Z", "timezone": "America/Los_Angeles"}`:                           "2024-01-15T04:00:00-08:00",
+		`{"time": "1700000000"}`: "2023-11-14T22:13:20Z",
+		`{"time": "15/01/2024 08:05", "input_layout": "%d/%m/%Y %H:%M", "timezone": "UTC"}`: "2024-01-15T08:05:00Z",
+	} {
+		if err := run(t, timeTools["convert"], args, &converted); err != nil {
+			t.Errorf("%s: %v", args, err)
+			continue
+		}
+		if converted.Time != want {
+			t.Errorf("%s: time = %s, want %s", args, converted.Time, want)
+		}
+	}
+	run(t, timeTools["convert"], `{"time": "2024-01-15T12:00:00Z", "layout": "RFC1123"}`, &converted)
+	if converted.Formatted != "Mon, 15 Jan 2024 12:00:00 UTC" {
+		t.Errorf("formatted = %q", converted.Formatted)
+	}
+
+	for _, args := range []string{
+		`{"timezone": "Mars/Olympus"}`,
+		`{"time": "next tuesday"}`,
+		`{"time": ""}`,
+	} {
+		if err := run(t, timeTools["convert"], args, &converted); !isInvalidParams(err) {
+			t.Errorf("%s: expected invalid params, got %v", args, err)
+		}
+	}
+	if _, err := NewTimeToolset(TimeConfig{Timezone: "Nowhere/Atlantis"}); err == nil {
+		t.Error("expected an error for an unknown default timezone")
+	}
+}
+
+func TestTimeToolset_AddAndDiff(t *testing.T) {
+	timeTools := newTimeTools(t, TimeConfig{Timezone: "America/New_York"})
+
+	var added TimeValue
+	for args, want := range map[string]string{
+		// Calendar days keep the wall-clock time across the DST change on March 10
+		`{"time": "2024-03-09 12:00", "days": 1}`: "2024-03-10T12:00:00-04:00",
+		// A duration is elapsed time
+		`{"time": "2024-03-09 12:00", "duration": "24h"}`:                 "2024-03-10T13:00:00-04:00",
+		`{"time": "2024-01-31", "months": 1, "duration": "-1h30m"}`:       "2024-03-01T22:30:00-05:00",
+		`{"time": "2024-02-29T00:00:00Z", "years": 1, "timezone": "UTC"}`: "2025-03-01T00:00:00Z",
+	} {
+		if err := run(t, timeTools["add"], args, &added); err != nil {
+			t.Errorf("%s: %v", args, err)
+			continue
+		}
+		if added.Time != want {
+			t.Errorf("%s: time = %s, want %s", args, added.Time, want)
+		}
+	}
+	if err := run(t, timeTools["add"], `{"time": "now", "duration": "2 days"}`, &added); !isInvalidParams(err) {
+		t.Errorf("expected an invalid duration to be invalid params, got %v", err)
+	}
+
+	var diff TimeDiffResult
+	if err := run(t, timeTools["diff"], `{"start": "2023-01-15 08:00", "end": "2024-03-17 10:30:15"}`, &diff); err != nil {
+		t.Fatal(err)
+	}
+	want := TimeSpan{Years: 1, Months: 2, Days: 2, Hours: 2, Minutes: 30, Seconds: 15}
+	if diff.Calendar != want {
+		t.Errorf("calendar = %+v, want %+v", diff.Calendar, want)
+	}
+	run(t, timeTools["diff"], `{"start": "2024-03-10T12:00:00Z", "end": "2024-03-10T10:00:00Z"}`, &diff)
+	if diff.Seconds != -7200 || diff.Duration != "-2h0m0s" || diff.Calendar.Hours != -2 {
+		t.Errorf("negative diff = %+v", diff)
+	}
+}
+
+func TestTimeToolset_NextCron(t *testing.T) {
+	timeTools := newTimeTools(t, TimeConfig{})
+
+	runs := func(args string) []string {
+		t.Helper()
+		var result TimeCronResult
+		if err := run(t, timeTools["next_cron"], args, &result); err != nil {
+			t.Fatalf("%s: %v", args, err)
+		}
+		var times []string
+		for _, r := range result.Runs {
+			times = append(times, r.Time)
+		}
+		return times
+	}
+
+	if got := strings.Join(runs(`{"expression": "*/20 9-17 * * MON-FRI", "count": 3}`), " "); got !=
+		"2024-03-11T09:00:00Z 2024-03-11T09:20:00Z 2024-03-11T09:40:00Z" {
+		t.Errorf("weekday runs = %s", got)
+	}
+	if got := strings.Join(runs(`{"expression": "@monthly", "after": "2024-01-31T00:00:00Z", "count": 2}`), " "); got !=
+		"2024-02-01T00:00:00Z 2024-03-01T00:00:00Z" {
+		t.Errorf("monthly runs = %s", got)
+	}
+	// Leap days only
+	if got := strings.Join(runs(`{"expression": "0 12 29 2 *", "count": 2}`), " "); got !=
+		"2028-02-29T12:00:00Z 2032-02-29T12:00:00Z" {
+		t.Errorf("leap day runs = %s", got)
+	}
+	// Restricted day-of-month and day-of-week match either
+	if got := strings.Join(runs(`{"expression": "0 0 13 * 5", "after": "2024-09-01T00:00:00Z", "count": 3}`), " "); got !=
+		"2024-09-06T00:00:00Z 2024-09-13T00:00:00Z 2024-09-20T00:00:00Z" {
+		t.Errorf("dom or dow runs = %s", got)
+	}
+	// Schedules run in their zone, and times skipped by a DST change do not run
+	if got := strings.Join(runs(`{"expression": "30 2 * * *", "timezone": "America/New_York", "after": "2024-03-09T12:00:00Z", "count": 2}`), " "); got !=
+		"2024-03-11T02:30:00-04:00 2024-03-12T02:30:00-04:00" {
+		t.Errorf("dst runs = %s", got)
+	}
+
+	var result TimeCronResult
+	for _, expr := range []string{"* * * *", "60 * * * *", "0 0 * * MON-", "*/0 * * * *", "0 0 31 2 *"} {
+		if err := run(t, timeTools["next_cron"], `{"expression": "`+expr+`"}`, &result); !isInvalidParams(err) {
+			t.Errorf("%q: expected invalid params, got %v", expr, err)
+		}
+	}
+}
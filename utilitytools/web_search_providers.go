@@ -0,0 +1,274 @@
+package utilitytools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// SearchQuery is a web search request
+type SearchQuery struct {
+	Query string
+	Count int // results wanted; providers may return fewer
+}
+
+// SearchResult is a web search result, normalized across providers
+type SearchResult struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// SearchProvider runs web searches against a search engine
+type SearchProvider interface {
+	// Name identifies the provider in results and logs, such as "brave"
+	Name() string
+	Search(ctx context.Context, query SearchQuery) ([]SearchResult, error)
+}
+
+// ErrSearchRateLimited is returned when a provider or the search tool's own limit rejects
+// a search
+var ErrSearchRateLimited = errors.New("search rate limited")
+
+// SearchProviderConfig selects and configures a provider by name, for servers that
+// choose their search engine in configuration
+type SearchProviderConfig struct {
+	Kind       string // "bing", "brave", "serpapi", or "searx"
+	APIKey     string // required except for searx
+	Endpoint   string // overrides the provider's API URL; required for searx, the instance's base URL
+	HTTPClient *http.Client
+}
+
+// NewSearchProvider creates the provider cfg.Kind names
+func NewSearchProvider(cfg SearchProviderConfig) (SearchProvider, error) {
+	kind := strings.ToLower(cfg.Kind)
+	if kind == "searx" || kind == "searxng" {
+		if cfg.Endpoint == "" {
+			return nil, errors.New("search provider searx: no Endpoint")
+		}
+		return &SearxProvider{BaseURL: cfg.Endpoint, HTTPClient: cfg.HTTPClient}, nil
+	}
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("search provider %q: no API key", cfg.Kind)
+	}
+	switch kind {
+	case "bing":
+		return &BingProvider{APIKey: cfg.APIKey, Endpoint: cfg.Endpoint, HTTPClient: cfg.HTTPClient}, nil
+	case "brave":
+		return &BraveProvider{APIKey: cfg.APIKey, Endpoint: cfg.Endpoint, HTTPClient: cfg.HTTPClient}, nil
+	case "serpapi":
+		return &SerpAPIProvider{APIKey: cfg.APIKey, Endpoint: cfg.Endpoint, HTTPClient: cfg.HTTPClient}, nil
+	}
+	return nil, fmt.Errorf("unknown search provider %q", cfg.Kind)
+}
+
+// BingProvider searches with the Bing Web Search API
+type BingProvider struct {
+	APIKey     string
+	Endpoint   string       // default https://api.bing.microsoft.com/v7.0/search
+	HTTPClient *http.Client // default a client with a 10s timeout
+}
+
+// Name implements SearchProvider
+func (p *BingProvider) Name() string { return "bing" }
+
+// Search queries Bing
+func (p *BingProvider) Search(ctx context.Context, query SearchQuery) ([]SearchResult, error) {
+	params := url.Values{"q": {query.Query}, "count": {strconv.Itoa(query.Count)}}
+	var response struct {
+		WebPages struct {
+			Value []struct {
+				Name    string `json:"name"`
+				URL     string `json:"url"`
+				Snippet string `json:"snippet"`
+			} `json:"value"`
+		} `json:"webPages"`
+	}
+	endpoint := defaultString(p.Endpoint, "https://api.bing.microsoft.com/v7.0/search")
+	headers := map[string]string{"Ocp-Apim-Subscription-Key": p.APIKey}
+	if err := getSearchJSON(ctx, p.HTTPClient, endpoint, params, headers, &response); err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, 0, len(response.WebPages.Value))
+	for _, r := range response.WebPages.Value {
+		results = append(results, SearchResult{Title: r.Name, URL: r.URL, Snippet: r.Snippet})
+	}
+	return results, nil
+}
+
+// BraveProvider searches with the Brave Search API
+type BraveProvider struct {
+	APIKey     string
+	Endpoint   string       // default https://api.search.brave.com/res/v1/web/search
+	HTTPClient *http.Client // default a client with a 10s timeout
+}
+
+// Name implements SearchProvider
+func (p *BraveProvider) Name() string { return "brave" }
+
+// Search queries Brave
+func (p *BraveProvider) Search(ctx context.Context, query SearchQuery) ([]SearchResult, error) {
+	// Brave returns at most 20 results per request
+	params := url.Values{"q": {query.Query}, "count": {strconv.Itoa(min(query.Count, 20))}}
+	var response struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	endpoint := defaultString(p.Endpoint, "https://api.search.brave.com/res/v1/web/search")
+	headers := map[string]string{"X-Subscription-Token": p.APIKey}
+	if err := getSearchJSON(ctx, p.HTTPClient, endpoint, params, headers, &response); err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, 0, len(response.Web.Results))
+	for _, r := range response.Web.Results {
+		results = append(results, SearchResult{Title: r.Title, URL: r.URL, Snippet: r.Description})
+	}
+	return results, nil
+}
+
+// SerpAPIProvider searches Google through SerpApi
+type SerpAPIProvider struct {
+	APIKey     string
+	Engine     string       // SerpApi engine (default "google")
+	Endpoint   string       // default https://serpapi.com/search.json
+	HTTPClient *http.Client // default a client with a 10s timeout
+}
+
+// Name implements SearchProvider
+func (p *SerpAPIProvider) Name() string { return "serpapi" }
+
+// Search queries SerpApi
+func (p *SerpAPIProvider) Search(ctx context.Context, query SearchQuery) ([]SearchResult, error) {
+	params := url.Values{
+		"engine":  {defaultString(p.Engine, "google")},
+		"q":       {query.Query},
+		"num":     {strconv.Itoa(query.Count)},
+		"api_key": {p.APIKey},
+	}
+	var response struct {
+		Error          string `json:"error"`
+		OrganicResults []struct {
+			Title   string `json:"title"`
+			Link    string `json:"link"`
+			Snippet string `json:"snippet"`
+		} `json:"organic_results"`
+	}
+	endpoint := defaultString(p.Endpoint, "https://serpapi.com/search.json")
+	if err := getSearchJSON(ctx, p.HTTPClient, endpoint, params, nil, &response); err != nil {
+		return nil, err
+	}
+	// SerpApi reports searches without results as an error
+	if response.Error != "" && len(response.OrganicResults) == 0 && !strings.Contains(response.Error, "hasn't returned any results") {
+		return nil, fmt.Errorf("serpapi: %s", response.Error)
+	}
+	results := make([]SearchResult, 0, len(response.OrganicResults))
+	for _, r := range response.OrganicResults {
+		results = append(results, SearchResult{Title: r.Title, URL: r.Link, Snippet: r.Snippet})
+	}
+	return results, nil
+}
+
+// SearxProvider searches a SearXNG instance, which must allow the JSON output format
+type SearxProvider struct {
+	BaseURL    string       // such as https://searx.example.org
+	HTTPClient *http.Client // default a client with a 10s timeout
+}
+
+// Name implements SearchProvider
+func (p *SearxProvider) Name() string { return "searx" }
+
+// Search queries the SearXNG instance
+func (p *SearxProvider) Search(ctx context.Context, query SearchQuery) ([]SearchResult, error) {
+	params := url.Values{"q": {query.Query}, "format": {"json"}}
+	var response struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	endpoint := strings.TrimSuffix(p.BaseURL, "/") + "/search"
+	if err := getSearchJSON(ctx, p.HTTPClient, endpoint, params, nil, &response); err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, 0, len(response.Results))
+	for _, r := range response.Results {
+		results = append(results, SearchResult{Title: r.Title, URL: r.URL, Snippet: r.Content})
+	}
+	return results, nil
+}
+
+var defaultSearchClient = &http.Client{Timeout: 10 * time.Second}
+
+// getSearchJSON sends a GET request and decodes its JSON response into out. The request
+// draws one sub-call, and the bytes of its response, from the request budget in ctx.
+func getSearchJSON(ctx context.Context, client *http.Client, endpoint string, params url.Values, headers map[string]string, out interface{}) error {
+	if client == nil {
+		client = defaultSearchClient
+	}
+	if err := tools.SpendCall(ctx); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return ErrSearchRateLimited
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("search API returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading search response: %w", err)
+	}
+	if err := tools.SpendBytes(ctx, int64(len(body))); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decoding search response: %w", err)
+	}
+	return nil
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// plainText removes the HTML tags and entities some providers put in titles and snippets
+func plainText(s string) string {
+	return strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(s, "")))
+}
+
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
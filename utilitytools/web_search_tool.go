@@ -0,0 +1,168 @@
+package utilitytools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+// Defaults for SearchConfig
+const (
+	DefaultSearchResults    = 5
+	DefaultSearchMaxResults = 20
+)
+
+// SearchConfig configures NewSearchTool
+type SearchConfig struct {
+	// Provider runs the searches; see NewSearchProvider
+	Provider SearchProvider
+
+	DefaultResults int // results a call gets without count (default 5)
+	MaxResults     int // caps count (default 20)
+
+	// RequestsPerMinute limits searches across all callers, to stay within the provider's
+	// quota; 0 disables the limit. Burst searches may run back to back (default 1).
+	RequestsPerMinute int
+	Burst             int
+
+	Logger *slog.Logger
+}
+
+// SearchParams defines parameters for the web search tool
+type SearchParams struct {
+	Query string `json:"query" jsonschema:"search terms"`
+	Count int    `json:"count,omitempty" jsonschema:"number of results to return"`
+}
+
+// SearchResults holds a search's results
+type SearchResults struct {
+	Query    string         `json:"query"`
+	Provider string         `json:"provider"`
+	Results  []SearchResult `json:"results"`
+}
+
+// NewSearchTool creates a tool named "WebSearch" that searches the web with cfg.Provider
+// and returns each result's title, URL, and snippet, with HTML removed and duplicate URLs
+// dropped.
+//
+// Example:
+//
+//	provider, err := utilitytools.NewSearchProvider(utilitytools.SearchProviderConfig{
+//	    Kind:   "brave",
+//	    APIKey: os.Getenv("BRAVE_API_KEY"),
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	search, err := utilitytools.NewSearchTool(utilitytools.SearchConfig{Provider: provider, RequestsPerMinute: 60})
+func NewSearchTool(cfg SearchConfig) (tools.Tool, error) {
+	if cfg.Provider == nil {
+		return nil, errors.New("search tool: no provider")
+	}
+	if cfg.MaxResults <= 0 {
+		cfg.MaxResults = DefaultSearchMaxResults
+	}
+	if cfg.DefaultResults <= 0 {
+		cfg.DefaultResults = min(DefaultSearchResults, cfg.MaxResults)
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	s := &searchTool{cfg: cfg}
+	if cfg.RequestsPerMinute > 0 {
+		s.limiter = newRateLimiter(float64(cfg.RequestsPerMinute)/60, max(cfg.Burst, 1), time.Now)
+	}
+
+	description := fmt.Sprintf("Searches the web and returns up to %d results, each with a title, URL, and snippet. "+
+		"Results reflect the search engine's index and may be out of date.", cfg.MaxResults)
+	return tools.NewTool("WebSearch", description, s.run,
+		tools.WithType("WebSearch_v1"),
+		tools.WithVerb("Searching the web"),
+		tools.WithCategory("search"),
+	), nil
+}
+
+type searchTool struct {
+	cfg     SearchConfig
+	limiter *rateLimiter
+}
+
+func (s *searchTool) run(ctx context.Context, params SearchParams) (*SearchResults, error) {
+	query := strings.TrimSpace(params.Query)
+	if query == "" {
+		return nil, tools.NewInvalidParamsError("query is required")
+	}
+	count := params.Count
+	if count <= 0 {
+		count = s.cfg.DefaultResults
+	}
+	count = min(count, s.cfg.MaxResults)
+
+	if s.limiter != nil {
+		if wait := s.limiter.take(); wait > 0 {
+			return nil, fmt.Errorf("%w: retry in %d seconds", ErrSearchRateLimited, int(math.Ceil(wait.Seconds())))
+		}
+	}
+
+	provider := s.cfg.Provider.Name()
+	start := time.Now()
+	found, err := s.cfg.Provider.Search(ctx, SearchQuery{Query: query, Count: count})
+	if err != nil {
+		s.cfg.Logger.Warn("web search failed", "provider", provider, "error", err)
+		return nil, fmt.Errorf("searching with %s: %w", provider, err)
+	}
+	s.cfg.Logger.Debug("web search", "provider", provider, "results", len(found), "duration_ms", time.Since(start).Milliseconds())
+
+	result := &SearchResults{Query: query, Provider: provider, Results: []SearchResult{}}
+	seen := make(map[string]bool)
+	for _, r := range found {
+		if r.URL == "" || seen[r.URL] {
+			continue
+		}
+		seen[r.URL] = true
+		result.Results = append(result.Results, SearchResult{
+			Title:   plainText(r.Title),
+			URL:     r.URL,
+			Snippet: plainText(r.Snippet),
+		})
+		if len(result.Results) == count {
+			break
+		}
+	}
+	return result, nil
+}
+
+// rateLimiter is a token bucket holding up to burst tokens, refilled at rate per second
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func newRateLimiter(rate float64, burst int, now func() time.Time) *rateLimiter {
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: now(), now: now}
+}
+
+// take spends a token, or returns how long until one is available
+func (l *rateLimiter) take() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
@@ -0,0 +1,170 @@
+package utilitytools
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/minimcp/tools"
+)
+
+func TestSearchProviders(t *testing.T) {
+	responses := map[string]struct {
+		header, key, body string
+	}{
+		"/bing":    {"Ocp-Apim-Subscription-Key", "k", `{"webPages":{"value":[{"name":"Go","url":"https://go.dev","snippet":"The Go language"}]}}`},
+		"/brave":   {"X-Subscription-Token", "k", `{"web":{"results":[{"title":"Go","url":"https://go.dev","description":"The <strong>Go</strong> language"}]}}`},
+		"/serpapi": {"", "", `{"organic_results":[{"title":"Go","link":"https://go.dev","snippet":"The Go language"}]}`},
+		"/search":  {"", "", `{"results":[{"title":"Go","url":"https://go.dev","content":"The Go language"}]}`},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, ok := responses[r.URL.Path]
+		if !ok || r.URL.Query().Get("q") != "golang" {
+			http.NotFound(w, r)
+			return
+		}
+		if resp.header != "" && r.Header.Get(resp.header) != resp.key {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/serpapi" && r.URL.Query().Get("api_key") != "k" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(w, resp.body)
+	}))
+	defer server.Close()
+
+	for _, cfg := range []SearchProviderConfig{
+		{Kind: "bing", APIKey: "k", Endpoint: server.URL + "/bing"},
+		{Kind: "brave", APIKey: "k", Endpoint: server.URL + "/brave"},
+		{Kind: "serpapi", APIKey: "k", Endpoint: server.URL + "/serpapi"},
+		{Kind: "searx", Endpoint: server.URL},
+	} {
+		provider, err := NewSearchProvider(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tool, err := NewSearchTool(SearchConfig{Provider: provider, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result SearchResults
+		if err := run(t, tool, `{"query": "golang"}`, &result); err != nil {
+			t.Errorf("%s: %v", cfg.Kind, err)
+			continue
+		}
+		want := SearchResult{Title: "Go", URL: "https://go.dev", Snippet: "The Go language"}
+		if result.Provider != provider.Name() || len(result.Results) != 1 || result.Results[0] != want {
+			t.Errorf("%s: result = %+v", cfg.Kind, result)
+		}
+	}
+
+	for _, cfg := range []SearchProviderConfig{{Kind: "altavista", APIKey: "k"}, {Kind: "brave"}, {Kind: "searx"}} {
+		if _, err := NewSearchProvider(cfg); err == nil {
+			t.Errorf("%+v: expected an error", cfg)
+		}
+	}
+}
+
+// fakeSearchProvider returns n results for every query
+type fakeSearchProvider struct {
+	n     int
+	err   error
+	calls int
+}
+
+func (p *fakeSearchProvider) Name() string { return "fake" }
+
+func (p *fakeSearchProvider) Search(ctx context.Context, query SearchQuery) ([]SearchResult, error) {
+	p.calls++
+	var results []SearchResult
+	for i := 0; i < p.n; i++ {
+		url := "https://example.com/" + string(rune('a'+i%3)) // repeats after three results
+		results = append(results, SearchResult{Title: "Result &amp; more", URL: url})
+	}
+	return results, p.err
+}
+
+func TestSearchTool_LimitsAndErrors(t *testing.T) {
+	provider := &fakeSearchProvider{n: 10}
+	tool, err := NewSearchTool(SearchConfig{Provider: provider, MaxResults: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result SearchResults
+	if err := run(t, tool, `{"query": "x", "count": 50}`, &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Results) != 2 || result.Results[0].Title != "Result & more" || result.Results[0].URL == result.Results[1].URL {
+		t.Errorf("result = %+v", result)
+	}
+	if err := run(t, tool, `{"query": "  "}`, &result); !isInvalidParams(err) {
+		t.Errorf("expected an empty query to be invalid params, got %v", err)
+	}
+
+	provider.err = ErrSearchRateLimited
+	if err := run(t, tool, `{"query": "x"}`, &result); !errors.Is(err, ErrSearchRateLimited) {
+		t.Errorf("expected the provider's rate limit error, got %v", err)
+	}
+
+	if _, err := NewSearchTool(SearchConfig{}); err == nil {
+		t.Error("expected an error without a provider")
+	}
+}
+
+func TestSearchProviders_SpendBudget(t *testing.T) {
+	body := `{"results":[]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}))
+	defer server.Close()
+	provider, err := NewSearchProvider(SearchProviderConfig{Kind: "searx", Endpoint: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		limits tools.BudgetLimits
+	}{
+		{"calls", tools.BudgetLimits{MaxCalls: 1}},
+		{"bytes", tools.BudgetLimits{MaxBytes: int64(len(body)) + 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tools.WithBudget(context.Background(), tools.NewBudget(tt.limits))
+			if _, err := provider.Search(ctx, SearchQuery{Query: "golang"}); err != nil {
+				t.Fatalf("expected the first search to fit the budget, got %v", err)
+			}
+			if _, err := provider.Search(ctx, SearchQuery{Query: "golang"}); !errors.Is(err, tools.ErrBudgetExceeded) {
+				t.Errorf("expected ErrBudgetExceeded, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := newRateLimiter(1.0/60, 2, func() time.Time { return now }) // one per minute, burst of two
+
+	if limiter.take() != 0 || limiter.take() != 0 {
+		t.Fatal("expected the burst to be allowed")
+	}
+	if wait := limiter.take(); wait != time.Minute {
+		t.Errorf("wait = %v, want 1m", wait)
+	}
+	now = now.Add(30 * time.Second)
+	if wait := limiter.take(); wait != 30*time.Second {
+		t.Errorf("wait = %v, want 30s", wait)
+	}
+	now = now.Add(30 * time.Second)
+	if limiter.take() != 0 {
+		t.Error("expected a token after a minute")
+	}
+}